@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"paystack.mpc.proxy/internal/config"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/handlers"
+	"paystack.mpc.proxy/internal/money"
+)
+
+// Version is set at build time
+var Version = "dev"
+
+func main() {
+	file := flag.String("file", "", "Statement to import (CSV or OFX)")
+	format := flag.String("format", "", "Statement format: csv or ofx (detected when empty)")
+	profile := flag.String("profile", "", "CSV profile, e.g. gtbank, access, kuda (detected when empty)")
+	currency := flag.String("currency", "", "Currency of lines that don't state one (default NGN)")
+	budget := flag.Int("budget", 0, "Budget to count expenses against (default budget when 0)")
+	dryRun := flag.Bool("dry-run", false, "Show what would be imported without creating anything")
+	allowPossible := flag.Bool("allow-possible-duplicates", false, "Import lines that only match an expense by day and amount")
+	skipOverBudget := flag.Bool("skip-over-budget", false, "Leave out lines that would exceed the budget")
+	asJSON := flag.Bool("json", false, "Print the full result as JSON")
+	flag.Parse()
+
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "usage: import -file statement.csv [-dry-run] [-profile gtbank] [-budget 3]")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	// Keep stdout for the result; logs go to stderr
+	log.SetOutput(os.Stderr)
+
+	cfg := config.Load()
+	if err := database.Initialize(cfg.DatabasePath); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	opts := handlers.StatementImportOptions{
+		Format:                  *format,
+		Profile:                 *profile,
+		DryRun:                  *dryRun,
+		AllowPossibleDuplicates: *allowPossible,
+		SkipOverBudget:          *skipOverBudget,
+	}
+	if *currency != "" {
+		c, err := money.ParseCurrency(*currency)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.Currency = c
+	}
+	if *budget > 0 {
+		opts.BudgetLimitID = budget
+	}
+
+	result, err := handlers.ImportStatementFile(*file, opts)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(result)
+		return
+	}
+	printResult(result)
+}
+
+// printResult prints one line per statement line, then the totals and budget
+func printResult(result *handlers.StatementImportResult) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROW\tDATE\tAMOUNT\tSTATUS\tCATEGORY\tDESCRIPTION")
+	for _, row := range result.Rows {
+		status := row.Status
+		if row.OverBudget && status != "over_budget" {
+			status += " (over budget)"
+		}
+		if row.Reason != "" {
+			status += ": " + row.Reason
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", row.Row, row.Date.Format("2006-01-02"),
+			row.Money().Format(), status, row.Category, row.Description)
+	}
+	tw.Flush()
+
+	fmt.Println()
+	verb := "Imported"
+	if result.DryRun {
+		verb = "Would import"
+	}
+	profile := result.Format
+	if result.Profile != "" {
+		profile += ", " + result.Profile + " profile"
+	}
+	fmt.Printf("%s %d of %d lines (%s): %d duplicates, %d possible duplicates, %d credits, %d failed\n",
+		verb, result.Created, result.Lines, profile, result.Duplicates, result.PossibleDuplicates, result.Credits, result.Failed)
+
+	b := result.Budget
+	fmt.Printf("Budget %q: spent %s → %s of %s\n", b.Name,
+		money.New(int64(b.SpentBefore), b.Currency).Format(),
+		money.New(int64(b.SpentAfter), b.Currency).Format(), b.Format())
+	if b.OverBudget {
+		fmt.Printf("Over budget by %s\n", money.New(int64(b.ExcessAmount), b.Currency).Format())
+	}
+	if result.ImportID != 0 {
+		fmt.Printf("Import ID: %d\n", result.ImportID)
+	}
+}
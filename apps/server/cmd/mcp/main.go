@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"paystack.mpc.proxy/internal/config"
+	"paystack.mpc.proxy/internal/confirm"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/handlers"
+	"paystack.mpc.proxy/internal/mcp"
+	"paystack.mpc.proxy/internal/paystack"
+	"paystack.mpc.proxy/internal/server"
+	"paystack.mpc.proxy/internal/tools"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// Version is set at build time
+var Version = "dev"
+
+func main() {
+	transport := flag.String("transport", "stdio", "MCP transport: stdio or http")
+	addr := flag.String("addr", ":4001", "Listen address for the http transport")
+	flag.Parse()
+
+	// Keep stdout clean for the stdio transport; logs go to stderr
+	log.SetOutput(os.Stderr)
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize database
+	if err := database.Initialize(cfg.DatabasePath); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	// Configure confirmation token signing
+	confirm.Configure(cfg.ConfirmationSecret)
+
+	// Configure the balance floor flagged by cashflow forecasts
+	handlers.ConfigureForecast(cfg.ForecastBalanceFloor)
+
+	// Configure how payments are checked for duplicates and unusual amounts
+	handlers.ConfigureAnomalyDetection(cfg.AnomalyDuplicateWindow, cfg.AnomalyFirstPaymentThreshold)
+
+	// Build the tool registry on top of the same API routes the HTTP server uses
+	client := paystack.NewClient(cfg.PaystackSecretKey)
+	registry := tools.NewDefaultRegistry(server.NewAPIRouter(client))
+
+	s, err := mcp.NewServer(registry, Version)
+	if err != nil {
+		log.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigChan
+		log.Println("Shutting down gracefully...")
+		database.Close()
+		os.Exit(0)
+	}()
+
+	switch *transport {
+	case "stdio":
+		log.Println("Starting MCP server on stdio")
+		if err := mcpserver.ServeStdio(s); err != nil {
+			log.Fatalf("MCP server error: %v", err)
+		}
+	case "http":
+		log.Printf("Starting MCP server (streamable HTTP) on %s/mcp", *addr)
+		if err := mcpserver.NewStreamableHTTPServer(s).Start(*addr); err != nil {
+			log.Fatalf("MCP server error: %v", err)
+		}
+	default:
+		log.Fatalf("Unknown transport %q (expected stdio or http)", *transport)
+	}
+}
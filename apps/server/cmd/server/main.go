@@ -7,7 +7,9 @@ import (
 	"syscall"
 
 	"paystack.mpc.proxy/internal/config"
+	"paystack.mpc.proxy/internal/confirm"
 	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/handlers"
 	"paystack.mpc.proxy/internal/server"
 )
 
@@ -21,6 +23,15 @@ func main() {
 	}
 	defer database.Close()
 
+	// Configure confirmation token signing
+	confirm.Configure(cfg.ConfirmationSecret)
+
+	// Configure the balance floor flagged by cashflow forecasts
+	handlers.ConfigureForecast(cfg.ForecastBalanceFloor)
+
+	// Configure how payments are checked for duplicates and unusual amounts
+	handlers.ConfigureAnomalyDetection(cfg.AnomalyDuplicateWindow, cfg.AnomalyFirstPaymentThreshold)
+
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -0,0 +1,342 @@
+// Package analytics aggregates locally stored money movements (transactions,
+// expenses, transfers, invoices) into grouped sums, counts and averages with
+// period-over-period deltas.
+//
+// DESIGN DECISIONS:
+// - Aggregation is pure Go over rows loaded by the caller, so it works offline and is easy to test
+// - Time buckets are UTC and contiguous: empty days, weeks or months appear with zero totals
+// - Weeks start on Monday; a week bucket is keyed by its Monday (YYYY-MM-DD)
+// - The previous period is the range of equal length immediately before the requested one
+// - Percentage deltas are omitted when the previous value is zero
+// - All amounts are minor units (kobo)
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// GroupBy selects how rows are bucketed
+type GroupBy string
+
+const (
+	GroupByDay       GroupBy = "day"
+	GroupByWeek      GroupBy = "week"
+	GroupByMonth     GroupBy = "month"
+	GroupByCategory  GroupBy = "category"
+	GroupByRecipient GroupBy = "recipient"
+	GroupByStatus    GroupBy = "status"
+)
+
+// GroupByValues lists the supported groupings
+var GroupByValues = []GroupBy{GroupByDay, GroupByWeek, GroupByMonth, GroupByCategory, GroupByRecipient, GroupByStatus}
+
+// ParseGroupBy validates a grouping name
+func ParseGroupBy(s string) (GroupBy, bool) {
+	for _, g := range GroupByValues {
+		if string(g) == s {
+			return g, true
+		}
+	}
+	return "", false
+}
+
+// IsTime reports whether the grouping buckets by time
+func (g GroupBy) IsTime() bool {
+	return g == GroupByDay || g == GroupByWeek || g == GroupByMonth
+}
+
+// Row is a single money movement
+type Row struct {
+	Time          time.Time
+	Amount        int64
+	Currency      string
+	Category      string
+	Recipient     string
+	RecipientCode string
+	Status        string
+}
+
+// Filter restricts which rows are aggregated; empty fields match everything
+type Filter struct {
+	Currency  string
+	Category  string
+	Status    string
+	Recipient string
+}
+
+// Match reports whether row passes the filter. Recipient matches the recipient
+// code exactly or the recipient name case-insensitively.
+func (f Filter) Match(row Row) bool {
+	if f.Currency != "" && row.Currency != "" && !strings.EqualFold(row.Currency, f.Currency) {
+		return false
+	}
+	if f.Category != "" && !strings.EqualFold(row.Category, f.Category) {
+		return false
+	}
+	if f.Status != "" && !strings.EqualFold(row.Status, f.Status) {
+		return false
+	}
+	if f.Recipient != "" && row.RecipientCode != f.Recipient && !strings.EqualFold(row.Recipient, f.Recipient) {
+		return false
+	}
+	return true
+}
+
+// Stats summarises a set of rows
+type Stats struct {
+	Sum     int64   `json:"sum"`
+	Count   int     `json:"count"`
+	Average float64 `json:"average"`
+}
+
+func (s *Stats) add(amount int64) {
+	s.Sum += amount
+	s.Count++
+	s.Average = float64(s.Sum) / float64(s.Count)
+}
+
+// Delta compares a period against the one before it
+type Delta struct {
+	Sum          int64    `json:"sum"`
+	SumPercent   *float64 `json:"sum_percent,omitempty"`
+	Count        int      `json:"count"`
+	CountPercent *float64 `json:"count_percent,omitempty"`
+	Average      float64  `json:"average"`
+}
+
+// Compare computes the change from previous to current
+func Compare(current, previous Stats) Delta {
+	return Delta{
+		Sum:          current.Sum - previous.Sum,
+		SumPercent:   percentChange(float64(current.Sum), float64(previous.Sum)),
+		Count:        current.Count - previous.Count,
+		CountPercent: percentChange(float64(current.Count), float64(previous.Count)),
+		Average:      current.Average - previous.Average,
+	}
+}
+
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	p := (current - previous) / previous * 100
+	return &p
+}
+
+// Group is one bucket of the report
+type Group struct {
+	Key      string     `json:"key"`
+	Start    *time.Time `json:"start,omitempty"`
+	Stats               // current totals
+	Previous Stats      `json:"previous"`
+	Delta    Delta      `json:"delta"`
+}
+
+// Report is the result of Aggregate
+type Report struct {
+	GroupBy      GroupBy   `json:"group_by"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	PreviousFrom time.Time `json:"previous_from"`
+	PreviousTo   time.Time `json:"previous_to"`
+	Totals       Stats     `json:"totals"`
+	Previous     Stats     `json:"previous"`
+	Delta        Delta     `json:"delta"`
+	Groups       []Group   `json:"groups"`
+}
+
+// PreviousRange returns the range of equal length ending where [from, to) starts
+func PreviousRange(from, to time.Time) (time.Time, time.Time) {
+	return from.Add(-to.Sub(from)), from
+}
+
+// Aggregate groups rows in [from, to) and compares them with the previous range.
+// Rows outside [previousFrom, to) or rejected by filter are ignored.
+func Aggregate(rows []Row, groupBy GroupBy, from, to time.Time, filter Filter) *Report {
+	previousFrom, previousTo := PreviousRange(from, to)
+	report := &Report{
+		GroupBy:      groupBy,
+		From:         from,
+		To:           to,
+		PreviousFrom: previousFrom,
+		PreviousTo:   previousTo,
+		Groups:       []Group{},
+	}
+
+	var current, previous []Row
+	for _, row := range rows {
+		if !filter.Match(row) {
+			continue
+		}
+		switch {
+		case !row.Time.Before(from) && row.Time.Before(to):
+			current = append(current, row)
+			report.Totals.add(row.Amount)
+		case !row.Time.Before(previousFrom) && row.Time.Before(previousTo):
+			previous = append(previous, row)
+			report.Previous.add(row.Amount)
+		}
+	}
+	report.Delta = Compare(report.Totals, report.Previous)
+
+	if groupBy.IsTime() {
+		report.Groups = timeGroups(current, previous, groupBy, from, to)
+	} else {
+		report.Groups = keyGroups(current, previous, groupBy)
+	}
+	return report
+}
+
+// timeGroups buckets rows into contiguous periods; each bucket's previous is the
+// bucket before it. Only current rows count towards current buckets, so a
+// partial first bucket never includes rows from before from.
+func timeGroups(current, previous []Row, groupBy GroupBy, from, to time.Time) []Group {
+	bucket := func(rows ...[]Row) map[time.Time]*Stats {
+		m := map[time.Time]*Stats{}
+		for _, set := range rows {
+			for _, row := range set {
+				start := BucketStart(row.Time, groupBy)
+				if m[start] == nil {
+					m[start] = &Stats{}
+				}
+				m[start].add(row.Amount)
+			}
+		}
+		return m
+	}
+
+	cur := bucket(current)
+	all := bucket(previous, current)
+	stats := func(m map[time.Time]*Stats, start time.Time) Stats {
+		if s := m[start]; s != nil {
+			return *s
+		}
+		return Stats{}
+	}
+
+	groups := []Group{}
+	for start := BucketStart(from, groupBy); start.Before(to); start = nextBucket(start, groupBy) {
+		c := stats(cur, start)
+		p := stats(all, previousBucket(start, groupBy))
+		s := start
+		groups = append(groups, Group{
+			Key:      bucketKey(start, groupBy),
+			Start:    &s,
+			Stats:    c,
+			Previous: p,
+			Delta:    Compare(c, p),
+		})
+	}
+	return groups
+}
+
+// keyGroups buckets rows by an attribute, largest sum first
+func keyGroups(current, previous []Row, groupBy GroupBy) []Group {
+	byKey := func(rows []Row) map[string]*Stats {
+		m := map[string]*Stats{}
+		for _, row := range rows {
+			key := rowKey(row, groupBy)
+			if m[key] == nil {
+				m[key] = &Stats{}
+			}
+			m[key].add(row.Amount)
+		}
+		return m
+	}
+
+	cur := byKey(current)
+	prev := byKey(previous)
+
+	groups := make([]Group, 0, len(cur))
+	for key, stats := range cur {
+		var p Stats
+		if ps := prev[key]; ps != nil {
+			p = *ps
+		}
+		groups = append(groups, Group{Key: key, Stats: *stats, Previous: p, Delta: Compare(*stats, p)})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Sum != groups[j].Sum {
+			return groups[i].Sum > groups[j].Sum
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+func rowKey(row Row, groupBy GroupBy) string {
+	var key string
+	switch groupBy {
+	case GroupByCategory:
+		key = row.Category
+	case GroupByRecipient:
+		key = row.Recipient
+		if key == "" {
+			key = row.RecipientCode
+		}
+	case GroupByStatus:
+		key = row.Status
+	}
+	if key == "" {
+		return "uncategorized"
+	}
+	return key
+}
+
+// BucketStart truncates t (in UTC) to the start of its day, week or month
+func BucketStart(t time.Time, groupBy GroupBy) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch groupBy {
+	case GroupByWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case GroupByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+func nextBucket(start time.Time, groupBy GroupBy) time.Time {
+	switch groupBy {
+	case GroupByWeek:
+		return start.AddDate(0, 0, 7)
+	case GroupByMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+func previousBucket(start time.Time, groupBy GroupBy) time.Time {
+	switch groupBy {
+	case GroupByWeek:
+		return start.AddDate(0, 0, -7)
+	case GroupByMonth:
+		return start.AddDate(0, -1, 0)
+	default:
+		return start.AddDate(0, 0, -1)
+	}
+}
+
+func bucketKey(start time.Time, groupBy GroupBy) string {
+	if groupBy == GroupByMonth {
+		return start.Format("2006-01")
+	}
+	return start.Format("2006-01-02")
+}
+
+// DefaultGroupBy picks a time grouping that keeps the number of buckets readable
+func DefaultGroupBy(from, to time.Time) GroupBy {
+	switch days := to.Sub(from).Hours() / 24; {
+	case days <= 31:
+		return GroupByDay
+	case days <= 120:
+		return GroupByWeek
+	default:
+		return GroupByMonth
+	}
+}
@@ -0,0 +1,106 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+func day(d int, hour int) time.Time {
+	return time.Date(2026, 3, d, hour, 0, 0, 0, time.UTC)
+}
+
+func TestAggregateByDayWithDeltas(t *testing.T) {
+	rows := []Row{
+		{Time: day(1, 10), Amount: 1000, Currency: "NGN"}, // previous range
+		{Time: day(3, 9), Amount: 2000, Currency: "NGN"},  // previous range, bucket before Mar 4
+		{Time: day(4, 8), Amount: 3000, Currency: "NGN"},
+		{Time: day(4, 20), Amount: 1000, Currency: "NGN"},
+		{Time: day(6, 12), Amount: 5000, Currency: "NGN"},
+		{Time: day(6, 13), Amount: 9999, Currency: "USD"}, // filtered by currency
+		{Time: day(7, 0), Amount: 7000, Currency: "NGN"},  // outside [from, to)
+	}
+
+	report := Aggregate(rows, GroupByDay, day(4, 0), day(7, 0), Filter{Currency: "NGN"})
+
+	if report.Totals.Sum != 9000 || report.Totals.Count != 3 || report.Totals.Average != 3000 {
+		t.Fatalf("Unexpected totals: %+v", report.Totals)
+	}
+	if report.Previous.Sum != 3000 || report.Previous.Count != 2 {
+		t.Fatalf("Unexpected previous totals: %+v", report.Previous)
+	}
+	if report.Delta.Sum != 6000 || report.Delta.SumPercent == nil || *report.Delta.SumPercent != 200 {
+		t.Fatalf("Unexpected delta: %+v", report.Delta)
+	}
+
+	want := []struct {
+		key      string
+		sum      int64
+		previous int64
+	}{
+		{"2026-03-04", 4000, 2000},
+		{"2026-03-05", 0, 4000},
+		{"2026-03-06", 5000, 0},
+	}
+	if len(report.Groups) != len(want) {
+		t.Fatalf("Expected %d groups, got %d", len(want), len(report.Groups))
+	}
+	for i, w := range want {
+		g := report.Groups[i]
+		if g.Key != w.key || g.Sum != w.sum || g.Previous.Sum != w.previous {
+			t.Errorf("Group %d: expected %s sum=%d prev=%d, got %s sum=%d prev=%d",
+				i, w.key, w.sum, w.previous, g.Key, g.Sum, g.Previous.Sum)
+		}
+	}
+	if report.Groups[2].Delta.SumPercent != nil {
+		t.Errorf("Expected no percentage delta against an empty previous bucket")
+	}
+}
+
+func TestAggregateByCategory(t *testing.T) {
+	rows := []Row{
+		{Time: day(2, 0), Amount: 500, Category: "pets"},
+		{Time: day(10, 0), Amount: 1500, Category: "pets"},
+		{Time: day(11, 0), Amount: 4000, Category: "technology"},
+		{Time: day(12, 0), Amount: 100},
+	}
+
+	report := Aggregate(rows, GroupByCategory, day(8, 0), day(15, 0), Filter{})
+
+	keys := []string{"technology", "pets", "uncategorized"}
+	if len(report.Groups) != len(keys) {
+		t.Fatalf("Expected %d groups, got %+v", len(keys), report.Groups)
+	}
+	for i, key := range keys {
+		if report.Groups[i].Key != key {
+			t.Errorf("Group %d: expected %s, got %s", i, key, report.Groups[i].Key)
+		}
+	}
+	if pets := report.Groups[1]; pets.Previous.Sum != 500 || pets.Delta.Sum != 1000 {
+		t.Errorf("Unexpected pets comparison: %+v", pets)
+	}
+}
+
+func TestBucketStart(t *testing.T) {
+	// 2026-03-04 is a Wednesday
+	ts := time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC)
+
+	if got := BucketStart(ts, GroupByWeek); !got.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected week to start Monday 2026-03-02, got %s", got)
+	}
+	if got := BucketStart(ts, GroupByMonth); !got.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected month start 2026-03-01, got %s", got)
+	}
+}
+
+func TestFilterMatchesRecipientByCodeOrName(t *testing.T) {
+	row := Row{Recipient: "Ada Obi", RecipientCode: "RCP_1"}
+
+	for _, recipient := range []string{"RCP_1", "ada obi"} {
+		if !(Filter{Recipient: recipient}).Match(row) {
+			t.Errorf("Expected %q to match", recipient)
+		}
+	}
+	if (Filter{Recipient: "RCP_2"}).Match(row) {
+		t.Error("Expected RCP_2 not to match")
+	}
+}
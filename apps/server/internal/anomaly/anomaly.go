@@ -0,0 +1,260 @@
+// Package anomaly spots payments that look wrong before they go out: the same
+// payment made twice in quick succession, an amount far above what is usually
+// paid to that recipient or for that category, and a large first payment to
+// someone never paid before.
+//
+// Detect never blocks anything. It returns warnings for the caller to show the
+// user and to record for review.
+//
+// DESIGN DECISIONS:
+//   - Detection is pure: callers load the history, so rules are easy to test
+//   - Only history in the payment's currency is compared; amounts in different
+//     currencies say nothing about each other
+//   - "Far above" needs both a statistical and a practical margin: more than
+//     StdDevs standard deviations over the mean AND more than MedianMultiple times
+//     the median. The first alone fires on recipients always paid the same amount;
+//     the second alone fires on naturally spread-out amounts
+//   - Too little history (fewer than MinHistory payments) means no outlier
+//     warning at all rather than a guess
+//   - Narrations match ignoring case, spacing and punctuation, so "Fuel - Oct" and
+//     "fuel oct" are the same payment
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+// Warning kinds
+const (
+	KindDuplicate         = "duplicate"
+	KindRecipientOutlier  = "recipient_outlier"
+	KindCategoryOutlier   = "category_outlier"
+	KindFirstLargePayment = "first_large_payment"
+)
+
+// Payment is a payment about to be made, or one already made
+type Payment struct {
+	Reference     string
+	RecipientCode string
+	RecipientName string
+	money.Money
+	Narration string
+	Category  string
+	At        time.Time
+}
+
+// Config tunes detection
+type Config struct {
+	// DuplicateWindow is how far back an identical payment counts as a duplicate
+	DuplicateWindow time.Duration
+	// MinHistory is the fewest past payments an outlier check needs
+	MinHistory int
+	// StdDevs is how many standard deviations over the mean is unusual
+	StdDevs float64
+	// MedianMultiple is how many times the median is unusual
+	MedianMultiple float64
+	// FirstPaymentThreshold flags a first payment to a recipient at or above it.
+	// It only applies in its own currency; zero disables the check.
+	FirstPaymentThreshold money.Money
+}
+
+// DefaultConfig is used for anything left zero
+var DefaultConfig = Config{
+	DuplicateWindow:       10 * time.Minute,
+	MinHistory:            5,
+	StdDevs:               3,
+	MedianMultiple:        3,
+	FirstPaymentThreshold: money.New(50000000, money.NGN), // ₦500,000
+}
+
+// withDefaults fills zero fields from DefaultConfig
+func (c Config) withDefaults() Config {
+	if c.DuplicateWindow <= 0 {
+		c.DuplicateWindow = DefaultConfig.DuplicateWindow
+	}
+	if c.MinHistory <= 0 {
+		c.MinHistory = DefaultConfig.MinHistory
+	}
+	if c.StdDevs <= 0 {
+		c.StdDevs = DefaultConfig.StdDevs
+	}
+	if c.MedianMultiple <= 0 {
+		c.MedianMultiple = DefaultConfig.MedianMultiple
+	}
+	return c
+}
+
+// Warning is one reason a payment looks unusual
+type Warning struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	// MatchedReference and MatchedAt identify the earlier payment a duplicate repeats
+	MatchedReference string     `json:"matched_reference,omitempty"`
+	MatchedAt        *time.Time `json:"matched_at,omitempty"`
+	// Typical is the median of the history an outlier was compared with
+	Typical int64 `json:"typical,omitempty"`
+	// Threshold is the amount the payment went over
+	Threshold int64 `json:"threshold,omitempty"`
+	// History is how many past payments were compared
+	History int `json:"history,omitempty"`
+}
+
+// History is what a payment is compared against
+type History struct {
+	// Recipient holds past payments to the same recipient
+	Recipient []Payment
+	// Category holds past payments in the same category (expenses only)
+	Category []Payment
+}
+
+// Detect checks p against its history and returns any warnings, most serious first
+func Detect(p Payment, history History, cfg Config) []Warning {
+	cfg = cfg.withDefaults()
+	p.Currency = p.Currency.OrDefault()
+	warnings := []Warning{}
+
+	if w, ok := duplicate(p, history.Recipient, cfg.DuplicateWindow); ok {
+		warnings = append(warnings, w)
+	}
+
+	recipient := amountsIn(history.Recipient, p.Currency)
+	if len(history.Recipient) == 0 {
+		threshold := cfg.FirstPaymentThreshold
+		if threshold.Amount > 0 && threshold.Currency.OrDefault() == p.Currency && p.Amount >= threshold.Amount {
+			warnings = append(warnings, Warning{
+				Kind:      KindFirstLargePayment,
+				Message:   fmt.Sprintf("This is your first payment to %s, and %s is a large amount to start with.", p.name(), p.Format()),
+				Threshold: threshold.Minor(),
+			})
+		}
+	} else if w, ok := outlier(p.Minor(), recipient, cfg); ok {
+		w.Kind = KindRecipientOutlier
+		w.Message = fmt.Sprintf("%s is far more than you usually pay %s (typically %s).", p.Format(), p.name(), money.New(w.Typical, p.Currency).Format())
+		warnings = append(warnings, w)
+	}
+
+	if p.Category != "" {
+		if w, ok := outlier(p.Minor(), amountsIn(history.Category, p.Currency), cfg); ok {
+			w.Kind = KindCategoryOutlier
+			w.Message = fmt.Sprintf("%s is far more than you usually spend on %s (typically %s).", p.Format(), p.Category, money.New(w.Typical, p.Currency).Format())
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// name is how the recipient is referred to in messages
+func (p Payment) name() string {
+	if p.RecipientName != "" {
+		return p.RecipientName
+	}
+	return p.RecipientCode
+}
+
+// duplicate finds the latest earlier payment with the same amount and narration within window
+func duplicate(p Payment, past []Payment, window time.Duration) (Warning, bool) {
+	narration := normalize(p.Narration)
+	var match *Payment
+	for i := range past {
+		prev := &past[i]
+		if prev.Currency.OrDefault() != p.Currency || prev.Amount != p.Amount || normalize(prev.Narration) != narration {
+			continue
+		}
+		if prev.At.After(p.At) || p.At.Sub(prev.At) > window {
+			continue
+		}
+		if match == nil || prev.At.After(match.At) {
+			match = prev
+		}
+	}
+	if match == nil {
+		return Warning{}, false
+	}
+
+	at := match.At
+	return Warning{
+		Kind:             KindDuplicate,
+		Message:          fmt.Sprintf("You paid %s %s for the same thing %s ago. This may be a duplicate.", p.name(), p.Format(), ago(p.At.Sub(at))),
+		MatchedReference: match.Reference,
+		MatchedAt:        &at,
+	}, true
+}
+
+// amountsIn returns the amounts of the payments in currency
+func amountsIn(payments []Payment, currency money.Currency) []int64 {
+	var amounts []int64
+	for _, p := range payments {
+		if p.Currency.OrDefault() == currency {
+			amounts = append(amounts, p.Minor())
+		}
+	}
+	return amounts
+}
+
+// outlier reports whether amount is far above the amounts, filling in what it was compared with
+func outlier(amount int64, amounts []int64, cfg Config) (Warning, bool) {
+	if len(amounts) < cfg.MinHistory {
+		return Warning{}, false
+	}
+
+	var sum float64
+	for _, a := range amounts {
+		sum += float64(a)
+	}
+	mean := sum / float64(len(amounts))
+	var squares float64
+	for _, a := range amounts {
+		squares += (float64(a) - mean) * (float64(a) - mean)
+	}
+	stddev := math.Sqrt(squares / float64(len(amounts)))
+	median := Median(amounts)
+
+	threshold := math.Max(mean+cfg.StdDevs*stddev, float64(median)*cfg.MedianMultiple)
+	if float64(amount) <= threshold {
+		return Warning{}, false
+	}
+	return Warning{Typical: median, Threshold: int64(math.Ceil(threshold)), History: len(amounts)}, true
+}
+
+// Median is the middle amount, or the mean of the middle two
+func Median(amounts []int64) int64 {
+	if len(amounts) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), amounts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// normalize lowercases a narration and keeps only its letters and digits, one space apart
+func normalize(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// ago speaks a short duration, e.g. "2 minutes" or "less than a minute"
+func ago(d time.Duration) string {
+	switch minutes := int(d.Minutes()); {
+	case minutes < 1:
+		return "less than a minute"
+	case minutes == 1:
+		return "1 minute"
+	case minutes < 120:
+		return fmt.Sprintf("%d minutes", minutes)
+	default:
+		return fmt.Sprintf("%d hours", minutes/60)
+	}
+}
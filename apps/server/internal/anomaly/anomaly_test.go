@@ -0,0 +1,123 @@
+package anomaly
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+var now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func payment(amount int64, narration string, ago time.Duration) Payment {
+	return Payment{
+		Reference:     "ref-" + narration,
+		RecipientCode: "RCP_1",
+		RecipientName: "Total",
+		Money:         money.New(amount, money.NGN),
+		Narration:     narration,
+		At:            now.Add(-ago),
+	}
+}
+
+func kinds(warnings []Warning) string {
+	var out []string
+	for _, w := range warnings {
+		out = append(out, w.Kind)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestDetectDuplicate(t *testing.T) {
+	p := payment(50000, "Fuel - Oct", 0)
+	past := []Payment{
+		payment(50000, "fuel oct", 3*time.Minute),
+		payment(50000, "fuel oct", 8*time.Minute),
+		payment(50000, "fuel oct", 20*time.Minute), // outside the window
+	}
+	warnings := Detect(p, History{Recipient: past}, Config{})
+	if kinds(warnings) != KindDuplicate {
+		t.Fatalf("Expected one duplicate warning, got %+v", warnings)
+	}
+	w := warnings[0]
+	if !w.MatchedAt.Equal(now.Add(-3*time.Minute)) || !strings.Contains(w.Message, "3 minutes ago") {
+		t.Errorf("Expected the latest match, got %+v", w)
+	}
+}
+
+func TestDetectDuplicateNeedsSameDetails(t *testing.T) {
+	p := payment(50000, "fuel", 0)
+	past := []Payment{
+		payment(50001, "fuel", time.Minute),
+		payment(50000, "diesel", time.Minute),
+		{RecipientCode: "RCP_1", Money: money.New(50000, money.USD), Narration: "fuel", At: now.Add(-time.Minute)},
+	}
+	if warnings := Detect(p, History{Recipient: past}, Config{}); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %+v", warnings)
+	}
+}
+
+func TestDetectRecipientOutlier(t *testing.T) {
+	var past []Payment
+	for _, amount := range []int64{40000, 45000, 50000, 55000, 60000} {
+		past = append(past, payment(amount, "fuel", 48*time.Hour))
+	}
+
+	if warnings := Detect(payment(90000, "fuel", 0), History{Recipient: past}, Config{}); len(warnings) != 0 {
+		t.Errorf("Expected a somewhat larger payment to pass, got %+v", warnings)
+	}
+
+	warnings := Detect(payment(500000, "fuel", 0), History{Recipient: past}, Config{})
+	if kinds(warnings) != KindRecipientOutlier {
+		t.Fatalf("Expected a recipient outlier, got %+v", warnings)
+	}
+	if w := warnings[0]; w.Typical != 50000 || w.History != 5 || !strings.Contains(w.Message, "typically ₦500") {
+		t.Errorf("Unexpected warning %+v", w)
+	}
+}
+
+func TestDetectOutlierNeedsHistory(t *testing.T) {
+	past := []Payment{payment(1000, "a", time.Hour), payment(1000, "b", time.Hour)}
+	if warnings := Detect(payment(900000, "c", 0), History{Recipient: past}, Config{}); len(warnings) != 0 {
+		t.Errorf("Expected too little history to stay quiet, got %+v", warnings)
+	}
+}
+
+func TestDetectCategoryOutlier(t *testing.T) {
+	var category []Payment
+	for i := 0; i < 6; i++ {
+		category = append(category, payment(20000, "groceries", time.Duration(i+1)*24*time.Hour))
+	}
+	p := payment(300000, "groceries", 0)
+	p.Category = "food"
+	warnings := Detect(p, History{Recipient: category[:1], Category: category}, Config{})
+	if kinds(warnings) != KindCategoryOutlier || !strings.Contains(warnings[0].Message, "on food") {
+		t.Errorf("Expected a category outlier, got %+v", warnings)
+	}
+}
+
+func TestDetectFirstLargePayment(t *testing.T) {
+	cfg := Config{FirstPaymentThreshold: money.New(100000, money.NGN)}
+	if kinds(Detect(payment(150000, "deposit", 0), History{}, cfg)) != KindFirstLargePayment {
+		t.Error("Expected a large first payment to be flagged")
+	}
+	if warnings := Detect(payment(50000, "deposit", 0), History{}, cfg); len(warnings) != 0 {
+		t.Errorf("Expected a small first payment to pass, got %+v", warnings)
+	}
+
+	usd := payment(150000, "deposit", 0)
+	usd.Currency = money.USD
+	if warnings := Detect(usd, History{}, cfg); len(warnings) != 0 {
+		t.Errorf("Expected the threshold to apply only in its own currency, got %+v", warnings)
+	}
+	if warnings := Detect(payment(150000, "deposit", 0), History{Recipient: []Payment{payment(1000, "x", 72*time.Hour)}}, cfg); len(warnings) != 0 {
+		t.Errorf("Expected a known recipient not to count as first, got %+v", warnings)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	if Median([]int64{5, 1, 3}) != 3 || Median([]int64{4, 1, 3, 2}) != 2 || Median(nil) != 0 {
+		t.Error("Unexpected median")
+	}
+}
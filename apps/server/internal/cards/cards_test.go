@@ -0,0 +1,110 @@
+package cards
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimulatedIssuerIssuesMaskedLuhnValidCards(t *testing.T) {
+	issuer := NewSimulatedIssuer()
+	expiry := time.Date(2028, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	card, err := issuer.Issue(IssueRequest{Label: "Ads", Currency: "NGN", ExpiresAt: expiry})
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+
+	if !strings.HasPrefix(card.MaskedPAN, simulatedBIN) || !strings.HasSuffix(card.MaskedPAN, card.Last4) {
+		t.Errorf("Unexpected masked PAN %q for last4 %q", card.MaskedPAN, card.Last4)
+	}
+	if strings.Count(card.MaskedPAN, "*") != 6 {
+		t.Errorf("Expected 6 masked digits, got %q", card.MaskedPAN)
+	}
+	if card.ExpMonth != 6 || card.ExpYear != 2028 {
+		t.Errorf("Unexpected expiry %d/%d", card.ExpMonth, card.ExpYear)
+	}
+
+	if err := issuer.Freeze(card.ExternalID); err != nil {
+		t.Errorf("Freeze failed: %v", err)
+	}
+	if err := issuer.Freeze("OTHER_1"); err == nil {
+		t.Error("Expected freezing a foreign card to fail")
+	}
+
+	for i := 0; i < 50; i++ {
+		pan, err := simulatedPAN()
+		if err != nil {
+			t.Fatalf("simulatedPAN failed: %v", err)
+		}
+		if len(pan) != 16 || !ValidLuhn(pan) {
+			t.Fatalf("Generated invalid PAN %q", pan)
+		}
+	}
+}
+
+func TestValidLuhn(t *testing.T) {
+	if !ValidLuhn("4111111111111111") {
+		t.Error("Expected the Visa test number to pass")
+	}
+	if ValidLuhn("4111111111111112") {
+		t.Error("Expected a bad check digit to fail")
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	card := CardState{
+		Status:    StatusActive,
+		Currency:  "NGN",
+		ExpiresAt: now.AddDate(1, 0, 0),
+		Controls: Controls{
+			PerTransactionLimit: 50000,
+			SpendLimit:          100000,
+			SpendPeriod:         PeriodMonthly,
+			BlockedCategories:   []string{"gambling"},
+		},
+	}
+	payment := Authorization{Amount: 30000, Currency: "NGN", MerchantCategory: "software"}
+
+	tests := []struct {
+		name   string
+		mutate func(c *CardState, a *Authorization, spent *int)
+		reason string
+	}{
+		{"approved", func(*CardState, *Authorization, *int) {}, ""},
+		{"frozen", func(c *CardState, _ *Authorization, _ *int) { c.Status = StatusFrozen }, "frozen"},
+		{"expired", func(c *CardState, _ *Authorization, _ *int) { c.ExpiresAt = now }, "expired"},
+		{"currency", func(_ *CardState, a *Authorization, _ *int) { a.Currency = "USD" }, "only accepts NGN"},
+		{"blocked category", func(_ *CardState, a *Authorization, _ *int) { a.MerchantCategory = "Gambling" }, "blocked"},
+		{"not allowed category", func(c *CardState, _ *Authorization, _ *int) { c.Controls.AllowedCategories = []string{"travel"} }, "not allowed"},
+		{"per transaction", func(_ *CardState, a *Authorization, _ *int) { a.Amount = 60000 }, "per-transaction"},
+		{"period limit", func(_ *CardState, _ *Authorization, s *int) { *s = 80000 }, "monthly spend limit"},
+	}
+
+	for _, tt := range tests {
+		c, a, spent := card, payment, 0
+		c.Controls.AllowedCategories = nil
+		tt.mutate(&c, &a, &spent)
+
+		approved, reason := Authorize(c, a, spent, now)
+		if tt.reason == "" {
+			if !approved {
+				t.Errorf("%s: expected approval, got %q", tt.name, reason)
+			}
+			continue
+		}
+		if approved || !strings.Contains(reason, tt.reason) {
+			t.Errorf("%s: expected decline containing %q, got approved=%v reason=%q", tt.name, tt.reason, approved, reason)
+		}
+	}
+}
+
+func TestControlsValidate(t *testing.T) {
+	if err := (Controls{SpendPeriod: "weekly"}).Validate(); err == nil {
+		t.Error("Expected unknown period to fail")
+	}
+	if err := (Controls{AllowedCategories: []string{"food"}, BlockedCategories: []string{"FOOD"}}).Validate(); err == nil {
+		t.Error("Expected overlapping categories to fail")
+	}
+}
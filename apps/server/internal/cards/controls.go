@@ -0,0 +1,142 @@
+package cards
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+// Controls are the spend rules attached to a card. Zero values mean "no rule".
+type Controls struct {
+	PerTransactionLimit int      `json:"per_transaction_limit"`
+	SpendLimit          int      `json:"spend_limit"`
+	SpendPeriod         string   `json:"spend_period"`
+	AllowedCategories   []string `json:"allowed_categories"`
+	BlockedCategories   []string `json:"blocked_categories"`
+}
+
+// Validate checks the controls are internally consistent
+func (c Controls) Validate() error {
+	if c.PerTransactionLimit < 0 || c.SpendLimit < 0 {
+		return fmt.Errorf("limits cannot be negative")
+	}
+	if c.SpendPeriod != "" && !validPeriod(c.SpendPeriod) {
+		return fmt.Errorf("spend_period must be one of: %s", strings.Join(SpendPeriods, ", "))
+	}
+	for _, allowed := range c.AllowedCategories {
+		if containsFold(c.BlockedCategories, allowed) {
+			return fmt.Errorf("category %q cannot be both allowed and blocked", allowed)
+		}
+	}
+	return nil
+}
+
+// CardState is the part of a card that authorization depends on
+type CardState struct {
+	Status    string
+	Currency  string
+	ExpiresAt time.Time
+	Controls  Controls
+}
+
+// Authorization is a card payment attempt
+type Authorization struct {
+	Amount           int
+	Currency         string
+	MerchantName     string
+	MerchantCategory string
+}
+
+// Authorize applies the card's state and controls to a payment attempt.
+// periodSpent is what the card has already spent in the current spend period.
+// It returns an empty reason when the payment is approved.
+func Authorize(card CardState, auth Authorization, periodSpent int, now time.Time) (bool, string) {
+	switch card.Status {
+	case StatusActive:
+	case StatusFrozen:
+		return false, "card is frozen"
+	default:
+		return false, fmt.Sprintf("card is %s", card.Status)
+	}
+
+	if !card.ExpiresAt.IsZero() && !now.Before(card.ExpiresAt) {
+		return false, "card has expired"
+	}
+
+	if auth.Amount <= 0 {
+		return false, "amount must be greater than 0"
+	}
+
+	if !strings.EqualFold(auth.Currency, card.Currency) {
+		return false, fmt.Sprintf("card only accepts %s", card.Currency)
+	}
+
+	c := card.Controls
+	format := func(minor int) string {
+		return money.New(int64(minor), money.Currency(strings.ToUpper(card.Currency))).Format()
+	}
+	if containsFold(c.BlockedCategories, auth.MerchantCategory) {
+		return false, fmt.Sprintf("merchant category %q is blocked on this card", auth.MerchantCategory)
+	}
+	if len(c.AllowedCategories) > 0 && !containsFold(c.AllowedCategories, auth.MerchantCategory) {
+		return false, fmt.Sprintf("merchant category %q is not allowed on this card", auth.MerchantCategory)
+	}
+
+	if c.PerTransactionLimit > 0 && auth.Amount > c.PerTransactionLimit {
+		return false, fmt.Sprintf("amount exceeds the per-transaction limit of %s", format(c.PerTransactionLimit))
+	}
+
+	if c.SpendLimit > 0 {
+		if c.SpendPeriod == PeriodPerTransaction {
+			if auth.Amount > c.SpendLimit {
+				return false, fmt.Sprintf("amount exceeds the per-transaction limit of %s", format(c.SpendLimit))
+			}
+		} else if periodSpent+auth.Amount > c.SpendLimit {
+			return false, fmt.Sprintf("amount exceeds the %s spend limit of %s (%s remaining)",
+				periodLabel(c.SpendPeriod), format(c.SpendLimit), format(max(c.SpendLimit-periodSpent, 0)))
+		}
+	}
+
+	return true, ""
+}
+
+// PeriodStart is when the current spend period began. Lifetime and
+// per-transaction periods return the zero time.
+func PeriodStart(period string, now time.Time) time.Time {
+	now = now.UTC()
+	switch period {
+	case PeriodDaily:
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	case PeriodMonthly:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Time{}
+	}
+}
+
+func periodLabel(period string) string {
+	if period == "" {
+		return PeriodLifetime
+	}
+	return period
+}
+
+func validPeriod(period string) bool {
+	for _, p := range SpendPeriods {
+		if p == period {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
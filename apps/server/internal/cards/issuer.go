@@ -0,0 +1,61 @@
+// Package cards implements virtual card issuing and spend controls.
+//
+// Paystack does not issue cards, so issuing sits behind the Issuer interface.
+// SimulatedIssuer is a local implementation for development and tests; a real
+// card programme plugs in by implementing the same interface.
+//
+// DESIGN DECISIONS:
+// - Full card numbers never leave the issuer; only the last four digits and a masked PAN are stored
+// - Spend controls are evaluated locally by Authorize so every issuer enforces the same rules
+// - Budget and transfer-limit checks happen in the handler, after the card's own controls pass
+// - All amounts are minor units (kobo for NGN)
+package cards
+
+import "time"
+
+// Card statuses
+const (
+	StatusActive     = "active"
+	StatusFrozen     = "frozen"
+	StatusTerminated = "terminated"
+)
+
+// Spend periods for SpendLimit
+const (
+	PeriodPerTransaction = "per_transaction"
+	PeriodDaily          = "daily"
+	PeriodMonthly        = "monthly"
+	PeriodLifetime       = "lifetime"
+)
+
+// SpendPeriods lists the supported spend periods
+var SpendPeriods = []string{PeriodPerTransaction, PeriodDaily, PeriodMonthly, PeriodLifetime}
+
+// IssueRequest asks an issuer for a new card
+type IssueRequest struct {
+	Label     string
+	Currency  string
+	ExpiresAt time.Time
+}
+
+// IssuedCard is what an issuer returns for a new card
+type IssuedCard struct {
+	ExternalID string
+	Brand      string
+	Last4      string
+	MaskedPAN  string
+	ExpMonth   int
+	ExpYear    int
+}
+
+// Issuer creates cards and changes their state with the card programme
+type Issuer interface {
+	// Name identifies the issuer in stored cards
+	Name() string
+	// Issue creates a new card
+	Issue(req IssueRequest) (*IssuedCard, error)
+	// Freeze blocks all authorizations on a card
+	Freeze(externalID string) error
+	// Unfreeze re-enables a frozen card
+	Unfreeze(externalID string) error
+}
@@ -0,0 +1,233 @@
+// Package categorize assigns categories to expenses.
+//
+// User-defined rules are tried first, highest priority first. When no rule
+// matches, the engine falls back to what the same recipient was categorized as
+// before, provided one category clearly dominates that recipient's history.
+//
+// DESIGN DECISIONS:
+//   - Categories are canonical slugs (e.g. ride_hailing) arranged in a tree, so
+//     reports can roll children up into their parent (transport)
+//   - Rules match one expense field with a case-insensitive operator; regular
+//     expressions are compiled once when the engine is built
+//   - Ties in priority go to the oldest rule, so adding a rule never silently
+//     changes what an existing one catches
+//   - Learning only trusts categories people chose, never ones a rule assigned
+package categorize
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Sources of an assigned category
+const (
+	SourceManual  = "manual"
+	SourceRule    = "rule"
+	SourceLearned = "learned"
+)
+
+// Field is the expense field a rule looks at
+type Field string
+
+const (
+	FieldRecipientCode Field = "recipient_code"
+	FieldRecipientName Field = "recipient_name"
+	FieldNarration     Field = "narration"
+)
+
+// Fields lists the fields rules can match
+var Fields = []Field{FieldRecipientCode, FieldRecipientName, FieldNarration}
+
+// Operator is how a rule compares its value with the field
+type Operator string
+
+const (
+	OperatorEquals   Operator = "equals"
+	OperatorContains Operator = "contains"
+	OperatorPrefix   Operator = "prefix"
+	OperatorRegex    Operator = "regex"
+)
+
+// Operators lists the supported operators
+var Operators = []Operator{OperatorEquals, OperatorContains, OperatorPrefix, OperatorRegex}
+
+// Expense is what the engine knows about an expense being categorized
+type Expense struct {
+	RecipientCode string `json:"recipient_code,omitempty"`
+	RecipientName string `json:"recipient_name,omitempty"`
+	Narration     string `json:"narration,omitempty"`
+}
+
+func (e Expense) field(f Field) string {
+	switch f {
+	case FieldRecipientCode:
+		return e.RecipientCode
+	case FieldRecipientName:
+		return e.RecipientName
+	case FieldNarration:
+		return e.Narration
+	}
+	return ""
+}
+
+// Rule assigns Category to expenses whose Field matches Value
+type Rule struct {
+	ID       int      `json:"id"`
+	Name     string   `json:"name,omitempty"`
+	Field    Field    `json:"field"`
+	Operator Operator `json:"operator"`
+	Value    string   `json:"value"`
+	Category string   `json:"category"`
+	Priority int      `json:"priority"`
+	Enabled  bool     `json:"enabled"`
+}
+
+// Validate checks the rule's field, operator and value
+func (r Rule) Validate() error {
+	if !containsField(r.Field) {
+		return fmt.Errorf("field must be one of: recipient_code, recipient_name, narration")
+	}
+	if !containsOperator(r.Operator) {
+		return fmt.Errorf("operator must be one of: equals, contains, prefix, regex")
+	}
+	if strings.TrimSpace(r.Value) == "" {
+		return fmt.Errorf("value is required")
+	}
+	if r.Category == "" {
+		return fmt.Errorf("category is required")
+	}
+	if r.Operator == OperatorRegex {
+		if _, err := regexp.Compile("(?i)" + r.Value); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+	return nil
+}
+
+func containsField(f Field) bool {
+	for _, v := range Fields {
+		if v == f {
+			return true
+		}
+	}
+	return false
+}
+
+func containsOperator(o Operator) bool {
+	for _, v := range Operators {
+		if v == o {
+			return true
+		}
+	}
+	return false
+}
+
+// compiledRule is a rule ready to match
+type compiledRule struct {
+	Rule
+	value string
+	re    *regexp.Regexp
+}
+
+func (r compiledRule) match(e Expense) bool {
+	got := strings.TrimSpace(e.field(r.Field))
+	if got == "" {
+		return false
+	}
+	switch r.Operator {
+	case OperatorEquals:
+		return strings.EqualFold(got, r.value)
+	case OperatorContains:
+		return strings.Contains(strings.ToLower(got), r.value)
+	case OperatorPrefix:
+		return strings.HasPrefix(strings.ToLower(got), r.value)
+	case OperatorRegex:
+		return r.re.MatchString(got)
+	}
+	return false
+}
+
+// History counts the categories people gave each recipient's past expenses
+type History map[string]map[string]int
+
+// Add records one categorized expense
+func (h History) Add(recipientCode, category string, count int) {
+	if recipientCode == "" || category == "" {
+		return
+	}
+	if h[recipientCode] == nil {
+		h[recipientCode] = map[string]int{}
+	}
+	h[recipientCode][category] += count
+}
+
+// MinLearnedShare is the share of a recipient's history a category needs to be learned
+const MinLearnedShare = 0.6
+
+// Result is the category chosen for an expense. An empty Category means none applied.
+type Result struct {
+	Category   string  `json:"category"`
+	Source     string  `json:"source,omitempty"`
+	RuleID     int     `json:"rule_id,omitempty"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Engine categorizes expenses with rules, then history
+type Engine struct {
+	rules   []compiledRule
+	history History
+}
+
+// NewEngine prepares enabled, valid rules in priority order. Invalid rules are skipped.
+func NewEngine(rules []Rule, history History) *Engine {
+	e := &Engine{history: history}
+	for _, r := range rules {
+		if !r.Enabled || r.Validate() != nil {
+			continue
+		}
+		c := compiledRule{Rule: r, value: strings.ToLower(strings.TrimSpace(r.Value))}
+		if r.Operator == OperatorRegex {
+			c.re = regexp.MustCompile("(?i)" + r.Value)
+		}
+		e.rules = append(e.rules, c)
+	}
+	sort.SliceStable(e.rules, func(i, j int) bool {
+		if e.rules[i].Priority != e.rules[j].Priority {
+			return e.rules[i].Priority > e.rules[j].Priority
+		}
+		return e.rules[i].ID < e.rules[j].ID
+	})
+	return e
+}
+
+// Categorize picks a category for an expense
+func (e *Engine) Categorize(x Expense) Result {
+	for _, r := range e.rules {
+		if r.match(x) {
+			return Result{Category: r.Category, Source: SourceRule, RuleID: r.ID, Confidence: 1}
+		}
+	}
+	return e.learned(x.RecipientCode)
+}
+
+// learned returns the category that dominates a recipient's history
+func (e *Engine) learned(recipientCode string) Result {
+	counts := e.history[recipientCode]
+	total, best, bestCount := 0, "", 0
+	for category, n := range counts {
+		total += n
+		if n > bestCount || (n == bestCount && category < best) {
+			best, bestCount = category, n
+		}
+	}
+	if total == 0 {
+		return Result{}
+	}
+	share := float64(bestCount) / float64(total)
+	if share < MinLearnedShare {
+		return Result{}
+	}
+	return Result{Category: best, Source: SourceLearned, Confidence: share}
+}
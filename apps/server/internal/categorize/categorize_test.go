@@ -0,0 +1,97 @@
+package categorize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRulesMatchInPriorityOrder(t *testing.T) {
+	engine := NewEngine([]Rule{
+		{ID: 1, Field: FieldNarration, Operator: OperatorContains, Value: "uber", Category: "transport", Enabled: true},
+		{ID: 2, Field: FieldRecipientCode, Operator: OperatorEquals, Value: "RCP_ikedc", Category: "electricity", Priority: 10, Enabled: true},
+		{ID: 3, Field: FieldNarration, Operator: OperatorRegex, Value: `^uber eats\b`, Category: "dining", Priority: 5, Enabled: true},
+		{ID: 4, Field: FieldNarration, Operator: OperatorContains, Value: "uber", Category: "travel", Enabled: false},
+		{ID: 5, Field: FieldRecipientName, Operator: OperatorPrefix, Value: "MTN", Category: "airtime", Enabled: true},
+	}, nil)
+
+	tests := []struct {
+		name    string
+		expense Expense
+		want    Result
+	}{
+		{"contains ignores case", Expense{Narration: "UBER trip to Yaba"}, Result{Category: "transport", Source: SourceRule, RuleID: 1, Confidence: 1}},
+		{"higher priority wins", Expense{Narration: "Uber Eats lunch"}, Result{Category: "dining", Source: SourceRule, RuleID: 3, Confidence: 1}},
+		{"equals on recipient", Expense{RecipientCode: "rcp_IKEDC", Narration: "uber"}, Result{Category: "electricity", Source: SourceRule, RuleID: 2, Confidence: 1}},
+		{"prefix", Expense{RecipientName: "mtn nigeria"}, Result{Category: "airtime", Source: SourceRule, RuleID: 5, Confidence: 1}},
+		{"no match", Expense{Narration: "Lunch"}, Result{}},
+	}
+	for _, tt := range tests {
+		if got := engine.Categorize(tt.expense); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLearnedFallbackNeedsAClearMajority(t *testing.T) {
+	history := History{}
+	history.Add("RCP_ada", "groceries", 3)
+	history.Add("RCP_ada", "dining", 1)
+	history.Add("RCP_obi", "rent", 1)
+	history.Add("RCP_obi", "maintenance", 1)
+	engine := NewEngine([]Rule{{ID: 1, Field: FieldNarration, Operator: OperatorContains, Value: "rent", Category: "rent", Enabled: true}}, history)
+
+	if got := engine.Categorize(Expense{RecipientCode: "RCP_ada"}); got != (Result{Category: "groceries", Source: SourceLearned, Confidence: 0.75}) {
+		t.Errorf("Expected groceries learned at 0.75, got %+v", got)
+	}
+	if got := engine.Categorize(Expense{RecipientCode: "RCP_obi"}); got.Category != "" {
+		t.Errorf("Expected no category from a split history, got %+v", got)
+	}
+	if got := engine.Categorize(Expense{RecipientCode: "RCP_ada", Narration: "Rent for May"}); got.Source != SourceRule {
+		t.Errorf("Expected rules to beat history, got %+v", got)
+	}
+}
+
+func TestRuleValidation(t *testing.T) {
+	bad := []Rule{
+		{Field: "amount", Operator: OperatorEquals, Value: "1", Category: "other"},
+		{Field: FieldNarration, Operator: "like", Value: "x", Category: "other"},
+		{Field: FieldNarration, Operator: OperatorContains, Value: "  ", Category: "other"},
+		{Field: FieldNarration, Operator: OperatorRegex, Value: "(", Category: "other"},
+		{Field: FieldNarration, Operator: OperatorContains, Value: "x"},
+	}
+	for _, r := range bad {
+		if r.Validate() == nil {
+			t.Errorf("Expected %+v to be invalid", r)
+		}
+	}
+}
+
+func TestTaxonomy(t *testing.T) {
+	tax := NewTaxonomy(append(Defaults, Category{Slug: "bolt", Name: "Bolt rides", Parent: "ride_hailing"}))
+
+	if slug, ok := tax.Resolve("Dining Out"); !ok || slug != "dining" {
+		t.Errorf("Expected 'Dining Out' to resolve to dining, got %q", slug)
+	}
+	if slug, ok := tax.Resolve(" Ride-Hailing "); !ok || slug != "ride_hailing" {
+		t.Errorf("Expected 'Ride-Hailing' to resolve to ride_hailing, got %q", slug)
+	}
+	if _, ok := tax.Resolve("spaceships"); ok {
+		t.Error("Expected an unknown category not to resolve")
+	}
+
+	if got := tax.Path("bolt"); !reflect.DeepEqual(got, []string{"transport", "ride_hailing", "bolt"}) {
+		t.Errorf("Unexpected path %v", got)
+	}
+	if got := tax.Descendants("transport"); !reflect.DeepEqual(got, []string{"transport", "fuel", "public_transport", "ride_hailing", "bolt"}) {
+		t.Errorf("Unexpected descendants %v", got)
+	}
+
+	for _, node := range tax.Tree() {
+		if node.Parent != "" {
+			t.Errorf("Expected only roots at the top of the tree, got %s", node.Slug)
+		}
+	}
+	if got := Slugify("  Bank Fees & Charges "); got != "bank_fees_charges" {
+		t.Errorf("Unexpected slug %q", got)
+	}
+}
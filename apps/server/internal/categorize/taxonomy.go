@@ -0,0 +1,208 @@
+package categorize
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Category is a node in the category tree
+type Category struct {
+	Slug   string `json:"slug"`
+	Name   string `json:"name"`
+	Parent string `json:"parent,omitempty"`
+}
+
+// Defaults is the canonical category list every install starts with
+var Defaults = []Category{
+	{Slug: "food", Name: "Food"},
+	{Slug: "groceries", Name: "Groceries", Parent: "food"},
+	{Slug: "dining", Name: "Dining out", Parent: "food"},
+
+	{Slug: "transport", Name: "Transport"},
+	{Slug: "fuel", Name: "Fuel", Parent: "transport"},
+	{Slug: "ride_hailing", Name: "Ride hailing", Parent: "transport"},
+	{Slug: "public_transport", Name: "Public transport", Parent: "transport"},
+
+	{Slug: "housing", Name: "Housing"},
+	{Slug: "rent", Name: "Rent", Parent: "housing"},
+	{Slug: "maintenance", Name: "Repairs and maintenance", Parent: "housing"},
+
+	{Slug: "utilities", Name: "Utilities"},
+	{Slug: "electricity", Name: "Electricity", Parent: "utilities"},
+	{Slug: "water", Name: "Water", Parent: "utilities"},
+	{Slug: "internet", Name: "Internet", Parent: "utilities"},
+	{Slug: "airtime", Name: "Airtime and data", Parent: "utilities"},
+
+	{Slug: "health", Name: "Health"},
+	{Slug: "medical", Name: "Medical", Parent: "health"},
+	{Slug: "pharmacy", Name: "Pharmacy", Parent: "health"},
+
+	{Slug: "personal_care", Name: "Personal care"},
+	{Slug: "beauty", Name: "Beauty", Parent: "personal_care"},
+
+	{Slug: "technology", Name: "Technology"},
+	{Slug: "software", Name: "Software", Parent: "technology"},
+	{Slug: "hardware", Name: "Hardware", Parent: "technology"},
+
+	{Slug: "business", Name: "Business"},
+	{Slug: "salaries", Name: "Salaries", Parent: "business"},
+	{Slug: "office", Name: "Office", Parent: "business"},
+	{Slug: "professional_services", Name: "Professional services", Parent: "business"},
+	{Slug: "marketing", Name: "Marketing", Parent: "business"},
+
+	{Slug: "finance", Name: "Finance"},
+	{Slug: "fees", Name: "Bank fees", Parent: "finance"},
+	{Slug: "taxes", Name: "Taxes", Parent: "finance"},
+	{Slug: "loan_repayment", Name: "Loan repayment", Parent: "finance"},
+	{Slug: "savings", Name: "Savings", Parent: "finance"},
+
+	{Slug: "entertainment", Name: "Entertainment"},
+	{Slug: "subscriptions", Name: "Subscriptions", Parent: "entertainment"},
+
+	{Slug: "education", Name: "Education"},
+	{Slug: "travel", Name: "Travel"},
+	{Slug: "shopping", Name: "Shopping"},
+	{Slug: "family", Name: "Family and gifts"},
+	{Slug: "pets", Name: "Pets"},
+	{Slug: "other", Name: "Other"},
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// Slugify turns a category name into a slug ("Ride Hailing" → ride_hailing)
+func Slugify(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			underscore = false
+		case b.Len() > 0 && !underscore:
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// Validate checks the slug and name
+func (c Category) Validate() error {
+	if !slugPattern.MatchString(c.Slug) {
+		return fmt.Errorf("slug must be lowercase letters, digits and underscores, got %q", c.Slug)
+	}
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.Parent == c.Slug {
+		return fmt.Errorf("a category can't be its own parent")
+	}
+	return nil
+}
+
+// Taxonomy indexes categories by slug and parent
+type Taxonomy struct {
+	bySlug   map[string]Category
+	children map[string][]string
+}
+
+// NewTaxonomy builds the tree. Categories whose parent is unknown become roots.
+func NewTaxonomy(categories []Category) *Taxonomy {
+	t := &Taxonomy{bySlug: map[string]Category{}, children: map[string][]string{}}
+	for _, c := range categories {
+		t.bySlug[c.Slug] = c
+	}
+	for _, c := range categories {
+		if _, ok := t.bySlug[c.Parent]; ok && c.Parent != c.Slug {
+			t.children[c.Parent] = append(t.children[c.Parent], c.Slug)
+		}
+	}
+	for _, kids := range t.children {
+		sort.Strings(kids)
+	}
+	return t
+}
+
+// Get returns a category by slug
+func (t *Taxonomy) Get(slug string) (Category, bool) {
+	c, ok := t.bySlug[slug]
+	return c, ok
+}
+
+// Resolve maps free text onto a canonical slug by slug or name, ignoring case and spacing
+func (t *Taxonomy) Resolve(text string) (string, bool) {
+	slug := Slugify(text)
+	if _, ok := t.bySlug[slug]; ok {
+		return slug, true
+	}
+	for _, c := range t.bySlug {
+		if Slugify(c.Name) == slug {
+			return c.Slug, true
+		}
+	}
+	return "", false
+}
+
+// Path returns the slugs from the root down to slug
+func (t *Taxonomy) Path(slug string) []string {
+	var path []string
+	seen := map[string]bool{}
+	for c, ok := t.bySlug[slug]; ok && !seen[c.Slug]; c, ok = t.bySlug[c.Parent] {
+		seen[c.Slug] = true
+		path = append([]string{c.Slug}, path...)
+	}
+	return path
+}
+
+// Descendants returns slug and every category below it
+func (t *Taxonomy) Descendants(slug string) []string {
+	out := []string{slug}
+	seen := map[string]bool{slug: true}
+	for i := 0; i < len(out); i++ {
+		for _, child := range t.children[out[i]] {
+			if !seen[child] {
+				seen[child] = true
+				out = append(out, child)
+			}
+		}
+	}
+	return out
+}
+
+// Node is a category with its children, for listing the tree
+type Node struct {
+	Category
+	Children []Node `json:"children,omitempty"`
+}
+
+// Tree returns the categories as a tree sorted by slug
+func (t *Taxonomy) Tree() []Node {
+	var roots []string
+	for slug, c := range t.bySlug {
+		if _, ok := t.bySlug[c.Parent]; !ok || c.Parent == slug {
+			roots = append(roots, slug)
+		}
+	}
+	sort.Strings(roots)
+
+	var build func(slug string, seen map[string]bool) Node
+	build = func(slug string, seen map[string]bool) Node {
+		seen[slug] = true
+		node := Node{Category: t.bySlug[slug]}
+		for _, child := range t.children[slug] {
+			if !seen[child] {
+				node.Children = append(node.Children, build(child, seen))
+			}
+		}
+		return node
+	}
+
+	seen := map[string]bool{}
+	tree := make([]Node, 0, len(roots))
+	for _, slug := range roots {
+		tree = append(tree, build(slug, seen))
+	}
+	return tree
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +14,24 @@ type Config struct {
 	PaystackSecretKey string
 	ServerPort        string
 	DatabasePath      string
+	// ConfirmationSecret signs two-phase confirmation tokens
+	ConfirmationSecret string
+	// TransactionSyncInterval is how often Paystack transactions are synced locally (0 disables)
+	TransactionSyncInterval time.Duration
+	// RecipientSyncInterval is how often Paystack transfer recipients are synced locally (0 disables)
+	RecipientSyncInterval time.Duration
+	// ReconciliationInterval is how often local data is reconciled against Paystack (0 disables)
+	ReconciliationInterval time.Duration
+	// ScheduleInterval is how often due recurring payments and scheduled transfers are run (0 disables)
+	ScheduleInterval time.Duration
+	// FXRatesFile is a CSV of exchange rates imported at startup (optional)
+	FXRatesFile string
+	// ForecastBalanceFloor is the balance, in minor units, below which forecast days are flagged
+	ForecastBalanceFloor int64
+	// AnomalyDuplicateWindow is how far back an identical payment is flagged as a likely duplicate
+	AnomalyDuplicateWindow time.Duration
+	// AnomalyFirstPaymentThreshold is the NGN amount, in kobo, at which a first payment to a recipient is flagged
+	AnomalyFirstPaymentThreshold int64
 }
 
 // Load loads configuration from environment variables
@@ -39,9 +59,53 @@ func Load() *Config {
 		dbPath = "./data/moniewave.db"
 	}
 
+	syncInterval := durationEnv("TRANSACTION_SYNC_INTERVAL", 15*time.Minute)
+	recipientSyncInterval := durationEnv("RECIPIENT_SYNC_INTERVAL", time.Hour)
+	reconciliationInterval := durationEnv("RECONCILIATION_INTERVAL", 24*time.Hour)
+	scheduleInterval := durationEnv("SCHEDULE_INTERVAL", time.Minute)
+	forecastFloor := int64Env("FORECAST_BALANCE_FLOOR", 0)
+	duplicateWindow := durationEnv("ANOMALY_DUPLICATE_WINDOW", 10*time.Minute)
+	firstPaymentThreshold := int64Env("ANOMALY_FIRST_PAYMENT_THRESHOLD", 50000000)
+
 	return &Config{
-		PaystackSecretKey: apiKey,
-		ServerPort:        port,
-		DatabasePath:      dbPath,
+		PaystackSecretKey:       apiKey,
+		ServerPort:              port,
+		DatabasePath:            dbPath,
+		ConfirmationSecret:      os.Getenv("CONFIRMATION_SECRET"),
+		TransactionSyncInterval: syncInterval,
+		RecipientSyncInterval:   recipientSyncInterval,
+		ReconciliationInterval:  reconciliationInterval,
+		ScheduleInterval:        scheduleInterval,
+		FXRatesFile:             os.Getenv("FX_RATES_FILE"),
+		ForecastBalanceFloor:    forecastFloor,
+
+		AnomalyDuplicateWindow:       duplicateWindow,
+		AnomalyFirstPaymentThreshold: firstPaymentThreshold,
+	}
+}
+
+// durationEnv reads a Go duration (e.g. "15m") from the environment
+func durationEnv(name string, fallback time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: %v", name, v, err)
+	}
+	return parsed
+}
+
+// int64Env reads a whole number (e.g. minor units of money) from the environment
+func int64Env(name string, fallback int64) int64 {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: %v", name, v, err)
 	}
+	return parsed
 }
@@ -0,0 +1,239 @@
+// Package confirm implements the two-phase confirmation flow for money-moving
+// operations.
+//
+// KEY WORKFLOW:
+// Preview Request → Issue Signed Token (payload stored) → User Approves →
+// Confirm With Token → Verify Signature, Expiry and Single Use → Execute Stored Payload
+//
+// DESIGN DECISIONS:
+// - Tokens are HMAC-SHA256 signed so any alteration is detected without a DB lookup
+// - The previewed request is stored server-side; confirming executes exactly that request
+// - The token carries a hash of the stored payload, binding the two together
+// - Redemption atomically flips the row from pending to used, so tokens cannot be replayed
+// - Without a configured secret a random per-process key is used (tokens die on restart)
+package confirm
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+)
+
+// DefaultTTL is how long a confirmation token stays valid
+const DefaultTTL = 5 * time.Minute
+
+// Actions that require confirmation
+const (
+	ActionExpense           = "expense"
+	ActionTransfer          = "transfer"
+	ActionInvoice           = "invoice"
+	ActionSchedule          = "schedule"
+	ActionScheduledTransfer = "scheduled_transfer"
+	ActionBulkTransfer      = "bulk_transfer"
+)
+
+var (
+	// ErrInvalidToken is returned for malformed or tampered tokens
+	ErrInvalidToken = errors.New("invalid confirmation token")
+	// ErrExpired is returned when a token is past its expiry
+	ErrExpired = errors.New("confirmation token has expired")
+	// ErrAlreadyUsed is returned when a token has already been redeemed
+	ErrAlreadyUsed = errors.New("confirmation token has already been used")
+	// ErrActionMismatch is returned when a token is presented to the wrong operation
+	ErrActionMismatch = errors.New("confirmation token was issued for a different action")
+)
+
+var (
+	mu     sync.RWMutex
+	secret []byte
+)
+
+// claims is the signed part of a token
+type claims struct {
+	ID          string `json:"id"`
+	Action      string `json:"action"`
+	PayloadHash string `json:"payload_hash"`
+	ExpiresAt   int64  `json:"exp"`
+}
+
+// Configure sets the signing secret. An empty secret generates a random key.
+func Configure(key string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if key != "" {
+		secret = []byte(key)
+		return
+	}
+
+	secret = randomBytes(32)
+	log.Println("CONFIRMATION_SECRET not set, using an ephemeral key (pending confirmations will not survive a restart)")
+}
+
+func signingKey() []byte {
+	mu.RLock()
+	key := secret
+	mu.RUnlock()
+
+	if key == nil {
+		Configure("")
+		mu.RLock()
+		key = secret
+		mu.RUnlock()
+	}
+	return key
+}
+
+// Issue stores payload as a pending confirmation for action and returns a signed token
+func Issue(action string, payload interface{}) (string, time.Time, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	id := hex.EncodeToString(randomBytes(16))
+	now := time.Now()
+	expiresAt := now.Add(DefaultTTL)
+	hash := sha256.Sum256(body)
+
+	query := `
+		INSERT INTO confirmations (id, action, payload, payload_hash, status, expires_at, created_at)
+		VALUES (?, ?, ?, ?, 'pending', ?, ?)
+	`
+	if _, err := database.DB.Exec(query, id, action, string(body), hex.EncodeToString(hash[:]), expiresAt, now); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store confirmation: %w", err)
+	}
+
+	token, err := sign(claims{
+		ID:          id,
+		Action:      action,
+		PayloadHash: hex.EncodeToString(hash[:]),
+		ExpiresAt:   expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// Redeem validates token for action, marks it used and decodes the stored payload into out
+func Redeem(token, action string, out interface{}) error {
+	c, err := verify(token)
+	if err != nil {
+		return err
+	}
+
+	if c.Action != action {
+		return ErrActionMismatch
+	}
+
+	now := time.Now()
+	if now.Unix() > c.ExpiresAt {
+		return ErrExpired
+	}
+
+	var payload, payloadHash, status string
+	var expiresAt time.Time
+	err = database.DB.QueryRow(
+		"SELECT payload, payload_hash, status, expires_at FROM confirmations WHERE id = ? AND action = ?",
+		c.ID, action,
+	).Scan(&payload, &payloadHash, &status, &expiresAt)
+	if err == sql.ErrNoRows {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load confirmation: %w", err)
+	}
+
+	if payloadHash != c.PayloadHash {
+		return ErrInvalidToken
+	}
+	if status != "pending" {
+		return ErrAlreadyUsed
+	}
+	if now.After(expiresAt) {
+		return ErrExpired
+	}
+
+	// Flip to used atomically so concurrent redemptions can't both succeed
+	result, err := database.DB.Exec(
+		"UPDATE confirmations SET status = 'used', used_at = ? WHERE id = ? AND status = 'pending'",
+		now, c.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to redeem confirmation: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows != 1 {
+		return ErrAlreadyUsed
+	}
+
+	if err := json.Unmarshal([]byte(payload), out); err != nil {
+		return fmt.Errorf("failed to decode confirmed payload: %w", err)
+	}
+	return nil
+}
+
+// sign encodes claims as "<payload>.<signature>", both base64url
+func sign(c claims) (string, error) {
+	body, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(body)
+	mac := hmac.New(sha256.New, signingKey())
+	mac.Write([]byte(encoded))
+
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verify checks the signature and decodes the claims
+func verify(token string) (*claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	mac := hmac.New(sha256.New, signingKey())
+	mac.Write([]byte(parts[0]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrInvalidToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var c claims
+	if err := json.Unmarshal(body, &c); err != nil {
+		return nil, ErrInvalidToken
+	}
+	return &c, nil
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return b
+}
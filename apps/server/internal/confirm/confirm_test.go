@@ -0,0 +1,110 @@
+package confirm
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+)
+
+type payload struct {
+	Recipient string `json:"recipient"`
+	Amount    int    `json:"amount"`
+}
+
+func setup(t *testing.T) {
+	t.Helper()
+	if err := database.Initialize(filepath.Join(t.TempDir(), "confirm.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	Configure("test-secret")
+}
+
+func TestIssueAndRedeem(t *testing.T) {
+	setup(t)
+
+	token, expiresAt, err := Issue(ActionTransfer, payload{Recipient: "RCP_1", Amount: 5000})
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	if time.Until(expiresAt) <= 0 || time.Until(expiresAt) > DefaultTTL {
+		t.Fatalf("Unexpected expiry %v", expiresAt)
+	}
+
+	var got payload
+	if err := Redeem(token, ActionTransfer, &got); err != nil {
+		t.Fatalf("Redeem failed: %v", err)
+	}
+	if got.Recipient != "RCP_1" || got.Amount != 5000 {
+		t.Fatalf("Expected stored payload, got %+v", got)
+	}
+
+	// Replays are rejected
+	if err := Redeem(token, ActionTransfer, &got); !errors.Is(err, ErrAlreadyUsed) {
+		t.Fatalf("Expected ErrAlreadyUsed on replay, got %v", err)
+	}
+}
+
+func TestRedeemRejectsBadTokens(t *testing.T) {
+	setup(t)
+
+	token, _, err := Issue(ActionExpense, payload{Recipient: "RCP_2", Amount: 100})
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+
+	var got payload
+	if err := Redeem(token, ActionTransfer, &got); !errors.Is(err, ErrActionMismatch) {
+		t.Errorf("Expected ErrActionMismatch, got %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "x." + parts[1]
+	if err := Redeem(tampered, ActionExpense, &got); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for tampered token, got %v", err)
+	}
+
+	if err := Redeem("not-a-token", ActionExpense, &got); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for malformed token, got %v", err)
+	}
+
+	// A token signed with another key is rejected
+	Configure("other-secret")
+	if err := Redeem(token, ActionExpense, &got); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for foreign signature, got %v", err)
+	}
+	Configure("test-secret")
+
+	// The failed attempts must not have consumed the token
+	if err := Redeem(token, ActionExpense, &got); err != nil {
+		t.Errorf("Expected valid token to redeem, got %v", err)
+	}
+}
+
+func TestRedeemRejectsExpiredTokens(t *testing.T) {
+	setup(t)
+
+	token, _, err := Issue(ActionInvoice, payload{Recipient: "CUS_1", Amount: 100})
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+
+	c, err := verify(token)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	c.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	expired, err := sign(*c)
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+
+	var got payload
+	if err := Redeem(expired, ActionInvoice, &got); !errors.Is(err, ErrExpired) {
+		t.Fatalf("Expected ErrExpired, got %v", err)
+	}
+}
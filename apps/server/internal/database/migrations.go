@@ -2,6 +2,8 @@ package database
 
 import (
 	"log"
+
+	"paystack.mpc.proxy/internal/categorize"
 )
 
 // runMigrations runs all database migrations
@@ -309,6 +311,968 @@ func runMigrations() error {
 
 	log.Println("Budget limit indexes created successfully")
 
+	// Create confirmations table (two-phase approval of money-moving operations)
+	createConfirmationsTable := `
+	CREATE TABLE IF NOT EXISTS confirmations (
+		id TEXT PRIMARY KEY,
+		action TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		payload_hash TEXT NOT NULL,
+		status TEXT DEFAULT 'pending',
+		expires_at DATETIME NOT NULL,
+		used_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := DB.Exec(createConfirmationsTable); err != nil {
+		return err
+	}
+
+	createConfirmationStatusIndex := `CREATE INDEX IF NOT EXISTS idx_confirmations_status ON confirmations(status, expires_at);`
+	if _, err := DB.Exec(createConfirmationStatusIndex); err != nil {
+		return err
+	}
+
+	log.Println("Confirmations table created successfully")
+
+	// Create account limits table (single row, 0 means no limit)
+	createAccountLimitsTable := `
+	CREATE TABLE IF NOT EXISTS account_limits (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		per_transaction_limit INTEGER DEFAULT 0,
+		daily_transfer_limit INTEGER DEFAULT 0,
+		weekly_transfer_limit INTEGER DEFAULT 0,
+		monthly_transfer_limit INTEGER DEFAULT 0,
+		balance_limit INTEGER DEFAULT 0,
+		currency TEXT DEFAULT 'NGN',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := DB.Exec(createAccountLimitsTable); err != nil {
+		return err
+	}
+
+	// Create beneficiary limits table (one cap per recipient and period)
+	createBeneficiaryLimitsTable := `
+	CREATE TABLE IF NOT EXISTS beneficiary_limits (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		recipient_code TEXT NOT NULL,
+		period TEXT NOT NULL,
+		amount_limit INTEGER NOT NULL,
+		currency TEXT DEFAULT 'NGN',
+		alerts_at_percent INTEGER DEFAULT 80,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(recipient_code, period)
+	);`
+
+	if _, err := DB.Exec(createBeneficiaryLimitsTable); err != nil {
+		return err
+	}
+
+	// Create outgoing payments table (rolling-window accounting for limits)
+	createOutgoingPaymentsTable := `
+	CREATE TABLE IF NOT EXISTS outgoing_payments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		recipient_code TEXT NOT NULL,
+		amount INTEGER NOT NULL,
+		currency TEXT DEFAULT 'NGN',
+		source TEXT NOT NULL,
+		reference TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := DB.Exec(createOutgoingPaymentsTable); err != nil {
+		return err
+	}
+
+	createOutgoingCreatedIndex := `CREATE INDEX IF NOT EXISTS idx_outgoing_payments_created_at ON outgoing_payments(created_at);`
+	createOutgoingRecipientIndex := `CREATE INDEX IF NOT EXISTS idx_outgoing_payments_recipient ON outgoing_payments(recipient_code, created_at);`
+
+	if _, err := DB.Exec(createOutgoingCreatedIndex); err != nil {
+		return err
+	}
+
+	if _, err := DB.Exec(createOutgoingRecipientIndex); err != nil {
+		return err
+	}
+
+	log.Println("Transfer limits tables created successfully")
+
+	// Create transactions table (local store of Paystack transactions for analytics)
+	createTransactionsTable := `
+	CREATE TABLE IF NOT EXISTS transactions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		paystack_id INTEGER UNIQUE,
+		reference TEXT NOT NULL UNIQUE,
+		amount INTEGER NOT NULL,
+		currency TEXT DEFAULT 'NGN',
+		status TEXT,
+		channel TEXT,
+		customer_email TEXT,
+		customer_code TEXT,
+		transaction_date DATETIME NOT NULL,
+		synced_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := DB.Exec(createTransactionsTable); err != nil {
+		return err
+	}
+
+	createTransactionDateIndex := `CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(transaction_date);`
+	if _, err := DB.Exec(createTransactionDateIndex); err != nil {
+		return err
+	}
+
+	log.Println("Transactions table created successfully")
+
+	// Create virtual cards table (cards issued through a card issuer, linked to a budget)
+	createVirtualCardsTable := `
+	CREATE TABLE IF NOT EXISTS virtual_cards (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		issuer TEXT NOT NULL,
+		external_id TEXT NOT NULL UNIQUE,
+		label TEXT NOT NULL,
+		currency TEXT DEFAULT 'NGN',
+		brand TEXT,
+		last4 TEXT NOT NULL,
+		masked_pan TEXT NOT NULL,
+		exp_month INTEGER NOT NULL,
+		exp_year INTEGER NOT NULL,
+		budget_limit_id INTEGER NOT NULL,
+		per_transaction_limit INTEGER DEFAULT 0,
+		spend_limit INTEGER DEFAULT 0,
+		spend_period TEXT DEFAULT 'lifetime',
+		allowed_categories TEXT DEFAULT '[]',
+		blocked_categories TEXT DEFAULT '[]',
+		status TEXT DEFAULT 'active',
+		expires_at DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (budget_limit_id) REFERENCES budget_limits(id)
+	);`
+
+	if _, err := DB.Exec(createVirtualCardsTable); err != nil {
+		return err
+	}
+
+	// Create card transactions table (every authorization attempt, approved or declined)
+	createCardTransactionsTable := `
+	CREATE TABLE IF NOT EXISTS card_transactions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		card_id INTEGER NOT NULL,
+		amount INTEGER NOT NULL,
+		currency TEXT DEFAULT 'NGN',
+		merchant_name TEXT,
+		merchant_category TEXT,
+		status TEXT NOT NULL,
+		decline_reason TEXT,
+		expense_id INTEGER,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (card_id) REFERENCES virtual_cards(id),
+		FOREIGN KEY (expense_id) REFERENCES expenses(id)
+	);`
+
+	if _, err := DB.Exec(createCardTransactionsTable); err != nil {
+		return err
+	}
+
+	createCardTransactionsIndex := `CREATE INDEX IF NOT EXISTS idx_card_transactions_card ON card_transactions(card_id, status, created_at);`
+	if _, err := DB.Exec(createCardTransactionsIndex); err != nil {
+		return err
+	}
+
+	log.Println("Virtual card tables created successfully")
+
+	// Create indexes for local transaction list and search filters
+	createTransactionStatusIndex := `CREATE INDEX IF NOT EXISTS idx_transactions_status ON transactions(status);`
+	createTransactionCustomerIndex := `CREATE INDEX IF NOT EXISTS idx_transactions_customer ON transactions(customer_email);`
+
+	if _, err := DB.Exec(createTransactionStatusIndex); err != nil {
+		return err
+	}
+
+	if _, err := DB.Exec(createTransactionCustomerIndex); err != nil {
+		return err
+	}
+
+	// Create sync state table (cursor and high-water mark for incremental syncs)
+	createSyncStateTable := `
+	CREATE TABLE IF NOT EXISTS sync_state (
+		name TEXT PRIMARY KEY,
+		cursor_page INTEGER DEFAULT 0,
+		run_from DATETIME,
+		run_to DATETIME,
+		watermark DATETIME,
+		last_run_at DATETIME,
+		last_success_at DATETIME,
+		last_error TEXT,
+		records_synced INTEGER DEFAULT 0
+	);`
+
+	if _, err := DB.Exec(createSyncStateTable); err != nil {
+		return err
+	}
+
+	log.Println("Transaction sync tables created successfully")
+
+	// Create reconciliation runs table (one row per comparison against Paystack)
+	createReconciliationRunsTable := `
+	CREATE TABLE IF NOT EXISTS reconciliation_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		status TEXT NOT NULL DEFAULT 'running',
+		sources TEXT NOT NULL,
+		window_from DATETIME,
+		window_to DATETIME,
+		auto_fix BOOLEAN DEFAULT 0,
+		checked INTEGER DEFAULT 0,
+		discrepancies INTEGER DEFAULT 0,
+		fixed INTEGER DEFAULT 0,
+		truncated TEXT,
+		error TEXT,
+		started_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		finished_at DATETIME
+	);`
+
+	if _, err := DB.Exec(createReconciliationRunsTable); err != nil {
+		return err
+	}
+
+	// Create reconciliation items table (discrepancies found by a run)
+	createReconciliationItemsTable := `
+	CREATE TABLE IF NOT EXISTS reconciliation_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		run_id INTEGER NOT NULL,
+		source TEXT NOT NULL,
+		item_key TEXT NOT NULL,
+		type TEXT NOT NULL,
+		local_id INTEGER,
+		local_amount INTEGER,
+		remote_amount INTEGER,
+		local_status TEXT,
+		remote_status TEXT,
+		fix_action TEXT,
+		remote_data TEXT,
+		status TEXT NOT NULL DEFAULT 'open',
+		note TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		resolved_at DATETIME,
+		FOREIGN KEY (run_id) REFERENCES reconciliation_runs(id)
+	);`
+
+	if _, err := DB.Exec(createReconciliationItemsTable); err != nil {
+		return err
+	}
+
+	createReconciliationItemsIndex := `CREATE INDEX IF NOT EXISTS idx_reconciliation_items_run ON reconciliation_items(run_id, status);`
+	if _, err := DB.Exec(createReconciliationItemsIndex); err != nil {
+		return err
+	}
+
+	log.Println("Reconciliation tables created successfully")
+
+	// Create journal entries table (one balanced entry per money event)
+	createJournalEntriesTable := `
+	CREATE TABLE IF NOT EXISTS journal_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		reference TEXT,
+		description TEXT,
+		source_type TEXT NOT NULL,
+		source_id INTEGER NOT NULL,
+		currency TEXT NOT NULL DEFAULT 'NGN',
+		occurred_at DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(source_type, source_id, kind)
+	);`
+
+	if _, err := DB.Exec(createJournalEntriesTable); err != nil {
+		return err
+	}
+
+	// Create journal lines table (debits and credits of each entry)
+	createJournalLinesTable := `
+	CREATE TABLE IF NOT EXISTS journal_lines (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entry_id INTEGER NOT NULL,
+		account_code TEXT NOT NULL,
+		debit INTEGER NOT NULL DEFAULT 0,
+		credit INTEGER NOT NULL DEFAULT 0,
+		FOREIGN KEY (entry_id) REFERENCES journal_entries(id)
+	);`
+
+	if _, err := DB.Exec(createJournalLinesTable); err != nil {
+		return err
+	}
+
+	createJournalLinesIndexes := `
+	CREATE INDEX IF NOT EXISTS idx_journal_lines_account ON journal_lines(account_code);
+	CREATE INDEX IF NOT EXISTS idx_journal_lines_entry ON journal_lines(entry_id);`
+	if _, err := DB.Exec(createJournalLinesIndexes); err != nil {
+		return err
+	}
+
+	// Budget spent and goal progress are derived from the journal
+	createLedgerViews := `
+	CREATE VIEW IF NOT EXISTS budget_ledger_spent AS
+		SELECT CAST(substr(account_code, 16) AS INTEGER) AS budget_id,
+		       SUM(debit) - SUM(credit) AS spent_amount
+		FROM journal_lines
+		WHERE account_code LIKE 'expense:budget:%'
+		GROUP BY account_code;
+	CREATE VIEW IF NOT EXISTS goal_ledger_progress AS
+		SELECT CAST(substr(account_code, 12) AS INTEGER) AS goal_id,
+		       SUM(debit) - SUM(credit) AS progress_amount
+		FROM journal_lines
+		WHERE account_code LIKE 'asset:goal:%'
+		GROUP BY account_code;`
+	if _, err := DB.Exec(createLedgerViews); err != nil {
+		return err
+	}
+
+	log.Println("Ledger tables created successfully")
+
+	// Add currency to budgets and invoices (amounts are minor units of it)
+	addCurrencyColumnToBudgets := `ALTER TABLE budget_limits ADD COLUMN currency TEXT DEFAULT 'NGN';`
+	addCurrencyColumnToInvoices := `ALTER TABLE invoices ADD COLUMN currency TEXT DEFAULT 'NGN';`
+
+	// Try to add columns (will fail silently if already exists)
+	DB.Exec(addCurrencyColumnToBudgets)
+	DB.Exec(addCurrencyColumnToInvoices)
+
+	// Create fx_rates table (one rate per pair per day, effective until the next)
+	createFXRatesTable := `
+	CREATE TABLE IF NOT EXISTS fx_rates (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		base TEXT NOT NULL,
+		quote TEXT NOT NULL,
+		rate REAL NOT NULL,
+		effective_date TEXT NOT NULL,
+		source TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(base, quote, effective_date)
+	);`
+
+	if _, err := DB.Exec(createFXRatesTable); err != nil {
+		return err
+	}
+
+	log.Println("FX rates table created successfully")
+
+	// Expenses in another currency than their budget keep the converted amount and the rate used
+	addBudgetAmountColumnToExpenses := `ALTER TABLE expenses ADD COLUMN budget_amount INTEGER;`
+	addBudgetCurrencyColumnToExpenses := `ALTER TABLE expenses ADD COLUMN budget_currency TEXT;`
+	addFXRateColumnToExpenses := `ALTER TABLE expenses ADD COLUMN fx_rate REAL;`
+	addFXRateDateColumnToExpenses := `ALTER TABLE expenses ADD COLUMN fx_rate_date TEXT;`
+
+	// Try to add columns (will fail silently if already exists)
+	DB.Exec(addBudgetAmountColumnToExpenses)
+	DB.Exec(addBudgetCurrencyColumnToExpenses)
+	DB.Exec(addFXRateColumnToExpenses)
+	DB.Exec(addFXRateDateColumnToExpenses)
+
+	// Create categories table (canonical category tree)
+	createCategoriesTable := `
+	CREATE TABLE IF NOT EXISTS categories (
+		slug TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		parent_slug TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	// Create category_rules table (user rules assigning categories to expenses)
+	createCategoryRulesTable := `
+	CREATE TABLE IF NOT EXISTS category_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT,
+		field TEXT NOT NULL,
+		operator TEXT NOT NULL,
+		value TEXT NOT NULL,
+		category TEXT NOT NULL,
+		priority INTEGER DEFAULT 0,
+		enabled INTEGER DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := DB.Exec(createCategoriesTable); err != nil {
+		return err
+	}
+	if _, err := DB.Exec(createCategoryRulesTable); err != nil {
+		return err
+	}
+	if err := seedCategories(); err != nil {
+		return err
+	}
+
+	log.Println("Category tables created successfully")
+
+	// Record how each expense got its category (manual, rule or learned)
+	addCategorySourceColumnToExpenses := `ALTER TABLE expenses ADD COLUMN category_source TEXT;`
+	addCategoryRuleColumnToExpenses := `ALTER TABLE expenses ADD COLUMN category_rule_id INTEGER;`
+
+	// Try to add columns (will fail silently if already exists)
+	DB.Exec(addCategorySourceColumnToExpenses)
+	DB.Exec(addCategoryRuleColumnToExpenses)
+
+	// Create statement_imports table (bank statements imported as expenses)
+	createStatementImportsTable := `
+	CREATE TABLE IF NOT EXISTS statement_imports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		filename TEXT,
+		format TEXT NOT NULL,
+		profile TEXT,
+		currency TEXT NOT NULL,
+		budget_limit_id INTEGER,
+		line_count INTEGER DEFAULT 0,
+		created_count INTEGER DEFAULT 0,
+		duplicate_count INTEGER DEFAULT 0,
+		skipped_count INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (budget_limit_id) REFERENCES budget_limits(id)
+	);`
+
+	// Create statement_profiles table (custom CSV column mappings, stored as JSON)
+	createStatementProfilesTable := `
+	CREATE TABLE IF NOT EXISTS statement_profiles (
+		name TEXT PRIMARY KEY,
+		config TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := DB.Exec(createStatementImportsTable); err != nil {
+		return err
+	}
+	if _, err := DB.Exec(createStatementProfilesTable); err != nil {
+		return err
+	}
+
+	log.Println("Statement import tables created successfully")
+
+	// Link imported expenses to their import and keep the bank's own reference
+	addImportColumnToExpenses := `ALTER TABLE expenses ADD COLUMN import_id INTEGER REFERENCES statement_imports(id);`
+	addStatementReferenceColumnToExpenses := `ALTER TABLE expenses ADD COLUMN statement_reference TEXT;`
+	createExpensesImportIndex := `CREATE INDEX IF NOT EXISTS idx_expenses_import ON expenses(import_id);`
+
+	// Try to add columns (will fail silently if already exists)
+	DB.Exec(addImportColumnToExpenses)
+	DB.Exec(addStatementReferenceColumnToExpenses)
+	DB.Exec(createExpensesImportIndex)
+
+	// Create schedules table (recurring payments, on a cron expression or an interval)
+	createSchedulesTable := `
+	CREATE TABLE IF NOT EXISTS schedules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		recipient_code TEXT NOT NULL,
+		recipient_name TEXT NOT NULL,
+		amount INTEGER NOT NULL,
+		currency TEXT DEFAULT 'NGN',
+		category TEXT,
+		narration TEXT NOT NULL,
+		notes TEXT,
+		budget_limit_id INTEGER,
+		cron TEXT,
+		interval_count INTEGER,
+		interval_unit TEXT,
+		start_at DATETIME NOT NULL,
+		end_at DATETIME,
+		next_run_at DATETIME,
+		status TEXT DEFAULT 'active',
+		auto_transfer INTEGER DEFAULT 0,
+		max_retries INTEGER DEFAULT 3,
+		attempts INTEGER DEFAULT 0,
+		retry_at DATETIME,
+		last_run_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (recipient_code) REFERENCES recipients(recipient_code),
+		FOREIGN KEY (budget_limit_id) REFERENCES budget_limits(id)
+	);`
+
+	// Create schedule_runs table (history of every occurrence: paid, failed, skipped or missed)
+	createScheduleRunsTable := `
+	CREATE TABLE IF NOT EXISTS schedule_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		schedule_id INTEGER NOT NULL,
+		due_at DATETIME NOT NULL,
+		attempt INTEGER DEFAULT 1,
+		status TEXT NOT NULL,
+		expense_id INTEGER,
+		transfer_code TEXT,
+		error TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (schedule_id) REFERENCES schedules(id),
+		FOREIGN KEY (expense_id) REFERENCES expenses(id)
+	);`
+
+	if _, err := DB.Exec(createSchedulesTable); err != nil {
+		return err
+	}
+	if _, err := DB.Exec(createScheduleRunsTable); err != nil {
+		return err
+	}
+
+	createSchedulesDueIndex := `CREATE INDEX IF NOT EXISTS idx_schedules_due ON schedules(status, next_run_at);`
+	createScheduleRunsIndex := `CREATE INDEX IF NOT EXISTS idx_schedule_runs_schedule ON schedule_runs(schedule_id, due_at);`
+	if _, err := DB.Exec(createSchedulesDueIndex); err != nil {
+		return err
+	}
+	if _, err := DB.Exec(createScheduleRunsIndex); err != nil {
+		return err
+	}
+
+	log.Println("Schedule tables created successfully")
+
+	// Link expenses created by a schedule back to it
+	addScheduleColumnToExpenses := `ALTER TABLE expenses ADD COLUMN schedule_id INTEGER REFERENCES schedules(id);`
+
+	// Try to add columns (will fail silently if already exists)
+	DB.Exec(addScheduleColumnToExpenses)
+
+	// Create scheduled_transfers table (one-off transfers sent at a future time by a leased worker)
+	createScheduledTransfersTable := `
+	CREATE TABLE IF NOT EXISTS scheduled_transfers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		recipient_code TEXT NOT NULL,
+		recipient_name TEXT NOT NULL,
+		amount INTEGER NOT NULL,
+		currency TEXT DEFAULT 'NGN',
+		source TEXT DEFAULT 'balance',
+		reason TEXT,
+		reference TEXT NOT NULL UNIQUE,
+		execute_at DATETIME NOT NULL,
+		status TEXT DEFAULT 'scheduled',
+		attempts INTEGER DEFAULT 0,
+		retry_at DATETIME,
+		lease_owner TEXT,
+		lease_expires_at DATETIME,
+		initiated_at DATETIME,
+		outgoing_payment_id INTEGER,
+		transfer_code TEXT,
+		transfer_status TEXT,
+		error TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (outgoing_payment_id) REFERENCES outgoing_payments(id)
+	);`
+
+	// Create scheduled_transfer_events table (status history of each scheduled transfer)
+	createScheduledTransferEventsTable := `
+	CREATE TABLE IF NOT EXISTS scheduled_transfer_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		scheduled_transfer_id INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		detail TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (scheduled_transfer_id) REFERENCES scheduled_transfers(id)
+	);`
+
+	if _, err := DB.Exec(createScheduledTransfersTable); err != nil {
+		return err
+	}
+	if _, err := DB.Exec(createScheduledTransferEventsTable); err != nil {
+		return err
+	}
+
+	createScheduledTransfersDueIndex := `CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers(status, execute_at);`
+	createScheduledTransferEventsIndex := `CREATE INDEX IF NOT EXISTS idx_scheduled_transfer_events_transfer ON scheduled_transfer_events(scheduled_transfer_id, id);`
+	if _, err := DB.Exec(createScheduledTransfersDueIndex); err != nil {
+		return err
+	}
+	if _, err := DB.Exec(createScheduledTransferEventsIndex); err != nil {
+		return err
+	}
+
+	log.Println("Scheduled transfer tables created successfully")
+
+	// Keep each invoice's due date so expected income can be forecast
+	addDueDateColumnToInvoices := `ALTER TABLE invoices ADD COLUMN due_date DATETIME;`
+
+	// Try to add columns (will fail silently if already exists)
+	DB.Exec(addDueDateColumnToInvoices)
+
+	// Create reports table (monthly statements frozen as JSON when generated)
+	createReportsTable := `
+	CREATE TABLE IF NOT EXISTS reports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		period TEXT NOT NULL,
+		currency TEXT NOT NULL DEFAULT 'NGN',
+		data TEXT NOT NULL,
+		generated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := DB.Exec(createReportsTable); err != nil {
+		return err
+	}
+
+	createReportsPeriodIndex := `CREATE INDEX IF NOT EXISTS idx_reports_period ON reports(period, currency, id);`
+	if _, err := DB.Exec(createReportsPeriodIndex); err != nil {
+		return err
+	}
+
+	log.Println("Reports table created successfully")
+
+	// Keep each payment's narration so repeats of the same payment can be spotted
+	addNarrationColumnToOutgoingPayments := `ALTER TABLE outgoing_payments ADD COLUMN narration TEXT;`
+
+	// Try to add columns (will fail silently if already exists)
+	DB.Exec(addNarrationColumnToOutgoingPayments)
+
+	// Create anomaly events table (payments flagged as possible duplicates or unusual, for review)
+	createAnomalyEventsTable := `
+	CREATE TABLE IF NOT EXISTS anomaly_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		source TEXT NOT NULL,
+		reference TEXT,
+		recipient_code TEXT NOT NULL,
+		recipient_name TEXT,
+		amount INTEGER NOT NULL,
+		currency TEXT DEFAULT 'NGN',
+		narration TEXT,
+		category TEXT,
+		message TEXT NOT NULL,
+		detail TEXT,
+		status TEXT NOT NULL DEFAULT 'open',
+		note TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		reviewed_at DATETIME
+	);`
+
+	if _, err := DB.Exec(createAnomalyEventsTable); err != nil {
+		return err
+	}
+
+	createAnomalyEventsStatusIndex := `CREATE INDEX IF NOT EXISTS idx_anomaly_events_status ON anomaly_events(status, created_at);`
+	if _, err := DB.Exec(createAnomalyEventsStatusIndex); err != nil {
+		return err
+	}
+
+	log.Println("Anomaly events table created successfully")
+
+	// Create recipient aliases table (other names a recipient is called by, e.g. "landlord")
+	createRecipientAliasesTable := `
+	CREATE TABLE IF NOT EXISTS recipient_aliases (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		recipient_code TEXT NOT NULL,
+		alias TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(recipient_code, alias),
+		FOREIGN KEY (recipient_code) REFERENCES recipients(recipient_code)
+	);`
+
+	if _, err := DB.Exec(createRecipientAliasesTable); err != nil {
+		return err
+	}
+
+	createRecipientAliasesIndex := `CREATE INDEX IF NOT EXISTS idx_recipient_aliases_code ON recipient_aliases(recipient_code);`
+	if _, err := DB.Exec(createRecipientAliasesIndex); err != nil {
+		return err
+	}
+
+	log.Println("Recipient aliases table created successfully")
+
+	// Create recipient groups tables (named sets of recipients paid together, e.g. "cleaning team")
+	createRecipientGroupsTable := `
+	CREATE TABLE IF NOT EXISTS recipient_groups (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		description TEXT,
+		currency TEXT DEFAULT 'NGN',
+		default_amount INTEGER,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	createRecipientGroupMembersTable := `
+	CREATE TABLE IF NOT EXISTS recipient_group_members (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		group_id INTEGER NOT NULL,
+		recipient_code TEXT NOT NULL,
+		amount INTEGER,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(group_id, recipient_code),
+		FOREIGN KEY (group_id) REFERENCES recipient_groups(id),
+		FOREIGN KEY (recipient_code) REFERENCES recipients(recipient_code)
+	);`
+
+	if _, err := DB.Exec(createRecipientGroupsTable); err != nil {
+		return err
+	}
+
+	if _, err := DB.Exec(createRecipientGroupMembersTable); err != nil {
+		return err
+	}
+
+	createRecipientGroupMembersIndex := `CREATE INDEX IF NOT EXISTS idx_recipient_group_members_group ON recipient_group_members(group_id);`
+	if _, err := DB.Exec(createRecipientGroupMembersIndex); err != nil {
+		return err
+	}
+
+	log.Println("Recipient groups tables created successfully")
+
+	// Track recipients deleted here or in Paystack, duplicates of another recipient's
+	// account, and when each was last seen in a Paystack sync
+	addActiveColumnToRecipients := `ALTER TABLE recipients ADD COLUMN active INTEGER NOT NULL DEFAULT 1;`
+	addDeletedAtColumnToRecipients := `ALTER TABLE recipients ADD COLUMN deleted_at DATETIME;`
+	addDuplicateOfColumnToRecipients := `ALTER TABLE recipients ADD COLUMN duplicate_of TEXT;`
+	addSyncedAtColumnToRecipients := `ALTER TABLE recipients ADD COLUMN synced_at DATETIME;`
+
+	// Try to add columns (will fail silently if already exists)
+	DB.Exec(addActiveColumnToRecipients)
+	DB.Exec(addDeletedAtColumnToRecipients)
+	DB.Exec(addDuplicateOfColumnToRecipients)
+	DB.Exec(addSyncedAtColumnToRecipients)
+
+	if err := mergeDuplicateRecipients(); err != nil {
+		return err
+	}
+
+	// One live recipient per bank account
+	createRecipientAccountIndex := `
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_recipients_account
+	ON recipients(bank_code, account_number) WHERE deleted_at IS NULL;`
+	if _, err := DB.Exec(createRecipientAccountIndex); err != nil {
+		return err
+	}
+
+	log.Println("Recipient lifecycle columns added successfully")
+
+	// Create account resolutions table (names banks hold for account numbers, cached
+	// so the same account isn't looked up on every recipient created for it)
+	createAccountResolutionsTable := `
+	CREATE TABLE IF NOT EXISTS account_resolutions (
+		bank_code TEXT NOT NULL,
+		account_number TEXT NOT NULL,
+		account_name TEXT NOT NULL,
+		bank_id INTEGER,
+		resolved_at DATETIME NOT NULL,
+		PRIMARY KEY (bank_code, account_number)
+	);`
+
+	if _, err := DB.Exec(createAccountResolutionsTable); err != nil {
+		return err
+	}
+
+	// Keep the name the bank holds alongside the nickname a recipient was saved
+	// under, and how well the two matched
+	addAccountNameColumnToRecipients := `ALTER TABLE recipients ADD COLUMN account_name TEXT;`
+	addNameMatchColumnToRecipients := `ALTER TABLE recipients ADD COLUMN name_match TEXT;`
+	addNameConfidenceColumnToRecipients := `ALTER TABLE recipients ADD COLUMN name_confidence REAL;`
+
+	// Try to add columns (will fail silently if already exists)
+	DB.Exec(addAccountNameColumnToRecipients)
+	DB.Exec(addNameMatchColumnToRecipients)
+	DB.Exec(addNameConfidenceColumnToRecipients)
+
+	log.Println("Account resolutions table created successfully")
+
+	// Credit profiles say which currency their amounts are in
+	addCurrencyColumnToCreditProfiles := `ALTER TABLE credit_profiles ADD COLUMN currency TEXT DEFAULT 'NGN';`
+
+	// Try to add columns (will fail silently if already exists)
+	DB.Exec(addCurrencyColumnToCreditProfiles)
+
+	// Create the full-text search index over expenses, recipients and invoices
+	if err := createSearchIndex(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SearchAvailable reports whether the full-text search index exists. It needs
+// go-sqlite3 built with FTS5 (go build -tags sqlite_fts5).
+var SearchAvailable bool
+
+// searchSource says how the rows of one table are indexed for search.
+// Each field is an SQL expression over the table's columns.
+type searchSource struct {
+	table    string
+	kind     string
+	key      string
+	title    string
+	keywords string
+	body     string
+	amount   string
+	currency string
+	// filter limits which rows are indexed (all when empty)
+	filter string
+}
+
+var searchSources = []searchSource{
+	{
+		table:    "expenses",
+		kind:     "expense",
+		key:      "id",
+		title:    "recipient_name",
+		keywords: "COALESCE(category, '') || ' ' || COALESCE(reference, '') || ' ' || recipient_code",
+		body:     "COALESCE(narration, '') || ' ' || COALESCE(notes, '')",
+		amount:   "amount",
+		currency: "COALESCE(currency, 'NGN')",
+	},
+	{
+		table:    "recipients",
+		kind:     "recipient",
+		key:      "recipient_code",
+		title:    "name",
+		keywords: "account_number || ' ' || COALESCE(bank_name, '') || ' ' || recipient_code",
+		body:     "COALESCE(description, '')",
+		amount:   "NULL",
+		currency: "COALESCE(currency, 'NGN')",
+		filter:   "deleted_at IS NULL",
+	},
+	{
+		table:    "invoices",
+		kind:     "invoice",
+		key:      "invoice_code",
+		title:    "customer_name",
+		keywords: "invoice_code || ' ' || customer_id || ' ' || COALESCE(status, '')",
+		body:     "''",
+		amount:   "amount",
+		currency: "COALESCE(currency, 'NGN')",
+	},
+}
+
+// mergeDuplicateRecipients keeps the oldest live recipient for each bank account and
+// marks the rest as deleted duplicates of it. Aliases, group memberships and
+// beneficiary limits move to the kept recipient unless it already has the same one.
+func mergeDuplicateRecipients() error {
+	markDuplicates := `
+	UPDATE recipients SET
+		active = 0,
+		deleted_at = CURRENT_TIMESTAMP,
+		duplicate_of = (
+			SELECT k.recipient_code FROM recipients k
+			WHERE k.bank_code = recipients.bank_code AND k.account_number = recipients.account_number AND k.deleted_at IS NULL
+			ORDER BY k.id LIMIT 1
+		)
+	WHERE deleted_at IS NULL AND id > (
+		SELECT MIN(k.id) FROM recipients k
+		WHERE k.bank_code = recipients.bank_code AND k.account_number = recipients.account_number AND k.deleted_at IS NULL
+	);`
+
+	result, err := DB.Exec(markDuplicates)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("Merged %d duplicate recipients", n)
+	}
+
+	for _, table := range []string{"recipient_aliases", "recipient_group_members", "beneficiary_limits"} {
+		duplicates := "SELECT recipient_code FROM recipients WHERE duplicate_of IS NOT NULL"
+		move := "UPDATE OR IGNORE " + table + " SET recipient_code = (SELECT duplicate_of FROM recipients r WHERE r.recipient_code = " +
+			table + ".recipient_code) WHERE recipient_code IN (" + duplicates + ")"
+		if _, err := DB.Exec(move); err != nil {
+			return err
+		}
+		if _, err := DB.Exec("DELETE FROM " + table + " WHERE recipient_code IN (" + duplicates + ")"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createSearchIndex creates the FTS5 index, its triggers and its vocabulary, and
+// rebuilds it from the tables. Without FTS5 the triggers are dropped instead, so a
+// database last opened by an FTS5 build still accepts writes.
+func createSearchIndex() error {
+	var enabled bool
+	if err := DB.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return err
+	}
+	if !enabled {
+		for _, src := range searchSources {
+			for _, suffix := range []string{"ai", "au", "ad"} {
+				DB.Exec("DROP TRIGGER IF EXISTS search_" + src.table + "_" + suffix)
+			}
+		}
+		log.Println("Full-text search disabled: build with -tags sqlite_fts5 to enable it")
+		return nil
+	}
+
+	createSearchIndexTable := `
+	CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+		entity_type UNINDEXED,
+		entity_id UNINDEXED,
+		title,
+		keywords,
+		body,
+		amount UNINDEXED,
+		currency UNINDEXED,
+		tokenize = 'unicode61 remove_diacritics 2'
+	);`
+
+	if _, err := DB.Exec(createSearchIndexTable); err != nil {
+		return err
+	}
+
+	// One row per distinct word, used to correct typos in queries
+	createSearchTermsTable := `CREATE VIRTUAL TABLE IF NOT EXISTS search_terms USING fts5vocab(search_index, 'row');`
+	if _, err := DB.Exec(createSearchTermsTable); err != nil {
+		return err
+	}
+
+	for _, src := range searchSources {
+		filter := src.filter
+		if filter == "" {
+			filter = "1"
+		}
+		insert := "INSERT INTO search_index (entity_type, entity_id, title, keywords, body, amount, currency) SELECT '" +
+			src.kind + "', CAST(" + src.key + " AS TEXT), " + src.title + ", " + src.keywords + ", " + src.body + ", " +
+			src.amount + ", " + src.currency + " FROM " + src.table + " WHERE " + filter
+		remove := "DELETE FROM search_index WHERE entity_type = '" + src.kind + "' AND entity_id = CAST(old." + src.key + " AS TEXT);"
+
+		triggers := []string{
+			"CREATE TRIGGER IF NOT EXISTS search_" + src.table + "_ai AFTER INSERT ON " + src.table + " BEGIN " +
+				insert + " AND rowid = new.rowid; END;",
+			"CREATE TRIGGER IF NOT EXISTS search_" + src.table + "_au AFTER UPDATE ON " + src.table + " BEGIN " +
+				remove + " " + insert + " AND rowid = new.rowid; END;",
+			"CREATE TRIGGER IF NOT EXISTS search_" + src.table + "_ad AFTER DELETE ON " + src.table + " BEGIN " +
+				remove + " END;",
+		}
+		// Recreated on every start, so changes to a source reach existing databases
+		for _, suffix := range []string{"ai", "au", "ad"} {
+			if _, err := DB.Exec("DROP TRIGGER IF EXISTS search_" + src.table + "_" + suffix); err != nil {
+				return err
+			}
+		}
+		for _, trigger := range triggers {
+			if _, err := DB.Exec(trigger); err != nil {
+				return err
+			}
+		}
+
+		// Rebuilt on every start, so writes made without FTS5 are picked up
+		if _, err := DB.Exec("DELETE FROM search_index WHERE entity_type = ?", src.kind); err != nil {
+			return err
+		}
+		if _, err := DB.Exec(insert); err != nil {
+			return err
+		}
+	}
+
+	SearchAvailable = true
+	log.Println("Search index created successfully")
+	return nil
+}
+
+// seedCategories adds any missing default categories, leaving edited ones alone
+func seedCategories() error {
+	for _, c := range categorize.Defaults {
+		_, err := DB.Exec(
+			"INSERT OR IGNORE INTO categories (slug, name, parent_slug) VALUES (?, ?, ?)",
+			c.Slug, c.Name, c.Parent,
+		)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
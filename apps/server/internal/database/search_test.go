@@ -0,0 +1,57 @@
+//go:build sqlite_fts5
+
+package database
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSearchIndexFollowsTables(t *testing.T) {
+	dbPath := "./test_search.db"
+	defer os.Remove(dbPath)
+
+	if err := Initialize(dbPath); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	if !SearchAvailable {
+		t.Fatal("Expected search to be available in an FTS5 build")
+	}
+
+	count := func(match string) int {
+		var n int
+		if err := DB.QueryRow("SELECT COUNT(*) FROM search_index WHERE search_index MATCH ?", match).Scan(&n); err != nil {
+			t.Fatalf("Search for %q failed: %v", match, err)
+		}
+		return n
+	}
+
+	if _, err := DB.Exec(`INSERT INTO expenses (recipient_code, recipient_name, amount, category, narration, reference)
+		VALUES ('RCP_serviceprovider', 'Service Provider', 500000, 'fuel', 'Diesel for the generator', 'EXP_search')`); err != nil {
+		t.Fatal(err)
+	}
+	if count("diesel") != 1 {
+		t.Error("Expected a new expense to be indexed")
+	}
+
+	if _, err := DB.Exec("UPDATE expenses SET narration = 'Petrol for the car' WHERE reference = 'EXP_search'"); err != nil {
+		t.Fatal(err)
+	}
+	if count("diesel") != 0 || count("petrol") != 1 {
+		t.Error("Expected an edited expense to be reindexed")
+	}
+
+	if _, err := DB.Exec("DELETE FROM expenses WHERE reference = 'EXP_search'"); err != nil {
+		t.Fatal(err)
+	}
+	if count("petrol") != 0 {
+		t.Error("Expected a deleted expense to leave the index")
+	}
+
+	// The default recipient is indexed when the index is built
+	if count(`"default" AND "bank"`) != 1 {
+		t.Error("Expected existing recipients to be indexed")
+	}
+}
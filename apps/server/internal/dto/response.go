@@ -9,6 +9,9 @@ type Response struct {
 	Status  bool        `json:"status"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+
+	// Warnings are things the caller should pass on even though the request succeeded
+	Warnings interface{} `json:"warnings,omitempty"`
 }
 
 // ErrorResponse represents an error response
@@ -0,0 +1,64 @@
+// Package export writes records out as CSV, XLSX, OFX and QIF.
+//
+// CSV and XLSX are tables: a header row, then one row of cells per record.
+// OFX and QIF are bank statements: one transaction per record, money out
+// negative. Every writer takes rows one at a time and sends them on as it
+// goes, so an export never has to hold the whole dataset.
+//
+// DESIGN DECISIONS:
+//   - Amounts are written in major units (1500.50), the way spreadsheets and
+//     accounting tools expect them; XLSX cells are real numbers and dates
+//   - Text that a spreadsheet would run as a formula is quoted (CSV injection)
+//   - XLSX is streamed through excelize's StreamWriter, which spills rows to a
+//     temporary file, so memory stays flat however many rows there are
+//   - OFX has one statement per currency, so transactions must arrive grouped
+//     by currency; QIF has no currency at all, so it accepts only one
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatOFX  = "ofx"
+	FormatQIF  = "qif"
+)
+
+// ParseFormat normalizes a format name; empty means CSV
+func ParseFormat(name string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(name)); f {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatXLSX, FormatOFX, FormatQIF:
+		return f, nil
+	}
+	return "", fmt.Errorf("format must be one of: csv, xlsx, ofx, qif")
+}
+
+// IsStatement reports whether the format is a bank statement rather than a table
+func IsStatement(format string) bool {
+	return format == FormatOFX || format == FormatQIF
+}
+
+// ContentType is the MIME type to serve a format with
+func ContentType(format string) string {
+	switch format {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatQIF:
+		return "application/qif"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Filename names a download, e.g. expenses-2026-10-18.xlsx
+func Filename(name, format string, at time.Time) string {
+	return fmt.Sprintf("%s-%s.%s", name, at.Format("2006-01-02"), format)
+}
@@ -0,0 +1,152 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"paystack.mpc.proxy/internal/money"
+	"paystack.mpc.proxy/internal/statement"
+)
+
+func TestCSVSheet(t *testing.T) {
+	var buf bytes.Buffer
+	sheet, err := NewSheet(&buf, FormatCSV, "expenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)
+	sheet.WriteHeader("id", "date", "amount", "narration", "paid_at")
+	sheet.WriteRow(1, day, money.New(150050, money.NGN), "=SUM(A1:A9)", &at)
+	sheet.WriteRow(2, day, money.New(-75, money.USD), "Lunch, with \"client\"", nil)
+	if err := sheet.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "id,date,amount,narration,paid_at\n" +
+		"1,2026-01-05,1500.50,'=SUM(A1:A9),2026-01-05 09:30:00\n" +
+		"2,2026-01-05,-0.75,\"Lunch, with \"\"client\"\"\",\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestXLSXSheet(t *testing.T) {
+	var buf bytes.Buffer
+	sheet, err := NewSheet(&buf, FormatXLSX, "Expenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet.WriteHeader("id", "amount", "narration")
+	for i := 1; i <= 3; i++ {
+		sheet.WriteRow(i, money.New(int64(i)*100000, money.NGN), "Fuel")
+	}
+	if err := sheet.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Expected a readable workbook: %v", err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows("Expenses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[0][1] != "amount" || rows[3][2] != "Fuel" {
+		t.Errorf("Unexpected rows %v", rows)
+	}
+	raw, _ := f.GetCellValue("Expenses", "B4", excelize.Options{RawCellValue: true})
+	if raw != "3000" {
+		t.Errorf("Expected amounts stored as numbers in major units, got %q", raw)
+	}
+}
+
+func TestOFXRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	st, err := NewStatement(&buf, FormatOFX, StatementOptions{Start: start, End: end})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs := []Transaction{
+		{ID: "EXP_1", Amount: money.New(-500000, money.NGN), Date: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), Payee: "Ada & Sons", Memo: "Fuel", Category: "fuel"},
+		{ID: "INV_1", Amount: money.New(2000000, money.NGN), Date: time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC), Payee: "Client"},
+		{ID: "EXP_2", Amount: money.New(-1500, money.USD), Date: time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC), Payee: "AWS"},
+	}
+	for _, tx := range txs {
+		if err := st.WriteTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "<STMTRS>"); n != 2 {
+		t.Errorf("Expected one statement per currency, got %d", n)
+	}
+	if !strings.Contains(buf.String(), "<DTSTART>20260101000000[0:GMT]</DTSTART>") {
+		t.Error("Expected the requested start date")
+	}
+
+	lines, err := statement.ParseOFX(buf.Bytes(), money.NGN)
+	if err != nil {
+		t.Fatalf("Expected our OFX to parse: %v", err)
+	}
+	if len(lines) != 3 || !lines[0].Debit || lines[0].Amount != 500000 || lines[0].Reference != "EXP_1" || lines[0].Counterparty != "Ada & Sons" {
+		t.Errorf("Unexpected lines %+v", lines)
+	}
+	if lines[1].Debit || lines[1].Amount != 2000000 {
+		t.Errorf("Expected the invoice as a credit, got %+v", lines[1])
+	}
+	if lines[2].Currency != money.USD || lines[2].Amount != 1500 {
+		t.Errorf("Expected the USD statement's currency, got %+v", lines[2])
+	}
+
+	// Without a start date each statement starts at its first transaction, and
+	// a currency can't come back once its statement is closed
+	var open bytes.Buffer
+	st, _ = NewStatement(&open, FormatOFX, StatementOptions{})
+	st.WriteTransaction(txs[0])
+	st.WriteTransaction(txs[2])
+	if err := st.WriteTransaction(txs[1]); err == nil {
+		t.Error("Expected an error when currencies aren't grouped")
+	}
+	st.Close()
+	if !strings.Contains(open.String(), "<DTSTART>20260107000000[0:GMT]</DTSTART>") {
+		t.Errorf("Expected the USD statement to start at its transaction:\n%s", open.String())
+	}
+}
+
+func TestQIF(t *testing.T) {
+	var buf bytes.Buffer
+	st, _ := NewStatement(&buf, FormatQIF, StatementOptions{})
+	st.WriteTransaction(Transaction{ID: "EXP_1", Amount: money.New(-500000, money.NGN), Date: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), Payee: "Ada\nObi", Category: "fuel"})
+	if err := st.WriteTransaction(Transaction{Amount: money.New(-1, money.USD)}); err == nil {
+		t.Error("Expected QIF to refuse a second currency")
+	}
+	st.Close()
+
+	want := "!Type:Bank\nD01/05/2026\nT-5000.00\nNEXP_1\nPAda Obi\nLfuel\n^\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected QIF:\n%s", buf.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat(" XLSX "); err != nil || f != FormatXLSX {
+		t.Errorf("Expected xlsx, got %q, %v", f, err)
+	}
+	if f, _ := ParseFormat(""); f != FormatCSV {
+		t.Errorf("Expected csv by default, got %q", f)
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("Expected pdf to be rejected")
+	}
+}
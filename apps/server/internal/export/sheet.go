@@ -0,0 +1,230 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+// maxXLSXRows is the most rows a worksheet can hold
+const maxXLSXRows = 1048576
+
+// Sheet writes a table one row at a time. Cells may be strings, integers,
+// floats, bools, time.Time, *time.Time, money.Money or nil.
+type Sheet interface {
+	WriteHeader(columns ...string) error
+	WriteRow(cells ...interface{}) error
+	// Close finishes the file; nothing may be written after it
+	Close() error
+}
+
+// NewSheet returns a CSV or XLSX writer. name titles the XLSX worksheet.
+func NewSheet(w io.Writer, format, name string) (Sheet, error) {
+	switch format {
+	case FormatCSV:
+		return &csvSheet{w: csv.NewWriter(w)}, nil
+	case FormatXLSX:
+		return newXLSXSheet(w, name)
+	}
+	return nil, fmt.Errorf("%s is not a spreadsheet format", format)
+}
+
+// csvSheet writes comma-separated rows
+type csvSheet struct {
+	w *csv.Writer
+}
+
+func (s *csvSheet) WriteHeader(columns ...string) error {
+	return s.w.Write(columns)
+}
+
+func (s *csvSheet) WriteRow(cells ...interface{}) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = csvCell(cell)
+	}
+	return s.w.Write(record)
+}
+
+func (s *csvSheet) Close() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// csvCell renders one cell as text
+func csvCell(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return safeText(v)
+	case money.Money:
+		return MajorUnits(v)
+	case time.Time:
+		return formatTime(v)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return formatTime(*v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case fmt.Stringer:
+		return safeText(v.String())
+	}
+	return fmt.Sprint(cell)
+}
+
+// safeText stops spreadsheets treating text as a formula
+func safeText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// formatTime writes a date alone when there is no time of day
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// MajorUnits formats an amount in major units with no symbol or separators, e.g. -1500.50
+func MajorUnits(m money.Money) string {
+	exp := m.Currency.Exponent()
+	minor := m.Minor()
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	if exp == 0 {
+		return sign + strconv.FormatInt(minor, 10)
+	}
+	scale := int64(1)
+	for i := 0; i < exp; i++ {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/scale, exp, minor%scale)
+}
+
+// xlsxSheet streams rows into a single worksheet
+type xlsxSheet struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+
+	headerStyle, moneyStyle, dateStyle, timeStyle int
+}
+
+func newXLSXSheet(w io.Writer, name string) (*xlsxSheet, error) {
+	file := excelize.NewFile()
+	if name == "" {
+		name = "Sheet1"
+	}
+	if err := file.SetSheetName("Sheet1", name); err != nil {
+		return nil, err
+	}
+	stream, err := file.NewStreamWriter(name)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the header in view while scrolling
+	if err := stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return nil, err
+	}
+
+	s := &xlsxSheet{w: w, file: file, stream: stream}
+	styles := []struct {
+		id    *int
+		style excelize.Style
+	}{
+		{&s.headerStyle, excelize.Style{Font: &excelize.Font{Bold: true}}},
+		{&s.moneyStyle, excelize.Style{NumFmt: 4}}, // #,##0.00
+		{&s.dateStyle, excelize.Style{NumFmt: 14}}, // m/d/yy, shown in the reader's locale
+		{&s.timeStyle, excelize.Style{NumFmt: 22}}, // m/d/yy h:mm
+	}
+	for _, st := range styles {
+		if *st.id, err = file.NewStyle(&st.style); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *xlsxSheet) next(values []interface{}) error {
+	if s.row >= maxXLSXRows {
+		return fmt.Errorf("XLSX worksheets hold at most %d rows; use CSV or narrow the filters", maxXLSXRows)
+	}
+	s.row++
+	cell, err := excelize.CoordinatesToCellName(1, s.row)
+	if err != nil {
+		return err
+	}
+	return s.stream.SetRow(cell, values)
+}
+
+func (s *xlsxSheet) WriteHeader(columns ...string) error {
+	values := make([]interface{}, len(columns))
+	for i, c := range columns {
+		values[i] = excelize.Cell{StyleID: s.headerStyle, Value: c}
+	}
+	return s.next(values)
+}
+
+func (s *xlsxSheet) WriteRow(cells ...interface{}) error {
+	values := make([]interface{}, len(cells))
+	for i, cell := range cells {
+		values[i] = s.cell(cell)
+	}
+	return s.next(values)
+}
+
+// cell turns a value into a typed, styled XLSX cell
+func (s *xlsxSheet) cell(cell interface{}) interface{} {
+	switch v := cell.(type) {
+	case nil:
+		return nil
+	case money.Money:
+		return excelize.Cell{StyleID: s.moneyStyle, Value: v.Major()}
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return s.cell(*v)
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		style := s.timeStyle
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 {
+			style = s.dateStyle
+		}
+		return excelize.Cell{StyleID: style, Value: v}
+	case string:
+		return v // XLSX stores strings as text, so no formula quoting is needed
+	case fmt.Stringer:
+		return v.String()
+	}
+	return cell
+}
+
+func (s *xlsxSheet) Close() error {
+	defer s.file.Close()
+	if err := s.stream.Flush(); err != nil {
+		return err
+	}
+	return s.file.Write(s.w)
+}
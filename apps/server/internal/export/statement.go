@@ -0,0 +1,211 @@
+package export
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+// Transaction is one line of an exported statement
+type Transaction struct {
+	ID string
+	// Amount is negative for money out
+	Amount   money.Money
+	Date     time.Time
+	Payee    string
+	Memo     string
+	Category string
+}
+
+// Statement writes transactions one at a time
+type Statement interface {
+	WriteTransaction(tx Transaction) error
+	// Close finishes the file; nothing may be written after it
+	Close() error
+}
+
+// StatementOptions describe the period and account the transactions belong to.
+// A zero Start is taken from each statement's first transaction, so
+// transactions should arrive oldest first; a zero End is Now.
+type StatementOptions struct {
+	Start, End time.Time
+	// AccountID identifies the account in OFX files
+	AccountID string
+	Now       time.Time
+}
+
+// NewStatement returns an OFX or QIF writer
+func NewStatement(w io.Writer, format string, opts StatementOptions) (Statement, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.End.IsZero() {
+		opts.End = opts.Now
+	}
+	if opts.AccountID == "" {
+		opts.AccountID = "moniewave"
+	}
+	switch format {
+	case FormatOFX:
+		return &ofxStatement{w: bufio.NewWriter(w), opts: opts}, nil
+	case FormatQIF:
+		return &qifStatement{w: bufio.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("%s is not a statement format", format)
+}
+
+// ofxStatement writes OFX 2.2 (XML). Each run of transactions in one currency
+// becomes its own bank statement.
+type ofxStatement struct {
+	w        *bufio.Writer
+	opts     StatementOptions
+	started  bool
+	currency money.Currency
+	seen     map[money.Currency]bool
+	balance  money.Money
+	count    int
+}
+
+func ofxDate(t time.Time) string {
+	return t.UTC().Format("20060102150405") + "[0:GMT]"
+}
+
+// ofxText trims a value to max characters and escapes it for XML
+func ofxText(s string, max int) string {
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) > max {
+		runes = runes[:max]
+	}
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(string(runes)))
+	return b.String()
+}
+
+func (s *ofxStatement) header() {
+	fmt.Fprintf(s.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+`, ofxDate(s.opts.Now))
+	s.started = true
+	s.seen = map[money.Currency]bool{}
+}
+
+func (s *ofxStatement) openStatement(currency money.Currency, first time.Time) error {
+	if s.seen[currency] {
+		return fmt.Errorf("OFX transactions must be grouped by currency; %s appeared twice", currency)
+	}
+	s.seen[currency] = true
+	s.currency = currency
+	s.balance = money.New(0, currency)
+	s.count++
+	start := s.opts.Start
+	if start.IsZero() {
+		start = first
+	}
+	fmt.Fprintf(s.w, `<STMTTRNRS><TRNUID>%d</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>MONIEWAVE</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`, s.count, currency, ofxText(s.opts.AccountID+"-"+string(currency), 22), ofxDate(start), ofxDate(s.opts.End))
+	return nil
+}
+
+// closeStatement ends the open statement. LEDGERBAL is the net of the exported
+// transactions, since the account's real balance isn't known here.
+func (s *ofxStatement) closeStatement() {
+	fmt.Fprintf(s.w, "</BANKTRANLIST>\n<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>\n</STMTRS></STMTTRNRS>\n",
+		MajorUnits(s.balance), ofxDate(s.opts.End))
+}
+
+func (s *ofxStatement) WriteTransaction(tx Transaction) error {
+	if !s.started {
+		s.header()
+	}
+	currency := tx.Amount.Currency.OrDefault()
+	if currency != s.currency {
+		if s.currency != "" {
+			s.closeStatement()
+		}
+		if err := s.openStatement(currency, tx.Date); err != nil {
+			return err
+		}
+	}
+	s.balance.Amount += tx.Amount.Amount
+
+	kind := "CREDIT"
+	if tx.Amount.Amount < 0 {
+		kind = "DEBIT"
+	}
+	memo := tx.Memo
+	if tx.Category != "" {
+		memo = strings.TrimSpace(memo + " [" + tx.Category + "]")
+	}
+	fmt.Fprintf(s.w, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+		kind, ofxDate(tx.Date), MajorUnits(tx.Amount), ofxText(tx.ID, 255), ofxText(tx.Payee, 32), ofxText(memo, 255))
+	return nil
+}
+
+func (s *ofxStatement) Close() error {
+	if !s.started {
+		s.header()
+	}
+	if s.currency != "" {
+		s.closeStatement()
+	}
+	fmt.Fprint(s.w, "</BANKMSGSRSV1>\n</OFX>\n")
+	return s.w.Flush()
+}
+
+// qifStatement writes a Quicken bank register. QIF has no currency field, so
+// every transaction must share one.
+type qifStatement struct {
+	w        *bufio.Writer
+	started  bool
+	currency money.Currency
+}
+
+// qifText keeps a value on one line
+func qifText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func (s *qifStatement) WriteTransaction(tx Transaction) error {
+	currency := tx.Amount.Currency.OrDefault()
+	if !s.started {
+		fmt.Fprint(s.w, "!Type:Bank\n")
+		s.started, s.currency = true, currency
+	}
+	if currency != s.currency {
+		return fmt.Errorf("QIF has no currency, so one file can't mix %s and %s", s.currency, currency)
+	}
+
+	fmt.Fprintf(s.w, "D%s\nT%s\n", tx.Date.Format("01/02/2006"), MajorUnits(tx.Amount))
+	if tx.ID != "" {
+		fmt.Fprintf(s.w, "N%s\n", qifText(tx.ID))
+	}
+	if tx.Payee != "" {
+		fmt.Fprintf(s.w, "P%s\n", qifText(tx.Payee))
+	}
+	if tx.Memo != "" {
+		fmt.Fprintf(s.w, "M%s\n", qifText(tx.Memo))
+	}
+	if tx.Category != "" {
+		fmt.Fprintf(s.w, "L%s\n", qifText(tx.Category))
+	}
+	_, err := fmt.Fprint(s.w, "^\n")
+	return err
+}
+
+func (s *qifStatement) Close() error {
+	if !s.started {
+		fmt.Fprint(s.w, "!Type:Bank\n")
+	}
+	return s.w.Flush()
+}
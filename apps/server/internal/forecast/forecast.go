@@ -0,0 +1,163 @@
+// Package forecast projects a daily balance from a starting balance and the
+// money expected in and out on each day.
+//
+// Callers gather the flows (unpaid invoices, scheduled payments, what is left in
+// budgets) and Project lays them over the days of the window, carrying each
+// day's closing balance into the next and flagging the days that end below zero
+// or below a floor.
+//
+// DESIGN DECISIONS:
+//   - Amounts are minor units of a single currency; callers convert beforehand
+//   - Days are calendar days in UTC, starting on the day of Start
+//   - Flows dated before the window (overdue invoices, late payments) land on
+//     its first day; flows after it are left out
+//   - A breach is reported once when the balance first crosses a line, not on
+//     every day it stays there
+package forecast
+
+import (
+	"sort"
+	"time"
+)
+
+// Flow is money expected in (positive Amount) or out (negative Amount) on a date
+type Flow struct {
+	Date        time.Time `json:"date"`
+	Amount      int64     `json:"amount"`
+	Kind        string    `json:"kind"`
+	Description string    `json:"description"`
+	Reference   string    `json:"reference,omitempty"`
+}
+
+// Options shape a projection
+type Options struct {
+	// Start is the first day projected
+	Start time.Time
+	// Days is how many days to project, Start included
+	Days int
+	// Floor is the lowest balance that isn't flagged
+	Floor int64
+}
+
+// Day is one projected day
+type Day struct {
+	Date       time.Time `json:"date"`
+	Opening    int64     `json:"opening"`
+	Inflow     int64     `json:"inflow"`
+	Outflow    int64     `json:"outflow"`
+	Closing    int64     `json:"closing"`
+	Negative   bool      `json:"negative"`
+	BelowFloor bool      `json:"below_floor"`
+	Flows      []Flow    `json:"flows"`
+}
+
+// Alert kinds
+const (
+	AlertNegative   = "negative"
+	AlertBelowFloor = "below_floor"
+)
+
+// Alert marks the day the balance first drops below zero or the floor
+type Alert struct {
+	Date    time.Time `json:"date"`
+	Kind    string    `json:"kind"`
+	Balance int64     `json:"balance"`
+}
+
+// Forecast is a projection over a window of days
+type Forecast struct {
+	Opening    int64     `json:"opening"`
+	Closing    int64     `json:"closing"`
+	Inflow     int64     `json:"inflow"`
+	Outflow    int64     `json:"outflow"`
+	Floor      int64     `json:"floor"`
+	Lowest     int64     `json:"lowest"`
+	LowestDate time.Time `json:"lowest_date"`
+	Days       []Day     `json:"days"`
+	Alerts     []Alert   `json:"alerts"`
+}
+
+// day truncates t to the start of its UTC calendar day
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Project lays flows over the window and carries the balance from day to day
+func Project(opening int64, flows []Flow, opts Options) Forecast {
+	if opts.Days < 1 {
+		opts.Days = 1
+	}
+	start := day(opts.Start)
+
+	f := Forecast{Opening: opening, Floor: opts.Floor, Days: make([]Day, opts.Days), Alerts: []Alert{}}
+	for i := range f.Days {
+		f.Days[i] = Day{Date: start.AddDate(0, 0, i), Flows: []Flow{}}
+	}
+
+	sorted := append([]Flow(nil), flows...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+	for _, flow := range sorted {
+		i := int(day(flow.Date).Sub(start).Hours() / 24)
+		if i < 0 {
+			i = 0
+		}
+		if i >= opts.Days || flow.Amount == 0 {
+			continue
+		}
+		d := &f.Days[i]
+		d.Flows = append(d.Flows, flow)
+		if flow.Amount > 0 {
+			d.Inflow += flow.Amount
+		} else {
+			d.Outflow -= flow.Amount
+		}
+	}
+
+	balance := opening
+	f.Lowest, f.LowestDate = opening, start
+	wasNegative, wasBelowFloor := opening < 0, opening < opts.Floor
+	for i := range f.Days {
+		d := &f.Days[i]
+		d.Opening = balance
+		balance += d.Inflow - d.Outflow
+		d.Closing = balance
+		d.Negative = balance < 0
+		d.BelowFloor = balance < opts.Floor
+		f.Inflow += d.Inflow
+		f.Outflow += d.Outflow
+
+		if balance < f.Lowest {
+			f.Lowest, f.LowestDate = balance, d.Date
+		}
+		// A negative balance is below any non-negative floor too; report the floor first
+		if d.BelowFloor && !wasBelowFloor && opts.Floor > 0 {
+			f.Alerts = append(f.Alerts, Alert{Date: d.Date, Kind: AlertBelowFloor, Balance: balance})
+		}
+		if d.Negative && !wasNegative {
+			f.Alerts = append(f.Alerts, Alert{Date: d.Date, Kind: AlertNegative, Balance: balance})
+		}
+		wasNegative, wasBelowFloor = d.Negative, d.BelowFloor
+	}
+	f.Closing = balance
+	return f
+}
+
+// Spread divides total evenly over the days from one date to another, both
+// included, giving any remainder to the earliest days so the parts add up to total
+func Spread(total int64, from, to time.Time) []int64 {
+	from, to = day(from), day(to)
+	if to.Before(from) {
+		return nil
+	}
+	n := int64(to.Sub(from).Hours()/24) + 1
+	parts := make([]int64, n)
+	share, rest := total/n, total%n
+	for i := range parts {
+		parts[i] = share
+		if int64(i) < rest {
+			parts[i]++
+		}
+	}
+	return parts
+}
@@ -0,0 +1,112 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestProjectCarriesBalanceAndFlagsBreaches(t *testing.T) {
+	flows := []Flow{
+		{Date: date("2026-11-03"), Amount: -60000, Kind: "scheduled_transfer"},
+		{Date: date("2026-11-02"), Amount: -30000, Kind: "schedule"},
+		{Date: date("2026-11-05"), Amount: 200000, Kind: "invoice"},
+		{Date: date("2026-11-04"), Amount: -20000, Kind: "budget"},
+	}
+	f := Project(100000, flows, Options{Start: date("2026-11-01").Add(15 * time.Hour), Days: 5, Floor: 50000})
+
+	wantClosing := []int64{100000, 70000, 10000, -10000, 190000}
+	for i, d := range f.Days {
+		if d.Closing != wantClosing[i] {
+			t.Errorf("Day %d closed at %d, want %d", i, d.Closing, wantClosing[i])
+		}
+	}
+	if f.Days[0].Date != date("2026-11-01") {
+		t.Errorf("Expected the first day to start at midnight, got %v", f.Days[0].Date)
+	}
+	if f.Days[3].Opening != 10000 || !f.Days[3].Negative || !f.Days[2].BelowFloor || f.Days[2].Negative {
+		t.Errorf("Unexpected flags: %+v %+v", f.Days[2], f.Days[3])
+	}
+	if f.Closing != 190000 || f.Inflow != 200000 || f.Outflow != 110000 {
+		t.Errorf("Totals = closing %d in %d out %d", f.Closing, f.Inflow, f.Outflow)
+	}
+	if f.Lowest != -10000 || f.LowestDate != date("2026-11-04") {
+		t.Errorf("Lowest = %d on %v", f.Lowest, f.LowestDate)
+	}
+
+	want := []Alert{
+		{Date: date("2026-11-03"), Kind: AlertBelowFloor, Balance: 10000},
+		{Date: date("2026-11-04"), Kind: AlertNegative, Balance: -10000},
+	}
+	if len(f.Alerts) != len(want) {
+		t.Fatalf("Alerts = %+v, want %+v", f.Alerts, want)
+	}
+	for i := range want {
+		if f.Alerts[i] != want[i] {
+			t.Errorf("Alert %d = %+v, want %+v", i, f.Alerts[i], want[i])
+		}
+	}
+}
+
+func TestProjectClampsFlowsToWindow(t *testing.T) {
+	flows := []Flow{
+		{Date: date("2026-10-01"), Amount: 5000, Kind: "invoice", Description: "overdue"},
+		{Date: date("2026-11-10"), Amount: -5000, Kind: "schedule", Description: "after the window"},
+	}
+	f := Project(0, flows, Options{Start: date("2026-11-01"), Days: 3})
+
+	if len(f.Days) != 3 || len(f.Days[0].Flows) != 1 || f.Days[0].Inflow != 5000 {
+		t.Fatalf("Expected the overdue invoice on the first day, got %+v", f.Days)
+	}
+	if f.Closing != 5000 || f.Outflow != 0 {
+		t.Errorf("Expected the later flow to be left out, closing %d outflow %d", f.Closing, f.Outflow)
+	}
+	if len(f.Alerts) != 0 {
+		t.Errorf("Expected no alerts with a zero floor and no negative day, got %+v", f.Alerts)
+	}
+}
+
+func TestProjectReportsEachCrossingOnce(t *testing.T) {
+	flows := []Flow{
+		{Date: date("2026-11-02"), Amount: -200},
+		{Date: date("2026-11-03"), Amount: 300},
+		{Date: date("2026-11-05"), Amount: -300},
+	}
+	f := Project(100, flows, Options{Start: date("2026-11-01"), Days: 6})
+
+	var negative []time.Time
+	for _, a := range f.Alerts {
+		if a.Kind == AlertNegative {
+			negative = append(negative, a.Date)
+		}
+	}
+	if len(negative) != 2 || negative[0] != date("2026-11-02") || negative[1] != date("2026-11-05") {
+		t.Errorf("Expected negative alerts on 2 and 5 November, got %v", negative)
+	}
+}
+
+func TestSpread(t *testing.T) {
+	parts := Spread(1000, date("2026-11-01"), date("2026-11-03").Add(9*time.Hour))
+	want := []int64{334, 333, 333}
+	if len(parts) != len(want) {
+		t.Fatalf("Spread = %v, want %v", parts, want)
+	}
+	var sum int64
+	for i := range want {
+		sum += parts[i]
+		if parts[i] != want[i] {
+			t.Errorf("Spread = %v, want %v", parts, want)
+			break
+		}
+	}
+	if sum != 1000 {
+		t.Errorf("Parts add up to %d, want 1000", sum)
+	}
+	if parts := Spread(1000, date("2026-11-03"), date("2026-11-01")); parts != nil {
+		t.Errorf("Expected nothing when to is before from, got %v", parts)
+	}
+}
@@ -0,0 +1,238 @@
+// Package fx converts money between currencies using dated exchange rates.
+//
+// Rates are loaded by hand or imported from a file, and each one applies from
+// its date until a newer rate for the same pair replaces it. Looking up a rate
+// "on" a date therefore returns the latest rate dated on or before it, which
+// lets past expenses be converted at the rate that applied when they happened.
+//
+// DESIGN DECISIONS:
+//   - A rate is the price of one major unit of Base in Quote (1 USD = 1550 NGN)
+//   - Dates are plain YYYY-MM-DD strings in UTC; rates have no time of day
+//   - A missing direct rate falls back to the inverse pair, then to a cross
+//     rate through one other currency, each at the same date
+//   - Conversion rounds half away from zero to the target's minor unit
+package fx
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+// DateLayout is the format of rate dates
+const DateLayout = "2006-01-02"
+
+// ErrNoRate is returned when no rate covers a pair on a date
+var ErrNoRate = errors.New("no exchange rate")
+
+// Rate is the price of one unit of Base in Quote from Date onwards
+type Rate struct {
+	Base   money.Currency `json:"base"`
+	Quote  money.Currency `json:"quote"`
+	Rate   float64        `json:"rate"`
+	Date   string         `json:"date"`
+	Source string         `json:"source,omitempty"`
+}
+
+// Validate checks the pair, rate and date
+func (r Rate) Validate() error {
+	if !r.Base.Valid() || !r.Quote.Valid() {
+		return fmt.Errorf("%w: %s/%s", money.ErrUnsupportedCurrency, r.Base, r.Quote)
+	}
+	if r.Base == r.Quote {
+		return fmt.Errorf("base and quote must differ")
+	}
+	if r.Rate <= 0 || math.IsInf(r.Rate, 0) || math.IsNaN(r.Rate) {
+		return fmt.Errorf("rate must be greater than 0")
+	}
+	if _, err := time.Parse(DateLayout, r.Date); err != nil {
+		return fmt.Errorf("date must be YYYY-MM-DD, got %q", r.Date)
+	}
+	return nil
+}
+
+// Inverse returns the rate for the opposite direction
+func (r Rate) Inverse() Rate {
+	return Rate{Base: r.Quote, Quote: r.Base, Rate: 1 / r.Rate, Date: r.Date, Source: r.Source}
+}
+
+// Day formats a time as a rate date
+func Day(t time.Time) string {
+	return t.UTC().Format(DateLayout)
+}
+
+// Convert applies the rate to an amount in its base currency
+func (r Rate) Convert(m money.Money) (money.Money, error) {
+	if m.Currency.OrDefault() != r.Base {
+		return money.Money{}, fmt.Errorf("%w: rate is for %s, amount is in %s", money.ErrCurrencyMismatch, r.Base, m.Currency.OrDefault())
+	}
+	scale := math.Pow10(r.Quote.Exponent() - r.Base.Exponent())
+	return money.New(int64(math.Round(float64(m.Amount)*r.Rate*scale)), r.Quote), nil
+}
+
+type pair struct {
+	base, quote money.Currency
+}
+
+// Table holds rates indexed by pair, each list sorted by date
+type Table struct {
+	rates map[pair][]Rate
+}
+
+// NewTable indexes rates for lookup. Later rates for the same pair and date win.
+func NewTable(rates []Rate) *Table {
+	t := &Table{rates: map[pair][]Rate{}}
+	for _, r := range rates {
+		p := pair{r.Base, r.Quote}
+		list := t.rates[p]
+		i := sort.Search(len(list), func(i int) bool { return list[i].Date >= r.Date })
+		if i < len(list) && list[i].Date == r.Date {
+			list[i] = r
+			continue
+		}
+		list = append(list, Rate{})
+		copy(list[i+1:], list[i:])
+		list[i] = r
+		t.rates[p] = list
+	}
+	return t
+}
+
+// direct finds the latest stored rate for exactly base/quote on or before day
+func (t *Table) direct(base, quote money.Currency, day string) (Rate, bool) {
+	list := t.rates[pair{base, quote}]
+	i := sort.Search(len(list), func(i int) bool { return list[i].Date > day })
+	if i == 0 {
+		return Rate{}, false
+	}
+	return list[i-1], true
+}
+
+// pairRate finds base/quote directly or through the inverse pair
+func (t *Table) pairRate(base, quote money.Currency, day string) (Rate, bool) {
+	if r, ok := t.direct(base, quote, day); ok {
+		return r, true
+	}
+	if r, ok := t.direct(quote, base, day); ok {
+		return r.Inverse(), true
+	}
+	return Rate{}, false
+}
+
+// Lookup returns the rate from one currency to another in effect on a date.
+// The same currency always converts at 1.
+func (t *Table) Lookup(from, to money.Currency, on time.Time) (Rate, error) {
+	from, to = from.OrDefault(), to.OrDefault()
+	day := Day(on)
+	if from == to {
+		return Rate{Base: from, Quote: to, Rate: 1, Date: day}, nil
+	}
+	if r, ok := t.pairRate(from, to, day); ok {
+		return r, nil
+	}
+
+	// Cross through another currency, dated by the older of the two legs
+	for _, via := range money.Currencies() {
+		if via == from || via == to {
+			continue
+		}
+		first, ok := t.pairRate(from, via, day)
+		if !ok {
+			continue
+		}
+		second, ok := t.pairRate(via, to, day)
+		if !ok {
+			continue
+		}
+		date := first.Date
+		if second.Date < date {
+			date = second.Date
+		}
+		return Rate{Base: from, Quote: to, Rate: first.Rate * second.Rate, Date: date, Source: "cross:" + string(via)}, nil
+	}
+	return Rate{}, fmt.Errorf("%w for %s/%s on or before %s", ErrNoRate, from, to, day)
+}
+
+// Convert converts an amount into another currency at the rate in effect on a date
+func (t *Table) Convert(m money.Money, to money.Currency, on time.Time) (money.Money, Rate, error) {
+	rate, err := t.Lookup(m.Currency, to, on)
+	if err != nil {
+		return money.Money{}, Rate{}, err
+	}
+	converted, err := rate.Convert(m)
+	return converted, rate, err
+}
+
+// ParseCSV reads rates from CSV with a header naming the date, base, quote and
+// rate columns in any order. A source column is optional. Blank lines are skipped.
+func ParseCSV(r io.Reader) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("rates file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"date", "base", "quote", "rate"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("header must include date, base, quote and rate columns")
+		}
+	}
+
+	cell := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rates []Rate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		base, err := money.ParseCurrency(cell(record, "base"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		quote, err := money.ParseCurrency(cell(record, "quote"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		value, err := strconv.ParseFloat(strings.ReplaceAll(cell(record, "rate"), ",", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: rate must be a number", line)
+		}
+		rate := Rate{Base: base, Quote: quote, Rate: value, Date: cell(record, "date"), Source: cell(record, "source")}
+		if err := rate.Validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
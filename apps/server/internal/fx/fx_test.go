@@ -0,0 +1,113 @@
+package fx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+func day(s string) time.Time {
+	t, _ := time.Parse(DateLayout, s)
+	return t
+}
+
+func TestLookupUsesLatestRateOnOrBeforeDate(t *testing.T) {
+	table := NewTable([]Rate{
+		{Base: money.USD, Quote: money.NGN, Rate: 1500, Date: "2026-01-01"},
+		{Base: money.USD, Quote: money.NGN, Rate: 1600, Date: "2026-03-01"},
+		{Base: money.USD, Quote: money.NGN, Rate: 1550, Date: "2026-02-01"},
+	})
+
+	tests := []struct {
+		on   string
+		want float64
+	}{
+		{"2026-01-15", 1500},
+		{"2026-02-01", 1550},
+		{"2026-02-28", 1550},
+		{"2026-06-01", 1600},
+	}
+	for _, tt := range tests {
+		r, err := table.Lookup(money.USD, money.NGN, day(tt.on))
+		if err != nil || r.Rate != tt.want {
+			t.Errorf("Lookup on %s = %v %v, want %v", tt.on, r.Rate, err, tt.want)
+		}
+	}
+
+	if _, err := table.Lookup(money.USD, money.NGN, day("2025-12-31")); !errors.Is(err, ErrNoRate) {
+		t.Errorf("Expected no rate before the first one, got %v", err)
+	}
+}
+
+func TestLookupFallsBackToInverseAndCross(t *testing.T) {
+	table := NewTable([]Rate{
+		{Base: money.USD, Quote: money.NGN, Rate: 1600, Date: "2026-01-01"},
+		{Base: money.USD, Quote: money.GHS, Rate: 16, Date: "2026-01-10"},
+	})
+	on := day("2026-02-01")
+
+	inverse, err := table.Lookup(money.NGN, money.USD, on)
+	if err != nil || inverse.Rate != 1.0/1600 {
+		t.Errorf("Expected the inverse rate, got %v %v", inverse.Rate, err)
+	}
+
+	cross, err := table.Lookup(money.GHS, money.NGN, on)
+	if err != nil || cross.Rate != 100 || cross.Date != "2026-01-01" || cross.Source != "cross:USD" {
+		t.Errorf("Expected a GHS/NGN cross of 100 via USD dated 2026-01-01, got %+v %v", cross, err)
+	}
+
+	same, err := table.Lookup(money.NGN, "", on)
+	if err != nil || same.Rate != 1 {
+		t.Errorf("Expected NGN to NGN at 1, got %v %v", same.Rate, err)
+	}
+}
+
+func TestConvertRoundsToMinorUnits(t *testing.T) {
+	table := NewTable([]Rate{{Base: money.USD, Quote: money.NGN, Rate: 1550.255, Date: "2026-01-01"}})
+
+	got, rate, err := table.Convert(money.New(1500, money.USD), money.NGN, day("2026-01-02"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Amount != 2325383 || got.Currency != money.NGN || rate.Date != "2026-01-01" {
+		t.Errorf("Expected 2325383 NGN at the 2026-01-01 rate, got %+v %+v", got, rate)
+	}
+
+	back, _, _ := table.Convert(got, money.USD, day("2026-01-02"))
+	if back.Amount != 1500 {
+		t.Errorf("Expected 1500 cents back, got %d", back.Amount)
+	}
+
+	if _, err := rate.Convert(money.New(100, money.GHS)); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("Expected a mismatch converting GHS with a USD rate, got %v", err)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	rates, err := ParseCSV(strings.NewReader("Rate,Date,Base,Quote,Source\n\"1,550.50\",2026-01-01,usd,ngn,cbn\n\n16.2,2026-01-01,USD,GHS,\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rates) != 2 {
+		t.Fatalf("Expected 2 rates, got %d", len(rates))
+	}
+	if rates[0] != (Rate{Base: money.USD, Quote: money.NGN, Rate: 1550.5, Date: "2026-01-01", Source: "cbn"}) {
+		t.Errorf("Unexpected first rate: %+v", rates[0])
+	}
+
+	bad := []string{
+		"",
+		"date,base,rate\n2026-01-01,USD,1500\n",
+		"date,base,quote,rate\n2026-01-01,USD,XYZ,1500\n",
+		"date,base,quote,rate\n2026-01-01,USD,NGN,-1\n",
+		"date,base,quote,rate\n01/02/2026,USD,NGN,1500\n",
+	}
+	for _, input := range bad {
+		if _, err := ParseCSV(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Analytics Handler - Reporting
+//
+// OBJECTIVES:
+// Users need to know how money is moving, not just a raw list of payments.
+//
+// PURPOSE:
+// - Aggregate transactions, expenses, transfers and invoices over a date range
+// - Group by day, week, month, category, recipient or status
+// - Return sums, counts, averages and period-over-period deltas
+// - Back the voice agent's aggregate_transactions tool
+//
+// KEY WORKFLOW:
+// Resolve Metric/Source → Parse Date Range → Load Local Rows (current + previous range) →
+// Filter → Group → Compare With Previous Period → Return Report
+//
+// DESIGN DECISIONS:
+// - Reads only from local tables (transactions cache, expenses, outgoing payments, invoices), so it is fast and works offline
+// - Transfers come from the outgoing_payments log written by the transfer limits check
+// - "metric" is a shortcut that picks the source, status and headline value the client asks for
+// - Dates accept RFC3339 or YYYY-MM-DD; a date-only "to" includes the whole day
+// - Defaults to the last 30 days with a grouping sized to the range
+// - Filtering by a parent category (transport) includes its children (ride_hailing, fuel)
+// - Reports cover one currency; "convert_to" folds the others in at the rate on each row's date,
+//   and rows with no rate are left out and listed rather than guessed
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/analytics"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+)
+
+type AnalyticsHandler struct{}
+
+func NewAnalyticsHandler() *AnalyticsHandler {
+	return &AnalyticsHandler{}
+}
+
+type AggregateFilters struct {
+	BeneficiaryID string `json:"beneficiary_id,omitempty"`
+	RecipientCode string `json:"recipient_code,omitempty"`
+	Category      string `json:"category,omitempty"`
+	Status        string `json:"status,omitempty"`
+}
+
+type AggregateRequest struct {
+	Metric   string           `json:"metric,omitempty"`
+	Source   string           `json:"source,omitempty"`
+	GroupBy  string           `json:"group_by,omitempty"`
+	From     string           `json:"from,omitempty"`
+	To       string           `json:"to,omitempty"`
+	Currency string           `json:"currency,omitempty"`
+	Filters  AggregateFilters `json:"filters,omitempty"`
+
+	// ConvertTo converts every row into this currency instead of filtering by currency
+	ConvertTo money.Currency `json:"convert_to,omitempty"`
+	Limit    int              `json:"limit,omitempty"`
+}
+
+// AggregateResponse is the analytics report plus the headline metric value
+type AggregateResponse struct {
+	Metric   string `json:"metric,omitempty"`
+	Value    int64  `json:"value"`
+	Source   string `json:"source"`
+	Currency string `json:"currency"`
+	*analytics.Report
+
+	Conversion *AggregateConversion `json:"conversion,omitempty"`
+}
+
+// AggregateConversion explains how rows in other currencies were converted
+type AggregateConversion struct {
+	To             money.Currency                  `json:"to"`
+	ConvertedRows  int                             `json:"converted_rows"`
+	SkippedRows    int                             `json:"skipped_rows"`
+	MissingRates   []string                        `json:"missing_rates,omitempty"`
+	OriginalTotals map[money.Currency]money.Amount `json:"original_totals"`
+}
+
+// aggregateMetric maps a client metric onto a source, default status and value
+type aggregateMetric struct {
+	Source  string
+	Status  string
+	Count   bool
+	GroupBy analytics.GroupBy
+}
+
+var aggregateMetrics = map[string]aggregateMetric{
+	"total_transfers_value":    {Source: "transfers"},
+	"total_transfers_count":    {Source: "transfers", Count: true},
+	"total_transactions_value": {Source: "transactions", Status: "success"},
+	"total_transactions_count": {Source: "transactions", Status: "success", Count: true},
+	"total_invoices_value":     {Source: "invoices"},
+	"total_invoices_count":     {Source: "invoices", Count: true},
+	"top_categories":           {Source: "expenses", GroupBy: analytics.GroupByCategory},
+}
+
+// aggregateSources are the local queries feeding the report. Each selects
+// amount, currency, category, recipient, recipient code, status and time.
+var aggregateSources = map[string]string{
+	"transactions": `
+		SELECT amount, currency, COALESCE(channel, ''), COALESCE(customer_email, ''),
+		       COALESCE(customer_code, ''), COALESCE(status, ''), transaction_date
+		FROM transactions WHERE transaction_date >= ? AND transaction_date < ?`,
+	"expenses": `
+		SELECT amount, currency, COALESCE(category, ''), recipient_name,
+		       recipient_code, COALESCE(status, ''), created_at
+		FROM expenses WHERE created_at >= ? AND created_at < ?`,
+	"transfers": `
+		SELECT op.amount, op.currency, '', COALESCE(r.name, op.recipient_code),
+		       op.recipient_code, 'sent', op.created_at
+		FROM outgoing_payments op
+		LEFT JOIN recipients r ON r.recipient_code = op.recipient_code
+		WHERE op.source = 'transfer' AND op.created_at >= ? AND op.created_at < ?`,
+	"invoices": `
+		SELECT amount, COALESCE(currency, 'NGN'), '', customer_name, customer_id, COALESCE(status, ''), created_at
+		FROM invoices WHERE created_at >= ? AND created_at < ?`,
+}
+
+// parseRangeTime accepts RFC3339 or YYYY-MM-DD. Date-only end bounds cover the whole day.
+func parseRangeTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD or RFC3339", value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// loadAggregateRows reads a source's rows between from and to
+func loadAggregateRows(source string, from, to time.Time) ([]analytics.Row, error) {
+	// Widen the SQL window by a day so stored timezone offsets can't drop rows;
+	// analytics.Aggregate applies the exact bounds
+	rows, err := database.DB.Query(aggregateSources[source], from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", source, err)
+	}
+	defer rows.Close()
+
+	var result []analytics.Row
+	for rows.Next() {
+		var row analytics.Row
+		if err := rows.Scan(
+			&row.Amount,
+			&row.Currency,
+			&row.Category,
+			&row.Recipient,
+			&row.RecipientCode,
+			&row.Status,
+			&row.Time,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", source, err)
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// convertAggregateRows converts rows into one currency at the rate on each row's date.
+// Rows with no rate are dropped. Counts and original totals cover rows in [from, to)
+// that pass the filter, so they line up with the report.
+func convertAggregateRows(rows []analytics.Row, target money.Currency, from, to time.Time, filter analytics.Filter) ([]analytics.Row, *AggregateConversion, error) {
+	table, err := loadFXTable()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conversion := &AggregateConversion{To: target, OriginalTotals: map[money.Currency]money.Amount{}}
+	missing := map[string]bool{}
+	converted := make([]analytics.Row, 0, len(rows))
+	for _, row := range rows {
+		currency := money.Currency(strings.ToUpper(row.Currency)).OrDefault()
+		counted := !row.Time.Before(from) && row.Time.Before(to) && filter.Match(row)
+		if counted {
+			conversion.OriginalTotals[currency] += money.Amount(row.Amount)
+		}
+		if currency == target {
+			converted = append(converted, row)
+			continue
+		}
+
+		amount, _, err := table.Convert(money.New(row.Amount, currency), target, row.Time)
+		if err != nil {
+			if counted {
+				conversion.SkippedRows++
+				pair := string(currency) + "/" + string(target)
+				if !missing[pair] {
+					missing[pair] = true
+					conversion.MissingRates = append(conversion.MissingRates, pair)
+				}
+			}
+			continue
+		}
+		if counted {
+			conversion.ConvertedRows++
+		}
+		row.Amount = amount.Minor()
+		row.Currency = string(target)
+		converted = append(converted, row)
+	}
+	return converted, conversion, nil
+}
+
+// rowsInCategories keeps rows in any of the categories
+func rowsInCategories(rows []analytics.Row, categories []string) []analytics.Row {
+	kept := rows[:0]
+	for _, row := range rows {
+		for _, category := range categories {
+			if strings.EqualFold(row.Category, category) {
+				kept = append(kept, row)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// Aggregate groups local money movements and compares them with the previous period
+func (h *AnalyticsHandler) Aggregate(w http.ResponseWriter, r *http.Request) {
+	var req AggregateRequest
+	if r.Body != http.NoBody {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteJSONBadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	// Resolve metric shortcut
+	var metric aggregateMetric
+	if req.Metric != "" {
+		m, ok := aggregateMetrics[req.Metric]
+		if !ok {
+			WriteJSONBadRequest(w, fmt.Sprintf("unknown metric: %s", req.Metric))
+			return
+		}
+		metric = m
+		if req.Source == "" {
+			req.Source = m.Source
+		}
+		if req.Filters.Status == "" {
+			req.Filters.Status = m.Status
+		}
+	}
+
+	if req.Source == "" {
+		req.Source = "transactions"
+	}
+	if _, ok := aggregateSources[req.Source]; !ok {
+		WriteJSONBadRequest(w, "source must be one of: transactions, expenses, transfers, invoices")
+		return
+	}
+
+	// Parse date range (default: last 30 days)
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -30)
+	var err error
+	if req.To != "" {
+		if to, err = parseRangeTime(req.To, true); err != nil {
+			WriteJSONBadRequest(w, err.Error())
+			return
+		}
+	}
+	if req.From != "" {
+		if from, err = parseRangeTime(req.From, false); err != nil {
+			WriteJSONBadRequest(w, err.Error())
+			return
+		}
+	}
+	if !from.Before(to) {
+		WriteJSONBadRequest(w, "from must be before to")
+		return
+	}
+
+	groupBy := metric.GroupBy
+	if req.GroupBy != "" {
+		g, ok := analytics.ParseGroupBy(req.GroupBy)
+		if !ok {
+			WriteJSONBadRequest(w, "group_by must be one of: day, week, month, category, recipient, status")
+			return
+		}
+		groupBy = g
+	}
+	if groupBy == "" {
+		groupBy = analytics.DefaultGroupBy(from, to)
+	}
+
+	if req.Currency == "" {
+		req.Currency = "NGN"
+	}
+
+	previousFrom, _ := analytics.PreviousRange(from, to)
+	rows, err := loadAggregateRows(req.Source, previousFrom, to)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	recipient := req.Filters.RecipientCode
+	if recipient == "" {
+		recipient = req.Filters.BeneficiaryID
+	}
+	filter := analytics.Filter{
+		Currency:  strings.ToUpper(req.Currency),
+		Category:  req.Filters.Category,
+		Status:    req.Filters.Status,
+		Recipient: recipient,
+	}
+
+	// A parent category covers everything under it
+	if filter.Category != "" {
+		if categories := expandCategory(filter.Category); len(categories) > 1 {
+			rows = rowsInCategories(rows, categories)
+			filter.Category = ""
+		}
+	}
+
+	// Convert everything into one currency when asked, instead of filtering the others out
+	var conversion *AggregateConversion
+	if req.ConvertTo != "" {
+		filter.Currency = ""
+		if rows, conversion, err = convertAggregateRows(rows, req.ConvertTo, from, to, filter); err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+		req.Currency = string(req.ConvertTo)
+		filter.Currency = req.Currency
+	}
+
+	report := analytics.Aggregate(rows, groupBy, from, to, filter)
+
+	if req.Limit > 0 && !groupBy.IsTime() && len(report.Groups) > req.Limit {
+		report.Groups = report.Groups[:req.Limit]
+	}
+
+	response := AggregateResponse{
+		Metric:   req.Metric,
+		Value:    report.Totals.Sum,
+		Source:   req.Source,
+		Currency: strings.ToUpper(req.Currency),
+		Report:   report,
+
+		Conversion: conversion,
+	}
+	if metric.Count {
+		response.Value = int64(report.Totals.Count)
+	}
+
+	WriteJSONSuccess(w, response)
+}
@@ -0,0 +1,311 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Anomalies Handler - Duplicate and Unusual Payment Detection
+//
+// OBJECTIVES:
+// Nothing stopped the same recipient being paid the same amount twice in a minute,
+// and nothing flagged an expense far larger than usual.
+//
+// PURPOSE:
+// - Check expenses and transfers for likely duplicates, amounts far above the
+//   recipient's or category's history, and large first payments to a recipient
+// - Return warnings with the preview and with the created payment
+// - Log flagged payments so they can be reviewed later
+//
+// KEY WORKFLOW:
+// Preview → Detect → Warn → Confirm → Detect Again → Pay → Log Warnings → Review
+//
+// DESIGN DECISIONS:
+// - The rules live in internal/anomaly; this file loads history and stores events
+// - Warnings never block a payment, and a failure to load history only skips detection
+// - Recipient history is outgoing_payments, so expenses, transfers, schedules and
+//   scheduled transfers to the same recipient are all compared
+// - Category history is expenses in that category, leaving out cancelled, refunded
+//   and failed ones like the ledger
+// - Detection runs again at confirmation, since a duplicate may have gone out since
+//   the preview; only warnings on payments that went through are logged
+// - The first-payment threshold is set in NGN and converted into the payment's currency
+//   at today's rate; without a rate the check is skipped
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"paystack.mpc.proxy/internal/anomaly"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// anomalyHistoryLimit caps the past payments a new one is compared with
+const anomalyHistoryLimit = 500
+
+// Anomaly event statuses
+const (
+	anomalyOpen      = "open"
+	anomalyReviewed  = "reviewed"
+	anomalyDismissed = "dismissed"
+)
+
+var errAnomalyNotFound = errors.New("anomaly event not found")
+
+// anomalyConfig is the detection config; the threshold is in NGN
+var anomalyConfig = anomaly.DefaultConfig
+
+// ConfigureAnomalyDetection sets the duplicate window and the first-payment threshold, in kobo
+func ConfigureAnomalyDetection(duplicateWindow time.Duration, firstPaymentThreshold int64) {
+	anomalyConfig.DuplicateWindow = duplicateWindow
+	anomalyConfig.FirstPaymentThreshold = money.New(firstPaymentThreshold, money.NGN)
+}
+
+type AnomalyHandler struct{}
+
+func NewAnomalyHandler() *AnomalyHandler {
+	return &AnomalyHandler{}
+}
+
+// AnomalyEvent is a flagged payment kept for review
+type AnomalyEvent struct {
+	ID            int    `json:"id"`
+	Kind          string `json:"kind"`
+	Source        string `json:"source"`
+	Reference     string `json:"reference"`
+	RecipientCode string `json:"recipient_code"`
+	RecipientName string `json:"recipient_name"`
+	money.Money
+	Narration  string           `json:"narration"`
+	Category   string           `json:"category,omitempty"`
+	Message    string           `json:"message"`
+	Detail     *anomaly.Warning `json:"detail,omitempty"`
+	Status     string           `json:"status"`
+	Note       string           `json:"note,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	ReviewedAt *time.Time       `json:"reviewed_at,omitempty"`
+}
+
+type ReviewAnomalyRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note,omitempty"`
+}
+
+// checkPayment returns the warnings for a payment about to be made
+func checkPayment(p anomaly.Payment) []anomaly.Warning {
+	p.Currency = p.Currency.OrDefault()
+	if p.At.IsZero() {
+		p.At = time.Now()
+	}
+
+	var history anomaly.History
+	var err error
+	if history.Recipient, err = recipientPaymentHistory(p.RecipientCode); err != nil {
+		fmt.Printf("Warning: skipping anomaly detection: %v\n", err)
+		return nil
+	}
+	if p.Category != "" {
+		if history.Category, err = categoryPaymentHistory(p.Category); err != nil {
+			fmt.Printf("Warning: skipping anomaly detection: %v\n", err)
+			return nil
+		}
+	}
+
+	cfg := anomalyConfig
+	if threshold := cfg.FirstPaymentThreshold; threshold.Amount > 0 {
+		converted, _, err := ConvertMoney(threshold, p.Currency, p.At)
+		if err != nil {
+			converted = money.New(0, p.Currency)
+		}
+		cfg.FirstPaymentThreshold = converted
+	}
+	return anomaly.Detect(p, history, cfg)
+}
+
+// recipientPaymentHistory loads recent outgoing payments to a recipient
+func recipientPaymentHistory(recipientCode string) ([]anomaly.Payment, error) {
+	rows, err := database.DB.Query(`
+		SELECT COALESCE(reference, ''), recipient_code, amount, COALESCE(currency, 'NGN'), COALESCE(narration, ''), created_at
+		FROM outgoing_payments
+		WHERE recipient_code = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, recipientCode, anomalyHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payment history: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []anomaly.Payment
+	for rows.Next() {
+		var p anomaly.Payment
+		if err := rows.Scan(&p.Reference, &p.RecipientCode, &p.Amount, &p.Currency, &p.Narration, &p.At); err != nil {
+			return nil, fmt.Errorf("failed to scan payment history: %w", err)
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+// categoryPaymentHistory loads recent expenses in a category
+func categoryPaymentHistory(category string) ([]anomaly.Payment, error) {
+	rows, err := database.DB.Query(`
+		SELECT COALESCE(reference, ''), recipient_code, amount, COALESCE(currency, 'NGN'), COALESCE(narration, ''), category, created_at
+		FROM expenses
+		WHERE category = ? AND status NOT IN ('cancelled', 'refunded', 'failed')
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, category, anomalyHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category history: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []anomaly.Payment
+	for rows.Next() {
+		var p anomaly.Payment
+		if err := rows.Scan(&p.Reference, &p.RecipientCode, &p.Amount, &p.Currency, &p.Narration, &p.Category, &p.At); err != nil {
+			return nil, fmt.Errorf("failed to scan category history: %w", err)
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+// recordAnomalies logs the warnings on a payment that went through, one event per warning
+func recordAnomalies(source string, p anomaly.Payment, warnings []anomaly.Warning) {
+	for _, warning := range warnings {
+		detail, _ := json.Marshal(warning)
+		_, err := database.DB.Exec(`
+			INSERT INTO anomaly_events (
+				kind, source, reference, recipient_code, recipient_name, amount, currency,
+				narration, category, message, detail, status, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, warning.Kind, source, p.Reference, p.RecipientCode, p.RecipientName, p.Amount, p.Currency.OrDefault(),
+			p.Narration, p.Category, warning.Message, string(detail), anomalyOpen, time.Now())
+		if err != nil {
+			fmt.Printf("Warning: Failed to record %s anomaly for %s: %v\n", warning.Kind, p.Reference, err)
+		}
+	}
+}
+
+const anomalyEventColumns = `
+	id, kind, source, COALESCE(reference, ''), recipient_code, COALESCE(recipient_name, ''), amount,
+	COALESCE(currency, 'NGN'), COALESCE(narration, ''), COALESCE(category, ''), message,
+	COALESCE(detail, ''), status, COALESCE(note, ''), created_at, reviewed_at`
+
+func scanAnomalyEvent(row rowScanner) (*AnomalyEvent, error) {
+	var e AnomalyEvent
+	var detail string
+	var reviewedAt sql.NullTime
+	if err := row.Scan(
+		&e.ID, &e.Kind, &e.Source, &e.Reference, &e.RecipientCode, &e.RecipientName, &e.Amount,
+		&e.Currency, &e.Narration, &e.Category, &e.Message,
+		&detail, &e.Status, &e.Note, &e.CreatedAt, &reviewedAt,
+	); err != nil {
+		return nil, err
+	}
+	if detail != "" {
+		var w anomaly.Warning
+		if err := json.Unmarshal([]byte(detail), &w); err == nil {
+			e.Detail = &w
+		}
+	}
+	if reviewedAt.Valid {
+		e.ReviewedAt = &reviewedAt.Time
+	}
+	return &e, nil
+}
+
+func loadAnomalyEvent(id int) (*AnomalyEvent, error) {
+	e, err := scanAnomalyEvent(database.DB.QueryRow("SELECT "+anomalyEventColumns+" FROM anomaly_events WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, errAnomalyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load anomaly event: %w", err)
+	}
+	return e, nil
+}
+
+// List lists flagged payments, newest first. Query: status, kind.
+func (h *AnomalyHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + anomalyEventColumns + " FROM anomaly_events WHERE 1=1"
+	var args []interface{}
+	if status := r.URL.Query().Get("status"); status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		query += " AND kind = ?"
+		args = append(args, kind)
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to query anomaly events: %w", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	events := []*AnomalyEvent{}
+	for rows.Next() {
+		e, err := scanAnomalyEvent(rows)
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to scan anomaly event: %w", err), http.StatusInternalServerError)
+			return
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		WriteJSONError(w, fmt.Errorf("error iterating anomaly events: %w", err), http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccess(w, events)
+}
+
+// Review marks a flagged payment as reviewed (it was fine, or has been dealt with) or dismissed
+func (h *AnomalyHandler) Review(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		WriteJSONBadRequest(w, "id must be a positive integer")
+		return
+	}
+	var req ReviewAnomalyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.Status == "" {
+		req.Status = anomalyReviewed
+	}
+	if req.Status != anomalyReviewed && req.Status != anomalyDismissed {
+		WriteJSONBadRequest(w, "status must be one of: reviewed, dismissed")
+		return
+	}
+
+	result, err := database.DB.Exec(
+		"UPDATE anomaly_events SET status = ?, note = ?, reviewed_at = ? WHERE id = ?",
+		req.Status, req.Note, time.Now(), id,
+	)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to review anomaly event: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		WriteJSONError(w, errAnomalyNotFound, http.StatusNotFound)
+		return
+	}
+
+	e, err := loadAnomalyEvent(id)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, "Anomaly "+req.Status, e)
+}
@@ -9,24 +9,36 @@
 // - List Nigerian banks and their codes
 // - Resolve account numbers to verify account ownership
 // - Enable accurate recipient creation for transfers
+// - Cache resolved account names (recipient creation resolves every account)
 //
 // KEY WORKFLOW:
-// List Banks → User Selects Bank → Resolve Account Number → Verify Account Details
+// List Banks → User Selects Bank → Resolve Account Number (cache, then Paystack) →
+// Verify Account Details
 //
 // DESIGN DECISIONS:
 // - Bank list fetched from Paystack for up-to-date information
 // - Account resolution validates account ownership before transfers
-// - No local caching (banks list is small and changes infrequently)
+// - Banks list isn't cached (it is small and changes infrequently)
+// - Resolved names are reused for 30 days; failed lookups aren't cached, so a
+//   mistyped number can be retried as soon as it's corrected
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"paystack.mpc.proxy/internal/database"
 	"paystack.mpc.proxy/internal/paystack"
 )
 
+// accountResolutionTTL is how long a resolved account name is reused
+const accountResolutionTTL = 30 * 24 * time.Hour
+
 type BankHandler struct {
 	client *paystack.Client
 }
@@ -61,10 +73,86 @@ func (h *BankHandler) ResolveAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.client.Bank.ResolveAccountNumber(req.AccountNumber, req.BankCode)
+	result, err := resolveBankAccount(h.client, req.BankCode, req.AccountNumber)
 	if err != nil {
-		WriteJSONError(w, err, http.StatusInternalServerError)
+		WriteJSONError(w, err, accountResolutionErrorStatus(err))
 		return
 	}
 	WriteJSONSuccess(w, result)
 }
+
+// AccountResolution is the name a bank holds for an account number
+type AccountResolution struct {
+	AccountNumber string    `json:"account_number"`
+	AccountName   string    `json:"account_name"`
+	BankCode      string    `json:"bank_code"`
+	BankID        int       `json:"bank_id,omitempty"`
+	ResolvedAt    time.Time `json:"resolved_at"`
+	// Cached is true when the name came from an earlier lookup
+	Cached bool `json:"cached"`
+}
+
+// resolveBankAccount returns the name the bank holds for an account, from the cache
+// when it was looked up in the last 30 days and from Paystack otherwise
+func resolveBankAccount(client *paystack.Client, bankCode, accountNumber string) (AccountResolution, error) {
+	bankCode = strings.TrimSpace(bankCode)
+	accountNumber = strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, accountNumber)
+	if accountNumber == "" {
+		return AccountResolution{}, fmt.Errorf("%w: account number has no digits", paystack.ErrAccountNotResolved)
+	}
+
+	cached := AccountResolution{AccountNumber: accountNumber, BankCode: bankCode, Cached: true}
+	var bankID sql.NullInt64
+	err := database.DB.QueryRow(`
+		SELECT account_name, bank_id, resolved_at FROM account_resolutions
+		WHERE bank_code = ? AND account_number = ?
+	`, bankCode, accountNumber).Scan(&cached.AccountName, &bankID, &cached.ResolvedAt)
+	if err == nil && time.Since(cached.ResolvedAt) < accountResolutionTTL {
+		cached.BankID = int(bankID.Int64)
+		return cached, nil
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return AccountResolution{}, fmt.Errorf("failed to read account resolution: %w", err)
+	}
+
+	result, err := client.ResolveAccount(accountNumber, bankCode)
+	if err != nil {
+		return AccountResolution{}, fmt.Errorf("failed to resolve account: %w", err)
+	}
+
+	resolution := AccountResolution{AccountNumber: accountNumber, BankCode: bankCode, ResolvedAt: time.Now().UTC()}
+	resolution.AccountName, _ = result["account_name"].(string)
+	if id, ok := result["bank_id"].(float64); ok {
+		resolution.BankID = int(id)
+	}
+	if resolution.AccountName == "" {
+		return AccountResolution{}, fmt.Errorf("%w: bank returned no account name", paystack.ErrAccountNotResolved)
+	}
+
+	_, err = database.DB.Exec(`
+		INSERT INTO account_resolutions (bank_code, account_number, account_name, bank_id, resolved_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(bank_code, account_number) DO UPDATE SET
+			account_name = excluded.account_name,
+			bank_id = excluded.bank_id,
+			resolved_at = excluded.resolved_at
+	`, bankCode, accountNumber, resolution.AccountName, resolution.BankID, resolution.ResolvedAt)
+	if err != nil {
+		fmt.Printf("Warning: Failed to cache account resolution: %v\n", err)
+	}
+	return resolution, nil
+}
+
+// accountResolutionErrorStatus is the HTTP status for a failed account lookup: the
+// caller's mistake when the bank has no such account, Paystack's otherwise
+func accountResolutionErrorStatus(err error) int {
+	if errors.Is(err, paystack.ErrAccountNotResolved) {
+		return http.StatusBadRequest
+	}
+	return http.StatusBadGateway
+}
@@ -16,7 +16,7 @@
 // Check Alert Threshold → Notify if Exceeded
 //
 // DESIGN DECISIONS:
-// - Budgets track 'spent_amount' automatically when expenses are created
+// - Budgets track 'spent_amount' automatically; it mirrors the budget's ledger account
 // - Alert thresholds (e.g., 80%) warn users before they exceed limits
 // - Multiple budget types (category, general, default) allow flexible spending controls
 // - Default budgets are auto-created for users without explicit budgets
@@ -27,11 +27,14 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/fx"
+	"paystack.mpc.proxy/internal/money"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -47,22 +50,32 @@ type BudgetLimit struct {
 	ID           int       `json:"id"`
 	Name         string    `json:"name"`
 	LimitType    string    `json:"limit_type"`
-	Amount       int       `json:"amount"`
-	PeriodStart  time.Time `json:"period_start"`
-	PeriodEnd    time.Time `json:"period_end"`
-	SpentAmount  int       `json:"spent_amount"`
-	Remaining    int       `json:"remaining"`
+	money.Money
+	PeriodStart  time.Time    `json:"period_start"`
+	PeriodEnd    time.Time    `json:"period_end"`
+	SpentAmount  money.Amount `json:"spent_amount"`
+	Remaining    money.Amount `json:"remaining"`
 	Status       string    `json:"status"`
 	Notes        string    `json:"notes"`
 	UsagePercent float64   `json:"usage_percentage"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// ForeignSpending is spending in other currencies, as paid and as counted against the budget
+	ForeignSpending []BudgetForeignSpend `json:"foreign_spending,omitempty"`
+}
+
+// BudgetForeignSpend totals a budget's expenses in one foreign currency
+type BudgetForeignSpend struct {
+	Original  money.Money `json:"original"`
+	Converted money.Money `json:"converted"`
+	Expenses  int         `json:"expenses"`
 }
 
 type CreateBudgetLimitRequest struct {
 	Name          string `json:"name"`
 	LimitType     string `json:"limit_type"`
-	Amount        int    `json:"amount"`
+	money.Money
 	PeriodStart   string `json:"period_start"`
 	PeriodEnd     string `json:"period_end"`
 	AlertThreshold int   `json:"alert_threshold,omitempty"`
@@ -71,23 +84,27 @@ type CreateBudgetLimitRequest struct {
 
 type UpdateBudgetLimitRequest struct {
 	Name           string `json:"name,omitempty"`
-	Amount         int    `json:"amount,omitempty"`
-	AlertThreshold int    `json:"alert_threshold,omitempty"`
+	Amount         money.Amount `json:"amount,omitempty"`
+	AlertThreshold int          `json:"alert_threshold,omitempty"`
 	Status         string `json:"status,omitempty"`
 	Notes          string `json:"notes,omitempty"`
 }
 
 type CheckLimitResponse struct {
 	CanAfford      bool     `json:"can_afford"`
-	RequestedAmount int     `json:"requested_amount"`
-	BudgetLimit    int      `json:"budget_limit"`
-	SpentAmount    int      `json:"spent_amount"`
-	Remaining      int      `json:"remaining"`
+	RequestedAmount money.Amount `json:"requested_amount"`
+	Currency       money.Currency `json:"currency"`
+	BudgetLimit    money.Amount `json:"budget_limit"`
+	SpentAmount    money.Amount `json:"spent_amount"`
+	Remaining      money.Amount `json:"remaining"`
 	WouldExceed    bool     `json:"would_exceed"`
-	ExcessAmount   int      `json:"excess_amount,omitempty"`
+	ExcessAmount   money.Amount `json:"excess_amount,omitempty"`
 	UsageBefore    float64  `json:"usage_before"`
 	UsageAfter     float64  `json:"usage_after"`
 	Reason         string   `json:"reason"`
+
+	// Conversion is set when the amount was converted into the budget's currency
+	Conversion *FXConversion `json:"conversion,omitempty"`
 }
 
 type ListBudgetLimitsRequest struct {
@@ -107,7 +124,7 @@ func FindOrCreateDefaultBudget() (*BudgetLimit, error) {
 	endOfMonth := startOfMonth.AddDate(0, 1, -1)
 
 	query := `
-		SELECT id, name, limit_type, amount, period_start, period_end, spent_amount, status, notes, created_at, updated_at
+		SELECT id, name, limit_type, amount, currency, period_start, period_end, spent_amount, status, notes, created_at, updated_at
 		FROM budget_limits
 		WHERE limit_type = 'default'
 		AND period_start <= ?
@@ -124,6 +141,7 @@ func FindOrCreateDefaultBudget() (*BudgetLimit, error) {
 		&budget.Name,
 		&budget.LimitType,
 		&budget.Amount,
+		&budget.Currency,
 		&budget.PeriodStart,
 		&budget.PeriodEnd,
 		&budget.SpentAmount,
@@ -145,15 +163,15 @@ func FindOrCreateDefaultBudget() (*BudgetLimit, error) {
 	}
 
 	// No existing default budget - create one
-	defaultAmount := 5000000 // ₦50,000 default
+	defaultAmount := money.New(5000000, money.Default) // ₦50,000 default
 	budgetName := fmt.Sprintf("Default Budget - %s %d", now.Month().String(), now.Year())
 
 	insertQuery := `
-		INSERT INTO budget_limits (name, limit_type, amount, period_start, period_end, status, created_at, updated_at)
-		VALUES (?, 'default', ?, ?, ?, 'active', ?, ?)
+		INSERT INTO budget_limits (name, limit_type, amount, currency, period_start, period_end, status, created_at, updated_at)
+		VALUES (?, 'default', ?, ?, ?, ?, 'active', ?, ?)
 	`
 
-	result, err := database.DB.Exec(insertQuery, budgetName, defaultAmount, startOfMonth, endOfMonth, now, now)
+	result, err := database.DB.Exec(insertQuery, budgetName, defaultAmount.Amount, defaultAmount.Currency, startOfMonth, endOfMonth, now, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create default budget: %w", err)
 	}
@@ -164,11 +182,11 @@ func FindOrCreateDefaultBudget() (*BudgetLimit, error) {
 		ID:           int(id),
 		Name:         budgetName,
 		LimitType:    "default",
-		Amount:       defaultAmount,
+		Money:        defaultAmount,
 		PeriodStart:  startOfMonth,
 		PeriodEnd:    endOfMonth,
 		SpentAmount:  0,
-		Remaining:    defaultAmount,
+		Remaining:    defaultAmount.Amount,
 		Status:       "active",
 		UsagePercent: 0,
 		CreatedAt:    now,
@@ -176,9 +194,19 @@ func FindOrCreateDefaultBudget() (*BudgetLimit, error) {
 	}, nil
 }
 
-// Helper: CheckBudgetAffordability validates if budget can afford amount
-func CheckBudgetAffordability(budgetID int, amount int) (*CheckLimitResponse, error) {
-	query := `SELECT id, name, limit_type, amount, period_start, period_end, spent_amount, status FROM budget_limits WHERE id = ?`
+// Helper: BudgetCurrency returns the currency a budget is kept in
+func BudgetCurrency(budgetID int) (money.Currency, error) {
+	var currency money.Currency
+	if err := database.DB.QueryRow("SELECT currency FROM budget_limits WHERE id = ?", budgetID).Scan(&currency); err != nil {
+		return "", fmt.Errorf("budget not found: %d", budgetID)
+	}
+	return currency.OrDefault(), nil
+}
+
+// Helper: CheckBudgetAffordability validates if budget can afford amount.
+// The amount must be in the budget's currency.
+func CheckBudgetAffordability(budgetID int, amount money.Money) (*CheckLimitResponse, error) {
+	query := `SELECT id, name, limit_type, amount, currency, period_start, period_end, spent_amount, status FROM budget_limits WHERE id = ?`
 
 	var budget BudgetLimit
 	err := database.DB.QueryRow(query, budgetID).Scan(
@@ -186,6 +214,7 @@ func CheckBudgetAffordability(budgetID int, amount int) (*CheckLimitResponse, er
 		&budget.Name,
 		&budget.LimitType,
 		&budget.Amount,
+		&budget.Currency,
 		&budget.PeriodStart,
 		&budget.PeriodEnd,
 		&budget.SpentAmount,
@@ -196,15 +225,24 @@ func CheckBudgetAffordability(budgetID int, amount int) (*CheckLimitResponse, er
 		return nil, fmt.Errorf("budget not found: %d", budgetID)
 	}
 
+	// Never compare amounts across currencies
+	if !amount.SameCurrency(budget.Money) {
+		return nil, fmt.Errorf("budget %d is in %s but the amount is in %s: %w", budgetID, budget.Currency, amount.Currency.OrDefault(), money.ErrCurrencyMismatch)
+	}
+
+	spent := money.New(int64(budget.SpentAmount), budget.Currency)
+	remaining, _ := budget.Money.Sub(spent)
+
 	// Check if budget is active
 	now := time.Now()
 	if budget.Status != "active" {
 		return &CheckLimitResponse{
 			CanAfford:       false,
-			RequestedAmount: amount,
+			RequestedAmount: amount.Amount,
+			Currency:        budget.Currency,
 			BudgetLimit:     budget.Amount,
 			SpentAmount:     budget.SpentAmount,
-			Remaining:       budget.Amount - budget.SpentAmount,
+			Remaining:       remaining.Amount,
 			WouldExceed:     false,
 			Reason:          fmt.Sprintf("Budget is %s", budget.Status),
 		}, nil
@@ -214,59 +252,101 @@ func CheckBudgetAffordability(budgetID int, amount int) (*CheckLimitResponse, er
 	if now.Before(budget.PeriodStart) || now.After(budget.PeriodEnd) {
 		return &CheckLimitResponse{
 			CanAfford:       false,
-			RequestedAmount: amount,
+			RequestedAmount: amount.Amount,
+			Currency:        budget.Currency,
 			BudgetLimit:     budget.Amount,
 			SpentAmount:     budget.SpentAmount,
-			Remaining:       budget.Amount - budget.SpentAmount,
+			Remaining:       remaining.Amount,
 			WouldExceed:     false,
 			Reason:          "Budget period is not active",
 		}, nil
 	}
 
 	// Calculate affordability
-	remaining := budget.Amount - budget.SpentAmount
-	newSpentAmount := budget.SpentAmount + amount
-	wouldExceed := newSpentAmount > budget.Amount
+	newSpent, _ := spent.Add(amount)
+	wouldExceed := newSpent.Amount > budget.Amount
 	canAfford := !wouldExceed
 
 	usageBefore := float64(0)
 	usageAfter := float64(0)
 	if budget.Amount > 0 {
 		usageBefore = (float64(budget.SpentAmount) / float64(budget.Amount)) * 100
-		usageAfter = (float64(newSpentAmount) / float64(budget.Amount)) * 100
+		usageAfter = (float64(newSpent.Amount) / float64(budget.Amount)) * 100
 	}
 
 	response := &CheckLimitResponse{
 		CanAfford:       canAfford,
-		RequestedAmount: amount,
+		RequestedAmount: amount.Amount,
+		Currency:        budget.Currency,
 		BudgetLimit:     budget.Amount,
 		SpentAmount:     budget.SpentAmount,
-		Remaining:       remaining,
+		Remaining:       remaining.Amount,
 		WouldExceed:     wouldExceed,
 		UsageBefore:     usageBefore,
 		UsageAfter:      usageAfter,
 	}
 
 	if wouldExceed {
-		response.ExcessAmount = newSpentAmount - budget.Amount
-		response.Reason = fmt.Sprintf("Spending ₦%d would exceed budget limit by ₦%d (remaining: ₦%d)",
-			amount/100, response.ExcessAmount/100, remaining/100)
+		excess, _ := newSpent.Sub(budget.Money)
+		response.ExcessAmount = excess.Amount
+		response.Reason = fmt.Sprintf("Spending %s would exceed budget limit by %s (remaining: %s)",
+			amount.Format(), excess.Format(), remaining.Format())
 	} else {
-		response.Reason = fmt.Sprintf("Spending ₦%d is within budget (remaining: ₦%d after transaction)",
-			amount/100, (remaining-amount)/100)
+		after, _ := remaining.Sub(amount)
+		response.Reason = fmt.Sprintf("Spending %s is within budget (remaining: %s after transaction)",
+			amount.Format(), after.Format())
 	}
 
 	return response, nil
 }
 
-// Helper: UpdateBudgetSpending increments spent_amount
-func UpdateBudgetSpending(budgetID int, amount int) error {
-	query := `UPDATE budget_limits SET spent_amount = spent_amount + ?, updated_at = ? WHERE id = ?`
-	_, err := database.DB.Exec(query, amount, time.Now(), budgetID)
+// Helper: CheckBudgetAffordabilityOn checks an amount in any currency, converting it
+// into the budget's currency at the rate in effect on the given date
+func CheckBudgetAffordabilityOn(budgetID int, amount money.Money, on time.Time) (*CheckLimitResponse, error) {
+	currency, err := BudgetCurrency(budgetID)
+	if err != nil {
+		return nil, err
+	}
+	converted, conversion, err := ConvertMoney(amount, currency, on)
 	if err != nil {
-		return fmt.Errorf("failed to update budget spending: %w", err)
+		return nil, fmt.Errorf("can't convert %s into budget %d's %s: %w", amount.Format(), budgetID, currency, err)
 	}
-	return nil
+
+	response, err := CheckBudgetAffordability(budgetID, converted)
+	if err != nil {
+		return nil, err
+	}
+	if conversion != nil {
+		response.Conversion = conversion
+		response.Reason += fmt.Sprintf(" (%s)", conversion.Describe())
+	}
+	return response, nil
+}
+
+// budgetForeignSpending totals a budget's live expenses in currencies other than its own
+func budgetForeignSpending(budgetID int) ([]BudgetForeignSpend, error) {
+	rows, err := database.DB.Query(`
+		SELECT currency, budget_currency, COUNT(*), SUM(amount), SUM(budget_amount)
+		FROM expenses
+		WHERE budget_limit_id = ? AND budget_amount IS NOT NULL AND budget_currency != currency
+		  AND status NOT IN ('cancelled', 'refunded', 'failed')
+		GROUP BY currency, budget_currency
+		ORDER BY currency
+	`, budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total foreign spending: %w", err)
+	}
+	defer rows.Close()
+
+	var spending []BudgetForeignSpend
+	for rows.Next() {
+		var spend BudgetForeignSpend
+		if err := rows.Scan(&spend.Original.Currency, &spend.Converted.Currency, &spend.Expenses, &spend.Original.Amount, &spend.Converted.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign spending: %w", err)
+		}
+		spending = append(spending, spend)
+	}
+	return spending, rows.Err()
 }
 
 // Create creates a new budget limit
@@ -292,6 +372,7 @@ func (h *BudgetHandler) Create(w http.ResponseWriter, r *http.Request) {
 		WriteJSONBadRequest(w, "amount must be greater than 0")
 		return
 	}
+	req.Currency = req.Currency.OrDefault()
 
 	if req.PeriodStart == "" {
 		WriteJSONBadRequest(w, "period_start is required")
@@ -329,8 +410,8 @@ func (h *BudgetHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	// Insert budget limit
 	query := `
-		INSERT INTO budget_limits (name, limit_type, amount, period_start, period_end, alert_threshold, notes, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO budget_limits (name, limit_type, amount, currency, period_start, period_end, alert_threshold, notes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	now := time.Now()
@@ -339,6 +420,7 @@ func (h *BudgetHandler) Create(w http.ResponseWriter, r *http.Request) {
 		req.Name,
 		req.LimitType,
 		req.Amount,
+		req.Currency,
 		periodStart,
 		periodEnd,
 		alertThreshold,
@@ -359,7 +441,7 @@ func (h *BudgetHandler) Create(w http.ResponseWriter, r *http.Request) {
 		ID:           int(id),
 		Name:         req.Name,
 		LimitType:    req.LimitType,
-		Amount:       req.Amount,
+		Money:        req.Money,
 		PeriodStart:  periodStart,
 		PeriodEnd:    periodEnd,
 		SpentAmount:  0,
@@ -382,26 +464,8 @@ func (h *BudgetHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Build query with filters
-	query := `SELECT id, name, limit_type, amount, period_start, period_end, spent_amount, status, notes, created_at, updated_at FROM budget_limits WHERE 1=1`
-	args := []interface{}{}
-
-	// Add filters
-	if req.LimitType != "" {
-		query += " AND limit_type = ?"
-		args = append(args, req.LimitType)
-	}
-
-	if req.Status != "" {
-		query += " AND status = ?"
-		args = append(args, req.Status)
-	}
-
-	// Filter for active budgets (within current period)
-	if req.Active {
-		now := time.Now()
-		query += " AND period_start <= ? AND period_end >= ? AND status = 'active'"
-		args = append(args, now, now)
-	}
+	filters, args := budgetFilters(req)
+	query := `SELECT id, name, limit_type, amount, currency, period_start, period_end, spent_amount, status, notes, created_at, updated_at FROM budget_limits WHERE 1=1` + filters
 
 	// Add ordering
 	query += " ORDER BY period_start DESC"
@@ -429,6 +493,7 @@ func (h *BudgetHandler) List(w http.ResponseWriter, r *http.Request) {
 			&budget.Name,
 			&budget.LimitType,
 			&budget.Amount,
+			&budget.Currency,
 			&budget.PeriodStart,
 			&budget.PeriodEnd,
 			&budget.SpentAmount,
@@ -463,6 +528,32 @@ func (h *BudgetHandler) List(w http.ResponseWriter, r *http.Request) {
 	WriteJSONSuccess(w, budgets)
 }
 
+// budgetFilters turns list filters into SQL conditions on the budget_limits table.
+// List and the exports share it so both select the same budgets.
+func budgetFilters(req ListBudgetLimitsRequest) (string, []interface{}) {
+	query := ""
+	args := []interface{}{}
+
+	if req.LimitType != "" {
+		query += " AND limit_type = ?"
+		args = append(args, req.LimitType)
+	}
+
+	if req.Status != "" {
+		query += " AND status = ?"
+		args = append(args, req.Status)
+	}
+
+	// Filter for active budgets (within current period)
+	if req.Active {
+		now := time.Now()
+		query += " AND period_start <= ? AND period_end >= ? AND status = 'active'"
+		args = append(args, now, now)
+	}
+
+	return query, args
+}
+
 // Get retrieves a specific budget limit by ID
 func (h *BudgetHandler) Get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -472,7 +563,7 @@ func (h *BudgetHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	query := `
-		SELECT id, name, limit_type, amount, period_start, period_end, spent_amount, status, notes, created_at, updated_at
+		SELECT id, name, limit_type, amount, currency, period_start, period_end, spent_amount, status, notes, created_at, updated_at
 		FROM budget_limits
 		WHERE id = ?
 	`
@@ -485,6 +576,7 @@ func (h *BudgetHandler) Get(w http.ResponseWriter, r *http.Request) {
 		&budget.Name,
 		&budget.LimitType,
 		&budget.Amount,
+		&budget.Currency,
 		&budget.PeriodStart,
 		&budget.PeriodEnd,
 		&budget.SpentAmount,
@@ -509,6 +601,10 @@ func (h *BudgetHandler) Get(w http.ResponseWriter, r *http.Request) {
 		budget.UsagePercent = (float64(budget.SpentAmount) / float64(budget.Amount)) * 100
 	}
 
+	if budget.ForeignSpending, err = budgetForeignSpending(budget.ID); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	WriteJSONSuccess(w, budget)
 }
 
@@ -610,7 +706,32 @@ func (h *BudgetHandler) CheckLimit(w http.ResponseWriter, r *http.Request) {
 	var budgetID int
 	fmt.Sscanf(id, "%d", &budgetID)
 
-	response, err := CheckBudgetAffordability(budgetID, amountInt)
+	// Amounts are in the budget's currency unless ?currency= says otherwise,
+	// in which case they're converted at the rate on ?date= (default today)
+	currency, err := money.ParseCurrency(r.URL.Query().Get("currency"))
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	if r.URL.Query().Get("currency") == "" {
+		if currency, err = BudgetCurrency(budgetID); err != nil {
+			WriteJSONError(w, err, http.StatusNotFound)
+			return
+		}
+	}
+	on := time.Now()
+	if date := r.URL.Query().Get("date"); date != "" {
+		if on, err = time.Parse(fx.DateLayout, date); err != nil {
+			WriteJSONBadRequest(w, "date must be YYYY-MM-DD")
+			return
+		}
+	}
+
+	response, err := CheckBudgetAffordabilityOn(budgetID, money.New(int64(amountInt), currency), on)
+	if errors.Is(err, fx.ErrNoRate) {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
 	if err != nil {
 		WriteJSONError(w, err, http.StatusNotFound)
 		return
@@ -621,9 +742,20 @@ func (h *BudgetHandler) CheckLimit(w http.ResponseWriter, r *http.Request) {
 
 // GetActiveBudgets returns all currently active budget limits
 func (h *BudgetHandler) GetActiveBudgets(w http.ResponseWriter, r *http.Request) {
+	budgets, err := ListActiveBudgets()
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	WriteJSONSuccess(w, budgets)
+}
+
+// ListActiveBudgets returns budgets whose period covers now, with usage computed
+func ListActiveBudgets() ([]BudgetLimit, error) {
 	now := time.Now()
 	query := `
-		SELECT id, name, limit_type, amount, period_start, period_end, spent_amount, status, notes, created_at, updated_at
+		SELECT id, name, limit_type, amount, currency, period_start, period_end, spent_amount, status, notes, created_at, updated_at
 		FROM budget_limits
 		WHERE status = 'active' AND period_start <= ? AND period_end >= ?
 		ORDER BY period_start DESC
@@ -631,8 +763,7 @@ func (h *BudgetHandler) GetActiveBudgets(w http.ResponseWriter, r *http.Request)
 
 	rows, err := database.DB.Query(query, now, now)
 	if err != nil {
-		WriteJSONError(w, fmt.Errorf("failed to query active budgets: %w", err), http.StatusInternalServerError)
-		return
+		return nil, fmt.Errorf("failed to query active budgets: %w", err)
 	}
 	defer rows.Close()
 
@@ -646,6 +777,7 @@ func (h *BudgetHandler) GetActiveBudgets(w http.ResponseWriter, r *http.Request)
 			&budget.Name,
 			&budget.LimitType,
 			&budget.Amount,
+			&budget.Currency,
 			&budget.PeriodStart,
 			&budget.PeriodEnd,
 			&budget.SpentAmount,
@@ -655,8 +787,7 @@ func (h *BudgetHandler) GetActiveBudgets(w http.ResponseWriter, r *http.Request)
 			&budget.UpdatedAt,
 		)
 		if err != nil {
-			WriteJSONError(w, fmt.Errorf("failed to scan budget: %w", err), http.StatusInternalServerError)
-			return
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
 		}
 
 		if notes.Valid {
@@ -673,9 +804,8 @@ func (h *BudgetHandler) GetActiveBudgets(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err = rows.Err(); err != nil {
-		WriteJSONError(w, fmt.Errorf("error iterating budgets: %w", err), http.StatusInternalServerError)
-		return
+		return nil, fmt.Errorf("error iterating budgets: %w", err)
 	}
 
-	WriteJSONSuccess(w, budgets)
+	return budgets, nil
 }
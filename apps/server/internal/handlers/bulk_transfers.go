@@ -0,0 +1,354 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Bulk Transfers Handler - Paying Many Recipients at Once
+//
+// OBJECTIVES:
+// "Pay the cleaning team their weekly rate" meant one transfer per cleaner, each
+// needing its own recipient code, amount and confirmation.
+//
+// PURPOSE:
+// - Pay a group, or a list of recipients named by code, name or alias, in one request
+// - Expand a group into one payment per member at their rate (see recipient_groups.go)
+// - Preview the whole batch and confirm it with a single token
+//
+// KEY WORKFLOW:
+// Resolve Group and Names → Expand to Payments → Check Limits and Anomalies →
+// Preview + Token → Confirm → Reserve and Transfer Each → Report Each Outcome
+//
+// DESIGN DECISIONS:
+// - Each payment is an ordinary Paystack transfer, so limits, anomaly checks and
+//   the ledger treat it exactly like a single transfer
+// - Names are resolved before the preview, and the token carries recipient codes,
+//   so what is confirmed is exactly what was previewed
+// - A name that doesn't resolve to one recipient refuses the whole batch with the
+//   question to ask; nothing is paid on a guess
+// - Limits are checked at preview with each payment counting the ones before it,
+//   so the batch's total is held to every window and beneficiary cap; at execution
+//   each payment reserves in turn, so one that would cross a limit fails alone
+// - Each payment's reference comes from the batch's confirmation token and its place
+//   in the batch. A payment whose outcome Paystack didn't give is looked up by it; if
+//   that fails too it keeps its reservation and is needs_review, not failed, so it
+//   isn't simply paid again
+// - A batch is in one currency, so its total means something
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/anomaly"
+	"paystack.mpc.proxy/internal/confirm"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+	"paystack.mpc.proxy/internal/payout"
+
+	paystackSDK "github.com/borderlesshq/paystack-go"
+)
+
+// maxBulkTransfers caps the payments in one batch
+const maxBulkTransfers = 100
+
+// BulkTransferRequest pays a group, a list of recipients, or both
+type BulkTransferRequest struct {
+	Source string `json:"source"`
+	// Group is a group's id or name; every member is paid
+	Group string `json:"group,omitempty"`
+	// Amount pays each group member this instead of their usual rate
+	Amount money.Amount `json:"amount,omitempty"`
+	// Transfers are payments to individual recipients
+	Transfers []BulkTransferItem `json:"transfers,omitempty"`
+	// Reason is used for payments without their own
+	Reason string `json:"reason,omitempty"`
+
+	// ConfirmationToken executes a previously previewed batch
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// BulkTransferItem is one payment in a batch
+type BulkTransferItem struct {
+	// Recipient is a recipient code, or a name or alias as the user said it
+	Recipient string `json:"recipient"`
+	// RecipientName is filled in once the recipient is resolved
+	RecipientName string `json:"recipient_name,omitempty"`
+	money.Money
+	Reason string `json:"reason,omitempty"`
+}
+
+// BulkTransferResult is what happened to one payment
+type BulkTransferResult struct {
+	BulkTransferItem
+	// Status is sent, failed or needs_review
+	Status       string `json:"status"`
+	Reference    string `json:"reference,omitempty"`
+	TransferCode string `json:"transfer_code,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// BulkTransferResponse reports every payment in an executed batch
+type BulkTransferResponse struct {
+	Group  string      `json:"group,omitempty"`
+	Total  money.Money `json:"total"`
+	Sent   int         `json:"sent"`
+	Failed int         `json:"failed"`
+	// NeedsReview counts payments Paystack may or may not have made
+	NeedsReview int                  `json:"needs_review,omitempty"`
+	Results     []BulkTransferResult `json:"results"`
+}
+
+// Bulk pays several recipients, previewing the batch until it is confirmed
+func (h *TransferHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	var req BulkTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	// A confirmation token replaces the body with the previewed, resolved batch;
+	// nothing else in the body is kept, so a field can't be added after the preview
+	confirmed := false
+	token := req.ConfirmationToken
+	if token != "" {
+		var previewed BulkTransferRequest
+		if !redeemConfirmation(w, token, confirm.ActionBulkTransfer, &previewed) {
+			return
+		}
+		req = previewed
+		confirmed = true
+	}
+
+	if req.Source == "" {
+		req.Source = "balance"
+	}
+	if req.Group == "" && len(req.Transfers) == 0 {
+		WriteJSONBadRequest(w, "group or transfers is required")
+		return
+	}
+
+	if !confirmed {
+		if !expandBulkTransfer(w, &req) {
+			return
+		}
+	}
+	if len(req.Transfers) > maxBulkTransfers {
+		WriteJSONBadRequest(w, fmt.Sprintf("a batch can have at most %d payments", maxBulkTransfers))
+		return
+	}
+
+	items := make([]payout.Item, len(req.Transfers))
+	for i, t := range req.Transfers {
+		items[i] = payout.Item{RecipientCode: t.Recipient, Name: t.RecipientName, Money: t.Money}
+	}
+	total, err := payout.Total(items)
+	if err != nil {
+		WriteJSONBadRequest(w, "every payment in a batch must be in the same currency")
+		return
+	}
+
+	if !confirmed {
+		preview := ConfirmationPreview{
+			Money:         total,
+			RecipientName: bulkRecipientLabel(req),
+			Payments:      req.Transfers,
+			Summary:       fmt.Sprintf("Send %s to %s", total.Format(), bulkRecipientLabel(req)),
+		}
+		pending := &pendingPayments{}
+		for _, t := range req.Transfers {
+			check, err := checkTransferLimits(t.Recipient, t.Money, pending)
+			if err != nil {
+				WriteJSONError(w, fmt.Errorf("error checking transfer limits: %w", err), http.StatusInternalServerError)
+				return
+			}
+			if !check.Allowed {
+				writeLimitRejection(w, check)
+				return
+			}
+			pending.add(t.Recipient, t.Amount)
+			preview.LimitAlerts = append(preview.LimitAlerts, check.Alerts...)
+			preview.Warnings = append(preview.Warnings, checkPayment(bulkPayment(t))...)
+		}
+		if budget, err := FindOrCreateDefaultBudget(); err == nil {
+			if impact, err := CheckBudgetAffordabilityOn(budget.ID, total, time.Now()); err == nil {
+				preview.BudgetImpact = impact
+			}
+		}
+
+		writeConfirmationPreview(w, confirm.ActionBulkTransfer, req, preview)
+		return
+	}
+
+	response := BulkTransferResponse{Group: req.Group, Total: total, Results: []BulkTransferResult{}}
+	var warnings []anomaly.Warning
+	for i, t := range req.Transfers {
+		reference := transferReference("blk_", fmt.Sprintf("%s/%d", token, i))
+		result, paymentWarnings := h.sendBulkItem(req.Source, t, reference)
+		warnings = append(warnings, paymentWarnings...)
+		switch result.Status {
+		case "sent":
+			response.Sent++
+		case transferNeedsReview:
+			response.NeedsReview++
+		default:
+			response.Failed++
+		}
+		response.Results = append(response.Results, result)
+	}
+	WriteJSONSuccessWithWarnings(w, response, warnings)
+}
+
+// sendBulkItem reserves against limits and makes one transfer under reference
+func (h *TransferHandler) sendBulkItem(source string, t BulkTransferItem, reference string) (BulkTransferResult, []anomaly.Warning) {
+	result := BulkTransferResult{BulkTransferItem: t, Status: "failed", Reference: reference}
+
+	// The recipient may have been deleted or merged into another since the preview
+	recipientCode, err := transferRecipientCode(t.Recipient)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	t.Recipient = recipientCode
+
+	payment := bulkPayment(t)
+	warnings := checkPayment(payment)
+
+	reservationID, check, err := ReserveOutgoingPayment(t.Recipient, t.Money, "transfer", t.Reason)
+	if err != nil {
+		result.Error = fmt.Sprintf("error checking transfer limits: %v", err)
+		return result, nil
+	}
+	if !check.Allowed {
+		result.Error = check.Reason
+		return result, nil
+	}
+
+	// Tagged before the call so the reservation can be matched to the transfer if the answer is lost
+	SetOutgoingPaymentReference(reservationID, reference)
+	transfer, err := initiateTransfer(h.client, &paystackSDK.TransferRequest{
+		Source:    source,
+		Amount:    float32(t.Amount),
+		Currency:  string(t.Currency),
+		Recipient: t.Recipient,
+		Reason:    t.Reason,
+		Reference: reference,
+	})
+	if errors.Is(err, errTransferUnconfirmed) {
+		// The money may have gone, so it stays counted against the limits
+		result.Status = transferNeedsReview
+		result.Error = err.Error()
+		return result, nil
+	}
+	if err != nil {
+		ReleaseOutgoingPayment(reservationID)
+		result.Error = err.Error()
+		return result, nil
+	}
+	SetOutgoingPaymentReference(reservationID, transfer.TransferCode)
+	postLedger("transfer "+transfer.TransferCode, PostTransferJournal(reservationID))
+
+	payment.Reference = transfer.TransferCode
+	recordAnomalies("transfer", payment, warnings)
+
+	result.Status = "sent"
+	result.TransferCode = transfer.TransferCode
+	return result, warnings
+}
+
+// expandBulkTransfer resolves the group and every recipient name into recipient
+// codes, so the batch can be previewed and confirmed as it stands.
+// It writes the error response and returns false when something doesn't resolve.
+func expandBulkTransfer(w http.ResponseWriter, req *BulkTransferRequest) bool {
+	var transfers []BulkTransferItem
+
+	if req.Group != "" {
+		group, question, err := resolveRecipientGroup(req.Group)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			WriteJSONError(w, fmt.Errorf("group not found: %s", req.Group), http.StatusNotFound)
+			return false
+		case err != nil:
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return false
+		case group == nil:
+			respondWithJSON(w, http.StatusConflict, map[string]interface{}{
+				"status":  false,
+				"message": question,
+				"error":   fmt.Sprintf("group %q doesn't match one group", req.Group),
+			})
+			return false
+		}
+
+		items, err := payout.Expand(payoutGroup(group), req.Amount)
+		if err != nil {
+			WriteJSONBadRequest(w, err.Error())
+			return false
+		}
+		req.Group = group.Name
+		for _, item := range items {
+			transfers = append(transfers, BulkTransferItem{
+				Recipient:     item.RecipientCode,
+				RecipientName: item.Name,
+				Money:         item.Money,
+				Reason:        req.Reason,
+			})
+		}
+	}
+
+	for _, t := range req.Transfers {
+		if strings.TrimSpace(t.Recipient) == "" {
+			WriteJSONBadRequest(w, "recipient is required for each transfer")
+			return false
+		}
+		t.Currency = t.Currency.OrDefault()
+		if err := t.Validate(); err != nil {
+			WriteJSONBadRequest(w, fmt.Sprintf("%s: %v", t.Recipient, err))
+			return false
+		}
+		code, resolution, err := resolveRecipientReference(t.Recipient)
+		if err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return false
+		}
+		if code == "" {
+			writeUnresolvedRecipient(w, resolution)
+			return false
+		}
+		t.Recipient = code
+		database.DB.QueryRow("SELECT name FROM recipients WHERE recipient_code = ?", code).Scan(&t.RecipientName)
+		if t.Reason == "" {
+			t.Reason = req.Reason
+		}
+		transfers = append(transfers, t)
+	}
+
+	req.Transfers = transfers
+	req.Amount = 0
+	return true
+}
+
+// bulkPayment is a batch payment as anomaly detection sees it
+func bulkPayment(t BulkTransferItem) anomaly.Payment {
+	return anomaly.Payment{
+		RecipientCode: t.Recipient,
+		RecipientName: t.RecipientName,
+		Money:         t.Money,
+		Narration:     t.Reason,
+	}
+}
+
+// bulkRecipientLabel describes who a batch pays, e.g. "cleaning team (3 payments)"
+func bulkRecipientLabel(req BulkTransferRequest) string {
+	if req.Group != "" {
+		if len(req.Transfers) == 1 {
+			return req.Group + " (1 payment)"
+		}
+		return fmt.Sprintf("%s (%d payments)", req.Group, len(req.Transfers))
+	}
+	if len(req.Transfers) == 1 {
+		return req.Transfers[0].RecipientName
+	}
+	return fmt.Sprintf("%d recipients", len(req.Transfers))
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"paystack.mpc.proxy/internal/database"
+)
+
+func TestBulkKeepsReservationWhenPaymentMayHaveGone(t *testing.T) {
+	tests := []struct {
+		name             string
+		initiate         fakeResponse
+		verify           fakeResponse
+		wantStatus       string
+		wantReservations int
+	}{
+		{"sent", transferSentResponse, transferUnknownResponse, "sent", 2},
+		{"refused", transferRefusedResponse, transferUnknownResponse, "failed", 0},
+		{"answer lost, Paystack has it", paystackDownResponse, transferFoundResponse, "sent", 2},
+		{"answer lost, Paystack never got it", paystackDownResponse, transferUnknownResponse, "failed", 0},
+		{"answer lost, can't tell", paystackDownResponse, paystackDownResponse, transferNeedsReview, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandlerDB(t)
+			fake := newFakePaystack(t)
+			fake.respond("POST /transfer", tt.initiate)
+			fake.respond("GET /transfer/verify/", tt.verify)
+			h := NewTransferHandler(fake.client)
+
+			code, preview := post(t, h.Bulk, map[string]interface{}{
+				"transfers": []map[string]interface{}{
+					{"recipient": defaultRecipientCode, "amount": 500000},
+					{"recipient": defaultRecipientCode, "amount": 500000},
+				},
+			})
+			if code != http.StatusOK {
+				t.Fatalf("Expected a preview, got %d: %v", code, preview)
+			}
+			token, _ := preview["data"].(map[string]interface{})["confirmation_token"].(string)
+
+			code, response := post(t, h.Bulk, map[string]interface{}{"confirmation_token": token})
+			if code != http.StatusOK {
+				t.Fatalf("Expected the batch to report each payment, got %d: %v", code, response)
+			}
+			results, _ := response["data"].(map[string]interface{})["results"].([]interface{})
+			references := map[string]bool{}
+			for _, item := range results {
+				result, _ := item.(map[string]interface{})
+				if result["status"] != tt.wantStatus {
+					t.Errorf("Expected status %s, got %v", tt.wantStatus, result)
+				}
+				reference, _ := result["reference"].(string)
+				references[reference] = true
+			}
+			if len(references) != 2 || references[""] {
+				t.Errorf("Expected each payment to have its own reference, got %v", references)
+			}
+			if got := reservations(t); got != tt.wantReservations {
+				t.Errorf("Expected %d reservations, got %d", tt.wantReservations, got)
+			}
+			if got := fake.count("POST /transfer"); got != 2 {
+				t.Errorf("Expected one transfer request per payment, got %d", got)
+			}
+
+			// A kept reservation can be matched to its payment by reference
+			if tt.wantStatus == transferNeedsReview {
+				for reference := range references {
+					if id, err := reservationByReference(reference); err != nil || id == 0 {
+						t.Errorf("Expected a reservation under %s, got %d, %v", reference, id, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBulkPreviewChecksLimitsOnTheBatchTotal(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup string
+	}{
+		{"account window", "INSERT INTO account_limits (id, daily_transfer_limit, currency, created_at, updated_at) VALUES (1, 1000000, 'NGN', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)"},
+		{"beneficiary", "INSERT INTO beneficiary_limits (recipient_code, period, amount_limit, currency, alerts_at_percent, created_at, updated_at) VALUES ('" + defaultRecipientCode + "', 'daily', 1000000, 'NGN', 80, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandlerDB(t)
+			if _, err := database.DB.Exec(tt.setup); err != nil {
+				t.Fatalf("Failed to set limit: %v", err)
+			}
+
+			// Each payment is under the cap on its own; together they cross it
+			code, response := post(t, NewTransferHandler(nil).Bulk, map[string]interface{}{
+				"transfers": []map[string]interface{}{
+					{"recipient": defaultRecipientCode, "amount": 600000},
+					{"recipient": defaultRecipientCode, "amount": 600000},
+				},
+			})
+			if code != http.StatusBadRequest {
+				t.Fatalf("Expected the batch to be rejected, got %d: %v", code, response)
+			}
+			if reason, _ := response["error"].(string); !strings.Contains(reason, "earlier in this batch") {
+				t.Errorf("Expected the reason to count the earlier payment, got %q", reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,675 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Cards Handler - Spending Controls
+//
+// OBJECTIVES:
+// Users need scoped cards for online spending that can't outrun a budget.
+//
+// PURPOSE:
+// - Issue virtual cards through a card issuer, each linked to a budget
+// - Attach spend controls (per-transaction, period limit, merchant categories, expiry)
+// - Freeze and unfreeze cards instantly
+// - Authorize card payments and record approved ones as expenses
+//
+// KEY WORKFLOW:
+// Create Card → Issuer Issues Card → Set Controls → Card Payment →
+// Check Card Controls → Check Budget → Check Transfer Limits → Record Expense
+//
+// DESIGN DECISIONS:
+// - Issuing sits behind cards.Issuer; the simulated issuer is used until a card programme is connected
+// - Only the masked PAN and last four digits are stored
+// - Every authorization attempt is logged in card_transactions, declined ones with the reason
+// - Approved payments become 'paid' expenses against the card's budget, so budgets stay the source of truth
+// - Card spend counts towards account and beneficiary transfer limits like any other payment
+// - Cards are NGN-only for now because budgets are kept in kobo
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"paystack.mpc.proxy/internal/cards"
+	"paystack.mpc.proxy/internal/categorize"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// cardAuthMu serialises authorizations so period limits can't be raced
+var cardAuthMu sync.Mutex
+
+type CardHandler struct {
+	issuer cards.Issuer
+}
+
+func NewCardHandler(issuer cards.Issuer) *CardHandler {
+	return &CardHandler{issuer: issuer}
+}
+
+// VirtualCard is an issued card with its controls
+type VirtualCard struct {
+	ID            int            `json:"id"`
+	Issuer        string         `json:"issuer"`
+	ExternalID    string         `json:"external_id"`
+	Label         string         `json:"label"`
+	Currency      money.Currency `json:"currency"`
+	Brand         string         `json:"brand"`
+	Last4         string         `json:"last4"`
+	MaskedPAN     string         `json:"masked_pan"`
+	ExpMonth      int            `json:"exp_month"`
+	ExpYear       int            `json:"exp_year"`
+	BudgetLimitID int            `json:"budget_limit_id"`
+	Controls      cards.Controls `json:"controls"`
+	Status        string         `json:"status"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// CardTransaction is one authorization attempt on a card
+type CardTransaction struct {
+	ID               int       `json:"id"`
+	CardID           int       `json:"card_id"`
+	money.Money
+	MerchantName     string    `json:"merchant_name"`
+	MerchantCategory string    `json:"merchant_category"`
+	Status           string    `json:"status"`
+	DeclineReason    string    `json:"decline_reason,omitempty"`
+	ExpenseID        *int      `json:"expense_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type CreateCardRequest struct {
+	Label               string         `json:"label"`
+	Currency            money.Currency `json:"currency"`
+	BudgetLimitID       *int           `json:"budget_limit_id,omitempty"`
+	PerTransactionLimit money.Amount   `json:"per_transaction_limit,omitempty"`
+	SpendLimit          money.Amount   `json:"spend_limit,omitempty"`
+	SpendPeriod         string         `json:"spend_period,omitempty"`
+	AllowedCategories   []string       `json:"allowed_categories,omitempty"`
+	BlockedCategories   []string       `json:"blocked_categories,omitempty"`
+	ExpiresAt           string         `json:"expires_at,omitempty"`
+}
+
+type UpdateCardControlsRequest struct {
+	PerTransactionLimit *money.Amount `json:"per_transaction_limit,omitempty"`
+	SpendLimit          *money.Amount `json:"spend_limit,omitempty"`
+	SpendPeriod         *string       `json:"spend_period,omitempty"`
+	AllowedCategories   *[]string     `json:"allowed_categories,omitempty"`
+	BlockedCategories   *[]string     `json:"blocked_categories,omitempty"`
+}
+
+type AuthorizeCardRequest struct {
+	money.Money
+	MerchantName     string `json:"merchant_name"`
+	MerchantCategory string `json:"merchant_category,omitempty"`
+}
+
+const cardColumns = `
+	id, issuer, external_id, label, currency, COALESCE(brand, ''), last4, masked_pan,
+	exp_month, exp_year, budget_limit_id, per_transaction_limit, spend_limit, spend_period,
+	allowed_categories, blocked_categories, status, expires_at, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCard(row rowScanner) (*VirtualCard, error) {
+	var card VirtualCard
+	var allowed, blocked string
+	err := row.Scan(
+		&card.ID,
+		&card.Issuer,
+		&card.ExternalID,
+		&card.Label,
+		&card.Currency,
+		&card.Brand,
+		&card.Last4,
+		&card.MaskedPAN,
+		&card.ExpMonth,
+		&card.ExpYear,
+		&card.BudgetLimitID,
+		&card.Controls.PerTransactionLimit,
+		&card.Controls.SpendLimit,
+		&card.Controls.SpendPeriod,
+		&allowed,
+		&blocked,
+		&card.Status,
+		&card.ExpiresAt,
+		&card.CreatedAt,
+		&card.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal([]byte(allowed), &card.Controls.AllowedCategories)
+	json.Unmarshal([]byte(blocked), &card.Controls.BlockedCategories)
+	if card.Controls.AllowedCategories == nil {
+		card.Controls.AllowedCategories = []string{}
+	}
+	if card.Controls.BlockedCategories == nil {
+		card.Controls.BlockedCategories = []string{}
+	}
+	return &card, nil
+}
+
+// loadCard fetches a card by the {id} URL parameter, writing the error response on failure
+func loadCard(w http.ResponseWriter, r *http.Request) (*VirtualCard, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteJSONBadRequest(w, "Invalid card ID")
+		return nil, false
+	}
+
+	card, err := scanCard(database.DB.QueryRow("SELECT "+cardColumns+" FROM virtual_cards WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		WriteJSONError(w, fmt.Errorf("card not found: %d", id), http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to fetch card: %w", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	return card, true
+}
+
+func encodeCategories(categories []string) string {
+	if categories == nil {
+		categories = []string{}
+	}
+	encoded, _ := json.Marshal(categories)
+	return string(encoded)
+}
+
+// Create issues a new virtual card linked to a budget
+func (h *CardHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req CreateCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.Label == "" {
+		WriteJSONBadRequest(w, "label is required")
+		return
+	}
+
+	req.Currency = req.Currency.OrDefault()
+	if req.Currency != money.NGN {
+		WriteJSONBadRequest(w, "only NGN cards can be linked to a budget")
+		return
+	}
+
+	if req.SpendPeriod == "" {
+		req.SpendPeriod = cards.PeriodLifetime
+	}
+	controls := cards.Controls{
+		PerTransactionLimit: int(req.PerTransactionLimit),
+		SpendLimit:          int(req.SpendLimit),
+		SpendPeriod:         req.SpendPeriod,
+		AllowedCategories:   req.AllowedCategories,
+		BlockedCategories:   req.BlockedCategories,
+	}
+	if err := controls.Validate(); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	// Default expiry is one year; explicit expiries must be in the future
+	now := time.Now()
+	expiresAt := now.AddDate(1, 0, 0)
+	if req.ExpiresAt != "" {
+		parsed, err := parseRangeTime(req.ExpiresAt, true)
+		if err != nil {
+			WriteJSONBadRequest(w, fmt.Sprintf("expires_at: %v", err))
+			return
+		}
+		if !parsed.After(now) {
+			WriteJSONBadRequest(w, "expires_at must be in the future")
+			return
+		}
+		expiresAt = parsed
+	}
+
+	// Resolve the budget the card spends from
+	var budgetID int
+	if req.BudgetLimitID != nil && *req.BudgetLimitID > 0 {
+		var status string
+		err := database.DB.QueryRow("SELECT status FROM budget_limits WHERE id = ?", *req.BudgetLimitID).Scan(&status)
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("budget not found: %d", *req.BudgetLimitID), http.StatusNotFound)
+			return
+		}
+		budgetID = *req.BudgetLimitID
+	} else {
+		defaultBudget, err := FindOrCreateDefaultBudget()
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to get default budget: %w", err), http.StatusInternalServerError)
+			return
+		}
+		budgetID = defaultBudget.ID
+	}
+
+	issued, err := h.issuer.Issue(cards.IssueRequest{
+		Label:     req.Label,
+		Currency:  string(req.Currency),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to issue card: %w", err), http.StatusBadGateway)
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO virtual_cards (
+			issuer, external_id, label, currency, brand, last4, masked_pan, exp_month, exp_year,
+			budget_limit_id, per_transaction_limit, spend_limit, spend_period,
+			allowed_categories, blocked_categories, status, expires_at, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 'active', ?, ?, ?)
+	`,
+		h.issuer.Name(), issued.ExternalID, req.Label, req.Currency, issued.Brand, issued.Last4, issued.MaskedPAN,
+		issued.ExpMonth, issued.ExpYear, budgetID, controls.PerTransactionLimit, controls.SpendLimit,
+		controls.SpendPeriod, encodeCategories(controls.AllowedCategories), encodeCategories(controls.BlockedCategories),
+		expiresAt, now, now,
+	)
+	if err != nil {
+		// The issuer has a card we can't track, so make sure it can't be used
+		if freezeErr := h.issuer.Freeze(issued.ExternalID); freezeErr != nil {
+			fmt.Printf("Warning: Failed to freeze untracked card %s: %v\n", issued.ExternalID, freezeErr)
+		}
+		WriteJSONError(w, fmt.Errorf("failed to save card: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	card, err := scanCard(database.DB.QueryRow("SELECT "+cardColumns+" FROM virtual_cards WHERE id = ?", id))
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to fetch card: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	WriteJSONSuccessWithMessage(w, "Card created", card)
+}
+
+// List lists cards, optionally filtered by ?status=
+func (h *CardHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + cardColumns + " FROM virtual_cards"
+	var args []interface{}
+	if status := r.URL.Query().Get("status"); status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to list cards: %w", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	list := []VirtualCard{}
+	for rows.Next() {
+		card, err := scanCard(rows)
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to scan card: %w", err), http.StatusInternalServerError)
+			return
+		}
+		list = append(list, *card)
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"cards": list,
+		"count": len(list),
+	})
+}
+
+// Get returns a card with its spend in the current period
+func (h *CardHandler) Get(w http.ResponseWriter, r *http.Request) {
+	card, ok := loadCard(w, r)
+	if !ok {
+		return
+	}
+
+	spent, err := cardPeriodSpend(card.ID, card.Controls.SpendPeriod, time.Now())
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"card":         card,
+		"period_spent": spent,
+	})
+}
+
+// Freeze blocks all payments on a card
+func (h *CardHandler) Freeze(w http.ResponseWriter, r *http.Request) {
+	h.setFrozen(w, r, true)
+}
+
+// Unfreeze re-enables payments on a frozen card
+func (h *CardHandler) Unfreeze(w http.ResponseWriter, r *http.Request) {
+	h.setFrozen(w, r, false)
+}
+
+func (h *CardHandler) setFrozen(w http.ResponseWriter, r *http.Request, frozen bool) {
+	card, ok := loadCard(w, r)
+	if !ok {
+		return
+	}
+
+	from, to, action := cards.StatusActive, cards.StatusFrozen, h.issuer.Freeze
+	if !frozen {
+		from, to, action = cards.StatusFrozen, cards.StatusActive, h.issuer.Unfreeze
+	}
+
+	if card.Status == to {
+		WriteJSONSuccessWithMessage(w, fmt.Sprintf("Card is already %s", to), card)
+		return
+	}
+	if card.Status != from {
+		WriteJSONBadRequest(w, fmt.Sprintf("Cannot change a %s card", card.Status))
+		return
+	}
+
+	if err := action(card.ExternalID); err != nil {
+		WriteJSONError(w, fmt.Errorf("issuer rejected the change: %w", err), http.StatusBadGateway)
+		return
+	}
+
+	now := time.Now()
+	if _, err := database.DB.Exec("UPDATE virtual_cards SET status = ?, updated_at = ? WHERE id = ?", to, now, card.ID); err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to update card: %w", err), http.StatusInternalServerError)
+		return
+	}
+	card.Status = to
+	card.UpdatedAt = now
+
+	WriteJSONSuccessWithMessage(w, fmt.Sprintf("Card %s", to), card)
+}
+
+// UpdateControls changes a card's spend controls; omitted fields are unchanged
+func (h *CardHandler) UpdateControls(w http.ResponseWriter, r *http.Request) {
+	card, ok := loadCard(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateCardControlsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	controls := card.Controls
+	if req.PerTransactionLimit != nil {
+		controls.PerTransactionLimit = int(*req.PerTransactionLimit)
+	}
+	if req.SpendLimit != nil {
+		controls.SpendLimit = int(*req.SpendLimit)
+	}
+	if req.SpendPeriod != nil {
+		controls.SpendPeriod = *req.SpendPeriod
+	}
+	if req.AllowedCategories != nil {
+		controls.AllowedCategories = *req.AllowedCategories
+	}
+	if req.BlockedCategories != nil {
+		controls.BlockedCategories = *req.BlockedCategories
+	}
+	if err := controls.Validate(); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	now := time.Now()
+	_, err := database.DB.Exec(`
+		UPDATE virtual_cards
+		SET per_transaction_limit = ?, spend_limit = ?, spend_period = ?,
+		    allowed_categories = ?, blocked_categories = ?, updated_at = ?
+		WHERE id = ?
+	`, controls.PerTransactionLimit, controls.SpendLimit, controls.SpendPeriod,
+		encodeCategories(controls.AllowedCategories), encodeCategories(controls.BlockedCategories), now, card.ID)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to update card controls: %w", err), http.StatusInternalServerError)
+		return
+	}
+	card.Controls = controls
+	card.UpdatedAt = now
+
+	WriteJSONSuccessWithMessage(w, "Card controls updated", card)
+}
+
+// cardPeriodSpend sums approved payments in the card's current spend period
+func cardPeriodSpend(cardID int, period string, now time.Time) (int, error) {
+	var spent int
+	err := database.DB.QueryRow(
+		"SELECT COALESCE(SUM(amount), 0) FROM card_transactions WHERE card_id = ? AND status = 'approved' AND created_at >= ?",
+		cardID, cards.PeriodStart(period, now),
+	).Scan(&spent)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum card spend: %w", err)
+	}
+	return spent, nil
+}
+
+// Authorize simulates a card payment: controls, budget and transfer limits are
+// checked in turn and approved payments are recorded as expenses
+func (h *CardHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	card, ok := loadCard(w, r)
+	if !ok {
+		return
+	}
+
+	var req AuthorizeCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.MerchantName == "" {
+		WriteJSONBadRequest(w, "merchant_name is required")
+		return
+	}
+	if req.Currency == "" {
+		req.Currency = card.Currency
+	}
+	if err := req.Validate(); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	cardAuthMu.Lock()
+	defer cardAuthMu.Unlock()
+
+	now := time.Now()
+	txn := CardTransaction{
+		CardID:           card.ID,
+		Money:            req.Money,
+		MerchantName:     req.MerchantName,
+		MerchantCategory: req.MerchantCategory,
+		CreatedAt:        now,
+	}
+
+	decline := func(reason string) {
+		txn.Status = "declined"
+		txn.DeclineReason = reason
+		if err := saveCardTransaction(&txn); err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+		WriteJSONSuccessWithMessage(w, "Authorization declined", txn)
+	}
+
+	// Step 1: Card state and controls
+	spent, err := cardPeriodSpend(card.ID, card.Controls.SpendPeriod, now)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	state := cards.CardState{Status: card.Status, Currency: string(card.Currency), ExpiresAt: card.ExpiresAt, Controls: card.Controls}
+	if approved, reason := cards.Authorize(state, cards.Authorization{
+		Amount:           int(txn.Amount),
+		Currency:         string(txn.Currency),
+		MerchantName:     req.MerchantName,
+		MerchantCategory: req.MerchantCategory,
+	}, spent, now); !approved {
+		decline(reason)
+		return
+	}
+
+	// Step 2: Linked budget
+	budgetCheck, err := CheckBudgetAffordability(card.BudgetLimitID, txn.Money)
+	if errors.Is(err, money.ErrCurrencyMismatch) {
+		decline(fmt.Sprintf("the card's budget is not in %s", txn.Currency))
+		return
+	}
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("error checking budget: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if !budgetCheck.CanAfford {
+		decline(budgetCheck.Reason)
+		return
+	}
+
+	// Step 3: Account and beneficiary transfer limits
+	recipientCode := fmt.Sprintf("CARD_%d", card.ID)
+	reservationID, limitCheck, err := ReserveOutgoingPayment(recipientCode, txn.Money, "card", txn.MerchantName)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("error checking transfer limits: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if !limitCheck.Allowed {
+		decline(limitCheck.Reason)
+		return
+	}
+
+	// Step 4: Approved - record the payment as a paid expense against the card's budget
+	txn.Status = "approved"
+	if err := saveCardTransaction(&txn); err != nil {
+		ReleaseOutgoingPayment(reservationID)
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// The merchant's category is kept when given; otherwise rules and history decide
+	reference := fmt.Sprintf("CTX_%d", txn.ID)
+	narration := fmt.Sprintf("Card payment at %s (%s ••%s)", req.MerchantName, card.Label, card.Last4)
+	categoryResult, err := CategorizeExpense(req.MerchantCategory, categorize.Expense{
+		RecipientCode: recipientCode,
+		RecipientName: req.MerchantName,
+		Narration:     narration,
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to categorize card payment: %v\n", err)
+		categoryResult = categorize.Result{Category: req.MerchantCategory, Source: categorize.SourceManual}
+	}
+	category, categorySource, categoryRuleID := categoryColumns(categoryResult)
+
+	result, err := database.DB.Exec(`
+		INSERT INTO expenses (
+			recipient_code, recipient_name, amount, currency, category,
+			narration, reference, status, payment_date, budget_limit_id,
+			category_source, category_rule_id, created_at, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'paid', ?, ?, ?, ?, ?, ?)
+	`, recipientCode, req.MerchantName, txn.Amount, txn.Currency, category,
+		narration, reference, now, card.BudgetLimitID, categorySource, categoryRuleID, now, now)
+	if err != nil {
+		database.DB.Exec("DELETE FROM card_transactions WHERE id = ?", txn.ID)
+		ReleaseOutgoingPayment(reservationID)
+		WriteJSONError(w, fmt.Errorf("failed to record card expense: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	expenseID, _ := result.LastInsertId()
+	if err := PostExpenseJournal(expenseID); err != nil {
+		database.DB.Exec("DELETE FROM expenses WHERE id = ?", expenseID)
+		database.DB.Exec("DELETE FROM card_transactions WHERE id = ?", txn.ID)
+		ReleaseOutgoingPayment(reservationID)
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	id := int(expenseID)
+	txn.ExpenseID = &id
+	if _, err := database.DB.Exec("UPDATE card_transactions SET expense_id = ? WHERE id = ?", expenseID, txn.ID); err != nil {
+		fmt.Printf("Warning: Failed to link card transaction %d to expense: %v\n", txn.ID, err)
+	}
+	SetOutgoingPaymentReference(reservationID, reference)
+
+	WriteJSONSuccessWithMessage(w, "Authorization approved", map[string]interface{}{
+		"transaction": txn,
+		"budget_info": map[string]interface{}{
+			"budget_id": card.BudgetLimitID,
+			"remaining": budgetCheck.Remaining - txn.Amount,
+		},
+	})
+}
+
+func saveCardTransaction(txn *CardTransaction) error {
+	result, err := database.DB.Exec(`
+		INSERT INTO card_transactions (card_id, amount, currency, merchant_name, merchant_category, status, decline_reason, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, txn.CardID, txn.Amount, txn.Currency, txn.MerchantName, txn.MerchantCategory, txn.Status, txn.DeclineReason, txn.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record card transaction: %w", err)
+	}
+	id, _ := result.LastInsertId()
+	txn.ID = int(id)
+	return nil
+}
+
+// Transactions lists authorization attempts on a card, newest first
+func (h *CardHandler) Transactions(w http.ResponseWriter, r *http.Request) {
+	card, ok := loadCard(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT id, card_id, amount, currency, COALESCE(merchant_name, ''), COALESCE(merchant_category, ''),
+		       status, COALESCE(decline_reason, ''), expense_id, created_at
+		FROM card_transactions WHERE card_id = ? ORDER BY created_at DESC, id DESC
+	`, card.ID)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to list card transactions: %w", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	list := []CardTransaction{}
+	for rows.Next() {
+		var txn CardTransaction
+		if err := rows.Scan(
+			&txn.ID,
+			&txn.CardID,
+			&txn.Amount,
+			&txn.Currency,
+			&txn.MerchantName,
+			&txn.MerchantCategory,
+			&txn.Status,
+			&txn.DeclineReason,
+			&txn.ExpenseID,
+			&txn.CreatedAt,
+		); err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to scan card transaction: %w", err), http.StatusInternalServerError)
+			return
+		}
+		list = append(list, txn)
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"transactions": list,
+		"count":        len(list),
+	})
+}
@@ -0,0 +1,614 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Categories Handler - Expense Categorization
+//
+// OBJECTIVES:
+// Category reports and budgets are only as good as the categories on expenses,
+// and free-text categories are often missing or spelled five different ways.
+//
+// PURPOSE:
+// - Keep a canonical category tree (transport → ride_hailing) that reports can roll up
+// - Let users define rules such as "narration contains uber → transport"
+// - Fall back to how the same recipient's expenses were categorized before
+// - Categorize new expenses automatically and re-categorize historical ones on demand
+//
+// KEY WORKFLOW:
+// Expense Created → Category Given? (map onto canonical slug) → Otherwise Rules →
+// Otherwise Recipient History → Store Category With Its Source (manual, rule, learned)
+//
+// DESIGN DECISIONS:
+// - Matching and the tree live in internal/categorize; this file stores and loads them
+// - A category given with an expense always wins and is never overwritten by re-categorizing
+// - Categories that don't match the canonical list are kept as given, so old clients keep working
+// - Rules may only assign canonical categories
+// - Learning reads only categories people chose, so rules can't reinforce themselves
+// - Rules and history are loaded per expense; they are small and this keeps edits live
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/categorize"
+	"paystack.mpc.proxy/internal/database"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CategoryHandler struct{}
+
+func NewCategoryHandler() *CategoryHandler {
+	return &CategoryHandler{}
+}
+
+// loadTaxonomy reads the category tree
+func loadTaxonomy() (*categorize.Taxonomy, error) {
+	rows, err := database.DB.Query("SELECT slug, name, COALESCE(parent_slug, '') FROM categories")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []categorize.Category
+	for rows.Next() {
+		var c categorize.Category
+		if err := rows.Scan(&c.Slug, &c.Name, &c.Parent); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return categorize.NewTaxonomy(categories), nil
+}
+
+// loadCategoryRules reads rules matching a WHERE clause (which may be empty)
+func loadCategoryRules(where string, args ...interface{}) ([]categorize.Rule, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, COALESCE(name, ''), field, operator, value, category, priority, enabled
+		FROM category_rules`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load category rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []categorize.Rule{}
+	for rows.Next() {
+		var rule categorize.Rule
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.Field, &rule.Operator, &rule.Value, &rule.Category, &rule.Priority, &rule.Enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan category rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// loadCategoryHistory counts the categories people gave each recipient's expenses
+func loadCategoryHistory() (categorize.History, error) {
+	rows, err := database.DB.Query(`
+		SELECT recipient_code, category, COUNT(*)
+		FROM expenses
+		WHERE category IS NOT NULL AND category != ''
+		  AND COALESCE(category_source, ?) = ?
+		GROUP BY recipient_code, category
+	`, categorize.SourceManual, categorize.SourceManual)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load category history: %w", err)
+	}
+	defer rows.Close()
+
+	history := categorize.History{}
+	for rows.Next() {
+		var recipientCode, category string
+		var count int
+		if err := rows.Scan(&recipientCode, &category, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan category history: %w", err)
+		}
+		history.Add(recipientCode, category, count)
+	}
+	return history, rows.Err()
+}
+
+// newCategoryEngine builds an engine from the stored rules and history
+func newCategoryEngine() (*categorize.Engine, error) {
+	rules, err := loadCategoryRules(" WHERE enabled = 1")
+	if err != nil {
+		return nil, err
+	}
+	history, err := loadCategoryHistory()
+	if err != nil {
+		return nil, err
+	}
+	return categorize.NewEngine(rules, history), nil
+}
+
+// ResolveCategory maps a category given by a person onto its canonical slug.
+// Text that matches no canonical category is returned trimmed but otherwise as given.
+func ResolveCategory(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", nil
+	}
+	taxonomy, err := loadTaxonomy()
+	if err != nil {
+		return "", err
+	}
+	if slug, ok := taxonomy.Resolve(text); ok {
+		return slug, nil
+	}
+	return text, nil
+}
+
+// CategorizeExpense picks an expense's category: the one given, else a rule, else
+// the recipient's history. An empty result category means nothing applied.
+func CategorizeExpense(given string, expense categorize.Expense) (categorize.Result, error) {
+	if strings.TrimSpace(given) != "" {
+		category, err := ResolveCategory(given)
+		if err != nil {
+			return categorize.Result{}, err
+		}
+		return categorize.Result{Category: category, Source: categorize.SourceManual, Confidence: 1}, nil
+	}
+
+	engine, err := newCategoryEngine()
+	if err != nil {
+		return categorize.Result{}, err
+	}
+	return engine.Categorize(expense), nil
+}
+
+// categoryColumns returns the category, category_source and category_rule_id values to store
+func categoryColumns(result categorize.Result) (interface{}, interface{}, interface{}) {
+	if result.Category == "" {
+		return "", nil, nil
+	}
+	var ruleID interface{}
+	if result.RuleID > 0 {
+		ruleID = result.RuleID
+	}
+	return result.Category, result.Source, ruleID
+}
+
+// CategoryWithPath is a category with its place in the tree
+type CategoryWithPath struct {
+	categorize.Category
+	Path []string `json:"path"`
+}
+
+// List returns the category tree, or a flat list with each category's path when ?flat=true
+func (h *CategoryHandler) List(w http.ResponseWriter, r *http.Request) {
+	taxonomy, err := loadTaxonomy()
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("flat") == "true" {
+		categories := []CategoryWithPath{}
+		var walk func(nodes []categorize.Node)
+		walk = func(nodes []categorize.Node) {
+			for _, node := range nodes {
+				categories = append(categories, CategoryWithPath{Category: node.Category, Path: taxonomy.Path(node.Slug)})
+				walk(node.Children)
+			}
+		}
+		walk(taxonomy.Tree())
+		WriteJSONSuccess(w, map[string]interface{}{
+			"categories": categories,
+			"count":      len(categories),
+		})
+		return
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"categories": taxonomy.Tree(),
+	})
+}
+
+// Create adds a category. The slug defaults to the slugified name.
+func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req categorize.Category
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.Slug == "" {
+		req.Slug = categorize.Slugify(req.Name)
+	}
+	if err := req.Validate(); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	taxonomy, err := loadTaxonomy()
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if _, exists := taxonomy.Get(req.Slug); exists {
+		WriteJSONError(w, fmt.Errorf("category already exists: %s", req.Slug), http.StatusConflict)
+		return
+	}
+	if req.Parent != "" {
+		if _, ok := taxonomy.Get(req.Parent); !ok {
+			WriteJSONBadRequest(w, fmt.Sprintf("parent category not found: %s", req.Parent))
+			return
+		}
+	}
+
+	_, err = database.DB.Exec(
+		"INSERT INTO categories (slug, name, parent_slug) VALUES (?, ?, ?)",
+		req.Slug, strings.TrimSpace(req.Name), req.Parent,
+	)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to create category: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	WriteJSONSuccessWithMessage(w, "Category created", CategoryWithPath{
+		Category: req,
+		Path:     append(taxonomy.Path(req.Parent), req.Slug),
+	})
+}
+
+// CategoryRuleRequest creates or updates a rule; omitted fields keep their value on update
+type CategoryRuleRequest struct {
+	Name     *string              `json:"name,omitempty"`
+	Field    *categorize.Field    `json:"field,omitempty"`
+	Operator *categorize.Operator `json:"operator,omitempty"`
+	Value    *string              `json:"value,omitempty"`
+	Category *string              `json:"category,omitempty"`
+	Priority *int                 `json:"priority,omitempty"`
+	Enabled  *bool                `json:"enabled,omitempty"`
+}
+
+// apply copies the request's fields onto a rule and checks the result
+func (req CategoryRuleRequest) apply(rule *categorize.Rule) error {
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Field != nil {
+		rule.Field = *req.Field
+	}
+	if req.Operator != nil {
+		rule.Operator = *req.Operator
+	}
+	if req.Value != nil {
+		rule.Value = *req.Value
+	}
+	if req.Category != nil {
+		rule.Category = *req.Category
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	// Rules may only assign canonical categories
+	taxonomy, err := loadTaxonomy()
+	if err != nil {
+		return err
+	}
+	slug, ok := taxonomy.Resolve(rule.Category)
+	if !ok {
+		return fmt.Errorf("unknown category: %s (create it first)", rule.Category)
+	}
+	rule.Category = slug
+	return nil
+}
+
+// loadCategoryRule reads the rule named by the {id} URL parameter, writing an error response if it can't
+func loadCategoryRule(w http.ResponseWriter, r *http.Request) (categorize.Rule, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteJSONBadRequest(w, "invalid rule id")
+		return categorize.Rule{}, false
+	}
+	rules, err := loadCategoryRules(" WHERE id = ?", id)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return categorize.Rule{}, false
+	}
+	if len(rules) == 0 {
+		WriteJSONError(w, fmt.Errorf("category rule not found: %d", id), http.StatusNotFound)
+		return categorize.Rule{}, false
+	}
+	return rules[0], true
+}
+
+// ListRules lists rules in the order they are tried
+func (h *CategoryHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := loadCategoryRules(" ORDER BY priority DESC, id")
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccess(w, map[string]interface{}{
+		"rules": rules,
+		"count": len(rules),
+	})
+}
+
+// CreateRule adds a rule. Rules are enabled unless told otherwise.
+func (h *CategoryHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var req CategoryRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	rule := categorize.Rule{Enabled: true}
+	if err := req.apply(&rule); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO category_rules (name, field, operator, value, category, priority, enabled)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, rule.Name, rule.Field, rule.Operator, rule.Value, rule.Category, rule.Priority, rule.Enabled)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to create category rule: %w", err), http.StatusInternalServerError)
+		return
+	}
+	id, _ := result.LastInsertId()
+	rule.ID = int(id)
+
+	WriteJSONSuccessWithMessage(w, "Category rule created", rule)
+}
+
+// UpdateRule changes the fields given
+func (h *CategoryHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := loadCategoryRule(w, r)
+	if !ok {
+		return
+	}
+
+	var req CategoryRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+	if err := req.apply(&rule); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	_, err := database.DB.Exec(`
+		UPDATE category_rules
+		SET name = ?, field = ?, operator = ?, value = ?, category = ?, priority = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`, rule.Name, rule.Field, rule.Operator, rule.Value, rule.Category, rule.Priority, rule.Enabled, time.Now(), rule.ID)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to update category rule: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	WriteJSONSuccessWithMessage(w, "Category rule updated", rule)
+}
+
+// DeleteRule removes a rule. Expenses it categorized keep their category.
+func (h *CategoryHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := loadCategoryRule(w, r)
+	if !ok {
+		return
+	}
+	if _, err := database.DB.Exec("DELETE FROM category_rules WHERE id = ?", rule.ID); err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to delete category rule: %w", err), http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, "Category rule deleted", map[string]interface{}{"id": rule.ID})
+}
+
+// Suggest shows the category an expense would get, without recording anything
+func (h *CategoryHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	var req categorize.Expense
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.RecipientCode != "" && req.RecipientName == "" {
+		database.DB.QueryRow("SELECT name FROM recipients WHERE recipient_code = ?", req.RecipientCode).Scan(&req.RecipientName)
+	}
+
+	result, err := CategorizeExpense("", req)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccess(w, result)
+}
+
+type RecategorizeRequest struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// IncludeAutomatic also revisits expenses a rule or history categorized
+	IncludeAutomatic bool `json:"include_automatic,omitempty"`
+	DryRun           bool `json:"dry_run,omitempty"`
+}
+
+// CategoryChange is one expense whose category changed (or would change)
+type CategoryChange struct {
+	ExpenseID int    `json:"expense_id"`
+	Narration string `json:"narration"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Source    string `json:"source"`
+	RuleID    int    `json:"rule_id,omitempty"`
+}
+
+// maxReportedChanges caps the changes listed in a re-categorize response
+const maxReportedChanges = 100
+
+// Recategorize applies the current rules and history to past expenses.
+// Categories people chose are never changed.
+func (h *CategoryHandler) Recategorize(w http.ResponseWriter, r *http.Request) {
+	var req RecategorizeRequest
+	if r.Body != http.NoBody {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteJSONBadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	query := `
+		SELECT id, recipient_code, recipient_name, narration, COALESCE(category, ''), COALESCE(category_source, '')
+		FROM expenses
+		WHERE (category IS NULL OR category = ''`
+	args := []interface{}{}
+	if req.IncludeAutomatic {
+		query += " OR category_source IN (?, ?)"
+		args = append(args, categorize.SourceRule, categorize.SourceLearned)
+	}
+	query += ")"
+	if req.From != "" {
+		from, err := parseRangeTime(req.From, false)
+		if err != nil {
+			WriteJSONBadRequest(w, err.Error())
+			return
+		}
+		query += " AND created_at >= ?"
+		args = append(args, from)
+	}
+	if req.To != "" {
+		to, err := parseRangeTime(req.To, true)
+		if err != nil {
+			WriteJSONBadRequest(w, err.Error())
+			return
+		}
+		query += " AND created_at < ?"
+		args = append(args, to)
+	}
+
+	engine, err := newCategoryEngine()
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to load expenses: %w", err), http.StatusInternalServerError)
+		return
+	}
+	examined := 0
+	var changes []CategoryChange
+	var results []categorize.Result
+	for rows.Next() {
+		var change CategoryChange
+		var expense categorize.Expense
+		var source string
+		if err := rows.Scan(&change.ExpenseID, &expense.RecipientCode, &expense.RecipientName, &expense.Narration, &change.From, &source); err != nil {
+			rows.Close()
+			WriteJSONError(w, fmt.Errorf("failed to scan expense: %w", err), http.StatusInternalServerError)
+			return
+		}
+		examined++
+
+		result := engine.Categorize(expense)
+		if result.Category == "" || (result.Category == change.From && result.Source == source) {
+			continue
+		}
+		change.Narration = expense.Narration
+		change.To = result.Category
+		change.Source = result.Source
+		change.RuleID = result.RuleID
+		changes = append(changes, change)
+		results = append(results, result)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		WriteJSONError(w, fmt.Errorf("error iterating expenses: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	if !req.DryRun && len(changes) > 0 {
+		if err := saveCategoryChanges(changes, results); err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	bySource := map[string]int{}
+	for _, change := range changes {
+		bySource[change.Source]++
+	}
+	reported := changes
+	if len(reported) > maxReportedChanges {
+		reported = reported[:maxReportedChanges]
+	}
+	if reported == nil {
+		reported = []CategoryChange{}
+	}
+
+	message := fmt.Sprintf("Re-categorized %d of %d expenses", len(changes), examined)
+	if req.DryRun {
+		message = fmt.Sprintf("Would re-categorize %d of %d expenses", len(changes), examined)
+	}
+	WriteJSONSuccessWithMessage(w, message, map[string]interface{}{
+		"examined":  examined,
+		"changed":   len(changes),
+		"by_source": bySource,
+		"dry_run":   req.DryRun,
+		"changes":   reported,
+	})
+}
+
+// saveCategoryChanges writes re-categorized expenses in one transaction
+func saveCategoryChanges(changes []CategoryChange, results []categorize.Result) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for i, change := range changes {
+		category, source, ruleID := categoryColumns(results[i])
+		_, err := tx.Exec(
+			"UPDATE expenses SET category = ?, category_source = ?, category_rule_id = ?, updated_at = ? WHERE id = ?",
+			category, source, ruleID, now, change.ExpenseID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to re-categorize expense %d: %w", change.ExpenseID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// expandCategory returns a category and everything under it, for filters that roll up
+func expandCategory(category string) []string {
+	taxonomy, err := loadTaxonomy()
+	if err != nil {
+		return []string{category}
+	}
+	slug, ok := taxonomy.Resolve(category)
+	if !ok {
+		return []string{category}
+	}
+	return taxonomy.Descendants(slug)
+}
+
+// inPlaceholders returns "?, ?, ?" for n values
+func inPlaceholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// stringArgs converts strings to query arguments
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"paystack.mpc.proxy/internal/anomaly"
+	"paystack.mpc.proxy/internal/confirm"
+	"paystack.mpc.proxy/internal/money"
+)
+
+// ConfirmationPreview is returned by money-moving endpoints instead of executing.
+// Sending the same endpoint {"confirmation_token": "..."} executes the previewed request.
+type ConfirmationPreview struct {
+	ConfirmationRequired bool   `json:"confirmation_required"`
+	Action               string `json:"action"`
+	money.Money
+	RecipientName string              `json:"recipient_name"`
+	BudgetImpact  *CheckLimitResponse `json:"budget_impact,omitempty"`
+	LimitAlerts   []string            `json:"limit_alerts,omitempty"`
+	// Warnings flag a payment that looks like a duplicate or is unusually large
+	Warnings []anomaly.Warning `json:"warnings,omitempty"`
+	Summary  string            `json:"summary"`
+	// Schedule says when a recurring payment runs, e.g. "every month from 2026-11-01"
+	Schedule string `json:"schedule,omitempty"`
+	// Payments lists each payment in a batch
+	Payments          []BulkTransferItem `json:"payments,omitempty"`
+	ConfirmationToken string             `json:"confirmation_token"`
+	ExpiresAt         time.Time          `json:"expires_at"`
+}
+
+// writeConfirmationPreview stores payload as a pending confirmation and writes the preview
+func writeConfirmationPreview(w http.ResponseWriter, action string, payload interface{}, preview ConfirmationPreview) {
+	token, expiresAt, err := confirm.Issue(action, payload)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to issue confirmation: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	preview.ConfirmationRequired = true
+	preview.Action = action
+	preview.ConfirmationToken = token
+	preview.ExpiresAt = expiresAt
+
+	WriteJSONSuccessWithMessage(w, "Confirmation required", preview)
+}
+
+// redeemConfirmation loads the previewed request for token into out.
+// It writes the error response and returns false when the token is rejected.
+func redeemConfirmation(w http.ResponseWriter, token, action string, out interface{}) bool {
+	err := confirm.Redeem(token, action, out)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, confirm.ErrAlreadyUsed):
+		WriteJSONError(w, err, http.StatusConflict)
+	case errors.Is(err, confirm.ErrExpired):
+		WriteJSONError(w, err, http.StatusGone)
+	case errors.Is(err, confirm.ErrInvalidToken), errors.Is(err, confirm.ErrActionMismatch):
+		WriteJSONError(w, err, http.StatusBadRequest)
+	default:
+		WriteJSONError(w, err, http.StatusInternalServerError)
+	}
+	return false
+}
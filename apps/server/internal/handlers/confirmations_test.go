@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"paystack.mpc.proxy/internal/confirm"
+	"paystack.mpc.proxy/internal/database"
+)
+
+func setupHandlerDB(t *testing.T) {
+	t.Helper()
+	if err := database.Initialize(filepath.Join(t.TempDir(), "handlers.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	confirm.Configure("test-secret")
+}
+
+// post calls handler with body and decodes the response envelope
+func post(t *testing.T, handler http.HandlerFunc, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Failed to encode body: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(encoded)))
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, response
+}
+
+func TestConfirmIgnoresFieldsAddedAfterPreview(t *testing.T) {
+	setupHandlerDB(t)
+	h := NewExpenseHandler()
+
+	code, preview := post(t, h.Create, map[string]interface{}{
+		"recipient_code": defaultRecipientCode,
+		"amount":         500000,
+		"narration":      "Internet subscription",
+	})
+	if code != http.StatusOK {
+		t.Fatalf("Expected a preview, got %d: %v", code, preview)
+	}
+	token, _ := preview["data"].(map[string]interface{})["confirmation_token"].(string)
+	if token == "" {
+		t.Fatalf("Expected a confirmation token, got %v", preview)
+	}
+
+	code, confirmed := post(t, h.Create, map[string]interface{}{
+		"confirmation_token": token,
+		"notes":              "never previewed",
+		"reference":          "INJECTED",
+		"category":           "travel",
+	})
+	if code != http.StatusOK {
+		t.Fatalf("Expected the expense to be created, got %d: %v", code, confirmed)
+	}
+
+	var notes, reference, category string
+	err := database.DB.QueryRow("SELECT notes, reference, category FROM expenses").Scan(&notes, &reference, &category)
+	if err != nil {
+		t.Fatalf("Failed to load expense: %v", err)
+	}
+	if notes != "" || reference == "INJECTED" || category == "travel" {
+		t.Errorf("Expected fields sent with the token to be ignored, got notes %q, reference %q, category %q", notes, reference, category)
+	}
+}
+
+func TestConfirmScheduleIgnoresFieldsAddedAfterPreview(t *testing.T) {
+	setupHandlerDB(t)
+	h := NewScheduleHandler(nil)
+
+	code, preview := post(t, h.Create, map[string]interface{}{
+		"recipient_code": defaultRecipientCode,
+		"amount":         500000,
+		"narration":      "Internet subscription",
+		"every":          1,
+		"unit":           "month",
+	})
+	if code != http.StatusOK {
+		t.Fatalf("Expected a preview, got %d: %v", code, preview)
+	}
+	token, _ := preview["data"].(map[string]interface{})["confirmation_token"].(string)
+
+	code, confirmed := post(t, h.Create, map[string]interface{}{
+		"confirmation_token": token,
+		"auto_transfer":      true,
+		"notes":              "never previewed",
+	})
+	if code != http.StatusOK {
+		t.Fatalf("Expected the schedule to be created, got %d: %v", code, confirmed)
+	}
+
+	var autoTransfer bool
+	var notes string
+	if err := database.DB.QueryRow("SELECT auto_transfer, COALESCE(notes, '') FROM schedules").Scan(&autoTransfer, &notes); err != nil {
+		t.Fatalf("Failed to load schedule: %v", err)
+	}
+	if autoTransfer || notes != "" {
+		t.Errorf("Expected fields sent with the token to be ignored, got auto_transfer %v, notes %q", autoTransfer, notes)
+	}
+}
@@ -12,25 +12,41 @@
 // - Maintain payment history for analysis and reporting
 //
 // KEY WORKFLOW:
-// Create Expense → Validate Recipient → Check Budget Limit → Update Budget Spent →
-// Link to Goal (if applicable) → Return Budget Status
+// Create Expense → Validate Recipient → Check Budget Limit → Check for Anomalies → Return Preview + Token →
+// Confirm With Token → Post to Ledger (budget + goal) → Return Budget Status and Warnings
 //
 // DESIGN DECISIONS:
 // - We use 'narration' instead of 'description' to better convey the story behind each expense
-// - Budget tracking is automatic - when you create an expense in a category, it updates the relevant budget
+// - Budget tracking is automatic - every expense posts to the ledger, which derives the budget's spent amount
 // - Expenses are pending by default, allowing for approval workflows
 // - All amounts stored in kobo (Nigerian currency subunit) for precision
+// - Foreign-currency expenses are converted into the budget's currency at the day's rate;
+//   both amounts and the rate used are stored so later rate changes don't rewrite history
 // - Recipients are validated against local cache to prevent invalid expense creation
+// - Nothing is recorded until the previewed request is confirmed with its one-time token
+// - Expense payments count against account and per-beneficiary transfer limits
+// - Expenses without a category are categorized by the user's rules, then by the recipient's history
+// - Likely duplicates and unusually large amounts are flagged in the preview and the response (see anomalies.go)
+// - The recipient can be named by name or alias instead of code (see recipient_resolution.go); a group's
+//   name is pointed at bulk transfers, since one expense pays one recipient
 package handlers
 
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"paystack.mpc.proxy/internal/anomaly"
+	"paystack.mpc.proxy/internal/categorize"
+	"paystack.mpc.proxy/internal/confirm"
 	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/fx"
+	"paystack.mpc.proxy/internal/money"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -46,8 +62,7 @@ type Expense struct {
 	ID            int        `json:"id"`
 	RecipientCode string     `json:"recipient_code"`
 	RecipientName string     `json:"recipient_name"`
-	Amount        int        `json:"amount"`
-	Currency      string     `json:"currency"`
+	money.Money
 	Category      string     `json:"category"`
 	Narration     string     `json:"narration"`
 	Reference     string     `json:"reference"`
@@ -58,18 +73,27 @@ type Expense struct {
 	BudgetLimitID *int       `json:"budget_limit_id,omitempty"`
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// CategorySource is how the category was chosen: manual, rule or learned
+	CategorySource string `json:"category_source,omitempty"`
+	// Conversion is the amount charged to a budget in another currency
+	Conversion *FXConversion `json:"conversion,omitempty"`
 }
 
 type CreateExpenseRequest struct {
 	RecipientCode string `json:"recipient_code"`
-	Amount        int    `json:"amount"`
-	Currency      string `json:"currency,omitempty"`
+	// Recipient names the recipient by name or alias instead of recipient_code
+	Recipient     string `json:"recipient,omitempty"`
+	money.Money
 	Category      string `json:"category,omitempty"`
 	Narration     string `json:"narration"`
 	Reference     string `json:"reference,omitempty"`
 	Notes         string `json:"notes,omitempty"`
 	GoalID        *int   `json:"goal_id,omitempty"`
 	BudgetLimitID *int   `json:"budget_limit_id,omitempty"`
+
+	// ConfirmationToken executes a previously previewed expense
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
 }
 
 type UpdateExpenseRequest struct {
@@ -84,13 +108,16 @@ type ListExpensesRequest struct {
 	RecipientCode string `json:"recipient_code,omitempty"`
 	Category      string `json:"category,omitempty"`
 	Status        string `json:"status,omitempty"`
+	Currency      string `json:"currency,omitempty"`
 	From          string `json:"from,omitempty"`
 	To            string `json:"to,omitempty"`
 	Count         int    `json:"count,omitempty"`
 	Offset        int    `json:"offset,omitempty"`
 }
 
-// Create creates a new expense with budget validation
+// Create creates a new expense with budget validation.
+// The first call returns a preview and confirmation token; the expense is only
+// recorded when the token is sent back.
 func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req CreateExpenseRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -98,9 +125,28 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A confirmation token replaces the body with the previewed request; nothing else
+	// in the body is kept, so a field can't be added after the preview
+	confirmed := false
+	if req.ConfirmationToken != "" {
+		var previewed CreateExpenseRequest
+		if !redeemConfirmation(w, req.ConfirmationToken, confirm.ActionExpense, &previewed) {
+			return
+		}
+		req = previewed
+		confirmed = true
+	}
+
+	// A spoken name or alias must resolve to one recipient before anything else
+	if req.RecipientCode == "" && req.Recipient != "" {
+		if !resolveExpenseRecipient(w, &req) {
+			return
+		}
+	}
+
 	// Validate required fields
 	if req.RecipientCode == "" {
-		WriteJSONBadRequest(w, "recipient_code is required")
+		WriteJSONBadRequest(w, "recipient_code or recipient is required")
 		return
 	}
 
@@ -115,17 +161,21 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Set default currency
-	if req.Currency == "" {
-		req.Currency = "NGN"
-	}
+	req.Currency = req.Currency.OrDefault()
 
-	// Verify recipient exists
-	var recipientName string
-	err := database.DB.QueryRow("SELECT name FROM recipients WHERE recipient_code = ?", req.RecipientCode).Scan(&recipientName)
+	// Verify recipient exists and hasn't been deleted; a duplicate pays the recipient it duplicates
+	code, err := payableRecipientCode(req.RecipientCode)
+	if errors.Is(err, errRecipientDeleted) {
+		WriteJSONError(w, err, http.StatusConflict)
+		return
+	}
 	if err != nil {
 		WriteJSONError(w, fmt.Errorf("recipient not found: %s", req.RecipientCode), http.StatusNotFound)
 		return
 	}
+	req.RecipientCode = code
+	var recipientName string
+	database.DB.QueryRow("SELECT name FROM recipients WHERE recipient_code = ?", req.RecipientCode).Scan(&recipientName)
 
 	// BUDGET RESOLUTION LOGIC
 	// Step 1: Determine which budget to use
@@ -136,7 +186,7 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		// Goal provided - get goal's budget_limit_id
 		var goalBudgetID sql.NullInt64
 		var goalStatus string
-		var goalTargetAmount int
+		var goalTargetAmount money.Amount
 		err := database.DB.QueryRow(
 			"SELECT budget_limit_id, status, target_amount FROM goals WHERE id = ?",
 			*req.GoalID,
@@ -194,8 +244,25 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		budgetID = defaultBudget.ID
 	}
 
-	// Step 2: Check if budget can afford this expense
-	checkResp, err := CheckBudgetAffordability(budgetID, req.Amount)
+	// Goal targets are in the budget's currency, so goal expenses must be too
+	if goalID != nil {
+		budgetCurrency, err := BudgetCurrency(budgetID)
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("error checking budget: %w", err), http.StatusInternalServerError)
+			return
+		}
+		if req.Currency != budgetCurrency {
+			WriteJSONBadRequest(w, fmt.Sprintf("goal expenses must be in the goal's currency (%s)", budgetCurrency))
+			return
+		}
+	}
+
+	// Step 2: Check if budget can afford this expense, converted into its currency at today's rate
+	checkResp, err := CheckBudgetAffordabilityOn(budgetID, req.Money, time.Now())
+	if errors.Is(err, fx.ErrNoRate) {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
 	if err != nil {
 		WriteJSONError(w, fmt.Errorf("error checking budget: %w", err), http.StatusInternalServerError)
 		return
@@ -216,6 +283,7 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 				"usage_before":      checkResp.UsageBefore,
 				"usage_after":       checkResp.UsageAfter,
 				"reason":            checkResp.Reason,
+				"conversion":        checkResp.Conversion,
 				"suggestions": []string{
 					"Reduce the expense amount to fit within the budget",
 					"Increase the budget limit to accommodate this expense",
@@ -227,21 +295,84 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Step 3: Budget can afford - create the expense
+	// Map the given category onto the canonical list, or pick one from rules and history
+	categoryResult, err := CategorizeExpense(req.Category, categorize.Expense{
+		RecipientCode: req.RecipientCode,
+		RecipientName: recipientName,
+		Narration:     req.Narration,
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to categorize expense: %v\n", err)
+		categoryResult = categorize.Result{Category: req.Category, Source: categorize.SourceManual}
+	}
+	category, categorySource, categoryRuleID := categoryColumns(categoryResult)
+
+	// Look for duplicates and unusual amounts; these warn but never block
+	payment := anomaly.Payment{
+		RecipientCode: req.RecipientCode,
+		RecipientName: recipientName,
+		Money:         req.Money,
+		Narration:     req.Narration,
+		Category:      categoryResult.Category,
+	}
+	warnings := checkPayment(payment)
+
+	// Budget can afford - preview until the user confirms
+	if !confirmed {
+		limitCheck, err := CheckTransferLimits(req.RecipientCode, req.Money)
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("error checking transfer limits: %w", err), http.StatusInternalServerError)
+			return
+		}
+		if !limitCheck.Allowed {
+			writeLimitRejection(w, limitCheck)
+			return
+		}
+
+		writeConfirmationPreview(w, confirm.ActionExpense, req, ConfirmationPreview{
+			Money:         req.Money,
+			RecipientName: recipientName,
+			BudgetImpact:  checkResp,
+			LimitAlerts:   limitCheck.Alerts,
+			Warnings:      warnings,
+			Summary:       fmt.Sprintf("Pay %s to %s for %s", req.Format(), recipientName, req.Narration),
+		})
+		return
+	}
+
+	// Step 3: Confirmed - reserve against transfer limits, then create the expense
+	reservationID, limitCheck, err := ReserveOutgoingPayment(req.RecipientCode, req.Money, "expense", req.Narration)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("error checking transfer limits: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if !limitCheck.Allowed {
+		writeLimitRejection(w, limitCheck)
+		return
+	}
+
 	// Generate reference if not provided
 	reference := req.Reference
 	if reference == "" {
 		reference = fmt.Sprintf("EXP_%d", time.Now().Unix())
 	}
 
+	// Record what the budget is charged, and the rate used when that differs from what was paid
+	var fxRate, fxRateDate interface{}
+	if checkResp.Conversion != nil {
+		fxRate = checkResp.Conversion.Rate
+		fxRateDate = checkResp.Conversion.RateDate
+	}
+
 	// Insert expense with budget tracking
 	query := `
 		INSERT INTO expenses (
 			recipient_code, recipient_name, amount, currency, category,
 			narration, reference, status, notes, goal_id, budget_limit_id,
-			created_at, updated_at
+			budget_amount, budget_currency, fx_rate, fx_rate_date,
+			category_source, category_rule_id, created_at, updated_at
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, 'pending', ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'pending', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	now := time.Now()
@@ -251,28 +382,38 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		recipientName,
 		req.Amount,
 		req.Currency,
-		req.Category,
+		category,
 		req.Narration,
 		reference,
 		req.Notes,
 		goalID,
 		budgetID,
+		checkResp.RequestedAmount,
+		checkResp.Currency,
+		fxRate,
+		fxRateDate,
+		categorySource,
+		categoryRuleID,
 		now,
 		now,
 	)
 
 	if err != nil {
+		ReleaseOutgoingPayment(reservationID)
 		WriteJSONError(w, fmt.Errorf("failed to create expense: %w", err), http.StatusInternalServerError)
 		return
 	}
 
 	expenseID, _ := result.LastInsertId()
+	SetOutgoingPaymentReference(reservationID, reference)
 
-	// Step 4: Update budget spent amount
-	err = UpdateBudgetSpending(budgetID, req.Amount)
+	// Step 4: Post to the ledger, which charges the budget and funds the goal
+	err = PostExpenseJournal(expenseID)
 	if err != nil {
-		// Rollback expense creation
-		database.DB.Exec("DELETE FROM expenses WHERE id = ?", expenseID)
+		// Rollback expense creation, including any journal entries posted before the failure
+		if undoErr := discardExpense(expenseID, budgetID, reservationID); undoErr != nil {
+			fmt.Printf("Warning: %v\n", undoErr)
+		}
 		WriteJSONError(w, fmt.Errorf("failed to update budget: %w", err), http.StatusInternalServerError)
 		return
 	}
@@ -299,9 +440,8 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		ID:            int(expenseID),
 		RecipientCode: req.RecipientCode,
 		RecipientName: recipientName,
-		Amount:        req.Amount,
-		Currency:      req.Currency,
-		Category:      req.Category,
+		Money:         req.Money,
+		Category:      categoryResult.Category,
 		Narration:     req.Narration,
 		Reference:     reference,
 		Status:        "pending",
@@ -310,6 +450,9 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		BudgetLimitID: &budgetID,
 		CreatedAt:     now,
 		UpdatedAt:     now,
+		Conversion:    checkResp.Conversion,
+
+		CategorySource: categoryResult.Source,
 	}
 
 	// Include budget information in response
@@ -319,8 +462,8 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 			"budget_id":          budgetID,
 			"budget_limit":       checkResp.BudgetLimit,
 			"previous_spent":     checkResp.SpentAmount,
-			"new_spent":          checkResp.SpentAmount + req.Amount,
-			"remaining":          checkResp.Remaining - req.Amount,
+			"new_spent":          checkResp.SpentAmount + checkResp.RequestedAmount,
+			"remaining":          checkResp.Remaining - checkResp.RequestedAmount,
 			"usage_before":       checkResp.UsageBefore,
 			"usage_after":        checkResp.UsageAfter,
 		},
@@ -331,7 +474,9 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		responseData["goal_id"] = *goalID
 	}
 
-	WriteJSONSuccess(w, responseData)
+	payment.Reference = reference
+	recordAnomalies("expense", payment, warnings)
+	WriteJSONSuccessWithWarnings(w, responseData, warnings)
 }
 
 // List lists expenses with optional filters
@@ -342,34 +487,8 @@ func (h *ExpenseHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Build query with filters
-	query := `SELECT id, recipient_code, recipient_name, amount, currency, category, narration, reference, status, payment_date, notes, goal_id, budget_limit_id, created_at, updated_at FROM expenses WHERE 1=1`
-	args := []interface{}{}
-
-	// Add filters
-	if req.RecipientCode != "" {
-		query += " AND recipient_code = ?"
-		args = append(args, req.RecipientCode)
-	}
-
-	if req.Category != "" {
-		query += " AND category = ?"
-		args = append(args, req.Category)
-	}
-
-	if req.Status != "" {
-		query += " AND status = ?"
-		args = append(args, req.Status)
-	}
-
-	if req.From != "" {
-		query += " AND created_at >= ?"
-		args = append(args, req.From)
-	}
-
-	if req.To != "" {
-		query += " AND created_at <= ?"
-		args = append(args, req.To)
-	}
+	filters, args := expenseFilters(req)
+	query := `SELECT id, recipient_code, recipient_name, amount, currency, category, COALESCE(category_source, ''), narration, reference, status, payment_date, notes, goal_id, budget_limit_id, budget_amount, budget_currency, fx_rate, fx_rate_date, created_at, updated_at FROM expenses WHERE 1=1` + filters
 
 	// Add ordering
 	query += " ORDER BY created_at DESC"
@@ -392,7 +511,9 @@ func (h *ExpenseHandler) List(w http.ResponseWriter, r *http.Request) {
 		var expense Expense
 		var category, notes sql.NullString
 		var paymentDate sql.NullTime
-		var goalID, budgetLimitID sql.NullInt64
+		var goalID, budgetLimitID, budgetAmount sql.NullInt64
+		var budgetCurrency, fxRateDate sql.NullString
+		var fxRate sql.NullFloat64
 
 		err := rows.Scan(
 			&expense.ID,
@@ -401,6 +522,7 @@ func (h *ExpenseHandler) List(w http.ResponseWriter, r *http.Request) {
 			&expense.Amount,
 			&expense.Currency,
 			&category,
+			&expense.CategorySource,
 			&expense.Narration,
 			&expense.Reference,
 			&expense.Status,
@@ -408,6 +530,10 @@ func (h *ExpenseHandler) List(w http.ResponseWriter, r *http.Request) {
 			&notes,
 			&goalID,
 			&budgetLimitID,
+			&budgetAmount,
+			&budgetCurrency,
+			&fxRate,
+			&fxRateDate,
 			&expense.CreatedAt,
 			&expense.UpdatedAt,
 		)
@@ -433,6 +559,7 @@ func (h *ExpenseHandler) List(w http.ResponseWriter, r *http.Request) {
 			bid := int(budgetLimitID.Int64)
 			expense.BudgetLimitID = &bid
 		}
+		expense.Conversion = storedConversion(expense.Money, budgetAmount, budgetCurrency, fxRate, fxRateDate)
 
 		expenses = append(expenses, expense)
 	}
@@ -445,6 +572,47 @@ func (h *ExpenseHandler) List(w http.ResponseWriter, r *http.Request) {
 	WriteJSONSuccess(w, expenses)
 }
 
+// expenseFilters turns list filters into SQL conditions on the expenses table.
+// List and the exports share it so both select the same expenses.
+func expenseFilters(req ListExpensesRequest) (string, []interface{}) {
+	query := ""
+	args := []interface{}{}
+
+	if req.RecipientCode != "" {
+		query += " AND recipient_code = ?"
+		args = append(args, req.RecipientCode)
+	}
+
+	// A parent category includes everything under it
+	if req.Category != "" {
+		categories := expandCategory(req.Category)
+		query += " AND category IN (" + inPlaceholders(len(categories)) + ")"
+		args = append(args, stringArgs(categories)...)
+	}
+
+	if req.Status != "" {
+		query += " AND status = ?"
+		args = append(args, req.Status)
+	}
+
+	if req.Currency != "" {
+		query += " AND currency = ?"
+		args = append(args, strings.ToUpper(req.Currency))
+	}
+
+	if req.From != "" {
+		query += " AND created_at >= ?"
+		args = append(args, req.From)
+	}
+
+	if req.To != "" {
+		query += " AND created_at <= ?"
+		args = append(args, req.To)
+	}
+
+	return query, args
+}
+
 // Get retrieves a specific expense by ID
 func (h *ExpenseHandler) Get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -454,7 +622,7 @@ func (h *ExpenseHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	query := `
-		SELECT id, recipient_code, recipient_name, amount, currency, category, narration, reference, status, payment_date, notes, goal_id, budget_limit_id, created_at, updated_at
+		SELECT id, recipient_code, recipient_name, amount, currency, category, COALESCE(category_source, ''), narration, reference, status, payment_date, notes, goal_id, budget_limit_id, budget_amount, budget_currency, fx_rate, fx_rate_date, created_at, updated_at
 		FROM expenses
 		WHERE id = ?
 	`
@@ -462,7 +630,9 @@ func (h *ExpenseHandler) Get(w http.ResponseWriter, r *http.Request) {
 	var expense Expense
 	var category, notes sql.NullString
 	var paymentDate sql.NullTime
-	var goalID, budgetLimitID sql.NullInt64
+	var goalID, budgetLimitID, budgetAmount sql.NullInt64
+	var budgetCurrency, fxRateDate sql.NullString
+	var fxRate sql.NullFloat64
 
 	err := database.DB.QueryRow(query, id).Scan(
 		&expense.ID,
@@ -471,6 +641,7 @@ func (h *ExpenseHandler) Get(w http.ResponseWriter, r *http.Request) {
 		&expense.Amount,
 		&expense.Currency,
 		&category,
+		&expense.CategorySource,
 		&expense.Narration,
 		&expense.Reference,
 		&expense.Status,
@@ -478,6 +649,10 @@ func (h *ExpenseHandler) Get(w http.ResponseWriter, r *http.Request) {
 		&notes,
 		&goalID,
 		&budgetLimitID,
+		&budgetAmount,
+		&budgetCurrency,
+		&fxRate,
+		&fxRateDate,
 		&expense.CreatedAt,
 		&expense.UpdatedAt,
 	)
@@ -504,6 +679,7 @@ func (h *ExpenseHandler) Get(w http.ResponseWriter, r *http.Request) {
 		bid := int(budgetLimitID.Int64)
 		expense.BudgetLimitID = &bid
 	}
+	expense.Conversion = storedConversion(expense.Money, budgetAmount, budgetCurrency, fxRate, fxRateDate)
 
 	WriteJSONSuccess(w, expense)
 }
@@ -526,9 +702,15 @@ func (h *ExpenseHandler) Update(w http.ResponseWriter, r *http.Request) {
 	updates := []string{}
 	args := []interface{}{}
 
+	// A category set by hand is canonicalized and never re-categorized automatically
 	if req.Category != "" {
-		updates = append(updates, "category = ?")
-		args = append(args, req.Category)
+		category, err := ResolveCategory(req.Category)
+		if err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+		updates = append(updates, "category = ?", "category_source = ?", "category_rule_id = NULL")
+		args = append(args, category, categorize.SourceManual)
 	}
 
 	if req.Narration != "" {
@@ -577,11 +759,57 @@ func (h *ExpenseHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Status changes post to the ledger (paid, cancelled, refunded...)
+	if expenseID, err := strconv.ParseInt(id, 10, 64); err == nil {
+		postLedger("expense "+id, PostExpenseJournal(expenseID))
+
+		// A payment that won't go out no longer counts against the transfer limits
+		if req.Status == "cancelled" || req.Status == "failed" {
+			if err := releaseExpenseReservation(expenseID); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
+	}
+
 	// Retrieve updated expense
 	h.Get(w, r)
 }
 
 
+// discardExpense removes an expense that could not be fully recorded: its journal
+// entries and lines, its limits reservation and the expense itself, all or nothing.
+// The budget's spent amount is refreshed from what is left in the ledger.
+func discardExpense(expenseID int64, budgetID int, reservationID int64) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to discard expense %d: %w", expenseID, err)
+	}
+	defer tx.Rollback()
+
+	statements := []struct {
+		query string
+		arg   interface{}
+	}{
+		{"DELETE FROM journal_lines WHERE entry_id IN (SELECT id FROM journal_entries WHERE source_type = 'expense' AND source_id = ?)", expenseID},
+		{"DELETE FROM journal_entries WHERE source_type = 'expense' AND source_id = ?", expenseID},
+		{"DELETE FROM outgoing_payments WHERE id = ?", reservationID},
+		{"DELETE FROM expenses WHERE id = ?", expenseID},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt.query, stmt.arg); err != nil {
+			return fmt.Errorf("failed to discard expense %d: %w", expenseID, err)
+		}
+	}
+	if err := refreshBudgetSpent(tx, budgetID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to discard expense %d: %w", expenseID, err)
+	}
+	return nil
+}
+
 // Helper function to join strings
 func joinStrings(strs []string, sep string) string {
 	if len(strs) == 0 {
@@ -593,3 +821,24 @@ func joinStrings(strs []string, sep string) string {
 	}
 	return result
 }
+
+// resolveExpenseRecipient replaces the request's recipient name or alias with its code.
+// It writes the error response and returns false when the name doesn't resolve.
+func resolveExpenseRecipient(w http.ResponseWriter, req *CreateExpenseRequest) bool {
+	code, resolution, err := resolveRecipientReference(req.Recipient)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return false
+	}
+	if code == "" {
+		if group, _, err := resolveRecipientGroup(req.Recipient); err == nil && group != nil {
+			WriteJSONBadRequest(w, fmt.Sprintf("%q is a group of %d recipients: pay groups with a bulk transfer", group.Name, len(group.Members)))
+			return false
+		}
+		writeUnresolvedRecipient(w, resolution)
+		return false
+	}
+	req.RecipientCode = code
+	req.Recipient = ""
+	return true
+}
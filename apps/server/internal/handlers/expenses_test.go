@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"paystack.mpc.proxy/internal/database"
+)
+
+// createExpense previews and confirms an expense, returning the confirmation's status and body
+func createExpense(t *testing.T) (int, map[string]interface{}) {
+	t.Helper()
+	h := NewExpenseHandler()
+	code, preview := post(t, h.Create, map[string]interface{}{"recipient_code": defaultRecipientCode, "amount": 500000, "narration": "Rent"})
+	if code != http.StatusOK {
+		t.Fatalf("Expected a preview, got %d: %v", code, preview)
+	}
+	token, _ := preview["data"].(map[string]interface{})["confirmation_token"].(string)
+	return post(t, h.Create, map[string]interface{}{"confirmation_token": token})
+}
+
+func countRows(t *testing.T, table string) int {
+	t.Helper()
+	var n int
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatalf("Failed to count %s: %v", table, err)
+	}
+	return n
+}
+
+func TestExpenseIsUndoneWhenTheLedgerFails(t *testing.T) {
+	setupHandlerDB(t)
+	_, err := database.DB.Exec("CREATE TRIGGER ledger_down BEFORE INSERT ON journal_lines BEGIN SELECT RAISE(ABORT, 'ledger unavailable'); END")
+	if err != nil {
+		t.Fatalf("Failed to break the ledger: %v", err)
+	}
+
+	if code, response := createExpense(t); code != http.StatusInternalServerError {
+		t.Fatalf("Expected the expense to fail, got %d: %v", code, response)
+	}
+	for _, table := range []string{"expenses", "outgoing_payments", "journal_entries", "journal_lines"} {
+		if got := countRows(t, table); got != 0 {
+			t.Errorf("Expected nothing left in %s, got %d rows", table, got)
+		}
+	}
+}
+
+func TestDiscardExpenseRemovesEverythingItWrote(t *testing.T) {
+	setupHandlerDB(t)
+	code, response := createExpense(t)
+	if code != http.StatusOK {
+		t.Fatalf("Expected the expense to be created, got %d: %v", code, response)
+	}
+	expense, _ := response["data"].(map[string]interface{})["expense"].(map[string]interface{})
+	expenseID, _ := expense["id"].(float64)
+	budgetID, _ := expense["budget_limit_id"].(float64)
+	reference, _ := expense["reference"].(string)
+	reservationID, err := reservationByReference(reference)
+	if err != nil || reservationID == 0 {
+		t.Fatalf("Expected a reservation under %s, got %d, %v", reference, reservationID, err)
+	}
+	if countRows(t, "journal_entries") == 0 {
+		t.Fatalf("Expected the expense to be posted to the ledger")
+	}
+
+	if err := discardExpense(int64(expenseID), int(budgetID), reservationID); err != nil {
+		t.Fatalf("Failed to discard expense: %v", err)
+	}
+	for _, table := range []string{"expenses", "outgoing_payments", "journal_entries", "journal_lines"} {
+		if got := countRows(t, table); got != 0 {
+			t.Errorf("Expected nothing left in %s, got %d rows", table, got)
+		}
+	}
+	var spent int64
+	if err := database.DB.QueryRow("SELECT spent_amount FROM budget_limits WHERE id = ?", int(budgetID)).Scan(&spent); err != nil {
+		t.Fatalf("Failed to load budget: %v", err)
+	}
+	if spent != 0 {
+		t.Errorf("Expected the budget to be charged nothing, got %d", spent)
+	}
+}
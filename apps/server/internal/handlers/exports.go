@@ -0,0 +1,523 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Exports Handler - Accounting Exports
+//
+// OBJECTIVES:
+// Expenses, budgets and invoices can only be read a page of JSON at a time, which
+// is no use to an accountant working in a spreadsheet or an accounting package.
+//
+// PURPOSE:
+// - Download expenses with their category, budget and goal links
+// - Download budget period summaries: limit, spent, remaining and usage
+// - Download invoices
+// - Write CSV and XLSX tables, or OFX and QIF bank statements for accounting tools
+// - Select rows with the same filters as the list endpoints
+//
+// KEY WORKFLOW:
+// Parse Format and Filters → Query → Set Download Headers → Stream Each Row Into
+// the Writer → Finish File
+//
+// DESIGN DECISIONS:
+// - Filters are shared with the list endpoints (expenseFilters, invoiceFilters,
+//   budgetFilters) so an export holds exactly what the list would show
+// - Filters come from the query string on GET or a JSON body on POST; the format
+//   is always ?format=csv|xlsx|ofx|qif
+// - Rows are read and written one at a time; nothing holds the whole result
+// - The query runs before any header is sent, so a bad query is still a JSON
+//   error; once the file has started, an error can only cut it short and is logged
+// - Statements hold money that actually moved: paid expenses (money out, dated by
+//   payment date) and paid invoices (money in), grouped by currency, oldest first
+// - QIF has no currency, so a QIF export spanning currencies is refused up front
+//   rather than failing half way; budgets aren't transactions and have no statement form
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/export"
+	"paystack.mpc.proxy/internal/money"
+)
+
+type ExportHandler struct{}
+
+func NewExportHandler() *ExportHandler {
+	return &ExportHandler{}
+}
+
+// exportSource is one query streamed into a file
+type exportSource struct {
+	// name titles the file and worksheet
+	name    string
+	columns []string
+	query   string
+	args    []interface{}
+	// row reads the current row as sheet cells and as a statement transaction
+	row func(rows *sql.Rows) ([]interface{}, export.Transaction, error)
+}
+
+// Expenses downloads expenses. Filters match /expenses/list.
+func (h *ExportHandler) Expenses(w http.ResponseWriter, r *http.Request) {
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	var req ListExpensesRequest
+	err = decodeExportFilters(r, &req, func(q url.Values) error {
+		req.RecipientCode = q.Get("recipient_code")
+		req.Category = q.Get("category")
+		req.Status = q.Get("status")
+		req.Currency = q.Get("currency")
+		req.From = q.Get("from")
+		req.To = q.Get("to")
+		req.Count, req.Offset, err = exportPage(q)
+		return err
+	})
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	filters, args := expenseFilters(req)
+	order := " ORDER BY e.created_at DESC, e.id DESC"
+	if export.IsStatement(format) {
+		// Only paid expenses left the account
+		filters += " AND status = 'paid'"
+		order = " ORDER BY COALESCE(e.currency, 'NGN'), e.created_at, e.id"
+	}
+	selected := "SELECT * FROM expenses WHERE 1=1" + filters + " ORDER BY created_at DESC"
+	if req.Count > 0 {
+		selected += " LIMIT ? OFFSET ?"
+		args = append(args, req.Count, req.Offset)
+	}
+
+	src := exportSource{
+		name: "expenses",
+		columns: []string{
+			"id", "reference", "created_at", "payment_date", "status",
+			"recipient_code", "recipient_name", "narration",
+			"category", "parent_category", "category_source",
+			"amount", "currency", "budget_amount", "budget_currency", "fx_rate",
+			"budget_limit_id", "budget_name", "goal_id", "goal_title",
+			"notes", "import_id",
+		},
+		query: `
+			SELECT e.id, COALESCE(e.reference, ''), e.created_at, e.payment_date, COALESCE(e.status, ''),
+			       e.recipient_code, e.recipient_name, COALESCE(e.narration, ''),
+			       COALESCE(e.category, ''), COALESCE(c.parent_slug, ''), COALESCE(e.category_source, ''),
+			       e.amount, e.currency, e.budget_amount, e.budget_currency, e.fx_rate,
+			       e.budget_limit_id, COALESCE(b.name, ''), e.goal_id, COALESCE(g.title, ''),
+			       COALESCE(e.notes, ''), e.import_id
+			FROM (` + selected + `) e
+			LEFT JOIN budget_limits b ON b.id = e.budget_limit_id
+			LEFT JOIN goals g ON g.id = e.goal_id
+			LEFT JOIN categories c ON c.slug = e.category` + order,
+		args: args,
+		row:  exportExpenseRow,
+	}
+
+	if format == export.FormatQIF && !singleExportCurrency(w, "expenses", selected, args) {
+		return
+	}
+	opts, err := exportStatementOptions(req.From, req.To)
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	streamExport(w, format, src, opts)
+}
+
+// exportExpenseRow reads one expense; the statement amount is negative, money out
+func exportExpenseRow(rows *sql.Rows) ([]interface{}, export.Transaction, error) {
+	var (
+		id                                                  int
+		reference, status, recipientCode, recipientName     string
+		narration, category, parentCategory, categorySource string
+		budgetName, goalTitle, notes                        string
+		createdAt                                           time.Time
+		paymentDate                                         sql.NullTime
+		amount                                              int64
+		currency                                            sql.NullString
+		budgetAmount, budgetLimitID, goalID, importID       sql.NullInt64
+		budgetCurrency                                      sql.NullString
+		fxRate                                              sql.NullFloat64
+	)
+	err := rows.Scan(
+		&id, &reference, &createdAt, &paymentDate, &status,
+		&recipientCode, &recipientName, &narration,
+		&category, &parentCategory, &categorySource,
+		&amount, &currency, &budgetAmount, &budgetCurrency, &fxRate,
+		&budgetLimitID, &budgetName, &goalID, &goalTitle,
+		&notes, &importID,
+	)
+	if err != nil {
+		return nil, export.Transaction{}, fmt.Errorf("failed to scan expense: %w", err)
+	}
+
+	spent := money.New(amount, money.Currency(currency.String).OrDefault())
+	var converted interface{}
+	if budgetAmount.Valid {
+		converted = money.New(budgetAmount.Int64, money.Currency(budgetCurrency.String).OrDefault())
+	}
+	var paidAt *time.Time
+	if paymentDate.Valid {
+		paidAt = &paymentDate.Time
+	}
+
+	cells := []interface{}{
+		id, reference, createdAt, paidAt, status,
+		recipientCode, recipientName, narration,
+		category, parentCategory, categorySource,
+		spent, string(spent.Currency), converted, nullString(budgetCurrency), nullFloat(fxRate),
+		nullInt(budgetLimitID), budgetName, nullInt(goalID), goalTitle,
+		notes, nullInt(importID),
+	}
+
+	date := createdAt
+	if paidAt != nil {
+		date = *paidAt
+	}
+	tx := export.Transaction{
+		ID:       reference,
+		Amount:   money.New(-amount, spent.Currency),
+		Date:     date,
+		Payee:    recipientName,
+		Memo:     narration,
+		Category: category,
+	}
+	if tx.ID == "" {
+		tx.ID = fmt.Sprintf("EXPENSE_%d", id)
+	}
+	return cells, tx, nil
+}
+
+// Budgets downloads budget period summaries. Filters match /budgets/list.
+// Budgets aren't transactions, so only CSV and XLSX are offered.
+func (h *ExportHandler) Budgets(w http.ResponseWriter, r *http.Request) {
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	if export.IsStatement(format) {
+		WriteJSONBadRequest(w, "budgets export as csv or xlsx")
+		return
+	}
+
+	var req ListBudgetLimitsRequest
+	err = decodeExportFilters(r, &req, func(q url.Values) error {
+		req.LimitType = q.Get("limit_type")
+		req.Status = q.Get("status")
+		req.Active = q.Get("active") == "true"
+		req.Count, req.Offset, err = exportPage(q)
+		return err
+	})
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	filters, args := budgetFilters(req)
+	query := `
+		SELECT b.id, b.name, b.limit_type, b.period_start, b.period_end, b.amount, COALESCE(b.currency, 'NGN'),
+		       COALESCE(b.spent_amount, 0), COALESCE(b.status, ''), COALESCE(b.alert_threshold, 0),
+		       (SELECT COUNT(*) FROM expenses x WHERE x.budget_limit_id = b.id AND x.status NOT IN (` + inPlaceholders(len(reversedExpenseStatuses)) + `))
+		FROM budget_limits b WHERE 1=1` + filters + " ORDER BY period_start DESC"
+	args = append(sortedKeys(reversedExpenseStatuses), args...)
+	if req.Count > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, req.Count, req.Offset)
+	}
+
+	src := exportSource{
+		name: "budgets",
+		columns: []string{
+			"id", "name", "limit_type", "period_start", "period_end",
+			"amount", "spent", "remaining", "usage_percent", "currency",
+			"status", "alert_threshold", "expense_count",
+		},
+		query: query,
+		args:  args,
+		row:   exportBudgetRow,
+	}
+	streamExport(w, format, src, export.StatementOptions{})
+}
+
+// exportBudgetRow reads one budget period
+func exportBudgetRow(rows *sql.Rows) ([]interface{}, export.Transaction, error) {
+	var (
+		id, alertThreshold, expenseCount int
+		name, limitType, status          string
+		periodStart, periodEnd           time.Time
+		amount, spent                    int64
+		currency                         string
+	)
+	err := rows.Scan(&id, &name, &limitType, &periodStart, &periodEnd, &amount, &currency,
+		&spent, &status, &alertThreshold, &expenseCount)
+	if err != nil {
+		return nil, export.Transaction{}, fmt.Errorf("failed to scan budget limit: %w", err)
+	}
+
+	code := money.Currency(currency).OrDefault()
+	usage := 0.0
+	if amount > 0 {
+		usage = float64(spent) / float64(amount) * 100
+	}
+	cells := []interface{}{
+		id, name, limitType, periodStart, periodEnd,
+		money.New(amount, code), money.New(spent, code), money.New(amount-spent, code),
+		float64(int64(usage*100+0.5)) / 100, string(code),
+		status, alertThreshold, expenseCount,
+	}
+	return cells, export.Transaction{}, nil
+}
+
+// Invoices downloads invoices. Filters match /invoices/list, except that
+// customer_id is optional here.
+func (h *ExportHandler) Invoices(w http.ResponseWriter, r *http.Request) {
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	var req ListInvoicesRequest
+	err = decodeExportFilters(r, &req, func(q url.Values) error {
+		req.CustomerID = q.Get("customer_id")
+		req.Status = q.Get("status")
+		req.Currency = q.Get("currency")
+		req.From = q.Get("from")
+		req.To = q.Get("to")
+		req.Count, req.Offset, err = exportPage(q)
+		return err
+	})
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	filters, args := invoiceFilters(req)
+	order := " ORDER BY created_at DESC, id DESC"
+	if export.IsStatement(format) {
+		// Only paid invoices brought money in
+		filters += " AND status IN (" + inPlaceholders(len(paidInvoiceStatuses)) + ")"
+		args = append(args, sortedKeys(paidInvoiceStatuses)...)
+		order = " ORDER BY currency, created_at, id"
+	}
+	selected := "SELECT id, invoice_code, customer_id, customer_name, amount, COALESCE(currency, 'NGN') AS currency, status, created_at, updated_at FROM invoices WHERE 1=1" + filters + " ORDER BY created_at DESC"
+	if req.Count > 0 {
+		selected += " LIMIT ? OFFSET ?"
+		args = append(args, req.Count, req.Offset)
+	}
+
+	src := exportSource{
+		name: "invoices",
+		columns: []string{
+			"id", "invoice_code", "customer_id", "customer_name",
+			"amount", "currency", "status", "created_at", "updated_at",
+		},
+		query: "SELECT * FROM (" + selected + ")" + order,
+		args:  args,
+		row:   exportInvoiceRow,
+	}
+
+	if format == export.FormatQIF && !singleExportCurrency(w, "invoices", selected, args) {
+		return
+	}
+	opts, err := exportStatementOptions(req.From, req.To)
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	streamExport(w, format, src, opts)
+}
+
+// exportInvoiceRow reads one invoice; the statement amount is positive, money in
+func exportInvoiceRow(rows *sql.Rows) ([]interface{}, export.Transaction, error) {
+	var (
+		id                                       int
+		code, customerID, customerName, currency string
+		status                                   sql.NullString
+		amount                                   int64
+		createdAt, updatedAt                     time.Time
+	)
+	err := rows.Scan(&id, &code, &customerID, &customerName, &amount, &currency, &status, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, export.Transaction{}, fmt.Errorf("failed to scan invoice: %w", err)
+	}
+
+	billed := money.New(amount, money.Currency(currency).OrDefault())
+	cells := []interface{}{
+		id, code, customerID, customerName,
+		billed, string(billed.Currency), status.String, createdAt, updatedAt,
+	}
+	tx := export.Transaction{
+		ID:     code,
+		Amount: billed,
+		// Invoices don't record when they were paid; updated_at is when the status last changed
+		Date:  updatedAt,
+		Payee: customerName,
+		Memo:  "Invoice " + code,
+	}
+	return cells, tx, nil
+}
+
+// streamExport runs the source's query and writes its rows to the response as they are read
+func streamExport(w http.ResponseWriter, format string, src exportSource, opts export.StatementOptions) {
+	rows, err := database.DB.Query(src.query, src.args...)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to query %s: %w", src.name, err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	var (
+		sheet     export.Sheet
+		statement export.Statement
+	)
+	if export.IsStatement(format) {
+		statement, err = export.NewStatement(w, format, opts)
+	} else {
+		sheet, err = export.NewSheet(w, format, strings.ToUpper(src.name[:1])+src.name[1:])
+	}
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to start %s export: %w", src.name, err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename(src.name, format, time.Now())))
+
+	if sheet != nil {
+		err = sheet.WriteHeader(src.columns...)
+	}
+	count := 0
+	for err == nil && rows.Next() {
+		cells, tx, scanErr := src.row(rows)
+		if scanErr != nil {
+			err = scanErr
+			break
+		}
+		if sheet != nil {
+			err = sheet.WriteRow(cells...)
+		} else {
+			err = statement.WriteTransaction(tx)
+		}
+		count++
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	// The headers are gone, so a failure can only end the file early
+	if err != nil {
+		fmt.Printf("Warning: %s export stopped after %d rows: %v\n", src.name, count, err)
+	}
+
+	if sheet != nil {
+		err = sheet.Close()
+	} else {
+		err = statement.Close()
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to finish %s export: %v\n", src.name, err)
+	}
+}
+
+// decodeExportFilters reads filters from a JSON body on POST, or from the query string
+func decodeExportFilters(r *http.Request, req interface{}, fromQuery func(url.Values) error) error {
+	if r.Method == http.MethodPost && r.Body != http.NoBody {
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			return errors.New("Invalid request body")
+		}
+		return nil
+	}
+	return fromQuery(r.URL.Query())
+}
+
+// exportPage reads ?count= and ?offset=
+func exportPage(q url.Values) (count, offset int, err error) {
+	for name, value := range map[string]*int{"count": &count, "offset": &offset} {
+		if text := q.Get(name); text != "" {
+			if *value, err = strconv.Atoi(text); err != nil || *value < 0 {
+				return 0, 0, fmt.Errorf("%s must be a non-negative integer", name)
+			}
+		}
+	}
+	return count, offset, nil
+}
+
+// exportStatementOptions sets a statement's period from the from and to filters.
+// Either may be empty; the writer falls back to the first transaction and now.
+func exportStatementOptions(from, to string) (export.StatementOptions, error) {
+	var opts export.StatementOptions
+	var err error
+	if from != "" {
+		if opts.Start, err = parseRangeTime(from, false); err != nil {
+			return opts, err
+		}
+	}
+	if to != "" {
+		if opts.End, err = parseRangeTime(to, false); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// singleExportCurrency refuses a QIF export whose rows span currencies.
+// selected is the query choosing the rows; it must have a currency column.
+func singleExportCurrency(w http.ResponseWriter, name, selected string, args []interface{}) bool {
+	var currencies int
+	err := database.DB.QueryRow("SELECT COUNT(DISTINCT COALESCE(currency, 'NGN')) FROM ("+selected+")", args...).Scan(&currencies)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to count %s currencies: %w", name, err), http.StatusInternalServerError)
+		return false
+	}
+	if currencies > 1 {
+		WriteJSONBadRequest(w, fmt.Sprintf("these %s are in %d currencies and QIF has none; filter with currency= or use ofx", name, currencies))
+		return false
+	}
+	return true
+}
+
+// sortedKeys returns a status set as query args, in a stable order
+func sortedKeys(set map[string]bool) []interface{} {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return stringArgs(keys)
+}
+
+func nullString(v sql.NullString) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	return v.String
+}
+
+func nullInt(v sql.NullInt64) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	return v.Int64
+}
+
+func nullFloat(v sql.NullFloat64) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	return v.Float64
+}
@@ -0,0 +1,424 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Forecast Handler - Cashflow Forecasting
+//
+// OBJECTIVES:
+// Budgets, invoices and schedules each say something about the weeks ahead, but
+// nothing adds them up into "what will the balance be, and when does it run short".
+//
+// PURPOSE:
+// - Project the balance for each of the next N days from today's Paystack balance
+// - Add unpaid invoices on their due dates
+// - Subtract scheduled transfers, recurring schedule runs and pending expenses
+// - Subtract what is left in active budgets, spread over the rest of each period
+// - Flag the days the balance goes negative or below a floor
+//
+// KEY WORKFLOW:
+// Read Balance → Gather Inflows and Outflows → Convert to the Balance Currency →
+// Project Day by Day → Flag Breaches
+//
+// DESIGN DECISIONS:
+// - The projection itself lives in internal/forecast; this file only gathers flows
+// - Everything is projected in the balance's currency; other currencies are converted
+//   at today's rate and listed as excluded when there is none
+// - Overdue invoices and late payments count on the first day rather than being dropped
+// - A budget's unspent amount leaves out runs of schedules already charged to it, so
+//   the same rent isn't subtracted twice
+// - Invoices without a due date can't be placed and are listed as excluded
+// - The floor comes from FORECAST_BALANCE_FLOOR unless ?floor= is given
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/forecast"
+	"paystack.mpc.proxy/internal/money"
+	"paystack.mpc.proxy/internal/paystack"
+)
+
+const (
+	defaultForecastDays = 30
+	maxForecastDays     = 365
+	// maxForecastRuns caps the runs taken from one schedule (an hourly cron over a year)
+	maxForecastRuns = 1000
+)
+
+// Kinds of forecast flow
+const (
+	flowInvoice           = "invoice"
+	flowScheduledTransfer = "scheduled_transfer"
+	flowSchedule          = "schedule"
+	flowPendingExpense    = "pending_expense"
+	flowBudget            = "budget"
+)
+
+// forecastFloor is the balance below which forecast days are flagged by default
+var forecastFloor int64
+
+// ConfigureForecast sets the default floor, in minor units of the balance currency
+func ConfigureForecast(floor int64) {
+	forecastFloor = floor
+}
+
+type ForecastHandler struct {
+	client *paystack.Client
+}
+
+func NewForecastHandler(client *paystack.Client) *ForecastHandler {
+	return &ForecastHandler{client: client}
+}
+
+// CashflowForecast is a projected daily balance in one currency
+type CashflowForecast struct {
+	Currency money.Currency `json:"currency"`
+	forecast.Forecast
+	// Excluded lists flows that couldn't be placed or converted
+	Excluded []ExcludedFlow `json:"excluded"`
+}
+
+// ExcludedFlow is a flow left out of the forecast, and why
+type ExcludedFlow struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	money.Money
+	Reason string `json:"reason"`
+}
+
+// forecastWindow is what every flow source needs to know
+type forecastWindow struct {
+	currency money.Currency
+	start    time.Time // midnight UTC today
+	end      time.Time // midnight UTC after the last day
+	now      time.Time
+	excluded []ExcludedFlow
+}
+
+// convert brings an amount into the forecast currency at today's rate, or
+// records it as excluded and returns false
+func (fw *forecastWindow) convert(kind, description string, amount money.Money) (int64, bool) {
+	converted, _, err := ConvertMoney(amount, fw.currency, fw.now)
+	if err != nil {
+		fw.excluded = append(fw.excluded, ExcludedFlow{Kind: kind, Description: description, Money: amount, Reason: err.Error()})
+		return 0, false
+	}
+	return int64(converted.Amount), true
+}
+
+// Get projects the daily balance. Query: days (default 30), floor (minor units),
+// and opening with currency to project from a given balance instead of Paystack's.
+func (h *ForecastHandler) Get(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	days := defaultForecastDays
+	if v := query.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxForecastDays {
+			WriteJSONBadRequest(w, fmt.Sprintf("days must be between 1 and %d", maxForecastDays))
+			return
+		}
+		days = n
+	}
+	floor := forecastFloor
+	if v := query.Get("floor"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			WriteJSONBadRequest(w, "floor must be a whole number of minor units")
+			return
+		}
+		floor = n
+	}
+
+	var opening int64
+	var currency money.Currency
+	if v := query.Get("opening"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			WriteJSONBadRequest(w, "opening must be a whole number of minor units")
+			return
+		}
+		currency = money.Currency(strings.ToUpper(query.Get("currency"))).OrDefault()
+		if !currency.Valid() {
+			WriteJSONBadRequest(w, fmt.Sprintf("unsupported currency %q", query.Get("currency")))
+			return
+		}
+		opening = n
+	} else {
+		balance, err := h.client.SafeCheckBalance()
+		if err != nil || balance == nil {
+			WriteJSONError(w, fmt.Errorf("failed to check balance: %v", err), http.StatusBadGateway)
+			return
+		}
+		minor, _ := balance["balance"].(float64)
+		code, _ := balance["currency"].(string)
+		opening, currency = int64(minor), money.Currency(strings.ToUpper(code)).OrDefault()
+	}
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	fw := &forecastWindow{currency: currency, start: start, end: start.AddDate(0, 0, days), now: now, excluded: []ExcludedFlow{}}
+
+	flows, err := gatherForecastFlows(fw)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	WriteJSONSuccess(w, CashflowForecast{
+		Currency: currency,
+		Forecast: forecast.Project(opening, flows, forecast.Options{Start: start, Days: days, Floor: floor}),
+		Excluded: fw.excluded,
+	})
+}
+
+// gatherForecastFlows collects every expected inflow and outflow in the window
+func gatherForecastFlows(fw *forecastWindow) ([]forecast.Flow, error) {
+	var flows []forecast.Flow
+
+	invoices, err := invoiceFlows(fw)
+	if err != nil {
+		return nil, err
+	}
+	flows = append(flows, invoices...)
+
+	transfers, err := scheduledTransferFlows(fw)
+	if err != nil {
+		return nil, err
+	}
+	flows = append(flows, transfers...)
+
+	runs, byBudget, err := scheduleFlows(fw)
+	if err != nil {
+		return nil, err
+	}
+	flows = append(flows, runs...)
+
+	pending, err := pendingExpenseFlows(fw)
+	if err != nil {
+		return nil, err
+	}
+	flows = append(flows, pending...)
+
+	budgets, err := budgetFlows(fw, byBudget)
+	if err != nil {
+		return nil, err
+	}
+	return append(flows, budgets...), nil
+}
+
+// invoiceFlows expects each unpaid invoice to be paid on its due date
+func invoiceFlows(fw *forecastWindow) ([]forecast.Flow, error) {
+	rows, err := database.DB.Query(`
+		SELECT invoice_code, customer_name, amount, COALESCE(currency, 'NGN'), due_date
+		FROM invoices
+		WHERE COALESCE(status, '') NOT IN ('paid', 'success', 'cancelled')
+		  AND (due_date IS NULL OR due_date < ?)
+	`, fw.end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var flows []forecast.Flow
+	for rows.Next() {
+		var code, customer string
+		var amount money.Money
+		var dueDate sql.NullTime
+		if err := rows.Scan(&code, &customer, &amount.Amount, &amount.Currency, &dueDate); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		description := "Invoice from " + customer
+		if !dueDate.Valid {
+			fw.excluded = append(fw.excluded, ExcludedFlow{Kind: flowInvoice, Description: description, Money: amount, Reason: "no due date"})
+			continue
+		}
+		if minor, ok := fw.convert(flowInvoice, description, amount); ok {
+			flows = append(flows, forecast.Flow{Date: dueDate.Time, Amount: minor, Kind: flowInvoice, Description: description, Reference: code})
+		}
+	}
+	return flows, rows.Err()
+}
+
+// scheduledTransferFlows subtracts transfers waiting to be sent
+func scheduledTransferFlows(fw *forecastWindow) ([]forecast.Flow, error) {
+	rows, err := database.DB.Query(`
+		SELECT reference, recipient_name, amount, COALESCE(currency, 'NGN'), execute_at, retry_at
+		FROM scheduled_transfers
+		WHERE status IN (?, ?) AND COALESCE(retry_at, execute_at) < ?
+	`, transferScheduled, transferSending, fw.end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var flows []forecast.Flow
+	for rows.Next() {
+		var reference, recipient string
+		var amount money.Money
+		var due time.Time
+		var retryAt sql.NullTime
+		if err := rows.Scan(&reference, &recipient, &amount.Amount, &amount.Currency, &due, &retryAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled transfer: %w", err)
+		}
+		// SQLite hands COALESCE of two DATETIME columns back as text, so pick the time here
+		if retryAt.Valid {
+			due = retryAt.Time
+		}
+		description := "Transfer to " + recipient
+		if minor, ok := fw.convert(flowScheduledTransfer, description, amount); ok {
+			flows = append(flows, forecast.Flow{Date: due, Amount: -minor, Kind: flowScheduledTransfer, Description: description, Reference: reference})
+		}
+	}
+	return flows, rows.Err()
+}
+
+// scheduledRun is a schedule run in the forecast, kept to net it off its budget
+type scheduledRun struct {
+	date   time.Time
+	amount int64
+}
+
+// scheduleFlows subtracts every run of the active schedules in the window. It also
+// returns the runs by the budget they'll be charged to (0 for the default budget).
+func scheduleFlows(fw *forecastWindow) ([]forecast.Flow, map[int][]scheduledRun, error) {
+	rows, err := database.DB.Query("SELECT "+scheduleColumns+" FROM schedules WHERE status = ? AND next_run_at IS NOT NULL AND next_run_at < ?", scheduleActive, fw.end)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	var schedules []*Schedule
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+	rows.Close()
+
+	var flows []forecast.Flow
+	byBudget := map[int][]scheduledRun{}
+	for _, s := range schedules {
+		description := fmt.Sprintf("%s to %s", s.Name, s.RecipientName)
+		minor, ok := fw.convert(flowSchedule, description, s.Money)
+		if !ok {
+			continue
+		}
+		budgetID := 0
+		if s.BudgetLimitID != nil {
+			budgetID = *s.BudgetLimitID
+		}
+
+		// A run waiting on a retry goes at the retry time
+		rule, after, due := s.rule(), *s.NextRunAt, *s.NextRunAt
+		if s.RetryAt != nil {
+			due = *s.RetryAt
+		}
+		for runs := 0; due.Before(fw.end) && runs < maxForecastRuns; runs++ {
+			flows = append(flows, forecast.Flow{Date: due, Amount: -minor, Kind: flowSchedule, Description: description, Reference: fmt.Sprintf("schedule %d", s.ID)})
+			byBudget[budgetID] = append(byBudget[budgetID], scheduledRun{date: due, amount: minor})
+
+			next, ok := rule.Next(after)
+			if !ok {
+				break
+			}
+			due, after = next, next
+		}
+	}
+	return flows, byBudget, nil
+}
+
+// pendingExpenseFlows subtracts expenses recorded but not yet paid, as due now
+func pendingExpenseFlows(fw *forecastWindow) ([]forecast.Flow, error) {
+	rows, err := database.DB.Query(`
+		SELECT COALESCE(reference, ''), recipient_name, amount, COALESCE(currency, 'NGN'), created_at, payment_date
+		FROM expenses
+		WHERE status = 'pending'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var flows []forecast.Flow
+	for rows.Next() {
+		var reference, recipient string
+		var amount money.Money
+		var due time.Time
+		var paymentDate sql.NullTime
+		if err := rows.Scan(&reference, &recipient, &amount.Amount, &amount.Currency, &due, &paymentDate); err != nil {
+			return nil, fmt.Errorf("failed to scan pending expense: %w", err)
+		}
+		if paymentDate.Valid {
+			due = paymentDate.Time
+		}
+		description := "Pending expense to " + recipient
+		if minor, ok := fw.convert(flowPendingExpense, description, amount); ok {
+			flows = append(flows, forecast.Flow{Date: due, Amount: -minor, Kind: flowPendingExpense, Description: description, Reference: reference})
+		}
+	}
+	return flows, rows.Err()
+}
+
+// budgetFlows spreads what is left in each active budget over the rest of its
+// period, less the schedule runs that will be charged to it
+func budgetFlows(fw *forecastWindow, runs map[int][]scheduledRun) ([]forecast.Flow, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, name, limit_type, amount, COALESCE(currency, 'NGN'), period_start, period_end, spent_amount
+		FROM budget_limits
+		WHERE status = 'active' AND period_start < ? AND period_end >= ?
+	`, fw.end, fw.start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var flows []forecast.Flow
+	for rows.Next() {
+		var b BudgetLimit
+		if err := rows.Scan(&b.ID, &b.Name, &b.LimitType, &b.Amount, &b.Currency, &b.PeriodStart, &b.PeriodEnd, &b.SpentAmount); err != nil {
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+		unspent := money.New(int64(b.Amount-b.SpentAmount), b.Currency)
+		if unspent.Amount <= 0 {
+			continue
+		}
+		description := "Unspent budget " + b.Name
+		remaining, ok := fw.convert(flowBudget, description, unspent)
+		if !ok {
+			continue
+		}
+
+		// Schedules without a budget are charged to the default budget of the month they run in
+		from, to := b.PeriodStart.UTC(), b.PeriodEnd.UTC()
+		charged := runs[b.ID]
+		if b.LimitType == "default" {
+			charged = append(charged, runs[0]...)
+		}
+		for _, run := range charged {
+			if !run.date.Before(from) && run.date.Before(to.AddDate(0, 0, 1)) {
+				remaining -= run.amount
+			}
+		}
+		if remaining <= 0 {
+			continue
+		}
+
+		if from.Before(fw.start) {
+			from = fw.start
+		}
+		for i, part := range forecast.Spread(remaining, from, to) {
+			date := from.AddDate(0, 0, i)
+			if !date.Before(fw.end) {
+				break
+			}
+			flows = append(flows, forecast.Flow{Date: date, Amount: -part, Kind: flowBudget, Description: description, Reference: fmt.Sprintf("budget %d", b.ID)})
+		}
+	}
+	return flows, rows.Err()
+}
@@ -0,0 +1,382 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// FX Handler - Exchange Rates
+//
+// OBJECTIVES:
+// Payments can be in a different currency than the budget they count against.
+//
+// PURPOSE:
+// - Store exchange rates loaded by hand or imported from a CSV file
+// - Look up the rate that applied on any past date
+// - Convert amounts into a budget's currency at the expense date
+// - Keep the original amount, the converted amount and the rate used side by side
+//
+// KEY WORKFLOW:
+// Load Rates (API or CSV) → Store Per Pair Per Day → Payment in Another Currency →
+// Look Up Rate On Payment Date → Convert → Check Budget → Store Both Amounts
+//
+// DESIGN DECISIONS:
+// - Lookup and conversion rules live in internal/fx; this file stores and loads rates
+// - A rate applies from its date until a newer one for the pair; loading the same pair and date again replaces it
+// - Rates are never fetched from a provider, so conversions are reproducible from the table
+// - A missing rate refuses the payment rather than guessing
+// - FX_RATES_FILE is imported on startup so a deployment can ship its rates
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/fx"
+	"paystack.mpc.proxy/internal/money"
+)
+
+// maxFXImportSize caps an uploaded rates file
+const maxFXImportSize = 5 << 20
+
+type FXHandler struct{}
+
+func NewFXHandler() *FXHandler {
+	return &FXHandler{}
+}
+
+// FXConversion records an amount converted into another currency
+type FXConversion struct {
+	Original  money.Money `json:"original"`
+	Converted money.Money `json:"converted"`
+	Rate      float64     `json:"rate"`
+	RateDate  string      `json:"rate_date"`
+}
+
+// Describe explains the conversion for people, e.g. "$15 at 1 USD = 1,550 NGN on 2026-01-01"
+func (c *FXConversion) Describe() string {
+	return fmt.Sprintf("%s at 1 %s = %s %s on %s", c.Original.Format(), c.Original.Currency,
+		formatRate(c.Rate), c.Converted.Currency, c.RateDate)
+}
+
+// formatRate renders a rate with thousands separators and up to six decimals
+func formatRate(rate float64) string {
+	s := strconv.FormatFloat(rate, 'f', -1, 64)
+	if strings.Contains(s, ".") && len(s)-strings.Index(s, ".") > 7 {
+		s = strings.TrimSuffix(strings.TrimRight(strconv.FormatFloat(rate, 'f', 6, 64), "0"), ".")
+	}
+	whole, fraction, hasFraction := strings.Cut(s, ".")
+	var grouped strings.Builder
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(d)
+	}
+	if hasFraction {
+		return grouped.String() + "." + fraction
+	}
+	return grouped.String()
+}
+
+// storedConversion rebuilds the conversion saved on an expense, or nil if it had none
+func storedConversion(original money.Money, amount sql.NullInt64, currency sql.NullString, rate sql.NullFloat64, rateDate sql.NullString) *FXConversion {
+	if !amount.Valid || !currency.Valid || money.Currency(currency.String) == original.Currency.OrDefault() {
+		return nil
+	}
+	return &FXConversion{
+		Original:  original,
+		Converted: money.New(amount.Int64, money.Currency(currency.String)),
+		Rate:      rate.Float64,
+		RateDate:  rateDate.String,
+	}
+}
+
+// loadFXTable reads every stored rate into a lookup table
+func loadFXTable() (*fx.Table, error) {
+	rates, err := queryFXRates("")
+	if err != nil {
+		return nil, err
+	}
+	return fx.NewTable(rates), nil
+}
+
+func queryFXRates(where string, args ...interface{}) ([]fx.Rate, error) {
+	rows, err := database.DB.Query("SELECT base, quote, rate, effective_date, COALESCE(source, '') FROM fx_rates"+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []fx.Rate{}
+	for rows.Next() {
+		var r fx.Rate
+		if err := rows.Scan(&r.Base, &r.Quote, &r.Rate, &r.Date, &r.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, r)
+	}
+	return rates, rows.Err()
+}
+
+// ConvertMoney converts an amount into a currency at the rate in effect on a date.
+// Amounts already in that currency come back unchanged with a nil conversion.
+func ConvertMoney(amount money.Money, to money.Currency, on time.Time) (money.Money, *FXConversion, error) {
+	amount.Currency = amount.Currency.OrDefault()
+	if amount.Currency == to.OrDefault() {
+		return amount, nil, nil
+	}
+
+	table, err := loadFXTable()
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+	converted, rate, err := table.Convert(amount, to.OrDefault(), on)
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+	return converted, &FXConversion{Original: amount, Converted: converted, Rate: rate.Rate, RateDate: rate.Date}, nil
+}
+
+// SaveFXRates validates and stores rates in one transaction, replacing any for the same pair and date
+func SaveFXRates(rates []fx.Rate) (int, error) {
+	for i, r := range rates {
+		if err := r.Validate(); err != nil {
+			return 0, fmt.Errorf("rate %d (%s/%s): %w", i+1, r.Base, r.Quote, err)
+		}
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin rates transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, r := range rates {
+		_, err := tx.Exec(`
+			INSERT INTO fx_rates (base, quote, rate, effective_date, source, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(base, quote, effective_date) DO UPDATE SET
+				rate = excluded.rate,
+				source = excluded.source,
+				updated_at = excluded.updated_at
+		`, r.Base, r.Quote, r.Rate, r.Date, r.Source, now, now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save %s/%s rate: %w", r.Base, r.Quote, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit rates: %w", err)
+	}
+	return len(rates), nil
+}
+
+// importFXRates parses CSV rates and stores them, tagging rows without a source
+func importFXRates(r io.Reader, source string) (int, error) {
+	rates, err := fx.ParseCSV(r)
+	if err != nil {
+		return 0, err
+	}
+	for i := range rates {
+		if rates[i].Source == "" {
+			rates[i].Source = source
+		}
+	}
+	return SaveFXRates(rates)
+}
+
+// ImportFXRatesFile loads rates from a CSV file with date, base, quote and rate columns
+func ImportFXRatesFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open rates file: %w", err)
+	}
+	defer f.Close()
+	return importFXRates(f, filepath.Base(path))
+}
+
+// SetFXRatesRequest loads one rate, or several with "rates"
+type SetFXRatesRequest struct {
+	Base   money.Currency `json:"base,omitempty"`
+	Quote  money.Currency `json:"quote,omitempty"`
+	Rate   float64        `json:"rate,omitempty"`
+	Date   string         `json:"date,omitempty"`
+	Source string         `json:"source,omitempty"`
+	Rates  []fx.Rate      `json:"rates,omitempty"`
+}
+
+// SetRates stores manually entered rates. A rate without a date applies from today.
+func (h *FXHandler) SetRates(w http.ResponseWriter, r *http.Request) {
+	var req SetFXRatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	rates := req.Rates
+	if req.Base != "" || req.Quote != "" || req.Rate != 0 {
+		rates = append(rates, fx.Rate{Base: req.Base, Quote: req.Quote, Rate: req.Rate, Date: req.Date, Source: req.Source})
+	}
+	if len(rates) == 0 {
+		WriteJSONBadRequest(w, "base, quote and rate are required")
+		return
+	}
+	today := fx.Day(time.Now())
+	for i := range rates {
+		if rates[i].Date == "" {
+			rates[i].Date = today
+		}
+		if rates[i].Source == "" {
+			rates[i].Source = "manual"
+		}
+	}
+
+	saved, err := SaveFXRates(rates)
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	WriteJSONSuccessWithMessage(w, fmt.Sprintf("Saved %d exchange rates", saved), map[string]interface{}{
+		"saved": saved,
+		"rates": rates,
+	})
+}
+
+// ImportRates stores rates from a CSV request body (?source= names where they came from)
+func (h *FXHandler) ImportRates(w http.ResponseWriter, r *http.Request) {
+	source := r.URL.Query().Get("source")
+	if source == "" {
+		source = "import"
+	}
+
+	saved, err := importFXRates(http.MaxBytesReader(w, r.Body, maxFXImportSize), source)
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	WriteJSONSuccessWithMessage(w, fmt.Sprintf("Imported %d exchange rates", saved), map[string]interface{}{
+		"imported": saved,
+		"source":   source,
+	})
+}
+
+// ListRates lists stored rates, newest first (?base=, ?quote=, ?limit=).
+// With ?date= it returns the single rate in effect for base/quote on that date.
+func (h *FXHandler) ListRates(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var base, quote money.Currency
+	var err error
+	if q.Get("base") != "" {
+		if base, err = money.ParseCurrency(q.Get("base")); err != nil {
+			WriteJSONBadRequest(w, err.Error())
+			return
+		}
+	}
+	if q.Get("quote") != "" {
+		if quote, err = money.ParseCurrency(q.Get("quote")); err != nil {
+			WriteJSONBadRequest(w, err.Error())
+			return
+		}
+	}
+
+	if date := q.Get("date"); date != "" {
+		on, err := time.Parse(fx.DateLayout, date)
+		if err != nil {
+			WriteJSONBadRequest(w, "date must be YYYY-MM-DD")
+			return
+		}
+		if base == "" || quote == "" {
+			WriteJSONBadRequest(w, "base and quote are required with date")
+			return
+		}
+		table, err := loadFXTable()
+		if err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+		rate, err := table.Lookup(base, quote, on)
+		if err != nil {
+			WriteJSONError(w, err, http.StatusNotFound)
+			return
+		}
+		WriteJSONSuccess(w, rate)
+		return
+	}
+
+	where := " WHERE 1=1"
+	var args []interface{}
+	if base != "" {
+		where += " AND base = ?"
+		args = append(args, base)
+	}
+	if quote != "" {
+		where += " AND quote = ?"
+		args = append(args, quote)
+	}
+	limit := 100
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = n
+		}
+	}
+	where += " ORDER BY effective_date DESC, base, quote LIMIT ?"
+	args = append(args, limit)
+
+	rates, err := queryFXRates(where, args...)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccess(w, map[string]interface{}{
+		"rates": rates,
+		"count": len(rates),
+	})
+}
+
+// Convert converts ?amount= minor units from ?from= to ?to= at the rate on ?date= (default today)
+func (h *FXHandler) Convert(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	minor, err := strconv.ParseInt(q.Get("amount"), 10, 64)
+	if err != nil {
+		WriteJSONBadRequest(w, "amount must be a whole number of minor units")
+		return
+	}
+	from, err := money.ParseCurrency(q.Get("from"))
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	to, err := money.ParseCurrency(q.Get("to"))
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	on := time.Now()
+	if date := q.Get("date"); date != "" {
+		if on, err = time.Parse(fx.DateLayout, date); err != nil {
+			WriteJSONBadRequest(w, "date must be YYYY-MM-DD")
+			return
+		}
+	}
+
+	original := money.New(minor, from)
+	converted, conversion, err := ConvertMoney(original, to, on)
+	if errors.Is(err, fx.ErrNoRate) {
+		WriteJSONError(w, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if conversion == nil {
+		conversion = &FXConversion{Original: original, Converted: converted, Rate: 1, RateDate: fx.Day(on)}
+	}
+	WriteJSONSuccessWithMessage(w, conversion.Describe()+" is "+converted.Format(), conversion)
+}
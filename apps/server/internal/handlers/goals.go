@@ -33,6 +33,7 @@ import (
 	"time"
 
 	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -43,7 +44,7 @@ type Goal struct {
 	Title               string    `json:"title"`
 	Description         string    `json:"description,omitempty"`
 	GoalType            string    `json:"goal_type"`
-	TargetAmount        int       `json:"target_amount"`
+	TargetAmount        money.Amount `json:"target_amount"`
 	BudgetLimitID       *int      `json:"budget_limit_id,omitempty"`
 	Frequency           string    `json:"frequency"`
 	StartDate           time.Time `json:"start_date"`
@@ -56,6 +57,9 @@ type Goal struct {
 	Notes               string    `json:"notes,omitempty"`
 	CreatedAt           time.Time `json:"created_at"`
 	UpdatedAt           time.Time `json:"updated_at"`
+
+	// ProgressAmount is the ledger balance of the goal's account
+	ProgressAmount money.Amount `json:"progress_amount"`
 }
 
 // GoalHandler handles goal-related requests
@@ -71,7 +75,7 @@ type CreateGoalRequest struct {
 	Title         string    `json:"title"`
 	Description   string    `json:"description,omitempty"`
 	GoalType      string    `json:"goal_type"`
-	TargetAmount  int       `json:"target_amount"`
+	TargetAmount  money.Amount `json:"target_amount"`
 	BudgetLimitID *int      `json:"budget_limit_id,omitempty"`
 	Frequency     string    `json:"frequency"`
 	StartDate     time.Time `json:"start_date"`
@@ -85,7 +89,7 @@ type CreateGoalRequest struct {
 type UpdateGoalRequest struct {
 	Title         *string    `json:"title,omitempty"`
 	Description   *string    `json:"description,omitempty"`
-	TargetAmount  *int       `json:"target_amount,omitempty"`
+	TargetAmount  *money.Amount `json:"target_amount,omitempty"`
 	BudgetLimitID *int       `json:"budget_limit_id,omitempty"`
 	EndDate       *time.Time `json:"end_date,omitempty"`
 	Status        *string    `json:"status,omitempty"`
@@ -167,7 +171,7 @@ func (h *GoalHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	// If budget_limit_id is provided, validate it exists and can afford the goal
 	if req.BudgetLimitID != nil && *req.BudgetLimitID > 0 {
-		checkResp, err := CheckBudgetAffordability(*req.BudgetLimitID, req.TargetAmount)
+		checkResp, err := checkGoalAffordability(*req.BudgetLimitID, req.TargetAmount)
 		if err != nil {
 			WriteJSONError(w, fmt.Errorf("error checking budget: %w", err), http.StatusBadRequest)
 			return
@@ -260,7 +264,8 @@ func (h *GoalHandler) List(w http.ResponseWriter, r *http.Request) {
 	query := `
 		SELECT id, title, description, goal_type, target_amount, budget_limit_id,
 		       frequency, start_date, end_date, status, achieved_at, achieved_by_expense_id,
-		       category, priority, notes, created_at, updated_at
+		       category, priority, notes, created_at, updated_at,
+		       COALESCE((SELECT progress_amount FROM goal_ledger_progress WHERE goal_id = goals.id), 0)
 		FROM goals
 		WHERE 1=1
 	`
@@ -353,6 +358,7 @@ func (h *GoalHandler) List(w http.ResponseWriter, r *http.Request) {
 			&goal.Notes,
 			&goal.CreatedAt,
 			&goal.UpdatedAt,
+			&goal.ProgressAmount,
 		)
 		if err != nil {
 			WriteJSONError(w, fmt.Errorf("LFailed to scan goal: : %w", err), http.StatusInternalServerError)
@@ -456,7 +462,7 @@ func (h *GoalHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 		// If budget is linked, check if new target amount is affordable
 		if existingGoal.BudgetLimitID != nil && *existingGoal.BudgetLimitID > 0 {
-			checkResp, err := CheckBudgetAffordability(*existingGoal.BudgetLimitID, *req.TargetAmount)
+			checkResp, err := checkGoalAffordability(*existingGoal.BudgetLimitID, *req.TargetAmount)
 			if err != nil {
 				WriteJSONBadRequest(w, "Error checking budget: "+err.Error())
 				return
@@ -489,7 +495,7 @@ func (h *GoalHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.BudgetLimitID != nil {
 		// Validate budget exists and can afford the goal
 		if *req.BudgetLimitID > 0 {
-			checkResp, err := CheckBudgetAffordability(*req.BudgetLimitID, existingGoal.TargetAmount)
+			checkResp, err := checkGoalAffordability(*req.BudgetLimitID, existingGoal.TargetAmount)
 			if err != nil {
 				WriteJSONBadRequest(w, "Error checking budget: "+err.Error())
 				return
@@ -655,7 +661,8 @@ func getGoalByID(id int) (*Goal, error) {
 	query := `
 		SELECT id, title, description, goal_type, target_amount, budget_limit_id,
 		       frequency, start_date, end_date, status, achieved_at, achieved_by_expense_id,
-		       category, priority, notes, created_at, updated_at
+		       category, priority, notes, created_at, updated_at,
+		       COALESCE((SELECT progress_amount FROM goal_ledger_progress WHERE goal_id = goals.id), 0)
 		FROM goals
 		WHERE id = ?
 	`
@@ -679,6 +686,7 @@ func getGoalByID(id int) (*Goal, error) {
 		&goal.Notes,
 		&goal.CreatedAt,
 		&goal.UpdatedAt,
+		&goal.ProgressAmount,
 	)
 
 	if err != nil {
@@ -687,3 +695,13 @@ func getGoalByID(id int) (*Goal, error) {
 
 	return &goal, nil
 }
+
+// checkGoalAffordability checks a goal target against its budget. Goals carry
+// no currency of their own; the target is in the linked budget's currency.
+func checkGoalAffordability(budgetID int, target money.Amount) (*CheckLimitResponse, error) {
+	currency, err := BudgetCurrency(budgetID)
+	if err != nil {
+		return nil, err
+	}
+	return CheckBudgetAffordability(budgetID, money.New(int64(target), currency))
+}
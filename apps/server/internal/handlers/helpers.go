@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"paystack.mpc.proxy/internal/anomaly"
 	"paystack.mpc.proxy/internal/dto"
 )
 
@@ -33,6 +34,22 @@ func WriteJSONSuccessWithMessage(w http.ResponseWriter, message string, data int
 	json.NewEncoder(w).Encode(response)
 }
 
+// WriteJSONSuccessWithWarnings writes a successful JSON response with any anomaly warnings alongside the data
+func WriteJSONSuccessWithWarnings(w http.ResponseWriter, data interface{}, warnings []anomaly.Warning) {
+	response := dto.Response{
+		Status:  true,
+		Message: "Success",
+		Data:    data,
+	}
+	if len(warnings) > 0 {
+		response.Warnings = warnings
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
 // WriteJSONError writes an error JSON response
 func WriteJSONError(w http.ResponseWriter, err error, statusCode int) {
 	errResponse := dto.ErrorResponse{
@@ -0,0 +1,676 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Imports Handler - Bank Statement Import
+//
+// OBJECTIVES:
+// Expenses are created one at a time and only for cached recipients, so spending
+// that happened outside Paystack (POS, bank app transfers, direct debits) never
+// reaches budgets or reports.
+//
+// PURPOSE:
+// - Turn CSV, OFX and Nigerian bank statement exports into paid expenses in bulk
+// - Map each bank's columns through profiles, built in or saved by the user
+// - Skip lines that were already recorded, by date, amount and reference
+// - Categorize and count every imported expense against a budget
+// - Preview what an import would create, and which budgets it would push over
+//
+// KEY WORKFLOW:
+// Upload Statement → Parse With Profile → Skip Credits and Duplicates →
+// Convert Into Budget Currency → Categorize → Preview (dry run) or Create Paid
+// Expenses → Post to Ledger
+//
+// DESIGN DECISIONS:
+// - Parsing lives in internal/statement; this file decides what each line becomes
+// - Imported expenses are already paid, from the bank account rather than Paystack,
+//   so they skip confirmation tokens and transfer limits and are left out of
+//   Paystack reconciliation
+// - Each line's reference is a key derived from its contents, so re-importing a
+//   statement finds its own expenses; the bank's reference is kept separately
+// - Same day and amount without a matching reference is only a possible duplicate:
+//   skipped by default, since the line may be an expense already entered by hand
+// - Lines over budget are still imported unless asked otherwise; the money is already spent
+// - Credits are listed in the preview but never become expenses
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/categorize"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+	"paystack.mpc.proxy/internal/statement"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxStatementImportSize caps an uploaded statement
+const maxStatementImportSize = 10 << 20
+
+// ErrInvalidStatement is returned when a statement can't be read or its options are wrong
+var ErrInvalidStatement = errors.New("invalid statement")
+
+// What happened to each statement line
+const (
+	importLineCreate            = "create" // dry run only
+	importLineCreated           = "created"
+	importLineDuplicate         = "duplicate"
+	importLinePossibleDuplicate = "possible_duplicate"
+	importLineCredit            = "credit"
+	importLineOverBudget        = "over_budget"
+	importLineFailed            = "failed"
+)
+
+type ImportHandler struct{}
+
+func NewImportHandler() *ImportHandler {
+	return &ImportHandler{}
+}
+
+// StatementImportOptions controls how a statement is imported
+type StatementImportOptions struct {
+	Filename string `json:"filename,omitempty"`
+	// Format is csv or ofx; empty detects it from the contents
+	Format string `json:"format,omitempty"`
+	// Profile names the CSV profile; empty detects it from the header row
+	Profile string `json:"profile,omitempty"`
+	// Currency applies to lines that don't state one
+	Currency      money.Currency `json:"currency,omitempty"`
+	BudgetLimitID *int           `json:"budget_limit_id,omitempty"`
+	DryRun        bool           `json:"dry_run"`
+	// AllowPossibleDuplicates imports lines that only match an expense by day and amount
+	AllowPossibleDuplicates bool `json:"allow_possible_duplicates,omitempty"`
+	// SkipOverBudget leaves out lines that would take the budget past its limit
+	SkipOverBudget bool `json:"skip_over_budget,omitempty"`
+}
+
+// StatementImportLine is one statement line and what the import did with it
+type StatementImportLine struct {
+	statement.Line
+	Key            string        `json:"key"`
+	Status         string        `json:"status"`
+	Reason         string        `json:"reason,omitempty"`
+	ExpenseID      int64         `json:"expense_id,omitempty"`
+	DuplicateOf    int64         `json:"duplicate_of,omitempty"`
+	RecipientCode  string        `json:"recipient_code,omitempty"`
+	Category       string        `json:"category,omitempty"`
+	CategorySource string        `json:"category_source,omitempty"`
+	BudgetAmount   money.Amount  `json:"budget_amount,omitempty"`
+	OverBudget     bool          `json:"over_budget,omitempty"`
+	Conversion     *FXConversion `json:"conversion,omitempty"`
+}
+
+// StatementImportBudget is the budget an import counts against, before and after
+type StatementImportBudget struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	money.Money
+	SpentBefore  money.Amount `json:"spent_before"`
+	SpentAfter   money.Amount `json:"spent_after"`
+	Remaining    money.Amount `json:"remaining"`
+	OverBudget   bool         `json:"over_budget"`
+	ExcessAmount money.Amount `json:"excess_amount,omitempty"`
+}
+
+// StatementImportResult summarizes an import or its preview
+type StatementImportResult struct {
+	ImportID           int64                  `json:"import_id,omitempty"`
+	DryRun             bool                   `json:"dry_run"`
+	Filename           string                 `json:"filename,omitempty"`
+	Format             string                 `json:"format"`
+	Profile            string                 `json:"profile,omitempty"`
+	Lines              int                    `json:"lines"`
+	Created            int                    `json:"created"`
+	Duplicates         int                    `json:"duplicates"`
+	PossibleDuplicates int                    `json:"possible_duplicates"`
+	Credits            int                    `json:"credits"`
+	OverBudget         int                    `json:"over_budget"`
+	Failed             int                    `json:"failed"`
+	Total              money.Money            `json:"total"`
+	Budget             *StatementImportBudget `json:"budget"`
+	Rows               []StatementImportLine  `json:"rows"`
+}
+
+// Skipped counts lines that didn't become expenses for any reason
+func (r *StatementImportResult) Skipped() int {
+	return r.Lines - r.Created
+}
+
+// loadStatementProfiles returns saved profiles followed by the built-in ones,
+// so a saved profile can replace a built-in one of the same name
+func loadStatementProfiles() ([]statement.Profile, error) {
+	rows, err := database.DB.Query("SELECT config FROM statement_profiles ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []statement.Profile
+	for rows.Next() {
+		var config string
+		if err := rows.Scan(&config); err != nil {
+			return nil, fmt.Errorf("failed to scan import profile: %w", err)
+		}
+		var p statement.Profile
+		if err := json.Unmarshal([]byte(config), &p); err != nil {
+			fmt.Printf("Warning: skipping unreadable import profile: %v\n", err)
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return append(profiles, statement.Profiles...), nil
+}
+
+// findStatementDuplicate looks for an expense this line was already recorded as.
+// exact is true when the reference matches; otherwise only the day and amount do.
+func findStatementDuplicate(key string, line statement.Line, claimed map[int64]bool) (id int64, exact bool, err error) {
+	day := line.Date.Format("2006-01-02")
+
+	rows, err := database.DB.Query(`
+		SELECT id, reference = ? OR (COALESCE(statement_reference, '') = ? AND ? != '')
+		FROM expenses
+		WHERE reference = ?
+		   OR (amount = ? AND currency = ? AND date(created_at) = ? AND status NOT IN ('cancelled', 'failed', 'refunded'))
+		ORDER BY 2 DESC, id
+	`, key, line.Reference, line.Reference, key, line.Amount, line.Currency, day)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(&id, &exact); err != nil {
+			return 0, false, fmt.Errorf("failed to scan duplicate: %w", err)
+		}
+		if !claimed[id] {
+			return id, exact, nil
+		}
+	}
+	return 0, false, rows.Err()
+}
+
+// statementRecipient matches a line's counterparty to a cached recipient by name,
+// or makes up a stable code so history and rules can still follow the payee
+func statementRecipient(line statement.Line) (code, name string) {
+	name = line.Counterparty
+	if name == "" {
+		name = line.Description
+	}
+	if line.Counterparty != "" {
+		var cachedCode, cachedName string
+		err := database.DB.QueryRow(
+			"SELECT recipient_code, name FROM recipients WHERE LOWER(name) = LOWER(?) LIMIT 1",
+			line.Counterparty,
+		).Scan(&cachedCode, &cachedName)
+		if err == nil {
+			return cachedCode, cachedName
+		}
+	}
+
+	slug := strings.ToUpper(categorize.Slugify(name))
+	if len(slug) > 40 {
+		slug = strings.TrimSuffix(slug[:40], "_")
+	}
+	if slug == "" {
+		slug = "UNKNOWN"
+	}
+	return "STMT_" + slug, name
+}
+
+// loadImportBudget resolves the budget an import counts against
+func loadImportBudget(budgetLimitID *int) (*StatementImportBudget, error) {
+	budgetID := 0
+	if budgetLimitID != nil && *budgetLimitID > 0 {
+		budgetID = *budgetLimitID
+	} else {
+		defaultBudget, err := FindOrCreateDefaultBudget()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default budget: %w", err)
+		}
+		budgetID = defaultBudget.ID
+	}
+
+	budget := &StatementImportBudget{ID: budgetID}
+	err := database.DB.QueryRow(
+		"SELECT name, amount, currency, spent_amount FROM budget_limits WHERE id = ?", budgetID,
+	).Scan(&budget.Name, &budget.Amount, &budget.Currency, &budget.SpentBefore)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: budget not found: %d", ErrInvalidStatement, budgetID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load budget: %w", err)
+	}
+	budget.Currency = budget.Currency.OrDefault()
+	budget.SpentAfter = budget.SpentBefore
+	return budget, nil
+}
+
+// ImportStatement reads a statement and creates a paid expense for each new debit.
+// With DryRun set nothing is written and the result shows what would happen.
+func ImportStatement(data []byte, opts StatementImportOptions) (*StatementImportResult, error) {
+	if opts.Currency != "" && !opts.Currency.Valid() {
+		return nil, fmt.Errorf("%w: unsupported currency %s", ErrInvalidStatement, opts.Currency)
+	}
+	format := strings.ToLower(strings.TrimSpace(opts.Format))
+	if format == "" {
+		format = statement.DetectFormat(data)
+	}
+
+	profiles, err := loadStatementProfiles()
+	if err != nil {
+		return nil, err
+	}
+	var profile *statement.Profile
+	if opts.Profile != "" {
+		var ok bool
+		if profile, ok = statement.FindProfile(profiles, opts.Profile); !ok {
+			return nil, fmt.Errorf("%w: unknown profile %q", ErrInvalidStatement, opts.Profile)
+		}
+	}
+
+	lines, profile, err := statement.Parse(data, format, profile, profiles, opts.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+	}
+
+	budget, err := loadImportBudget(opts.BudgetLimitID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StatementImportResult{
+		DryRun:   opts.DryRun,
+		Filename: opts.Filename,
+		Format:   format,
+		Lines:    len(lines),
+		Total:    money.New(0, budget.Currency),
+		Budget:   budget,
+		Rows:     make([]StatementImportLine, 0, len(lines)),
+	}
+	if profile != nil {
+		result.Profile = profile.Name
+	}
+
+	// Record the import first so its expenses can point at it
+	if !opts.DryRun {
+		res, err := database.DB.Exec(`
+			INSERT INTO statement_imports (filename, format, profile, currency, budget_limit_id, line_count, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, opts.Filename, format, result.Profile, opts.Currency.OrDefault(), budget.ID, len(lines), time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to record import: %w", err)
+		}
+		result.ImportID, _ = res.LastInsertId()
+	}
+
+	// Expenses matched or created in this import can't be matched again
+	claimed := map[int64]bool{}
+	keys := statement.Keys(lines)
+
+	for i, line := range lines {
+		row := StatementImportLine{Line: line, Key: keys[i]}
+
+		if !line.Debit {
+			row.Status, row.Reason = importLineCredit, "money in is not an expense"
+			result.Credits++
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+
+		duplicateID, exact, err := findStatementDuplicate(row.Key, line, claimed)
+		if err != nil {
+			return nil, err
+		}
+		if duplicateID != 0 && (exact || !opts.AllowPossibleDuplicates) {
+			claimed[duplicateID] = true
+			row.DuplicateOf = duplicateID
+			if exact {
+				row.Status, row.Reason = importLineDuplicate, fmt.Sprintf("already recorded as expense %d", duplicateID)
+				result.Duplicates++
+			} else {
+				row.Status, row.Reason = importLinePossibleDuplicate, fmt.Sprintf("expense %d has the same day and amount", duplicateID)
+				result.PossibleDuplicates++
+			}
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+
+		// Count the line against the budget in the budget's currency, at the rate on the day it happened
+		converted, conversion, err := ConvertMoney(line.Money(), budget.Currency, line.Date)
+		if err != nil {
+			row.Status, row.Reason = importLineFailed, err.Error()
+			result.Failed++
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+		row.BudgetAmount, row.Conversion = converted.Amount, conversion
+		row.OverBudget = budget.SpentAfter+converted.Amount > budget.Amount
+		if row.OverBudget {
+			result.OverBudget++
+			if opts.SkipOverBudget {
+				row.Status, row.Reason = importLineOverBudget, fmt.Sprintf("would exceed budget %s", budget.Name)
+				result.Rows = append(result.Rows, row)
+				continue
+			}
+		}
+
+		recipientCode, recipientName := statementRecipient(line)
+		narration := line.Description
+		if narration == "" {
+			narration = recipientName
+		}
+		row.RecipientCode = recipientCode
+
+		categoryResult, err := CategorizeExpense("", categorize.Expense{
+			RecipientCode: recipientCode,
+			RecipientName: recipientName,
+			Narration:     narration,
+		})
+		if err != nil {
+			fmt.Printf("Warning: failed to categorize imported line %d: %v\n", line.Row, err)
+		}
+		row.Category, row.CategorySource = categoryResult.Category, categoryResult.Source
+
+		if opts.DryRun {
+			row.Status = importLineCreate
+		} else {
+			expenseID, err := createImportedExpense(result.ImportID, opts.Filename, row, recipientName, narration, budget.ID, categoryResult)
+			if err != nil {
+				row.Status, row.Reason = importLineFailed, err.Error()
+				result.Failed++
+				result.Rows = append(result.Rows, row)
+				continue
+			}
+			row.Status, row.ExpenseID = importLineCreated, expenseID
+			claimed[expenseID] = true
+		}
+
+		budget.SpentAfter += converted.Amount
+		result.Total.Amount += converted.Amount
+		result.Created++
+		result.Rows = append(result.Rows, row)
+	}
+
+	budget.Remaining = budget.Amount - budget.SpentAfter
+	if budget.Remaining < 0 {
+		budget.OverBudget, budget.ExcessAmount, budget.Remaining = true, -budget.Remaining, 0
+	}
+
+	if !opts.DryRun {
+		_, err := database.DB.Exec(`
+			UPDATE statement_imports SET created_count = ?, duplicate_count = ?, skipped_count = ? WHERE id = ?
+		`, result.Created, result.Duplicates+result.PossibleDuplicates, result.Skipped(), result.ImportID)
+		if err != nil {
+			fmt.Printf("Warning: Failed to update import %d counts: %v\n", result.ImportID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// createImportedExpense records one statement line as a paid expense and posts it to the ledger
+func createImportedExpense(importID int64, filename string, row StatementImportLine, recipientName, narration string, budgetID int, categoryResult categorize.Result) (int64, error) {
+	var budgetCurrency money.Currency
+	var fxRate, fxRateDate interface{}
+	if row.Conversion != nil {
+		budgetCurrency = row.Conversion.Converted.Currency
+		fxRate, fxRateDate = row.Conversion.Rate, row.Conversion.RateDate
+	} else {
+		budgetCurrency = row.Currency.OrDefault()
+	}
+	category, categorySource, categoryRuleID := categoryColumns(categoryResult)
+
+	source := filename
+	if source == "" {
+		source = "bank statement"
+	}
+	var statementReference interface{}
+	if row.Reference != "" {
+		statementReference = row.Reference
+	}
+
+	res, err := database.DB.Exec(`
+		INSERT INTO expenses (
+			recipient_code, recipient_name, amount, currency, category,
+			narration, reference, status, payment_date, notes, budget_limit_id,
+			budget_amount, budget_currency, fx_rate, fx_rate_date,
+			category_source, category_rule_id, import_id, statement_reference, created_at, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'paid', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		row.RecipientCode, recipientName, row.Amount, row.Currency.OrDefault(), category,
+		narration, row.Key, row.Date, fmt.Sprintf("Imported from %s, row %d", source, row.Row), budgetID,
+		row.BudgetAmount, budgetCurrency, fxRate, fxRateDate,
+		categorySource, categoryRuleID, importID, statementReference, row.Date, time.Now(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create expense: %w", err)
+	}
+	expenseID, _ := res.LastInsertId()
+
+	if err := PostExpenseJournal(expenseID); err != nil {
+		database.DB.Exec("DELETE FROM expenses WHERE id = ?", expenseID)
+		return 0, fmt.Errorf("failed to post expense: %w", err)
+	}
+	return expenseID, nil
+}
+
+// ImportStatementFile imports a statement from disk, naming the import after the file
+func ImportStatementFile(path string, opts StatementImportOptions) (*StatementImportResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read statement: %w", err)
+	}
+	if opts.Filename == "" {
+		opts.Filename = filepath.Base(path)
+	}
+	return ImportStatement(data, opts)
+}
+
+// Import imports a statement sent as the raw request body. Options come from the query:
+// ?format=, ?profile=, ?currency=, ?budget_limit_id=, ?filename=, ?dry_run=true,
+// ?allow_possible_duplicates=true and ?skip_over_budget=true.
+func (h *ImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := StatementImportOptions{
+		Filename:                q.Get("filename"),
+		Format:                  q.Get("format"),
+		Profile:                 q.Get("profile"),
+		DryRun:                  q.Get("dry_run") == "true",
+		AllowPossibleDuplicates: q.Get("allow_possible_duplicates") == "true",
+		SkipOverBudget:          q.Get("skip_over_budget") == "true",
+	}
+	if code := q.Get("currency"); code != "" {
+		currency, err := money.ParseCurrency(code)
+		if err != nil {
+			WriteJSONBadRequest(w, err.Error())
+			return
+		}
+		opts.Currency = currency
+	}
+	if id := q.Get("budget_limit_id"); id != "" {
+		budgetID, err := strconv.Atoi(id)
+		if err != nil || budgetID <= 0 {
+			WriteJSONBadRequest(w, "budget_limit_id must be a positive integer")
+			return
+		}
+		opts.BudgetLimitID = &budgetID
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxStatementImportSize))
+	if err != nil {
+		WriteJSONBadRequest(w, fmt.Sprintf("statement must be at most %d MB", maxStatementImportSize>>20))
+		return
+	}
+	if len(data) == 0 {
+		WriteJSONBadRequest(w, "statement is empty")
+		return
+	}
+
+	result, err := ImportStatement(data, opts)
+	if errors.Is(err, ErrInvalidStatement) {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	message := fmt.Sprintf("Imported %d expenses, skipped %d lines", result.Created, result.Skipped())
+	if result.DryRun {
+		message = fmt.Sprintf("Would import %d expenses and skip %d lines", result.Created, result.Skipped())
+	}
+	WriteJSONSuccessWithMessage(w, message, result)
+}
+
+// StatementImport is a past import
+type StatementImport struct {
+	ID            int            `json:"id"`
+	Filename      string         `json:"filename,omitempty"`
+	Format        string         `json:"format"`
+	Profile       string         `json:"profile,omitempty"`
+	Currency      money.Currency `json:"currency"`
+	BudgetLimitID *int           `json:"budget_limit_id,omitempty"`
+	Lines         int            `json:"lines"`
+	Created       int            `json:"created"`
+	Duplicates    int            `json:"duplicates"`
+	Skipped       int            `json:"skipped"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+func queryStatementImports(where string, args ...interface{}) ([]StatementImport, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, COALESCE(filename, ''), format, COALESCE(profile, ''), currency, budget_limit_id,
+		       line_count, created_count, duplicate_count, skipped_count, created_at
+		FROM statement_imports`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query imports: %w", err)
+	}
+	defer rows.Close()
+
+	imports := []StatementImport{}
+	for rows.Next() {
+		var imp StatementImport
+		var budgetID sql.NullInt64
+		if err := rows.Scan(&imp.ID, &imp.Filename, &imp.Format, &imp.Profile, &imp.Currency, &budgetID,
+			&imp.Lines, &imp.Created, &imp.Duplicates, &imp.Skipped, &imp.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan import: %w", err)
+		}
+		if budgetID.Valid {
+			id := int(budgetID.Int64)
+			imp.BudgetLimitID = &id
+		}
+		imports = append(imports, imp)
+	}
+	return imports, rows.Err()
+}
+
+// List lists past imports, newest first
+func (h *ImportHandler) List(w http.ResponseWriter, r *http.Request) {
+	imports, err := queryStatementImports(" ORDER BY id DESC")
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccess(w, imports)
+}
+
+// Get returns an import and the expenses it created
+func (h *ImportHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteJSONBadRequest(w, "id must be an integer")
+		return
+	}
+	imports, err := queryStatementImports(" WHERE id = ?", id)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if len(imports) == 0 {
+		WriteJSONError(w, fmt.Errorf("import not found: %d", id), http.StatusNotFound)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT id, recipient_code, recipient_name, amount, currency, COALESCE(category, ''), COALESCE(category_source, ''),
+		       narration, reference, status, COALESCE(notes, ''), budget_limit_id, created_at, updated_at
+		FROM expenses WHERE import_id = ? ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to query expenses: %w", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	expenses := []Expense{}
+	for rows.Next() {
+		var e Expense
+		var budgetID sql.NullInt64
+		if err := rows.Scan(&e.ID, &e.RecipientCode, &e.RecipientName, &e.Amount, &e.Currency, &e.Category, &e.CategorySource,
+			&e.Narration, &e.Reference, &e.Status, &e.Notes, &budgetID, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to scan expense: %w", err), http.StatusInternalServerError)
+			return
+		}
+		if budgetID.Valid {
+			bid := int(budgetID.Int64)
+			e.BudgetLimitID = &bid
+		}
+		expenses = append(expenses, e)
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"import":   imports[0],
+		"expenses": expenses,
+	})
+}
+
+// ListProfiles lists saved and built-in CSV profiles
+func (h *ImportHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := loadStatementProfiles()
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccess(w, profiles)
+}
+
+// SaveProfile saves a CSV profile, replacing any saved profile with the same name
+func (h *ImportHandler) SaveProfile(w http.ResponseWriter, r *http.Request) {
+	var profile statement.Profile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+	profile.Name = strings.ToLower(strings.TrimSpace(profile.Name))
+	profile.BuiltIn = false
+	if err := profile.Validate(); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	config, _ := json.Marshal(profile)
+	now := time.Now()
+	_, err := database.DB.Exec(`
+		INSERT INTO statement_profiles (name, config, created_at, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET config = excluded.config, updated_at = excluded.updated_at
+	`, profile.Name, string(config), now, now)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to save profile: %w", err), http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, fmt.Sprintf("Saved import profile %s", profile.Name), profile)
+}
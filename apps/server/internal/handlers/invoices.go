@@ -12,7 +12,7 @@
 // - Maintain invoice history for accounting
 //
 // KEY WORKFLOW:
-// Create Invoice → Generate Payment Request → Customer Pays →
+// Create Invoice (preview) → Confirm With Token → Generate Payment Request → Customer Pays →
 // Verify Payment → Update Invoice Status → Record Transaction
 //
 // DESIGN DECISIONS:
@@ -21,15 +21,20 @@
 // - Verification endpoint confirms payment completion
 // - Line items support for detailed invoice breakdown
 // - Local database cache for quick invoice lookups
+// - Payment requests are previewed with a one-time confirmation token before they are sent
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"paystack.mpc.proxy/internal/confirm"
 	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
 	"paystack.mpc.proxy/internal/paystack"
 
 	"github.com/go-chi/chi/v5"
@@ -45,7 +50,7 @@ func NewInvoiceHandler(client *paystack.Client) *InvoiceHandler {
 
 type CreateInvoiceRequest struct {
 	Customer         string                `json:"customer"`
-	Amount           int                   `json:"amount"`
+	money.Money
 	Description      string                `json:"description,omitempty"`
 	LineItems        []paystack.LineItem   `json:"line_items,omitempty"`
 	DueDate          string                `json:"due_date,omitempty"`
@@ -53,12 +58,15 @@ type CreateInvoiceRequest struct {
 	Draft            bool                  `json:"draft,omitempty"`
 	HasInvoice       bool                  `json:"has_invoice,omitempty"`
 	InvoiceNumber    int                   `json:"invoice_number,omitempty"`
-	Currency         string                `json:"currency,omitempty"`
+
+	// ConfirmationToken executes a previously previewed payment request
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
 }
 
 type ListInvoicesRequest struct {
 	CustomerID string `json:"customer_id"`
 	Status     string `json:"status,omitempty"`
+	Currency   string `json:"currency,omitempty"`
 	From       string `json:"from,omitempty"`
 	To         string `json:"to,omitempty"`
 	Count      int    `json:"count,omitempty"`
@@ -70,13 +78,14 @@ type Invoice struct {
 	InvoiceCode  string    `json:"invoice_code"`
 	CustomerID   string    `json:"customer_id"`
 	CustomerName string    `json:"customer_name"`
-	Amount       int       `json:"amount"`
-	Status       string    `json:"status"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	money.Money
+	Status       string     `json:"status"`
+	DueDate      *time.Time `json:"due_date,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
-// Create creates a new invoice
+// Create creates a new invoice once the previewed request is confirmed
 func (h *InvoiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req CreateInvoiceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -84,16 +93,38 @@ func (h *InvoiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A confirmation token replaces the body with the previewed request; nothing else
+	// in the body is kept, so a field can't be added after the preview
+	confirmed := false
+	if req.ConfirmationToken != "" {
+		var previewed CreateInvoiceRequest
+		if !redeemConfirmation(w, req.ConfirmationToken, confirm.ActionInvoice, &previewed) {
+			return
+		}
+		req = previewed
+		confirmed = true
+	}
+
 	// Validate required fields
 	if req.Customer == "" {
 		WriteJSONBadRequest(w, "customer is required")
 		return
 	}
 
-	if req.Amount <= 0 {
-		WriteJSONBadRequest(w, "amount must be greater than 0")
+	req.Currency = req.Currency.OrDefault()
+	if err := req.Validate(); err != nil {
+		WriteJSONBadRequest(w, err.Error())
 		return
 	}
+	var dueDate interface{}
+	if req.DueDate != "" {
+		due, err := parseRangeTime(req.DueDate, false)
+		if err != nil {
+			WriteJSONBadRequest(w, fmt.Sprintf("due_date: %v", err))
+			return
+		}
+		dueDate = due
+	}
 
 	// Verify customer exists in Paystack
 	customer, err := h.client.Customer.Get(req.Customer)
@@ -115,10 +146,19 @@ func (h *InvoiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 		customerName = customer.Email
 	}
 
+	if !confirmed {
+		writeConfirmationPreview(w, confirm.ActionInvoice, req, ConfirmationPreview{
+			Money:         req.Money,
+			RecipientName: customerName,
+			Summary:       fmt.Sprintf("Request %s from %s", req.Format(), customerName),
+		})
+		return
+	}
+
 	// Create payment request in Paystack
 	paymentReq := &paystack.PaymentRequest{
 		Customer:         req.Customer,
-		Amount:           req.Amount,
+		Amount:           int(req.Amount),
 		Description:      req.Description,
 		LineItems:        req.LineItems,
 		DueDate:          req.DueDate,
@@ -126,7 +166,7 @@ func (h *InvoiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Draft:            req.Draft,
 		HasInvoice:       req.HasInvoice,
 		InvoiceNumber:    req.InvoiceNumber,
-		Currency:         req.Currency,
+		Currency:         string(req.Currency),
 	}
 
 	result, err := h.client.CreatePaymentRequest(paymentReq)
@@ -141,11 +181,11 @@ func (h *InvoiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	// Insert into SQLite
 	query := `
-		INSERT INTO invoices (invoice_code, customer_id, customer_name, amount, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO invoices (invoice_code, customer_id, customer_name, amount, currency, status, due_date, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	now := time.Now()
-	_, err = database.DB.Exec(query, requestCode, req.Customer, customerName, req.Amount, status, now, now)
+	_, err = database.DB.Exec(query, requestCode, req.Customer, customerName, req.Amount, req.Currency, status, dueDate, now, now)
 	if err != nil {
 		// Log the error but still return the Paystack response
 		fmt.Printf("Warning: Failed to cache invoice in database: %v\n", err)
@@ -170,24 +210,8 @@ func (h *InvoiceHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Build query with filters
-	query := `SELECT id, invoice_code, customer_id, customer_name, amount, status, created_at, updated_at FROM invoices WHERE customer_id = ?`
-	args := []interface{}{req.CustomerID}
-
-	// Add optional filters
-	if req.Status != "" {
-		query += " AND status = ?"
-		args = append(args, req.Status)
-	}
-
-	if req.From != "" {
-		query += " AND created_at >= ?"
-		args = append(args, req.From)
-	}
-
-	if req.To != "" {
-		query += " AND created_at <= ?"
-		args = append(args, req.To)
-	}
+	filters, args := invoiceFilters(req)
+	query := `SELECT id, invoice_code, customer_id, customer_name, amount, COALESCE(currency, 'NGN'), status, due_date, created_at, updated_at FROM invoices WHERE 1=1` + filters
 
 	// Add ordering
 	query += " ORDER BY created_at DESC"
@@ -208,13 +232,16 @@ func (h *InvoiceHandler) List(w http.ResponseWriter, r *http.Request) {
 	invoices := []Invoice{}
 	for rows.Next() {
 		var invoice Invoice
+		var dueDate sql.NullTime
 		err := rows.Scan(
 			&invoice.ID,
 			&invoice.InvoiceCode,
 			&invoice.CustomerID,
 			&invoice.CustomerName,
 			&invoice.Amount,
+			&invoice.Currency,
 			&invoice.Status,
+			&dueDate,
 			&invoice.CreatedAt,
 			&invoice.UpdatedAt,
 		)
@@ -222,6 +249,9 @@ func (h *InvoiceHandler) List(w http.ResponseWriter, r *http.Request) {
 			WriteJSONError(w, fmt.Errorf("failed to scan invoice: %w", err), http.StatusInternalServerError)
 			return
 		}
+		if dueDate.Valid {
+			invoice.DueDate = &dueDate.Time
+		}
 		invoices = append(invoices, invoice)
 	}
 
@@ -233,6 +263,40 @@ func (h *InvoiceHandler) List(w http.ResponseWriter, r *http.Request) {
 	WriteJSONSuccess(w, invoices)
 }
 
+// invoiceFilters turns list filters into SQL conditions on the invoices table.
+// List and the exports share it so both select the same invoices.
+func invoiceFilters(req ListInvoicesRequest) (string, []interface{}) {
+	query := ""
+	args := []interface{}{}
+
+	if req.CustomerID != "" {
+		query += " AND customer_id = ?"
+		args = append(args, req.CustomerID)
+	}
+
+	if req.Status != "" {
+		query += " AND status = ?"
+		args = append(args, req.Status)
+	}
+
+	if req.Currency != "" {
+		query += " AND COALESCE(currency, 'NGN') = ?"
+		args = append(args, strings.ToUpper(req.Currency))
+	}
+
+	if req.From != "" {
+		query += " AND created_at >= ?"
+		args = append(args, req.From)
+	}
+
+	if req.To != "" {
+		query += " AND created_at <= ?"
+		args = append(args, req.To)
+	}
+
+	return query, args
+}
+
 // Get fetches a single invoice from Paystack
 func (h *InvoiceHandler) Get(w http.ResponseWriter, r *http.Request) {
 	idOrCode := chi.URLParam(r, "id_or_code")
@@ -273,6 +337,8 @@ func (h *InvoiceHandler) Verify(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		// Log the error but still return the Paystack response
 		fmt.Printf("Warning: Failed to update invoice status in database: %v\n", err)
+	} else {
+		postLedger("invoice "+code, PostInvoiceJournal(code))
 	}
 
 	WriteJSONSuccess(w, result)
@@ -0,0 +1,666 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Ledger Handler - Financial Integrity
+//
+// OBJECTIVES:
+// Every naira that moves should be accounted for twice, so the numbers always add up.
+//
+// PURPOSE:
+// - Post balanced journal entries for expenses, card payments, transfers, refunds, invoice payments and goal contributions
+// - Derive budget spent amounts and goal progress from account balances
+// - Show account balances and the entries behind them
+// - Prove the books balance with an integrity check
+//
+// KEY WORKFLOW:
+// Money Event (expense, transfer, invoice paid...) → Build Entry → Validate Balance →
+// Write Entry + Lines in One Transaction → Refresh Budget Spent From Ledger
+//
+// DESIGN DECISIONS:
+// - Entry rules and account codes live in internal/ledger; this file stores and reads them
+// - Entries are keyed by (source, kind), so posting the same event twice is a no-op
+// - Postings follow the current state of a record, so callers just post after every change
+// - Refunds, cancellations and failures post reversing entries; nothing is ever deleted
+// - budget_limits.spent_amount is kept equal to the budget account balance in the same transaction
+// - Goal progress is read from the goal account balance, never stored
+// - Existing records are backfilled on startup so the journal covers history
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/ledger"
+	"paystack.mpc.proxy/internal/money"
+)
+
+// Journal entry kinds
+const (
+	entryExpenseRecorded  = "expense_recorded"
+	entryExpensePaid      = "expense_paid"
+	entryExpenseReversed  = "expense_reversed"
+	entryGoalContribution = "goal_contribution"
+	entryGoalReversal     = "goal_contribution_reversed"
+	entryTransfer         = "transfer"
+	entryInvoicePaid      = "invoice_paid"
+)
+
+// Expense statuses that take an expense back out of its budget
+var reversedExpenseStatuses = map[string]bool{
+	"cancelled": true,
+	"refunded":  true,
+	"failed":    true,
+}
+
+// Invoice statuses that mean the customer has paid
+var paidInvoiceStatuses = map[string]bool{
+	"paid":    true,
+	"success": true,
+}
+
+type LedgerHandler struct{}
+
+func NewLedgerHandler() *LedgerHandler {
+	return &LedgerHandler{}
+}
+
+// JournalEntry is a stored entry with its lines
+type JournalEntry struct {
+	ID int64 `json:"id"`
+	ledger.Entry
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// postJournal validates and writes an entry. It returns false if the entry was
+// already posted. Budgets touched by the entry get their spent amount refreshed
+// from the ledger in the same transaction.
+func postJournal(entry ledger.Entry) (bool, error) {
+	if entry.Currency == "" {
+		entry.Currency = "NGN"
+	}
+	if entry.OccurredAt.IsZero() {
+		entry.OccurredAt = time.Now()
+	}
+	if err := entry.Validate(); err != nil {
+		return false, fmt.Errorf("invalid %s entry for %s %d: %w", entry.Kind, entry.SourceType, entry.SourceID, err)
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin journal transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO journal_entries (kind, reference, description, source_type, source_id, currency, occurred_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(source_type, source_id, kind) DO NOTHING
+	`, entry.Kind, entry.Reference, entry.Description, entry.SourceType, entry.SourceID, entry.Currency, entry.OccurredAt, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to insert journal entry: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	entryID, _ := result.LastInsertId()
+
+	for _, line := range entry.Lines {
+		if _, err := tx.Exec(
+			"INSERT INTO journal_lines (entry_id, account_code, debit, credit) VALUES (?, ?, ?, ?)",
+			entryID, line.Account, line.Debit, line.Credit,
+		); err != nil {
+			return false, fmt.Errorf("failed to insert journal line: %w", err)
+		}
+		if budgetID, ok := ledger.BudgetID(line.Account); ok {
+			if err := refreshBudgetSpent(tx, budgetID); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit journal entry: %w", err)
+	}
+	return true, nil
+}
+
+// refreshBudgetSpent sets a budget's spent amount to its ledger balance
+func refreshBudgetSpent(tx *sql.Tx, budgetID int) error {
+	_, err := tx.Exec(`
+		UPDATE budget_limits
+		SET spent_amount = COALESCE((SELECT spent_amount FROM budget_ledger_spent WHERE budget_id = ?), 0),
+		    updated_at = ?
+		WHERE id = ?
+	`, budgetID, time.Now(), budgetID)
+	if err != nil {
+		return fmt.Errorf("failed to refresh budget spending: %w", err)
+	}
+	return nil
+}
+
+func journalEntryExists(sourceType string, sourceID int64, kind string) bool {
+	var exists bool
+	database.DB.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM journal_entries WHERE source_type = ? AND source_id = ? AND kind = ?)",
+		sourceType, sourceID, kind,
+	).Scan(&exists)
+	return exists
+}
+
+// expensePaymentAccount is where an expense's money comes from when it is paid
+func expensePaymentAccount(recipientCode string, imported bool) string {
+	if imported {
+		return ledger.AccountBank
+	}
+	if strings.HasPrefix(recipientCode, "CARD_") {
+		return ledger.AccountCards
+	}
+	return ledger.AccountPaystack
+}
+
+// PostExpenseJournal brings an expense's journal in line with its current status.
+// Recording charges the budget, paying settles the payable, and a cancelled,
+// failed or refunded expense is taken back out of its budget and goal.
+// Foreign-currency expenses post at the amount charged to the budget, so each
+// budget's books stay in its own currency.
+func PostExpenseJournal(expenseID int64) error {
+	var recipientCode, currency, originalCurrency, status, reference, narration string
+	var amount, originalAmount int64
+	var goalID, budgetID sql.NullInt64
+	var paymentDate sql.NullTime
+	var createdAt, updatedAt time.Time
+	var imported bool
+	err := database.DB.QueryRow(`
+		SELECT recipient_code, COALESCE(budget_amount, amount), COALESCE(budget_currency, currency), amount, currency,
+		       status, reference, narration, goal_id, budget_limit_id, payment_date, created_at, updated_at,
+		       import_id IS NOT NULL
+		FROM expenses WHERE id = ?
+	`, expenseID).Scan(&recipientCode, &amount, &currency, &originalAmount, &originalCurrency, &status, &reference, &narration, &goalID, &budgetID, &paymentDate, &createdAt, &updatedAt, &imported)
+	if err != nil {
+		return fmt.Errorf("failed to load expense %d: %w", expenseID, err)
+	}
+	if amount <= 0 {
+		return nil
+	}
+	if originalCurrency != currency {
+		narration = fmt.Sprintf("%s (paid %s)", narration, money.New(originalAmount, money.Currency(originalCurrency)).Format())
+	}
+
+	// Expenses recorded before budgets were required are charged to general spending
+	budgetAccount := ledger.AccountTransfers
+	if budgetID.Valid {
+		budgetAccount = ledger.BudgetAccount(int(budgetID.Int64))
+	}
+	paidFrom := expensePaymentAccount(recipientCode, imported)
+
+	entry := func(kind string, occurredAt time.Time, lines []ledger.Line) error {
+		_, err := postJournal(ledger.Entry{
+			Kind:        kind,
+			Reference:   reference,
+			Description: narration,
+			SourceType:  "expense",
+			SourceID:    expenseID,
+			Currency:    currency,
+			OccurredAt:  occurredAt,
+			Lines:       lines,
+		})
+		return err
+	}
+
+	if err := entry(entryExpenseRecorded, createdAt, ledger.Move(ledger.AccountPayables, budgetAccount, amount)); err != nil {
+		return err
+	}
+	if goalID.Valid {
+		if err := entry(entryGoalContribution, createdAt, ledger.Move(ledger.AccountGoalFunding, ledger.GoalAccount(int(goalID.Int64)), amount)); err != nil {
+			return err
+		}
+	}
+
+	// A reversed expense is final; later status changes don't post again
+	reversed := journalEntryExists("expense", expenseID, entryExpenseReversed)
+
+	if status == "paid" && !reversed {
+		paidAt := updatedAt
+		if paymentDate.Valid {
+			paidAt = paymentDate.Time
+		}
+		if err := entry(entryExpensePaid, paidAt, ledger.Move(paidFrom, ledger.AccountPayables, amount)); err != nil {
+			return err
+		}
+	}
+
+	if reversedExpenseStatuses[status] && !reversed {
+		// Unpaid expenses cancel the payable; paid ones bring the money back
+		returnTo := ledger.AccountPayables
+		if journalEntryExists("expense", expenseID, entryExpensePaid) {
+			returnTo = paidFrom
+		}
+		if err := entry(entryExpenseReversed, updatedAt, ledger.Move(budgetAccount, returnTo, amount)); err != nil {
+			return err
+		}
+		if goalID.Valid {
+			if err := entry(entryGoalReversal, updatedAt, ledger.Move(ledger.GoalAccount(int(goalID.Int64)), ledger.AccountGoalFunding, amount)); err != nil {
+				return err
+			}
+			// The goal is no longer funded, so it goes back to pending
+			if _, err := database.DB.Exec(`
+				UPDATE goals SET status = 'pending', achieved_at = NULL, achieved_by_expense_id = NULL, updated_at = ?
+				WHERE id = ? AND status = 'achieved' AND achieved_by_expense_id = ?
+			`, time.Now(), goalID.Int64, expenseID); err != nil {
+				fmt.Printf("Warning: Failed to reopen goal %d: %v\n", goalID.Int64, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// PostTransferJournal posts a transfer made outside of expenses
+func PostTransferJournal(paymentID int64) error {
+	var amount int64
+	var currency string
+	var reference sql.NullString
+	var createdAt time.Time
+	err := database.DB.QueryRow(
+		"SELECT amount, currency, reference, created_at FROM outgoing_payments WHERE id = ? AND source = 'transfer'",
+		paymentID,
+	).Scan(&amount, &currency, &reference, &createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to load transfer %d: %w", paymentID, err)
+	}
+
+	_, err = postJournal(ledger.Entry{
+		Kind:        entryTransfer,
+		Reference:   reference.String,
+		Description: "Transfer " + reference.String,
+		SourceType:  "transfer",
+		SourceID:    paymentID,
+		Currency:    currency,
+		OccurredAt:  createdAt,
+		Lines:       ledger.Move(ledger.AccountPaystack, ledger.AccountTransfers, amount),
+	})
+	return err
+}
+
+// PostInvoiceJournal records an invoice's income once it has been paid
+func PostInvoiceJournal(invoiceCode string) error {
+	var id, amount int64
+	var customerName, currency string
+	var status sql.NullString
+	var updatedAt time.Time
+	err := database.DB.QueryRow(
+		"SELECT id, amount, customer_name, COALESCE(currency, 'NGN'), status, updated_at FROM invoices WHERE invoice_code = ?",
+		invoiceCode,
+	).Scan(&id, &amount, &customerName, &currency, &status, &updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to load invoice %s: %w", invoiceCode, err)
+	}
+	if !paidInvoiceStatuses[status.String] || amount <= 0 {
+		return nil
+	}
+
+	_, err = postJournal(ledger.Entry{
+		Kind:        entryInvoicePaid,
+		Reference:   invoiceCode,
+		Description: "Invoice paid by " + customerName,
+		SourceType:  "invoice",
+		SourceID:    id,
+		Currency:    currency,
+		OccurredAt:  updatedAt,
+		Lines:       ledger.Move(ledger.AccountInvoiceIncome, ledger.AccountPaystack, amount),
+	})
+	return err
+}
+
+// BackfillLedger posts entries for every record that should have them.
+// It is safe to run repeatedly; already-posted events are skipped.
+func BackfillLedger() (map[string]int, error) {
+	counts := map[string]int{}
+
+	backfill := func(name, query string, post func(int64, string) error) error {
+		rows, err := database.DB.Query(query)
+		if err != nil {
+			return fmt.Errorf("failed to load %s for the ledger: %w", name, err)
+		}
+		type record struct {
+			id   int64
+			code string
+		}
+		var records []record
+		for rows.Next() {
+			var rec record
+			if err := rows.Scan(&rec.id, &rec.code); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan %s: %w", name, err)
+			}
+			records = append(records, rec)
+		}
+		rows.Close()
+
+		for _, rec := range records {
+			if err := post(rec.id, rec.code); err != nil {
+				return err
+			}
+			counts[name]++
+		}
+		return nil
+	}
+
+	if err := backfill("expenses", "SELECT id, '' FROM expenses ORDER BY id", func(id int64, _ string) error {
+		return PostExpenseJournal(id)
+	}); err != nil {
+		return counts, err
+	}
+	if err := backfill("transfers", "SELECT id, '' FROM outgoing_payments WHERE source = 'transfer' AND reference IS NOT NULL ORDER BY id", func(id int64, _ string) error {
+		return PostTransferJournal(id)
+	}); err != nil {
+		return counts, err
+	}
+	if err := backfill("invoices", "SELECT id, invoice_code FROM invoices WHERE status IN ('paid', 'success') ORDER BY id", func(_ int64, code string) error {
+		return PostInvoiceJournal(code)
+	}); err != nil {
+		return counts, err
+	}
+
+	// Budgets counted before the ledger existed are reset to what the ledger shows
+	if _, err := database.DB.Exec(`
+		UPDATE budget_limits
+		SET spent_amount = COALESCE((SELECT spent_amount FROM budget_ledger_spent WHERE budget_id = budget_limits.id), 0)
+		WHERE spent_amount != COALESCE((SELECT spent_amount FROM budget_ledger_spent WHERE budget_id = budget_limits.id), 0)
+	`); err != nil {
+		return counts, fmt.Errorf("failed to refresh budget spending: %w", err)
+	}
+
+	return counts, nil
+}
+
+// postLedger logs a failed posting; the integrity check reports what's missing
+func postLedger(what string, err error) {
+	if err != nil {
+		fmt.Printf("Warning: Failed to post %s to the ledger: %v\n", what, err)
+	}
+}
+
+func loadJournalLines(where string, args ...interface{}) ([]ledger.Line, error) {
+	rows, err := database.DB.Query("SELECT account_code, debit, credit FROM journal_lines"+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load journal lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []ledger.Line
+	for rows.Next() {
+		var line ledger.Line
+		if err := rows.Scan(&line.Account, &line.Debit, &line.Credit); err != nil {
+			return nil, fmt.Errorf("failed to scan journal line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// Accounts returns the trial balance: every account with its debits, credits and balance.
+// Each currency is its own set of books, chosen with ?currency= (NGN by default).
+func (h *LedgerHandler) Accounts(w http.ResponseWriter, r *http.Request) {
+	currency, err := money.ParseCurrency(r.URL.Query().Get("currency"))
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	lines, err := loadJournalLines(" WHERE entry_id IN (SELECT id FROM journal_entries WHERE currency = ?)", currency)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	balances, debits, credits := ledger.TrialBalance(lines)
+	if t := r.URL.Query().Get("type"); t != "" {
+		filtered := []ledger.Balance{}
+		for _, b := range balances {
+			if b.Type == t {
+				filtered = append(filtered, b)
+			}
+		}
+		balances = filtered
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"currency":      currency,
+		"accounts":      balances,
+		"total_debits":  debits,
+		"total_credits": credits,
+		"balanced":      debits == credits,
+	})
+}
+
+// Entries lists journal entries, filtered by ?account=, ?source_type= and ?source_id=
+func (h *LedgerHandler) Entries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := "SELECT id, kind, COALESCE(reference, ''), COALESCE(description, ''), source_type, source_id, currency, occurred_at, created_at FROM journal_entries WHERE 1=1"
+	var args []interface{}
+
+	if account := q.Get("account"); account != "" {
+		query += " AND id IN (SELECT entry_id FROM journal_lines WHERE account_code = ?)"
+		args = append(args, account)
+	}
+	if sourceType := q.Get("source_type"); sourceType != "" {
+		query += " AND source_type = ?"
+		args = append(args, sourceType)
+	}
+	if sourceID := q.Get("source_id"); sourceID != "" {
+		id, err := strconv.ParseInt(sourceID, 10, 64)
+		if err != nil {
+			WriteJSONBadRequest(w, "source_id must be a number")
+			return
+		}
+		query += " AND source_id = ?"
+		args = append(args, id)
+	}
+
+	limit := 50
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 500 {
+			limit = n
+		}
+	}
+	offset := 0
+	if v := q.Get("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+	query += " ORDER BY occurred_at DESC, id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to list journal entries: %w", err), http.StatusInternalServerError)
+		return
+	}
+	entries := []JournalEntry{}
+	for rows.Next() {
+		var e JournalEntry
+		if err := rows.Scan(&e.ID, &e.Kind, &e.Reference, &e.Description, &e.SourceType, &e.SourceID, &e.Currency, &e.OccurredAt, &e.CreatedAt); err != nil {
+			rows.Close()
+			WriteJSONError(w, fmt.Errorf("failed to scan journal entry: %w", err), http.StatusInternalServerError)
+			return
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+
+	for i := range entries {
+		lines, err := loadJournalLines(" WHERE entry_id = ? ORDER BY id", entries[i].ID)
+		if err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+		entries[i].Lines = lines
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// LedgerIssue is one thing the integrity check found wrong
+type LedgerIssue struct {
+	Check     string `json:"check"`
+	Reference string `json:"reference"`
+	Detail    string `json:"detail"`
+}
+
+// LedgerIntegrity is the result of an integrity check
+type LedgerIntegrity struct {
+	Balanced     bool          `json:"balanced"`
+	Entries      int           `json:"entries"`
+	TotalDebits  int64         `json:"total_debits"`
+	TotalCredits int64         `json:"total_credits"`
+	Issues       []LedgerIssue `json:"issues"`
+	CheckedAt    time.Time     `json:"checked_at"`
+}
+
+// CheckLedgerIntegrity verifies the journal balances and agrees with the records it describes
+func CheckLedgerIntegrity() (*LedgerIntegrity, error) {
+	report := &LedgerIntegrity{Issues: []LedgerIssue{}, CheckedAt: time.Now()}
+
+	// issues runs a query returning (reference, detail) pairs and records each as an issue
+	issues := func(check, query string, args ...interface{}) error {
+		rows, err := database.DB.Query(query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to run %s check: %w", check, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			issue := LedgerIssue{Check: check}
+			if err := rows.Scan(&issue.Reference, &issue.Detail); err != nil {
+				return fmt.Errorf("failed to scan %s check: %w", check, err)
+			}
+			report.Issues = append(report.Issues, issue)
+		}
+		return rows.Err()
+	}
+
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM journal_entries").Scan(&report.Entries); err != nil {
+		return nil, fmt.Errorf("failed to count journal entries: %w", err)
+	}
+
+	lines, err := loadJournalLines("")
+	if err != nil {
+		return nil, err
+	}
+	_, report.TotalDebits, report.TotalCredits = ledger.TrialBalance(lines)
+	if report.TotalDebits != report.TotalCredits {
+		report.Issues = append(report.Issues, LedgerIssue{
+			Check:     "trial_balance",
+			Reference: "journal",
+			Detail:    fmt.Sprintf("debits %d do not equal credits %d", report.TotalDebits, report.TotalCredits),
+		})
+	}
+
+	checks := []struct {
+		name  string
+		query string
+	}{
+		{"unbalanced_entry", `
+			SELECT 'entry ' || e.id, e.kind || ' has debits ' || COALESCE(SUM(l.debit), 0) || ', credits ' || COALESCE(SUM(l.credit), 0) || ' over ' || COUNT(l.id) || ' lines'
+			FROM journal_entries e LEFT JOIN journal_lines l ON l.entry_id = e.id
+			GROUP BY e.id
+			HAVING COUNT(l.id) < 2 OR COALESCE(SUM(l.debit), 0) != COALESCE(SUM(l.credit), 0)`},
+		{"budget_spent", `
+			SELECT 'budget ' || b.id, 'spent_amount ' || b.spent_amount || ' but ledger shows ' || COALESCE(v.spent_amount, 0)
+			FROM budget_limits b LEFT JOIN budget_ledger_spent v ON v.budget_id = b.id
+			WHERE b.spent_amount != COALESCE(v.spent_amount, 0)`},
+		{"goal_progress", `
+			SELECT 'goal ' || g.id, 'achieved with target ' || g.target_amount || ' but ledger progress is ' || COALESCE(v.progress_amount, 0)
+			FROM goals g LEFT JOIN goal_ledger_progress v ON v.goal_id = g.id
+			WHERE g.status = 'achieved' AND g.achieved_by_expense_id IS NOT NULL AND COALESCE(v.progress_amount, 0) < g.target_amount`},
+		{"unposted_expense", `
+			SELECT 'expense ' || x.id, 'status ' || x.status || ' is missing ' || k.kind
+			FROM expenses x
+			JOIN (SELECT 'expense_recorded' AS kind UNION ALL SELECT 'expense_paid' UNION ALL SELECT 'expense_reversed') k
+			WHERE x.amount > 0
+			  AND (k.kind = 'expense_recorded'
+			       OR (k.kind = 'expense_paid' AND x.status = 'paid')
+			       OR (k.kind = 'expense_reversed' AND x.status IN ('cancelled', 'refunded', 'failed')))
+			  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'expense' AND e.source_id = x.id AND e.kind = k.kind)
+			  AND NOT (k.kind = 'expense_paid' AND EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'expense' AND e.source_id = x.id AND e.kind = 'expense_reversed'))`},
+		{"unposted_transfer", `
+			SELECT 'transfer ' || p.reference, 'transfer of ' || p.amount || ' has no journal entry'
+			FROM outgoing_payments p
+			WHERE p.source = 'transfer' AND p.reference IS NOT NULL
+			  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'transfer' AND e.source_id = p.id)`},
+		{"unposted_invoice", `
+			SELECT 'invoice ' || i.invoice_code, 'paid invoice of ' || i.amount || ' has no journal entry'
+			FROM invoices i
+			WHERE i.status IN ('paid', 'success')
+			  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'invoice' AND e.source_id = i.id)`},
+	}
+	for _, c := range checks {
+		if err := issues(c.name, c.query); err != nil {
+			return nil, err
+		}
+	}
+
+	// Outstanding payables must equal the unpaid expenses still standing, currency by currency
+	if err := issues("payables", `
+		SELECT ? || ' ' || c.currency, 'payables balance ' || COALESCE(p.balance, 0) || ' but unpaid expenses total ' || COALESCE(o.total, 0)
+		FROM (SELECT DISTINCT currency FROM journal_entries) c
+		LEFT JOIN (
+			SELECT e.currency, SUM(l.credit) - SUM(l.debit) AS balance
+			FROM journal_lines l JOIN journal_entries e ON e.id = l.entry_id
+			WHERE l.account_code = ?
+			GROUP BY e.currency
+		) p ON p.currency = c.currency
+		LEFT JOIN (
+			SELECT COALESCE(x.budget_currency, x.currency) AS currency, SUM(COALESCE(x.budget_amount, x.amount)) AS total
+			FROM expenses x
+			WHERE x.amount > 0
+			  AND EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'expense' AND e.source_id = x.id AND e.kind = 'expense_recorded')
+			  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'expense' AND e.source_id = x.id AND e.kind IN ('expense_paid', 'expense_reversed'))
+			GROUP BY COALESCE(x.budget_currency, x.currency)
+		) o ON o.currency = c.currency
+		WHERE COALESCE(p.balance, 0) != COALESCE(o.total, 0)`, ledger.AccountPayables, ledger.AccountPayables); err != nil {
+		return nil, err
+	}
+
+	report.Balanced = len(report.Issues) == 0
+	return report, nil
+}
+
+// Integrity runs the integrity check
+func (h *LedgerHandler) Integrity(w http.ResponseWriter, r *http.Request) {
+	report, err := CheckLedgerIntegrity()
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	message := "Ledger balances and matches all records"
+	if !report.Balanced {
+		message = fmt.Sprintf("Ledger has %d integrity issues", len(report.Issues))
+	}
+	WriteJSONSuccessWithMessage(w, message, report)
+}
+
+// Backfill posts any missing entries for existing records
+func (h *LedgerHandler) Backfill(w http.ResponseWriter, r *http.Request) {
+	counts, err := BackfillLedger()
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Ledger backfill checked %d expenses, %d transfers, %d invoices", counts["expenses"], counts["transfers"], counts["invoices"])
+	WriteJSONSuccessWithMessage(w, "Ledger backfill complete", map[string]interface{}{
+		"checked": counts,
+	})
+}
@@ -0,0 +1,783 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Limits Handler - Financial Management Core
+//
+// OBJECTIVES:
+// Users need hard caps on how much money can leave the account, overall and per beneficiary.
+//
+// PURPOSE:
+// - Persist account-level per-transaction, daily, weekly and monthly transfer caps
+// - Persist per-beneficiary caps keyed by recipient_code, with alert thresholds
+// - Check every transfer and expense payment against the caps before it executes
+// - Explain exactly which limit a rejected payment would break and by how much
+//
+// KEY WORKFLOW:
+// Set Limits → Initiate Transfer / Create Expense → Check Rolling Windows →
+// Reject With Reason (or Reserve) → Execute → Release Reservation on Failure
+//
+// DESIGN DECISIONS:
+// - Windows are rolling (last 24 hours, 7 days, 30 days), not calendar periods
+// - Outgoing payments are reserved in the outgoing_payments table before execution so concurrent payments can't both slip under a cap
+// - An expense's reservation is released when the expense is cancelled or fails, since that money never leaves
+// - A limit of 0 means "no limit"; account limits live in a single row
+// - Beneficiaries can be referenced by recipient_code or by cached recipient name
+// - balance_limit is stored as an alert threshold only and never blocks payments
+// - All amounts stored in kobo (Nigerian currency subunit) for precision
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// limitPeriods maps each rolling window to its length, in evaluation order
+var limitPeriods = []struct {
+	Name   string
+	Window time.Duration
+}{
+	{"daily", 24 * time.Hour},
+	{"weekly", 7 * 24 * time.Hour},
+	{"monthly", 30 * 24 * time.Hour},
+}
+
+// limitsMu serialises check-and-reserve so two payments can't both pass the same window
+var limitsMu sync.Mutex
+
+type LimitHandler struct{}
+
+func NewLimitHandler() *LimitHandler {
+	return &LimitHandler{}
+}
+
+// AccountLimits are the account-wide transfer caps (0 means no limit)
+type AccountLimits struct {
+	PerTransactionLimit  money.Amount   `json:"per_transaction_limit"`
+	DailyTransferLimit   money.Amount   `json:"daily_transfer_limit"`
+	WeeklyTransferLimit  money.Amount   `json:"weekly_transfer_limit"`
+	MonthlyTransferLimit money.Amount   `json:"monthly_transfer_limit"`
+	BalanceLimit         money.Amount   `json:"balance_limit"`
+	Currency             money.Currency `json:"currency"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+}
+
+// BeneficiaryLimit caps transfers to a single recipient over a rolling period
+type BeneficiaryLimit struct {
+	ID              int            `json:"id"`
+	RecipientCode   string         `json:"recipient_code"`
+	RecipientName   string         `json:"recipient_name,omitempty"`
+	Period          string         `json:"period"`
+	AmountLimit     money.Amount   `json:"amount_limit"`
+	Currency        money.Currency `json:"currency"`
+	AlertsAtPercent int            `json:"alerts_at_percent"`
+	UsedAmount      money.Amount   `json:"used_amount"`
+	Remaining       money.Amount   `json:"remaining"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+type SetAccountLimitsRequest struct {
+	PerTransactionLimit  *money.Amount `json:"per_transaction_limit,omitempty"`
+	DailyTransferLimit   *money.Amount `json:"daily_transfer_limit,omitempty"`
+	WeeklyTransferLimit  *money.Amount `json:"weekly_transfer_limit,omitempty"`
+	MonthlyTransferLimit *money.Amount `json:"monthly_transfer_limit,omitempty"`
+	BalanceLimit         *money.Amount `json:"balance_limit,omitempty"`
+	Currency             string        `json:"currency,omitempty"`
+}
+
+type SetBeneficiaryLimitRequest struct {
+	RecipientCode   string       `json:"recipient_code,omitempty"`
+	BeneficiaryID   string       `json:"beneficiary_id,omitempty"`
+	Period          string       `json:"period"`
+	AmountLimit     money.Amount `json:"amount_limit"`
+	Currency        string       `json:"currency,omitempty"`
+	AlertsAtPercent int          `json:"alerts_at_percent,omitempty"`
+}
+
+type CheckTransferLimitsRequest struct {
+	RecipientCode string `json:"recipient_code"`
+	money.Money
+}
+
+// LimitViolation describes one cap a payment would break. Limit, Used and
+// Remaining are in the cap's currency; Requested is in the payment's.
+type LimitViolation struct {
+	Scope     string         `json:"scope"`
+	Period    string         `json:"period"`
+	Limit     money.Amount   `json:"limit"`
+	Currency  money.Currency `json:"currency"`
+	Used      money.Amount   `json:"used"`
+	Requested money.Amount   `json:"requested"`
+	Remaining money.Amount   `json:"remaining"`
+	Reason    string         `json:"reason"`
+}
+
+// limit returns one of the account caps as money in the limits' currency
+func (l *AccountLimits) limit(amount money.Amount) money.Money {
+	return money.New(int64(amount), l.Currency.OrDefault())
+}
+
+// Limit returns the beneficiary cap as money
+func (bl BeneficiaryLimit) Limit() money.Money {
+	return money.New(int64(bl.AmountLimit), bl.Currency.OrDefault())
+}
+
+// LimitCheckResult is the outcome of checking a payment against all caps
+type LimitCheckResult struct {
+	Allowed         bool             `json:"allowed"`
+	RecipientCode   string           `json:"recipient_code"`
+	RequestedAmount money.Amount     `json:"requested_amount"`
+	Currency        money.Currency   `json:"currency"`
+	Violations      []LimitViolation `json:"violations,omitempty"`
+	Alerts          []string         `json:"alerts,omitempty"`
+	Reason          string           `json:"reason"`
+}
+
+// GetAccountLimits loads the account-wide caps, returning empty limits if none are set
+func GetAccountLimits() (*AccountLimits, error) {
+	limits := &AccountLimits{Currency: money.Default}
+	err := database.DB.QueryRow(`
+		SELECT per_transaction_limit, daily_transfer_limit, weekly_transfer_limit,
+		       monthly_transfer_limit, balance_limit, currency, updated_at
+		FROM account_limits WHERE id = 1
+	`).Scan(
+		&limits.PerTransactionLimit,
+		&limits.DailyTransferLimit,
+		&limits.WeeklyTransferLimit,
+		&limits.MonthlyTransferLimit,
+		&limits.BalanceLimit,
+		&limits.Currency,
+		&limits.UpdatedAt,
+	)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to fetch account limits: %w", err)
+	}
+	return limits, nil
+}
+
+// outgoingTotal sums outgoing payments in a currency since a point in time, optionally for one recipient
+func outgoingTotal(recipientCode string, currency money.Currency, since time.Time) (money.Amount, error) {
+	query := "SELECT COALESCE(SUM(amount), 0) FROM outgoing_payments WHERE created_at >= ? AND COALESCE(currency, 'NGN') = ?"
+	args := []interface{}{since, currency.OrDefault()}
+	if recipientCode != "" {
+		query += " AND recipient_code = ?"
+		args = append(args, recipientCode)
+	}
+
+	var total money.Amount
+	if err := database.DB.QueryRow(query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to sum outgoing payments: %w", err)
+	}
+	return total, nil
+}
+
+// pendingPayments are earlier payments in the same batch, counted against the
+// windows as if they were already reserved
+type pendingPayments struct {
+	total       money.Amount
+	byRecipient map[string]money.Amount
+}
+
+func (p *pendingPayments) add(recipientCode string, amount money.Amount) {
+	if p.byRecipient == nil {
+		p.byRecipient = map[string]money.Amount{}
+	}
+	p.total += amount
+	p.byRecipient[recipientCode] += amount
+}
+
+// to is what earlier payments in the batch send to recipientCode, or to everyone if it is empty
+func (p *pendingPayments) to(recipientCode string) money.Amount {
+	if p == nil {
+		return 0
+	}
+	if recipientCode == "" {
+		return p.total
+	}
+	return p.byRecipient[recipientCode]
+}
+
+// CheckTransferLimits checks a payment to recipientCode against account and
+// beneficiary caps using rolling windows. Caps only count payments in their own
+// currency, and a payment in another currency can't be checked against them.
+func CheckTransferLimits(recipientCode string, amount money.Money) (*LimitCheckResult, error) {
+	return checkTransferLimits(recipientCode, amount, nil)
+}
+
+// checkTransferLimits is CheckTransferLimits with a batch's earlier payments counted as used
+func checkTransferLimits(recipientCode string, amount money.Money, pending *pendingPayments) (*LimitCheckResult, error) {
+	limits, err := GetAccountLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	amount.Currency = amount.Currency.OrDefault()
+	result := &LimitCheckResult{RecipientCode: recipientCode, RequestedAmount: amount.Amount, Currency: amount.Currency}
+	now := time.Now()
+
+	mismatch := func(scope, period string, limit money.Money) LimitViolation {
+		return LimitViolation{
+			Scope:     scope,
+			Period:    period,
+			Limit:     limit.Amount,
+			Currency:  limit.Currency,
+			Requested: amount.Amount,
+			Reason: fmt.Sprintf("The %s limit is set in %s, so a %s payment can't be checked against it",
+				strings.ReplaceAll(period, "_", "-"), limit.Currency, amount.Currency),
+		}
+	}
+	// sent describes used, which includes earlier payments in the batch when there are any
+	sent := func(recipientCode string) string {
+		if pending.to(recipientCode) > 0 {
+			return "already sent or earlier in this batch"
+		}
+		return "already sent"
+	}
+	// exceeded reports whether this payment on top of used would go over limit, and what is left
+	exceeded := func(limit money.Money, used money.Amount) (money.Money, money.Money, bool) {
+		spent := money.New(int64(used), limit.Currency)
+		after, _ := spent.Add(amount)
+		remaining, _ := limit.Sub(spent)
+		remaining.Amount = max(remaining.Amount, 0)
+		return spent, remaining, after.Amount > limit.Amount
+	}
+
+	if perTransaction := limits.limit(limits.PerTransactionLimit); perTransaction.Amount > 0 {
+		if !perTransaction.SameCurrency(amount) {
+			result.Violations = append(result.Violations, mismatch("account", "per_transaction", perTransaction))
+		} else if amount.Amount > perTransaction.Amount {
+			result.Violations = append(result.Violations, LimitViolation{
+				Scope:     "account",
+				Period:    "per_transaction",
+				Limit:     perTransaction.Amount,
+				Currency:  perTransaction.Currency,
+				Requested: amount.Amount,
+				Remaining: perTransaction.Amount,
+				Reason: fmt.Sprintf("%s exceeds the per-transaction limit of %s",
+					amount.Format(), perTransaction.Format()),
+			})
+		}
+	}
+
+	accountCaps := map[string]money.Amount{
+		"daily":   limits.DailyTransferLimit,
+		"weekly":  limits.WeeklyTransferLimit,
+		"monthly": limits.MonthlyTransferLimit,
+	}
+	for _, period := range limitPeriods {
+		limit := limits.limit(accountCaps[period.Name])
+		if limit.Amount <= 0 {
+			continue
+		}
+		if !limit.SameCurrency(amount) {
+			result.Violations = append(result.Violations, mismatch("account", period.Name, limit))
+			continue
+		}
+
+		used, err := outgoingTotal("", amount.Currency, now.Add(-period.Window))
+		if err != nil {
+			return nil, err
+		}
+		if spent, remaining, over := exceeded(limit, used+pending.to("")); over {
+			result.Violations = append(result.Violations, LimitViolation{
+				Scope:     "account",
+				Period:    period.Name,
+				Limit:     limit.Amount,
+				Currency:  limit.Currency,
+				Used:      spent.Amount,
+				Requested: amount.Amount,
+				Remaining: remaining.Amount,
+				Reason: fmt.Sprintf("%s would exceed the %s transfer limit of %s (%s %s, %s remaining)",
+					amount.Format(), period.Name, limit.Format(), spent.Format(), sent(""), remaining.Format()),
+			})
+		}
+	}
+
+	beneficiaryLimits, err := listBeneficiaryLimits(recipientCode)
+	if err != nil {
+		return nil, err
+	}
+	for _, bl := range beneficiaryLimits {
+		limit := bl.Limit()
+		if !limit.SameCurrency(amount) {
+			result.Violations = append(result.Violations, mismatch("beneficiary", bl.Period, limit))
+			continue
+		}
+
+		used := bl.UsedAmount + pending.to(bl.RecipientCode)
+		if spent, remaining, over := exceeded(limit, used); over {
+			result.Violations = append(result.Violations, LimitViolation{
+				Scope:     "beneficiary",
+				Period:    bl.Period,
+				Limit:     limit.Amount,
+				Currency:  limit.Currency,
+				Used:      spent.Amount,
+				Requested: amount.Amount,
+				Remaining: remaining.Amount,
+				Reason: fmt.Sprintf("%s would exceed the %s limit of %s for %s (%s %s, %s remaining)",
+					amount.Format(), bl.Period, limit.Format(), beneficiaryLabel(bl), spent.Format(), sent(bl.RecipientCode), remaining.Format()),
+			})
+			continue
+		}
+
+		usageAfter := float64(used+amount.Amount) / float64(bl.AmountLimit) * 100
+		if bl.AlertsAtPercent > 0 && usageAfter >= float64(bl.AlertsAtPercent) {
+			result.Alerts = append(result.Alerts, fmt.Sprintf("This uses %.0f%% of the %s limit for %s",
+				usageAfter, bl.Period, beneficiaryLabel(bl)))
+		}
+	}
+
+	result.Allowed = len(result.Violations) == 0
+	if result.Allowed {
+		result.Reason = "Within all transfer limits"
+	} else {
+		result.Reason = result.Violations[0].Reason
+	}
+	return result, nil
+}
+
+// ReserveOutgoingPayment checks the caps and, if allowed, records the payment
+// against the rolling windows. Release the reservation if the payment fails.
+func ReserveOutgoingPayment(recipientCode string, amount money.Money, source, narration string) (int64, *LimitCheckResult, error) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+
+	check, err := CheckTransferLimits(recipientCode, amount)
+	if err != nil || !check.Allowed {
+		return 0, check, err
+	}
+
+	result, err := database.DB.Exec(
+		"INSERT INTO outgoing_payments (recipient_code, amount, currency, source, narration, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		recipientCode, amount.Amount, amount.Currency.OrDefault(), source, narration, time.Now(),
+	)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to reserve outgoing payment: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	return id, check, nil
+}
+
+// ReleaseOutgoingPayment removes a reservation for a payment that did not go through
+func ReleaseOutgoingPayment(id int64) {
+	if _, err := database.DB.Exec("DELETE FROM outgoing_payments WHERE id = ?", id); err != nil {
+		fmt.Printf("Warning: Failed to release outgoing payment %d: %v\n", id, err)
+	}
+}
+
+// releaseExpenseReservation removes the reservation held for an expense that was
+// cancelled or failed, so it stops counting against the caps
+func releaseExpenseReservation(expenseID int64) error {
+	_, err := database.DB.Exec(
+		"DELETE FROM outgoing_payments WHERE source = 'expense' AND reference = (SELECT reference FROM expenses WHERE id = ?)",
+		expenseID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release reservation for expense %d: %w", expenseID, err)
+	}
+	return nil
+}
+
+// SetOutgoingPaymentReference links a reservation to the executed payment
+func SetOutgoingPaymentReference(id int64, reference string) {
+	if _, err := database.DB.Exec("UPDATE outgoing_payments SET reference = ? WHERE id = ?", reference, id); err != nil {
+		fmt.Printf("Warning: Failed to tag outgoing payment %d: %v\n", id, err)
+	}
+}
+
+// writeLimitRejection responds with the limits a payment would break
+func writeLimitRejection(w http.ResponseWriter, check *LimitCheckResult) {
+	respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"status":  false,
+		"message": "Transfer limit exceeded",
+		"error":   check.Reason,
+		"data":    check,
+	})
+}
+
+// listBeneficiaryLimits loads beneficiary caps with current rolling usage, optionally for one recipient
+func listBeneficiaryLimits(recipientCode string) ([]BeneficiaryLimit, error) {
+	query := `
+		SELECT bl.id, bl.recipient_code, COALESCE(r.name, ''), bl.period, bl.amount_limit,
+		       bl.currency, bl.alerts_at_percent, bl.created_at, bl.updated_at
+		FROM beneficiary_limits bl
+		LEFT JOIN recipients r ON r.recipient_code = bl.recipient_code
+	`
+	var args []interface{}
+	if recipientCode != "" {
+		query += " WHERE bl.recipient_code = ?"
+		args = append(args, recipientCode)
+	}
+	query += " ORDER BY bl.recipient_code, bl.amount_limit"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch beneficiary limits: %w", err)
+	}
+	defer rows.Close()
+
+	limits := []BeneficiaryLimit{}
+	for rows.Next() {
+		var bl BeneficiaryLimit
+		if err := rows.Scan(
+			&bl.ID,
+			&bl.RecipientCode,
+			&bl.RecipientName,
+			&bl.Period,
+			&bl.AmountLimit,
+			&bl.Currency,
+			&bl.AlertsAtPercent,
+			&bl.CreatedAt,
+			&bl.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan beneficiary limit: %w", err)
+		}
+		limits = append(limits, bl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range limits {
+		used, err := outgoingTotal(limits[i].RecipientCode, limits[i].Currency, now.Add(-periodWindow(limits[i].Period)))
+		if err != nil {
+			return nil, err
+		}
+		limits[i].UsedAmount = used
+		limits[i].Remaining = max(limits[i].AmountLimit-used, 0)
+	}
+	return limits, nil
+}
+
+func periodWindow(name string) time.Duration {
+	for _, period := range limitPeriods {
+		if period.Name == name {
+			return period.Window
+		}
+	}
+	return 0
+}
+
+func beneficiaryLabel(bl BeneficiaryLimit) string {
+	if bl.RecipientName != "" {
+		return bl.RecipientName
+	}
+	return bl.RecipientCode
+}
+
+// resolveBeneficiary maps a recipient code or cached recipient name to a recipient code
+func resolveBeneficiary(identifier string) (string, error) {
+	var code string
+	err := database.DB.QueryRow("SELECT recipient_code FROM recipients WHERE recipient_code = ?", identifier).Scan(&code)
+	if err == nil {
+		return code, nil
+	}
+
+	rows, err := database.DB.Query(
+		"SELECT recipient_code FROM recipients WHERE LOWER(name) = LOWER(?) OR LOWER(name) LIKE LOWER(?)",
+		identifier, "%"+identifier+"%",
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up recipient: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []string
+	for rows.Next() {
+		if err := rows.Scan(&code); err == nil {
+			matches = append(matches, code)
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		// Uncached recipient codes can still be capped
+		if strings.HasPrefix(identifier, "RCP_") {
+			return identifier, nil
+		}
+		return "", fmt.Errorf("recipient not found: %s", identifier)
+	default:
+		return "", fmt.Errorf("%q matches %d recipients, use a recipient_code", identifier, len(matches))
+	}
+}
+
+// parseLimitCurrency validates the currency a limit is set in; an empty code is the default
+func parseLimitCurrency(code string) (money.Currency, error) {
+	currency, err := money.ParseCurrency(code)
+	if err != nil {
+		codes := make([]string, 0, len(money.Currencies()))
+		for _, c := range money.Currencies() {
+			codes = append(codes, string(c))
+		}
+		return "", fmt.Errorf("currency must be one of: %s", strings.Join(codes, ", "))
+	}
+	return currency, nil
+}
+
+// GetAccount returns the account-wide transfer caps with current rolling usage
+func (h *LimitHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	limits, err := GetAccountLimits()
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	usage := map[string]money.Amount{}
+	for _, period := range limitPeriods {
+		used, err := outgoingTotal("", limits.Currency, now.Add(-period.Window))
+		if err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+		usage[period.Name] = used
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"limits": limits,
+		"usage":  usage,
+	})
+}
+
+// SetAccount updates the account-wide caps; omitted fields keep their current value
+func (h *LimitHandler) SetAccount(w http.ResponseWriter, r *http.Request) {
+	var req SetAccountLimitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	limits, err := GetAccountLimits()
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	fields := []struct {
+		name  string
+		value *money.Amount
+		dest  *money.Amount
+	}{
+		{"per_transaction_limit", req.PerTransactionLimit, &limits.PerTransactionLimit},
+		{"daily_transfer_limit", req.DailyTransferLimit, &limits.DailyTransferLimit},
+		{"weekly_transfer_limit", req.WeeklyTransferLimit, &limits.WeeklyTransferLimit},
+		{"monthly_transfer_limit", req.MonthlyTransferLimit, &limits.MonthlyTransferLimit},
+		{"balance_limit", req.BalanceLimit, &limits.BalanceLimit},
+	}
+
+	updated := false
+	for _, f := range fields {
+		if f.value == nil {
+			continue
+		}
+		if *f.value < 0 {
+			WriteJSONBadRequest(w, fmt.Sprintf("%s cannot be negative", f.name))
+			return
+		}
+		*f.dest = *f.value
+		updated = true
+	}
+
+	if req.Currency != "" {
+		currency, err := parseLimitCurrency(req.Currency)
+		if err != nil {
+			WriteJSONBadRequest(w, err.Error())
+			return
+		}
+		limits.Currency = currency
+		updated = true
+	}
+
+	if !updated {
+		WriteJSONBadRequest(w, "No limits to update")
+		return
+	}
+
+	// Windows nest, so a shorter cap above a longer one can never be reached
+	if limits.DailyTransferLimit > 0 && limits.WeeklyTransferLimit > 0 && limits.DailyTransferLimit > limits.WeeklyTransferLimit {
+		WriteJSONBadRequest(w, "daily_transfer_limit cannot exceed weekly_transfer_limit")
+		return
+	}
+	if limits.WeeklyTransferLimit > 0 && limits.MonthlyTransferLimit > 0 && limits.WeeklyTransferLimit > limits.MonthlyTransferLimit {
+		WriteJSONBadRequest(w, "weekly_transfer_limit cannot exceed monthly_transfer_limit")
+		return
+	}
+
+	limits.UpdatedAt = time.Now()
+	_, err = database.DB.Exec(`
+		INSERT INTO account_limits (
+			id, per_transaction_limit, daily_transfer_limit, weekly_transfer_limit,
+			monthly_transfer_limit, balance_limit, currency, created_at, updated_at
+		) VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			per_transaction_limit = excluded.per_transaction_limit,
+			daily_transfer_limit = excluded.daily_transfer_limit,
+			weekly_transfer_limit = excluded.weekly_transfer_limit,
+			monthly_transfer_limit = excluded.monthly_transfer_limit,
+			balance_limit = excluded.balance_limit,
+			currency = excluded.currency,
+			updated_at = excluded.updated_at
+	`,
+		limits.PerTransactionLimit,
+		limits.DailyTransferLimit,
+		limits.WeeklyTransferLimit,
+		limits.MonthlyTransferLimit,
+		limits.BalanceLimit,
+		limits.Currency,
+		limits.UpdatedAt,
+		limits.UpdatedAt,
+	)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to save account limits: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	WriteJSONSuccessWithMessage(w, "Account limits updated", limits)
+}
+
+// SetBeneficiary creates or replaces the cap for a recipient and period
+func (h *LimitHandler) SetBeneficiary(w http.ResponseWriter, r *http.Request) {
+	var req SetBeneficiaryLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	identifier := req.RecipientCode
+	if identifier == "" {
+		identifier = req.BeneficiaryID
+	}
+	if identifier == "" {
+		WriteJSONBadRequest(w, "recipient_code or beneficiary_id is required")
+		return
+	}
+
+	if periodWindow(req.Period) == 0 {
+		WriteJSONBadRequest(w, "period must be one of: daily, weekly, monthly")
+		return
+	}
+
+	if req.AmountLimit <= 0 {
+		WriteJSONBadRequest(w, "amount_limit must be greater than 0")
+		return
+	}
+
+	currency, err := parseLimitCurrency(req.Currency)
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	if req.AlertsAtPercent == 0 {
+		req.AlertsAtPercent = 80
+	}
+	if req.AlertsAtPercent < 0 || req.AlertsAtPercent > 100 {
+		WriteJSONBadRequest(w, "alerts_at_percent must be between 1 and 100")
+		return
+	}
+
+	recipientCode, err := resolveBeneficiary(identifier)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	_, err = database.DB.Exec(`
+		INSERT INTO beneficiary_limits (recipient_code, period, amount_limit, currency, alerts_at_percent, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(recipient_code, period) DO UPDATE SET
+			amount_limit = excluded.amount_limit,
+			currency = excluded.currency,
+			alerts_at_percent = excluded.alerts_at_percent,
+			updated_at = excluded.updated_at
+	`, recipientCode, req.Period, req.AmountLimit, currency, req.AlertsAtPercent, now, now)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to save beneficiary limit: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	limits, err := listBeneficiaryLimits(recipientCode)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	for _, bl := range limits {
+		if bl.Period == req.Period {
+			WriteJSONSuccessWithMessage(w, "Beneficiary limit saved", bl)
+			return
+		}
+	}
+	WriteJSONSuccessWithMessage(w, "Beneficiary limit saved", nil)
+}
+
+// ListBeneficiaries lists beneficiary caps, optionally filtered by ?recipient_code=
+func (h *LimitHandler) ListBeneficiaries(w http.ResponseWriter, r *http.Request) {
+	limits, err := listBeneficiaryLimits(r.URL.Query().Get("recipient_code"))
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"limits": limits,
+		"count":  len(limits),
+	})
+}
+
+// DeleteBeneficiary removes the cap for a recipient and period
+func (h *LimitHandler) DeleteBeneficiary(w http.ResponseWriter, r *http.Request) {
+	recipientCode := chi.URLParam(r, "recipient_code")
+	period := chi.URLParam(r, "period")
+
+	result, err := database.DB.Exec(
+		"DELETE FROM beneficiary_limits WHERE recipient_code = ? AND period = ?",
+		recipientCode, period,
+	)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to delete beneficiary limit: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		WriteJSONError(w, fmt.Errorf("no %s limit for %s", period, recipientCode), http.StatusNotFound)
+		return
+	}
+
+	WriteJSONSuccessWithMessage(w, "Beneficiary limit removed", nil)
+}
+
+// Check reports whether a payment would pass all caps without executing it
+func (h *LimitHandler) Check(w http.ResponseWriter, r *http.Request) {
+	var req CheckTransferLimitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.RecipientCode == "" || req.Amount <= 0 {
+		WriteJSONBadRequest(w, "recipient_code and a positive amount are required")
+		return
+	}
+
+	check, err := CheckTransferLimits(req.RecipientCode, req.Money)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	WriteJSONSuccess(w, check)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// setAccountLimits sets the account caps in NGN; 0 means no limit
+func setAccountLimits(t *testing.T, perTransaction, daily, weekly, monthly money.Amount) {
+	t.Helper()
+	_, err := database.DB.Exec(`
+		INSERT OR REPLACE INTO account_limits (id, per_transaction_limit, daily_transfer_limit, weekly_transfer_limit, monthly_transfer_limit, currency, created_at, updated_at)
+		VALUES (1, ?, ?, ?, ?, 'NGN', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, perTransaction, daily, weekly, monthly)
+	if err != nil {
+		t.Fatalf("Failed to set account limits: %v", err)
+	}
+}
+
+// setBeneficiaryLimit caps payments to recipientCode over period in NGN
+func setBeneficiaryLimit(t *testing.T, recipientCode, period string, limit money.Amount) {
+	t.Helper()
+	_, err := database.DB.Exec(`
+		INSERT INTO beneficiary_limits (recipient_code, period, amount_limit, currency, alerts_at_percent, created_at, updated_at)
+		VALUES (?, ?, ?, 'NGN', 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, recipientCode, period, limit)
+	if err != nil {
+		t.Fatalf("Failed to set beneficiary limit: %v", err)
+	}
+}
+
+// recordOutgoing records a payment already sent to recipientCode, ago before now
+func recordOutgoing(t *testing.T, recipientCode string, amount money.Amount, ago time.Duration) {
+	t.Helper()
+	_, err := database.DB.Exec(
+		"INSERT INTO outgoing_payments (recipient_code, amount, currency, source, created_at) VALUES (?, ?, 'NGN', 'transfer', ?)",
+		recipientCode, amount, time.Now().Add(-ago),
+	)
+	if err != nil {
+		t.Fatalf("Failed to record outgoing payment: %v", err)
+	}
+}
+
+func TestAccountLimitWindows(t *testing.T) {
+	const limit = money.Amount(1000000)
+	tests := []struct {
+		name string
+		// limits are per-transaction, daily, weekly and monthly
+		limits     [4]money.Amount
+		sent       money.Amount
+		sentAgo    time.Duration
+		amount     money.Amount
+		wantPeriod string
+	}{
+		{"per-transaction at the cap", [4]money.Amount{limit, 0, 0, 0}, 0, 0, limit, ""},
+		{"per-transaction over the cap", [4]money.Amount{limit, 0, 0, 0}, 0, 0, limit + 1, "per_transaction"},
+		{"daily up to the cap", [4]money.Amount{0, limit, 0, 0}, 400000, time.Hour, 600000, ""},
+		{"daily over the cap", [4]money.Amount{0, limit, 0, 0}, 400000, time.Hour, 600001, "daily"},
+		{"daily counts just inside the window", [4]money.Amount{0, limit, 0, 0}, 400000, 24*time.Hour - time.Minute, 600001, "daily"},
+		{"daily forgets just outside the window", [4]money.Amount{0, limit, 0, 0}, 400000, 24*time.Hour + time.Minute, 600001, ""},
+		{"weekly up to the cap", [4]money.Amount{0, 0, limit, 0}, 400000, 3 * 24 * time.Hour, 600000, ""},
+		{"weekly over the cap", [4]money.Amount{0, 0, limit, 0}, 400000, 3 * 24 * time.Hour, 600001, "weekly"},
+		{"weekly counts just inside the window", [4]money.Amount{0, 0, limit, 0}, 400000, 7*24*time.Hour - time.Minute, 600001, "weekly"},
+		{"weekly forgets just outside the window", [4]money.Amount{0, 0, limit, 0}, 400000, 7*24*time.Hour + time.Minute, 600001, ""},
+		{"monthly counts just inside the window", [4]money.Amount{0, 0, 0, limit}, 400000, 30*24*time.Hour - time.Minute, 600001, "monthly"},
+		{"monthly forgets just outside the window", [4]money.Amount{0, 0, 0, limit}, 400000, 30*24*time.Hour + time.Minute, 600001, ""},
+		{"daily is clear but weekly isn't", [4]money.Amount{0, limit, limit, 0}, 900000, 2 * 24 * time.Hour, 200000, "weekly"},
+		{"payments to anyone count", [4]money.Amount{0, limit, 0, 0}, 900000, time.Hour, 200000, "daily"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandlerDB(t)
+			setAccountLimits(t, tt.limits[0], tt.limits[1], tt.limits[2], tt.limits[3])
+			if tt.sent > 0 {
+				recordOutgoing(t, "RCP_someone_else", tt.sent, tt.sentAgo)
+			}
+
+			check, err := CheckTransferLimits(defaultRecipientCode, money.New(int64(tt.amount), money.NGN))
+			if err != nil {
+				t.Fatalf("Failed to check limits: %v", err)
+			}
+			if want := tt.wantPeriod == ""; check.Allowed != want {
+				t.Fatalf("Expected allowed %v, got %v: %s", want, check.Allowed, check.Reason)
+			}
+			if tt.wantPeriod != "" && check.Violations[0].Period != tt.wantPeriod {
+				t.Errorf("Expected the %s limit to be broken, got %+v", tt.wantPeriod, check.Violations)
+			}
+		})
+	}
+}
+
+func TestBeneficiaryLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		period    string
+		recipient string
+		sent      money.Amount
+		sentAgo   time.Duration
+		amount    money.Amount
+		wantAllow bool
+	}{
+		{"up to the cap", "daily", defaultRecipientCode, 400000, time.Hour, 600000, true},
+		{"over the cap", "daily", defaultRecipientCode, 400000, time.Hour, 600001, false},
+		{"payments to others don't count", "daily", "RCP_someone_else", 900000, time.Hour, 600000, true},
+		{"forgets payments outside the window", "daily", defaultRecipientCode, 900000, 24*time.Hour + time.Minute, 600000, true},
+		{"weekly counts the week", "weekly", defaultRecipientCode, 900000, 3 * 24 * time.Hour, 200000, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandlerDB(t)
+			setBeneficiaryLimit(t, defaultRecipientCode, tt.period, 1000000)
+			recordOutgoing(t, tt.recipient, tt.sent, tt.sentAgo)
+
+			check, err := CheckTransferLimits(defaultRecipientCode, money.New(int64(tt.amount), money.NGN))
+			if err != nil {
+				t.Fatalf("Failed to check limits: %v", err)
+			}
+			if check.Allowed != tt.wantAllow {
+				t.Fatalf("Expected allowed %v, got %v: %s", tt.wantAllow, check.Allowed, check.Reason)
+			}
+			if !tt.wantAllow && check.Violations[0].Scope != "beneficiary" {
+				t.Errorf("Expected the beneficiary limit to be broken, got %+v", check.Violations)
+			}
+		})
+	}
+
+	t.Run("other recipients aren't capped", func(t *testing.T) {
+		setupHandlerDB(t)
+		setBeneficiaryLimit(t, "RCP_someone_else", "daily", 100)
+		check, err := CheckTransferLimits(defaultRecipientCode, money.New(500000, money.NGN))
+		if err != nil || !check.Allowed {
+			t.Errorf("Expected the payment to be allowed, got %+v, %v", check, err)
+		}
+	})
+}
+
+func TestLimitsInAnotherCurrencyRejectThePayment(t *testing.T) {
+	setupHandlerDB(t)
+	setAccountLimits(t, 0, 1000000, 0, 0)
+
+	check, err := CheckTransferLimits(defaultRecipientCode, money.New(100, money.USD))
+	if err != nil {
+		t.Fatalf("Failed to check limits: %v", err)
+	}
+	if check.Allowed {
+		t.Errorf("Expected a USD payment not to pass an NGN cap unchecked")
+	}
+}
+
+func TestReserveAndReleaseOutgoingPayment(t *testing.T) {
+	setupHandlerDB(t)
+	setAccountLimits(t, 0, 1000000, 0, 0)
+	payment := money.New(600000, money.NGN)
+
+	first, check, err := ReserveOutgoingPayment(defaultRecipientCode, payment, "transfer", "first")
+	if err != nil || !check.Allowed {
+		t.Fatalf("Expected the first payment to be reserved, got %+v, %v", check, err)
+	}
+	if _, check, err := ReserveOutgoingPayment(defaultRecipientCode, payment, "transfer", "second"); err != nil || check.Allowed {
+		t.Fatalf("Expected the reservation to count against the limit, got %+v, %v", check, err)
+	}
+
+	ReleaseOutgoingPayment(first)
+	if _, check, err := ReserveOutgoingPayment(defaultRecipientCode, payment, "transfer", "second"); err != nil || !check.Allowed {
+		t.Errorf("Expected the released payment to stop counting, got %+v, %v", check, err)
+	}
+	if got := reservations(t); got != 1 {
+		t.Errorf("Expected one reservation, got %d", got)
+	}
+}
+
+func TestCancelledOrFailedExpenseReleasesItsReservation(t *testing.T) {
+	for _, status := range []string{"cancelled", "failed", "paid"} {
+		t.Run(status, func(t *testing.T) {
+			setupHandlerDB(t)
+			id, _, err := ReserveOutgoingPayment(defaultRecipientCode, money.New(500000, money.NGN), "expense", "Rent")
+			if err != nil {
+				t.Fatalf("Failed to reserve: %v", err)
+			}
+			SetOutgoingPaymentReference(id, "EXP_rent")
+			result, err := database.DB.Exec(
+				"INSERT INTO expenses (recipient_code, recipient_name, amount, currency, narration, reference, status) VALUES (?, 'Service provider', 500000, 'NGN', 'Rent', 'EXP_rent', 'pending')",
+				defaultRecipientCode,
+			)
+			if err != nil {
+				t.Fatalf("Failed to add expense: %v", err)
+			}
+			expenseID, _ := result.LastInsertId()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", strconv.FormatInt(expenseID, 10))
+			req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"status":"`+status+`"}`))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rec := httptest.NewRecorder()
+			NewExpenseHandler().Update(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected the expense to be updated, got %d: %s", rec.Code, rec.Body)
+			}
+
+			want := 0
+			if status == "paid" {
+				want = 1
+			}
+			if got := reservations(t); got != want {
+				t.Errorf("Expected %d reservations, got %d", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,529 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Recipient Groups Handler - Paying Several People Together
+//
+// OBJECTIVES:
+// Users say "pay the cleaning team", not a list of account names, and each
+// member is usually paid the same rate every time.
+//
+// PURPOSE:
+// - Keep named groups of recipients, each with a default amount
+// - Let members have their own amount where their rate differs
+// - Resolve a spoken group name for bulk payouts, which expand the group into one
+//   payment per member (see bulk_transfers.go)
+//
+// KEY WORKFLOW:
+// Create Group → Add Members (by code, name or alias) → "Pay the cleaning team" →
+// Resolve Group → Expand to Payments → Preview → Confirm → Pay Each Member
+//
+// DESIGN DECISIONS:
+// - Expansion lives in internal/payout; this file stores groups and resolves names
+// - Group names are unique ignoring case and can't be a recipient's alias, so a
+//   spoken name means either one recipient or one group, never both
+// - Members are added by recipient code, name or alias, resolved like any spoken name
+// - Amounts are in the group's currency; a member's own amount wins over the group's
+// - Group names are matched like recipient names, so a misheard name still finds
+//   the group, and close matches are refused with a question rather than guessed
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+	"paystack.mpc.proxy/internal/payout"
+	"paystack.mpc.proxy/internal/resolve"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RecipientGroupHandler struct{}
+
+func NewRecipientGroupHandler() *RecipientGroupHandler {
+	return &RecipientGroupHandler{}
+}
+
+// RecipientGroup is a named set of recipients paid together
+type RecipientGroup struct {
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Currency    money.Currency `json:"currency"`
+	// DefaultAmount is paid to members without their own amount
+	DefaultAmount *money.Amount          `json:"default_amount,omitempty"`
+	Members       []RecipientGroupMember `json:"members"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// RecipientGroupMember is a recipient in a group
+type RecipientGroupMember struct {
+	RecipientCode string `json:"recipient_code"`
+	RecipientName string `json:"recipient_name"`
+	BankName      string `json:"bank_name,omitempty"`
+	// Amount is the member's own rate, used instead of the group's default
+	Amount    *money.Amount `json:"amount,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// RecipientGroupRequest creates or updates a group; omitted fields keep their value on update
+type RecipientGroupRequest struct {
+	Name          *string         `json:"name,omitempty"`
+	Description   *string         `json:"description,omitempty"`
+	Currency      *money.Currency `json:"currency,omitempty"`
+	DefaultAmount *money.Amount   `json:"default_amount,omitempty"`
+	// Members are added when the group is created
+	Members []GroupMemberRequest `json:"members,omitempty"`
+}
+
+// GroupMemberRequest adds a member, or changes a member's amount
+type GroupMemberRequest struct {
+	// Recipient is a recipient code, or a name or alias as the user said it
+	Recipient string        `json:"recipient"`
+	Amount    *money.Amount `json:"amount,omitempty"`
+}
+
+// errGroupNameTaken is returned for a group name already used by a group or an alias
+var errGroupNameTaken = errors.New("group name is already in use")
+
+// apply copies the request's fields onto a group and checks the result
+func (req RecipientGroupRequest) apply(g *RecipientGroup) error {
+	if req.Name != nil {
+		g.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		g.Description = *req.Description
+	}
+	if req.Currency != nil {
+		g.Currency = *req.Currency
+	}
+	g.Currency = g.Currency.OrDefault()
+	if req.DefaultAmount != nil {
+		g.DefaultAmount = req.DefaultAmount
+		if *g.DefaultAmount <= 0 {
+			g.DefaultAmount = nil
+		}
+	}
+	if g.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	return nil
+}
+
+// Create creates a group, optionally with its members
+func (h *RecipientGroupHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req RecipientGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	var group RecipientGroup
+	if err := req.apply(&group); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	if !checkGroupName(w, group.Name, 0) {
+		return
+	}
+
+	// Resolve every member before creating anything, so a bad name creates nothing
+	members := make([]groupMember, 0, len(req.Members))
+	for _, m := range req.Members {
+		member, ok := resolveGroupMember(w, m)
+		if !ok {
+			return
+		}
+		members = append(members, member)
+	}
+
+	now := time.Now()
+	result, err := database.DB.Exec(`
+		INSERT INTO recipient_groups (name, description, currency, default_amount, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, group.Name, group.Description, group.Currency, group.DefaultAmount, now, now)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to create group: %w", err), http.StatusInternalServerError)
+		return
+	}
+	id, _ := result.LastInsertId()
+
+	for _, m := range members {
+		if err := setGroupMember(int(id), m); err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	created, err := loadRecipientGroup(int(id))
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, "Group created", created)
+}
+
+// List lists groups with their members
+func (h *RecipientGroupHandler) List(w http.ResponseWriter, r *http.Request) {
+	rows, err := database.DB.Query("SELECT id FROM recipient_groups ORDER BY name")
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to query groups: %w", err), http.StatusInternalServerError)
+		return
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			WriteJSONError(w, fmt.Errorf("failed to scan group: %w", err), http.StatusInternalServerError)
+			return
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	groups := []RecipientGroup{}
+	for _, id := range ids {
+		group, err := loadRecipientGroup(id)
+		if err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+		groups = append(groups, *group)
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"groups": groups,
+		"count":  len(groups),
+	})
+}
+
+// Get returns a group with its members
+func (h *RecipientGroupHandler) Get(w http.ResponseWriter, r *http.Request) {
+	group, ok := groupFromURL(w, r)
+	if !ok {
+		return
+	}
+	WriteJSONSuccess(w, group)
+}
+
+// Update renames a group or changes its description, currency or default amount
+func (h *RecipientGroupHandler) Update(w http.ResponseWriter, r *http.Request) {
+	group, ok := groupFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var req RecipientGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+	if len(req.Members) > 0 {
+		WriteJSONBadRequest(w, "members can't be changed here: use the group's members endpoints")
+		return
+	}
+	if err := req.apply(group); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	if !checkGroupName(w, group.Name, group.ID) {
+		return
+	}
+
+	_, err := database.DB.Exec(`
+		UPDATE recipient_groups
+		SET name = ?, description = ?, currency = ?, default_amount = ?, updated_at = ?
+		WHERE id = ?
+	`, group.Name, group.Description, group.Currency, group.DefaultAmount, time.Now(), group.ID)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to update group: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := loadRecipientGroup(group.ID)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, "Group updated", updated)
+}
+
+// Delete removes a group. Its members stay as recipients.
+func (h *RecipientGroupHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	group, ok := groupFromURL(w, r)
+	if !ok {
+		return
+	}
+	if _, err := database.DB.Exec("DELETE FROM recipient_group_members WHERE group_id = ?", group.ID); err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to delete group members: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if _, err := database.DB.Exec("DELETE FROM recipient_groups WHERE id = ?", group.ID); err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to delete group: %w", err), http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, "Group deleted", map[string]interface{}{"id": group.ID})
+}
+
+// SetMember adds a recipient to a group, or changes a member's amount
+func (h *RecipientGroupHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	group, ok := groupFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var req GroupMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+	member, ok := resolveGroupMember(w, req)
+	if !ok {
+		return
+	}
+	if err := setGroupMember(group.ID, member); err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := loadRecipientGroup(group.ID)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, "Group member saved", updated)
+}
+
+// RemoveMember takes a recipient out of a group
+func (h *RecipientGroupHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	group, ok := groupFromURL(w, r)
+	if !ok {
+		return
+	}
+	code := chi.URLParam(r, "recipient_code")
+	result, err := database.DB.Exec("DELETE FROM recipient_group_members WHERE group_id = ? AND recipient_code = ?", group.ID, code)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to remove group member: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		WriteJSONError(w, fmt.Errorf("%s is not in group %q", code, group.Name), http.StatusNotFound)
+		return
+	}
+
+	updated, err := loadRecipientGroup(group.ID)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, "Group member removed", updated)
+}
+
+// groupMember is a member ready to store
+type groupMember struct {
+	recipientCode string
+	amount        *money.Amount
+}
+
+// resolveGroupMember finds the recipient a member request names.
+// It writes the error response and returns false when it can't.
+func resolveGroupMember(w http.ResponseWriter, req GroupMemberRequest) (groupMember, bool) {
+	if strings.TrimSpace(req.Recipient) == "" {
+		WriteJSONBadRequest(w, "recipient is required for each member")
+		return groupMember{}, false
+	}
+	if req.Amount != nil && *req.Amount < 0 {
+		WriteJSONBadRequest(w, "member amount can't be negative")
+		return groupMember{}, false
+	}
+
+	code, resolution, err := resolveRecipientReference(req.Recipient)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return groupMember{}, false
+	}
+	if code == "" {
+		writeUnresolvedRecipient(w, resolution)
+		return groupMember{}, false
+	}
+
+	amount := req.Amount
+	if amount != nil && *amount == 0 {
+		amount = nil
+	}
+	return groupMember{recipientCode: code, amount: amount}, true
+}
+
+// setGroupMember adds a member, or replaces the amount of an existing one
+func setGroupMember(groupID int, m groupMember) error {
+	_, err := database.DB.Exec(`
+		INSERT INTO recipient_group_members (group_id, recipient_code, amount)
+		VALUES (?, ?, ?)
+		ON CONFLICT(group_id, recipient_code) DO UPDATE SET amount = excluded.amount
+	`, groupID, m.recipientCode, m.amount)
+	if err != nil {
+		return fmt.Errorf("failed to save group member: %w", err)
+	}
+	_, err = database.DB.Exec("UPDATE recipient_groups SET updated_at = ? WHERE id = ?", time.Now(), groupID)
+	return err
+}
+
+// checkGroupName refuses a name another group (other than exceptID) or an alias already uses.
+// It writes the error response and returns false when the name is taken.
+func checkGroupName(w http.ResponseWriter, name string, exceptID int) bool {
+	var groups, aliases int
+	err := database.DB.QueryRow("SELECT COUNT(*) FROM recipient_groups WHERE name = ? AND id != ?", name, exceptID).Scan(&groups)
+	if err == nil {
+		err = database.DB.QueryRow("SELECT COUNT(*) FROM recipient_aliases WHERE alias = ? COLLATE NOCASE", name).Scan(&aliases)
+	}
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to check group name: %w", err), http.StatusInternalServerError)
+		return false
+	}
+	switch {
+	case groups > 0:
+		WriteJSONError(w, fmt.Errorf("%w: a group is already called %q", errGroupNameTaken, name), http.StatusConflict)
+		return false
+	case aliases > 0:
+		WriteJSONError(w, fmt.Errorf("%w: %q is a recipient's alias", errGroupNameTaken, name), http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+// groupFromURL loads the group named by the {id} URL parameter.
+// It writes the error response and returns false when there is none.
+func groupFromURL(w http.ResponseWriter, r *http.Request) (*RecipientGroup, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteJSONBadRequest(w, "invalid group id")
+		return nil, false
+	}
+	group, err := loadRecipientGroup(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		WriteJSONError(w, fmt.Errorf("group not found: %d", id), http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return nil, false
+	}
+	return group, true
+}
+
+// loadRecipientGroup loads a group and its members. A missing group is sql.ErrNoRows.
+func loadRecipientGroup(id int) (*RecipientGroup, error) {
+	g := RecipientGroup{Members: []RecipientGroupMember{}}
+	var description sql.NullString
+	var defaultAmount sql.NullInt64
+	err := database.DB.QueryRow(`
+		SELECT id, name, description, COALESCE(currency, 'NGN'), default_amount, created_at, updated_at
+		FROM recipient_groups WHERE id = ?
+	`, id).Scan(&g.ID, &g.Name, &description, &g.Currency, &defaultAmount, &g.CreatedAt, &g.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load group: %w", err)
+	}
+	g.Description = description.String
+	if defaultAmount.Valid {
+		a := money.Amount(defaultAmount.Int64)
+		g.DefaultAmount = &a
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT m.recipient_code, COALESCE(r.name, m.recipient_code), COALESCE(r.bank_name, ''), m.amount, m.created_at
+		FROM recipient_group_members m
+		LEFT JOIN recipients r ON r.recipient_code = m.recipient_code
+		WHERE m.group_id = ?
+		ORDER BY m.id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load group members: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m RecipientGroupMember
+		var amount sql.NullInt64
+		if err := rows.Scan(&m.RecipientCode, &m.RecipientName, &m.BankName, &amount, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan group member: %w", err)
+		}
+		if amount.Valid {
+			a := money.Amount(amount.Int64)
+			m.Amount = &a
+		}
+		g.Members = append(g.Members, m)
+	}
+	return &g, rows.Err()
+}
+
+// resolveRecipientGroup finds the group a reference means: an id, or a name as the
+// user said it. A name that is close to several groups, or only loosely matches
+// one, returns the question to ask instead; no match at all returns sql.ErrNoRows.
+func resolveRecipientGroup(ref string) (*RecipientGroup, string, error) {
+	ref = strings.TrimSpace(ref)
+	if id, err := strconv.Atoi(ref); err == nil {
+		group, err := loadRecipientGroup(id)
+		return group, "", err
+	}
+
+	rows, err := database.DB.Query("SELECT id, name FROM recipient_groups")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query groups: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []resolve.Candidate
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, "", fmt.Errorf("failed to scan group: %w", err)
+		}
+		if strings.EqualFold(name, ref) {
+			group, err := loadRecipientGroup(id)
+			return group, "", err
+		}
+		candidates = append(candidates, resolve.Candidate{RecipientCode: strconv.Itoa(id), Name: name})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error iterating groups: %w", err)
+	}
+
+	result := resolve.Resolve(ref, candidates, resolve.Config{})
+	switch result.Status {
+	case resolve.StatusResolved:
+		id, _ := strconv.Atoi(result.Matches[0].RecipientCode)
+		group, err := loadRecipientGroup(id)
+		return group, "", err
+	case resolve.StatusNotFound:
+		return nil, "", sql.ErrNoRows
+	}
+	return nil, result.Question, nil
+}
+
+// payoutGroup is a group as internal/payout expands it
+func payoutGroup(g *RecipientGroup) payout.Group {
+	pg := payout.Group{Name: g.Name, Currency: g.Currency}
+	if g.DefaultAmount != nil {
+		pg.DefaultAmount = *g.DefaultAmount
+	}
+	for _, m := range g.Members {
+		member := payout.Member{RecipientCode: m.RecipientCode, Name: m.RecipientName}
+		if m.Amount != nil {
+			member.Amount = *m.Amount
+		}
+		pg.Members = append(pg.Members, member)
+	}
+	return pg
+}
@@ -0,0 +1,394 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Recipient Resolution Handler - Spoken Names to Recipients
+//
+// OBJECTIVES:
+// Voice transcripts mangle names, and recipient search only matches exact or
+// substring text, so "pay Jon Doh" found nobody.
+//
+// PURPOSE:
+// - Work out which recipient a spoken name means, with a confidence score
+// - Match by sound and spelling, by aliases ("landlord"), and favour recipients
+//   paid often
+// - Return a ready-made question when several recipients are close, so the agent
+//   can ask "did you mean John Doe at GTBank or John Doh at Access?"
+//
+// KEY WORKFLOW:
+// Spoken Name → Load Recipients, Aliases and Recent Payments → Rank →
+// Resolved | Ambiguous (ask) | Uncertain (confirm) | Not Found
+//
+// DESIGN DECISIONS:
+// - Ranking lives in internal/resolve; this file loads the candidates
+// - Every cached recipient is a candidate: the cache is small, and a mangled name
+//   can't be narrowed in SQL
+// - Recent payments are counted from outgoing_payments over resolveFrequencyWindow,
+//   so every way of paying someone counts
+// - Aliases are given when a recipient is created or added later, and kept in
+//   recipient_aliases; an alias belongs to one recipient only, and can't be a
+//   group's name, so "pay the landlord" can only mean one thing
+// - Expenses and bulk payouts accept a name or alias in place of a recipient code
+//   and resolve it here; anything short of resolved is refused with the question
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/resolve"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// resolveFrequencyWindow is how far back payments count towards how often a recipient is paid
+const resolveFrequencyWindow = 90 * 24 * time.Hour
+
+// ResolvedRecipient is a recipient that may be the one meant
+type ResolvedRecipient struct {
+	Recipient
+	Aliases []string `json:"aliases,omitempty"`
+	// Confidence is from 0 to 1
+	Confidence float64 `json:"confidence"`
+	// MatchedOn is the name or alias that matched best
+	MatchedOn string `json:"matched_on"`
+	// RecentPayments is how many times the recipient was paid in the last 90 days
+	RecentPayments int `json:"recent_payments"`
+}
+
+// ResolveRecipientResponse is the outcome of resolving a spoken name
+type ResolveRecipientResponse struct {
+	Query string `json:"query"`
+	// Status is resolved, ambiguous, uncertain or not_found
+	Status string `json:"status"`
+	// Recipient is the one meant, when resolved
+	Recipient *ResolvedRecipient `json:"recipient,omitempty"`
+	// Confidence is the best candidate's
+	Confidence float64 `json:"confidence"`
+	// Candidates are the possible recipients, best first
+	Candidates []ResolvedRecipient `json:"candidates"`
+	// Question asks the user to confirm or choose, when not resolved
+	Question string `json:"question,omitempty"`
+}
+
+// Resolve works out which recipient a spoken name means. Query: name (required).
+func (h *RecipientHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if name == "" {
+		WriteJSONBadRequest(w, "name query parameter is required")
+		return
+	}
+
+	response, err := resolveRecipient(name)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccess(w, response)
+}
+
+// resolveRecipient ranks every cached recipient against a spoken name
+func resolveRecipient(name string) (ResolveRecipientResponse, error) {
+	recipients, err := loadResolveCandidates()
+	if err != nil {
+		return ResolveRecipientResponse{}, err
+	}
+
+	candidates := make([]resolve.Candidate, 0, len(recipients))
+	byCode := map[string]ResolvedRecipient{}
+	for _, rr := range recipients {
+		candidates = append(candidates, resolve.Candidate{
+			RecipientCode:  rr.RecipientCode,
+			Name:           rr.Name,
+			BankName:       rr.BankName,
+			AccountNumber:  rr.AccountNumber,
+			Aliases:        rr.Aliases,
+			RecentPayments: rr.RecentPayments,
+		})
+		byCode[rr.RecipientCode] = rr
+	}
+
+	result := resolve.Resolve(name, candidates, resolve.Config{})
+
+	response := ResolveRecipientResponse{
+		Query:      name,
+		Status:     result.Status,
+		Candidates: []ResolvedRecipient{},
+		Question:   result.Question,
+	}
+	for _, m := range result.Matches {
+		rr := byCode[m.RecipientCode]
+		rr.Confidence = m.Confidence
+		rr.MatchedOn = m.MatchedOn
+		response.Candidates = append(response.Candidates, rr)
+	}
+	if len(response.Candidates) > 0 {
+		response.Confidence = response.Candidates[0].Confidence
+		if result.Status == resolve.StatusResolved {
+			response.Recipient = &response.Candidates[0]
+		}
+	}
+	return response, nil
+}
+
+// resolveRecipientReference finds the recipient a reference means: a recipient
+// code, or a name or alias as the user said it. When the name doesn't resolve to
+// one recipient, the code is empty and the resolution says why.
+func resolveRecipientReference(ref string) (string, *ResolveRecipientResponse, error) {
+	ref = strings.TrimSpace(ref)
+	code, err := payableRecipientCode(ref)
+	if err == nil {
+		return code, nil, nil
+	}
+	if errors.Is(err, errRecipientDeleted) {
+		return "", &ResolveRecipientResponse{Query: ref, Status: resolve.StatusNotFound}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", nil, fmt.Errorf("failed to look up recipient: %w", err)
+	}
+
+	resolution, err := resolveRecipient(ref)
+	if err != nil {
+		return "", nil, err
+	}
+	if resolution.Recipient == nil {
+		return "", &resolution, nil
+	}
+	return resolution.Recipient.RecipientCode, &resolution, nil
+}
+
+// writeUnresolvedRecipient refuses a request whose recipient name didn't resolve,
+// returning the candidates and the question to ask
+func writeUnresolvedRecipient(w http.ResponseWriter, resolution *ResolveRecipientResponse) {
+	if resolution.Status == resolve.StatusNotFound {
+		WriteJSONError(w, fmt.Errorf("recipient not found: %s", resolution.Query), http.StatusNotFound)
+		return
+	}
+	respondWithJSON(w, http.StatusConflict, map[string]interface{}{
+		"status":  false,
+		"message": resolution.Question,
+		"error":   fmt.Sprintf("recipient %q is %s", resolution.Query, resolution.Status),
+		"data":    resolution,
+	})
+}
+
+// loadResolveCandidates loads every cached recipient with its aliases and recent payment count
+func loadResolveCandidates() ([]ResolvedRecipient, error) {
+	rows, err := database.DB.Query(`
+		SELECT `+recipientColumns+`,
+		       (SELECT COUNT(*) FROM outgoing_payments op WHERE op.recipient_code = r.recipient_code AND op.created_at >= ?)
+		FROM recipients r
+		WHERE r.deleted_at IS NULL
+		ORDER BY r.created_at DESC
+	`, time.Now().Add(-resolveFrequencyWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []ResolvedRecipient
+	index := map[string]int{}
+	for rows.Next() {
+		var rr ResolvedRecipient
+		recipient, err := scanRecipient(rows, &rr.RecentPayments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recipient: %w", err)
+		}
+		rr.Recipient = recipient
+		index[rr.RecipientCode] = len(recipients)
+		recipients = append(recipients, rr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recipients: %w", err)
+	}
+
+	aliases, err := database.DB.Query("SELECT recipient_code, alias FROM recipient_aliases ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recipient aliases: %w", err)
+	}
+	defer aliases.Close()
+
+	for aliases.Next() {
+		var code, alias string
+		if err := aliases.Scan(&code, &alias); err != nil {
+			return nil, fmt.Errorf("failed to scan recipient alias: %w", err)
+		}
+		if i, ok := index[code]; ok {
+			recipients[i].Aliases = append(recipients[i].Aliases, alias)
+		}
+	}
+	return recipients, aliases.Err()
+}
+
+// RecipientAlias is another name a recipient is called by
+type RecipientAlias struct {
+	ID            int       `json:"id"`
+	RecipientCode string    `json:"recipient_code"`
+	RecipientName string    `json:"recipient_name"`
+	Alias         string    `json:"alias"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type AddRecipientAliasRequest struct {
+	RecipientCode string `json:"recipient_code"`
+	Alias         string `json:"alias"`
+}
+
+// errAliasTaken is returned for an alias another recipient or a group already answers to
+var errAliasTaken = errors.New("alias is already in use")
+
+// ListAliases lists aliases, optionally for one recipient. Query: recipient_code.
+func (h *RecipientHandler) ListAliases(w http.ResponseWriter, r *http.Request) {
+	query := `
+		SELECT a.id, a.recipient_code, COALESCE(r.name, ''), a.alias, a.created_at
+		FROM recipient_aliases a
+		LEFT JOIN recipients r ON r.recipient_code = a.recipient_code`
+	var args []interface{}
+	if code := r.URL.Query().Get("recipient_code"); code != "" {
+		query += " WHERE a.recipient_code = ?"
+		args = append(args, code)
+	}
+	query += " ORDER BY a.recipient_code, a.alias"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to query recipient aliases: %w", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	aliases := []RecipientAlias{}
+	for rows.Next() {
+		var a RecipientAlias
+		if err := rows.Scan(&a.ID, &a.RecipientCode, &a.RecipientName, &a.Alias, &a.CreatedAt); err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to scan recipient alias: %w", err), http.StatusInternalServerError)
+			return
+		}
+		aliases = append(aliases, a)
+	}
+	if err := rows.Err(); err != nil {
+		WriteJSONError(w, fmt.Errorf("error iterating recipient aliases: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"aliases": aliases,
+		"count":   len(aliases),
+	})
+}
+
+// AddAlias gives a recipient another name
+func (h *RecipientHandler) AddAlias(w http.ResponseWriter, r *http.Request) {
+	var req AddRecipientAliasRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+	req.Alias = strings.TrimSpace(req.Alias)
+	if req.RecipientCode == "" || req.Alias == "" {
+		WriteJSONBadRequest(w, "recipient_code and alias are required")
+		return
+	}
+
+	var name string
+	if err := database.DB.QueryRow("SELECT name FROM recipients WHERE recipient_code = ? AND deleted_at IS NULL", req.RecipientCode).Scan(&name); err != nil {
+		WriteJSONError(w, fmt.Errorf("recipient not found: %s", req.RecipientCode), http.StatusNotFound)
+		return
+	}
+
+	alias, err := addRecipientAlias(req.RecipientCode, req.Alias)
+	if errors.Is(err, errAliasTaken) {
+		WriteJSONError(w, err, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	alias.RecipientName = name
+	WriteJSONSuccessWithMessage(w, "Alias added", alias)
+}
+
+// DeleteAlias removes an alias
+func (h *RecipientHandler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteJSONBadRequest(w, "invalid alias id")
+		return
+	}
+	result, err := database.DB.Exec("DELETE FROM recipient_aliases WHERE id = ?", id)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to delete alias: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		WriteJSONError(w, fmt.Errorf("alias not found: %d", id), http.StatusNotFound)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, "Alias deleted", map[string]interface{}{"id": id})
+}
+
+// addRecipientAlias stores an alias, refusing one that another recipient or a group answers to.
+// Adding an alias the recipient already has returns the existing one.
+func addRecipientAlias(recipientCode, alias string) (RecipientAlias, error) {
+	var owner string
+	err := database.DB.QueryRow("SELECT recipient_code FROM recipient_aliases WHERE alias = ? COLLATE NOCASE", alias).Scan(&owner)
+	switch {
+	case err == nil && owner != recipientCode:
+		return RecipientAlias{}, fmt.Errorf("%w: %q belongs to %s", errAliasTaken, alias, owner)
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		return RecipientAlias{}, fmt.Errorf("failed to check alias: %w", err)
+	}
+
+	var groups int
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM recipient_groups WHERE name = ?", alias).Scan(&groups); err != nil {
+		return RecipientAlias{}, fmt.Errorf("failed to check alias: %w", err)
+	}
+	if groups > 0 {
+		return RecipientAlias{}, fmt.Errorf("%w: %q is a group", errAliasTaken, alias)
+	}
+
+	if owner == "" {
+		if _, err := database.DB.Exec(
+			"INSERT INTO recipient_aliases (recipient_code, alias) VALUES (?, ?)",
+			recipientCode, alias,
+		); err != nil {
+			return RecipientAlias{}, fmt.Errorf("failed to save alias %q: %w", alias, err)
+		}
+	}
+
+	a := RecipientAlias{RecipientCode: recipientCode}
+	err = database.DB.QueryRow(
+		"SELECT id, alias, created_at FROM recipient_aliases WHERE recipient_code = ? AND alias = ? COLLATE NOCASE",
+		recipientCode, alias,
+	).Scan(&a.ID, &a.Alias, &a.CreatedAt)
+	if err != nil {
+		return RecipientAlias{}, fmt.Errorf("failed to load alias %q: %w", alias, err)
+	}
+	return a, nil
+}
+
+// saveRecipientAliases stores other names a recipient is called by, ignoring blanks
+// and skipping any already in use elsewhere
+func saveRecipientAliases(recipientCode string, aliases []string) error {
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			continue
+		}
+		_, err := addRecipientAlias(recipientCode, alias)
+		if errors.Is(err, errAliasTaken) {
+			fmt.Printf("Warning: skipping alias for %s: %v\n", recipientCode, err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Recipient Sync - Keeping the Recipient Cache Current
+//
+// OBJECTIVES:
+// Recipients created on the Paystack dashboard or by another integration should be
+// payable by name here, and renames and deletions made there should show up here.
+//
+// PURPOSE:
+// - List every Paystack transfer recipient and compare it with the local cache
+// - Import new recipients, refresh changed ones and soft-delete deleted ones
+// - Record a second code for an already saved bank account as a duplicate
+// - Run on an interval in the background and on demand
+//
+// KEY WORKFLOW:
+// Fetch All Pages → Plan Changes (internal/recipients) → Apply Each →
+// Stamp synced_at → Save Sync State
+//
+// DESIGN DECISIONS:
+// - Recipient lists are small, so each run reads the whole listing; there is no cursor
+// - Changes are only planned from a complete listing, so a failed page changes nothing
+// - Duplicates are stored deleted, pointing at the kept recipient: payments and
+//   expenses that name either code reach the same person
+// - Recipients deleted here are not brought back by the sync
+// - Progress is kept in sync_state alongside the transaction sync
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/paystack"
+	"paystack.mpc.proxy/internal/recipients"
+)
+
+const (
+	recipientSyncName    = "paystack_recipients"
+	recipientSyncPerPage = 100
+	// recipientSyncMaxPages bounds one run at 5,000 recipients
+	recipientSyncMaxPages = 50
+)
+
+var recipientSyncMu sync.Mutex
+
+// RecipientSyncResult describes one recipient sync run
+type RecipientSyncResult struct {
+	Fetched     int `json:"fetched"`
+	Imported    int `json:"imported"`
+	Refreshed   int `json:"refreshed"`
+	Deactivated int `json:"deactivated"`
+	Duplicates  int `json:"duplicates"`
+	// Changes lists what was done, one line per recipient
+	Changes []RecipientSyncChange `json:"changes"`
+}
+
+// RecipientSyncChange is one recipient changed by a sync
+type RecipientSyncChange struct {
+	Action        string `json:"action"`
+	RecipientCode string `json:"recipient_code"`
+	Name          string `json:"name"`
+	DuplicateOf   string `json:"duplicate_of,omitempty"`
+}
+
+// RecipientSyncStatus is the stored state of the recipient sync
+type RecipientSyncStatus struct {
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	RecordsSynced int        `json:"records_synced"`
+	Running       bool       `json:"running"`
+}
+
+// SyncRecipients brings the local recipient cache in line with Paystack.
+// It returns (nil, nil) when another sync is already running.
+func SyncRecipients(client *paystack.Client) (*RecipientSyncResult, error) {
+	if !recipientSyncMu.TryLock() {
+		return nil, nil
+	}
+	defer recipientSyncMu.Unlock()
+
+	status, err := loadRecipientSyncStatus()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	status.LastRunAt = &now
+
+	remote, err := fetchRemoteRecipients(client)
+	if err != nil {
+		status.LastError = err.Error()
+		if saveErr := saveRecipientSyncStatus(status); saveErr != nil {
+			fmt.Printf("Warning: %v\n", saveErr)
+		}
+		return nil, err
+	}
+
+	local, err := loadSyncedRecipients()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RecipientSyncResult{Fetched: len(remote), Changes: []RecipientSyncChange{}}
+	for _, change := range recipients.Plan(local, remote) {
+		if err := applyRecipientChange(change, now); err != nil {
+			status.LastError = err.Error()
+			saveRecipientSyncStatus(status)
+			return result, err
+		}
+		switch change.Kind {
+		case recipients.Import:
+			result.Imported++
+		case recipients.Refresh:
+			result.Refreshed++
+		case recipients.Deactivate:
+			result.Deactivated++
+		case recipients.Duplicate:
+			result.Duplicates++
+		}
+		result.Changes = append(result.Changes, RecipientSyncChange{
+			Action:        change.Kind,
+			RecipientCode: change.Remote.Code,
+			Name:          change.Remote.Name,
+			DuplicateOf:   change.DuplicateOf,
+		})
+	}
+
+	// Everything in the listing has now been seen
+	for _, r := range remote {
+		database.DB.Exec("UPDATE recipients SET synced_at = ? WHERE recipient_code = ?", now, r.Code)
+	}
+
+	status.LastSuccessAt = &now
+	status.LastError = ""
+	status.RecordsSynced += len(result.Changes)
+	if err := saveRecipientSyncStatus(status); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// fetchRemoteRecipients reads every page of Paystack transfer recipients
+func fetchRemoteRecipients(client *paystack.Client) ([]recipients.Recipient, error) {
+	var remote []recipients.Recipient
+	for page := 1; page <= recipientSyncMaxPages; page++ {
+		items, pageCount, err := client.ListPage("transferrecipient", page, recipientSyncPerPage, time.Time{}, time.Time{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list recipients page %d: %w", page, err)
+		}
+		for _, item := range items {
+			remote = append(remote, recipients.FromPaystack(item))
+		}
+		if len(items) == 0 || page >= pageCount {
+			return remote, nil
+		}
+	}
+	return nil, fmt.Errorf("more than %d pages of recipients; not syncing a partial listing", recipientSyncMaxPages)
+}
+
+// loadSyncedRecipients loads the local cache as the sync compares it
+func loadSyncedRecipients() ([]recipients.Recipient, error) {
+	rows, err := database.DB.Query("SELECT " + recipientColumns + " FROM recipients ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var local []recipients.Recipient
+	for rows.Next() {
+		r, err := scanRecipient(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recipient: %w", err)
+		}
+		local = append(local, recipients.Recipient{
+			Code:          r.RecipientCode,
+			Type:          r.Type,
+			Name:          r.Name,
+			AccountNumber: r.AccountNumber,
+			BankCode:      r.BankCode,
+			BankName:      r.BankName,
+			Currency:      r.Currency,
+			Active:        r.DeletedAt == nil,
+		})
+	}
+	return local, rows.Err()
+}
+
+// applyRecipientChange makes one planned change to the local cache
+func applyRecipientChange(change recipients.Change, now time.Time) error {
+	r := change.Remote
+	var err error
+	switch change.Kind {
+	case recipients.Import:
+		_, err = database.DB.Exec(`
+			INSERT INTO recipients (recipient_code, type, name, account_number, bank_code, bank_name, currency, created_at, updated_at, synced_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, r.Code, r.Type, r.Name, r.AccountNumber, r.BankCode, r.BankName, r.Currency, now, now, now)
+
+	case recipients.Duplicate:
+		_, err = database.DB.Exec(`
+			INSERT INTO recipients (recipient_code, type, name, account_number, bank_code, bank_name, currency, created_at, updated_at,
+			                        synced_at, active, deleted_at, duplicate_of)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, ?, ?)
+		`, r.Code, r.Type, r.Name, r.AccountNumber, r.BankCode, r.BankName, r.Currency, now, now, now, now, change.DuplicateOf)
+
+	case recipients.Refresh:
+		_, err = database.DB.Exec(`
+			UPDATE recipients SET
+				name = COALESCE(NULLIF(?, ''), name),
+				bank_name = COALESCE(NULLIF(?, ''), bank_name),
+				type = COALESCE(NULLIF(?, ''), type),
+				currency = COALESCE(NULLIF(?, ''), currency),
+				updated_at = ?
+			WHERE recipient_code = ?
+		`, r.Name, r.BankName, r.Type, r.Currency, now, r.Code)
+
+	case recipients.Deactivate:
+		err = softDeleteRecipient(r.Code, now)
+
+	default:
+		return fmt.Errorf("unknown recipient change: %s", change.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s recipient %s: %w", change.Kind, r.Code, err)
+	}
+	return nil
+}
+
+func loadRecipientSyncStatus() (*RecipientSyncStatus, error) {
+	var status RecipientSyncStatus
+	var lastRunAt, lastSuccessAt sql.NullTime
+	var lastError sql.NullString
+
+	err := database.DB.QueryRow(`
+		SELECT last_run_at, last_success_at, last_error, records_synced
+		FROM sync_state WHERE name = ?
+	`, recipientSyncName).Scan(&lastRunAt, &lastSuccessAt, &lastError, &status.RecordsSynced)
+	if err == sql.ErrNoRows {
+		return &status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sync state: %w", err)
+	}
+
+	status.LastRunAt = nullTimePtr(lastRunAt)
+	status.LastSuccessAt = nullTimePtr(lastSuccessAt)
+	status.LastError = lastError.String
+	return &status, nil
+}
+
+func saveRecipientSyncStatus(status *RecipientSyncStatus) error {
+	var lastError interface{}
+	if status.LastError != "" {
+		lastError = status.LastError
+	}
+
+	_, err := database.DB.Exec(`
+		INSERT INTO sync_state (name, last_run_at, last_success_at, last_error, records_synced)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			last_run_at = excluded.last_run_at,
+			last_success_at = excluded.last_success_at,
+			last_error = excluded.last_error,
+			records_synced = excluded.records_synced
+	`, recipientSyncName, timeOrNil(status.LastRunAt), timeOrNil(status.LastSuccessAt), lastError, status.RecordsSynced)
+	if err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+	return nil
+}
+
+// StartRecipientSync runs SyncRecipients every interval in the background
+func StartRecipientSync(client *paystack.Client, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			result, err := SyncRecipients(client)
+			if err != nil {
+				log.Printf("Recipient sync failed: %v", err)
+			} else if result != nil && len(result.Changes) > 0 {
+				log.Printf("Recipient sync: %d imported, %d refreshed, %d deactivated, %d duplicates",
+					result.Imported, result.Refreshed, result.Deactivated, result.Duplicates)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// Sync runs a recipient sync now
+func (h *RecipientHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	result, err := SyncRecipients(h.client)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusBadGateway)
+		return
+	}
+
+	if result == nil {
+		status, err := loadRecipientSyncStatus()
+		if err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+		status.Running = true
+		WriteJSONSuccessWithMessage(w, "A sync is already running", status)
+		return
+	}
+
+	WriteJSONSuccessWithMessage(w, "Recipients synced", result)
+}
+
+// SyncStatus returns the stored recipient sync state
+func (h *RecipientHandler) SyncStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := loadRecipientSyncStatus()
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if recipientSyncMu.TryLock() {
+		recipientSyncMu.Unlock()
+	} else {
+		status.Running = true
+	}
+
+	var count int
+	database.DB.QueryRow("SELECT COUNT(*) FROM recipients WHERE deleted_at IS NULL").Scan(&count)
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"state":      status,
+		"recipients": count,
+	})
+}
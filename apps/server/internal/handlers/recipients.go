@@ -10,10 +10,11 @@
 // - Provide fast recipient lookups without API calls
 // - Support expense creation with validated recipients
 // - Maintain default recipients (e.g., service providers)
+// - Rename and delete recipients here and in Paystack (sync: recipient_sync.go)
 //
 // KEY WORKFLOW:
-// Create Recipient → Call Paystack API → Cache Locally →
-// Reference in Expenses → Validate Before Transfer
+// Create Recipient → Already Saved? → Resolve Account → Check Name → Call Paystack API →
+// Cache Locally → Reference in Expenses → Validate Before Transfer
 //
 // DESIGN DECISIONS:
 // - Recipients are cached to reduce API calls and improve performance
@@ -21,21 +22,54 @@
 // - Local cache ensures expenses can reference recipients that exist
 // - All recipient creation goes through Paystack first, then cached locally
 // - Bank name extracted from Paystack response for display purposes
+// - A bank account is saved once; creating it again returns the saved recipient
+// - Bank accounts are resolved before they're saved and the bank's name is compared
+//   with the spoken one: a mismatch is refused until the user confirms, a partial
+//   match is saved with a warning. The name the user gave stays the recipient's name
+// - Updates and deletes go to Paystack first and are only applied locally once it agrees
+// - Deletes are soft: the row stays so past expenses and transfers still name the
+//   recipient, but it is no longer listed, resolved or paid
 package handlers
 
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/dto"
 	"paystack.mpc.proxy/internal/paystack"
+	"paystack.mpc.proxy/internal/recipients"
+	"paystack.mpc.proxy/internal/resolve"
 
 	paystackSDK "github.com/borderlesshq/paystack-go"
+	"github.com/go-chi/chi/v5"
 )
 
+// defaultRecipientCode is the local-only recipient all service providers are paid through
+const defaultRecipientCode = "RCP_serviceprovider"
+
+// recipientColumns are the columns scanRecipient reads, in order
+const recipientColumns = `id, recipient_code, type, name, account_number, bank_code, bank_name, currency, description,
+	created_at, updated_at, active, deleted_at, duplicate_of, synced_at, account_name, name_match, name_confidence`
+
+// accountNameWarning is the warning kind for a recipient saved under a name the
+// bank's name for the account doesn't clearly match
+const accountNameWarning = "account_name_mismatch"
+
+// recipientWarning is something about a saved recipient the caller should pass on
+type recipientWarning struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// errRecipientDeleted is returned for a recipient that has been deleted
+var errRecipientDeleted = errors.New("recipient has been deleted")
+
 type RecipientHandler struct {
 	client *paystack.Client
 }
@@ -57,6 +91,143 @@ type Recipient struct {
 	Description   string    `json:"description"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+	// Active is false once the recipient is deleted, here or in Paystack
+	Active    bool       `json:"active"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// DuplicateOf is the recipient saved earlier for the same bank account
+	DuplicateOf string     `json:"duplicate_of,omitempty"`
+	SyncedAt    *time.Time `json:"synced_at,omitempty"`
+	// AccountName is the name the bank holds for the account; Name is what the user calls them
+	AccountName string `json:"account_name,omitempty"`
+	// NameMatch is how well Name matched AccountName when the recipient was created
+	// (verified, uncertain or mismatch), and NameConfidence the score behind it
+	NameMatch      string  `json:"name_match,omitempty"`
+	NameConfidence float64 `json:"name_confidence,omitempty"`
+}
+
+// scanRecipient reads recipientColumns, followed by any extra columns, into a Recipient
+func scanRecipient(row interface{ Scan(...interface{}) error }, extra ...interface{}) (Recipient, error) {
+	var recipient Recipient
+	var bankName, description, duplicateOf, accountName, nameMatch sql.NullString
+	var deletedAt, syncedAt sql.NullTime
+	var nameConfidence sql.NullFloat64
+
+	dest := []interface{}{
+		&recipient.ID,
+		&recipient.RecipientCode,
+		&recipient.Type,
+		&recipient.Name,
+		&recipient.AccountNumber,
+		&recipient.BankCode,
+		&bankName,
+		&recipient.Currency,
+		&description,
+		&recipient.CreatedAt,
+		&recipient.UpdatedAt,
+		&recipient.Active,
+		&deletedAt,
+		&duplicateOf,
+		&syncedAt,
+		&accountName,
+		&nameMatch,
+		&nameConfidence,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return Recipient{}, err
+	}
+
+	recipient.BankName = bankName.String
+	recipient.Description = description.String
+	recipient.DuplicateOf = duplicateOf.String
+	recipient.DeletedAt = nullTimePtr(deletedAt)
+	recipient.SyncedAt = nullTimePtr(syncedAt)
+	recipient.AccountName = accountName.String
+	recipient.NameMatch = nameMatch.String
+	recipient.NameConfidence = nameConfidence.Float64
+	return recipient, nil
+}
+
+// loadRecipient reads a cached recipient, deleted or not, by code
+func loadRecipient(code string) (Recipient, error) {
+	row := database.DB.QueryRow("SELECT "+recipientColumns+" FROM recipients WHERE recipient_code = ?", code)
+	return scanRecipient(row)
+}
+
+// payableRecipientCode returns the code to pay for a cached recipient code: the code
+// itself, or the recipient it duplicates. It returns sql.ErrNoRows for an unknown
+// code and errRecipientDeleted for a deleted recipient.
+func payableRecipientCode(code string) (string, error) {
+	recipient, err := loadRecipient(code)
+	if err != nil {
+		return "", err
+	}
+	if recipient.DuplicateOf != "" {
+		return payableRecipientCode(recipient.DuplicateOf)
+	}
+	if recipient.DeletedAt != nil {
+		return "", fmt.Errorf("%w: %s", errRecipientDeleted, code)
+	}
+	return recipient.RecipientCode, nil
+}
+
+// transferRecipientCode is payableRecipientCode for transfers, which may also pay a
+// recipient code that isn't cached yet; such a code is returned as it is
+func transferRecipientCode(code string) (string, error) {
+	payable, err := payableRecipientCode(code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return code, nil
+	}
+	if err != nil && !errors.Is(err, errRecipientDeleted) {
+		return "", fmt.Errorf("failed to look up recipient: %w", err)
+	}
+	return payable, err
+}
+
+// resolvePayableRecipient replaces code with the recipient code a transfer to it pays.
+// It writes the error response and returns false for a deleted recipient.
+func resolvePayableRecipient(w http.ResponseWriter, code *string) bool {
+	payable, err := transferRecipientCode(*code)
+	if errors.Is(err, errRecipientDeleted) {
+		WriteJSONError(w, err, http.StatusConflict)
+		return false
+	}
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return false
+	}
+	*code = payable
+	return true
+}
+
+// findRecipientByAccount returns the live recipient saved for a bank account
+func findRecipientByAccount(bankCode, accountNumber string) (Recipient, error) {
+	key := recipients.AccountKey(bankCode, accountNumber)
+	if key == "" {
+		return Recipient{}, sql.ErrNoRows
+	}
+
+	rows, err := database.DB.Query(
+		"SELECT "+recipientColumns+" FROM recipients WHERE bank_code = ? AND deleted_at IS NULL ORDER BY id",
+		strings.TrimSpace(bankCode),
+	)
+	if err != nil {
+		return Recipient{}, fmt.Errorf("failed to query recipients: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		recipient, err := scanRecipient(rows)
+		if err != nil {
+			return Recipient{}, fmt.Errorf("failed to scan recipient: %w", err)
+		}
+		if recipients.AccountKey(recipient.BankCode, recipient.AccountNumber) == key {
+			return recipient, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return Recipient{}, fmt.Errorf("error iterating recipients: %w", err)
+	}
+	return Recipient{}, sql.ErrNoRows
 }
 
 type CreateRecipientWithCacheRequest struct {
@@ -66,9 +237,15 @@ type CreateRecipientWithCacheRequest struct {
 	BankCode      string `json:"bank_code"`
 	Currency      string `json:"currency,omitempty"`
 	Description   string `json:"description,omitempty"`
+	// Aliases are other names the recipient is called by, e.g. "landlord"
+	Aliases []string `json:"aliases,omitempty"`
+	// AcceptAccountName saves the recipient even though the bank's name for the
+	// account doesn't match Name, once the user has confirmed it's the right account
+	AcceptAccountName bool `json:"accept_account_name,omitempty"`
 }
 
-// Create creates a new transfer recipient in Paystack and caches it locally
+// Create creates a new transfer recipient in Paystack and caches it locally, with the
+// name the bank holds for the account
 func (h *RecipientHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req CreateRecipientWithCacheRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -102,6 +279,55 @@ func (h *RecipientHandler) Create(w http.ResponseWriter, r *http.Request) {
 		req.Currency = "NGN"
 	}
 
+	// An account that is already saved isn't created again
+	req.BankCode = strings.TrimSpace(req.BankCode)
+	req.AccountNumber = strings.TrimSpace(req.AccountNumber)
+	existing, err := findRecipientByAccount(req.BankCode, req.AccountNumber)
+	if err == nil {
+		if err := saveRecipientAliases(existing.RecipientCode, req.Aliases); err != nil {
+			fmt.Printf("Warning: Failed to save recipient aliases: %v\n", err)
+		}
+		WriteJSONSuccessWithMessage(w, fmt.Sprintf("That account is already saved as %s", existing.Name), existing)
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Check the account belongs to who the user named before saving it
+	var resolution AccountResolution
+	var check resolve.NameCheck
+	var warnings []recipientWarning
+	if req.Type == "nuban" {
+		resolution, err = resolveBankAccount(h.client, req.BankCode, req.AccountNumber)
+		if err != nil {
+			WriteJSONError(w, err, accountResolutionErrorStatus(err))
+			return
+		}
+
+		check = resolve.CheckAccountName(req.Name, resolution.AccountName, resolve.Config{})
+		switch {
+		case check.Status == resolve.NameMismatch && !req.AcceptAccountName:
+			respondWithJSON(w, http.StatusConflict, map[string]interface{}{
+				"status":  false,
+				"message": fmt.Sprintf("That account is held by %s, not %s. Save it anyway?", resolution.AccountName, req.Name),
+				"error":   fmt.Sprintf("account %s is held by %q, not %q", req.AccountNumber, resolution.AccountName, req.Name),
+				"data": map[string]interface{}{
+					"account_name":    resolution.AccountName,
+					"name_match":      check.Status,
+					"name_confidence": check.Confidence,
+				},
+			})
+			return
+		case check.Status != resolve.NameVerified:
+			warnings = append(warnings, recipientWarning{
+				Kind:    accountNameWarning,
+				Message: fmt.Sprintf("The bank has this account as %s, which doesn't clearly match %s.", resolution.AccountName, req.Name),
+			})
+		}
+	}
+
 	// Create recipient in Paystack
 	recipient := &paystackSDK.TransferRecipient{
 		Type:          req.Type,
@@ -126,10 +352,23 @@ func (h *RecipientHandler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Cache in SQLite
+	// Cache in SQLite. Paystack hands back the existing code for an account it
+	// already has, which brings back a recipient deleted here earlier.
 	query := `
-		INSERT INTO recipients (recipient_code, type, name, account_number, bank_code, bank_name, currency, description, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO recipients (recipient_code, type, name, account_number, bank_code, bank_name, currency, description, created_at, updated_at,
+		                        account_name, name_match, name_confidence)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(recipient_code) DO UPDATE SET
+			name = excluded.name,
+			bank_name = excluded.bank_name,
+			description = excluded.description,
+			account_name = excluded.account_name,
+			name_match = excluded.name_match,
+			name_confidence = excluded.name_confidence,
+			active = 1,
+			deleted_at = NULL,
+			duplicate_of = NULL,
+			updated_at = excluded.updated_at
 	`
 	now := time.Now()
 	_, err = database.DB.Exec(
@@ -144,23 +383,32 @@ func (h *RecipientHandler) Create(w http.ResponseWriter, r *http.Request) {
 		req.Description,
 		now,
 		now,
+		resolution.AccountName,
+		check.Status,
+		check.Confidence,
 	)
 	if err != nil {
 		// Log error but still return Paystack response
 		fmt.Printf("Warning: Failed to cache recipient in database: %v\n", err)
+	} else if err := saveRecipientAliases(recipientCode, req.Aliases); err != nil {
+		fmt.Printf("Warning: Failed to save recipient aliases: %v\n", err)
 	}
 
 	// Return Paystack response
-	WriteJSONSuccess(w, result)
+	response := dto.Response{Status: true, Message: "Success", Data: result}
+	if len(warnings) > 0 {
+		response.Warnings = warnings
+	}
+	respondWithJSON(w, http.StatusOK, response)
 }
 
-// List lists all cached recipients from SQLite
+// List lists cached recipients from SQLite. Deleted recipients are left out unless include_deleted=true.
 func (h *RecipientHandler) List(w http.ResponseWriter, r *http.Request) {
-	query := `
-		SELECT id, recipient_code, type, name, account_number, bank_code, bank_name, currency, description, created_at, updated_at
-		FROM recipients
-		ORDER BY created_at DESC
-	`
+	query := "SELECT " + recipientColumns + " FROM recipients"
+	if r.URL.Query().Get("include_deleted") != "true" {
+		query += " WHERE deleted_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC"
 
 	rows, err := database.DB.Query(query)
 	if err != nil {
@@ -171,34 +419,11 @@ func (h *RecipientHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	recipients := []Recipient{}
 	for rows.Next() {
-		var recipient Recipient
-		var bankName, description sql.NullString
-
-		err := rows.Scan(
-			&recipient.ID,
-			&recipient.RecipientCode,
-			&recipient.Type,
-			&recipient.Name,
-			&recipient.AccountNumber,
-			&recipient.BankCode,
-			&bankName,
-			&recipient.Currency,
-			&description,
-			&recipient.CreatedAt,
-			&recipient.UpdatedAt,
-		)
+		recipient, err := scanRecipient(rows)
 		if err != nil {
 			WriteJSONError(w, fmt.Errorf("failed to scan recipient: %w", err), http.StatusInternalServerError)
 			return
 		}
-
-		if bankName.Valid {
-			recipient.BankName = bankName.String
-		}
-		if description.Valid {
-			recipient.Description = description.String
-		}
-
 		recipients = append(recipients, recipient)
 	}
 
@@ -210,7 +435,8 @@ func (h *RecipientHandler) List(w http.ResponseWriter, r *http.Request) {
 	WriteJSONSuccess(w, recipients)
 }
 
-// Get retrieves a specific recipient by recipient_code
+// Get retrieves a specific recipient by recipient_code. Deleted recipients are
+// returned too, so past payments can still be explained.
 func (h *RecipientHandler) Get(w http.ResponseWriter, r *http.Request) {
 	recipientCode := r.URL.Query().Get("recipient_code")
 	if recipientCode == "" {
@@ -218,42 +444,156 @@ func (h *RecipientHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := `
-		SELECT id, recipient_code, type, name, account_number, bank_code, bank_name, currency, description, created_at, updated_at
-		FROM recipients
-		WHERE recipient_code = ?
-	`
+	recipient, err := loadRecipient(recipientCode)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("recipient not found: %s", recipientCode), http.StatusNotFound)
+		return
+	}
 
-	var recipient Recipient
-	var bankName, description sql.NullString
+	WriteJSONSuccess(w, recipient)
+}
 
-	err := database.DB.QueryRow(query, recipientCode).Scan(
-		&recipient.ID,
-		&recipient.RecipientCode,
-		&recipient.Type,
-		&recipient.Name,
-		&recipient.AccountNumber,
-		&recipient.BankCode,
-		&bankName,
-		&recipient.Currency,
-		&description,
-		&recipient.CreatedAt,
-		&recipient.UpdatedAt,
+// UpdateRecipientRequest changes a recipient; omitted fields are unchanged
+type UpdateRecipientRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	// Email is passed to Paystack and not kept locally
+	Email string `json:"email,omitempty"`
+}
+
+// Update renames a recipient or changes its description. Name and email changes go
+// to Paystack first; the description is only kept locally.
+func (h *RecipientHandler) Update(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "recipient_code")
+	var req UpdateRecipientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	recipient, err := loadRecipient(code)
+	if errors.Is(err, sql.ErrNoRows) {
+		WriteJSONError(w, fmt.Errorf("recipient not found: %s", code), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to load recipient: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if recipient.DeletedAt != nil {
+		WriteJSONError(w, fmt.Errorf("%w: %s", errRecipientDeleted, code), http.StatusConflict)
+		return
+	}
+
+	name := recipient.Name
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+		if name == "" {
+			WriteJSONBadRequest(w, "name can't be empty")
+			return
+		}
+	}
+	description := recipient.Description
+	if req.Description != nil {
+		description = *req.Description
+	}
+
+	if (name != recipient.Name || req.Email != "") && code != defaultRecipientCode {
+		if err := h.client.UpdateTransferRecipient(code, name, req.Email); err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to update recipient in Paystack: %w", err), http.StatusBadGateway)
+			return
+		}
+	}
+
+	_, err = database.DB.Exec(
+		"UPDATE recipients SET name = ?, description = ?, updated_at = ? WHERE recipient_code = ?",
+		name, description, time.Now(), code,
 	)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to update recipient: %w", err), http.StatusInternalServerError)
+		return
+	}
 
+	updated, err := loadRecipient(code)
 	if err != nil {
-		WriteJSONError(w, fmt.Errorf("recipient not found: %s", recipientCode), http.StatusNotFound)
+		WriteJSONError(w, fmt.Errorf("failed to load recipient: %w", err), http.StatusInternalServerError)
 		return
 	}
+	WriteJSONSuccessWithMessage(w, "Recipient updated", updated)
+}
 
-	if bankName.Valid {
-		recipient.BankName = bankName.String
+// Delete deletes a recipient in Paystack and soft-deletes it locally. Its aliases
+// and group memberships are removed. A recipient with recurring payments or
+// transfers waiting to be sent can't be deleted until they are cancelled.
+func (h *RecipientHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "recipient_code")
+	if code == defaultRecipientCode {
+		WriteJSONBadRequest(w, "the service provider recipient can't be deleted")
+		return
 	}
-	if description.Valid {
-		recipient.Description = description.String
+
+	recipient, err := loadRecipient(code)
+	if errors.Is(err, sql.ErrNoRows) {
+		WriteJSONError(w, fmt.Errorf("recipient not found: %s", code), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to load recipient: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if recipient.DeletedAt != nil {
+		WriteJSONSuccessWithMessage(w, "Recipient was already deleted", recipient)
+		return
 	}
 
-	WriteJSONSuccess(w, recipient)
+	var schedules, scheduledTransfers int
+	database.DB.QueryRow("SELECT COUNT(*) FROM schedules WHERE recipient_code = ? AND status IN ('active', 'paused')", code).Scan(&schedules)
+	database.DB.QueryRow("SELECT COUNT(*) FROM scheduled_transfers WHERE recipient_code = ? AND status = 'scheduled'", code).Scan(&scheduledTransfers)
+	if schedules > 0 || scheduledTransfers > 0 {
+		WriteJSONError(w, fmt.Errorf("%s has %d recurring payments and %d scheduled transfers; cancel them before deleting the recipient",
+			recipient.Name, schedules, scheduledTransfers), http.StatusConflict)
+		return
+	}
+
+	if err := h.client.DeleteTransferRecipient(code); err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to delete recipient in Paystack: %w", err), http.StatusBadGateway)
+		return
+	}
+
+	if err := softDeleteRecipient(code, time.Now()); err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	deleted, err := loadRecipient(code)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to load recipient: %w", err), http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, "Recipient deleted", deleted)
+}
+
+// softDeleteRecipient marks a recipient deleted and removes its aliases and group memberships
+func softDeleteRecipient(code string, now time.Time) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"UPDATE recipients SET active = 0, deleted_at = ?, updated_at = ? WHERE recipient_code = ?",
+		now, now, code,
+	); err != nil {
+		return fmt.Errorf("failed to delete recipient: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM recipient_aliases WHERE recipient_code = ?", code); err != nil {
+		return fmt.Errorf("failed to delete recipient aliases: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM recipient_group_members WHERE recipient_code = ?", code); err != nil {
+		return fmt.Errorf("failed to remove recipient from groups: %w", err)
+	}
+	return tx.Commit()
 }
 
 // RecipientSearchResult represents a search result with match scoring
@@ -286,7 +626,7 @@ func (h *RecipientHandler) Search(w http.ResponseWriter, r *http.Request) {
 	// Priority: exact name > partial name > account number > bank name
 	sqlQuery := `
 		SELECT
-			id, recipient_code, type, name, account_number, bank_code, bank_name, currency, description, created_at, updated_at,
+			` + recipientColumns + `,
 			CASE
 				WHEN LOWER(name) = LOWER(?) THEN 1.0
 				WHEN LOWER(name) LIKE LOWER(?) THEN 0.9
@@ -295,10 +635,11 @@ func (h *RecipientHandler) Search(w http.ResponseWriter, r *http.Request) {
 				ELSE 0.5
 			END as match_score
 		FROM recipients
-		WHERE
+		WHERE deleted_at IS NULL AND (
 			LOWER(name) LIKE LOWER(?) OR
 			account_number = ? OR
 			LOWER(bank_name) LIKE LOWER(?)
+		)
 		ORDER BY match_score DESC, created_at DESC
 		LIMIT ? OFFSET ?
 	`
@@ -326,33 +667,12 @@ func (h *RecipientHandler) Search(w http.ResponseWriter, r *http.Request) {
 	results := []RecipientSearchResult{}
 	for rows.Next() {
 		var result RecipientSearchResult
-		var bankName, description sql.NullString
-
-		err := rows.Scan(
-			&result.ID,
-			&result.RecipientCode,
-			&result.Type,
-			&result.Name,
-			&result.AccountNumber,
-			&result.BankCode,
-			&bankName,
-			&result.Currency,
-			&description,
-			&result.CreatedAt,
-			&result.UpdatedAt,
-			&result.MatchScore,
-		)
+		recipient, err := scanRecipient(rows, &result.MatchScore)
 		if err != nil {
 			WriteJSONError(w, fmt.Errorf("failed to scan recipient: %w", err), http.StatusInternalServerError)
 			return
 		}
-
-		if bankName.Valid {
-			result.BankName = bankName.String
-		}
-		if description.Valid {
-			result.Description = description.String
-		}
+		result.Recipient = recipient
 
 		results = append(results, result)
 	}
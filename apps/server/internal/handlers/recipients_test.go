@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"paystack.mpc.proxy/internal/confirm"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/recipients"
+)
+
+// addRecipient caches a live bank recipient
+func addRecipient(t *testing.T, code, name string) {
+	t.Helper()
+	_, err := database.DB.Exec(
+		"INSERT INTO recipients (recipient_code, type, name, account_number, bank_code, currency) VALUES (?, 'nuban', ?, '0123456789', '058', 'NGN')",
+		code, name,
+	)
+	if err != nil {
+		t.Fatalf("Failed to add recipient: %v", err)
+	}
+}
+
+// deleteRecipient deletes a recipient the way the Delete handler does, or the way the sync
+// does when Paystack no longer has it
+func deleteRecipient(t *testing.T, code string, bySync bool) {
+	t.Helper()
+	var err error
+	if bySync {
+		err = applyRecipientChange(recipients.Change{Kind: recipients.Deactivate, Remote: recipients.Recipient{Code: code}}, time.Now())
+	} else {
+		err = softDeleteRecipient(code, time.Now())
+	}
+	if err != nil {
+		t.Fatalf("Failed to delete recipient: %v", err)
+	}
+}
+
+func TestDeletedRecipientIsNotPaid(t *testing.T) {
+	for _, bySync := range []bool{false, true} {
+		name := "deleted here"
+		if bySync {
+			name = "deactivated by sync"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Run("transfer", func(t *testing.T) {
+				setupHandlerDB(t)
+				h := NewTransferHandler(nil)
+				addRecipient(t, "RCP_ada", "Ada Obi")
+				transfer := map[string]interface{}{"source": "balance", "amount": 500000, "recipient": "RCP_ada"}
+
+				code, preview := post(t, h.Initiate, transfer)
+				if code != http.StatusOK {
+					t.Fatalf("Expected a preview, got %d: %v", code, preview)
+				}
+				token, _ := preview["data"].(map[string]interface{})["confirmation_token"].(string)
+
+				deleteRecipient(t, "RCP_ada", bySync)
+				if code, response := post(t, h.Initiate, transfer); code != http.StatusConflict {
+					t.Errorf("Expected a new preview to be refused, got %d: %v", code, response)
+				}
+				if code, response := post(t, h.Initiate, map[string]interface{}{"confirmation_token": token}); code != http.StatusConflict {
+					t.Errorf("Expected the confirmation to be refused, got %d: %v", code, response)
+				}
+			})
+
+			t.Run("scheduled transfer", func(t *testing.T) {
+				setupHandlerDB(t)
+				h := NewTransferHandler(nil)
+				addRecipient(t, "RCP_ada", "Ada Obi")
+				now := time.Now().UTC()
+				_, err := database.DB.Exec(`
+					INSERT INTO scheduled_transfers (recipient_code, recipient_name, amount, currency, source, reference, execute_at, status, created_at, updated_at)
+					VALUES ('RCP_ada', 'Ada Obi', 500000, 'NGN', 'balance', 'sxf_deletedrecipient', ?, ?, ?, ?)
+				`, now.Add(-time.Minute), transferScheduled, now, now)
+				if err != nil {
+					t.Fatalf("Failed to queue transfer: %v", err)
+				}
+
+				deleteRecipient(t, "RCP_ada", bySync)
+				code, response := post(t, h.Schedule, map[string]interface{}{
+					"amount":     500000,
+					"recipient":  "RCP_ada",
+					"execute_at": now.Add(24 * time.Hour).Format(time.RFC3339),
+				})
+				if code != http.StatusConflict {
+					t.Errorf("Expected scheduling to be refused, got %d: %v", code, response)
+				}
+
+				transfers, err := RunDueTransfers(nil, now)
+				if err != nil {
+					t.Fatalf("Failed to run due transfers: %v", err)
+				}
+				if len(transfers) != 1 || transfers[0].Status != transferNeedsReview {
+					t.Fatalf("Expected the queued transfer to need review, got %+v", transfers)
+				}
+				if transfers[0].InitiatedAt != nil {
+					t.Errorf("Expected the transfer not to have been initiated")
+				}
+			})
+
+			t.Run("schedule", func(t *testing.T) {
+				setupHandlerDB(t)
+				h := NewScheduleHandler(nil)
+				addRecipient(t, "RCP_ada", "Ada Obi")
+				schedule := map[string]interface{}{"recipient_code": "RCP_ada", "amount": 500000, "narration": "Rent", "every": 1, "unit": "month"}
+
+				code, preview := post(t, h.Create, schedule)
+				if code != http.StatusOK {
+					t.Fatalf("Expected a preview, got %d: %v", code, preview)
+				}
+				token, _ := preview["data"].(map[string]interface{})["confirmation_token"].(string)
+				if code, created := post(t, h.Create, map[string]interface{}{"confirmation_token": token}); code != http.StatusOK {
+					t.Fatalf("Expected the schedule to be created, got %d: %v", code, created)
+				}
+
+				deleteRecipient(t, "RCP_ada", bySync)
+				if code, response := post(t, h.Create, schedule); code != http.StatusConflict {
+					t.Errorf("Expected a new schedule to be refused, got %d: %v", code, response)
+				}
+
+				runs, err := RunDueSchedules(nil, time.Now().UTC().Add(time.Minute))
+				if err != nil {
+					t.Fatalf("Failed to run schedules: %v", err)
+				}
+				if len(runs) != 1 || runs[0].Status != scheduleRunNeedsReview || runs[0].ExpenseID != nil {
+					t.Fatalf("Expected one run needing review and no expense, got %+v", runs)
+				}
+				s, err := loadSchedule(runs[0].ScheduleID)
+				if err != nil {
+					t.Fatalf("Failed to load schedule: %v", err)
+				}
+				if s.Status != schedulePaused {
+					t.Errorf("Expected the schedule to be paused, got %s", s.Status)
+				}
+			})
+
+			t.Run("bulk transfer", func(t *testing.T) {
+				setupHandlerDB(t)
+				h := NewTransferHandler(nil)
+				addRecipient(t, "RCP_ada", "Ada Obi")
+
+				code, preview := post(t, h.Bulk, map[string]interface{}{
+					"transfers": []map[string]interface{}{{"recipient": "RCP_ada", "amount": 500000}},
+				})
+				if code != http.StatusOK {
+					t.Fatalf("Expected a preview, got %d: %v", code, preview)
+				}
+				token, _ := preview["data"].(map[string]interface{})["confirmation_token"].(string)
+
+				deleteRecipient(t, "RCP_ada", bySync)
+				code, response := post(t, h.Bulk, map[string]interface{}{"confirmation_token": token})
+				if code != http.StatusOK {
+					t.Fatalf("Expected the batch to report each payment, got %d: %v", code, response)
+				}
+				data, _ := response["data"].(map[string]interface{})
+				if sent, _ := data["sent"].(float64); sent != 0 {
+					t.Errorf("Expected nothing to be sent, got %v", data)
+				}
+			})
+		})
+	}
+}
+
+func TestDuplicateRecipientPaysTheRecipientKept(t *testing.T) {
+	setupHandlerDB(t)
+	addRecipient(t, "RCP_ada", "Ada Obi")
+	err := applyRecipientChange(recipients.Change{
+		Kind:        recipients.Duplicate,
+		Remote:      recipients.Recipient{Code: "RCP_ada2", Type: "nuban", Name: "Ada Obi", AccountNumber: "0123456789", BankCode: "058"},
+		DuplicateOf: "RCP_ada",
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to add duplicate: %v", err)
+	}
+
+	code, preview := post(t, NewTransferHandler(nil).Initiate, map[string]interface{}{"source": "balance", "amount": 500000, "recipient": "RCP_ada2"})
+	if code != http.StatusOK {
+		t.Fatalf("Expected a preview, got %d: %v", code, preview)
+	}
+	if name, _ := preview["data"].(map[string]interface{})["recipient_name"].(string); name != "Ada Obi" {
+		t.Errorf("Expected the preview to pay Ada Obi, got %v", preview)
+	}
+
+	token, _ := preview["data"].(map[string]interface{})["confirmation_token"].(string)
+	var confirmed InitiateTransferRequest
+	if err := confirm.Redeem(token, confirm.ActionTransfer, &confirmed); err != nil {
+		t.Fatalf("Failed to redeem token: %v", err)
+	}
+	if confirmed.Recipient != "RCP_ada" {
+		t.Errorf("Expected the confirmed transfer to pay RCP_ada, got %q", confirmed.Recipient)
+	}
+}
@@ -0,0 +1,999 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Reconciliation Handler - Data Integrity
+//
+// OBJECTIVES:
+// Finance needs to trust that local records match what actually happened on Paystack.
+//
+// PURPOSE:
+// - Compare transfers, expenses, invoices, transactions and recipients with Paystack
+// - Flag missing records, mismatched amounts and mismatched statuses
+// - Auto-fix the safe cases and leave the rest for review
+// - Keep every run and its discrepancies so they can be reviewed and resolved later
+//
+// KEY WORKFLOW:
+// Start Run → Page Paystack Lists → Load Local Records → Compare →
+// Store Discrepancies → Apply Safe Fixes (optional) → Review → Fix or Resolve
+//
+// DESIGN DECISIONS:
+// - Comparison rules live in internal/reconcile; this file loads records and applies fixes
+// - Safe fixes only copy Paystack's view into local caches (missing rows, final statuses)
+// - Amount mismatches and anything touching budgets are never auto-fixed
+// - Each Paystack list is fetched once per run and shared by the sources that need it
+// - Lists are capped per run; a truncated list never produces "missing on Paystack" reports
+// - Scheduled runs never auto-fix, so changes only happen when someone asks for them
+// - A fix claims its item before changing anything, so the same fix is never applied twice
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/paystack"
+	"paystack.mpc.proxy/internal/reconcile"
+
+	paystackSDK "github.com/borderlesshq/paystack-go"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	reconciliationPerPage  = 100
+	reconciliationMaxPages = 20
+	reconciliationWindow   = 30 * 24 * time.Hour
+)
+
+// Reconciliation fix actions
+const (
+	fixMarkExpensePaid        = "mark_expense_paid"
+	fixRecordTransfer         = "record_transfer"
+	fixInsertInvoice          = "insert_invoice"
+	fixUpdateInvoiceStatus    = "update_invoice_status"
+	fixCacheTransaction       = "cache_transaction"
+	fixUpdateTransactionState = "update_transaction_status"
+	fixInsertRecipient        = "insert_recipient"
+)
+
+var reconciliationMu sync.Mutex
+
+type ReconciliationHandler struct {
+	client *paystack.Client
+}
+
+func NewReconciliationHandler(client *paystack.Client) *ReconciliationHandler {
+	return &ReconciliationHandler{client: client}
+}
+
+// ReconciliationRun is one comparison of local data against Paystack
+type ReconciliationRun struct {
+	ID            int                       `json:"id"`
+	Status        string                    `json:"status"`
+	Sources       []string                  `json:"sources"`
+	From          *time.Time                `json:"from,omitempty"`
+	To            *time.Time                `json:"to,omitempty"`
+	AutoFix       bool                      `json:"auto_fix"`
+	Checked       int                       `json:"checked"`
+	Discrepancies int                       `json:"discrepancies"`
+	Fixed         int                       `json:"fixed"`
+	Truncated     []string                  `json:"truncated,omitempty"`
+	Error         string                    `json:"error,omitempty"`
+	StartedAt     time.Time                 `json:"started_at"`
+	FinishedAt    *time.Time                `json:"finished_at,omitempty"`
+	Summary       map[string]map[string]int `json:"summary,omitempty"`
+	Items         []ReconciliationItem      `json:"items,omitempty"`
+}
+
+// ReconciliationItem is one discrepancy found by a run
+type ReconciliationItem struct {
+	ID           int        `json:"id"`
+	RunID        int        `json:"run_id"`
+	Source       string     `json:"source"`
+	Key          string     `json:"key"`
+	Type         string     `json:"type"`
+	LocalID      *int64     `json:"local_id,omitempty"`
+	LocalAmount  *int64     `json:"local_amount,omitempty"`
+	RemoteAmount *int64     `json:"remote_amount,omitempty"`
+	LocalStatus  string     `json:"local_status,omitempty"`
+	RemoteStatus string     `json:"remote_status,omitempty"`
+	FixAction    string     `json:"fix_action,omitempty"`
+	Status       string     `json:"status"`
+	Note         string     `json:"note,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+// reconciliationSource describes how one kind of local record is matched against Paystack
+type reconciliationSource struct {
+	// resource is the Paystack list endpoint the source compares against
+	resource string
+	// windowed sources apply the run's date window; others compare everything
+	windowed  bool
+	loadLocal func(from, to time.Time) ([]reconcile.Record, error)
+	remoteKey func(item map[string]interface{}) reconcile.Record
+	rules     reconcile.Rules
+}
+
+var reconciliationSources = map[string]reconciliationSource{
+	"transfers": {
+		resource:  "transfer",
+		windowed:  true,
+		loadLocal: loadLocalTransfers,
+		remoteKey: func(item map[string]interface{}) reconcile.Record {
+			return remoteRecord(item, "transfer_code")
+		},
+		rules: reconcile.Rules{
+			CompareAmounts:  true,
+			NormalizeStatus: func(string) string { return "" },
+			// Transfers paying an expense are matched by the expenses source
+			ExpectLocal: func(r reconcile.Record) bool {
+				reference, _ := r.Raw["reference"].(string)
+				return !expenseReferenceExists(reference)
+			},
+			FixMissingLocal: fixRecordTransfer,
+		},
+	},
+	"expenses": {
+		resource:  "transfer",
+		windowed:  true,
+		loadLocal: loadLocalExpenses,
+		remoteKey: func(item map[string]interface{}) reconcile.Record {
+			return remoteRecord(item, "reference")
+		},
+		rules: reconcile.Rules{
+			CompareAmounts:  true,
+			NormalizeStatus: reconcile.TransferStatus,
+			// Only paid expenses are expected to have gone out through Paystack
+			ExpectRemote: func(l reconcile.Record) bool { return l.Status == "paid" },
+			ExpectLocal:  func(reconcile.Record) bool { return false },
+			FixStatus: func(local, remote string) string {
+				if local == "pending" && remote == "paid" {
+					return fixMarkExpensePaid
+				}
+				return ""
+			},
+		},
+	},
+	"invoices": {
+		resource:  "paymentrequest",
+		windowed:  true,
+		loadLocal: loadLocalInvoices,
+		remoteKey: func(item map[string]interface{}) reconcile.Record {
+			return remoteRecord(item, "request_code")
+		},
+		rules: reconcile.Rules{
+			CompareAmounts:  true,
+			FixMissingLocal: fixInsertInvoice,
+			FixStatus:       func(string, string) string { return fixUpdateInvoiceStatus },
+		},
+	},
+	"transactions": {
+		resource:  "transaction",
+		windowed:  true,
+		loadLocal: loadLocalTransactions,
+		remoteKey: func(item map[string]interface{}) reconcile.Record {
+			return remoteRecord(item, "reference")
+		},
+		rules: reconcile.Rules{
+			CompareAmounts:  true,
+			FixMissingLocal: fixCacheTransaction,
+			FixStatus:       func(string, string) string { return fixUpdateTransactionState },
+		},
+	},
+	"recipients": {
+		resource:  "transferrecipient",
+		loadLocal: loadLocalRecipients,
+		remoteKey: func(item map[string]interface{}) reconcile.Record {
+			return remoteRecord(item, "recipient_code")
+		},
+		rules: reconcile.Rules{
+			NormalizeStatus: func(string) string { return "" },
+			// The default service provider recipient only exists locally
+			ExpectRemote:    func(l reconcile.Record) bool { return l.Key != "RCP_serviceprovider" },
+			FixMissingLocal: fixInsertRecipient,
+		},
+	},
+}
+
+// reconciliationSourceOrder is the order sources run and are reported in
+var reconciliationSourceOrder = []string{"transfers", "expenses", "invoices", "transactions", "recipients"}
+
+// remoteRecord reduces a Paystack list item to the fields being compared
+func remoteRecord(item map[string]interface{}, keyField string) reconcile.Record {
+	record := reconcile.Record{Raw: item}
+	record.Key, _ = item[keyField].(string)
+	if amount, ok := item["amount"].(float64); ok {
+		record.Amount = int64(amount)
+	}
+	record.Currency, _ = item["currency"].(string)
+	record.Status, _ = item["status"].(string)
+	for _, field := range []string{"createdAt", "created_at"} {
+		if value, ok := item[field].(string); ok {
+			if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+				record.Date = parsed
+				break
+			}
+		}
+	}
+	return record
+}
+
+func expenseReferenceExists(reference string) bool {
+	if reference == "" {
+		return false
+	}
+	var exists int
+	err := database.DB.QueryRow("SELECT 1 FROM expenses WHERE reference = ?", reference).Scan(&exists)
+	return err == nil
+}
+
+// queryLocalRecords runs a query returning (id, key, amount, currency, status, date) rows
+func queryLocalRecords(query string, args ...interface{}) ([]reconcile.Record, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []reconcile.Record
+	for rows.Next() {
+		var r reconcile.Record
+		if err := rows.Scan(&r.LocalID, &r.Key, &r.Amount, &r.Currency, &r.Status, &r.Date); err != nil {
+			return nil, fmt.Errorf("failed to scan local record: %w", err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func loadLocalTransfers(from, to time.Time) ([]reconcile.Record, error) {
+	return queryLocalRecords(`
+		SELECT id, reference, amount, COALESCE(currency, 'NGN'), '', created_at
+		FROM outgoing_payments
+		WHERE source = 'transfer' AND reference IS NOT NULL AND created_at >= ? AND created_at < ?
+	`, from, to)
+}
+
+func loadLocalExpenses(from, to time.Time) ([]reconcile.Record, error) {
+	// Card payments, service provider payments and imported bank statement
+	// lines never go through Paystack transfers
+	return queryLocalRecords(`
+		SELECT id, reference, amount, COALESCE(currency, 'NGN'), COALESCE(status, ''), created_at
+		FROM expenses
+		WHERE reference IS NOT NULL AND recipient_code NOT LIKE 'CARD_%'
+		  AND recipient_code != 'RCP_serviceprovider' AND import_id IS NULL
+		  AND created_at >= ? AND created_at < ?
+	`, from, to)
+}
+
+func loadLocalInvoices(from, to time.Time) ([]reconcile.Record, error) {
+	return queryLocalRecords(`
+		SELECT id, invoice_code, amount, '', COALESCE(status, ''), created_at
+		FROM invoices WHERE created_at >= ? AND created_at < ?
+	`, from, to)
+}
+
+func loadLocalTransactions(from, to time.Time) ([]reconcile.Record, error) {
+	return queryLocalRecords(`
+		SELECT id, reference, amount, COALESCE(currency, 'NGN'), COALESCE(status, ''), transaction_date
+		FROM transactions WHERE transaction_date >= ? AND transaction_date < ?
+	`, from, to)
+}
+
+func loadLocalRecipients(time.Time, time.Time) ([]reconcile.Record, error) {
+	return queryLocalRecords(`
+		SELECT id, recipient_code, 0, COALESCE(currency, 'NGN'), '', created_at FROM recipients WHERE deleted_at IS NULL
+	`)
+}
+
+// remoteListing is one fetched Paystack list, shared between sources in a run
+type remoteListing struct {
+	items    []map[string]interface{}
+	complete bool
+	err      error
+}
+
+func (h *ReconciliationHandler) fetchRemote(resource string, from, to time.Time) remoteListing {
+	var listing remoteListing
+	for page := 1; page <= reconciliationMaxPages; page++ {
+		items, pageCount, err := h.client.ListPage(resource, page, reconciliationPerPage, from, to)
+		if err != nil {
+			listing.err = fmt.Errorf("failed to list %s page %d: %w", resource, page, err)
+			return listing
+		}
+		listing.items = append(listing.items, items...)
+		if len(items) == 0 || page >= pageCount {
+			listing.complete = true
+			return listing
+		}
+	}
+	return listing
+}
+
+// runReconciliation compares the given sources against Paystack for records
+// created between from and to. It returns (nil, nil) if a run is in progress.
+func (h *ReconciliationHandler) runReconciliation(sources []string, from, to time.Time, autoFix bool) (*ReconciliationRun, error) {
+	if !reconciliationMu.TryLock() {
+		return nil, nil
+	}
+	defer reconciliationMu.Unlock()
+
+	run := &ReconciliationRun{
+		Status:    "running",
+		Sources:   sources,
+		From:      &from,
+		To:        &to,
+		AutoFix:   autoFix,
+		StartedAt: time.Now(),
+		Summary:   map[string]map[string]int{},
+	}
+
+	result, err := database.DB.Exec(
+		"INSERT INTO reconciliation_runs (status, sources, window_from, window_to, auto_fix, started_at) VALUES (?, ?, ?, ?, ?, ?)",
+		run.Status, strings.Join(sources, ","), from, to, autoFix, run.StartedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start reconciliation run: %w", err)
+	}
+	runID, _ := result.LastInsertId()
+	run.ID = int(runID)
+
+	listings := map[string]remoteListing{}
+	var errors []string
+	failed := 0
+
+	for _, name := range sources {
+		source := reconciliationSources[name]
+
+		remoteFrom, remoteTo := from, to
+		if !source.windowed {
+			remoteFrom, remoteTo = time.Time{}, time.Time{}
+		}
+		listing, ok := listings[source.resource]
+		if !ok {
+			listing = h.fetchRemote(source.resource, remoteFrom, remoteTo)
+			listings[source.resource] = listing
+		}
+		if listing.err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", name, listing.err))
+			failed++
+			continue
+		}
+		if !listing.complete {
+			run.Truncated = append(run.Truncated, name)
+		}
+
+		localFrom, localTo := from, to
+		if !source.windowed {
+			localFrom, localTo = time.Time{}, time.Now().Add(time.Minute)
+		}
+		local, err := source.loadLocal(localFrom, localTo)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", name, err))
+			failed++
+			continue
+		}
+
+		remote := make([]reconcile.Record, 0, len(listing.items))
+		for _, item := range listing.items {
+			remote = append(remote, source.remoteKey(item))
+		}
+
+		run.Checked += len(local)
+		for _, d := range reconcile.Compare(name, local, remote, source.rules, listing.complete) {
+			item, err := saveReconciliationItem(run.ID, d)
+			if err != nil {
+				return nil, err
+			}
+			run.Discrepancies++
+			if run.Summary[name] == nil {
+				run.Summary[name] = map[string]int{}
+			}
+			run.Summary[name][d.Type]++
+
+			if autoFix && item.FixAction != "" {
+				if err := applyReconciliationFix(item.ID); err != nil {
+					fmt.Printf("Warning: Failed to auto-fix reconciliation item %d: %v\n", item.ID, err)
+					continue
+				}
+				run.Fixed++
+			}
+		}
+	}
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Error = strings.Join(errors, "; ")
+	run.Status = "completed"
+	if failed > 0 && failed == len(sources) {
+		run.Status = "failed"
+	} else if failed > 0 {
+		run.Status = "partial"
+	}
+
+	var errorText interface{}
+	if run.Error != "" {
+		errorText = run.Error
+	}
+	_, err = database.DB.Exec(`
+		UPDATE reconciliation_runs
+		SET status = ?, checked = ?, discrepancies = ?, fixed = ?, truncated = ?, error = ?, finished_at = ?
+		WHERE id = ?
+	`, run.Status, run.Checked, run.Discrepancies, run.Fixed, strings.Join(run.Truncated, ","), errorText, now, run.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish reconciliation run: %w", err)
+	}
+
+	return run, nil
+}
+
+func saveReconciliationItem(runID int, d reconcile.Discrepancy) (*ReconciliationItem, error) {
+	item := &ReconciliationItem{
+		RunID:     runID,
+		Source:    d.Source,
+		Key:       d.Key,
+		Type:      d.Type,
+		FixAction: d.Fix,
+		Status:    "open",
+		CreatedAt: time.Now(),
+	}
+
+	var remoteData interface{}
+	if d.Local != nil {
+		item.LocalID = &d.Local.LocalID
+		item.LocalAmount = &d.Local.Amount
+		item.LocalStatus = d.Local.Status
+	}
+	if d.Remote != nil {
+		item.RemoteAmount = &d.Remote.Amount
+		item.RemoteStatus = d.Remote.Status
+		if d.RemoteStatus != "" {
+			item.RemoteStatus = d.RemoteStatus
+		}
+		if encoded, err := json.Marshal(d.Remote.Raw); err == nil {
+			remoteData = string(encoded)
+		}
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO reconciliation_items (
+			run_id, source, item_key, type, local_id, local_amount, remote_amount,
+			local_status, remote_status, fix_action, remote_data, status, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 'open', ?)
+	`, runID, item.Source, item.Key, item.Type, item.LocalID, item.LocalAmount, item.RemoteAmount,
+		item.LocalStatus, item.RemoteStatus, item.FixAction, remoteData, item.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save reconciliation item: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	item.ID = int(id)
+	return item, nil
+}
+
+// applyReconciliationFix applies an open item's fix action and marks it fixed
+func applyReconciliationFix(itemID int) error {
+	var key, action, status string
+	var localID sql.NullInt64
+	var remoteStatus, remoteData sql.NullString
+	err := database.DB.QueryRow(
+		"SELECT item_key, COALESCE(fix_action, ''), status, local_id, remote_status, remote_data FROM reconciliation_items WHERE id = ?",
+		itemID,
+	).Scan(&key, &action, &status, &localID, &remoteStatus, &remoteData)
+	if err != nil {
+		return fmt.Errorf("failed to load reconciliation item: %w", err)
+	}
+	if status != "open" {
+		return fmt.Errorf("item is already %s", status)
+	}
+	if action == "" {
+		return fmt.Errorf("item has no safe fix and needs manual review")
+	}
+
+	var remote map[string]interface{}
+	if remoteData.Valid {
+		if err := json.Unmarshal([]byte(remoteData.String), &remote); err != nil {
+			return fmt.Errorf("failed to read Paystack's record: %w", err)
+		}
+	}
+
+	// Claim the item so two requests can't apply the same fix; it is reopened if the fix fails
+	claim, err := database.DB.Exec("UPDATE reconciliation_items SET status = 'fixing' WHERE id = ? AND status = 'open'", itemID)
+	if err != nil {
+		return fmt.Errorf("failed to claim reconciliation item: %w", err)
+	}
+	if claimed, _ := claim.RowsAffected(); claimed == 0 {
+		return fmt.Errorf("item is already being fixed or resolved")
+	}
+	applied := false
+	defer func() {
+		if !applied {
+			database.DB.Exec("UPDATE reconciliation_items SET status = 'open' WHERE id = ? AND status = 'fixing'", itemID)
+		}
+	}()
+
+	remoteString := func(path ...string) string {
+		var value interface{} = remote
+		for _, p := range path {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return ""
+			}
+			value = m[p]
+		}
+		s, _ := value.(string)
+		return s
+	}
+	remoteAmount := 0
+	if amount, ok := remote["amount"].(float64); ok {
+		remoteAmount = int(amount)
+	}
+	now := time.Now()
+
+	switch action {
+	case fixMarkExpensePaid:
+		paidAt := now
+		if parsed, err := time.Parse(time.RFC3339, remoteString("updatedAt")); err == nil {
+			paidAt = parsed
+		}
+		var result sql.Result
+		result, err = database.DB.Exec(
+			"UPDATE expenses SET status = 'paid', payment_date = ?, updated_at = ? WHERE id = ? AND status = 'pending'",
+			paidAt, now, localID.Int64,
+		)
+		// An expense that is no longer pending was changed since the run; don't post it again
+		if err == nil {
+			if updated, _ := result.RowsAffected(); updated == 0 {
+				err = fmt.Errorf("expense %d is no longer pending", localID.Int64)
+			}
+		}
+
+	case fixRecordTransfer:
+		createdAt := now
+		if parsed, err := time.Parse(time.RFC3339, remoteString("createdAt")); err == nil {
+			createdAt = parsed
+		}
+		currency := remoteString("currency")
+		if currency == "" {
+			currency = "NGN"
+		}
+		_, err = database.DB.Exec(
+			"INSERT INTO outgoing_payments (recipient_code, amount, currency, source, reference, created_at) VALUES (?, ?, ?, 'transfer', ?, ?)",
+			remoteString("recipient", "recipient_code"), remoteAmount, currency, key, createdAt,
+		)
+
+	case fixInsertInvoice:
+		customerID := remoteString("customer", "customer_code")
+		customerName := strings.TrimSpace(remoteString("customer", "first_name") + " " + remoteString("customer", "last_name"))
+		if customerName == "" {
+			customerName = remoteString("customer", "email")
+		}
+		_, err = database.DB.Exec(`
+			INSERT OR IGNORE INTO invoices (invoice_code, customer_id, customer_name, amount, status, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, key, customerID, customerName, remoteAmount, remoteString("status"), now, now)
+
+	case fixUpdateInvoiceStatus:
+		_, err = database.DB.Exec("UPDATE invoices SET status = ?, updated_at = ? WHERE invoice_code = ?", remoteStatus.String, now, key)
+
+	case fixCacheTransaction:
+		var txn paystackSDK.Transaction
+		if err = json.Unmarshal([]byte(remoteData.String), &txn); err == nil {
+			if cacheTransactions([]paystackSDK.Transaction{txn}) == 0 {
+				err = fmt.Errorf("transaction could not be cached")
+			}
+		}
+
+	case fixUpdateTransactionState:
+		_, err = database.DB.Exec("UPDATE transactions SET status = ?, synced_at = ? WHERE reference = ?", remoteStatus.String, now, key)
+
+	case fixInsertRecipient:
+		name := remoteString("name")
+		_, err = database.DB.Exec(`
+			INSERT OR IGNORE INTO recipients (recipient_code, type, name, account_number, bank_code, bank_name, currency, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, key, remoteString("type"), name, remoteString("details", "account_number"), remoteString("details", "bank_code"),
+			remoteString("details", "bank_name"), remoteString("currency"), now, now)
+
+	default:
+		return fmt.Errorf("unknown fix action: %s", action)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply %s: %w", action, err)
+	}
+	applied = true
+
+	// Fixes that change money state post to the ledger
+	switch action {
+	case fixMarkExpensePaid:
+		err = PostExpenseJournal(localID.Int64)
+	case fixRecordTransfer:
+		var paymentID int64
+		if err = database.DB.QueryRow("SELECT id FROM outgoing_payments WHERE source = 'transfer' AND reference = ? ORDER BY id DESC LIMIT 1", key).Scan(&paymentID); err == nil {
+			err = PostTransferJournal(paymentID)
+		}
+	case fixInsertInvoice, fixUpdateInvoiceStatus:
+		err = PostInvoiceJournal(key)
+	}
+	postLedger(action+" "+key, err)
+
+	_, err = database.DB.Exec(
+		"UPDATE reconciliation_items SET status = 'fixed', note = ?, resolved_at = ? WHERE id = ? AND status = 'fixing'",
+		"Applied "+action, now, itemID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark item fixed: %w", err)
+	}
+	return nil
+}
+
+// StartReconciliation runs a reconciliation of all sources every interval.
+// Scheduled runs only report; fixes are applied on request.
+func StartReconciliation(client *paystack.Client, interval time.Duration) {
+	h := NewReconciliationHandler(client)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			to := time.Now()
+			run, err := h.runReconciliation(reconciliationSourceOrder, to.Add(-reconciliationWindow), to, false)
+			if err != nil {
+				log.Printf("Reconciliation failed: %v", err)
+			} else if run != nil {
+				log.Printf("Reconciliation run %d: %d checked, %d discrepancies", run.ID, run.Checked, run.Discrepancies)
+			}
+		}
+	}()
+}
+
+type RunReconciliationRequest struct {
+	Sources []string `json:"sources,omitempty"`
+	From    string   `json:"from,omitempty"`
+	To      string   `json:"to,omitempty"`
+	AutoFix bool     `json:"auto_fix,omitempty"`
+}
+
+// Run reconciles local data against Paystack now
+func (h *ReconciliationHandler) Run(w http.ResponseWriter, r *http.Request) {
+	var req RunReconciliationRequest
+	if r.Body != http.NoBody {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteJSONBadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	sources := reconciliationSourceOrder
+	if len(req.Sources) > 0 {
+		sources = nil
+		for _, name := range req.Sources {
+			if _, ok := reconciliationSources[name]; !ok {
+				WriteJSONBadRequest(w, fmt.Sprintf("unknown source %q, use one of: %s", name, strings.Join(reconciliationSourceOrder, ", ")))
+				return
+			}
+			sources = append(sources, name)
+		}
+	}
+
+	to := time.Now()
+	from := to.Add(-reconciliationWindow)
+	if req.From != "" {
+		parsed, err := parseRangeTime(req.From, false)
+		if err != nil {
+			WriteJSONBadRequest(w, fmt.Sprintf("from: %v", err))
+			return
+		}
+		from = parsed
+	}
+	if req.To != "" {
+		parsed, err := parseRangeTime(req.To, true)
+		if err != nil {
+			WriteJSONBadRequest(w, fmt.Sprintf("to: %v", err))
+			return
+		}
+		to = parsed
+	}
+	if !from.Before(to) {
+		WriteJSONBadRequest(w, "from must be before to")
+		return
+	}
+
+	run, err := h.runReconciliation(sources, from, to, req.AutoFix)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if run == nil {
+		WriteJSONError(w, fmt.Errorf("a reconciliation run is already in progress"), http.StatusConflict)
+		return
+	}
+	if run.Status == "failed" {
+		WriteJSONError(w, fmt.Errorf("reconciliation run %d failed: %s", run.ID, run.Error), http.StatusBadGateway)
+		return
+	}
+
+	items, err := listReconciliationItems(run.ID, "", "", "")
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	run.Items = items
+
+	message := fmt.Sprintf("Found %d discrepancies", run.Discrepancies)
+	if run.Fixed > 0 {
+		message += fmt.Sprintf(", fixed %d", run.Fixed)
+	}
+	WriteJSONSuccessWithMessage(w, message, run)
+}
+
+const reconciliationRunColumns = `
+	id, status, sources, window_from, window_to, auto_fix, checked, discrepancies, fixed,
+	COALESCE(truncated, ''), COALESCE(error, ''), started_at, finished_at`
+
+func scanReconciliationRun(row rowScanner) (*ReconciliationRun, error) {
+	var run ReconciliationRun
+	var sources, truncated string
+	var from, to, finishedAt sql.NullTime
+	err := row.Scan(
+		&run.ID,
+		&run.Status,
+		&sources,
+		&from,
+		&to,
+		&run.AutoFix,
+		&run.Checked,
+		&run.Discrepancies,
+		&run.Fixed,
+		&truncated,
+		&run.Error,
+		&run.StartedAt,
+		&finishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	run.Sources = strings.Split(sources, ",")
+	if truncated != "" {
+		run.Truncated = strings.Split(truncated, ",")
+	}
+	run.From = nullTimePtr(from)
+	run.To = nullTimePtr(to)
+	run.FinishedAt = nullTimePtr(finishedAt)
+	return &run, nil
+}
+
+// ListRuns lists recent reconciliation runs
+func (h *ReconciliationHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+
+	rows, err := database.DB.Query("SELECT "+reconciliationRunColumns+" FROM reconciliation_runs ORDER BY id DESC LIMIT ?", limit)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to list reconciliation runs: %w", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	runs := []ReconciliationRun{}
+	for rows.Next() {
+		run, err := scanReconciliationRun(rows)
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to scan reconciliation run: %w", err), http.StatusInternalServerError)
+			return
+		}
+		runs = append(runs, *run)
+	}
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"runs":  runs,
+		"count": len(runs),
+	})
+}
+
+// GetRun returns a run with its discrepancies, filtered by ?source=, ?type= and ?status=
+func (h *ReconciliationHandler) GetRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteJSONBadRequest(w, "Invalid run ID")
+		return
+	}
+
+	run, err := scanReconciliationRun(database.DB.QueryRow("SELECT "+reconciliationRunColumns+" FROM reconciliation_runs WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		WriteJSONError(w, fmt.Errorf("reconciliation run not found: %d", id), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to fetch reconciliation run: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	q := r.URL.Query()
+	items, err := listReconciliationItems(run.ID, q.Get("source"), q.Get("type"), q.Get("status"))
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	run.Items = items
+
+	run.Summary = map[string]map[string]int{}
+	for _, item := range items {
+		if run.Summary[item.Source] == nil {
+			run.Summary[item.Source] = map[string]int{}
+		}
+		run.Summary[item.Source][item.Type]++
+	}
+
+	WriteJSONSuccess(w, run)
+}
+
+func listReconciliationItems(runID int, source, itemType, status string) ([]ReconciliationItem, error) {
+	query := `
+		SELECT id, run_id, source, item_key, type, local_id, local_amount, remote_amount,
+		       COALESCE(local_status, ''), COALESCE(remote_status, ''), COALESCE(fix_action, ''),
+		       status, COALESCE(note, ''), created_at, resolved_at
+		FROM reconciliation_items WHERE run_id = ?`
+	args := []interface{}{runID}
+
+	if source != "" {
+		query += " AND source = ?"
+		args = append(args, source)
+	}
+	if itemType != "" {
+		query += " AND type = ?"
+		args = append(args, itemType)
+	}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY id"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconciliation items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []ReconciliationItem{}
+	for rows.Next() {
+		item, err := scanReconciliationItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, nil
+}
+
+func scanReconciliationItem(row rowScanner) (*ReconciliationItem, error) {
+	var item ReconciliationItem
+	var localID, localAmount, remoteAmount sql.NullInt64
+	var resolvedAt sql.NullTime
+	err := row.Scan(
+		&item.ID,
+		&item.RunID,
+		&item.Source,
+		&item.Key,
+		&item.Type,
+		&localID,
+		&localAmount,
+		&remoteAmount,
+		&item.LocalStatus,
+		&item.RemoteStatus,
+		&item.FixAction,
+		&item.Status,
+		&item.Note,
+		&item.CreatedAt,
+		&resolvedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan reconciliation item: %w", err)
+	}
+	if localID.Valid {
+		item.LocalID = &localID.Int64
+	}
+	if localAmount.Valid {
+		item.LocalAmount = &localAmount.Int64
+	}
+	if remoteAmount.Valid {
+		item.RemoteAmount = &remoteAmount.Int64
+	}
+	item.ResolvedAt = nullTimePtr(resolvedAt)
+	return &item, nil
+}
+
+func getReconciliationItem(w http.ResponseWriter, r *http.Request) (*ReconciliationItem, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteJSONBadRequest(w, "Invalid item ID")
+		return nil, false
+	}
+
+	item, err := scanReconciliationItem(database.DB.QueryRow(`
+		SELECT id, run_id, source, item_key, type, local_id, local_amount, remote_amount,
+		       COALESCE(local_status, ''), COALESCE(remote_status, ''), COALESCE(fix_action, ''),
+		       status, COALESCE(note, ''), created_at, resolved_at
+		FROM reconciliation_items WHERE id = ?
+	`, id))
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("reconciliation item not found: %d", id), http.StatusNotFound)
+		return nil, false
+	}
+	return item, true
+}
+
+// FixItem applies the safe fix for one discrepancy
+func (h *ReconciliationHandler) FixItem(w http.ResponseWriter, r *http.Request) {
+	item, ok := getReconciliationItem(w, r)
+	if !ok {
+		return
+	}
+
+	if err := applyReconciliationFix(item.ID); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	item, ok = getReconciliationItem(w, r)
+	if !ok {
+		return
+	}
+	WriteJSONSuccessWithMessage(w, "Discrepancy fixed", item)
+}
+
+type ResolveReconciliationItemRequest struct {
+	Note string `json:"note"`
+}
+
+// ResolveItem marks a discrepancy as reviewed without changing any records
+func (h *ReconciliationHandler) ResolveItem(w http.ResponseWriter, r *http.Request) {
+	item, ok := getReconciliationItem(w, r)
+	if !ok {
+		return
+	}
+
+	var req ResolveReconciliationItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Note) == "" {
+		WriteJSONBadRequest(w, "note is required")
+		return
+	}
+	if item.Status != "open" {
+		WriteJSONBadRequest(w, fmt.Sprintf("Item is already %s", item.Status))
+		return
+	}
+
+	now := time.Now()
+	result, err := database.DB.Exec(
+		"UPDATE reconciliation_items SET status = 'resolved', note = ?, resolved_at = ? WHERE id = ? AND status = 'open'",
+		req.Note, now, item.ID,
+	)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to resolve item: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if resolved, _ := result.RowsAffected(); resolved == 0 {
+		WriteJSONBadRequest(w, "Item is already being fixed or resolved")
+		return
+	}
+	item.Status = "resolved"
+	item.Note = req.Note
+	item.ResolvedAt = &now
+
+	WriteJSONSuccessWithMessage(w, "Discrepancy resolved", item)
+}
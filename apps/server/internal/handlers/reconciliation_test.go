@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+)
+
+// addExpenseFix adds a pending expense and an open item that marks it paid, returning both IDs
+func addExpenseFix(t *testing.T, remoteData string) (int64, int) {
+	t.Helper()
+	now := time.Now()
+	expense, err := database.DB.Exec(
+		"INSERT INTO expenses (recipient_code, recipient_name, amount, currency, narration, reference, status) VALUES (?, 'Service provider', 500000, 'NGN', 'Rent', 'EXP_rent', 'pending')",
+		defaultRecipientCode,
+	)
+	if err != nil {
+		t.Fatalf("Failed to add expense: %v", err)
+	}
+	expenseID, _ := expense.LastInsertId()
+
+	run, err := database.DB.Exec(
+		"INSERT INTO reconciliation_runs (status, sources, window_from, window_to, auto_fix, started_at) VALUES ('completed', 'expenses', ?, ?, 0, ?)",
+		now.Add(-reconciliationWindow), now, now,
+	)
+	if err != nil {
+		t.Fatalf("Failed to add run: %v", err)
+	}
+	runID, _ := run.LastInsertId()
+	item, err := database.DB.Exec(`
+		INSERT INTO reconciliation_items (run_id, source, item_key, type, local_id, local_status, remote_status, fix_action, remote_data, status, created_at)
+		VALUES (?, 'expenses', 'EXP_rent', 'status_mismatch', ?, 'pending', 'success', ?, ?, 'open', ?)
+	`, runID, expenseID, fixMarkExpensePaid, remoteData, now)
+	if err != nil {
+		t.Fatalf("Failed to add item: %v", err)
+	}
+	itemID, _ := item.LastInsertId()
+	return expenseID, int(itemID)
+}
+
+func itemStatus(t *testing.T, itemID int) string {
+	t.Helper()
+	var status string
+	if err := database.DB.QueryRow("SELECT status FROM reconciliation_items WHERE id = ?", itemID).Scan(&status); err != nil {
+		t.Fatalf("Failed to load item: %v", err)
+	}
+	return status
+}
+
+func TestReconciliationFixIsAppliedOnce(t *testing.T) {
+	setupHandlerDB(t)
+	_, itemID := addExpenseFix(t, `{"status":"success"}`)
+
+	if err := applyReconciliationFix(itemID); err != nil {
+		t.Fatalf("Expected the fix to be applied, got %v", err)
+	}
+	if got := itemStatus(t, itemID); got != "fixed" {
+		t.Errorf("Expected the item to be fixed, got %s", got)
+	}
+	if err := applyReconciliationFix(itemID); err == nil {
+		t.Errorf("Expected a second fix to be refused")
+	}
+}
+
+func TestReconciliationFixIsRefusedWhileAnotherIsApplyingIt(t *testing.T) {
+	setupHandlerDB(t)
+	_, itemID := addExpenseFix(t, `{"status":"success"}`)
+	if _, err := database.DB.Exec("UPDATE reconciliation_items SET status = 'fixing' WHERE id = ?", itemID); err != nil {
+		t.Fatalf("Failed to claim item: %v", err)
+	}
+
+	if err := applyReconciliationFix(itemID); err == nil {
+		t.Errorf("Expected the fix to be refused while it is being applied")
+	}
+	if got := itemStatus(t, itemID); got != "fixing" {
+		t.Errorf("Expected the other fix to keep its claim, got %s", got)
+	}
+}
+
+func TestReconciliationFixFailsWhenTheExpenseChanged(t *testing.T) {
+	setupHandlerDB(t)
+	expenseID, itemID := addExpenseFix(t, `{"status":"success"}`)
+	if _, err := database.DB.Exec("UPDATE expenses SET status = 'cancelled' WHERE id = ?", expenseID); err != nil {
+		t.Fatalf("Failed to cancel expense: %v", err)
+	}
+
+	if err := applyReconciliationFix(itemID); err == nil {
+		t.Fatalf("Expected the fix to fail for an expense that is no longer pending")
+	}
+	if got := itemStatus(t, itemID); got != "open" {
+		t.Errorf("Expected the item to be reopened, got %s", got)
+	}
+	var entries int
+	database.DB.QueryRow("SELECT COUNT(*) FROM journal_entries").Scan(&entries)
+	if entries != 0 {
+		t.Errorf("Expected nothing to be posted to the ledger, got %d entries", entries)
+	}
+}
+
+func TestReconciliationFixFailsOnUnreadablePaystackRecord(t *testing.T) {
+	setupHandlerDB(t)
+	_, itemID := addExpenseFix(t, `{not json`)
+
+	if err := applyReconciliationFix(itemID); err == nil {
+		t.Fatalf("Expected the fix to fail")
+	}
+	if got := itemStatus(t, itemID); got != "open" {
+		t.Errorf("Expected the item to stay open, got %s", got)
+	}
+}
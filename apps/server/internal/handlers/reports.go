@@ -0,0 +1,439 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Reports Handler - Monthly Statements
+//
+// OBJECTIVES:
+// Lists and the snapshot answer "what is happening now", but there is no record of
+// how a month went that can be read back, shared or filed once the month is over.
+//
+// PURPOSE:
+// - Produce a statement for a calendar month: spending by category and recipient,
+//   budget vs actual for every budget_limits row in the month, goals achieved or
+//   missed, invoices issued and paid, and changes on the month before
+// - Store each statement as a point-in-time snapshot
+// - Serve stored statements as JSON, HTML or PDF
+//
+// KEY WORKFLOW:
+// Parse Period → Load the Month and the Month Before → Convert to the Statement
+// Currency → Build → Store Snapshot → Render on Request
+//
+// DESIGN DECISIONS:
+// - The statement is built once and stored as JSON; HTML and PDF render that JSON,
+//   so editing or deleting old records never changes a statement already issued
+// - Generating again makes a new snapshot rather than replacing the old one
+// - The month-level arithmetic lives in internal/report; this file loads rows
+// - Expenses count from their payment date (created_at until paid) and exclude
+//   cancelled, refunded and failed ones, matching the ledger
+// - Invoices count as issued on created_at and as paid on updated_at once their status
+//   is paid, since the cache doesn't record when payment arrived
+// - A goal is missed when it is marked failed, or when its end date passed in the month
+//   while it was still pending
+// - Amounts in other currencies are converted at the rate on the day they happened;
+//   any without a rate are listed as excluded rather than dropped silently
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+	"paystack.mpc.proxy/internal/report"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Report formats
+const (
+	reportFormatJSON = "json"
+	reportFormatHTML = "html"
+	reportFormatPDF  = "pdf"
+)
+
+var errReportNotFound = errors.New("report not found")
+
+type ReportHandler struct{}
+
+func NewReportHandler() *ReportHandler {
+	return &ReportHandler{}
+}
+
+// GenerateReportRequest asks for a statement. Period defaults to last month.
+type GenerateReportRequest struct {
+	Period   string         `json:"period,omitempty"`
+	Currency money.Currency `json:"currency,omitempty"`
+}
+
+// StoredReport is a statement snapshot with its ID
+type StoredReport struct {
+	ID int `json:"id"`
+	*report.Report
+}
+
+// ReportSummary describes a stored statement without its contents
+type ReportSummary struct {
+	ID          int            `json:"id"`
+	Period      string         `json:"period"`
+	Currency    money.Currency `json:"currency"`
+	GeneratedAt time.Time      `json:"generated_at"`
+}
+
+// Generate builds a statement for a month and stores it
+func (h *ReportHandler) Generate(w http.ResponseWriter, r *http.Request) {
+	var req GenerateReportRequest
+	if r.Body != nil && r.Body != http.NoBody {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteJSONBadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	period := report.MonthOf(now).Previous()
+	if req.Period != "" {
+		var err error
+		if period, err = report.ParsePeriod(req.Period); err != nil {
+			WriteJSONBadRequest(w, err.Error())
+			return
+		}
+		if period.Start.After(now) {
+			WriteJSONBadRequest(w, fmt.Sprintf("%s hasn't started yet", period.Title()))
+			return
+		}
+	}
+	currency := req.Currency.OrDefault()
+
+	in, err := loadReportInput(period, currency, now)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	built := report.Build(period, currency, in, now)
+
+	stored, err := saveReport(built)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, fmt.Sprintf("Statement for %s generated", built.Title), stored)
+}
+
+// List returns stored statements, newest first. Query: period (YYYY-MM), currency.
+func (h *ReportHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT id, period, currency, generated_at FROM reports WHERE 1=1"
+	var args []interface{}
+	if period := r.URL.Query().Get("period"); period != "" {
+		query += " AND period = ?"
+		args = append(args, period)
+	}
+	if currency := r.URL.Query().Get("currency"); currency != "" {
+		query += " AND currency = ?"
+		args = append(args, strings.ToUpper(currency))
+	}
+	query += " ORDER BY period DESC, id DESC"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to query reports: %w", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	reports := []ReportSummary{}
+	for rows.Next() {
+		var s ReportSummary
+		if err := rows.Scan(&s.ID, &s.Period, &s.Currency, &s.GeneratedAt); err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to scan report: %w", err), http.StatusInternalServerError)
+			return
+		}
+		reports = append(reports, s)
+	}
+	if err := rows.Err(); err != nil {
+		WriteJSONError(w, fmt.Errorf("error iterating reports: %w", err), http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccess(w, reports)
+}
+
+// Get returns a stored statement. Query: format=json (default), html or pdf.
+func (h *ReportHandler) Get(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = reportFormatJSON
+	}
+	if format != reportFormatJSON && format != reportFormatHTML && format != reportFormatPDF {
+		WriteJSONBadRequest(w, "format must be one of: json, html, pdf")
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		WriteJSONBadRequest(w, "id must be a positive integer")
+		return
+	}
+	stored, err := loadReport(id)
+	if errors.Is(err, errReportNotFound) {
+		WriteJSONError(w, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if format == reportFormatJSON {
+		WriteJSONSuccess(w, stored)
+		return
+	}
+
+	// Render fully before writing so a failure can still be reported as JSON
+	var buf bytes.Buffer
+	contentType := "text/html; charset=utf-8"
+	if format == reportFormatPDF {
+		contentType = "application/pdf"
+		err = report.WritePDF(&buf, stored.Report)
+	} else {
+		err = report.WriteHTML(&buf, stored.Report)
+	}
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to render report: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if format == reportFormatPDF {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-%s-%s.pdf"`, stored.Period, strings.ToLower(string(stored.Currency))))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// saveReport stores a statement snapshot
+func saveReport(built *report.Report) (*StoredReport, error) {
+	data, err := json.Marshal(built)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	result, err := database.DB.Exec("INSERT INTO reports (period, currency, data, generated_at) VALUES (?, ?, ?, ?)",
+		built.Period, built.Currency, string(data), built.GeneratedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save report: %w", err)
+	}
+	id, _ := result.LastInsertId()
+	return &StoredReport{ID: int(id), Report: built}, nil
+}
+
+// loadReport reads a statement snapshot back
+func loadReport(id int) (*StoredReport, error) {
+	var data string
+	err := database.DB.QueryRow("SELECT data FROM reports WHERE id = ?", id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, errReportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load report: %w", err)
+	}
+	stored := &StoredReport{ID: id, Report: &report.Report{}}
+	if err := json.Unmarshal([]byte(data), stored.Report); err != nil {
+		return nil, fmt.Errorf("failed to decode report %d: %w", id, err)
+	}
+	return stored, nil
+}
+
+// reportConverter brings amounts into the statement currency, recording what it can't convert
+type reportConverter struct {
+	currency money.Currency
+	excluded []report.Excluded
+}
+
+func (c *reportConverter) convert(kind, description string, amount money.Money, on time.Time) (int64, bool) {
+	converted, _, err := ConvertMoney(amount, c.currency, on)
+	if err != nil {
+		c.excluded = append(c.excluded, report.Excluded{Kind: kind, Description: description, Money: amount, Reason: err.Error()})
+		return 0, false
+	}
+	return converted.Minor(), true
+}
+
+// loadReportInput reads the month and the month before it
+func loadReportInput(period report.Period, currency money.Currency, now time.Time) (report.Input, error) {
+	conv := &reportConverter{currency: currency}
+	from := period.Previous().Start
+
+	expenses, err := reportExpenses(conv, from, period.End)
+	if err != nil {
+		return report.Input{}, err
+	}
+	invoices, err := reportInvoices(conv, from, period.End)
+	if err != nil {
+		return report.Input{}, err
+	}
+	budgets, err := reportBudgets(period)
+	if err != nil {
+		return report.Input{}, err
+	}
+	achieved, failed, err := reportGoals(period, now)
+	if err != nil {
+		return report.Input{}, err
+	}
+
+	return report.Input{
+		Expenses: expenses,
+		Invoices: invoices,
+		Budgets:  budgets,
+		Achieved: achieved,
+		Failed:   failed,
+		Excluded: conv.excluded,
+	}, nil
+}
+
+// reportExpenses loads expenses that went out between from and to
+func reportExpenses(conv *reportConverter, from, to time.Time) ([]report.Expense, error) {
+	rows, err := database.DB.Query(`
+		SELECT recipient_name, amount, COALESCE(currency, 'NGN'), COALESCE(category, ''), created_at, payment_date
+		FROM expenses
+		WHERE COALESCE(status, '') NOT IN (`+inPlaceholders(len(reversedExpenseStatuses))+`)
+		  AND COALESCE(payment_date, created_at) >= ? AND COALESCE(payment_date, created_at) < ?
+	`, append(sortedKeys(reversedExpenseStatuses), from, to)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var expenses []report.Expense
+	for rows.Next() {
+		var e report.Expense
+		var amount money.Money
+		var paymentDate sql.NullTime
+		if err := rows.Scan(&e.Recipient, &amount.Amount, &amount.Currency, &e.Category, &e.Date, &paymentDate); err != nil {
+			return nil, fmt.Errorf("failed to scan expense: %w", err)
+		}
+		if paymentDate.Valid {
+			e.Date = paymentDate.Time
+		}
+		var ok bool
+		if e.Amount, ok = conv.convert("expense", "Expense to "+e.Recipient, amount, e.Date); ok {
+			expenses = append(expenses, e)
+		}
+	}
+	return expenses, rows.Err()
+}
+
+// reportInvoices loads invoices issued or paid between from and to
+func reportInvoices(conv *reportConverter, from, to time.Time) ([]report.Invoice, error) {
+	paid := sortedKeys(paidInvoiceStatuses)
+	args := append([]interface{}{}, paid...)
+	args = append(args, from, to)
+	args = append(args, paid...)
+	args = append(args, from, to)
+	rows, err := database.DB.Query(`
+		SELECT invoice_code, customer_name, amount, COALESCE(currency, 'NGN'), created_at, updated_at,
+		       COALESCE(status, '') IN (`+inPlaceholders(len(paid))+`)
+		FROM invoices
+		WHERE COALESCE(status, '') != 'cancelled'
+		  AND ((created_at >= ? AND created_at < ?)
+		    OR (COALESCE(status, '') IN (`+inPlaceholders(len(paid))+`) AND updated_at >= ? AND updated_at < ?))
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []report.Invoice
+	for rows.Next() {
+		var inv report.Invoice
+		var amount money.Money
+		var updatedAt time.Time
+		var isPaid bool
+		if err := rows.Scan(&inv.Code, &inv.Customer, &amount.Amount, &amount.Currency, &inv.IssuedAt, &updatedAt, &isPaid); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		if isPaid {
+			inv.PaidAt = &updatedAt
+		}
+		var ok bool
+		if inv.Amount, ok = conv.convert("invoice", "Invoice "+inv.Code+" to "+inv.Customer, amount, inv.IssuedAt); ok {
+			invoices = append(invoices, inv)
+		}
+	}
+	return invoices, rows.Err()
+}
+
+// reportBudgets loads every budget whose period overlaps the month
+func reportBudgets(period report.Period) ([]report.Budget, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, name, limit_type, period_start, period_end, amount, COALESCE(spent_amount, 0), COALESCE(currency, 'NGN')
+		FROM budget_limits
+		WHERE period_start < ? AND period_end >= ?
+		ORDER BY period_start, id
+	`, period.End, period.Start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var budgets []report.Budget
+	for rows.Next() {
+		var b report.Budget
+		var limit, spent int64
+		var currency money.Currency
+		if err := rows.Scan(&b.ID, &b.Name, &b.LimitType, &b.PeriodStart, &b.PeriodEnd, &limit, &spent, &currency); err != nil {
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+		b.Limit, b.Spent = money.New(limit, currency), money.New(spent, currency)
+		budgets = append(budgets, b)
+	}
+	return budgets, rows.Err()
+}
+
+// reportGoals loads goals achieved in the month and goals missed in it
+func reportGoals(period report.Period, now time.Time) (achieved, failed []report.Goal, err error) {
+	// A pending goal has only been missed once its end date is behind us
+	cutoff := period.End
+	if now.Before(cutoff) {
+		cutoff = now
+	}
+	rows, err := database.DB.Query(`
+		SELECT g.id, g.title, g.target_amount, COALESCE(b.currency, 'NGN'),
+		       COALESCE((SELECT progress_amount FROM goal_ledger_progress WHERE goal_id = g.id), 0),
+		       g.status, g.achieved_at, g.end_date, g.updated_at
+		FROM goals g
+		LEFT JOIN budget_limits b ON b.id = g.budget_limit_id
+		WHERE (g.status = 'achieved' AND g.achieved_at >= ? AND g.achieved_at < ?)
+		   OR (g.status = 'failed' AND COALESCE(g.end_date, g.updated_at) >= ? AND COALESCE(g.end_date, g.updated_at) < ?)
+		   OR (g.status = 'pending' AND g.end_date >= ? AND g.end_date < ?)
+	`, period.Start, period.End, period.Start, period.End, period.Start, cutoff)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query goals: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g report.Goal
+		var target, progress int64
+		var currency money.Currency
+		var status string
+		var achievedAt, endDate sql.NullTime
+		if err := rows.Scan(&g.ID, &g.Title, &target, &currency, &progress, &status, &achievedAt, &endDate, &g.At); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		g.Target, g.Progress = money.New(target, currency), money.New(progress, currency)
+		if status == "achieved" {
+			g.At = achievedAt.Time
+			achieved = append(achieved, g)
+			continue
+		}
+		if endDate.Valid {
+			g.At = endDate.Time
+		}
+		failed = append(failed, g)
+	}
+	return achieved, failed, rows.Err()
+}
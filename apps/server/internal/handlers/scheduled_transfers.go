@@ -0,0 +1,782 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Scheduled Transfers Handler - Future-Dated Transfers
+//
+// OBJECTIVES:
+// Send a one-off transfer at a later time ("send 20k to Tunde on Friday") without
+// anyone having to be around to send it, and without sending it twice.
+//
+// PURPOSE:
+// - Queue a transfer for a future time, previewed and confirmed like an immediate one
+// - Cancel or reschedule it until it is sent
+// - Check the balance and transfer limits again when it is sent, not just when queued
+// - Keep a status history of every queued transfer
+//
+// KEY WORKFLOW:
+// Schedule Transfer (preview) → Confirm With Token → Worker Leases Due Transfer →
+// Recheck Balance and Limits → Initiate on Paystack → Sent, or Retry Later / Failed /
+// Needs Review
+//
+// DESIGN DECISIONS:
+// - The queue is the scheduled_transfers table, so queued transfers survive restarts
+// - A worker takes a due transfer by leasing it in a single UPDATE; only the lease
+//   holder may finish it, and a lease left by a crashed process expires and is taken over
+// - Each transfer gets its Paystack reference when it is queued; once a transfer
+//   has been initiated, later attempts look the reference up on Paystack first and
+//   only initiate when Paystack has never seen it, so nothing is sent twice
+// - Not enough balance, a transfer limit or Paystack refusing the transfer fails it;
+//   Paystack being unreachable retries it with backoff
+// - A transfer whose last attempt may have reached Paystack isn't failed: it keeps
+//   its limit reservation and waits in needs_review, and rescheduling it looks the
+//   reference up before anything is sent
+// - A transfer whose recipient was deleted after it was queued waits in needs_review
+//   too, to be rescheduled or cancelled; one merged into another recipient pays that one
+// - Every status change is written to scheduled_transfer_events
+// - Times are UTC; a date without a time means the start of that day
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/confirm"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+	"paystack.mpc.proxy/internal/paystack"
+
+	paystackSDK "github.com/borderlesshq/paystack-go"
+	"github.com/go-chi/chi/v5"
+)
+
+// Scheduled transfer statuses; the same names are used in the history
+const (
+	transferScheduled   = "scheduled"
+	transferSending     = "sending"
+	transferSent        = "sent"
+	transferFailed      = "failed"
+	transferCancelled   = "cancelled"
+	transferRescheduled = "rescheduled"
+	transferRetrying    = "retrying"
+	transferRecovered   = "recovered"
+	// transferNeedsReview is a transfer that failed after it may have reached Paystack
+	transferNeedsReview = "needs_review"
+)
+
+// errTransferUnconfirmed marks a failure after the transfer may have reached Paystack,
+// so the money may have gone and its reservation must be kept
+var errTransferUnconfirmed = errors.New("transfer may have been sent")
+
+const (
+	// transferLeaseDuration is how long a worker holds a transfer before another may take it
+	transferLeaseDuration = 5 * time.Minute
+	// maxScheduledTransferAttempts is how many times Paystack errors are retried in all
+	maxScheduledTransferAttempts = 3
+)
+
+// transferQueueOwner names this process on the leases it takes
+var transferQueueOwner = newLeaseOwner()
+
+func newLeaseOwner() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// ScheduledTransfer is a transfer queued for a future time
+type ScheduledTransfer struct {
+	ID            int    `json:"id"`
+	RecipientCode string `json:"recipient_code"`
+	RecipientName string `json:"recipient_name"`
+	money.Money
+	Source         string                   `json:"source"`
+	Reason         string                   `json:"reason,omitempty"`
+	Reference      string                   `json:"reference"`
+	ExecuteAt      time.Time                `json:"execute_at"`
+	Status         string                   `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	RetryAt        *time.Time               `json:"retry_at,omitempty"`
+	LeaseOwner     string                   `json:"lease_owner,omitempty"`
+	LeaseExpiresAt *time.Time               `json:"lease_expires_at,omitempty"`
+	InitiatedAt    *time.Time               `json:"initiated_at,omitempty"`
+	TransferCode   string                   `json:"transfer_code,omitempty"`
+	TransferStatus string                   `json:"transfer_status,omitempty"`
+	Error          string                   `json:"error,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+	History        []ScheduledTransferEvent `json:"history,omitempty"`
+
+	outgoingPaymentID sql.NullInt64
+}
+
+// ScheduledTransferEvent is one status change of a scheduled transfer
+type ScheduledTransferEvent struct {
+	ID        int       `json:"id"`
+	Status    string    `json:"status"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ScheduleTransferRequest struct {
+	Source string `json:"source,omitempty"`
+	money.Money
+	Recipient string `json:"recipient"`
+	Reason    string `json:"reason,omitempty"`
+	// ExecuteAt is when to send, as YYYY-MM-DD or RFC3339
+	ExecuteAt string `json:"execute_at"`
+
+	// ConfirmationToken queues a previously previewed transfer
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+type RescheduleTransferRequest struct {
+	ExecuteAt string `json:"execute_at"`
+}
+
+// parseExecuteAt reads a future send time
+func parseExecuteAt(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("execute_at is required")
+	}
+	executeAt, err := parseRangeTime(value, false)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("execute_at: %w", err)
+	}
+	if !executeAt.After(now) {
+		return time.Time{}, fmt.Errorf("execute_at must be in the future; use /transfers/initiate to send now")
+	}
+	return executeAt, nil
+}
+
+// describeExecuteAt says when a transfer goes, e.g. "on 23 October 2026 at 09:00 UTC"
+func describeExecuteAt(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 {
+		return "on " + t.Format("2 January 2006")
+	}
+	return "on " + t.Format("2 January 2006 at 15:04 MST")
+}
+
+// newTransferReference makes a Paystack transfer reference (lowercase, 16-50 characters)
+func newTransferReference() string {
+	b := make([]byte, 10)
+	rand.Read(b)
+	return "sxf_" + hex.EncodeToString(b)
+}
+
+// Schedule previews a future-dated transfer and queues it once the preview is confirmed
+func (h *TransferHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	var req ScheduleTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	// A confirmation token replaces the body with the previewed request; nothing else
+	// in the body is kept, so a field can't be added after the preview
+	confirmed := false
+	if req.ConfirmationToken != "" {
+		var previewed ScheduleTransferRequest
+		if !redeemConfirmation(w, req.ConfirmationToken, confirm.ActionScheduledTransfer, &previewed) {
+			return
+		}
+		req = previewed
+		confirmed = true
+	}
+
+	if req.Amount <= 0 || req.Recipient == "" {
+		WriteJSONBadRequest(w, "amount and recipient are required")
+		return
+	}
+	if req.Source == "" {
+		req.Source = "balance"
+	}
+	req.Currency = req.Currency.OrDefault()
+	if err := req.Validate(); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	now := time.Now().UTC()
+	executeAt, err := parseExecuteAt(req.ExecuteAt, now)
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	// Checked at preview, when queued and again when sent
+	if !resolvePayableRecipient(w, &req.Recipient) {
+		return
+	}
+	recipientName := h.recipientName(req.Recipient)
+
+	if !confirmed {
+		// Rolling limits may have room again by then, so a breach today is only a warning
+		check, err := CheckTransferLimits(req.Recipient, req.Money)
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("error checking transfer limits: %w", err), http.StatusInternalServerError)
+			return
+		}
+		alerts := check.Alerts
+		if !check.Allowed {
+			alerts = append(alerts, "If sent now: "+check.Reason)
+		}
+
+		when := describeExecuteAt(executeAt)
+		preview := ConfirmationPreview{
+			Money:         req.Money,
+			RecipientName: recipientName,
+			LimitAlerts:   alerts,
+			Schedule:      when,
+			Summary:       fmt.Sprintf("Send %s to %s %s", req.Format(), recipientName, when),
+		}
+		if budget, err := FindOrCreateDefaultBudget(); err == nil {
+			if impact, err := CheckBudgetAffordabilityOn(budget.ID, req.Money, executeAt); err == nil {
+				preview.BudgetImpact = impact
+			}
+		}
+
+		writeConfirmationPreview(w, confirm.ActionScheduledTransfer, req, preview)
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO scheduled_transfers (
+			recipient_code, recipient_name, amount, currency, source, reason, reference,
+			execute_at, status, created_at, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		req.Recipient, recipientName, req.Amount, req.Currency, req.Source, req.Reason, newTransferReference(),
+		executeAt, transferScheduled, now, now,
+	)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to schedule transfer: %w", err), http.StatusInternalServerError)
+		return
+	}
+	id, _ := result.LastInsertId()
+	recordTransferEvent(int(id), transferScheduled, "to send "+describeExecuteAt(executeAt))
+
+	respondWithScheduledTransfer(w, int(id), "Transfer scheduled")
+}
+
+const scheduledTransferColumns = `
+	id, recipient_code, recipient_name, amount, COALESCE(currency, 'NGN'), COALESCE(source, 'balance'),
+	COALESCE(reason, ''), reference, execute_at, status, attempts, retry_at, COALESCE(lease_owner, ''),
+	lease_expires_at, initiated_at, outgoing_payment_id, COALESCE(transfer_code, ''),
+	COALESCE(transfer_status, ''), COALESCE(error, ''), created_at, updated_at`
+
+func scanScheduledTransfer(row rowScanner) (*ScheduledTransfer, error) {
+	var t ScheduledTransfer
+	var retryAt, leaseExpiresAt, initiatedAt sql.NullTime
+	err := row.Scan(
+		&t.ID, &t.RecipientCode, &t.RecipientName, &t.Amount, &t.Currency, &t.Source,
+		&t.Reason, &t.Reference, &t.ExecuteAt, &t.Status, &t.Attempts, &retryAt, &t.LeaseOwner,
+		&leaseExpiresAt, &initiatedAt, &t.outgoingPaymentID, &t.TransferCode,
+		&t.TransferStatus, &t.Error, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range []struct {
+		src sql.NullTime
+		dst **time.Time
+	}{{retryAt, &t.RetryAt}, {leaseExpiresAt, &t.LeaseExpiresAt}, {initiatedAt, &t.InitiatedAt}} {
+		if v.src.Valid {
+			value := v.src.Time
+			*v.dst = &value
+		}
+	}
+	return &t, nil
+}
+
+// errScheduledTransferNotFound is returned when no scheduled transfer has the ID
+var errScheduledTransferNotFound = errors.New("scheduled transfer not found")
+
+func loadScheduledTransfer(id int) (*ScheduledTransfer, error) {
+	t, err := scanScheduledTransfer(database.DB.QueryRow("SELECT "+scheduledTransferColumns+" FROM scheduled_transfers WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %d", errScheduledTransferNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scheduled transfer %d: %w", id, err)
+	}
+	return t, nil
+}
+
+func loadTransferHistory(id int) ([]ScheduledTransferEvent, error) {
+	rows, err := database.DB.Query(
+		"SELECT id, status, COALESCE(detail, ''), created_at FROM scheduled_transfer_events WHERE scheduled_transfer_id = ? ORDER BY id",
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transfer history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []ScheduledTransferEvent{}
+	for rows.Next() {
+		var event ScheduledTransferEvent
+		if err := rows.Scan(&event.ID, &event.Status, &event.Detail, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer event: %w", err)
+		}
+		history = append(history, event)
+	}
+	return history, rows.Err()
+}
+
+// recordTransferEvent adds a status change to a scheduled transfer's history
+func recordTransferEvent(id int, status, detail string) {
+	_, err := database.DB.Exec(
+		"INSERT INTO scheduled_transfer_events (scheduled_transfer_id, status, detail, created_at) VALUES (?, ?, ?, ?)",
+		id, status, detail, time.Now().UTC(),
+	)
+	if err != nil {
+		fmt.Printf("Warning: failed to record history for scheduled transfer %d: %v\n", id, err)
+	}
+}
+
+func respondWithScheduledTransfer(w http.ResponseWriter, id int, message string) {
+	t, err := loadScheduledTransfer(id)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if t.History, err = loadTransferHistory(id); err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, message, t)
+}
+
+// RunDueTransfers sends every scheduled transfer that is due, plus any whose
+// lease has expired, and returns them as they ended up
+func RunDueTransfers(client *paystack.Client, now time.Time) ([]ScheduledTransfer, error) {
+	rows, err := database.DB.Query(`
+		SELECT id FROM scheduled_transfers
+		WHERE (status = ? AND execute_at <= ? AND (retry_at IS NULL OR retry_at <= ?))
+		   OR (status = ? AND lease_expires_at <= ?)
+		ORDER BY execute_at
+	`, transferScheduled, now, now, transferSending, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due transfers: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan scheduled transfer: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	processed := []ScheduledTransfer{}
+	for _, id := range ids {
+		t, err := leaseScheduledTransfer(id, now)
+		if err != nil {
+			fmt.Printf("Warning: scheduled transfer %d wasn't leased: %v\n", id, err)
+			continue
+		}
+		if t == nil {
+			// Another worker got there first
+			continue
+		}
+		runScheduledTransfer(client, t, now)
+		if done, err := loadScheduledTransfer(id); err == nil {
+			processed = append(processed, *done)
+		}
+	}
+	return processed, nil
+}
+
+// leaseScheduledTransfer takes a due transfer for this process, returning nil
+// when it is no longer due or another process holds it
+func leaseScheduledTransfer(id int, now time.Time) (*ScheduledTransfer, error) {
+	before, err := loadScheduledTransfer(id)
+	if err != nil {
+		return nil, err
+	}
+	result, err := database.DB.Exec(`
+		UPDATE scheduled_transfers
+		SET status = ?, lease_owner = ?, lease_expires_at = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id = ?
+		  AND ((status = ? AND execute_at <= ? AND (retry_at IS NULL OR retry_at <= ?))
+		    OR (status = ? AND lease_expires_at <= ?))
+	`,
+		transferSending, transferQueueOwner, now.Add(transferLeaseDuration), now,
+		id,
+		transferScheduled, now, now,
+		transferSending, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease scheduled transfer: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+
+	if before.Status == transferSending {
+		recordTransferEvent(id, transferRecovered, fmt.Sprintf("lease held by %s expired", before.LeaseOwner))
+	}
+	t, err := loadScheduledTransfer(id)
+	if err != nil {
+		return nil, err
+	}
+	recordTransferEvent(id, transferSending, fmt.Sprintf("attempt %d by %s", t.Attempts, transferQueueOwner))
+	return t, nil
+}
+
+// updateLeasedTransfer changes a transfer this process still holds the lease on
+func updateLeasedTransfer(id int, set string, args ...interface{}) error {
+	args = append(args, time.Now().UTC(), id, transferQueueOwner)
+	result, err := database.DB.Exec("UPDATE scheduled_transfers SET "+set+", updated_at = ? WHERE id = ? AND lease_owner = ?", args...)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled transfer %d: %w", id, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("lost the lease on scheduled transfer %d", id)
+	}
+	return nil
+}
+
+// runScheduledTransfer makes one attempt at a leased transfer and records how it ended
+func runScheduledTransfer(client *paystack.Client, t *ScheduledTransfer, now time.Time) {
+	transfer, retry, err := sendScheduledTransfer(client, t)
+	switch {
+	case err == nil:
+		if updateErr := updateLeasedTransfer(t.ID,
+			"status = ?, transfer_code = ?, transfer_status = ?, error = NULL, lease_owner = NULL, lease_expires_at = NULL",
+			transferSent, transfer.TransferCode, transfer.Status,
+		); updateErr != nil {
+			fmt.Printf("Warning: %v\n", updateErr)
+			return
+		}
+		recordTransferEvent(t.ID, transferSent, fmt.Sprintf("transfer %s is %s", transfer.TransferCode, transfer.Status))
+
+	case retry && t.Attempts < maxScheduledTransferAttempts:
+		retryAt := now.Add(retryDelay(t.Attempts))
+		if updateErr := updateLeasedTransfer(t.ID,
+			"status = ?, retry_at = ?, error = ?, lease_owner = NULL, lease_expires_at = NULL",
+			transferScheduled, retryAt, err.Error(),
+		); updateErr != nil {
+			fmt.Printf("Warning: %v\n", updateErr)
+			return
+		}
+		recordTransferEvent(t.ID, transferRetrying, fmt.Sprintf("%v; retrying at %s", err, retryAt.Format(time.RFC3339)))
+
+	case errors.Is(err, errRecipientDeleted):
+		// Nothing was sent; it waits for a new time or a cancel once the recipient is sorted out
+		if t.outgoingPaymentID.Valid {
+			ReleaseOutgoingPayment(t.outgoingPaymentID.Int64)
+		}
+		if updateErr := updateLeasedTransfer(t.ID,
+			"status = ?, error = ?, initiated_at = NULL, outgoing_payment_id = NULL, lease_owner = NULL, lease_expires_at = NULL",
+			transferNeedsReview, err.Error(),
+		); updateErr != nil {
+			fmt.Printf("Warning: %v\n", updateErr)
+			return
+		}
+		recordTransferEvent(t.ID, transferNeedsReview, err.Error())
+
+	case errors.Is(err, errTransferUnconfirmed):
+		// The spend stays reserved until the reference is looked up again by rescheduling
+		if updateErr := updateLeasedTransfer(t.ID,
+			"status = ?, error = ?, lease_owner = NULL, lease_expires_at = NULL",
+			transferNeedsReview, err.Error(),
+		); updateErr != nil {
+			fmt.Printf("Warning: %v\n", updateErr)
+			return
+		}
+		recordTransferEvent(t.ID, transferNeedsReview, err.Error())
+
+	default:
+		if t.outgoingPaymentID.Valid {
+			ReleaseOutgoingPayment(t.outgoingPaymentID.Int64)
+		}
+		if updateErr := updateLeasedTransfer(t.ID,
+			"status = ?, error = ?, outgoing_payment_id = NULL, lease_owner = NULL, lease_expires_at = NULL",
+			transferFailed, err.Error(),
+		); updateErr != nil {
+			fmt.Printf("Warning: %v\n", updateErr)
+			return
+		}
+		recordTransferEvent(t.ID, transferFailed, err.Error())
+	}
+}
+
+// sendScheduledTransfer rechecks the balance and limits and initiates the transfer.
+// retry reports whether a failure came from Paystack and may pass; a failure after
+// the reference may have reached Paystack wraps errTransferUnconfirmed.
+func sendScheduledTransfer(client *paystack.Client, t *ScheduledTransfer) (transfer *paystackSDK.Transfer, retry bool, err error) {
+	// An earlier attempt may have reached Paystack before failing or crashing
+	if t.InitiatedAt != nil {
+		existing, err := client.VerifyTransfer(t.Reference)
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to check for an earlier attempt: %w (%w)", err, errTransferUnconfirmed)
+		}
+		if existing != nil {
+			if t.outgoingPaymentID.Valid {
+				SetOutgoingPaymentReference(t.outgoingPaymentID.Int64, existing.TransferCode)
+				postLedger("transfer "+existing.TransferCode, PostTransferJournal(t.outgoingPaymentID.Int64))
+			}
+			return existing, false, nil
+		}
+		if t.outgoingPaymentID.Valid {
+			ReleaseOutgoingPayment(t.outgoingPaymentID.Int64)
+			t.outgoingPaymentID = sql.NullInt64{}
+		}
+	}
+
+	// The recipient may have been deleted, here or by the sync, or merged into another since it was queued
+	recipientCode, err := transferRecipientCode(t.RecipientCode)
+	if err != nil {
+		return nil, !errors.Is(err, errRecipientDeleted), err
+	}
+	if recipientCode != t.RecipientCode {
+		if err := updateLeasedTransfer(t.ID, "recipient_code = ?", recipientCode); err != nil {
+			return nil, false, err
+		}
+		t.RecipientCode = recipientCode
+	}
+
+	available, err := availableBalance(client, t.Currency)
+	if err != nil {
+		return nil, true, err
+	}
+	if available < t.Amount {
+		return nil, false, fmt.Errorf("insufficient balance: %s available", money.New(int64(available), t.Currency).Format())
+	}
+
+	reservationID, check, err := ReserveOutgoingPayment(t.RecipientCode, t.Money, "transfer", t.Reason)
+	if err != nil {
+		return nil, true, fmt.Errorf("error checking transfer limits: %w", err)
+	}
+	if !check.Allowed {
+		return nil, false, fmt.Errorf("transfer limit exceeded: %s", check.Reason)
+	}
+	t.outgoingPaymentID = sql.NullInt64{Int64: reservationID, Valid: true}
+
+	// Marked before the call so a crash mid-request is looked up, not resent
+	if err := updateLeasedTransfer(t.ID, "initiated_at = ?, outgoing_payment_id = ?", time.Now().UTC(), reservationID); err != nil {
+		ReleaseOutgoingPayment(reservationID)
+		t.outgoingPaymentID = sql.NullInt64{}
+		return nil, false, err
+	}
+
+	transfer, err = initiateTransfer(client, &paystackSDK.TransferRequest{
+		Source:    t.Source,
+		Amount:    float32(t.Amount),
+		Currency:  string(t.Currency),
+		Recipient: t.RecipientCode,
+		Reason:    t.Reason,
+		Reference: t.Reference,
+	})
+	if errors.Is(err, errTransferUnconfirmed) {
+		// The reservation is kept until the next attempt has looked the reference up
+		return nil, true, err
+	}
+	if err != nil {
+		// Paystack refused it or never got it, so nothing was sent
+		ReleaseOutgoingPayment(reservationID)
+		t.outgoingPaymentID = sql.NullInt64{}
+		if updateErr := updateLeasedTransfer(t.ID, "initiated_at = NULL, outgoing_payment_id = NULL"); updateErr != nil {
+			fmt.Printf("Warning: %v\n", updateErr)
+		}
+		return nil, !transferRejected(err), err
+	}
+	SetOutgoingPaymentReference(reservationID, transfer.TransferCode)
+	postLedger("transfer "+transfer.TransferCode, PostTransferJournal(reservationID))
+	return transfer, false, nil
+}
+
+// availableBalance returns the Paystack balance held in currency
+func availableBalance(client *paystack.Client, currency money.Currency) (balance money.Amount, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("balance check failed: %v", rec)
+		}
+	}()
+	balances, err := client.CheckBalances()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check balance: %w", err)
+	}
+	for _, b := range balances {
+		if c, _ := b["currency"].(string); strings.EqualFold(c, string(currency)) {
+			minor, _ := b["balance"].(float64)
+			return money.Amount(minor), nil
+		}
+	}
+	return 0, nil
+}
+
+// StartTransferQueue sends due scheduled transfers every interval
+func StartTransferQueue(client *paystack.Client, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			transfers, err := RunDueTransfers(client, time.Now().UTC())
+			if err != nil {
+				log.Printf("Scheduled transfers failed: %v", err)
+				continue
+			}
+			for _, t := range transfers {
+				log.Printf("Scheduled transfer %d (%s): %s %s", t.ID, t.Reference, t.Status, t.Error)
+			}
+		}
+	}()
+}
+
+// ListScheduled lists scheduled transfers, optionally by ?status=
+func (h *TransferHandler) ListScheduled(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + scheduledTransferColumns + " FROM scheduled_transfers"
+	args := []interface{}{}
+	if status := r.URL.Query().Get("status"); status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY execute_at, id"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to query scheduled transfers: %w", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	transfers := []*ScheduledTransfer{}
+	for rows.Next() {
+		t, err := scanScheduledTransfer(rows)
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to scan scheduled transfer: %w", err), http.StatusInternalServerError)
+			return
+		}
+		transfers = append(transfers, t)
+	}
+	if err := rows.Err(); err != nil {
+		WriteJSONError(w, fmt.Errorf("error iterating scheduled transfers: %w", err), http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccess(w, transfers)
+}
+
+// scheduledTransferFromURL loads the transfer named by {id}, writing the error response on failure
+func scheduledTransferFromURL(w http.ResponseWriter, r *http.Request) (*ScheduledTransfer, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		WriteJSONBadRequest(w, "id must be a positive integer")
+		return nil, false
+	}
+	t, err := loadScheduledTransfer(id)
+	if errors.Is(err, errScheduledTransferNotFound) {
+		WriteJSONError(w, err, http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return nil, false
+	}
+	return t, true
+}
+
+// GetScheduled returns a scheduled transfer with its status history
+func (h *TransferHandler) GetScheduled(w http.ResponseWriter, r *http.Request) {
+	t, ok := scheduledTransferFromURL(w, r)
+	if !ok {
+		return
+	}
+	respondWithScheduledTransfer(w, t.ID, "Success")
+}
+
+// CancelScheduled cancels a waiting or needs_review transfer that hasn't been sent to Paystack
+func (h *TransferHandler) CancelScheduled(w http.ResponseWriter, r *http.Request) {
+	t, ok := scheduledTransferFromURL(w, r)
+	if !ok {
+		return
+	}
+	if t.InitiatedAt != nil {
+		// Paystack may have it; the next attempt finds out before sending anything
+		WriteJSONError(w, fmt.Errorf("transfer may already have reached Paystack and can't be cancelled"), http.StatusConflict)
+		return
+	}
+
+	// Conditional, so a worker that has just leased it wins
+	result, err := database.DB.Exec(
+		"UPDATE scheduled_transfers SET status = ?, retry_at = NULL, updated_at = ? WHERE id = ? AND status IN (?, ?) AND initiated_at IS NULL",
+		transferCancelled, time.Now().UTC(), t.ID, transferScheduled, transferNeedsReview,
+	)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to cancel scheduled transfer: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		current, _ := loadScheduledTransfer(t.ID)
+		if current != nil {
+			t = current
+		}
+		WriteJSONError(w, fmt.Errorf("transfer is %s and can no longer be cancelled", t.Status), http.StatusConflict)
+		return
+	}
+	recordTransferEvent(t.ID, transferCancelled, "")
+	respondWithScheduledTransfer(w, t.ID, "Scheduled transfer cancelled")
+}
+
+// RescheduleScheduled moves a waiting, failed or needs_review transfer to a new time
+func (h *TransferHandler) RescheduleScheduled(w http.ResponseWriter, r *http.Request) {
+	t, ok := scheduledTransferFromURL(w, r)
+	if !ok {
+		return
+	}
+	var req RescheduleTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+	now := time.Now().UTC()
+	executeAt, err := parseExecuteAt(req.ExecuteAt, now)
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	// The reference is kept, so a failed transfer Paystack did receive isn't sent again
+	result, err := database.DB.Exec(`
+		UPDATE scheduled_transfers
+		SET execute_at = ?, status = ?, attempts = 0, retry_at = NULL, error = NULL, updated_at = ?
+		WHERE id = ? AND status IN (?, ?, ?)
+	`, executeAt, transferScheduled, now, t.ID, transferScheduled, transferFailed, transferNeedsReview)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to reschedule transfer: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		current, _ := loadScheduledTransfer(t.ID)
+		if current != nil {
+			t = current
+		}
+		WriteJSONError(w, fmt.Errorf("transfer is %s and can no longer be rescheduled", t.Status), http.StatusConflict)
+		return
+	}
+	recordTransferEvent(t.ID, transferRescheduled, fmt.Sprintf("from %s to %s", t.ExecuteAt.UTC().Format(time.RFC3339), executeAt.Format(time.RFC3339)))
+	respondWithScheduledTransfer(w, t.ID, "Transfer rescheduled")
+}
+
+// RunScheduled sends every due scheduled transfer now, without waiting for the worker
+func (h *TransferHandler) RunScheduled(w http.ResponseWriter, r *http.Request) {
+	transfers, err := RunDueTransfers(h.client, time.Now().UTC())
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, fmt.Sprintf("Processed %d scheduled transfers", len(transfers)), transfers)
+}
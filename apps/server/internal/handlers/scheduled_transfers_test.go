@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// queueTransfer adds a scheduled transfer due at executeAt and returns its ID
+func queueTransfer(t *testing.T, executeAt time.Time) int {
+	t.Helper()
+	result, err := database.DB.Exec(`
+		INSERT INTO scheduled_transfers (recipient_code, recipient_name, amount, currency, source, reference, execute_at, status, created_at, updated_at)
+		VALUES (?, 'Service provider', 500000, 'NGN', 'balance', ?, ?, ?, ?, ?)
+	`, defaultRecipientCode, newTransferReference(), executeAt, transferScheduled, executeAt, executeAt)
+	if err != nil {
+		t.Fatalf("Failed to queue transfer: %v", err)
+	}
+	id, _ := result.LastInsertId()
+	return int(id)
+}
+
+// asCrashedWorker runs fn as another process, whose lease outlives it
+func asCrashedWorker(fn func()) {
+	owner := transferQueueOwner
+	transferQueueOwner = "crashed-worker"
+	defer func() { transferQueueOwner = owner }()
+	fn()
+}
+
+// postScheduled calls a handler for the scheduled transfer with the given ID
+func postScheduled(t *testing.T, handler http.HandlerFunc, id int, body string) int {
+	t.Helper()
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", strconv.Itoa(id))
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec.Code
+}
+
+func mustLoadScheduledTransfer(t *testing.T, id int) *ScheduledTransfer {
+	t.Helper()
+	transfer, err := loadScheduledTransfer(id)
+	if err != nil {
+		t.Fatalf("Failed to load scheduled transfer: %v", err)
+	}
+	return transfer
+}
+
+func TestScheduledTransferIsNotSentTwiceAfterACrash(t *testing.T) {
+	tests := []struct {
+		name string
+		// reachedPaystack is whether the crashed worker's request got to Paystack
+		reachedPaystack bool
+		verify          fakeResponse
+	}{
+		{"crashed after Paystack made the transfer", true, transferFoundResponse},
+		{"crashed before the request reached Paystack", false, transferUnknownResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandlerDB(t)
+			fake := newFakePaystack(t)
+			fake.respond("GET /transfer/verify/", tt.verify)
+			now := time.Now().UTC()
+			id := queueTransfer(t, now.Add(-time.Minute))
+
+			// The crashed worker leased the transfer and marked it initiated, then died
+			asCrashedWorker(func() {
+				leased, err := leaseScheduledTransfer(id, now)
+				if err != nil || leased == nil {
+					t.Fatalf("Expected the crashed worker to lease the transfer, got %v, %v", leased, err)
+				}
+				if tt.reachedPaystack {
+					if _, _, err := sendScheduledTransfer(fake.client, leased); err != nil {
+						t.Fatalf("Expected the crashed worker's transfer to go through, got %v", err)
+					}
+				} else if err := updateLeasedTransfer(id, "initiated_at = ?", now); err != nil {
+					t.Fatalf("Failed to mark the transfer initiated: %v", err)
+				}
+			})
+			sentBefore := fake.count("POST /transfer")
+
+			// Its lease still holds, so nothing else may touch the transfer
+			if transfers, err := RunDueTransfers(fake.client, now.Add(time.Minute)); err != nil || len(transfers) != 0 {
+				t.Fatalf("Expected the leased transfer to be left alone, got %+v, %v", transfers, err)
+			}
+
+			// Once the lease expires the transfer is taken over and finished exactly once
+			later := now.Add(transferLeaseDuration + time.Minute)
+			transfers, err := RunDueTransfers(fake.client, later)
+			if err != nil || len(transfers) != 1 {
+				t.Fatalf("Expected the transfer to be recovered, got %+v, %v", transfers, err)
+			}
+			if transfers[0].Status != transferSent {
+				t.Errorf("Expected the transfer to be sent, got %s: %s", transfers[0].Status, transfers[0].Error)
+			}
+			if transfers, err := RunDueTransfers(fake.client, later.Add(time.Hour)); err != nil || len(transfers) != 0 {
+				t.Errorf("Expected nothing left to send, got %+v, %v", transfers, err)
+			}
+
+			if got := fake.count("POST /transfer"); got != 1 {
+				t.Errorf("Expected exactly one transfer request, got %d (%d before the crash)", got, sentBefore)
+			}
+			if fake.count("GET /transfer/verify/") == 0 {
+				t.Errorf("Expected the reference to be looked up before sending")
+			}
+			if got := reservations(t); got != 1 {
+				t.Errorf("Expected one reservation, got %d", got)
+			}
+
+			history, err := loadTransferHistory(id)
+			if err != nil {
+				t.Fatalf("Failed to load history: %v", err)
+			}
+			recovered := false
+			for _, event := range history {
+				recovered = recovered || event.Status == transferRecovered
+			}
+			if !recovered {
+				t.Errorf("Expected the takeover to be in the history, got %+v", history)
+			}
+		})
+	}
+}
+
+func TestScheduledTransferNeedsReviewWhenPaystackCantConfirm(t *testing.T) {
+	setupHandlerDB(t)
+	fake := newFakePaystack(t)
+	fake.respond("POST /transfer", paystackDownResponse)
+	fake.respond("GET /transfer/verify/", paystackDownResponse)
+	now := time.Now().UTC()
+	id := queueTransfer(t, now.Add(-time.Minute))
+
+	for attempt := 1; attempt <= maxScheduledTransferAttempts; attempt++ {
+		if _, err := RunDueTransfers(fake.client, now); err != nil {
+			t.Fatalf("Attempt %d failed to run: %v", attempt, err)
+		}
+		now = now.Add(maxScheduleRetryDelay + time.Minute)
+	}
+
+	transfer := mustLoadScheduledTransfer(t, id)
+	if transfer.Status != transferNeedsReview {
+		t.Fatalf("Expected the transfer to need review, got %s: %s", transfer.Status, transfer.Error)
+	}
+	if got := fake.count("POST /transfer"); got != 1 {
+		t.Errorf("Expected later attempts to look the reference up instead of sending, got %d transfer requests", got)
+	}
+	if got := reservations(t); got != 1 {
+		t.Errorf("Expected the reservation to be kept, got %d", got)
+	}
+	if transfers, _ := RunDueTransfers(fake.client, now.Add(time.Hour)); len(transfers) != 0 {
+		t.Errorf("Expected a transfer needing review not to be run again, got %+v", transfers)
+	}
+
+	// It can't be cancelled, since Paystack may have it
+	if code := postScheduled(t, NewTransferHandler(fake.client).CancelScheduled, id, ""); code != http.StatusConflict {
+		t.Errorf("Expected cancelling to be refused, got %d", code)
+	}
+
+	// Rescheduling looks the reference up and records the transfer Paystack has
+	fake.respond("GET /transfer/verify/", transferFoundResponse)
+	body := `{"execute_at":"` + time.Now().UTC().Add(time.Hour).Format(time.RFC3339) + `"}`
+	if code := postScheduled(t, NewTransferHandler(fake.client).RescheduleScheduled, id, body); code != http.StatusOK {
+		t.Fatalf("Expected the transfer to be rescheduled, got %d", code)
+	}
+	if _, err := RunDueTransfers(fake.client, time.Now().UTC().Add(2*time.Hour)); err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+	if transfer := mustLoadScheduledTransfer(t, id); transfer.Status != transferSent {
+		t.Errorf("Expected the transfer Paystack has to be recorded as sent, got %s", transfer.Status)
+	}
+	if got := fake.count("POST /transfer"); got != 1 {
+		t.Errorf("Expected no second transfer request, got %d", got)
+	}
+}
+
+func TestCancelAndRescheduleLoseToALeasedTransfer(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(h *TransferHandler) http.HandlerFunc
+		body    string
+	}{
+		{"cancel", func(h *TransferHandler) http.HandlerFunc { return h.CancelScheduled }, ""},
+		{"reschedule", func(h *TransferHandler) http.HandlerFunc { return h.RescheduleScheduled },
+			`{"execute_at":"` + time.Now().UTC().Add(24*time.Hour).Format(time.RFC3339) + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandlerDB(t)
+			fake := newFakePaystack(t)
+			now := time.Now().UTC()
+			id := queueTransfer(t, now.Add(-time.Minute))
+
+			leased, err := leaseScheduledTransfer(id, now)
+			if err != nil || leased == nil {
+				t.Fatalf("Expected to lease the transfer, got %v, %v", leased, err)
+			}
+			if code := postScheduled(t, tt.handler(NewTransferHandler(fake.client)), id, tt.body); code != http.StatusConflict {
+				t.Errorf("Expected %s to be refused while the transfer is leased, got %d", tt.name, code)
+			}
+
+			// The lease holder finishes it as if nothing had happened
+			runScheduledTransfer(fake.client, leased, now)
+			if transfer := mustLoadScheduledTransfer(t, id); transfer.Status != transferSent {
+				t.Errorf("Expected the lease holder to send it, got %s: %s", transfer.Status, transfer.Error)
+			}
+			if got := fake.count("POST /transfer"); got != 1 {
+				t.Errorf("Expected exactly one transfer request, got %d", got)
+			}
+		})
+	}
+}
+
+func TestCancelledTransferIsNotLeased(t *testing.T) {
+	setupHandlerDB(t)
+	fake := newFakePaystack(t)
+	now := time.Now().UTC()
+	id := queueTransfer(t, now.Add(-time.Minute))
+
+	if code := postScheduled(t, NewTransferHandler(fake.client).CancelScheduled, id, ""); code != http.StatusOK {
+		t.Fatalf("Expected the transfer to be cancelled, got %d", code)
+	}
+	if leased, err := leaseScheduledTransfer(id, now); err != nil || leased != nil {
+		t.Errorf("Expected a cancelled transfer not to be leased, got %+v, %v", leased, err)
+	}
+	if transfers, _ := RunDueTransfers(fake.client, now); len(transfers) != 0 || fake.count("POST /transfer") != 0 {
+		t.Errorf("Expected nothing to be sent, got %+v", transfers)
+	}
+}
@@ -0,0 +1,1002 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Schedules Handler - Recurring Payments
+//
+// OBJECTIVES:
+// Rent, salaries and subscriptions are paid on the same day every period, but each
+// expense has to be recorded (and each transfer sent) by hand every time.
+//
+// PURPOSE:
+// - Store recurring payments to a recipient, on a cron expression or an interval
+// - Work out each schedule's next run and show the ones after it
+// - Check the budget before every run, then record the expense automatically
+// - Send the Paystack transfer too when the schedule asks for it
+// - Skip a run, pause and resume, and keep a history of every run with retries
+//
+// KEY WORKFLOW:
+// Create Schedule (preview) → Confirm With Token → Worker Finds Due Schedules →
+// Check Budget and Transfer Limits → Send Transfer (optional) → Record Expense →
+// Advance to Next Run, or Retry Later on Failure
+//
+// DESIGN DECISIONS:
+// - Recurrence rules live in internal/schedule; this file stores and runs them
+// - A schedule keeps only its next run; each run records its outcome in schedule_runs
+// - Each run's reference is derived from the schedule and the due time, so a run
+//   can't be recorded twice and Paystack rejects a repeated transfer for it
+// - With auto_transfer the transfer is sent first and the expense recorded as paid
+//   once Paystack accepts it; without it the expense is recorded as pending
+// - A failed run is retried with backoff up to max_retries, then given up on; a
+//   run Paystack has accepted is never retried
+// - A transfer that fails after it may have reached Paystack keeps its limit
+//   reservation; the next attempt looks the reference up and records the transfer
+//   Paystack has rather than sending again, and a run given up on in that state is
+//   needs_review, not failed
+// - After downtime only the latest due run is paid; earlier ones are recorded as
+//   missed rather than sent in a burst
+// - A run whose recipient has been deleted since the schedule was made is
+//   needs_review and pauses the schedule; a merged duplicate pays the recipient kept
+// - Pausing doesn't catch up: resuming picks up at the next run after now
+// - Schedule times are UTC
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"paystack.mpc.proxy/internal/categorize"
+	"paystack.mpc.proxy/internal/confirm"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+	"paystack.mpc.proxy/internal/paystack"
+	"paystack.mpc.proxy/internal/schedule"
+
+	paystackSDK "github.com/borderlesshq/paystack-go"
+	"github.com/go-chi/chi/v5"
+)
+
+// Schedule statuses
+const (
+	scheduleActive    = "active"
+	schedulePaused    = "paused"
+	scheduleCompleted = "completed"
+	scheduleCancelled = "cancelled"
+)
+
+// What happened to each run
+const (
+	scheduleRunSucceeded = "succeeded"
+	scheduleRunRetrying  = "retrying"
+	scheduleRunFailed    = "failed"
+	scheduleRunSkipped   = "skipped"
+	scheduleRunMissed    = "missed"
+	// scheduleRunNeedsReview is a run given up on after its transfer may have reached Paystack
+	scheduleRunNeedsReview = "needs_review"
+)
+
+const (
+	// defaultScheduleRetries is how many times a failed run is retried
+	defaultScheduleRetries = 3
+	// scheduleRetryDelay is the wait before the first retry; it doubles after each
+	scheduleRetryDelay    = 15 * time.Minute
+	maxScheduleRetryDelay = 6 * time.Hour
+	// maxMissedScheduleRuns caps the missed runs recorded after a long outage
+	maxMissedScheduleRuns = 100
+	// upcomingScheduleRuns is how many future runs a schedule shows
+	upcomingScheduleRuns = 3
+)
+
+// schedulesMu keeps the worker and the run endpoint from running a schedule twice at once
+var schedulesMu sync.Mutex
+
+type ScheduleHandler struct {
+	client *paystack.Client
+}
+
+func NewScheduleHandler(client *paystack.Client) *ScheduleHandler {
+	return &ScheduleHandler{client: client}
+}
+
+// Schedule is a recurring payment to a recipient
+type Schedule struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	RecipientCode string `json:"recipient_code"`
+	RecipientName string `json:"recipient_name"`
+	money.Money
+	Category      string `json:"category,omitempty"`
+	Narration     string `json:"narration"`
+	Notes         string `json:"notes,omitempty"`
+	BudgetLimitID *int   `json:"budget_limit_id,omitempty"`
+
+	// Either Cron, or Every Unit(s) counted from StartAt
+	Cron      string     `json:"cron,omitempty"`
+	Every     int        `json:"every,omitempty"`
+	Unit      string     `json:"unit,omitempty"`
+	Frequency string     `json:"frequency"`
+	StartAt   time.Time  `json:"start_at"`
+	EndAt     *time.Time `json:"end_at,omitempty"`
+
+	Status       string      `json:"status"`
+	AutoTransfer bool        `json:"auto_transfer"`
+	MaxRetries   int         `json:"max_retries"`
+	Attempts     int         `json:"attempts"`
+	NextRunAt    *time.Time  `json:"next_run_at"`
+	RetryAt      *time.Time  `json:"retry_at,omitempty"`
+	LastRunAt    *time.Time  `json:"last_run_at,omitempty"`
+	Upcoming     []time.Time `json:"upcoming"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+
+	Runs []ScheduleRun `json:"runs,omitempty"`
+}
+
+// ScheduleRun is one run of a schedule
+type ScheduleRun struct {
+	ID           int       `json:"id"`
+	ScheduleID   int       `json:"schedule_id"`
+	DueAt        time.Time `json:"due_at"`
+	Attempt      int       `json:"attempt"`
+	Status       string    `json:"status"`
+	ExpenseID    *int      `json:"expense_id,omitempty"`
+	TransferCode string    `json:"transfer_code,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateScheduleRequest struct {
+	Name          string `json:"name,omitempty"`
+	RecipientCode string `json:"recipient_code"`
+	money.Money
+	Category      string `json:"category,omitempty"`
+	Narration     string `json:"narration"`
+	Notes         string `json:"notes,omitempty"`
+	BudgetLimitID *int   `json:"budget_limit_id,omitempty"`
+
+	// Cron is a five-field cron expression, e.g. "0 9 1 * *"; or use Every and Unit
+	Cron  string `json:"cron,omitempty"`
+	Every int    `json:"every,omitempty"`
+	Unit  string `json:"unit,omitempty"`
+	// StartAt defaults to now; EndAt is optional. Both take YYYY-MM-DD or RFC3339.
+	StartAt string `json:"start_at,omitempty"`
+	EndAt   string `json:"end_at,omitempty"`
+
+	AutoTransfer bool `json:"auto_transfer,omitempty"`
+	MaxRetries   *int `json:"max_retries,omitempty"`
+
+	// ConfirmationToken creates a previously previewed schedule
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// rule builds the schedule's recurrence
+func (s *Schedule) rule() schedule.Rule {
+	rule := schedule.Rule{Cron: s.Cron, Interval: s.Every, Unit: s.Unit, Start: s.StartAt}
+	if s.EndAt != nil {
+		rule.End = *s.EndAt
+	}
+	return rule
+}
+
+// firstRun is a rule's first run at or after now
+func firstRun(rule schedule.Rule, now time.Time) (time.Time, bool) {
+	from := now
+	if rule.Start.After(from) {
+		from = rule.Start
+	}
+	return rule.Next(from.Add(-time.Nanosecond))
+}
+
+// Create previews a schedule and creates it once the preview is confirmed
+func (h *ScheduleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req CreateScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	// A confirmation token replaces the body with the previewed request; nothing else
+	// in the body is kept, so a field can't be added after the preview
+	confirmed := false
+	if req.ConfirmationToken != "" {
+		var previewed CreateScheduleRequest
+		if !redeemConfirmation(w, req.ConfirmationToken, confirm.ActionSchedule, &previewed) {
+			return
+		}
+		req = previewed
+		confirmed = true
+	}
+
+	if req.RecipientCode == "" {
+		WriteJSONBadRequest(w, "recipient_code is required")
+		return
+	}
+	if req.Amount <= 0 {
+		WriteJSONBadRequest(w, "amount must be greater than 0")
+		return
+	}
+	if req.Narration == "" {
+		WriteJSONBadRequest(w, "narration is required")
+		return
+	}
+	req.Currency = req.Currency.OrDefault()
+	if err := req.Validate(); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	if req.Name == "" {
+		req.Name = req.Narration
+	}
+	maxRetries := defaultScheduleRetries
+	if req.MaxRetries != nil {
+		if *req.MaxRetries < 0 {
+			WriteJSONBadRequest(w, "max_retries can't be negative")
+			return
+		}
+		maxRetries = *req.MaxRetries
+	}
+
+	// Only the local cache is checked; schedules pay recipients we already know, and
+	// a duplicate pays the recipient it duplicates
+	code, err := payableRecipientCode(req.RecipientCode)
+	if errors.Is(err, errRecipientDeleted) {
+		WriteJSONError(w, err, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("recipient not found: %s", req.RecipientCode), http.StatusNotFound)
+		return
+	}
+	req.RecipientCode = code
+	var recipientName string
+	database.DB.QueryRow("SELECT name FROM recipients WHERE recipient_code = ?", req.RecipientCode).Scan(&recipientName)
+
+	// An empty start is "now" when the schedule is created, not when it was previewed
+	now := time.Now().UTC()
+	rule := schedule.Rule{Cron: req.Cron, Interval: req.Every, Unit: req.Unit, Start: now}
+	if req.StartAt != "" {
+		if rule.Start, err = parseRangeTime(req.StartAt, false); err != nil {
+			WriteJSONBadRequest(w, fmt.Sprintf("start_at: %v", err))
+			return
+		}
+	}
+	if req.EndAt != "" {
+		if rule.End, err = parseRangeTime(req.EndAt, true); err != nil {
+			WriteJSONBadRequest(w, fmt.Sprintf("end_at: %v", err))
+			return
+		}
+		// A date-only end includes that whole day
+		rule.End = rule.End.Add(-time.Nanosecond)
+	}
+	if err := rule.Validate(); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	next, ok := firstRun(rule, now)
+	if !ok {
+		WriteJSONBadRequest(w, "schedule never runs between start_at and end_at")
+		return
+	}
+
+	budgetID := 0
+	if req.BudgetLimitID != nil && *req.BudgetLimitID > 0 {
+		budgetID = *req.BudgetLimitID
+	} else {
+		// Runs without a budget use the default budget of the month they run in
+		budget, err := FindOrCreateDefaultBudget()
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to get default budget: %w", err), http.StatusInternalServerError)
+			return
+		}
+		budgetID = budget.ID
+	}
+
+	if !confirmed {
+		impact, err := CheckBudgetAffordabilityOn(budgetID, req.Money, now)
+		if err != nil {
+			WriteJSONBadRequest(w, fmt.Sprintf("error checking budget: %v", err))
+			return
+		}
+		preview := ConfirmationPreview{
+			Money:         req.Money,
+			RecipientName: recipientName,
+			BudgetImpact:  impact,
+			Schedule:      fmt.Sprintf("%s from %s", rule.Describe(), next.Format("2006-01-02")),
+			Summary:       fmt.Sprintf("Pay %s to %s %s, first on %s", req.Format(), recipientName, rule.Describe(), next.Format("2006-01-02 15:04")),
+		}
+		if req.AutoTransfer {
+			check, err := CheckTransferLimits(req.RecipientCode, req.Money)
+			if err != nil {
+				WriteJSONError(w, fmt.Errorf("error checking transfer limits: %w", err), http.StatusInternalServerError)
+				return
+			}
+			preview.LimitAlerts = check.Alerts
+		}
+		writeConfirmationPreview(w, confirm.ActionSchedule, req, preview)
+		return
+	}
+
+	var endAt interface{}
+	if !rule.End.IsZero() {
+		endAt = rule.End
+	}
+	var budgetLimitID interface{}
+	if req.BudgetLimitID != nil && *req.BudgetLimitID > 0 {
+		budgetLimitID = *req.BudgetLimitID
+	}
+	var cron, every, unit interface{}
+	if req.Cron != "" {
+		cron = req.Cron
+	} else {
+		every, unit = req.Every, req.Unit
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO schedules (
+			name, recipient_code, recipient_name, amount, currency, category, narration, notes,
+			budget_limit_id, cron, interval_count, interval_unit, start_at, end_at, next_run_at,
+			status, auto_transfer, max_retries, created_at, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		req.Name, req.RecipientCode, recipientName, req.Amount, req.Currency, req.Category, req.Narration, req.Notes,
+		budgetLimitID, cron, every, unit, rule.Start, endAt, next,
+		scheduleActive, req.AutoTransfer, maxRetries, now, now,
+	)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to create schedule: %w", err), http.StatusInternalServerError)
+		return
+	}
+	id, _ := result.LastInsertId()
+
+	created, err := loadSchedule(int(id))
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, "Schedule created", created)
+}
+
+const scheduleColumns = `
+	id, name, recipient_code, recipient_name, amount, COALESCE(currency, 'NGN'), COALESCE(category, ''),
+	narration, COALESCE(notes, ''), budget_limit_id, COALESCE(cron, ''), COALESCE(interval_count, 0),
+	COALESCE(interval_unit, ''), start_at, end_at, next_run_at, status, auto_transfer, max_retries,
+	attempts, retry_at, last_run_at, created_at, updated_at`
+
+func scanSchedule(row rowScanner) (*Schedule, error) {
+	var s Schedule
+	var budgetLimitID sql.NullInt64
+	var endAt, nextRunAt, retryAt, lastRunAt sql.NullTime
+	err := row.Scan(
+		&s.ID, &s.Name, &s.RecipientCode, &s.RecipientName, &s.Amount, &s.Currency, &s.Category,
+		&s.Narration, &s.Notes, &budgetLimitID, &s.Cron, &s.Every,
+		&s.Unit, &s.StartAt, &endAt, &nextRunAt, &s.Status, &s.AutoTransfer, &s.MaxRetries,
+		&s.Attempts, &retryAt, &lastRunAt, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if budgetLimitID.Valid {
+		id := int(budgetLimitID.Int64)
+		s.BudgetLimitID = &id
+	}
+	for _, t := range []struct {
+		src sql.NullTime
+		dst **time.Time
+	}{{endAt, &s.EndAt}, {nextRunAt, &s.NextRunAt}, {retryAt, &s.RetryAt}, {lastRunAt, &s.LastRunAt}} {
+		if t.src.Valid {
+			value := t.src.Time
+			*t.dst = &value
+		}
+	}
+
+	s.Frequency = s.rule().Describe()
+	s.Upcoming = []time.Time{}
+	if s.NextRunAt != nil && (s.Status == scheduleActive || s.Status == schedulePaused) {
+		s.Upcoming = append([]time.Time{*s.NextRunAt}, s.rule().Upcoming(*s.NextRunAt, upcomingScheduleRuns-1)...)
+	}
+	return &s, nil
+}
+
+// errScheduleNotFound is returned when no schedule has the ID
+var errScheduleNotFound = errors.New("schedule not found")
+
+func loadSchedule(id int) (*Schedule, error) {
+	s, err := scanSchedule(database.DB.QueryRow("SELECT "+scheduleColumns+" FROM schedules WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %d", errScheduleNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schedule %d: %w", id, err)
+	}
+	return s, nil
+}
+
+func loadScheduleRuns(scheduleID int, limit int) ([]ScheduleRun, error) {
+	query := `
+		SELECT id, schedule_id, due_at, attempt, status, expense_id, COALESCE(transfer_code, ''), COALESCE(error, ''), created_at
+		FROM schedule_runs WHERE schedule_id = ? ORDER BY id DESC`
+	args := []interface{}{scheduleID}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []ScheduleRun{}
+	for rows.Next() {
+		var run ScheduleRun
+		var expenseID sql.NullInt64
+		if err := rows.Scan(&run.ID, &run.ScheduleID, &run.DueAt, &run.Attempt, &run.Status, &expenseID, &run.TransferCode, &run.Error, &run.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule run: %w", err)
+		}
+		if expenseID.Valid {
+			id := int(expenseID.Int64)
+			run.ExpenseID = &id
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func recordScheduleRun(run ScheduleRun) (ScheduleRun, error) {
+	var expenseID interface{}
+	if run.ExpenseID != nil {
+		expenseID = *run.ExpenseID
+	}
+	run.CreatedAt = time.Now().UTC()
+	result, err := database.DB.Exec(
+		"INSERT INTO schedule_runs (schedule_id, due_at, attempt, status, expense_id, transfer_code, error, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		run.ScheduleID, run.DueAt, run.Attempt, run.Status, expenseID, run.TransferCode, run.Error, run.CreatedAt,
+	)
+	if err != nil {
+		return run, fmt.Errorf("failed to record schedule run: %w", err)
+	}
+	id, _ := result.LastInsertId()
+	run.ID = int(id)
+	return run, nil
+}
+
+// advanceSchedule moves a schedule past due, completing it when the rule has no more runs
+func advanceSchedule(s *Schedule, due, now time.Time, ran bool) error {
+	next, ok := s.rule().Next(due)
+	status := s.Status
+	var nextRunAt interface{} = next
+	if !ok {
+		status, nextRunAt = scheduleCompleted, nil
+	}
+	query := "UPDATE schedules SET next_run_at = ?, status = ?, attempts = 0, retry_at = NULL, updated_at = ?"
+	args := []interface{}{nextRunAt, status, now}
+	if ran {
+		query += ", last_run_at = ?"
+		args = append(args, now)
+	}
+	_, err := database.DB.Exec(query+" WHERE id = ?", append(args, s.ID)...)
+	if err != nil {
+		return fmt.Errorf("failed to advance schedule %d: %w", s.ID, err)
+	}
+	return nil
+}
+
+// retryDelay is how long to wait after a failed attempt
+func retryDelay(attempt int) time.Duration {
+	delay := scheduleRetryDelay
+	for i := 1; i < attempt && delay < maxScheduleRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxScheduleRetryDelay {
+		delay = maxScheduleRetryDelay
+	}
+	return delay
+}
+
+// RunDueSchedules runs every active schedule whose next run or retry is due
+func RunDueSchedules(client *paystack.Client, now time.Time) ([]ScheduleRun, error) {
+	schedulesMu.Lock()
+	defer schedulesMu.Unlock()
+
+	rows, err := database.DB.Query(`
+		SELECT id FROM schedules
+		WHERE status = ? AND next_run_at IS NOT NULL AND COALESCE(retry_at, next_run_at) <= ?
+		ORDER BY COALESCE(retry_at, next_run_at)
+	`, scheduleActive, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due schedules: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	runs := []ScheduleRun{}
+	for _, id := range ids {
+		run, err := runSchedule(client, id, now)
+		if err != nil {
+			fmt.Printf("Warning: schedule %d didn't run: %v\n", id, err)
+			continue
+		}
+		runs = append(runs, *run)
+	}
+	return runs, nil
+}
+
+// runSchedule makes one attempt at a schedule's due run and records the outcome
+func runSchedule(client *paystack.Client, id int, now time.Time) (*ScheduleRun, error) {
+	s, err := loadSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+	rule := s.rule()
+	due := *s.NextRunAt
+
+	// Only the latest due run is paid; the ones before it were missed
+	if s.Attempts == 0 {
+		missed := 0
+		for {
+			next, ok := rule.Next(due)
+			if !ok || next.After(now) {
+				break
+			}
+			if missed < maxMissedScheduleRuns {
+				if _, err := recordScheduleRun(ScheduleRun{ScheduleID: s.ID, DueAt: due, Status: scheduleRunMissed, Error: "a later run was already due"}); err != nil {
+					return nil, err
+				}
+			}
+			missed++
+			due = next
+		}
+	}
+
+	attempt := s.Attempts + 1
+	run := ScheduleRun{ScheduleID: s.ID, DueAt: due, Attempt: attempt, Status: scheduleRunSucceeded}
+	expenseID, transferCode, note, err := executeScheduledPayment(client, s, due, now)
+	if expenseID > 0 {
+		id := int(expenseID)
+		run.ExpenseID = &id
+	}
+	run.TransferCode = transferCode
+	run.Error = note
+
+	switch {
+	case err == nil:
+		if advanceErr := advanceSchedule(s, due, now, true); advanceErr != nil {
+			return nil, advanceErr
+		}
+	case errors.Is(err, errRecipientDeleted):
+		// Retrying won't bring the recipient back, so the schedule is paused until it is resumed or cancelled
+		run.Status, run.Error = scheduleRunNeedsReview, err.Error()
+		s.Status = schedulePaused
+		if advanceErr := advanceSchedule(s, due, now, false); advanceErr != nil {
+			return nil, advanceErr
+		}
+	case attempt <= s.MaxRetries:
+		run.Status, run.Error = scheduleRunRetrying, err.Error()
+		_, updateErr := database.DB.Exec(
+			"UPDATE schedules SET next_run_at = ?, attempts = ?, retry_at = ?, updated_at = ? WHERE id = ?",
+			due, attempt, now.Add(retryDelay(attempt)), now, s.ID,
+		)
+		if updateErr != nil {
+			return nil, fmt.Errorf("failed to schedule retry: %w", updateErr)
+		}
+	case errors.Is(err, errTransferUnconfirmed):
+		// The money may have gone, so the run's reservation is kept for reconciliation
+		run.Status, run.Error = scheduleRunNeedsReview, err.Error()
+		if advanceErr := advanceSchedule(s, due, now, false); advanceErr != nil {
+			return nil, advanceErr
+		}
+	default:
+		run.Status, run.Error = scheduleRunFailed, err.Error()
+		if advanceErr := advanceSchedule(s, due, now, false); advanceErr != nil {
+			return nil, advanceErr
+		}
+	}
+
+	recorded, err := recordScheduleRun(run)
+	if err != nil {
+		return nil, err
+	}
+	return &recorded, nil
+}
+
+// executeScheduledPayment checks the budget and limits, sends the transfer when
+// the schedule asks for one, and records the expense. A non-empty note explains
+// a run that succeeded with something left to do. A transfer that may have reached
+// Paystack fails with errTransferUnconfirmed and keeps its limit reservation.
+func executeScheduledPayment(client *paystack.Client, s *Schedule, due, now time.Time) (expenseID int64, transferCode, note string, err error) {
+	// Paystack takes lowercase references of at least 16 characters
+	reference := fmt.Sprintf("sch_%d_%s", s.ID, due.UTC().Format("200601021504"))
+
+	// An earlier attempt at this run may have reached Paystack; if so it left its
+	// reservation under the reference, and the reference is looked up before anything is sent
+	var transfer *paystackSDK.Transfer
+	var reservationID int64
+	if s.AutoTransfer && client != nil {
+		previous, err := reservationByReference(reference)
+		if err != nil {
+			return 0, "", "", err
+		}
+		if previous != 0 {
+			existing, err := client.VerifyTransfer(reference)
+			if err != nil {
+				return 0, "", "", fmt.Errorf("failed to check for an earlier attempt: %w (%w)", err, errTransferUnconfirmed)
+			}
+			if existing != nil {
+				transfer, reservationID = existing, previous
+			} else {
+				ReleaseOutgoingPayment(previous)
+			}
+		}
+	}
+
+	// The recipient may have been deleted, here or by the sync, or merged into another since the schedule was made
+	if transfer == nil {
+		code, err := payableRecipientCode(s.RecipientCode)
+		if err != nil {
+			return 0, "", "", err
+		}
+		if code != s.RecipientCode {
+			database.DB.Exec("UPDATE schedules SET recipient_code = ?, updated_at = ? WHERE id = ?", code, now, s.ID)
+			s.RecipientCode = code
+		}
+	}
+
+	budgetID := 0
+	if s.BudgetLimitID != nil {
+		budgetID = *s.BudgetLimitID
+	} else {
+		budget, err := FindOrCreateDefaultBudget()
+		if err != nil {
+			return 0, "", "", fmt.Errorf("failed to get default budget: %w", err)
+		}
+		budgetID = budget.ID
+	}
+
+	check, err := CheckBudgetAffordabilityOn(budgetID, s.Money, now)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("error checking budget: %w", err)
+	}
+	// A transfer an earlier attempt already sent is recorded whatever the budget now says
+	if transfer == nil {
+		if !check.CanAfford {
+			return 0, "", "", fmt.Errorf("budget %d can't cover it: %s", budgetID, check.Reason)
+		}
+
+		var limitCheck *LimitCheckResult
+		reservationID, limitCheck, err = ReserveOutgoingPayment(s.RecipientCode, s.Money, "expense", s.Narration)
+		if err != nil {
+			return 0, "", "", fmt.Errorf("error checking transfer limits: %w", err)
+		}
+		if !limitCheck.Allowed {
+			return 0, "", "", fmt.Errorf("transfer limit exceeded: %s", limitCheck.Reason)
+		}
+	}
+
+	status := "pending"
+	var paymentDate interface{}
+
+	if s.AutoTransfer {
+		if client == nil {
+			ReleaseOutgoingPayment(reservationID)
+			return 0, "", "", fmt.Errorf("transfers aren't available")
+		}
+		if transfer == nil {
+			// Tagged before the call so the next attempt finds the reservation and looks the reference up
+			SetOutgoingPaymentReference(reservationID, reference)
+			transfer, err = initiateTransfer(client, &paystackSDK.TransferRequest{
+				Source:    "balance",
+				Amount:    float32(s.Amount),
+				Currency:  string(s.Currency),
+				Recipient: s.RecipientCode,
+				Reason:    s.Narration,
+				Reference: reference,
+			})
+			if errors.Is(err, errTransferUnconfirmed) {
+				// The reservation is kept until the reference has been looked up
+				return 0, "", "", err
+			}
+			if err != nil {
+				// Paystack refused it or never got it, so nothing was sent
+				ReleaseOutgoingPayment(reservationID)
+				return 0, "", "", err
+			}
+		}
+		transferCode = transfer.TransferCode
+		if transfer.Status == "otp" {
+			note = fmt.Sprintf("transfer %s is waiting for an OTP", transferCode)
+		} else {
+			status, paymentDate = "paid", now
+		}
+	}
+	SetOutgoingPaymentReference(reservationID, reference)
+
+	var fxRate, fxRateDate interface{}
+	if check.Conversion != nil {
+		fxRate, fxRateDate = check.Conversion.Rate, check.Conversion.RateDate
+	}
+	categoryResult, err := CategorizeExpense(s.Category, categorize.Expense{
+		RecipientCode: s.RecipientCode,
+		RecipientName: s.RecipientName,
+		Narration:     s.Narration,
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to categorize scheduled expense: %v\n", err)
+		categoryResult = categorize.Result{Category: s.Category, Source: categorize.SourceManual}
+	}
+	category, categorySource, categoryRuleID := categoryColumns(categoryResult)
+
+	notes := s.Notes
+	if notes == "" {
+		notes = fmt.Sprintf("Scheduled payment %q due %s", s.Name, due.Format("2006-01-02 15:04"))
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO expenses (
+			recipient_code, recipient_name, amount, currency, category,
+			narration, reference, status, payment_date, notes, budget_limit_id,
+			budget_amount, budget_currency, fx_rate, fx_rate_date,
+			category_source, category_rule_id, schedule_id, created_at, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		s.RecipientCode, s.RecipientName, s.Amount, s.Currency, category,
+		s.Narration, reference, status, paymentDate, notes, budgetID,
+		check.RequestedAmount, check.Currency, fxRate, fxRateDate,
+		categorySource, categoryRuleID, s.ID, now, now,
+	)
+	if err != nil {
+		if transferCode != "" {
+			// The money has gone; reconciliation will show the transfer with no expense
+			return 0, transferCode, fmt.Sprintf("transfer sent but the expense wasn't recorded: %v", err), nil
+		}
+		ReleaseOutgoingPayment(reservationID)
+		return 0, "", "", fmt.Errorf("failed to create expense: %w", err)
+	}
+	expenseID, _ = result.LastInsertId()
+
+	if err := PostExpenseJournal(expenseID); err != nil {
+		if transferCode != "" {
+			postLedger(fmt.Sprintf("expense %d", expenseID), err)
+			return expenseID, transferCode, note, nil
+		}
+		database.DB.Exec("DELETE FROM expenses WHERE id = ?", expenseID)
+		ReleaseOutgoingPayment(reservationID)
+		return 0, "", "", fmt.Errorf("failed to update budget: %w", err)
+	}
+	return expenseID, transferCode, note, nil
+}
+
+// reservationByReference returns the outgoing payment reserved under reference, or 0
+func reservationByReference(reference string) (int64, error) {
+	var id int64
+	err := database.DB.QueryRow("SELECT id FROM outgoing_payments WHERE reference = ? ORDER BY id LIMIT 1", reference).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up an earlier attempt: %w", err)
+	}
+	return id, nil
+}
+
+// StartScheduler runs due schedules every interval
+func StartScheduler(client *paystack.Client, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			runs, err := RunDueSchedules(client, time.Now().UTC())
+			if err != nil {
+				log.Printf("Scheduled payments failed: %v", err)
+				continue
+			}
+			for _, run := range runs {
+				log.Printf("Schedule %d run due %s: %s %s", run.ScheduleID, run.DueAt.Format(time.RFC3339), run.Status, run.Error)
+			}
+		}
+	}()
+}
+
+// List lists schedules, optionally by ?status=
+func (h *ScheduleHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + scheduleColumns + " FROM schedules"
+	args := []interface{}{}
+	if status := r.URL.Query().Get("status"); status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY next_run_at IS NULL, next_run_at, id"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to query schedules: %w", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	schedules := []*Schedule{}
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to scan schedule: %w", err), http.StatusInternalServerError)
+			return
+		}
+		schedules = append(schedules, s)
+	}
+	if err := rows.Err(); err != nil {
+		WriteJSONError(w, fmt.Errorf("error iterating schedules: %w", err), http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccess(w, schedules)
+}
+
+// scheduleFromURL loads the schedule named by {id}, writing the error response on failure
+func scheduleFromURL(w http.ResponseWriter, r *http.Request) (*Schedule, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		WriteJSONBadRequest(w, "id must be a positive integer")
+		return nil, false
+	}
+	s, err := loadSchedule(id)
+	if errors.Is(err, errScheduleNotFound) {
+		WriteJSONError(w, err, http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return nil, false
+	}
+	return s, true
+}
+
+// Get returns a schedule with its upcoming and most recent runs
+func (h *ScheduleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	s, ok := scheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+	runs, err := loadScheduleRuns(s.ID, 20)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	s.Runs = runs
+	WriteJSONSuccess(w, s)
+}
+
+// Runs returns a schedule's full run history, newest first
+func (h *ScheduleHandler) Runs(w http.ResponseWriter, r *http.Request) {
+	s, ok := scheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+	runs, err := loadScheduleRuns(s.ID, 0)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccess(w, runs)
+}
+
+// Skip skips the next run; it is recorded as skipped and never paid
+func (h *ScheduleHandler) Skip(w http.ResponseWriter, r *http.Request) {
+	schedulesMu.Lock()
+	defer schedulesMu.Unlock()
+
+	s, ok := scheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+	if (s.Status != scheduleActive && s.Status != schedulePaused) || s.NextRunAt == nil {
+		WriteJSONBadRequest(w, fmt.Sprintf("schedule is %s and has no run to skip", s.Status))
+		return
+	}
+
+	now := time.Now().UTC()
+	if _, err := recordScheduleRun(ScheduleRun{ScheduleID: s.ID, DueAt: *s.NextRunAt, Status: scheduleRunSkipped}); err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if err := advanceSchedule(s, *s.NextRunAt, now, false); err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	h.respondWithSchedule(w, s.ID, "Run skipped")
+}
+
+// Pause stops a schedule running until it is resumed
+func (h *ScheduleHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, []string{scheduleActive}, schedulePaused, "Schedule paused")
+}
+
+// Resume restarts a paused schedule from its next run after now; runs that
+// fell due while it was paused aren't paid
+func (h *ScheduleHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, []string{schedulePaused}, scheduleActive, "Schedule resumed")
+}
+
+// Cancel stops a schedule for good
+func (h *ScheduleHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, []string{scheduleActive, schedulePaused}, scheduleCancelled, "Schedule cancelled")
+}
+
+func (h *ScheduleHandler) setStatus(w http.ResponseWriter, r *http.Request, from []string, to, message string) {
+	schedulesMu.Lock()
+	defer schedulesMu.Unlock()
+
+	s, ok := scheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+	allowed := false
+	for _, status := range from {
+		allowed = allowed || s.Status == status
+	}
+	if !allowed {
+		WriteJSONBadRequest(w, fmt.Sprintf("schedule is %s", s.Status))
+		return
+	}
+
+	now := time.Now().UTC()
+	var nextRunAt interface{}
+	if s.NextRunAt != nil {
+		nextRunAt = *s.NextRunAt
+	}
+	switch to {
+	case scheduleActive:
+		if s.NextRunAt == nil || !s.NextRunAt.After(now) {
+			next, ok := firstRun(s.rule(), now)
+			if !ok {
+				to, nextRunAt = scheduleCompleted, nil
+			} else {
+				nextRunAt = next
+			}
+		}
+	case scheduleCancelled:
+		nextRunAt = nil
+	}
+
+	_, err := database.DB.Exec(
+		"UPDATE schedules SET status = ?, next_run_at = ?, attempts = 0, retry_at = NULL, updated_at = ? WHERE id = ?",
+		to, nextRunAt, now, s.ID,
+	)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to update schedule: %w", err), http.StatusInternalServerError)
+		return
+	}
+	h.respondWithSchedule(w, s.ID, message)
+}
+
+func (h *ScheduleHandler) respondWithSchedule(w http.ResponseWriter, id int, message string) {
+	s, err := loadSchedule(id)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, message, s)
+}
+
+// RunDue runs every schedule that is due now, without waiting for the worker
+func (h *ScheduleHandler) RunDue(w http.ResponseWriter, r *http.Request) {
+	runs, err := RunDueSchedules(h.client, time.Now().UTC())
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	WriteJSONSuccessWithMessage(w, fmt.Sprintf("Ran %d schedules", len(runs)), runs)
+}
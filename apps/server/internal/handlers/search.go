@@ -0,0 +1,256 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Search Handler - Full-Text Search
+//
+// OBJECTIVES:
+// Search was spread over per-entity endpoints that each matched differently, and
+// expenses and invoices couldn't be searched at all.
+//
+// PURPOSE:
+// - Search expenses, recipients, invoices and service providers from one endpoint
+// - Rank everything together with BM25 so the best match comes first whatever it is
+// - Find records from partial words and small typos, as voice transcripts have
+//
+// KEY WORKFLOW:
+// Split Query → Correct Terms from the Index Vocabulary → Exact Tier →
+// Fuzzy Tier → Partial Tier → Merge in Order
+//
+// DESIGN DECISIONS:
+// - One SQLite FTS5 table indexes every entity, kept in sync by triggers on the
+//   source tables (see database.createSearchIndex)
+// - Service providers are mock data rather than rows, so they are indexed from
+//   memory on the first search
+// - Query handling lives in internal/search; this file runs the tiers and merges them
+// - Names weigh most in ranking, then codes, categories and banks, then narrations
+//   and descriptions
+// - Results say which tier matched them, so a caller can tell a typo correction
+//   from an exact hit
+// - FTS5 needs a build with -tags sqlite_fts5; without it this endpoint returns 503
+//   and the per-entity search endpoints still work
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+	"paystack.mpc.proxy/internal/search"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Searchable entity types
+const (
+	searchExpense   = "expense"
+	searchRecipient = "recipient"
+	searchInvoice   = "invoice"
+	searchProvider  = "provider"
+)
+
+var errSearchUnavailable = errors.New("full-text search is not available: the server was built without FTS5 (go build -tags sqlite_fts5)")
+
+type SearchHandler struct {
+	providersOnce sync.Once
+}
+
+func NewSearchHandler() *SearchHandler {
+	return &SearchHandler{}
+}
+
+// SearchResult is one matching record
+type SearchResult struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Snippet is the matching text, trimmed around the match
+	Snippet  string         `json:"snippet,omitempty"`
+	Amount   *money.Amount  `json:"amount,omitempty"`
+	Currency money.Currency `json:"currency,omitempty"`
+	// Score is the BM25 relevance within its tier; higher is better
+	Score float64 `json:"score"`
+	// Match is the tier that found it: exact, fuzzy or partial
+	Match string `json:"match"`
+}
+
+// SearchResponse is the merged results of every tier
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Terms   []string       `json:"terms"`
+	Results []SearchResult `json:"results"`
+	Count   int            `json:"count"`
+}
+
+// Search searches every entity. Query: q (required), types (comma-separated), limit.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if !database.SearchAvailable {
+		WriteJSONError(w, errSearchUnavailable, http.StatusServiceUnavailable)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		WriteJSONBadRequest(w, "q query parameter is required")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxSearchLimit {
+			WriteJSONBadRequest(w, fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit))
+			return
+		}
+		limit = n
+	}
+
+	var types []string
+	if v := r.URL.Query().Get("types"); v != "" {
+		for _, t := range strings.Split(v, ",") {
+			t = strings.TrimSpace(strings.ToLower(t))
+			switch t {
+			case searchExpense, searchRecipient, searchInvoice, searchProvider:
+				types = append(types, t)
+			default:
+				WriteJSONBadRequest(w, fmt.Sprintf("unknown type %q: use expense, recipient, invoice or provider", t))
+				return
+			}
+		}
+	}
+
+	h.providersOnce.Do(indexServiceProviders)
+
+	terms := search.Terms(query)
+	corrections := map[string][]string{}
+	for _, term := range terms {
+		vocabulary, err := searchVocabulary(term)
+		if err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+		corrections[term] = search.Corrections(term, vocabulary)
+	}
+
+	response := SearchResponse{Query: query, Terms: terms, Results: []SearchResult{}}
+	seen := map[string]bool{}
+	for _, tier := range search.Plan(terms, corrections) {
+		if len(response.Results) >= limit {
+			break
+		}
+		results, err := searchTier(tier, types, limit)
+		if err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+		for _, result := range results {
+			key := result.Type + ":" + result.ID
+			if seen[key] || len(response.Results) >= limit {
+				continue
+			}
+			seen[key] = true
+			response.Results = append(response.Results, result)
+		}
+	}
+	response.Count = len(response.Results)
+	WriteJSONSuccess(w, response)
+}
+
+// searchVocabulary loads the indexed words that could be a correction of term
+func searchVocabulary(term string) ([]string, error) {
+	edits := search.MaxEdits(term)
+	if edits == 0 {
+		return nil, nil
+	}
+	length := len([]rune(term))
+	rows, err := database.DB.Query("SELECT term FROM search_terms WHERE length(term) BETWEEN ? AND ?", length-edits, length+edits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load search vocabulary: %w", err)
+	}
+	defer rows.Close()
+
+	var words []string
+	for rows.Next() {
+		var word string
+		if err := rows.Scan(&word); err != nil {
+			return nil, fmt.Errorf("failed to scan search vocabulary: %w", err)
+		}
+		words = append(words, word)
+	}
+	return words, rows.Err()
+}
+
+// searchTier runs one tier's match expression, best first
+func searchTier(tier search.Tier, types []string, limit int) ([]SearchResult, error) {
+	// Weights follow the column order: entity_type, entity_id, title, keywords, body, amount, currency
+	query := `
+		SELECT entity_type, entity_id, title, snippet(search_index, -1, '', '', '…', 10),
+		       amount, currency, bm25(search_index, 0, 0, 10.0, 4.0, 1.0, 0, 0) AS relevance
+		FROM search_index
+		WHERE search_index MATCH ?`
+	args := []interface{}{tier.Expression}
+	if len(types) > 0 {
+		query += " AND entity_type IN (?" + strings.Repeat(", ?", len(types)-1) + ")"
+		for _, t := range types {
+			args = append(args, t)
+		}
+	}
+	query += " ORDER BY relevance LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		var amount *int64
+		var currency *string
+		var relevance float64
+		if err := rows.Scan(&result.Type, &result.ID, &result.Title, &result.Snippet, &amount, &currency, &relevance); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		if amount != nil {
+			a := money.Amount(*amount)
+			result.Amount = &a
+			if currency != nil {
+				result.Currency = money.Currency(*currency)
+			}
+		}
+		// bm25 is lower for better matches
+		result.Score = -relevance
+		result.Match = tier.Name
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+// indexServiceProviders replaces the indexed service providers with the current list
+func indexServiceProviders() {
+	if _, err := database.DB.Exec("DELETE FROM search_index WHERE entity_type = ?", searchProvider); err != nil {
+		fmt.Printf("Warning: failed to clear indexed service providers: %v\n", err)
+		return
+	}
+	for _, p := range mockServiceProviders() {
+		services := make([]string, len(p.Services))
+		for i, s := range p.Services {
+			services[i] = s.Name
+		}
+		_, err := database.DB.Exec(
+			"INSERT INTO search_index (entity_type, entity_id, title, keywords, body) VALUES (?, ?, ?, ?, ?)",
+			searchProvider, strconv.Itoa(p.ID), p.Name, p.Category+" "+p.Location, p.Description+" "+strings.Join(services, " "),
+		)
+		if err != nil {
+			fmt.Printf("Warning: failed to index service provider %d: %v\n", p.ID, err)
+		}
+	}
+}
@@ -0,0 +1,432 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Snapshot Handler - Reporting
+//
+// OBJECTIVES:
+// Users (and the voice agent) need the whole financial picture in one call.
+//
+// PURPOSE:
+// - Combine balances, active budgets, open goals, pending expenses and outstanding invoices
+// - Summarise money in and out over a window (this week, this month, last month, this year)
+// - Narrow activity to a single beneficiary when asked
+//
+// KEY WORKFLOW:
+// Parse Window & Scope → Fetch Every Section Concurrently → Collect Per-Section Errors →
+// Return Partial Data With Error Flags
+//
+// DESIGN DECISIONS:
+// - Sections are independent: a Paystack outage only flags the balances section
+// - Paystack calls are bounded by a timeout so one slow section can't stall the snapshot
+// - Everything except balances reads local tables
+// - Outstanding invoices come from the local invoice cache (anything not yet paid)
+// - Goal progress is time elapsed plus whether the linked budget can still cover the target
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"paystack.mpc.proxy/internal/analytics"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+	"paystack.mpc.proxy/internal/paystack"
+)
+
+// snapshotPaystackTimeout bounds how long the snapshot waits on Paystack
+const snapshotPaystackTimeout = 8 * time.Second
+
+type SnapshotHandler struct {
+	client *paystack.Client
+}
+
+func NewSnapshotHandler(client *paystack.Client) *SnapshotHandler {
+	return &SnapshotHandler{client: client}
+}
+
+// SnapshotSection wraps one part of the snapshot with its own error flag
+type SnapshotSection struct {
+	OK    bool        `json:"ok"`
+	Error string      `json:"error,omitempty"`
+	Data  interface{} `json:"data"`
+}
+
+// GoalProgress is an open goal with how far along it is
+type GoalProgress struct {
+	Goal
+	TimeElapsedPercent float64       `json:"time_elapsed_percent"`
+	DaysRemaining      *int          `json:"days_remaining,omitempty"`
+	BudgetRemaining    *money.Amount `json:"budget_remaining,omitempty"`
+	Affordable         bool          `json:"affordable"`
+}
+
+// SnapshotActivity is money movement over the window compared with the window before it
+type SnapshotActivity struct {
+	Received analytics.Stats `json:"received"`
+	Spent    analytics.Stats `json:"spent"`
+	Sent     analytics.Stats `json:"sent"`
+	Previous struct {
+		Received analytics.Stats `json:"received"`
+		Spent    analytics.Stats `json:"spent"`
+		Sent     analytics.Stats `json:"sent"`
+	} `json:"previous"`
+}
+
+type Snapshot struct {
+	Window          string          `json:"window"`
+	From            time.Time       `json:"from"`
+	To              time.Time       `json:"to"`
+	Scope           string          `json:"scope"`
+	RecipientCode   string          `json:"recipient_code,omitempty"`
+	Partial         bool            `json:"partial"`
+	Balances        SnapshotSection `json:"balances"`
+	Budgets         SnapshotSection `json:"budgets"`
+	Goals           SnapshotSection `json:"goals"`
+	PendingExpenses SnapshotSection `json:"pending_expenses"`
+	Invoices        SnapshotSection `json:"outstanding_invoices"`
+	Activity        SnapshotSection `json:"activity"`
+}
+
+// snapshotWindow resolves a named window to [from, to) in UTC
+func snapshotWindow(window string, now time.Time) (time.Time, time.Time, error) {
+	now = now.UTC()
+	switch window {
+	case "this_week":
+		return analytics.BucketStart(now, analytics.GroupByWeek), now, nil
+	case "this_month":
+		return analytics.BucketStart(now, analytics.GroupByMonth), now, nil
+	case "last_month":
+		end := analytics.BucketStart(now, analytics.GroupByMonth)
+		return end.AddDate(0, -1, 0), end, nil
+	case "this_year":
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC), now, nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("window must be one of: this_week, this_month, last_month, this_year")
+	}
+}
+
+// section runs fetch and records its result or error
+func section(fetch func() (interface{}, error)) SnapshotSection {
+	data, err := fetch()
+	if err != nil {
+		return SnapshotSection{OK: false, Error: err.Error()}
+	}
+	return SnapshotSection{OK: true, Data: data}
+}
+
+// Get returns balances, budgets, goals, pending expenses, outstanding invoices
+// and window activity, fetched concurrently
+func (h *SnapshotHandler) Get(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	window := query.Get("window")
+	if window == "" {
+		window = "this_month"
+	}
+	from, to, err := snapshotWindow(window, time.Now())
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	snapshot := &Snapshot{Window: window, From: from, To: to, Scope: query.Get("scope")}
+	if snapshot.Scope == "" {
+		snapshot.Scope = "all"
+	}
+
+	switch snapshot.Scope {
+	case "all":
+	case "beneficiary":
+		identifier := query.Get("beneficiary_id")
+		if identifier == "" {
+			WriteJSONBadRequest(w, "beneficiary_id is required for beneficiary scope")
+			return
+		}
+		code, err := resolveBeneficiary(identifier)
+		if err != nil {
+			WriteJSONError(w, err, http.StatusNotFound)
+			return
+		}
+		snapshot.RecipientCode = code
+	default:
+		WriteJSONBadRequest(w, fmt.Sprintf("unsupported scope: %s", snapshot.Scope))
+		return
+	}
+
+	var wg sync.WaitGroup
+	run := func(dest *SnapshotSection, fetch func() (interface{}, error)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			*dest = section(fetch)
+		}()
+	}
+
+	run(&snapshot.Balances, h.balances)
+	run(&snapshot.Budgets, func() (interface{}, error) { return ListActiveBudgets() })
+	run(&snapshot.Goals, func() (interface{}, error) { return openGoals(time.Now()) })
+	run(&snapshot.PendingExpenses, func() (interface{}, error) { return pendingExpenses(snapshot.RecipientCode) })
+	run(&snapshot.Invoices, func() (interface{}, error) { return outstandingInvoices() })
+	run(&snapshot.Activity, func() (interface{}, error) { return windowActivity(from, to, snapshot.RecipientCode) })
+	wg.Wait()
+
+	for _, s := range []SnapshotSection{snapshot.Balances, snapshot.Budgets, snapshot.Goals, snapshot.PendingExpenses, snapshot.Invoices, snapshot.Activity} {
+		if !s.OK {
+			snapshot.Partial = true
+		}
+	}
+
+	WriteJSONSuccess(w, snapshot)
+}
+
+// balances fetches per-currency balances from Paystack, giving up after a timeout
+func (h *SnapshotHandler) balances() (interface{}, error) {
+	type result struct {
+		balances []map[string]interface{}
+		err      error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- result{err: fmt.Errorf("balance check failed: %v", rec)}
+			}
+		}()
+		balances, err := h.client.CheckBalances()
+		done <- result{balances, err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil, fmt.Errorf("failed to check balance: %w", res.err)
+		}
+		return res.balances, nil
+	case <-time.After(snapshotPaystackTimeout):
+		return nil, fmt.Errorf("balance check timed out after %s", snapshotPaystackTimeout)
+	}
+}
+
+// openGoals lists pending goals with time and budget progress
+func openGoals(now time.Time) ([]GoalProgress, error) {
+	rows, err := database.DB.Query(`
+		SELECT g.id, g.title, COALESCE(g.description, ''), g.goal_type, g.target_amount, g.budget_limit_id,
+		       g.frequency, g.start_date, g.end_date, g.status, COALESCE(g.category, ''), g.priority,
+		       COALESCE(g.notes, ''), g.created_at, g.updated_at,
+		       b.amount - b.spent_amount
+		FROM goals g
+		LEFT JOIN budget_limits b ON b.id = g.budget_limit_id
+		WHERE g.status = 'pending'
+		ORDER BY CASE g.priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END, g.end_date
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goals: %w", err)
+	}
+	defer rows.Close()
+
+	goals := []GoalProgress{}
+	for rows.Next() {
+		var gp GoalProgress
+		var budgetID *int
+		var budgetRemaining *money.Amount
+		var endDate *time.Time
+		if err := rows.Scan(
+			&gp.ID,
+			&gp.Title,
+			&gp.Description,
+			&gp.GoalType,
+			&gp.TargetAmount,
+			&budgetID,
+			&gp.Frequency,
+			&gp.StartDate,
+			&endDate,
+			&gp.Status,
+			&gp.Category,
+			&gp.Priority,
+			&gp.Notes,
+			&gp.CreatedAt,
+			&gp.UpdatedAt,
+			&budgetRemaining,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		gp.BudgetLimitID = budgetID
+		gp.EndDate = endDate
+		gp.BudgetRemaining = budgetRemaining
+		gp.Affordable = budgetRemaining == nil || *budgetRemaining >= gp.TargetAmount
+
+		if endDate != nil {
+			days := int(endDate.Sub(now).Hours() / 24)
+			gp.DaysRemaining = &days
+			if total := endDate.Sub(gp.StartDate); total > 0 {
+				gp.TimeElapsedPercent = min(max(float64(now.Sub(gp.StartDate))/float64(total)*100, 0), 100)
+			}
+		}
+
+		goals = append(goals, gp)
+	}
+	return goals, rows.Err()
+}
+
+// pendingExpenses lists unpaid expenses with their total, optionally for one recipient
+func pendingExpenses(recipientCode string) (map[string]interface{}, error) {
+	query := `
+		SELECT id, recipient_code, recipient_name, amount, currency, COALESCE(category, ''),
+		       COALESCE(narration, ''), COALESCE(reference, ''), status, created_at, updated_at
+		FROM expenses WHERE status = 'pending'
+	`
+	var args []interface{}
+	if recipientCode != "" {
+		query += " AND recipient_code = ?"
+		args = append(args, recipientCode)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expenses: %w", err)
+	}
+	defer rows.Close()
+
+	expenses := []Expense{}
+	totals := currencyTotals{}
+	for rows.Next() {
+		var e Expense
+		if err := rows.Scan(
+			&e.ID,
+			&e.RecipientCode,
+			&e.RecipientName,
+			&e.Amount,
+			&e.Currency,
+			&e.Category,
+			&e.Narration,
+			&e.Reference,
+			&e.Status,
+			&e.CreatedAt,
+			&e.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan expense: %w", err)
+		}
+		totals.add(e.Money)
+		expenses = append(expenses, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals.fill(map[string]interface{}{
+		"expenses": expenses,
+		"count":    len(expenses),
+	}), nil
+}
+
+// outstandingInvoices lists cached invoices that have not been paid
+func outstandingInvoices() (map[string]interface{}, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, invoice_code, customer_id, customer_name, amount, COALESCE(currency, 'NGN'), COALESCE(status, ''), due_date, created_at, updated_at
+		FROM invoices
+		WHERE COALESCE(status, '') NOT IN ('paid', 'success', 'cancelled')
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := []Invoice{}
+	totals := currencyTotals{}
+	for rows.Next() {
+		var inv Invoice
+		var dueDate sql.NullTime
+		if err := rows.Scan(
+			&inv.ID,
+			&inv.InvoiceCode,
+			&inv.CustomerID,
+			&inv.CustomerName,
+			&inv.Amount,
+			&inv.Currency,
+			&inv.Status,
+			&dueDate,
+			&inv.CreatedAt,
+			&inv.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		if dueDate.Valid {
+			inv.DueDate = &dueDate.Time
+		}
+		totals.add(inv.Money)
+		invoices = append(invoices, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals.fill(map[string]interface{}{
+		"invoices": invoices,
+		"count":    len(invoices),
+	}), nil
+}
+
+// currencyTotals sums amounts per currency, since different currencies can't be added
+type currencyTotals map[money.Currency]money.Amount
+
+func (t currencyTotals) add(m money.Money) {
+	t[m.Currency.OrDefault()] += m.Amount
+}
+
+// fill adds "totals" by currency to a section. When everything is in one
+// currency, total_amount and currency are set too.
+func (t currencyTotals) fill(section map[string]interface{}) map[string]interface{} {
+	section["totals"] = t
+	switch len(t) {
+	case 0:
+		section["total_amount"], section["currency"] = money.Amount(0), money.Default
+	case 1:
+		for currency, total := range t {
+			section["total_amount"], section["currency"] = total, currency
+		}
+	}
+	return section
+}
+
+// windowActivity totals money received, spent and sent in [from, to) and the window before it
+func windowActivity(from, to time.Time, recipientCode string) (*SnapshotActivity, error) {
+	previousFrom, _ := analytics.PreviousRange(from, to)
+
+	report := func(source string, filter analytics.Filter) (*analytics.Report, error) {
+		rows, err := loadAggregateRows(source, previousFrom, to)
+		if err != nil {
+			return nil, err
+		}
+		return analytics.Aggregate(rows, analytics.GroupByStatus, from, to, filter), nil
+	}
+
+	activity := &SnapshotActivity{}
+
+	// Incoming payments aren't tied to a recipient, so they're skipped for beneficiary scope
+	if recipientCode == "" {
+		received, err := report("transactions", analytics.Filter{Status: "success"})
+		if err != nil {
+			return nil, err
+		}
+		activity.Received, activity.Previous.Received = received.Totals, received.Previous
+	}
+
+	spent, err := report("expenses", analytics.Filter{Recipient: recipientCode})
+	if err != nil {
+		return nil, err
+	}
+	activity.Spent, activity.Previous.Spent = spent.Totals, spent.Previous
+
+	sent, err := report("transfers", analytics.Filter{Recipient: recipientCode})
+	if err != nil {
+		return nil, err
+	}
+	activity.Sent, activity.Previous.Sent = sent.Totals, sent.Previous
+
+	return activity, nil
+}
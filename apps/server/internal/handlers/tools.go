@@ -0,0 +1,128 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Tools Handler - Voice Agent Integration
+//
+// OBJECTIVES:
+// The voice agent should call one endpoint for every tool instead of mapping each tool to REST calls.
+//
+// PURPOSE:
+// - Execute any registered tool by name with JSON arguments
+// - Return normalized results with a spoken summary the agent can read out
+// - Serve the tool catalog so clients don't duplicate tool definitions
+//
+// KEY WORKFLOW:
+// Fetch Catalog → Agent Picks Tool → Execute Tool → Registry Dispatch →
+// Normalized Result + Spoken Summary
+//
+// DESIGN DECISIONS:
+// - Dispatch goes through the shared tools registry (same one the MCP server uses)
+// - The catalog is served in OpenAI function format, matching the client's toolsConfig
+// - The HTTP status of an execution mirrors the underlying handler's status
+// - Argument validation errors are reported before any handler runs
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"paystack.mpc.proxy/internal/tools"
+)
+
+type ToolHandler struct {
+	registry *tools.Registry
+}
+
+func NewToolHandler(registry *tools.Registry) *ToolHandler {
+	return &ToolHandler{registry: registry}
+}
+
+// ExecuteToolRequest represents a tool invocation
+type ExecuteToolRequest struct {
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// ToolExecution is the normalized result of a tool invocation
+type ToolExecution struct {
+	Tool          string          `json:"tool"`
+	StatusCode    int             `json:"status_code"`
+	SpokenSummary string          `json:"spoken_summary"`
+	Result        json.RawMessage `json:"result,omitempty"`
+	Error         string          `json:"error,omitempty"`
+}
+
+// ToolDefinition is a catalog entry in OpenAI function format
+type ToolDefinition struct {
+	Type        string                 `json:"type"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// Execute runs a tool by name and returns its normalized result
+func (h *ToolHandler) Execute(w http.ResponseWriter, r *http.Request) {
+	var req ExecuteToolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONBadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.Tool == "" {
+		WriteJSONBadRequest(w, "tool is required")
+		return
+	}
+
+	if _, ok := h.registry.Lookup(req.Tool); !ok {
+		WriteJSONError(w, fmt.Errorf("unknown tool: %s", req.Tool), http.StatusNotFound)
+		return
+	}
+
+	if req.Arguments == nil {
+		req.Arguments = map[string]interface{}{}
+	}
+
+	result, err := h.registry.Call(r.Context(), req.Tool, req.Arguments)
+	if err != nil {
+		var argErr *tools.ArgumentError
+		if errors.As(err, &argErr) {
+			WriteJSONBadRequest(w, argErr.Error())
+			return
+		}
+		WriteJSONError(w, fmt.Errorf("failed to execute tool: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	message := result.Message
+	if message == "" {
+		message = "Success"
+	}
+
+	respondWithJSON(w, result.StatusCode, map[string]interface{}{
+		"status":  result.Status,
+		"message": message,
+		"data": ToolExecution{
+			Tool:          req.Tool,
+			StatusCode:    result.StatusCode,
+			SpokenSummary: result.Summary,
+			Result:        result.Data,
+			Error:         result.Error,
+		},
+	})
+}
+
+// Catalog returns every registered tool definition
+func (h *ToolHandler) Catalog(w http.ResponseWriter, r *http.Request) {
+	definitions := []ToolDefinition{}
+	for _, tool := range h.registry.Tools() {
+		definitions = append(definitions, ToolDefinition{
+			Type:        "function",
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.InputSchema,
+		})
+	}
+
+	WriteJSONSuccess(w, definitions)
+}
@@ -0,0 +1,553 @@
+// Package handlers implements HTTP handlers for the moniewave financial management system.
+//
+// Transaction Sync - Local Ledger
+//
+// OBJECTIVES:
+// Keep a local copy of Paystack transactions so reporting doesn't depend on live API calls.
+//
+// PURPOSE:
+// - Page through Paystack transactions incrementally and upsert them by reference
+// - Run on an interval in the background and on demand
+// - List and search the local copy with status, channel, customer, amount and date filters
+//
+// KEY WORKFLOW:
+// Load Sync State → Fix Run Window → Fetch Page → Upsert Transactions →
+// Save Cursor → Next Page → Advance Watermark
+//
+// DESIGN DECISIONS:
+// - Each run fixes its window (from, to) up front so page boundaries stay stable while paging
+// - The page cursor is saved after every page; a failed run resumes where it stopped
+// - Runs start an hour before the watermark so late status changes are picked up
+// - Only one sync runs at a time; concurrent requests get the current state back
+// - Sync errors are stored on the sync state rather than failing callers of the local endpoints
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
+	"paystack.mpc.proxy/internal/paystack"
+)
+
+const (
+	transactionSyncName    = "paystack_transactions"
+	transactionSyncPerPage = 100
+	transactionSyncOverlap = time.Hour
+	// transactionSyncMaxPages bounds one run; the cursor carries the rest to the next run
+	transactionSyncMaxPages = 50
+)
+
+var transactionSyncMu sync.Mutex
+
+// TransactionSyncState is the stored progress of the transaction sync
+type TransactionSyncState struct {
+	CursorPage    int        `json:"cursor_page"`
+	RunFrom       *time.Time `json:"run_from,omitempty"`
+	RunTo         *time.Time `json:"run_to,omitempty"`
+	Watermark     *time.Time `json:"watermark,omitempty"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	RecordsSynced int        `json:"records_synced"`
+	Running       bool       `json:"running"`
+}
+
+// TransactionSyncResult describes one sync run
+type TransactionSyncResult struct {
+	Pages    int                   `json:"pages"`
+	Synced   int                   `json:"synced"`
+	Complete bool                  `json:"complete"`
+	Resumed  bool                  `json:"resumed"`
+	State    *TransactionSyncState `json:"state"`
+}
+
+// LoadTransactionSyncState reads the sync state, returning a zero state before the first run
+func LoadTransactionSyncState() (*TransactionSyncState, error) {
+	var state TransactionSyncState
+	var runFrom, runTo, watermark, lastRunAt, lastSuccessAt sql.NullTime
+	var lastError sql.NullString
+
+	err := database.DB.QueryRow(`
+		SELECT cursor_page, run_from, run_to, watermark, last_run_at, last_success_at, last_error, records_synced
+		FROM sync_state WHERE name = ?
+	`, transactionSyncName).Scan(
+		&state.CursorPage,
+		&runFrom,
+		&runTo,
+		&watermark,
+		&lastRunAt,
+		&lastSuccessAt,
+		&lastError,
+		&state.RecordsSynced,
+	)
+	if err == sql.ErrNoRows {
+		return &state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sync state: %w", err)
+	}
+
+	state.RunFrom = nullTimePtr(runFrom)
+	state.RunTo = nullTimePtr(runTo)
+	state.Watermark = nullTimePtr(watermark)
+	state.LastRunAt = nullTimePtr(lastRunAt)
+	state.LastSuccessAt = nullTimePtr(lastSuccessAt)
+	state.LastError = lastError.String
+	return &state, nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func timeOrNil(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+func saveTransactionSyncState(state *TransactionSyncState) error {
+	var lastError interface{}
+	if state.LastError != "" {
+		lastError = state.LastError
+	}
+
+	_, err := database.DB.Exec(`
+		INSERT INTO sync_state (name, cursor_page, run_from, run_to, watermark, last_run_at, last_success_at, last_error, records_synced)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			cursor_page = excluded.cursor_page,
+			run_from = excluded.run_from,
+			run_to = excluded.run_to,
+			watermark = excluded.watermark,
+			last_run_at = excluded.last_run_at,
+			last_success_at = excluded.last_success_at,
+			last_error = excluded.last_error,
+			records_synced = excluded.records_synced
+	`,
+		transactionSyncName,
+		state.CursorPage,
+		timeOrNil(state.RunFrom),
+		timeOrNil(state.RunTo),
+		timeOrNil(state.Watermark),
+		timeOrNil(state.LastRunAt),
+		timeOrNil(state.LastSuccessAt),
+		lastError,
+		state.RecordsSynced,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+	return nil
+}
+
+// SyncTransactions pages through Paystack transactions since the last sync and
+// upserts them locally. full ignores the watermark and re-syncs everything.
+// It returns (nil, nil) when another sync is already running.
+func SyncTransactions(client *paystack.Client, full bool) (*TransactionSyncResult, error) {
+	if !transactionSyncMu.TryLock() {
+		return nil, nil
+	}
+	defer transactionSyncMu.Unlock()
+
+	state, err := LoadTransactionSyncState()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	result := &TransactionSyncResult{State: state}
+
+	// Resume an interrupted run with its original window, otherwise start a new one
+	if state.CursorPage > 0 && !full {
+		result.Resumed = true
+	} else {
+		state.CursorPage = 1
+		state.RunFrom = nil
+		state.RunTo = &now
+		if state.Watermark != nil && !full {
+			from := state.Watermark.Add(-transactionSyncOverlap)
+			state.RunFrom = &from
+		}
+	}
+	state.LastRunAt = &now
+
+	var from, to time.Time
+	if state.RunFrom != nil {
+		from = *state.RunFrom
+	}
+	if state.RunTo != nil {
+		to = *state.RunTo
+	}
+
+	newest := state.Watermark
+	for result.Pages < transactionSyncMaxPages {
+		list, err := client.ListTransactions(state.CursorPage, transactionSyncPerPage, from, to)
+		if err != nil {
+			state.Watermark = newest
+			state.RecordsSynced += result.Synced
+			state.LastError = err.Error()
+			if saveErr := saveTransactionSyncState(state); saveErr != nil {
+				fmt.Printf("Warning: %v\n", saveErr)
+			}
+			return result, fmt.Errorf("failed to fetch transactions page %d: %w", state.CursorPage, err)
+		}
+
+		result.Pages++
+		result.Synced += cacheTransactions(list.Values)
+
+		for _, txn := range list.Values {
+			if created, err := time.Parse(time.RFC3339, txn.CreatedAt); err == nil {
+				if newest == nil || created.After(*newest) {
+					newest = &created
+				}
+			}
+		}
+
+		if len(list.Values) == 0 || state.CursorPage >= list.Meta.PageCount {
+			result.Complete = true
+			break
+		}
+
+		state.CursorPage++
+		if err := saveTransactionSyncState(state); err != nil {
+			return result, err
+		}
+	}
+
+	// Resumed runs keep their own window, so the watermark can advance before a run completes
+	state.Watermark = newest
+	state.RecordsSynced += result.Synced
+	state.LastError = ""
+	if result.Complete {
+		state.CursorPage = 0
+		state.RunFrom = nil
+		state.RunTo = nil
+		state.LastSuccessAt = &now
+	}
+	if err := saveTransactionSyncState(state); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// StartTransactionSync runs SyncTransactions every interval in the background
+func StartTransactionSync(client *paystack.Client, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			result, err := SyncTransactions(client, false)
+			if err != nil {
+				log.Printf("Transaction sync failed: %v", err)
+			} else if result != nil && result.Synced > 0 {
+				log.Printf("Transaction sync: %d transactions over %d pages", result.Synced, result.Pages)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+type SyncTransactionsRequest struct {
+	Full bool `json:"full,omitempty"`
+}
+
+// Sync runs a transaction sync now
+func (h *TransactionHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	var req SyncTransactionsRequest
+	if r.Body != http.NoBody {
+		json.NewDecoder(r.Body).Decode(&req)
+	}
+
+	result, err := SyncTransactions(h.client, req.Full)
+	if err != nil {
+		WriteJSONError(w, err, http.StatusBadGateway)
+		return
+	}
+
+	if result == nil {
+		state, err := LoadTransactionSyncState()
+		if err != nil {
+			WriteJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+		state.Running = true
+		WriteJSONSuccessWithMessage(w, "A sync is already running", state)
+		return
+	}
+
+	message := "Transactions synced"
+	if !result.Complete {
+		message = "Transactions partly synced; the next run continues from the saved cursor"
+	}
+	WriteJSONSuccessWithMessage(w, message, result)
+}
+
+// SyncStatus returns the stored sync state
+func (h *TransactionHandler) SyncStatus(w http.ResponseWriter, r *http.Request) {
+	state, err := LoadTransactionSyncState()
+	if err != nil {
+		WriteJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if transactionSyncMu.TryLock() {
+		transactionSyncMu.Unlock()
+	} else {
+		state.Running = true
+	}
+
+	var count int
+	database.DB.QueryRow("SELECT COUNT(*) FROM transactions").Scan(&count)
+
+	WriteJSONSuccess(w, map[string]interface{}{
+		"state":              state,
+		"local_transactions": count,
+	})
+}
+
+// LocalTransaction is a transaction from the local store
+type LocalTransaction struct {
+	ID              int       `json:"id"`
+	PaystackID      *int      `json:"paystack_id,omitempty"`
+	Reference       string    `json:"reference"`
+	money.Money
+	Status          string    `json:"status"`
+	Channel         string    `json:"channel"`
+	CustomerEmail   string    `json:"customer_email"`
+	CustomerCode    string    `json:"customer_code"`
+	TransactionDate time.Time `json:"transaction_date"`
+	SyncedAt        time.Time `json:"synced_at"`
+}
+
+// localTransactionFilter holds the list and search filters, parsed from the query string
+type localTransactionFilter struct {
+	Query     string
+	Status    string
+	Channel   string
+	Customer  string
+	Currency  string
+	MinAmount int
+	MaxAmount int
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+func parseLocalTransactionFilter(r *http.Request) (*localTransactionFilter, error) {
+	q := r.URL.Query()
+	filter := &localTransactionFilter{
+		Query:    strings.TrimSpace(q.Get("q")),
+		Status:   q.Get("status"),
+		Channel:  q.Get("channel"),
+		Customer: q.Get("customer"),
+		Currency: strings.ToUpper(q.Get("currency")),
+		Limit:    50,
+	}
+
+	ints := []struct {
+		name string
+		dest *int
+	}{
+		{"min_amount", &filter.MinAmount},
+		{"max_amount", &filter.MaxAmount},
+		{"limit", &filter.Limit},
+		{"offset", &filter.Offset},
+	}
+	for _, p := range ints {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s must be a non-negative integer", p.name)
+			}
+			*p.dest = n
+		}
+	}
+	if filter.MaxAmount > 0 && filter.MinAmount > filter.MaxAmount {
+		return nil, fmt.Errorf("min_amount cannot be greater than max_amount")
+	}
+	if filter.Limit == 0 || filter.Limit > 500 {
+		filter.Limit = 500
+	}
+
+	if v := q.Get("from"); v != "" {
+		from, err := parseRangeTime(v, false)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		filter.From = from
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := parseRangeTime(v, true)
+		if err != nil {
+			return nil, fmt.Errorf("to: %w", err)
+		}
+		filter.To = to
+	}
+
+	return filter, nil
+}
+
+// where builds the WHERE clause for the filter
+func (f *localTransactionFilter) where() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if f.Query != "" {
+		like := "%" + f.Query + "%"
+		conditions = append(conditions, "(reference LIKE ? OR customer_email LIKE ? OR customer_code LIKE ?)")
+		args = append(args, like, like, like)
+	}
+	if f.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, f.Status)
+	}
+	if f.Channel != "" {
+		conditions = append(conditions, "channel = ?")
+		args = append(args, f.Channel)
+	}
+	if f.Customer != "" {
+		conditions = append(conditions, "(customer_email = ? OR customer_code = ?)")
+		args = append(args, f.Customer, f.Customer)
+	}
+	if f.Currency != "" {
+		conditions = append(conditions, "currency = ?")
+		args = append(args, f.Currency)
+	}
+	if f.MinAmount > 0 {
+		conditions = append(conditions, "amount >= ?")
+		args = append(args, f.MinAmount)
+	}
+	if f.MaxAmount > 0 {
+		conditions = append(conditions, "amount <= ?")
+		args = append(args, f.MaxAmount)
+	}
+	if !f.From.IsZero() {
+		conditions = append(conditions, "transaction_date >= ?")
+		args = append(args, f.From)
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, "transaction_date < ?")
+		args = append(args, f.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ListLocal lists transactions from the local store
+func (h *TransactionHandler) ListLocal(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLocalTransactionFilter(r)
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	h.writeLocalTransactions(w, filter)
+}
+
+// Search searches local transactions by reference, customer email or customer code
+func (h *TransactionHandler) Search(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLocalTransactionFilter(r)
+	if err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+	if filter.Query == "" {
+		WriteJSONBadRequest(w, "q is required")
+		return
+	}
+	h.writeLocalTransactions(w, filter)
+}
+
+func (h *TransactionHandler) writeLocalTransactions(w http.ResponseWriter, filter *localTransactionFilter) {
+	where, args := filter.where()
+
+	// Sums are kept per currency, since amounts in different currencies can't be added
+	total := 0
+	totals := currencyTotals{}
+	sums, err := database.DB.Query("SELECT COALESCE(currency, 'NGN'), COUNT(*), COALESCE(SUM(amount), 0) FROM transactions"+where+" GROUP BY 1", args...)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to count transactions: %w", err), http.StatusInternalServerError)
+		return
+	}
+	for sums.Next() {
+		var sum money.Money
+		var count int
+		if err := sums.Scan(&sum.Currency, &count, &sum.Amount); err != nil {
+			sums.Close()
+			WriteJSONError(w, fmt.Errorf("failed to count transactions: %w", err), http.StatusInternalServerError)
+			return
+		}
+		total += count
+		totals.add(sum)
+	}
+	sums.Close()
+
+	query := `
+		SELECT id, paystack_id, reference, amount, COALESCE(currency, 'NGN'), COALESCE(status, ''),
+		       COALESCE(channel, ''), COALESCE(customer_email, ''), COALESCE(customer_code, ''),
+		       transaction_date, synced_at
+		FROM transactions` + where + " ORDER BY transaction_date DESC, id DESC LIMIT ? OFFSET ?"
+
+	rows, err := database.DB.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("failed to list transactions: %w", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	transactions := []LocalTransaction{}
+	for rows.Next() {
+		var txn LocalTransaction
+		if err := rows.Scan(
+			&txn.ID,
+			&txn.PaystackID,
+			&txn.Reference,
+			&txn.Amount,
+			&txn.Currency,
+			&txn.Status,
+			&txn.Channel,
+			&txn.CustomerEmail,
+			&txn.CustomerCode,
+			&txn.TransactionDate,
+			&txn.SyncedAt,
+		); err != nil {
+			WriteJSONError(w, fmt.Errorf("failed to scan transaction: %w", err), http.StatusInternalServerError)
+			return
+		}
+		transactions = append(transactions, txn)
+	}
+
+	response := totals.fill(map[string]interface{}{
+		"transactions": transactions,
+		"count":        len(transactions),
+		"total":        total,
+		"limit":        filter.Limit,
+		"offset":       filter.Offset,
+	})
+
+	// Tell the caller how fresh the local data is
+	if state, err := LoadTransactionSyncState(); err == nil {
+		response["last_synced_at"] = state.LastSuccessAt
+	}
+
+	WriteJSONSuccess(w, response)
+}
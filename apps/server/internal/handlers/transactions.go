@@ -16,7 +16,8 @@
 // Verify Transaction → Update Status → Record Revenue
 //
 // DESIGN DECISIONS:
-// - All transactions go through Paystack API (no local storage)
+// - All transactions go through Paystack API; listed, verified and synced transactions
+//   are stored locally so analytics and reconciliation work offline
 // - Reference is used to track transaction state
 // - Verification is required before considering payment complete
 // - List supports pagination for large transaction histories
@@ -26,7 +27,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
 	"paystack.mpc.proxy/internal/paystack"
 
 	paystackSDK "github.com/borderlesshq/paystack-go"
@@ -41,11 +45,10 @@ func NewTransactionHandler(client *paystack.Client) *TransactionHandler {
 }
 
 type InitializeTransactionRequest struct {
-	Email       string  `json:"email"`
-	Amount      float64 `json:"amount"`
-	Reference   string  `json:"reference,omitempty"`
-	CallbackURL string  `json:"callback_url,omitempty"`
-	Currency    string  `json:"currency,omitempty"`
+	Email string `json:"email"`
+	money.Money
+	Reference   string `json:"reference,omitempty"`
+	CallbackURL string `json:"callback_url,omitempty"`
 }
 
 type VerifyTransactionRequest struct {
@@ -63,13 +66,18 @@ func (h *TransactionHandler) Initialize(w http.ResponseWriter, r *http.Request)
 		WriteJSONBadRequest(w, "email and amount are required")
 		return
 	}
+	req.Currency = req.Currency.OrDefault()
+	if err := req.Validate(); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
 
 	txn := &paystackSDK.TransactionRequest{
 		Email:       req.Email,
-		Amount:      req.Amount,
+		Amount:      float64(req.Amount),
 		Reference:   req.Reference,
 		CallbackURL: req.CallbackURL,
-		Currency:    req.Currency,
+		Currency:    string(req.Currency),
 	}
 
 	result, err := h.client.Transaction.Initialize(txn)
@@ -97,6 +105,7 @@ func (h *TransactionHandler) Verify(w http.ResponseWriter, r *http.Request) {
 		WriteJSONError(w, err, http.StatusInternalServerError)
 		return
 	}
+	cacheTransactions([]paystackSDK.Transaction{*result})
 	WriteJSONSuccess(w, result)
 }
 
@@ -112,6 +121,7 @@ func (h *TransactionHandler) List(w http.ResponseWriter, r *http.Request) {
 			WriteJSONError(w, err, http.StatusInternalServerError)
 			return
 		}
+		cacheTransactions(result.Values)
 		WriteJSONSuccess(w, result)
 		return
 	}
@@ -121,5 +131,68 @@ func (h *TransactionHandler) List(w http.ResponseWriter, r *http.Request) {
 		WriteJSONError(w, fmt.Errorf("failed to list transactions: %w", err), http.StatusInternalServerError)
 		return
 	}
+	cacheTransactions(result.Values)
 	WriteJSONSuccess(w, result)
 }
+
+// cacheTransactions upserts Paystack transactions into the local store by
+// reference and returns how many were stored. Failures are logged and never
+// fail the request.
+func cacheTransactions(transactions []paystackSDK.Transaction) int {
+	query := `
+		INSERT INTO transactions (
+			paystack_id, reference, amount, currency, status, channel,
+			customer_email, customer_code, transaction_date, synced_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(reference) DO UPDATE SET
+			paystack_id = excluded.paystack_id,
+			amount = excluded.amount,
+			currency = excluded.currency,
+			status = excluded.status,
+			channel = excluded.channel,
+			customer_email = excluded.customer_email,
+			customer_code = excluded.customer_code,
+			transaction_date = excluded.transaction_date,
+			synced_at = excluded.synced_at
+	`
+
+	now := time.Now()
+	stored := 0
+	for _, txn := range transactions {
+		if txn.Reference == "" {
+			continue
+		}
+
+		transactionDate := now
+		if parsed, err := time.Parse(time.RFC3339, txn.CreatedAt); err == nil {
+			transactionDate = parsed
+		}
+
+		currency := money.Currency(txn.Currency).OrDefault()
+
+		var paystackID interface{}
+		if txn.ID != 0 {
+			paystackID = txn.ID
+		}
+
+		_, err := database.DB.Exec(query,
+			paystackID,
+			txn.Reference,
+			int(txn.Amount),
+			currency,
+			txn.Status,
+			txn.Channel,
+			txn.Customer.Email,
+			txn.Customer.CustomerCode,
+			transactionDate,
+			now,
+		)
+		if err != nil {
+			fmt.Printf("Warning: Failed to cache transaction %s: %v\n", txn.Reference, err)
+			continue
+		}
+		stored++
+	}
+
+	return stored
+}
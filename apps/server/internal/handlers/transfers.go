@@ -11,19 +11,35 @@
 // - Track transfer status and history
 //
 // KEY WORKFLOW:
-// Create Recipient → Initiate Transfer → Verify Transfer → Complete Transaction
+// Create Recipient → Initiate Transfer (preview) → Confirm With Token → Verify Transfer → Complete Transaction
 //
 // DESIGN DECISIONS:
 // - Recipients created via Paystack API before transfers
 // - All transfers go through Paystack (no direct bank integration)
 // - Currency defaults to NGN (Nigerian Naira)
 // - Reason field for transfer narration and tracking
+// - Transfers are previewed with a one-time confirmation token before any money moves
+// - Account and per-beneficiary transfer limits are checked at preview and again at execution
+// - Likely duplicates and unusually large amounts are flagged at preview and again at execution
+// - Every transfer carries a reference fixed before it is sent (from the confirmation
+//   token here), so a lost answer can be looked up. Its limit reservation is released
+//   only when Paystack refused the transfer or has no record of it; otherwise it stays
+//   counted and the transfer is reported as needing review
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
+	"paystack.mpc.proxy/internal/anomaly"
+	"paystack.mpc.proxy/internal/confirm"
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
 	"paystack.mpc.proxy/internal/paystack"
 
 	paystackSDK "github.com/borderlesshq/paystack-go"
@@ -46,10 +62,13 @@ type CreateRecipientRequest struct {
 }
 
 type InitiateTransferRequest struct {
-	Source    string  `json:"source"`
-	Amount    float32 `json:"amount"`
-	Recipient string  `json:"recipient"`
-	Reason    string  `json:"reason,omitempty"`
+	Source    string `json:"source"`
+	money.Money
+	Recipient string `json:"recipient"`
+	Reason    string `json:"reason,omitempty"`
+
+	// ConfirmationToken executes a previously previewed transfer
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
 }
 
 func (h *TransferHandler) CreateRecipient(w http.ResponseWriter, r *http.Request) {
@@ -91,22 +110,177 @@ func (h *TransferHandler) Initiate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A confirmation token replaces the body with the previewed request; nothing else
+	// in the body is kept, so a field can't be added after the preview
+	confirmed := false
+	token := req.ConfirmationToken
+	if token != "" {
+		var previewed InitiateTransferRequest
+		if !redeemConfirmation(w, token, confirm.ActionTransfer, &previewed) {
+			return
+		}
+		req = previewed
+		confirmed = true
+	}
+
 	if req.Source == "" || req.Amount == 0 || req.Recipient == "" {
 		WriteJSONBadRequest(w, "source, amount, and recipient are required")
 		return
 	}
+	req.Currency = req.Currency.OrDefault()
+	if err := req.Validate(); err != nil {
+		WriteJSONBadRequest(w, err.Error())
+		return
+	}
+
+	// Checked again on confirmation, since the recipient may have been deleted since the preview
+	if !resolvePayableRecipient(w, &req.Recipient) {
+		return
+	}
+
+	// Look for duplicates and unusual amounts; these warn but never block
+	payment := anomaly.Payment{
+		RecipientCode: req.Recipient,
+		RecipientName: h.recipientName(req.Recipient),
+		Money:         req.Money,
+		Narration:     req.Reason,
+	}
+	warnings := checkPayment(payment)
+
+	if !confirmed {
+		check, err := CheckTransferLimits(req.Recipient, req.Money)
+		if err != nil {
+			WriteJSONError(w, fmt.Errorf("error checking transfer limits: %w", err), http.StatusInternalServerError)
+			return
+		}
+		if !check.Allowed {
+			writeLimitRejection(w, check)
+			return
+		}
+
+		preview := ConfirmationPreview{
+			Money:         req.Money,
+			RecipientName: payment.RecipientName,
+			LimitAlerts:   check.Alerts,
+			Warnings:      warnings,
+			Summary:       fmt.Sprintf("Send %s to %s", req.Format(), payment.RecipientName),
+		}
 
+		// Budget impact is informational for transfers - the default budget is not enforced here.
+		// Amounts in another currency are converted at today's rate, and left out when there's none.
+		if budget, err := FindOrCreateDefaultBudget(); err == nil {
+			if impact, err := CheckBudgetAffordabilityOn(budget.ID, req.Money, time.Now()); err == nil {
+				preview.BudgetImpact = impact
+			}
+		}
+
+		writeConfirmationPreview(w, confirm.ActionTransfer, req, preview)
+		return
+	}
+
+	// Limits may have moved since the preview, so reserve against them again
+	reservationID, check, err := ReserveOutgoingPayment(req.Recipient, req.Money, "transfer", req.Reason)
+	if err != nil {
+		WriteJSONError(w, fmt.Errorf("error checking transfer limits: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if !check.Allowed {
+		writeLimitRejection(w, check)
+		return
+	}
+
+	// Tagged before the call so the reservation can be matched to the transfer if the answer is lost
+	reference := transferReference("txf_", token)
+	SetOutgoingPaymentReference(reservationID, reference)
 	transferReq := &paystackSDK.TransferRequest{
 		Source:    req.Source,
-		Amount:    req.Amount,
+		Amount:    float32(req.Amount),
+		Currency:  string(req.Currency),
 		Recipient: req.Recipient,
 		Reason:    req.Reason,
+		Reference: reference,
 	}
 
-	result, err := h.client.Transfer.Initiate(transferReq)
+	result, err := initiateTransfer(h.client, transferReq)
+	if errors.Is(err, errTransferUnconfirmed) {
+		// The money may have gone, so it stays counted against the limits
+		writeUnconfirmedTransfer(w, reference, err)
+		return
+	}
 	if err != nil {
+		ReleaseOutgoingPayment(reservationID)
 		WriteJSONError(w, err, http.StatusInternalServerError)
 		return
 	}
-	WriteJSONSuccess(w, result)
+	SetOutgoingPaymentReference(reservationID, result.TransferCode)
+	postLedger("transfer "+result.TransferCode, PostTransferJournal(reservationID))
+
+	payment.Reference = result.TransferCode
+	recordAnomalies("transfer", payment, warnings)
+	WriteJSONSuccessWithWarnings(w, result, warnings)
+}
+
+// transferReference derives a Paystack transfer reference (lowercase, 16-50 characters)
+// from the confirmation token that sent it
+func transferReference(prefix, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return prefix + hex.EncodeToString(sum[:16])
+}
+
+// transferRejected reports whether Paystack definitely refused a transfer by answering
+// with a 4xx. A network error, timeout or 5xx may hide a transfer it did make.
+func transferRejected(err error) bool {
+	var apiErr *paystackSDK.APIError
+	return errors.As(err, &apiErr) && apiErr.HTTPStatusCode >= 400 && apiErr.HTTPStatusCode < 500
+}
+
+// initiateTransfer sends a transfer under its reference. When Paystack's answer is
+// lost the reference is looked up: a transfer Paystack has is returned as sent, and
+// one it never got is an ordinary error. When that can't be told either, the error
+// wraps errTransferUnconfirmed and the caller must keep the limit reservation.
+func initiateTransfer(client *paystack.Client, req *paystackSDK.TransferRequest) (*paystackSDK.Transfer, error) {
+	transfer, err := client.Transfer.Initiate(req)
+	if err == nil || transferRejected(err) {
+		return transfer, err
+	}
+
+	existing, verifyErr := client.VerifyTransfer(req.Reference)
+	if verifyErr != nil {
+		return nil, fmt.Errorf("transfer failed: %w (%w)", err, errTransferUnconfirmed)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	return nil, fmt.Errorf("transfer failed: %w", err)
+}
+
+// writeUnconfirmedTransfer responds for a transfer that may or may not have gone through
+func writeUnconfirmedTransfer(w http.ResponseWriter, reference string, err error) {
+	respondWithJSON(w, http.StatusBadGateway, map[string]interface{}{
+		"status":  false,
+		"message": fmt.Sprintf("Paystack didn't say whether the transfer went through. Check reference %s before sending it again.", reference),
+		"error":   err.Error(),
+		"data": map[string]interface{}{
+			"reference": reference,
+			"status":    transferNeedsReview,
+		},
+	})
+}
+
+// recipientName resolves a display name for a recipient code, preferring the local cache
+func (h *TransferHandler) recipientName(code string) string {
+	var name string
+	if err := database.DB.QueryRow("SELECT name FROM recipients WHERE recipient_code = ?", code).Scan(&name); err == nil && name != "" {
+		return name
+	}
+
+	recipient, err := h.client.GetTransferRecipient(code)
+	if err != nil {
+		fmt.Printf("Warning: failed to fetch recipient %s: %v\n", code, err)
+		return code
+	}
+	if name, ok := recipient["name"].(string); ok && name != "" {
+		return name
+	}
+	return code
 }
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/paystack"
+)
+
+// fakeResponse is a canned Paystack answer
+type fakeResponse struct {
+	status int
+	body   string
+}
+
+// fakePaystack answers Paystack calls with canned responses and counts them.
+// Routes are "METHOD /path" prefixes, e.g. "POST /transfer" or "GET /transfer/verify/".
+type fakePaystack struct {
+	server *httptest.Server
+	client *paystack.Client
+
+	mu        sync.Mutex
+	responses map[string]fakeResponse
+	calls     map[string]int
+}
+
+var (
+	transferSentResponse    = fakeResponse{http.StatusOK, `{"status":true,"message":"Transfer has been queued","data":{"transfer_code":"TRF_1","status":"success","amount":500000}}`}
+	transferFoundResponse   = fakeResponse{http.StatusOK, `{"status":true,"message":"Transfer retrieved","data":{"transfer_code":"TRF_1","status":"success","amount":500000}}`}
+	transferUnknownResponse = fakeResponse{http.StatusNotFound, `{"status":false,"message":"Transfer not found"}`}
+	paystackDownResponse    = fakeResponse{http.StatusBadGateway, `{"status":false,"message":"Bad gateway"}`}
+	transferRefusedResponse = fakeResponse{http.StatusBadRequest, `{"status":false,"message":"Recipient is not active"}`}
+	balanceResponse         = fakeResponse{http.StatusOK, `{"status":true,"message":"Balances retrieved","data":[{"currency":"NGN","balance":100000000}]}`}
+)
+
+func newFakePaystack(t *testing.T) *fakePaystack {
+	t.Helper()
+	f := &fakePaystack{
+		responses: map[string]fakeResponse{
+			"GET /balance":          balanceResponse,
+			"POST /transfer":        transferSentResponse,
+			"GET /transfer/verify/": transferUnknownResponse,
+		},
+		calls: map[string]int{},
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		// The longest matching route wins, so /transfer/verify/ isn't answered as /transfer
+		route := ""
+		for candidate := range f.responses {
+			method, path, _ := strings.Cut(candidate, " ")
+			if r.Method == method && strings.HasPrefix(r.URL.Path, path) && len(candidate) > len(route) {
+				route = candidate
+			}
+		}
+		if route == "" {
+			t.Errorf("Unexpected Paystack call %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.calls[route]++
+		w.WriteHeader(f.responses[route].status)
+		w.Write([]byte(f.responses[route].body))
+	}))
+	t.Cleanup(f.server.Close)
+
+	f.client = paystack.NewClientAt("sk_test", f.server.URL)
+	f.client.LoggingEnabled = false
+	return f
+}
+
+// respond sets the answer for a route
+func (f *fakePaystack) respond(route string, response fakeResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[route] = response
+}
+
+// count returns how many times a route was called
+func (f *fakePaystack) count(route string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[route]
+}
+
+// reservations counts the outgoing payments held against the limits
+func reservations(t *testing.T) int {
+	t.Helper()
+	var n int
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM outgoing_payments").Scan(&n); err != nil {
+		t.Fatalf("Failed to count reservations: %v", err)
+	}
+	return n
+}
+
+func TestInitiateKeepsReservationWhenTransferMayHaveGone(t *testing.T) {
+	tests := []struct {
+		name             string
+		initiate         fakeResponse
+		verify           fakeResponse
+		wantCode         int
+		wantReservations int
+		wantVerified     bool
+	}{
+		{"sent", transferSentResponse, transferUnknownResponse, http.StatusOK, 1, false},
+		{"refused", transferRefusedResponse, transferUnknownResponse, http.StatusInternalServerError, 0, false},
+		{"answer lost, Paystack has it", paystackDownResponse, transferFoundResponse, http.StatusOK, 1, true},
+		{"answer lost, Paystack never got it", paystackDownResponse, transferUnknownResponse, http.StatusInternalServerError, 0, true},
+		{"answer lost, can't tell", paystackDownResponse, paystackDownResponse, http.StatusBadGateway, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandlerDB(t)
+			fake := newFakePaystack(t)
+			fake.respond("POST /transfer", tt.initiate)
+			fake.respond("GET /transfer/verify/", tt.verify)
+			h := NewTransferHandler(fake.client)
+
+			code, preview := post(t, h.Initiate, map[string]interface{}{"source": "balance", "amount": 500000, "recipient": defaultRecipientCode})
+			if code != http.StatusOK {
+				t.Fatalf("Expected a preview, got %d: %v", code, preview)
+			}
+			token, _ := preview["data"].(map[string]interface{})["confirmation_token"].(string)
+
+			code, response := post(t, h.Initiate, map[string]interface{}{"confirmation_token": token})
+			if code != tt.wantCode {
+				t.Fatalf("Expected %d, got %d: %v", tt.wantCode, code, response)
+			}
+			if got := reservations(t); got != tt.wantReservations {
+				t.Errorf("Expected %d reservations, got %d", tt.wantReservations, got)
+			}
+			if verified := fake.count("GET /transfer/verify/") > 0; verified != tt.wantVerified {
+				t.Errorf("Expected the reference to be looked up: %v, got %v", tt.wantVerified, verified)
+			}
+			if got := fake.count("POST /transfer"); got != 1 {
+				t.Errorf("Expected one transfer request, got %d", got)
+			}
+		})
+	}
+}
+
+func TestTransferReferenceIsFixedByTheToken(t *testing.T) {
+	a, b := transferReference("txf_", "token-a"), transferReference("txf_", "token-b")
+	if a != transferReference("txf_", "token-a") {
+		t.Errorf("Expected the same token to give the same reference")
+	}
+	if a == b {
+		t.Errorf("Expected different tokens to give different references")
+	}
+	if len(a) < 16 || len(a) > 50 || strings.ToLower(a) != a {
+		t.Errorf("Expected a lowercase reference of 16-50 characters, got %q", a)
+	}
+}
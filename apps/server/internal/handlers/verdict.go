@@ -20,7 +20,7 @@
 // - Verdict system considers multiple factors (income, debt, payment history)
 // - Risk levels (low, medium, high) inform lending decisions
 // - Seeded data includes diverse profiles (approved, review, denied)
-// - All amounts in kobo for consistency with rest of system
+// - Amounts are in the minor unit of the profile's currency (kobo for NGN)
 // - Profiles are looked up by email for customer identification
 package handlers
 
@@ -31,6 +31,7 @@ import (
 	"time"
 
 	"paystack.mpc.proxy/internal/database"
+	"paystack.mpc.proxy/internal/money"
 )
 
 type VerdictHandler struct{}
@@ -41,44 +42,47 @@ func NewVerdictHandler() *VerdictHandler {
 
 // CreditProfile represents a credit profile from the database
 type CreditProfile struct {
-	ID                  int       `json:"id"`
-	Name                string    `json:"name"`
-	Email               string    `json:"email"`
-	Phone               string    `json:"phone"`
-	ProfileType         string    `json:"profile_type"`
-	CreditScore         int       `json:"credit_score"`
-	MonthlyIncome       int       `json:"monthly_income"`
-	TotalDebt           int       `json:"total_debt"`
-	EmploymentStatus    string    `json:"employment_status"`
-	PaymentHistoryScore int       `json:"payment_history_score"`
-	AccountAgeMonths    int       `json:"account_age_months"`
-	Verdict             string    `json:"verdict"`
-	RiskLevel           string    `json:"risk_level"`
-	MaxAffordableAmount int       `json:"max_affordable_amount"`
-	Notes               string    `json:"notes"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
+	ID                  int            `json:"id"`
+	Name                string         `json:"name"`
+	Email               string         `json:"email"`
+	Phone               string         `json:"phone"`
+	ProfileType         string         `json:"profile_type"`
+	CreditScore         int            `json:"credit_score"`
+	MonthlyIncome       int            `json:"monthly_income"`
+	TotalDebt           int            `json:"total_debt"`
+	EmploymentStatus    string         `json:"employment_status"`
+	PaymentHistoryScore int            `json:"payment_history_score"`
+	AccountAgeMonths    int            `json:"account_age_months"`
+	Verdict             string         `json:"verdict"`
+	RiskLevel           string         `json:"risk_level"`
+	MaxAffordableAmount money.Amount   `json:"max_affordable_amount"`
+	Currency            money.Currency `json:"currency"`
+	Notes               string         `json:"notes"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
 }
 
-// AffordabilityCheckRequest represents a request to check affordability
+// AffordabilityCheckRequest represents a request to check affordability.
+// Amount is in the minor unit of the profile's currency.
 type AffordabilityCheckRequest struct {
-	Email  string `json:"email"`
-	Amount int    `json:"amount"`
+	Email  string       `json:"email"`
+	Amount money.Amount `json:"amount"`
 }
 
 // AffordabilityCheckResponse represents the affordability check result
 type AffordabilityCheckResponse struct {
-	CanAfford           bool   `json:"can_afford"`
-	RequestedAmount     int    `json:"requested_amount"`
-	MaxAffordableAmount int    `json:"max_affordable_amount"`
-	Verdict             string `json:"verdict"`
-	RiskLevel           string `json:"risk_level"`
-	Reason              string `json:"reason"`
+	CanAfford           bool           `json:"can_afford"`
+	RequestedAmount     money.Amount   `json:"requested_amount"`
+	MaxAffordableAmount money.Amount   `json:"max_affordable_amount"`
+	Currency            money.Currency `json:"currency"`
+	Verdict             string         `json:"verdict"`
+	RiskLevel           string         `json:"risk_level"`
+	Reason              string         `json:"reason"`
 	ProfileSummary      struct {
-		Name         string `json:"name"`
-		ProfileType  string `json:"profile_type"`
-		CreditScore  int    `json:"credit_score"`
-		MonthlyIncome int   `json:"monthly_income"`
+		Name          string `json:"name"`
+		ProfileType   string `json:"profile_type"`
+		CreditScore   int    `json:"credit_score"`
+		MonthlyIncome int    `json:"monthly_income"`
 	} `json:"profile_summary"`
 }
 
@@ -105,7 +109,7 @@ func (h *VerdictHandler) CheckAffordability(w http.ResponseWriter, r *http.Reque
 	query := `
 		SELECT id, name, email, phone, profile_type, credit_score, monthly_income,
 		       total_debt, employment_status, payment_history_score, account_age_months,
-		       verdict, risk_level, max_affordable_amount, notes, created_at, updated_at
+		       verdict, risk_level, max_affordable_amount, currency, notes, created_at, updated_at
 		FROM credit_profiles
 		WHERE email = ?
 	`
@@ -126,6 +130,7 @@ func (h *VerdictHandler) CheckAffordability(w http.ResponseWriter, r *http.Reque
 		&profile.Verdict,
 		&profile.RiskLevel,
 		&profile.MaxAffordableAmount,
+		&profile.Currency,
 		&profile.Notes,
 		&profile.CreatedAt,
 		&profile.UpdatedAt,
@@ -141,6 +146,7 @@ func (h *VerdictHandler) CheckAffordability(w http.ResponseWriter, r *http.Reque
 		CanAfford:           req.Amount <= profile.MaxAffordableAmount && profile.Verdict != "denied",
 		RequestedAmount:     req.Amount,
 		MaxAffordableAmount: profile.MaxAffordableAmount,
+		Currency:            profile.Currency.OrDefault(),
 		Verdict:             profile.Verdict,
 		RiskLevel:           profile.RiskLevel,
 	}
@@ -154,7 +160,8 @@ func (h *VerdictHandler) CheckAffordability(w http.ResponseWriter, r *http.Reque
 	if profile.Verdict == "denied" {
 		response.Reason = fmt.Sprintf("Profile verdict is denied. %s", profile.Notes)
 	} else if req.Amount > profile.MaxAffordableAmount {
-		response.Reason = fmt.Sprintf("Requested amount (₦%d) exceeds maximum affordable amount (₦%d)", req.Amount/100, profile.MaxAffordableAmount/100)
+		response.Reason = fmt.Sprintf("Requested amount (%s) exceeds maximum affordable amount (%s)",
+			money.New(int64(req.Amount), response.Currency).Format(), money.New(int64(profile.MaxAffordableAmount), response.Currency).Format())
 	} else if profile.Verdict == "review" {
 		response.Reason = fmt.Sprintf("Profile requires manual review. %s", profile.Notes)
 	} else {
@@ -175,7 +182,7 @@ func (h *VerdictHandler) GetFinancialProfile(w http.ResponseWriter, r *http.Requ
 	query := `
 		SELECT id, name, email, phone, profile_type, credit_score, monthly_income,
 		       total_debt, employment_status, payment_history_score, account_age_months,
-		       verdict, risk_level, max_affordable_amount, notes, created_at, updated_at
+		       verdict, risk_level, max_affordable_amount, currency, notes, created_at, updated_at
 		FROM credit_profiles
 		WHERE email = ?
 	`
@@ -196,6 +203,7 @@ func (h *VerdictHandler) GetFinancialProfile(w http.ResponseWriter, r *http.Requ
 		&profile.Verdict,
 		&profile.RiskLevel,
 		&profile.MaxAffordableAmount,
+		&profile.Currency,
 		&profile.Notes,
 		&profile.CreatedAt,
 		&profile.UpdatedAt,
@@ -214,7 +222,7 @@ func (h *VerdictHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
 	query := `
 		SELECT id, name, email, phone, profile_type, credit_score, monthly_income,
 		       total_debt, employment_status, payment_history_score, account_age_months,
-		       verdict, risk_level, max_affordable_amount, notes, created_at, updated_at
+		       verdict, risk_level, max_affordable_amount, currency, notes, created_at, updated_at
 		FROM credit_profiles
 		ORDER BY created_at DESC
 	`
@@ -244,6 +252,7 @@ func (h *VerdictHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
 			&profile.Verdict,
 			&profile.RiskLevel,
 			&profile.MaxAffordableAmount,
+			&profile.Currency,
 			&profile.Notes,
 			&profile.CreatedAt,
 			&profile.UpdatedAt,
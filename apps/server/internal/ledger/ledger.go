@@ -0,0 +1,206 @@
+// Package ledger implements the double-entry rules behind the journal.
+//
+// Every movement of money is a journal entry whose lines debit and credit
+// accounts by equal amounts. Account codes are "<type>:<name>", e.g.
+// "asset:paystack" or "expense:budget:3", so the type of an account is always
+// known from its code. Budget spent amounts and goal progress are balances of
+// their accounts rather than counters kept beside the data.
+//
+// DESIGN DECISIONS:
+// - Amounts are minor units (kobo) and every line is one-sided and positive
+// - Entries are validated before they are written; an unbalanced entry is never stored
+// - Balances are reported in the account's normal direction (debit for assets and expenses, credit otherwise)
+// - The package has no I/O; storage lives with the handlers
+package ledger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Account types
+const (
+	Asset     = "asset"
+	Liability = "liability"
+	Equity    = "equity"
+	Income    = "income"
+	Expense   = "expense"
+)
+
+// Well-known accounts
+const (
+	// AccountPaystack is money held in the Paystack balance
+	AccountPaystack = "asset:paystack"
+	// AccountCards is money spent through virtual cards
+	AccountCards = "asset:cards"
+	// AccountBank is money spent from a bank account outside Paystack, known from imported statements
+	AccountBank = "asset:bank"
+	// AccountPayables is recorded expenses that haven't been paid yet
+	AccountPayables = "liability:payables"
+	// AccountGoalFunding is the other side of money set aside for goals
+	AccountGoalFunding = "equity:goal_funding"
+	// AccountTransfers is transfers not recorded as expenses
+	AccountTransfers = "expense:transfers"
+	// AccountInvoiceIncome is invoices paid by customers
+	AccountInvoiceIncome = "income:invoices"
+)
+
+const (
+	budgetPrefix = "expense:budget:"
+	goalPrefix   = "asset:goal:"
+)
+
+// BudgetAccount is the expense account whose balance is a budget's spent amount
+func BudgetAccount(budgetID int) string {
+	return budgetPrefix + strconv.Itoa(budgetID)
+}
+
+// GoalAccount is the asset account whose balance is a goal's progress
+func GoalAccount(goalID int) string {
+	return goalPrefix + strconv.Itoa(goalID)
+}
+
+// BudgetID returns the budget a budget account belongs to
+func BudgetID(account string) (int, bool) {
+	return idSuffix(account, budgetPrefix)
+}
+
+// GoalID returns the goal a goal account belongs to
+func GoalID(account string) (int, bool) {
+	return idSuffix(account, goalPrefix)
+}
+
+func idSuffix(account, prefix string) (int, bool) {
+	if !strings.HasPrefix(account, prefix) {
+		return 0, false
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(account, prefix))
+	return id, err == nil
+}
+
+// TypeOf returns the account type encoded in an account code
+func TypeOf(account string) string {
+	t, _, _ := strings.Cut(account, ":")
+	return t
+}
+
+// ValidAccount reports whether an account code has a known type and a name
+func ValidAccount(account string) bool {
+	t, name, ok := strings.Cut(account, ":")
+	if !ok || name == "" {
+		return false
+	}
+	switch t {
+	case Asset, Liability, Equity, Income, Expense:
+		return true
+	}
+	return false
+}
+
+// Line is one side of a journal entry
+type Line struct {
+	Account string `json:"account"`
+	Debit   int64  `json:"debit"`
+	Credit  int64  `json:"credit"`
+}
+
+// Entry is a balanced set of lines describing one event
+type Entry struct {
+	Kind        string    `json:"kind"`
+	Reference   string    `json:"reference,omitempty"`
+	Description string    `json:"description,omitempty"`
+	SourceType  string    `json:"source_type"`
+	SourceID    int64     `json:"source_id"`
+	Currency    string    `json:"currency"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	Lines       []Line    `json:"lines"`
+}
+
+// Move returns the two lines that move amount from one account to another:
+// the destination is debited and the source credited.
+func Move(from, to string, amount int64) []Line {
+	return []Line{
+		{Account: to, Debit: amount},
+		{Account: from, Credit: amount},
+	}
+}
+
+// Validate checks an entry is well-formed and balanced
+func (e Entry) Validate() error {
+	if e.Kind == "" || e.SourceType == "" {
+		return fmt.Errorf("entry needs a kind and a source")
+	}
+	if len(e.Lines) < 2 {
+		return fmt.Errorf("entry needs at least two lines")
+	}
+
+	var debits, credits int64
+	for _, l := range e.Lines {
+		if !ValidAccount(l.Account) {
+			return fmt.Errorf("invalid account %q", l.Account)
+		}
+		if l.Debit < 0 || l.Credit < 0 {
+			return fmt.Errorf("line amounts cannot be negative")
+		}
+		if (l.Debit == 0) == (l.Credit == 0) {
+			return fmt.Errorf("each line must be either a debit or a credit")
+		}
+		debits += l.Debit
+		credits += l.Credit
+	}
+
+	if debits != credits {
+		return fmt.Errorf("entry is unbalanced: debits %d, credits %d", debits, credits)
+	}
+	return nil
+}
+
+// NormalBalance is an account's balance in its normal direction
+func NormalBalance(account string, debit, credit int64) int64 {
+	switch TypeOf(account) {
+	case Asset, Expense:
+		return debit - credit
+	default:
+		return credit - debit
+	}
+}
+
+// Balance is an account's totals in a trial balance
+type Balance struct {
+	Account string `json:"account"`
+	Type    string `json:"type"`
+	Debit   int64  `json:"debit"`
+	Credit  int64  `json:"credit"`
+	Balance int64  `json:"balance"`
+}
+
+// TrialBalance totals lines per account. The journal balances when the
+// returned debit and credit totals are equal.
+func TrialBalance(lines []Line) ([]Balance, int64, int64) {
+	byAccount := map[string]*Balance{}
+	var debits, credits int64
+
+	for _, l := range lines {
+		b, ok := byAccount[l.Account]
+		if !ok {
+			b = &Balance{Account: l.Account, Type: TypeOf(l.Account)}
+			byAccount[l.Account] = b
+		}
+		b.Debit += l.Debit
+		b.Credit += l.Credit
+		debits += l.Debit
+		credits += l.Credit
+	}
+
+	balances := make([]Balance, 0, len(byAccount))
+	for _, b := range byAccount {
+		b.Balance = NormalBalance(b.Account, b.Debit, b.Credit)
+		balances = append(balances, *b)
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i].Account < balances[j].Account })
+
+	return balances, debits, credits
+}
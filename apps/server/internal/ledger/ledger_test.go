@@ -0,0 +1,70 @@
+package ledger
+
+import (
+	"testing"
+)
+
+func TestEntryValidate(t *testing.T) {
+	valid := Entry{Kind: "expense_recorded", SourceType: "expense", SourceID: 1, Lines: Move(AccountPayables, BudgetAccount(3), 5000)}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Expected a valid entry, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		lines []Line
+	}{
+		{"single line", []Line{{Account: AccountPaystack, Debit: 100}}},
+		{"unbalanced", []Line{{Account: AccountPaystack, Debit: 100}, {Account: AccountInvoiceIncome, Credit: 90}}},
+		{"two-sided line", []Line{{Account: AccountPaystack, Debit: 100, Credit: 100}, {Account: AccountInvoiceIncome, Credit: 0, Debit: 0}}},
+		{"negative", []Line{{Account: AccountPaystack, Debit: -100}, {Account: AccountInvoiceIncome, Credit: -100}}},
+		{"unknown account type", Move("cash:drawer", AccountPaystack, 100)},
+	}
+	for _, tt := range tests {
+		e := valid
+		e.Lines = tt.lines
+		if err := e.Validate(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestTrialBalance(t *testing.T) {
+	var lines []Line
+	lines = append(lines, Move(AccountPayables, BudgetAccount(1), 5000)...)
+	lines = append(lines, Move(AccountPaystack, AccountPayables, 5000)...)
+	lines = append(lines, Move(AccountInvoiceIncome, AccountPaystack, 20000)...)
+	lines = append(lines, Move(AccountPaystack, BudgetAccount(1), 1000)...)
+
+	balances, debits, credits := TrialBalance(lines)
+	if debits != credits {
+		t.Fatalf("Expected balanced totals, got %d/%d", debits, credits)
+	}
+
+	want := map[string]int64{
+		BudgetAccount(1):     6000,
+		AccountPayables:      0,
+		AccountPaystack:      14000,
+		AccountInvoiceIncome: 20000,
+	}
+	if len(balances) != len(want) {
+		t.Fatalf("Expected %d accounts, got %d", len(want), len(balances))
+	}
+	for _, b := range balances {
+		if b.Balance != want[b.Account] {
+			t.Errorf("%s: expected balance %d, got %d", b.Account, want[b.Account], b.Balance)
+		}
+	}
+}
+
+func TestAccountIDs(t *testing.T) {
+	if id, ok := BudgetID(BudgetAccount(42)); !ok || id != 42 {
+		t.Errorf("Expected budget 42, got %d %v", id, ok)
+	}
+	if id, ok := GoalID(GoalAccount(7)); !ok || id != 7 {
+		t.Errorf("Expected goal 7, got %d %v", id, ok)
+	}
+	if _, ok := BudgetID(GoalAccount(7)); ok {
+		t.Error("Expected a goal account not to parse as a budget")
+	}
+}
@@ -0,0 +1,75 @@
+// Package mcp serves the tool registry over the Model Context Protocol.
+//
+// KEY WORKFLOW:
+// MCP Client → tools/list (registry catalog) → tools/call →
+// Registry Dispatch → Structured Result
+//
+// DESIGN DECISIONS:
+// - Every registry tool is exposed as-is, sharing names and schemas with the HTTP tool catalog
+// - Results carry the API response envelope as structured content plus its JSON encoding as text
+// - Handler rejections (status=false) are reported as tool errors with the full envelope attached
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"paystack.mpc.proxy/internal/tools"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// ServerName is reported to MCP clients during initialization
+	ServerName = "hezra"
+
+	instructions = "Hezra manages payments, expenses, budgets and goals. " +
+		"All amounts are in kobo (NGN * 100). Resolve recipients with search_recipients " +
+		"before sending money or recording expenses, and confirm money-moving actions with the user."
+)
+
+// NewServer creates an MCP server exposing every tool in the registry
+func NewServer(registry *tools.Registry, version string) (*mcpserver.MCPServer, error) {
+	s := mcpserver.NewMCPServer(
+		ServerName,
+		version,
+		mcpserver.WithToolCapabilities(false),
+		mcpserver.WithInstructions(instructions),
+		mcpserver.WithRecovery(),
+	)
+
+	for _, tool := range registry.Tools() {
+		schema, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode schema for %s: %w", tool.Name, err)
+		}
+
+		s.AddTool(
+			mcpgo.NewToolWithRawSchema(tool.Name, tool.Description, schema),
+			callHandler(registry, tool.Name),
+		)
+	}
+
+	return s, nil
+}
+
+// callHandler adapts a registry tool to an MCP tool handler
+func callHandler(registry *tools.Registry, name string) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, req mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
+		result, err := registry.Call(ctx, name, req.GetArguments())
+		if err != nil {
+			return mcpgo.NewToolResultError(err.Error()), nil
+		}
+
+		text, err := json.Marshal(result)
+		if err != nil {
+			return mcpgo.NewToolResultError(fmt.Sprintf("failed to encode result: %v", err)), nil
+		}
+
+		out := mcpgo.NewToolResultStructured(result, string(text))
+		out.IsError = !result.Status
+		return out, nil
+	}
+}
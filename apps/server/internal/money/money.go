@@ -0,0 +1,302 @@
+// Package money is the shared representation of amounts of money.
+//
+// Amounts are always whole minor units (kobo, pesewas, cents) paired with
+// the currency they are in. Arithmetic between two amounts refuses to mix
+// currencies, so a budget in NGN can never silently absorb a payment in USD.
+//
+// DESIGN DECISIONS:
+//   - Money has no JSON methods of its own, so embedding it in a request or
+//     response keeps the flat {"amount": ..., "currency": ...} shape
+//   - Amounts reject fractional minor units at decode time instead of rounding
+//   - An empty currency means the default (NGN), matching Paystack
+//   - Formatting follows the currency's home locale unless one is given
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCurrencyMismatch is returned when combining amounts in different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrUnsupportedCurrency is returned for currency codes we can't handle
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+const (
+	NGN Currency = "NGN"
+	GHS Currency = "GHS"
+	KES Currency = "KES"
+	ZAR Currency = "ZAR"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+)
+
+// Default is the currency assumed when none is given
+const Default = NGN
+
+type currencyInfo struct {
+	symbol   string
+	exponent int
+	locale   string
+}
+
+var currencies = map[Currency]currencyInfo{
+	NGN: {symbol: "₦", exponent: 2, locale: "en-NG"},
+	GHS: {symbol: "GH₵", exponent: 2, locale: "en-GH"},
+	KES: {symbol: "KSh", exponent: 2, locale: "en-KE"},
+	ZAR: {symbol: "R", exponent: 2, locale: "en-ZA"},
+	USD: {symbol: "$", exponent: 2, locale: "en-US"},
+	EUR: {symbol: "€", exponent: 2, locale: "en-IE"},
+	GBP: {symbol: "£", exponent: 2, locale: "en-GB"},
+}
+
+// Currencies lists the supported currencies
+func Currencies() []Currency {
+	return []Currency{NGN, GHS, KES, ZAR, USD, EUR, GBP}
+}
+
+// ParseCurrency normalizes and validates a currency code. An empty code is the default.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if c == "" {
+		return Default, nil
+	}
+	if !c.Valid() {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCurrency, code)
+	}
+	return c, nil
+}
+
+// Valid reports whether the currency is supported
+func (c Currency) Valid() bool {
+	_, ok := currencies[c]
+	return ok
+}
+
+// OrDefault returns the currency, or the default if it is empty
+func (c Currency) OrDefault() Currency {
+	if c == "" {
+		return Default
+	}
+	return c
+}
+
+// Exponent is the number of minor-unit digits (2 for kobo)
+func (c Currency) Exponent() int {
+	if info, ok := currencies[c.OrDefault()]; ok {
+		return info.exponent
+	}
+	return 2
+}
+
+// Symbol is the currency's display symbol
+func (c Currency) Symbol() string {
+	if info, ok := currencies[c.OrDefault()]; ok {
+		return info.symbol
+	}
+	return string(c) + " "
+}
+
+// UnmarshalJSON accepts a currency code in any case and rejects unsupported ones.
+// An empty string stays empty so callers can apply their own default.
+func (c *Currency) UnmarshalJSON(data []byte) error {
+	var code string
+	if err := json.Unmarshal(data, &code); err != nil {
+		return fmt.Errorf("currency must be a string")
+	}
+	if strings.TrimSpace(code) == "" {
+		*c = ""
+		return nil
+	}
+	parsed, err := ParseCurrency(code)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// Amount is a whole number of minor units
+type Amount int64
+
+// UnmarshalJSON accepts a whole number of minor units and rejects fractions
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("amount must be a number of minor units (kobo)")
+	}
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		*a = Amount(i)
+		return nil
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil || f != math.Trunc(f) || math.Abs(f) > math.MaxInt64 {
+		return fmt.Errorf("amount must be a whole number of minor units (kobo), got %s", n)
+	}
+	*a = Amount(f)
+	return nil
+}
+
+// Money is an amount in a currency
+type Money struct {
+	Amount   Amount   `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// New returns amount minor units of currency
+func New(amount int64, currency Currency) Money {
+	return Money{Amount: Amount(amount), Currency: currency.OrDefault()}
+}
+
+// FromMajor converts a major-unit value (e.g. 1500.50 naira) to Money, rounding to the nearest minor unit
+func FromMajor(major float64, currency Currency) Money {
+	scale := math.Pow10(currency.OrDefault().Exponent())
+	return New(int64(math.Round(major*scale)), currency)
+}
+
+// Minor returns the amount in minor units
+func (m Money) Minor() int64 {
+	return int64(m.Amount)
+}
+
+// Major returns the amount in major units, for display and ratios only
+func (m Money) Major() float64 {
+	return float64(m.Amount) / math.Pow10(m.Currency.Exponent())
+}
+
+// Validate checks the currency is supported and the amount is positive
+func (m Money) Validate() error {
+	if !m.Currency.OrDefault().Valid() {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCurrency, m.Currency)
+	}
+	if m.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	return nil
+}
+
+// SameCurrency reports whether two amounts can be combined
+func (m Money) SameCurrency(o Money) bool {
+	return m.Currency.OrDefault() == o.Currency.OrDefault()
+}
+
+func (m Money) mismatch(o Money) error {
+	return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency.OrDefault(), o.Currency.OrDefault())
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, m.mismatch(o)
+	}
+	return New(m.Minor()+o.Minor(), m.Currency), nil
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, m.mismatch(o)
+	}
+	return New(m.Minor()-o.Minor(), m.Currency), nil
+}
+
+// Cmp compares m with o, returning -1, 0 or 1
+func (m Money) Cmp(o Money) (int, error) {
+	if !m.SameCurrency(o) {
+		return 0, m.mismatch(o)
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Format renders the amount for people in the currency's home locale,
+// e.g. ₦1,234.56 or R 1 234,56. Whole amounts drop the minor digits.
+func (m Money) Format() string {
+	info, ok := currencies[m.Currency.OrDefault()]
+	if !ok {
+		return m.FormatLocale("")
+	}
+	return m.FormatLocale(info.locale)
+}
+
+type localeFormat struct {
+	group       string
+	decimal     string
+	symbolAfter bool
+	space       bool
+}
+
+var locales = map[string]localeFormat{
+	"en-NG": {group: ",", decimal: "."},
+	"en-GH": {group: ",", decimal: "."},
+	"en-KE": {group: ",", decimal: "."},
+	"en-US": {group: ",", decimal: "."},
+	"en-GB": {group: ",", decimal: "."},
+	"en-IE": {group: ",", decimal: "."},
+	"en-ZA": {group: " ", decimal: ",", space: true},
+	"fr-FR": {group: " ", decimal: ",", symbolAfter: true, space: true},
+	"de-DE": {group: ".", decimal: ",", symbolAfter: true, space: true},
+}
+
+// FormatLocale renders the amount using a locale's separators and symbol
+// placement. Unknown locales fall back to en-NG conventions.
+func (m Money) FormatLocale(locale string) string {
+	f, ok := locales[locale]
+	if !ok {
+		f = locales["en-NG"]
+	}
+
+	exp := m.Currency.Exponent()
+	minor := m.Minor()
+	negative := minor < 0
+	if negative {
+		minor = -minor
+	}
+	scale := int64(math.Pow10(exp))
+	whole, frac := minor/scale, minor%scale
+
+	digits := strconv.FormatInt(whole, 10)
+	var grouped strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteString(f.group)
+		}
+		grouped.WriteRune(d)
+	}
+	number := grouped.String()
+	if frac != 0 {
+		number += f.decimal + fmt.Sprintf("%0*d", exp, frac)
+	}
+
+	symbol := m.Currency.Symbol()
+	sep := ""
+	if f.space {
+		sep = " "
+	}
+	out := symbol + sep + number
+	if f.symbolAfter {
+		out = number + sep + symbol
+	}
+	if negative {
+		out = "-" + out
+	}
+	return out
+}
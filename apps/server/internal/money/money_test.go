@@ -0,0 +1,94 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		money  Money
+		locale string
+		want   string
+	}{
+		{New(123456789, NGN), "", "₦1,234,567.89"},
+		{New(500000, NGN), "", "₦5,000"},
+		{New(5, NGN), "", "₦0.05"},
+		{New(-250050, NGN), "", "-₦2,500.50"},
+		{New(123456, ZAR), "", "R 1 234,56"},
+		{New(100000, GHS), "", "GH₵1,000"},
+		{New(123456, EUR), "de-DE", "1.234,56 €"},
+		{New(123456, EUR), "fr-FR", "1 234,56 €"},
+		{New(99, USD), "", "$0.99"},
+	}
+	for _, tt := range tests {
+		got := tt.money.Format()
+		if tt.locale != "" {
+			got = tt.money.FormatLocale(tt.locale)
+		}
+		if got != tt.want {
+			t.Errorf("Format(%d %s, %q) = %q, want %q", tt.money.Amount, tt.money.Currency, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestArithmeticRefusesMixedCurrencies(t *testing.T) {
+	naira := New(1000, NGN)
+	dollars := New(1000, USD)
+
+	if _, err := naira.Add(dollars); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Expected a currency mismatch from Add, got %v", err)
+	}
+	if _, err := naira.Cmp(dollars); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Expected a currency mismatch from Cmp, got %v", err)
+	}
+
+	sum, err := naira.Add(New(500, ""))
+	if err != nil || sum.Amount != 1500 || sum.Currency != NGN {
+		t.Errorf("Expected an empty currency to default to NGN, got %+v %v", sum, err)
+	}
+	diff, _ := naira.Sub(New(1500, NGN))
+	if diff.Amount != -500 {
+		t.Errorf("Expected -500, got %d", diff.Amount)
+	}
+}
+
+func TestJSONKeepsFlatShape(t *testing.T) {
+	type request struct {
+		Money
+		Narration string `json:"narration"`
+	}
+
+	var req request
+	if err := json.Unmarshal([]byte(`{"amount":500000,"currency":"ngn","narration":"Fuel"}`), &req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if req.Amount != 500000 || req.Currency != NGN || req.Narration != "Fuel" {
+		t.Errorf("Unexpected decode: %+v", req)
+	}
+
+	out, _ := json.Marshal(req)
+	if string(out) != `{"amount":500000,"currency":"NGN","narration":"Fuel"}` {
+		t.Errorf("Unexpected encode: %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount":1500.5}`), &req); err == nil {
+		t.Error("Expected fractional minor units to be rejected")
+	}
+	if err := json.Unmarshal([]byte(`{"amount":2000.0}`), &req); err != nil || req.Amount != 2000 {
+		t.Errorf("Expected a whole float to be accepted, got %d %v", req.Amount, err)
+	}
+	if err := json.Unmarshal([]byte(`{"amount":100,"currency":"XYZ"}`), &req); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("Expected an unsupported currency error, got %v", err)
+	}
+}
+
+func TestFromMajor(t *testing.T) {
+	if m := FromMajor(1500.505, NGN); m.Amount != 150051 {
+		t.Errorf("Expected 150051 kobo, got %d", m.Amount)
+	}
+	if m := New(150050, NGN); m.Major() != 1500.5 {
+		t.Errorf("Expected 1500.5, got %v", m.Major())
+	}
+}
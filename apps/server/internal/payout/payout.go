@@ -0,0 +1,86 @@
+// Package payout turns a named group of recipients into a batch of payments, so
+// "pay the cleaning team their weekly rate" becomes one payment per cleaner.
+//
+// DESIGN DECISIONS:
+//   - A member's own amount wins over the group's default, so one group can hold
+//     people on different rates
+//   - An amount given with the request wins over both ("pay them ₦5,000 each")
+//   - A batch with any member lacking an amount is refused as a whole rather than
+//     paying only some of the group; the error names who is missing one
+//   - Every payment in a batch is in the group's currency
+package payout
+
+import (
+	"fmt"
+	"strings"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+// Member is a recipient in a group
+type Member struct {
+	RecipientCode string
+	Name          string
+	// Amount is the member's own default, or zero to use the group's
+	Amount money.Amount
+}
+
+// Group is a named set of recipients paid together
+type Group struct {
+	Name     string
+	Currency money.Currency
+	// DefaultAmount is paid to members without their own amount, or zero for none
+	DefaultAmount money.Amount
+	Members       []Member
+}
+
+// Item is one payment in a batch
+type Item struct {
+	RecipientCode string
+	Name          string
+	money.Money
+}
+
+// Expand lists the payment to each member. A non-zero each pays every member that amount.
+func Expand(g Group, each money.Amount) ([]Item, error) {
+	if len(g.Members) == 0 {
+		return nil, fmt.Errorf("group %q has no members", g.Name)
+	}
+	currency := g.Currency.OrDefault()
+
+	items := make([]Item, 0, len(g.Members))
+	var missing []string
+	for _, m := range g.Members {
+		amount := each
+		if amount <= 0 {
+			amount = m.Amount
+		}
+		if amount <= 0 {
+			amount = g.DefaultAmount
+		}
+		if amount <= 0 {
+			missing = append(missing, m.Name)
+			continue
+		}
+		items = append(items, Item{RecipientCode: m.RecipientCode, Name: m.Name, Money: money.New(int64(amount), currency)})
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no amount for %s in group %q: give an amount, or set a default for the group or member", strings.Join(missing, ", "), g.Name)
+	}
+	return items, nil
+}
+
+// Total adds up a batch. Items must share a currency.
+func Total(items []Item) (money.Money, error) {
+	if len(items) == 0 {
+		return money.Money{}, nil
+	}
+	total := money.New(0, items[0].Currency)
+	for _, item := range items {
+		var err error
+		if total, err = total.Add(item.Money); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
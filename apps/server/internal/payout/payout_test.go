@@ -0,0 +1,70 @@
+package payout
+
+import (
+	"strings"
+	"testing"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+var cleaners = Group{
+	Name:          "cleaning team",
+	Currency:      money.NGN,
+	DefaultAmount: 1500000,
+	Members: []Member{
+		{RecipientCode: "RCP_ada", Name: "Ada Obi"},
+		{RecipientCode: "RCP_bola", Name: "Bola Ade", Amount: 2000000},
+	},
+}
+
+func TestExpandUsesMemberThenGroupAmounts(t *testing.T) {
+	items, err := Expand(cleaners, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+	if items[0].Amount != 1500000 || items[1].Amount != 2000000 {
+		t.Errorf("Expected the group default then the member's own amount, got %d and %d", items[0].Amount, items[1].Amount)
+	}
+	if items[0].Currency != money.NGN {
+		t.Errorf("Expected the group's currency, got %s", items[0].Currency)
+	}
+
+	total, err := Total(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != money.New(3500000, money.NGN) {
+		t.Errorf("Total = %+v", total)
+	}
+}
+
+func TestExpandEachOverridesDefaults(t *testing.T) {
+	items, err := Expand(cleaners, 500000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if item.Amount != 500000 {
+			t.Errorf("Expected %s to be paid the given amount, got %d", item.Name, item.Amount)
+		}
+	}
+}
+
+func TestExpandRefusesMembersWithoutAmount(t *testing.T) {
+	g := cleaners
+	g.DefaultAmount = 0
+	_, err := Expand(g, 0)
+	if err == nil || !strings.Contains(err.Error(), "Ada Obi") {
+		t.Errorf("Expected an error naming Ada Obi, got %v", err)
+	}
+	if strings.Contains(err.Error(), "Bola Ade") {
+		t.Errorf("Expected members with an amount not to be named, got %v", err)
+	}
+
+	if _, err := Expand(Group{Name: "empty"}, 100); err == nil {
+		t.Error("Expected an empty group to be refused")
+	}
+}
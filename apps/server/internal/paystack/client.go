@@ -1,24 +1,64 @@
 package paystack
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/borderlesshq/paystack-go"
 )
 
+// apiURL is the Paystack API the SDK calls
+const apiURL = "https://api.paystack.co"
+
 // Client wraps the Paystack SDK client
 type Client struct {
 	*paystack.Client
+	// baseURL, key and httpClient are for calls whose error body matters; the SDK drops it
+	baseURL    string
+	key        string
+	httpClient *http.Client
 }
 
 // NewClient creates a new Paystack client
 func NewClient(apiKey string) *Client {
 	return &Client{
-		Client: paystack.NewClient(apiKey, nil),
+		Client:     paystack.NewClient(apiKey, nil),
+		baseURL:    apiURL,
+		key:        apiKey,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// NewClientAt creates a client that calls baseURL instead of the Paystack API,
+// for a local stand-in such as a test server
+func NewClientAt(apiKey, baseURL string) *Client {
+	target, _ := url.Parse(baseURL)
+	httpClient := &http.Client{Timeout: 60 * time.Second, Transport: redirectTransport{target: target}}
+	return &Client{
+		Client:     paystack.NewClient(apiKey, httpClient),
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		key:        apiKey,
+		httpClient: httpClient,
 	}
 }
 
+// redirectTransport sends requests to target's host; the SDK's own base URL can't be changed
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host, req.Host = t.target.Scheme, t.target.Host, t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
 // SafeCheckBalance wraps CheckBalance with proper error handling
 func (c *Client) SafeCheckBalance() (paystack.Response, error) {
 	// Recover from SDK panic
@@ -56,6 +96,30 @@ func (c *Client) SafeCheckBalance() (paystack.Response, error) {
 	}
 }
 
+// CheckBalances returns the balance for every currency on the integration
+func (c *Client) CheckBalances() ([]map[string]interface{}, error) {
+	resp := paystack.Response{}
+	if err := c.Call("GET", "balance", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	// Array data is not unwrapped by the SDK, so it stays under "data"
+	switch v := resp["data"].(type) {
+	case []interface{}:
+		balances := make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			if balance, ok := item.(map[string]interface{}); ok {
+				balances = append(balances, balance)
+			}
+		}
+		return balances, nil
+	case map[string]interface{}:
+		return []map[string]interface{}{v}, nil
+	default:
+		return nil, fmt.Errorf("invalid response: unexpected data type")
+	}
+}
+
 // PaymentRequest represents a Paystack payment request (invoice)
 type PaymentRequest struct {
 	Customer        string      `json:"customer"`
@@ -124,3 +188,159 @@ func (c *Client) VerifyPaymentRequest(code string) (paystack.Response, error) {
 	// The SDK Call method already unwraps the response and returns just the data field
 	return resp, nil
 }
+
+// GetTransferRecipient fetches a transfer recipient by ID or recipient code
+func (c *Client) GetTransferRecipient(idOrCode string) (paystack.Response, error) {
+	resp := paystack.Response{}
+	err := c.Call("GET", fmt.Sprintf("transferrecipient/%s", idOrCode), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// The SDK Call method already unwraps the response and returns just the data field
+	return resp, nil
+}
+
+// UpdateTransferRecipient renames a transfer recipient. Paystack requires the name
+// on every update; email is optional.
+func (c *Client) UpdateTransferRecipient(idOrCode, name, email string) error {
+	body := map[string]string{"name": name}
+	if email != "" {
+		body["email"] = email
+	}
+	resp := paystack.Response{}
+	return c.Call("PUT", fmt.Sprintf("transferrecipient/%s", url.PathEscape(idOrCode)), body, &resp)
+}
+
+// DeleteTransferRecipient deletes a transfer recipient. Paystack keeps it, inactive,
+// so past transfers still refer to it. A recipient Paystack doesn't know is not an error.
+func (c *Client) DeleteTransferRecipient(idOrCode string) error {
+	resp := paystack.Response{}
+	err := c.Call("DELETE", fmt.Sprintf("transferrecipient/%s", url.PathEscape(idOrCode)), nil, &resp)
+	var apiErr *paystack.APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// ErrAccountNotResolved is returned when the bank has no account with the given number
+var ErrAccountNotResolved = errors.New("account could not be resolved")
+
+// ResolveAccount looks up the name the bank holds for an account number.
+// Paystack's 400 and 422 replies, for an unknown account or bank, come back as ErrAccountNotResolved.
+func (c *Client) ResolveAccount(accountNumber, bankCode string) (paystack.Response, error) {
+	resp, err := c.Bank.ResolveAccountNumber(url.QueryEscape(accountNumber), url.QueryEscape(bankCode))
+	var apiErr *paystack.APIError
+	if errors.As(err, &apiErr) &&
+		(apiErr.HTTPStatusCode == http.StatusBadRequest || apiErr.HTTPStatusCode == http.StatusUnprocessableEntity) {
+		if apiErr.Details.Message != "" {
+			return nil, fmt.Errorf("%w: %s", ErrAccountNotResolved, apiErr.Details.Message)
+		}
+		return nil, ErrAccountNotResolved
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VerifyTransfer looks a transfer up by the reference it was initiated with.
+// It returns nil and no error only when Paystack says it has no transfer with that
+// reference: a 404, or a 400 whose message says the transfer wasn't found, which is
+// how Paystack answers an unknown reference. Any other failure is an error, since
+// the transfer may exist. The SDK is bypassed because it discards the error message.
+func (c *Client) VerifyTransfer(reference string) (*paystack.Transfer, error) {
+	req, err := http.NewRequest("GET", c.baseURL+"/transfer/verify/"+url.PathEscape(reference), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.key)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Status  bool            `json:"status"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode transfer verification (HTTP %d): %w", resp.StatusCode, err)
+	}
+
+	notFound := strings.Contains(strings.ToLower(body.Message), "not found")
+	if resp.StatusCode == http.StatusNotFound || (resp.StatusCode == http.StatusBadRequest && notFound) {
+		return nil, nil
+	}
+	if resp.StatusCode >= 400 || !body.Status {
+		return nil, fmt.Errorf("transfer verification failed (HTTP %d): %s", resp.StatusCode, body.Message)
+	}
+
+	transfer := &paystack.Transfer{}
+	if err := json.Unmarshal(body.Data, transfer); err != nil {
+		return nil, fmt.Errorf("failed to decode transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+// ListTransactions fetches one page of transactions created between from and to.
+// Zero times are omitted, so Paystack applies no bound on that side.
+func (c *Client) ListTransactions(page, perPage int, from, to time.Time) (*paystack.TransactionList, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("perPage", strconv.Itoa(perPage))
+	if !from.IsZero() {
+		query.Set("from", from.UTC().Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.UTC().Format(time.RFC3339))
+	}
+
+	list := &paystack.TransactionList{}
+	if err := c.Call("GET", "transaction?"+query.Encode(), nil, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListPage fetches one page of a Paystack list endpoint (e.g. "transfer",
+// "paymentrequest", "transferrecipient") created between from and to, and
+// returns the items with the total page count.
+func (c *Client) ListPage(resource string, page, perPage int, from, to time.Time) ([]map[string]interface{}, int, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("perPage", strconv.Itoa(perPage))
+	if !from.IsZero() {
+		query.Set("from", from.UTC().Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.UTC().Format(time.RFC3339))
+	}
+
+	resp := paystack.Response{}
+	if err := c.Call("GET", resource+"?"+query.Encode(), nil, &resp); err != nil {
+		return nil, 0, err
+	}
+
+	// Array data is not unwrapped by the SDK, so it stays under "data"
+	var items []map[string]interface{}
+	if data, ok := resp["data"].([]interface{}); ok {
+		for _, item := range data {
+			if m, ok := item.(map[string]interface{}); ok {
+				items = append(items, m)
+			}
+		}
+	}
+
+	pageCount := 0
+	if meta, ok := resp["meta"].(map[string]interface{}); ok {
+		if n, ok := meta["pageCount"].(float64); ok {
+			pageCount = int(n)
+		}
+	}
+	return items, pageCount, nil
+}
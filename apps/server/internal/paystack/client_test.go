@@ -0,0 +1,51 @@
+package paystack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVerifyTransfer(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantCode string
+		wantErr  bool
+	}{
+		{"found", http.StatusOK, `{"status":true,"message":"Transfer retrieved","data":{"transfer_code":"TRF_1","status":"success","amount":500000}}`, "TRF_1", false},
+		{"unknown reference", http.StatusBadRequest, `{"status":false,"message":"Transfer not found"}`, "", false},
+		{"not found status", http.StatusNotFound, `{"status":false,"message":"Not found"}`, "", false},
+		{"other bad request", http.StatusBadRequest, `{"status":false,"message":"Invalid key"}`, "", true},
+		{"server error", http.StatusInternalServerError, `{"status":false,"message":"Something went wrong"}`, "", true},
+		{"not json", http.StatusBadGateway, `<html>bad gateway</html>`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/transfer/verify/ref_1" {
+					t.Errorf("Unexpected path %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient("sk_test")
+			client.baseURL = server.URL
+
+			transfer, err := client.VerifyTransfer("ref_1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyTransfer error = %v, wantErr %v", err, tt.wantErr)
+			}
+			switch {
+			case tt.wantCode == "" && transfer != nil:
+				t.Errorf("Expected no transfer, got %+v", transfer)
+			case tt.wantCode != "" && (transfer == nil || transfer.TransferCode != tt.wantCode):
+				t.Errorf("Expected transfer %s, got %+v", tt.wantCode, transfer)
+			}
+		})
+	}
+}
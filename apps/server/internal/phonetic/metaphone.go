@@ -0,0 +1,540 @@
+// Package phonetic encodes words by how they sound, so names that a transcript
+// spells differently ("Jon" and "John", "Smyth" and "Smith") encode the same.
+//
+// DoubleMetaphone follows Lawrence Philips' Double Metaphone: a primary code for
+// the most likely pronunciation and an alternate for a common second one (often
+// a non-English reading), each at most four characters. "0" stands for "th".
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// codeLength is the longest code produced
+const codeLength = 4
+
+const vowels = "AEIOUY"
+
+// DoubleMetaphone returns the primary and alternate codes for word. Non-letters are ignored.
+func DoubleMetaphone(word string) (primary, alternate string) {
+	var letters []rune
+	for _, r := range strings.ToUpper(strings.TrimSpace(word)) {
+		if unicode.IsLetter(r) || r == ' ' {
+			letters = append(letters, r)
+		}
+	}
+	if len(letters) == 0 {
+		return "", ""
+	}
+
+	e := &encoder{value: letters, slavoGermanic: isSlavoGermanic(string(letters))}
+	e.encode()
+	return e.primary.String(), e.alternate.String()
+}
+
+// isSlavoGermanic reports spellings that change how some letters are read
+func isSlavoGermanic(s string) bool {
+	return strings.ContainsAny(s, "WK") || strings.Contains(s, "CZ") || strings.Contains(s, "WITZ")
+}
+
+type encoder struct {
+	value              []rune
+	slavoGermanic      bool
+	primary, alternate strings.Builder
+}
+
+// at returns the letter at i, or 0 outside the word
+func (e *encoder) at(i int) rune {
+	if i < 0 || i >= len(e.value) {
+		return 0
+	}
+	return e.value[i]
+}
+
+// has reports whether the letters from start, length long, are any of options
+func (e *encoder) has(start, length int, options ...string) bool {
+	if start < 0 || start+length > len(e.value) {
+		return false
+	}
+	s := string(e.value[start : start+length])
+	for _, o := range options {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *encoder) isVowel(i int) bool {
+	r := e.at(i)
+	return r != 0 && strings.ContainsRune(vowels, r)
+}
+
+func (e *encoder) last() int {
+	return len(e.value) - 1
+}
+
+func (e *encoder) complete() bool {
+	return e.primary.Len() >= codeLength && e.alternate.Len() >= codeLength
+}
+
+// add appends to both codes
+func (e *encoder) add(s string) {
+	e.addBoth(s, s)
+}
+
+// addBoth appends primary to the primary code and alternate to the alternate
+func (e *encoder) addBoth(primary, alternate string) {
+	appendUpTo(&e.primary, primary)
+	appendUpTo(&e.alternate, alternate)
+}
+
+func appendUpTo(b *strings.Builder, s string) {
+	if room := codeLength - b.Len(); room > 0 {
+		if len(s) > room {
+			s = s[:room]
+		}
+		b.WriteString(s)
+	}
+}
+
+func (e *encoder) encode() {
+	i := 0
+	if e.has(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		i = 1
+	}
+	if e.at(0) == 'X' {
+		// "Xavier" starts with an S sound
+		e.add("S")
+		i = 1
+	}
+
+	for !e.complete() && i <= e.last() {
+		switch r := e.at(i); r {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if i == 0 {
+				e.add("A")
+			}
+			i++
+		case 'B':
+			e.add("P")
+			i = e.skip(i, "B")
+		case 'Ç':
+			e.add("S")
+			i++
+		case 'C':
+			i = e.c(i)
+		case 'D':
+			i = e.d(i)
+		case 'F':
+			e.add("F")
+			i = e.skip(i, "F")
+		case 'G':
+			i = e.g(i)
+		case 'H':
+			if (i == 0 || e.isVowel(i-1)) && e.isVowel(i+1) {
+				e.add("H")
+				i += 2
+			} else {
+				i++
+			}
+		case 'J':
+			i = e.j(i)
+		case 'K':
+			e.add("K")
+			i = e.skip(i, "K")
+		case 'L':
+			i = e.l(i)
+		case 'M':
+			e.add("M")
+			if e.at(i+1) == 'M' || (e.has(i-1, 3, "UMB") && (i+1 == e.last() || e.has(i+2, 2, "ER"))) {
+				i += 2
+			} else {
+				i++
+			}
+		case 'N':
+			e.add("N")
+			i = e.skip(i, "N")
+		case 'Ñ':
+			e.add("N")
+			i++
+		case 'P':
+			if e.at(i+1) == 'H' {
+				e.add("F")
+				i += 2
+			} else {
+				e.add("P")
+				i = e.skip(i, "P", "B")
+			}
+		case 'Q':
+			e.add("K")
+			i = e.skip(i, "Q")
+		case 'R':
+			if i == e.last() && !e.slavoGermanic && e.has(i-2, 2, "IE") && !e.has(i-4, 2, "ME", "MA") {
+				// French endings like "Rogier" drop the R
+				e.addBoth("", "R")
+			} else {
+				e.add("R")
+			}
+			i = e.skip(i, "R")
+		case 'S':
+			i = e.s(i)
+		case 'T':
+			i = e.t(i)
+		case 'V':
+			e.add("F")
+			i = e.skip(i, "V")
+		case 'W':
+			i = e.w(i)
+		case 'X':
+			if !(i == e.last() && (e.has(i-3, 3, "IAU", "EAU") || e.has(i-2, 2, "AU", "OU"))) {
+				e.add("KS")
+			}
+			i = e.skip(i, "C", "X")
+		case 'Z':
+			i = e.z(i)
+		default:
+			i++
+		}
+	}
+}
+
+// skip moves past the letter at i, and the next one too if it is one of doubles
+func (e *encoder) skip(i int, doubles ...string) int {
+	if e.has(i+1, 1, doubles...) {
+		return i + 2
+	}
+	return i + 1
+}
+
+func (e *encoder) c(i int) int {
+	switch {
+	case e.germanicCH(i):
+		e.add("K")
+		return i + 2
+	case i == 0 && e.has(i, 6, "CAESAR"):
+		e.add("S")
+		return i + 2
+	case e.has(i, 2, "CH"):
+		return e.ch(i)
+	case e.has(i, 2, "CZ") && !e.has(i-2, 4, "WICZ"):
+		e.addBoth("S", "X")
+		return i + 2
+	case e.has(i+1, 3, "CIA"):
+		e.add("X")
+		return i + 3
+	case e.has(i, 2, "CC") && !(i == 1 && e.at(0) == 'M'):
+		if e.has(i+2, 1, "I", "E", "H") && !e.has(i+2, 2, "HU") {
+			if (i == 1 && e.at(i-1) == 'A') || e.has(i-1, 5, "UCCEE", "UCCES") {
+				e.add("KS")
+			} else {
+				e.add("X")
+			}
+			return i + 3
+		}
+		e.add("K")
+		return i + 2
+	case e.has(i, 2, "CK", "CG", "CQ"):
+		e.add("K")
+		return i + 2
+	case e.has(i, 2, "CI", "CE", "CY"):
+		if e.has(i, 3, "CIO", "CIE", "CIA") {
+			e.addBoth("S", "X")
+		} else {
+			e.add("S")
+		}
+		return i + 2
+	}
+
+	e.add("K")
+	switch {
+	case e.has(i+1, 2, " C", " Q", " G"):
+		return i + 3
+	case e.has(i+1, 1, "C", "K", "Q") && !e.has(i+1, 2, "CE", "CI"):
+		return i + 2
+	}
+	return i + 1
+}
+
+// germanicCH reports a "ch" read as K, as in "Bacher" or "Chianti"
+func (e *encoder) germanicCH(i int) bool {
+	if e.has(i, 4, "CHIA") {
+		return true
+	}
+	if i <= 1 || e.isVowel(i-2) || !e.has(i-1, 3, "ACH") {
+		return false
+	}
+	next := e.at(i + 2)
+	return (next != 'I' && next != 'E') || e.has(i-2, 6, "BACHER", "MACHER")
+}
+
+func (e *encoder) ch(i int) int {
+	switch {
+	case i > 0 && e.has(i, 4, "CHAE"):
+		e.addBoth("K", "X")
+	case e.greekCH(i) || e.hardCH(i):
+		e.add("K")
+	case i > 0:
+		if e.has(0, 2, "MC") {
+			e.add("K")
+		} else {
+			e.addBoth("X", "K")
+		}
+	default:
+		e.add("X")
+	}
+	return i + 2
+}
+
+// greekCH reports an initial "ch" read as K, as in "Character" or "Chemistry"
+func (e *encoder) greekCH(i int) bool {
+	if i != 0 {
+		return false
+	}
+	if !e.has(i+1, 5, "HARAC", "HARIS") && !e.has(i+1, 3, "HOR", "HYM", "HIA", "HEM") {
+		return false
+	}
+	return !e.has(0, 5, "CHORE")
+}
+
+// hardCH reports a "ch" read as K in Germanic names and before some consonants
+func (e *encoder) hardCH(i int) bool {
+	return e.has(0, 4, "VAN ", "VON ") || e.has(0, 3, "SCH") ||
+		e.has(i-2, 6, "ORCHES", "ARCHIT", "ORCHID") || e.has(i+2, 1, "T", "S") ||
+		((e.has(i-1, 1, "A", "O", "U", "E") || i == 0) &&
+			(e.has(i+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || i+1 == e.last()))
+}
+
+func (e *encoder) d(i int) int {
+	switch {
+	case e.has(i, 2, "DG"):
+		if e.has(i+2, 1, "I", "E", "Y") {
+			// "Edge"
+			e.add("J")
+			return i + 3
+		}
+		e.add("TK")
+		return i + 2
+	case e.has(i, 2, "DT", "DD"):
+		e.add("T")
+		return i + 2
+	}
+	e.add("T")
+	return i + 1
+}
+
+func (e *encoder) g(i int) int {
+	switch next := e.at(i + 1); {
+	case next == 'H':
+		return e.gh(i)
+	case next == 'N':
+		switch {
+		case i == 1 && e.isVowel(0) && !e.slavoGermanic:
+			e.addBoth("KN", "N")
+		case !e.has(i+2, 2, "EY") && !e.slavoGermanic:
+			e.addBoth("N", "KN")
+		default:
+			e.add("KN")
+		}
+		return i + 2
+	case e.has(i+1, 2, "LI") && !e.slavoGermanic:
+		e.addBoth("KL", "L")
+		return i + 2
+	case i == 0 && (next == 'Y' || e.has(i+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		e.addBoth("K", "J")
+		return i + 2
+	case (e.has(i+1, 2, "ER") || next == 'Y') && !e.has(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!e.has(i-1, 1, "E", "I") && !e.has(i-1, 3, "RGY", "OGY"):
+		e.addBoth("K", "J")
+		return i + 2
+	case e.has(i+1, 1, "E", "I", "Y") || e.has(i-1, 4, "AGGI", "OGGI"):
+		switch {
+		case e.has(0, 4, "VAN ", "VON ") || e.has(0, 3, "SCH") || e.has(i+1, 2, "ET"):
+			e.add("K")
+		case e.has(i+1, 3, "IER"):
+			e.add("J")
+		default:
+			e.addBoth("J", "K")
+		}
+		return i + 2
+	case next == 'G':
+		e.add("K")
+		return i + 2
+	}
+	e.add("K")
+	return i + 1
+}
+
+func (e *encoder) gh(i int) int {
+	switch {
+	case i > 0 && !e.isVowel(i-1):
+		e.add("K")
+	case i == 0:
+		if e.at(i+2) == 'I' {
+			e.add("J")
+		} else {
+			e.add("K")
+		}
+	case (i > 1 && e.has(i-2, 1, "B", "H", "D")) || (i > 2 && e.has(i-3, 1, "B", "H", "D")) || (i > 3 && e.has(i-4, 1, "B", "H")):
+		// Silent, as in "Hugh" or "bough"
+	default:
+		if i > 2 && e.at(i-1) == 'U' && e.has(i-3, 1, "C", "G", "L", "R", "T") {
+			// "Laugh", "tough"
+			e.add("F")
+		} else if i > 0 && e.at(i-1) != 'I' {
+			e.add("K")
+		}
+	}
+	return i + 2
+}
+
+func (e *encoder) j(i int) int {
+	if e.has(i, 4, "JOSE") || e.has(0, 4, "SAN ") {
+		if (i == 0 && e.at(i+4) == ' ') || len(e.value) == 4 || e.has(0, 4, "SAN ") {
+			e.add("H")
+		} else {
+			e.addBoth("J", "H")
+		}
+		return i + 1
+	}
+
+	switch {
+	case i == 0:
+		e.addBoth("J", "A")
+	case e.isVowel(i-1) && !e.slavoGermanic && (e.at(i+1) == 'A' || e.at(i+1) == 'O'):
+		e.addBoth("J", "H")
+	case i == e.last():
+		e.addBoth("J", "")
+	case !e.has(i+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !e.has(i-1, 1, "S", "K", "L"):
+		e.add("J")
+	}
+	return e.skip(i, "J")
+}
+
+func (e *encoder) l(i int) int {
+	if e.at(i+1) != 'L' {
+		e.add("L")
+		return i + 1
+	}
+	// Spanish "ll", as in "Cabrillo", is read as a Y
+	spanish := (i == len(e.value)-3 && e.has(i-1, 4, "ILLO", "ILLA", "ALLE")) ||
+		((e.has(len(e.value)-2, 2, "AS", "OS") || e.has(e.last(), 1, "A", "O")) && e.has(i-1, 4, "ALLE"))
+	if spanish {
+		e.addBoth("L", "")
+	} else {
+		e.add("L")
+	}
+	return i + 2
+}
+
+func (e *encoder) s(i int) int {
+	switch {
+	case e.has(i-1, 3, "ISL", "YSL"):
+		// Silent, as in "island"
+		return i + 1
+	case i == 0 && e.has(i, 5, "SUGAR"):
+		e.addBoth("X", "S")
+		return i + 1
+	case e.has(i, 2, "SH"):
+		if e.has(i+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			e.add("S")
+		} else {
+			e.add("X")
+		}
+		return i + 2
+	case e.has(i, 3, "SIO", "SIA") || e.has(i, 4, "SIAN"):
+		if e.slavoGermanic {
+			e.add("S")
+		} else {
+			e.addBoth("S", "X")
+		}
+		return i + 3
+	case (i == 0 && e.has(i+1, 1, "M", "N", "L", "W")) || e.has(i+1, 1, "Z"):
+		e.addBoth("S", "X")
+		return e.skip(i, "Z")
+	case e.has(i, 2, "SC"):
+		return e.sc(i)
+	}
+
+	if i == e.last() && e.has(i-2, 2, "AI", "OI") {
+		// French endings like "Dubois" drop the S
+		e.addBoth("", "S")
+	} else {
+		e.add("S")
+	}
+	return e.skip(i, "S", "Z")
+}
+
+func (e *encoder) sc(i int) int {
+	switch {
+	case e.at(i+2) == 'H':
+		switch {
+		case e.has(i+3, 2, "ER", "EN"):
+			e.addBoth("X", "SK")
+		case e.has(i+3, 2, "OO", "UY", "ED", "EM"):
+			e.add("SK")
+		case i == 0 && !e.isVowel(3) && e.at(3) != 'W':
+			e.addBoth("X", "S")
+		default:
+			e.add("X")
+		}
+	case e.has(i+2, 1, "I", "E", "Y"):
+		e.add("S")
+	default:
+		e.add("SK")
+	}
+	return i + 3
+}
+
+func (e *encoder) t(i int) int {
+	switch {
+	case e.has(i, 4, "TION"), e.has(i, 3, "TIA", "TCH"):
+		e.add("X")
+		return i + 3
+	case e.has(i, 2, "TH"), e.has(i, 3, "TTH"):
+		if e.has(i+2, 2, "OM", "AM") || e.has(0, 4, "VAN ", "VON ") || e.has(0, 3, "SCH") {
+			e.add("T")
+		} else {
+			e.addBoth("0", "T")
+		}
+		return i + 2
+	}
+	e.add("T")
+	return e.skip(i, "T", "D")
+}
+
+func (e *encoder) w(i int) int {
+	switch {
+	case e.has(i, 2, "WR"):
+		e.add("R")
+		return i + 2
+	case i == 0 && (e.isVowel(i+1) || e.has(i, 2, "WH")):
+		if e.isVowel(i + 1) {
+			e.addBoth("A", "F")
+		} else {
+			e.add("A")
+		}
+	case (i == e.last() && e.isVowel(i-1)) || e.has(i-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || e.has(0, 3, "SCH"):
+		// Polish "w" is read as a V
+		e.addBoth("", "F")
+	case e.has(i, 4, "WICZ", "WITZ"):
+		e.addBoth("TS", "FX")
+		return i + 4
+	}
+	return i + 1
+}
+
+func (e *encoder) z(i int) int {
+	if e.at(i+1) == 'H' {
+		// Chinese "Zhao"
+		e.add("J")
+		return i + 2
+	}
+	if e.has(i+1, 2, "ZO", "ZI", "ZA") || (e.slavoGermanic && i > 0 && e.at(i-1) != 'T') {
+		e.addBoth("S", "TS")
+	} else {
+		e.add("S")
+	}
+	return e.skip(i, "Z")
+}
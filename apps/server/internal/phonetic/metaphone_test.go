@@ -0,0 +1,40 @@
+package phonetic
+
+import "testing"
+
+func TestDoubleMetaphone(t *testing.T) {
+	tests := []struct {
+		word               string
+		primary, alternate string
+	}{
+		{"John", "JN", "AN"},
+		{"Jon", "JN", "AN"},
+		{"Smith", "SM0", "XMT"},
+		{"Smyth", "SM0", "XMT"},
+		{"Schmidt", "XMT", "SMT"},
+		{"Philip", "FLP", "FLP"},
+		{"Filip", "FLP", "FLP"},
+		{"Catherine", "K0RN", "KTRN"},
+		{"Kathryn", "K0RN", "KTRN"},
+		{"Knight", "NT", "NT"},
+		{"Doe", "T", "T"},
+		{"Doh", "T", "T"},
+		{"Adebayo", "ATP", "ATP"},
+		{"Xavier", "SF", "SFR"},
+		{"o'neil", "ANL", "ANL"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		primary, alternate := DoubleMetaphone(tt.word)
+		if primary != tt.primary || alternate != tt.alternate {
+			t.Errorf("DoubleMetaphone(%q) = %q, %q, want %q, %q", tt.word, primary, alternate, tt.primary, tt.alternate)
+		}
+	}
+}
+
+func TestDoubleMetaphoneCapsLength(t *testing.T) {
+	primary, alternate := DoubleMetaphone("Oluwaseun")
+	if len(primary) > codeLength || len(alternate) > codeLength {
+		t.Errorf("Expected codes of at most %d characters, got %q, %q", codeLength, primary, alternate)
+	}
+}
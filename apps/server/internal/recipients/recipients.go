@@ -0,0 +1,150 @@
+// Package recipients keeps the local recipient cache in step with Paystack.
+//
+// A recipient is identified by its bank account: the same bank code and account
+// number is the same person however many recipient codes have been issued for it.
+// Plan compares a Paystack listing with the local cache and says what to import,
+// refresh, deactivate or record as a duplicate. It has no I/O; loading recipients
+// and applying changes is left to the caller.
+//
+// DESIGN DECISIONS:
+// - Account numbers are compared by their digits, so "0123 456 789" and "0123456789" match
+// - When two codes share an account the cached one is kept and the other recorded as its duplicate
+// - Local recipients missing from the listing are left alone; the default service provider recipient only exists locally
+// - Recipients deleted locally stay deleted; deletions made in Paystack are copied locally
+package recipients
+
+import "strings"
+
+// Change kinds
+const (
+	// Import adds a recipient created outside this service
+	Import = "import"
+	// Refresh updates a cached recipient whose details changed in Paystack
+	Refresh = "refresh"
+	// Deactivate soft-deletes a cached recipient that was deleted in Paystack
+	Deactivate = "deactivate"
+	// Duplicate records a new code for an account that is already cached
+	Duplicate = "duplicate"
+)
+
+// Recipient is a transfer recipient reduced to the fields that are synced
+type Recipient struct {
+	Code          string
+	Type          string
+	Name          string
+	AccountNumber string
+	BankCode      string
+	BankName      string
+	Currency      string
+	// Active is false once the recipient has been deleted
+	Active bool
+}
+
+// Change is one thing to do to the local cache
+type Change struct {
+	Kind   string
+	Remote Recipient
+	// DuplicateOf is the cached recipient a Duplicate stands for
+	DuplicateOf string
+}
+
+// AccountKey identifies a bank account, e.g. "058:0123456789".
+// It is empty when the account number has no digits, e.g. for mobile money recipients.
+func AccountKey(bankCode, accountNumber string) string {
+	var digits strings.Builder
+	for _, r := range accountNumber {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	if digits.Len() == 0 {
+		return ""
+	}
+	return strings.TrimSpace(bankCode) + ":" + digits.String()
+}
+
+// FromPaystack reads a recipient from a Paystack transferrecipient object
+func FromPaystack(item map[string]interface{}) Recipient {
+	str := func(m map[string]interface{}, key string) string {
+		s, _ := m[key].(string)
+		return s
+	}
+	details, _ := item["details"].(map[string]interface{})
+
+	active := true
+	if a, ok := item["active"].(bool); ok && !a {
+		active = false
+	}
+	if deleted, _ := item["is_deleted"].(bool); deleted {
+		active = false
+	}
+
+	return Recipient{
+		Code:          str(item, "recipient_code"),
+		Type:          str(item, "type"),
+		Name:          str(item, "name"),
+		AccountNumber: str(details, "account_number"),
+		BankCode:      str(details, "bank_code"),
+		BankName:      str(details, "bank_name"),
+		Currency:      str(item, "currency"),
+		Active:        active,
+	}
+}
+
+// Plan works out the changes that bring the local cache in line with a Paystack listing
+func Plan(local, remote []Recipient) []Change {
+	byCode := make(map[string]Recipient, len(local))
+	byAccount := map[string]string{}
+	for _, l := range local {
+		byCode[l.Code] = l
+		if key := AccountKey(l.BankCode, l.AccountNumber); key != "" && l.Active {
+			if _, taken := byAccount[key]; !taken {
+				byAccount[key] = l.Code
+			}
+		}
+	}
+
+	var changes []Change
+	for _, r := range remote {
+		if r.Code == "" {
+			continue
+		}
+
+		if l, cached := byCode[r.Code]; cached {
+			switch {
+			case !l.Active:
+				// Deleted here; the sync doesn't bring it back
+			case !r.Active:
+				changes = append(changes, Change{Kind: Deactivate, Remote: r})
+			case changed(l, r):
+				changes = append(changes, Change{Kind: Refresh, Remote: r})
+			}
+			continue
+		}
+
+		if !r.Active {
+			continue
+		}
+		key := AccountKey(r.BankCode, r.AccountNumber)
+		if kept, ok := byAccount[key]; ok && key != "" {
+			changes = append(changes, Change{Kind: Duplicate, Remote: r, DuplicateOf: kept})
+			continue
+		}
+		if key != "" {
+			byAccount[key] = r.Code
+		}
+		byCode[r.Code] = r
+		changes = append(changes, Change{Kind: Import, Remote: r})
+	}
+	return changes
+}
+
+// changed reports whether Paystack holds details the cache doesn't.
+// Blank remote fields are treated as unknown rather than cleared.
+func changed(local, remote Recipient) bool {
+	differs := func(l, r string) bool { return r != "" && l != r }
+	return differs(local.Name, remote.Name) ||
+		differs(local.BankName, remote.BankName) ||
+		differs(local.Type, remote.Type) ||
+		differs(local.Currency, remote.Currency)
+}
@@ -0,0 +1,86 @@
+package recipients
+
+import "testing"
+
+func TestAccountKeyComparesDigits(t *testing.T) {
+	if AccountKey("058", "0123 456-789") != AccountKey(" 058", "0123456789") {
+		t.Error("Expected formatting differences in the account number to be ignored")
+	}
+	if AccountKey("058", "0123456789") == AccountKey("044", "0123456789") {
+		t.Error("Expected the same account number at another bank to be a different account")
+	}
+	if AccountKey("MTN", "") != "" {
+		t.Error("Expected no key without an account number")
+	}
+}
+
+func TestFromPaystack(t *testing.T) {
+	r := FromPaystack(map[string]interface{}{
+		"recipient_code": "RCP_1",
+		"type":           "nuban",
+		"name":           "Ada Obi",
+		"currency":       "NGN",
+		"active":         true,
+		"is_deleted":     false,
+		"details": map[string]interface{}{
+			"account_number": "0123456789",
+			"bank_code":      "058",
+			"bank_name":      "Guaranty Trust Bank",
+		},
+	})
+	want := Recipient{Code: "RCP_1", Type: "nuban", Name: "Ada Obi", AccountNumber: "0123456789", BankCode: "058", BankName: "Guaranty Trust Bank", Currency: "NGN", Active: true}
+	if r != want {
+		t.Errorf("FromPaystack = %+v, want %+v", r, want)
+	}
+
+	if FromPaystack(map[string]interface{}{"recipient_code": "RCP_2", "is_deleted": true}).Active {
+		t.Error("Expected a deleted recipient to be inactive")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	ada := Recipient{Code: "RCP_ada", Type: "nuban", Name: "Ada Obi", AccountNumber: "0123456789", BankCode: "058", BankName: "GTBank", Currency: "NGN", Active: true}
+	bola := Recipient{Code: "RCP_bola", Type: "nuban", Name: "Bola Ade", AccountNumber: "1111111111", BankCode: "044", Currency: "NGN", Active: true}
+	gone := Recipient{Code: "RCP_gone", Type: "nuban", Name: "Old Tenant", AccountNumber: "2222222222", BankCode: "044", Currency: "NGN"}
+	local := []Recipient{ada, bola, gone}
+
+	renamed := ada
+	renamed.Name = "Adaeze Obi"
+	deleted := bola
+	deleted.Active = false
+	revived := gone
+	revived.Active = true
+	newer := ada
+	newer.Code = "RCP_ada2"
+	chidi := Recipient{Code: "RCP_chidi", Type: "nuban", Name: "Chidi Eze", AccountNumber: "3333333333", BankCode: "058", Currency: "NGN", Active: true}
+	chidiAgain := chidi
+	chidiAgain.Code = "RCP_chidi2"
+	removed := Recipient{Code: "RCP_removed", AccountNumber: "4444444444", BankCode: "058"}
+
+	changes := Plan(local, []Recipient{renamed, deleted, revived, newer, chidi, chidiAgain, removed})
+
+	want := []Change{
+		{Kind: Refresh, Remote: renamed},
+		{Kind: Deactivate, Remote: deleted},
+		{Kind: Duplicate, Remote: newer, DuplicateOf: "RCP_ada"},
+		{Kind: Import, Remote: chidi},
+		{Kind: Duplicate, Remote: chidiAgain, DuplicateOf: "RCP_chidi"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %d: %+v", len(want), len(changes), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestPlanIgnoresBlankRemoteFields(t *testing.T) {
+	local := Recipient{Code: "RCP_1", Type: "nuban", Name: "Ada Obi", AccountNumber: "0123456789", BankCode: "058", BankName: "GTBank", Currency: "NGN", Active: true}
+	remote := local
+	remote.BankName = ""
+	if changes := Plan([]Recipient{local}, []Recipient{remote}); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+}
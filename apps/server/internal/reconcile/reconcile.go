@@ -0,0 +1,166 @@
+// Package reconcile compares local records with their Paystack counterparts.
+//
+// Local tables are updated best-effort after Paystack calls, so they drift.
+// Compare matches records by key and reports what is missing on either side
+// and where amounts or statuses disagree. It has no I/O; loading records and
+// applying fixes is left to the caller.
+//
+// DESIGN DECISIONS:
+// - Each source supplies Rules, so status vocabularies and safe fixes stay next to the data they describe
+// - A discrepancy carries the fix action to apply, or none when it needs a human
+// - Amount mismatches are never auto-fixed; money amounts are only changed by people
+// - Records only expected remotely when the remote listing was complete, to avoid false "missing" reports
+package reconcile
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Discrepancy types
+const (
+	MissingRemote    = "missing_remote"
+	MissingLocal     = "missing_local"
+	AmountMismatch   = "amount_mismatch"
+	StatusMismatch   = "status_mismatch"
+	CurrencyMismatch = "currency_mismatch"
+)
+
+// Record is one local or remote item reduced to the fields being compared
+type Record struct {
+	Key      string
+	Amount   int64
+	Currency string
+	Status   string
+	// LocalID is the local row id (local records only)
+	LocalID int64
+	Date    time.Time
+	// Raw is the remote payload, kept so missing records can be recreated locally
+	Raw map[string]interface{}
+}
+
+// Discrepancy is a difference between the local and remote view of one item
+type Discrepancy struct {
+	Source       string
+	Key          string
+	Type         string
+	Local        *Record
+	Remote       *Record
+	RemoteStatus string
+	// Fix names the action that resolves the discrepancy safely; empty means manual review
+	Fix string
+}
+
+// Rules describe how a source is compared
+type Rules struct {
+	// CompareAmounts reports amount and currency differences
+	CompareAmounts bool
+	// NormalizeStatus maps a remote status into the local vocabulary.
+	// Nil compares statuses as-is; returning "" skips the status check.
+	NormalizeStatus func(remote string) string
+	// ExpectRemote reports whether a local record should exist remotely.
+	// Nil means every local record is expected.
+	ExpectRemote func(local Record) bool
+	// ExpectLocal reports whether a remote record should exist locally.
+	// Nil means every remote record is expected.
+	ExpectLocal func(remote Record) bool
+	// FixMissingLocal is the action that recreates a missing local record ("" for manual)
+	FixMissingLocal string
+	// FixStatus returns the action that resolves a status mismatch ("" for manual)
+	FixStatus func(local, remote string) string
+}
+
+// Compare matches local and remote records by key. remoteComplete must be
+// false when the remote listing was truncated; local records are then not
+// reported as missing remotely.
+func Compare(source string, local, remote []Record, rules Rules, remoteComplete bool) []Discrepancy {
+	remoteByKey := make(map[string]*Record, len(remote))
+	for i := range remote {
+		if remote[i].Key != "" {
+			remoteByKey[remote[i].Key] = &remote[i]
+		}
+	}
+
+	var found []Discrepancy
+	seen := make(map[string]bool, len(local))
+
+	for i := range local {
+		l := &local[i]
+		if l.Key == "" {
+			continue
+		}
+		seen[l.Key] = true
+
+		r, ok := remoteByKey[l.Key]
+		if !ok {
+			if remoteComplete && (rules.ExpectRemote == nil || rules.ExpectRemote(*l)) {
+				found = append(found, Discrepancy{Source: source, Key: l.Key, Type: MissingRemote, Local: l})
+			}
+			continue
+		}
+
+		if rules.CompareAmounts {
+			if l.Amount != r.Amount {
+				found = append(found, Discrepancy{Source: source, Key: l.Key, Type: AmountMismatch, Local: l, Remote: r})
+			}
+			if l.Currency != "" && r.Currency != "" && !strings.EqualFold(l.Currency, r.Currency) {
+				found = append(found, Discrepancy{Source: source, Key: l.Key, Type: CurrencyMismatch, Local: l, Remote: r})
+			}
+		}
+
+		remoteStatus := r.Status
+		if rules.NormalizeStatus != nil {
+			remoteStatus = rules.NormalizeStatus(r.Status)
+		}
+		if remoteStatus != "" && !strings.EqualFold(l.Status, remoteStatus) {
+			d := Discrepancy{Source: source, Key: l.Key, Type: StatusMismatch, Local: l, Remote: r, RemoteStatus: remoteStatus}
+			if rules.FixStatus != nil {
+				d.Fix = rules.FixStatus(l.Status, remoteStatus)
+			}
+			found = append(found, d)
+		}
+	}
+
+	for i := range remote {
+		r := &remote[i]
+		if r.Key == "" || seen[r.Key] {
+			continue
+		}
+		if rules.ExpectLocal != nil && !rules.ExpectLocal(*r) {
+			continue
+		}
+		found = append(found, Discrepancy{Source: source, Key: r.Key, Type: MissingLocal, Remote: r, Fix: rules.FixMissingLocal})
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Type != found[j].Type {
+			return found[i].Type < found[j].Type
+		}
+		return found[i].Key < found[j].Key
+	})
+	return found
+}
+
+// TransferStatus maps a Paystack transfer status onto expense statuses
+func TransferStatus(status string) string {
+	switch strings.ToLower(status) {
+	case "success":
+		return "paid"
+	case "failed", "reversed", "abandoned", "rejected", "blocked":
+		return "failed"
+	case "pending", "otp", "processing", "queued", "received":
+		return "pending"
+	default:
+		return ""
+	}
+}
+
+// Terminal reports whether a normalized status can no longer change remotely
+func Terminal(status string) bool {
+	switch strings.ToLower(status) {
+	case "paid", "success", "failed", "reversed", "abandoned", "cancelled":
+		return true
+	}
+	return false
+}
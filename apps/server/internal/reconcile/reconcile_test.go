@@ -0,0 +1,85 @@
+package reconcile
+
+import (
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	local := []Record{
+		{Key: "a", Amount: 1000, Currency: "NGN", Status: "pending", LocalID: 1},
+		{Key: "b", Amount: 2000, Currency: "NGN", Status: "paid", LocalID: 2},
+		{Key: "c", Amount: 3000, Currency: "NGN", Status: "paid", LocalID: 3},
+		{Key: "d", Amount: 4000, Currency: "NGN", Status: "paid", LocalID: 4},
+	}
+	remote := []Record{
+		{Key: "a", Amount: 1000, Currency: "NGN", Status: "success"},
+		{Key: "b", Amount: 2500, Currency: "NGN", Status: "success"},
+		{Key: "d", Amount: 4000, Currency: "NGN", Status: "otp"},
+		{Key: "e", Amount: 5000, Currency: "NGN", Status: "success"},
+	}
+	rules := Rules{
+		CompareAmounts:  true,
+		NormalizeStatus: TransferStatus,
+		FixMissingLocal: "record",
+		FixStatus: func(local, remote string) string {
+			if local == "pending" && Terminal(remote) {
+				return "adopt"
+			}
+			return ""
+		},
+	}
+
+	found := Compare("transfers", local, remote, rules, true)
+
+	want := map[string]string{
+		"a": StatusMismatch + "/adopt",
+		"b": AmountMismatch + "/",
+		"c": MissingRemote + "/",
+		"d": StatusMismatch + "/",
+		"e": MissingLocal + "/record",
+	}
+	if len(found) != len(want) {
+		t.Fatalf("Expected %d discrepancies, got %d: %+v", len(want), len(found), found)
+	}
+	for _, d := range found {
+		if got := d.Type + "/" + d.Fix; got != want[d.Key] {
+			t.Errorf("%s: expected %s, got %s", d.Key, want[d.Key], got)
+		}
+	}
+}
+
+func TestCompareTruncatedRemote(t *testing.T) {
+	local := []Record{{Key: "a", Amount: 1000}}
+	if found := Compare("transactions", local, nil, Rules{}, false); len(found) != 0 {
+		t.Errorf("Expected no missing_remote when the remote listing is truncated, got %+v", found)
+	}
+	if found := Compare("transactions", local, nil, Rules{}, true); len(found) != 1 || found[0].Type != MissingRemote {
+		t.Errorf("Expected one missing_remote, got %+v", found)
+	}
+}
+
+func TestCompareExpectations(t *testing.T) {
+	local := []Record{{Key: "card", Status: "paid"}}
+	remote := []Record{{Key: "default", Status: "active"}}
+	rules := Rules{
+		ExpectRemote: func(r Record) bool { return r.Key != "card" },
+		ExpectLocal:  func(r Record) bool { return r.Key != "default" },
+	}
+	if found := Compare("recipients", local, remote, rules, true); len(found) != 0 {
+		t.Errorf("Expected expectations to suppress discrepancies, got %+v", found)
+	}
+}
+
+func TestTransferStatus(t *testing.T) {
+	cases := map[string]string{
+		"success":  "paid",
+		"reversed": "failed",
+		"otp":      "pending",
+		"mystery":  "",
+	}
+	for in, want := range cases {
+		if got := TransferStatus(in); got != want {
+			t.Errorf("TransferStatus(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
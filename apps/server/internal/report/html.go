@@ -0,0 +1,164 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"paystack.mpc.proxy/internal/analytics"
+	"paystack.mpc.proxy/internal/money"
+)
+
+// pageTemplate lays a report out as a standalone page with its styles inline
+var pageTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"money":   func(amount int64, currency money.Currency) string { return money.New(amount, currency).Format() },
+	"cash":    func(m money.Money) string { return m.Format() },
+	"percent": formatPercent,
+	"delta":   formatDelta,
+	"date":    func(t interface{ Format(string) string }) string { return t.Format("2 Jan 2006") },
+	"metric":  metricTitle,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Statement for {{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2933; margin: 2rem auto; max-width: 52rem; }
+h1 { margin-bottom: 0; }
+h2 { border-bottom: 1px solid #d9e2ec; padding-bottom: .25rem; margin-top: 2rem; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .35rem .5rem; border-bottom: 1px solid #f0f4f8; }
+td.num, th.num { text-align: right; font-variant-numeric: tabular-nums; }
+.muted { color: #7b8794; }
+.over { color: #c62828; }
+</style>
+</head>
+<body>
+<h1>Statement for {{.Title}}</h1>
+<p class="muted">{{date .Start}} to {{date .End}} (exclusive), in {{.Currency}}. Generated {{.GeneratedAt.Format "2 Jan 2006 15:04 MST"}}.</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Spent</th><td class="num">{{money .Spending.Sum .Currency}}</td><td class="muted">{{.Spending.Count}} expenses</td></tr>
+<tr><th>Received</th><td class="num">{{money .Income.Sum .Currency}}</td><td class="muted">{{.Income.Count}} invoices paid</td></tr>
+<tr><th>Net</th><td class="num">{{money .Net .Currency}}</td><td></td></tr>
+</table>
+
+<h2>Month over month</h2>
+<table>
+<tr><th></th><th class="num">This month</th><th class="num">Last month</th><th class="num">Change</th></tr>
+{{- range .Changes}}
+<tr><td>{{metric .Metric}}</td><td class="num">{{money .Current.Sum $.Currency}}</td><td class="num">{{money .Previous.Sum $.Currency}}</td><td class="num">{{delta .Delta $.Currency}}</td></tr>
+{{- end}}
+</table>
+
+<h2>By category</h2>
+{{- if .Categories}}
+<table>
+<tr><th>Category</th><th class="num">Count</th><th class="num">Amount</th><th class="num">Last month</th><th class="num">Change</th></tr>
+{{- range .Categories}}
+<tr><td>{{.Name}}</td><td class="num">{{.Stats.Count}}</td><td class="num">{{money .Stats.Sum $.Currency}}</td><td class="num">{{money .Previous.Sum $.Currency}}</td><td class="num">{{delta .Delta $.Currency}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p class="muted">No spending.</p>
+{{- end}}
+
+<h2>By recipient</h2>
+{{- if .Recipients}}
+<table>
+<tr><th>Recipient</th><th class="num">Count</th><th class="num">Amount</th><th class="num">Last month</th><th class="num">Change</th></tr>
+{{- range .Recipients}}
+<tr><td>{{.Name}}</td><td class="num">{{.Stats.Count}}</td><td class="num">{{money .Stats.Sum $.Currency}}</td><td class="num">{{money .Previous.Sum $.Currency}}</td><td class="num">{{delta .Delta $.Currency}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p class="muted">No spending.</p>
+{{- end}}
+
+<h2>Budgets</h2>
+{{- if .Budgets}}
+<table>
+<tr><th>Budget</th><th>Period</th><th class="num">Limit</th><th class="num">Actual</th><th class="num">Remaining</th><th class="num">Used</th></tr>
+{{- range .Budgets}}
+<tr{{if .Over}} class="over"{{end}}><td>{{.Name}}</td><td>{{date .PeriodStart}} to {{date .PeriodEnd}}</td><td class="num">{{money .Limit .Currency}}</td><td class="num">{{money .Actual .Currency}}</td><td class="num">{{money .Remaining .Currency}}</td><td class="num">{{percent .UsagePercent}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p class="muted">No budgets in this period.</p>
+{{- end}}
+
+<h2>Goals</h2>
+{{- if or .Goals.Achieved .Goals.Failed}}
+<table>
+<tr><th>Goal</th><th>Outcome</th><th>Date</th><th class="num">Target</th><th class="num">Progress</th></tr>
+{{- range .Goals.Achieved}}
+<tr><td>{{.Title}}</td><td>Achieved</td><td>{{date .At}}</td><td class="num">{{money .Target .Currency}}</td><td class="num">{{money .Progress .Currency}}</td></tr>
+{{- end}}
+{{- range .Goals.Failed}}
+<tr class="over"><td>{{.Title}}</td><td>Missed</td><td>{{date .At}}</td><td class="num">{{money .Target .Currency}}</td><td class="num">{{money .Progress .Currency}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p class="muted">No goals achieved or missed.</p>
+{{- end}}
+
+<h2>Invoices</h2>
+<table>
+<tr><th>Issued</th><td class="num">{{.Invoices.Issued.Count}}</td><td class="num">{{money .Invoices.Issued.Sum .Currency}}</td></tr>
+<tr><th>Paid</th><td class="num">{{.Invoices.Paid.Count}}</td><td class="num">{{money .Invoices.Paid.Sum .Currency}}</td></tr>
+<tr><th>Issued and still unpaid</th><td class="num">{{.Invoices.Unpaid.Count}}</td><td class="num">{{money .Invoices.Unpaid.Sum .Currency}}</td></tr>
+</table>
+{{- if .Excluded}}
+
+<h2>Left out</h2>
+<table>
+<tr><th>Record</th><th class="num">Amount</th><th>Reason</th></tr>
+{{- range .Excluded}}
+<tr><td>{{.Description}}</td><td class="num">{{cash .Money}}</td><td>{{.Reason}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML renders the report as a standalone HTML page
+func WriteHTML(w io.Writer, r *Report) error {
+	return pageTemplate.Execute(w, r)
+}
+
+// formatPercent writes a usage percentage, or a dash when there is none
+func formatPercent(p *float64) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", *p)
+}
+
+// formatDelta writes a change as "+₦5,000 (+12.5%)"
+func formatDelta(d analytics.Delta, currency money.Currency) string {
+	sign := ""
+	if d.Sum > 0 {
+		sign = "+"
+	}
+	out := sign + money.New(d.Sum, currency).Format()
+	if d.SumPercent != nil {
+		out += fmt.Sprintf(" (%+.1f%%)", *d.SumPercent)
+	}
+	return out
+}
+
+// metricTitle names a change metric for people
+func metricTitle(metric string) string {
+	switch metric {
+	case MetricSpending:
+		return "Spending"
+	case MetricInvoicesIssued:
+		return "Invoices issued"
+	case MetricInvoicesPaid:
+		return "Invoices paid"
+	}
+	return strings.ReplaceAll(metric, "_", " ")
+}
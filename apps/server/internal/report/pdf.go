@@ -0,0 +1,305 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+// The PDF is drawn by hand with the standard Helvetica fonts every reader
+// ships, so no font is embedded and no dependency is needed. Those fonts only
+// cover WinAnsi (Latin-1 plus a few extras such as €), so symbols outside it
+// (₦, GH₵) are written as currency codes instead.
+
+const (
+	pdfPageWidth  = 595.0 // A4 in points
+	pdfPageHeight = 842.0
+	pdfMargin     = 48.0
+	pdfLineHeight = 14.0
+	pdfFontSize   = 9.0
+)
+
+// helveticaWidths are the Helvetica advance widths of ASCII 32-126, in 1/1000 em
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// winAnsi maps the non-ASCII runes we may write to their WinAnsi bytes
+var winAnsi = map[rune]byte{'€': 0x80, '£': 0xA3, '–': 0x96, '—': 0x97}
+
+// pdfSymbols writes currency symbols WinAnsi can't encode as codes, e.g. ₦1,500 → NGN 1,500
+var pdfSymbols = func() *strings.Replacer {
+	var pairs []string
+	for _, c := range money.Currencies() {
+		for _, r := range c.Symbol() {
+			if _, ok := winAnsi[r]; r >= utf8.RuneSelf && !ok {
+				pairs = append(pairs, c.Symbol(), string(c)+" ")
+				break
+			}
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// pdfColumn is where a table column starts, or ends when it is right-aligned
+type pdfColumn struct {
+	x     float64
+	right bool
+}
+
+// pdfWriter lays text out top to bottom, starting a new page when one fills up
+type pdfWriter struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64
+}
+
+func newPDFWriter() *pdfWriter {
+	p := &pdfWriter{}
+	p.newPage()
+	return p
+}
+
+func (p *pdfWriter) newPage() {
+	p.page = &bytes.Buffer{}
+	p.pages = append(p.pages, p.page)
+	p.y = pdfPageHeight - pdfMargin
+}
+
+// advance moves down by height, breaking the page if it wouldn't fit
+func (p *pdfWriter) advance(height float64) {
+	if p.y-height < pdfMargin {
+		p.newPage()
+	}
+	p.y -= height
+}
+
+// text draws s with its left edge at x on the current line
+func (p *pdfWriter) text(x float64, s string, size float64, bold bool, gray float64) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(p.page, "BT %.2f g /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", gray, font, size, x, p.y, pdfString(s))
+}
+
+// heading starts a section
+func (p *pdfWriter) heading(s string) {
+	p.advance(pdfLineHeight * 2)
+	p.text(pdfMargin, s, 12, true, 0)
+	fmt.Fprintf(p.page, "0.85 G 0.5 w %.2f %.2f m %.2f %.2f l S\n", pdfMargin, p.y-4, pdfPageWidth-pdfMargin, p.y-4)
+	p.advance(4)
+}
+
+// line writes a line of plain text
+func (p *pdfWriter) line(s string, gray float64) {
+	p.advance(pdfLineHeight)
+	p.text(pdfMargin, s, pdfFontSize, false, gray)
+}
+
+// row writes one table row, cutting cells short so they don't run into the next column
+func (p *pdfWriter) row(columns []pdfColumn, cells []string, bold bool) {
+	p.advance(pdfLineHeight)
+	for i, cell := range cells {
+		if i >= len(columns) {
+			break
+		}
+		col := columns[i]
+		if col.right {
+			p.text(col.x-textWidth(cell, pdfFontSize), cell, pdfFontSize, bold, 0)
+			continue
+		}
+		end := pdfPageWidth - pdfMargin
+		if i+1 < len(columns) {
+			end = columns[i+1].x
+			if columns[i+1].right && i+1 < len(cells) {
+				end -= textWidth(cells[i+1], pdfFontSize)
+			}
+		}
+		limit := end - col.x - 8
+		p.text(col.x, fitText(cell, pdfFontSize, limit), pdfFontSize, bold, 0)
+	}
+}
+
+// write assembles the pages into a PDF file
+func (p *pdfWriter) write(w io.Writer, title string) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// Objects 1-5 are fixed; each page then takes a page object and its content stream
+	first := 6
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", first+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (moniewave) >>", pdfString(title)))
+	for i, page := range p.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, first+i*2+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// pdfString encodes s as the body of a PDF literal string in WinAnsi
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range pdfSymbols.Replace(s) {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= ' ' && r < utf8.RuneSelf:
+			b.WriteRune(r)
+		case winAnsi[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", winAnsi[r])
+		case r >= 0xA0 && r <= 0xFF:
+			// Latin-1 is the same in WinAnsi
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// textWidth measures s in points, counting anything outside ASCII as an average glyph
+func textWidth(s string, size float64) float64 {
+	total := 0
+	for _, r := range pdfSymbols.Replace(s) {
+		if r >= ' ' && r <= '~' {
+			total += helveticaWidths[r-' ']
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// fitText shortens s with an ellipsis until it fits in width points
+func fitText(s string, size, width float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// Table layouts, in points from the left edge of the page
+var (
+	pdfSummaryColumns  = []pdfColumn{{x: pdfMargin}, {x: 300, right: true}, {x: 320}}
+	pdfChangeColumns   = []pdfColumn{{x: pdfMargin}, {x: 290, right: true}, {x: 390, right: true}, {x: pdfPageWidth - pdfMargin, right: true}}
+	pdfLineColumns     = []pdfColumn{{x: pdfMargin}, {x: 250, right: true}, {x: 340, right: true}, {x: 430, right: true}, {x: pdfPageWidth - pdfMargin, right: true}}
+	pdfBudgetColumns   = []pdfColumn{{x: pdfMargin}, {x: 170}, {x: 360, right: true}, {x: 430, right: true}, {x: 500, right: true}, {x: pdfPageWidth - pdfMargin, right: true}}
+	pdfGoalColumns     = []pdfColumn{{x: pdfMargin}, {x: 220}, {x: 290}, {x: 460, right: true}, {x: pdfPageWidth - pdfMargin, right: true}}
+	pdfExcludedColumns = []pdfColumn{{x: pdfMargin}, {x: 330, right: true}, {x: 345}}
+)
+
+// WritePDF renders the report as a PDF document
+func WritePDF(w io.Writer, r *Report) error {
+	p := newPDFWriter()
+	cur := func(amount int64) string { return money.New(amount, r.Currency).Format() }
+
+	p.advance(18)
+	p.text(pdfMargin, "Statement for "+r.Title, 18, true, 0)
+	p.line(fmt.Sprintf("%s to %s (exclusive), in %s. Generated %s.", r.Start.Format("2 Jan 2006"), r.End.Format("2 Jan 2006"),
+		r.Currency, r.GeneratedAt.Format("2 Jan 2006 15:04 MST")), 0.45)
+
+	p.heading("Summary")
+	p.row(pdfSummaryColumns, []string{"Spent", cur(r.Spending.Sum), fmt.Sprintf("%d expenses", r.Spending.Count)}, false)
+	p.row(pdfSummaryColumns, []string{"Received", cur(r.Income.Sum), fmt.Sprintf("%d invoices paid", r.Income.Count)}, false)
+	p.row(pdfSummaryColumns, []string{"Net", cur(r.Net)}, true)
+
+	p.heading("Month over month")
+	p.row(pdfChangeColumns, []string{"", "This month", "Last month", "Change"}, true)
+	for _, c := range r.Changes {
+		p.row(pdfChangeColumns, []string{metricTitle(c.Metric), cur(c.Current.Sum), cur(c.Previous.Sum), formatDelta(c.Delta, r.Currency)}, false)
+	}
+
+	for _, section := range []struct {
+		title, column string
+		lines         []Line
+	}{{"By category", "Category", r.Categories}, {"By recipient", "Recipient", r.Recipients}} {
+		p.heading(section.title)
+		if len(section.lines) == 0 {
+			p.line("No spending.", 0.45)
+			continue
+		}
+		p.row(pdfLineColumns, []string{section.column, "Count", "Amount", "Last month", "Change"}, true)
+		for _, l := range section.lines {
+			p.row(pdfLineColumns, []string{l.Name, fmt.Sprint(l.Stats.Count), cur(l.Stats.Sum), cur(l.Previous.Sum), formatDelta(l.Delta, r.Currency)}, false)
+		}
+	}
+
+	p.heading("Budgets")
+	if len(r.Budgets) == 0 {
+		p.line("No budgets in this period.", 0.45)
+	} else {
+		p.row(pdfBudgetColumns, []string{"Budget", "Period", "Limit", "Actual", "Remaining", "Used"}, true)
+		for _, b := range r.Budgets {
+			inBudget := func(amount int64) string { return money.New(amount, b.Currency).Format() }
+			period := b.PeriodStart.Format("2 Jan") + " to " + b.PeriodEnd.Format("2 Jan 2006")
+			p.row(pdfBudgetColumns, []string{b.Name, period, inBudget(b.Limit), inBudget(b.Actual), inBudget(b.Remaining), formatPercent(b.UsagePercent)}, b.Over)
+		}
+	}
+
+	p.heading("Goals")
+	if len(r.Goals.Achieved)+len(r.Goals.Failed) == 0 {
+		p.line("No goals achieved or missed.", 0.45)
+	} else {
+		p.row(pdfGoalColumns, []string{"Goal", "Outcome", "Date", "Target", "Progress"}, true)
+		for _, outcome := range []struct {
+			name  string
+			goals []GoalLine
+		}{{"Achieved", r.Goals.Achieved}, {"Missed", r.Goals.Failed}} {
+			for _, g := range outcome.goals {
+				p.row(pdfGoalColumns, []string{g.Title, outcome.name, g.At.Format("2 Jan 2006"),
+					money.New(g.Target, g.Currency).Format(), money.New(g.Progress, g.Currency).Format()}, false)
+			}
+		}
+	}
+
+	p.heading("Invoices")
+	p.row(pdfSummaryColumns, []string{"Issued", cur(r.Invoices.Issued.Sum), fmt.Sprintf("%d invoices", r.Invoices.Issued.Count)}, false)
+	p.row(pdfSummaryColumns, []string{"Paid", cur(r.Invoices.Paid.Sum), fmt.Sprintf("%d invoices", r.Invoices.Paid.Count)}, false)
+	p.row(pdfSummaryColumns, []string{"Issued and still unpaid", cur(r.Invoices.Unpaid.Sum), fmt.Sprintf("%d invoices", r.Invoices.Unpaid.Count)}, false)
+
+	if len(r.Excluded) > 0 {
+		p.heading("Left out")
+		for _, e := range r.Excluded {
+			p.row(pdfExcludedColumns, []string{e.Description, e.Money.Format(), e.Reason}, false)
+		}
+	}
+
+	return p.write(w, "Statement for "+r.Title)
+}
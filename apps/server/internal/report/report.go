@@ -0,0 +1,366 @@
+// Package report builds monthly statements: what was spent and by whom, how
+// each budget did, which goals were met or missed, what was invoiced and paid,
+// and how the month compares with the one before.
+//
+// Callers load the month's records (and the previous month's, for the
+// comparison) already converted into the statement currency; Build only adds
+// them up. The result is plain data meant to be stored as-is, so a statement
+// reads the same however the records behind it are edited later.
+//
+// DESIGN DECISIONS:
+//   - A period is a calendar month in UTC, written YYYY-MM; End is exclusive
+//   - Amounts are minor units of the statement currency, except budget lines,
+//     which stay in their budget's own currency the way the budget was set
+//   - Month-over-month changes reuse analytics.Compare, so percentages are
+//     omitted when the previous month was zero
+//   - Categories and recipients are ordered by amount, largest first; the
+//     previous month's amount is shown even when nothing was spent this month
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/analytics"
+	"paystack.mpc.proxy/internal/money"
+)
+
+// Period is one calendar month
+type Period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ParsePeriod reads a month as YYYY-MM
+func ParsePeriod(value string) (Period, error) {
+	start, err := time.Parse("2006-01", strings.TrimSpace(value))
+	if err != nil {
+		return Period{}, fmt.Errorf("period must be a month as YYYY-MM")
+	}
+	return MonthOf(start), nil
+}
+
+// MonthOf returns the calendar month t falls in
+func MonthOf(t time.Time) Period {
+	y, m, _ := t.UTC().Date()
+	start := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	return Period{Start: start, End: start.AddDate(0, 1, 0)}
+}
+
+// Previous returns the month before p
+func (p Period) Previous() Period {
+	return MonthOf(p.Start.AddDate(0, -1, 0))
+}
+
+// String writes the period as YYYY-MM
+func (p Period) String() string {
+	return p.Start.Format("2006-01")
+}
+
+// Title names the period for people, e.g. "October 2026"
+func (p Period) Title() string {
+	return p.Start.Format("January 2006")
+}
+
+// Contains reports whether t falls in the period
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+// Expense is money spent in the statement currency
+type Expense struct {
+	Date      time.Time
+	Amount    int64
+	Category  string
+	Recipient string
+}
+
+// Invoice is an invoice in the statement currency
+type Invoice struct {
+	Code     string
+	Customer string
+	Amount   int64
+	IssuedAt time.Time
+	// PaidAt is set when the invoice has been paid
+	PaidAt *time.Time
+}
+
+// Budget is one budget_limits row that overlaps the period
+type Budget struct {
+	ID          int
+	Name        string
+	LimitType   string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Limit       money.Money
+	Spent       money.Money
+}
+
+// Goal is a goal that was achieved or missed in the period
+type Goal struct {
+	ID       int
+	Title    string
+	Target   money.Money
+	Progress money.Money
+	// At is when it was achieved, or when it ran out
+	At time.Time
+}
+
+// Input is everything a statement is built from
+type Input struct {
+	// Expenses, Invoices and Goals cover the period and the month before it
+	Expenses []Expense
+	Invoices []Invoice
+	// Budgets overlap the period
+	Budgets  []Budget
+	Achieved []Goal
+	Failed   []Goal
+	// Excluded lists records left out, typically because they couldn't be converted
+	Excluded []Excluded
+}
+
+// Excluded is a record left out of the statement, and why
+type Excluded struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	money.Money
+	Reason string `json:"reason"`
+}
+
+// Line is one category or recipient
+type Line struct {
+	Name     string          `json:"name"`
+	Stats    analytics.Stats `json:"totals"`
+	Previous analytics.Stats `json:"previous"`
+	Delta    analytics.Delta `json:"delta"`
+}
+
+// BudgetLine is one budget's limit against what was spent, in the budget's currency
+type BudgetLine struct {
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	LimitType   string         `json:"limit_type"`
+	PeriodStart time.Time      `json:"period_start"`
+	PeriodEnd   time.Time      `json:"period_end"`
+	Currency    money.Currency `json:"currency"`
+	Limit       int64          `json:"limit"`
+	Actual      int64          `json:"actual"`
+	Remaining   int64          `json:"remaining"`
+	// UsagePercent is omitted for a zero limit
+	UsagePercent *float64 `json:"usage_percent,omitempty"`
+	Over         bool     `json:"over"`
+}
+
+// GoalLine is a goal achieved or missed, in the goal's currency
+type GoalLine struct {
+	ID       int            `json:"id"`
+	Title    string         `json:"title"`
+	Currency money.Currency `json:"currency"`
+	Target   int64          `json:"target"`
+	Progress int64          `json:"progress"`
+	At       time.Time      `json:"at"`
+}
+
+// Goals lists the goals achieved and failed in the period
+type Goals struct {
+	Achieved []GoalLine `json:"achieved"`
+	Failed   []GoalLine `json:"failed"`
+}
+
+// Invoices compares what was billed with what came in
+type Invoices struct {
+	Issued analytics.Stats `json:"issued"`
+	Paid   analytics.Stats `json:"paid"`
+	// Unpaid counts invoices issued in the period that still haven't been paid
+	Unpaid analytics.Stats `json:"unpaid"`
+}
+
+// Change is one headline figure against the month before
+type Change struct {
+	Metric   string          `json:"metric"`
+	Current  analytics.Stats `json:"current"`
+	Previous analytics.Stats `json:"previous"`
+	Delta    analytics.Delta `json:"delta"`
+}
+
+// Change metrics
+const (
+	MetricSpending       = "spending"
+	MetricInvoicesIssued = "invoices_issued"
+	MetricInvoicesPaid   = "invoices_paid"
+)
+
+// Report is a monthly statement
+type Report struct {
+	Period      string         `json:"period"`
+	Title       string         `json:"title"`
+	Start       time.Time      `json:"start"`
+	End         time.Time      `json:"end"`
+	Currency    money.Currency `json:"currency"`
+	GeneratedAt time.Time      `json:"generated_at"`
+
+	Spending   analytics.Stats `json:"spending"`
+	Income     analytics.Stats `json:"income"`
+	Net        int64           `json:"net"`
+	Categories []Line          `json:"categories"`
+	Recipients []Line          `json:"recipients"`
+	Budgets    []BudgetLine    `json:"budgets"`
+	Goals      Goals           `json:"goals"`
+	Invoices   Invoices        `json:"invoices"`
+	Changes    []Change        `json:"changes"`
+	Excluded   []Excluded      `json:"excluded"`
+}
+
+// Build adds up a month. Records outside the period and the month before it are ignored.
+func Build(period Period, currency money.Currency, in Input, generatedAt time.Time) *Report {
+	previous := period.Previous()
+	r := &Report{
+		Period:      period.String(),
+		Title:       period.Title(),
+		Start:       period.Start,
+		End:         period.End,
+		Currency:    currency,
+		GeneratedAt: generatedAt,
+		Budgets:     []BudgetLine{},
+		Goals:       Goals{Achieved: goalLines(in.Achieved), Failed: goalLines(in.Failed)},
+		Excluded:    in.Excluded,
+	}
+	if r.Excluded == nil {
+		r.Excluded = []Excluded{}
+	}
+
+	var prevSpending analytics.Stats
+	categories, recipients := lineSet{}, lineSet{}
+	for _, e := range in.Expenses {
+		category := e.Category
+		if category == "" {
+			category = "uncategorized"
+		}
+		switch {
+		case period.Contains(e.Date):
+			add(&r.Spending, e.Amount)
+			add(&categories.get(category).Stats, e.Amount)
+			add(&recipients.get(e.Recipient).Stats, e.Amount)
+		case previous.Contains(e.Date):
+			add(&prevSpending, e.Amount)
+			add(&categories.get(category).Previous, e.Amount)
+			add(&recipients.get(e.Recipient).Previous, e.Amount)
+		}
+	}
+	r.Categories, r.Recipients = categories.lines(), recipients.lines()
+
+	var prevInvoices Invoices
+	for _, inv := range in.Invoices {
+		switch {
+		case period.Contains(inv.IssuedAt):
+			add(&r.Invoices.Issued, inv.Amount)
+			if inv.PaidAt == nil {
+				add(&r.Invoices.Unpaid, inv.Amount)
+			}
+		case previous.Contains(inv.IssuedAt):
+			add(&prevInvoices.Issued, inv.Amount)
+		}
+		if inv.PaidAt == nil {
+			continue
+		}
+		switch {
+		case period.Contains(*inv.PaidAt):
+			add(&r.Invoices.Paid, inv.Amount)
+		case previous.Contains(*inv.PaidAt):
+			add(&prevInvoices.Paid, inv.Amount)
+		}
+	}
+	r.Income = r.Invoices.Paid
+	r.Net = r.Income.Sum - r.Spending.Sum
+
+	for _, b := range in.Budgets {
+		r.Budgets = append(r.Budgets, budgetLine(b))
+	}
+	sort.SliceStable(r.Budgets, func(i, j int) bool { return r.Budgets[i].PeriodStart.Before(r.Budgets[j].PeriodStart) })
+
+	r.Changes = []Change{
+		change(MetricSpending, r.Spending, prevSpending),
+		change(MetricInvoicesIssued, r.Invoices.Issued, prevInvoices.Issued),
+		change(MetricInvoicesPaid, r.Invoices.Paid, prevInvoices.Paid),
+	}
+	return r
+}
+
+// add counts one amount into s
+func add(s *analytics.Stats, amount int64) {
+	s.Sum += amount
+	s.Count++
+	s.Average = float64(s.Sum) / float64(s.Count)
+}
+
+func change(metric string, current, previous analytics.Stats) Change {
+	return Change{Metric: metric, Current: current, Previous: previous, Delta: analytics.Compare(current, previous)}
+}
+
+// lineSet collects lines by name
+type lineSet map[string]*Line
+
+func (s lineSet) get(name string) *Line {
+	if s[name] == nil {
+		s[name] = &Line{Name: name}
+	}
+	return s[name]
+}
+
+// lines orders the set by this month's amount, then last month's, then name
+func (s lineSet) lines() []Line {
+	lines := make([]Line, 0, len(s))
+	for _, l := range s {
+		l.Delta = analytics.Compare(l.Stats, l.Previous)
+		lines = append(lines, *l)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if a.Stats.Sum != b.Stats.Sum {
+			return a.Stats.Sum > b.Stats.Sum
+		}
+		if a.Previous.Sum != b.Previous.Sum {
+			return a.Previous.Sum > b.Previous.Sum
+		}
+		return a.Name < b.Name
+	})
+	return lines
+}
+
+func budgetLine(b Budget) BudgetLine {
+	line := BudgetLine{
+		ID:          b.ID,
+		Name:        b.Name,
+		LimitType:   b.LimitType,
+		PeriodStart: b.PeriodStart,
+		PeriodEnd:   b.PeriodEnd,
+		Currency:    b.Limit.Currency.OrDefault(),
+		Limit:       b.Limit.Minor(),
+		Actual:      b.Spent.Minor(),
+		Remaining:   b.Limit.Minor() - b.Spent.Minor(),
+		Over:        b.Spent.Minor() > b.Limit.Minor(),
+	}
+	if line.Limit > 0 {
+		usage := float64(int64(float64(line.Actual)/float64(line.Limit)*10000+0.5)) / 100
+		line.UsagePercent = &usage
+	}
+	return line
+}
+
+func goalLines(goals []Goal) []GoalLine {
+	lines := make([]GoalLine, 0, len(goals))
+	for _, g := range goals {
+		lines = append(lines, GoalLine{
+			ID:       g.ID,
+			Title:    g.Title,
+			Currency: g.Target.Currency.OrDefault(),
+			Target:   g.Target.Minor(),
+			Progress: g.Progress.Minor(),
+			At:       g.At,
+		})
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].At.Before(lines[j].At) })
+	return lines
+}
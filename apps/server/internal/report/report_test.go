@@ -0,0 +1,185 @@
+package report
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+func day(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func testInput() Input {
+	paidOct, paidSep := day("2026-10-20"), day("2026-09-28")
+	return Input{
+		Expenses: []Expense{
+			{Date: day("2026-10-03"), Amount: 50000, Category: "fuel", Recipient: "Total"},
+			{Date: day("2026-10-10"), Amount: 30000, Category: "fuel", Recipient: "Mobil"},
+			{Date: day("2026-10-15"), Amount: 200000, Category: "rent", Recipient: "Landlord"},
+			{Date: day("2026-10-31"), Amount: 10000, Recipient: "Mama Put"},
+			{Date: day("2026-09-12"), Amount: 40000, Category: "fuel", Recipient: "Total"},
+			{Date: day("2026-09-30"), Amount: 25000, Category: "data", Recipient: "MTN"},
+			// Outside both months
+			{Date: day("2026-11-01"), Amount: 999999, Category: "fuel", Recipient: "Total"},
+		},
+		Invoices: []Invoice{
+			{Code: "PRQ_1", Customer: "Acme", Amount: 300000, IssuedAt: day("2026-10-02"), PaidAt: &paidOct},
+			{Code: "PRQ_2", Customer: "Globex", Amount: 120000, IssuedAt: day("2026-10-25")},
+			// Issued in September, paid in October
+			{Code: "PRQ_3", Customer: "Initech", Amount: 80000, IssuedAt: day("2026-09-20"), PaidAt: &paidOct},
+			{Code: "PRQ_4", Customer: "Acme", Amount: 50000, IssuedAt: day("2026-09-01"), PaidAt: &paidSep},
+		},
+		Budgets: []Budget{
+			{ID: 2, Name: "Fuel", LimitType: "category", PeriodStart: day("2026-10-01"), PeriodEnd: day("2026-10-31"),
+				Limit: money.New(60000, money.NGN), Spent: money.New(80000, money.NGN)},
+			{ID: 1, Name: "October", LimitType: "default", PeriodStart: day("2026-09-30"), PeriodEnd: day("2026-10-31"),
+				Limit: money.New(500000, money.NGN), Spent: money.New(290000, money.NGN)},
+		},
+		Achieved: []Goal{{ID: 7, Title: "Emergency fund", Target: money.New(100000, money.NGN), Progress: money.New(100000, money.NGN), At: day("2026-10-12")}},
+		Failed:   []Goal{{ID: 8, Title: "Holiday", Target: money.New(500, money.USD), Progress: money.New(200, money.USD), At: day("2026-10-31")}},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	period, err := ParsePeriod("2026-10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Build(period, money.NGN, testInput(), day("2026-11-01"))
+
+	if r.Period != "2026-10" || r.Title != "October 2026" || r.End != day("2026-11-01") {
+		t.Errorf("Unexpected period: %s %q %v", r.Period, r.Title, r.End)
+	}
+	if r.Spending.Sum != 290000 || r.Spending.Count != 4 {
+		t.Errorf("Spending = %+v", r.Spending)
+	}
+	if r.Income.Sum != 380000 || r.Net != 90000 {
+		t.Errorf("Income = %+v, net %d", r.Income, r.Net)
+	}
+
+	var names []string
+	for _, l := range r.Categories {
+		names = append(names, l.Name)
+	}
+	if got := strings.Join(names, ","); got != "rent,fuel,uncategorized,data" {
+		t.Errorf("Categories in order %s", got)
+	}
+	fuel := r.Categories[1]
+	if fuel.Stats.Sum != 80000 || fuel.Previous.Sum != 40000 || fuel.Delta.Sum != 40000 || *fuel.Delta.SumPercent != 100 {
+		t.Errorf("Fuel line = %+v", fuel)
+	}
+	if data := r.Categories[3]; data.Stats.Count != 0 || data.Previous.Sum != 25000 || *data.Delta.SumPercent != -100 {
+		t.Errorf("Data line = %+v", data)
+	}
+	if r.Recipients[0].Name != "Landlord" || len(r.Recipients) != 5 {
+		t.Errorf("Recipients = %+v", r.Recipients)
+	}
+
+	if r.Budgets[0].ID != 1 || !r.Budgets[1].Over || r.Budgets[1].Remaining != -20000 || *r.Budgets[1].UsagePercent != 133.33 {
+		t.Errorf("Budgets = %+v", r.Budgets)
+	}
+	if len(r.Goals.Achieved) != 1 || len(r.Goals.Failed) != 1 || r.Goals.Failed[0].Currency != money.USD {
+		t.Errorf("Goals = %+v", r.Goals)
+	}
+
+	inv := r.Invoices
+	if inv.Issued.Sum != 420000 || inv.Paid.Count != 2 || inv.Unpaid.Sum != 120000 {
+		t.Errorf("Invoices = %+v", inv)
+	}
+
+	want := map[string][2]int64{
+		MetricSpending:       {290000, 65000},
+		MetricInvoicesIssued: {420000, 130000},
+		MetricInvoicesPaid:   {380000, 50000},
+	}
+	for _, c := range r.Changes {
+		if w := want[c.Metric]; c.Current.Sum != w[0] || c.Previous.Sum != w[1] || c.Delta.Sum != w[0]-w[1] {
+			t.Errorf("Change %s = %+v", c.Metric, c)
+		}
+	}
+}
+
+func TestBuildEmptyMonth(t *testing.T) {
+	r := Build(MonthOf(day("2026-02-14")), money.NGN, Input{}, day("2026-03-01"))
+	if r.Period != "2026-02" || r.End != day("2026-03-01") {
+		t.Errorf("Unexpected period %s ending %v", r.Period, r.End)
+	}
+	if r.Categories == nil || r.Budgets == nil || r.Goals.Achieved == nil || r.Excluded == nil {
+		t.Error("Expected empty lists rather than nil so the JSON has arrays")
+	}
+	for _, c := range r.Changes {
+		if c.Delta.SumPercent != nil {
+			t.Errorf("Expected no percentage against an empty month, got %v", *c.Delta.SumPercent)
+		}
+	}
+}
+
+func TestParsePeriodRejectsDays(t *testing.T) {
+	if _, err := ParsePeriod("2026-10-01"); err == nil {
+		t.Error("Expected a full date to be rejected")
+	}
+}
+
+func TestWriteHTMLEscapes(t *testing.T) {
+	in := testInput()
+	in.Expenses[0].Recipient = "<script>alert(1)</script>"
+	r := Build(MonthOf(day("2026-10-01")), money.NGN, in, day("2026-11-01"))
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	if strings.Contains(page, "<script>") {
+		t.Error("Expected recipient names to be escaped")
+	}
+	for _, want := range []string{"Statement for October 2026", "₦2,900", "₦400 (&#43;100.0%)", "133.3%", "Holiday", "$5"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected the page to contain %q", want)
+		}
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	in := testInput()
+	// Enough recipients to need a second page
+	for i := 0; i < 80; i++ {
+		in.Expenses = append(in.Expenses, Expense{Date: day("2026-10-05"), Amount: int64(i + 1), Recipient: "Vendor (" + strconv.Itoa(i) + ")"})
+	}
+	r := Build(MonthOf(day("2026-10-01")), money.NGN, in, day("2026-11-01"))
+
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.String()
+	if !strings.HasPrefix(doc, "%PDF-1.4") || !strings.HasSuffix(doc, "%%EOF\n") {
+		t.Fatal("Expected a PDF header and trailer")
+	}
+	if pages := regexp.MustCompile(`/Count (\d+)`).FindStringSubmatch(doc); pages == nil || pages[1] == "1" {
+		t.Error("Expected the report to run onto a second page")
+	}
+	if !strings.Contains(doc, "(NGN 2,900)") || strings.Contains(doc, "₦") {
+		t.Error("Expected naira amounts written with the currency code")
+	}
+	if !strings.Contains(doc, `(Vendor \(0\))`) {
+		t.Error("Expected parentheses in text to be escaped")
+	}
+
+	// Every xref offset must point at its object
+	start, _ := strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(doc)[1])
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(doc[start:], -1)
+	for i, m := range offsets {
+		offset, _ := strconv.Atoi(m[1])
+		if want := strconv.Itoa(i+1) + " 0 obj"; !strings.HasPrefix(doc[offset:], want) {
+			t.Errorf("Offset %d doesn't start object %d", offset, i+1)
+		}
+	}
+}
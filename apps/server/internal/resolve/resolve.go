@@ -0,0 +1,361 @@
+// Package resolve works out which recipient a spoken name means. Voice
+// transcripts mangle names ("Jon Doh" for "John Doe"), so names are compared by
+// sound and by spelling rather than as text, and when several recipients are
+// about equally likely the result says so, so the agent can ask which one.
+//
+// DESIGN DECISIONS:
+//   - Resolution is pure: callers load the candidates, so ranking is easy to test
+//   - Each spoken word is matched to the closest word of the name, and the score
+//     leans on how well the spoken words are covered (80%) over how much of the
+//     name was said (20%): "pay John" should find John Doe, but John alone ranks
+//     above John Doe when both exist
+//   - Words that sound alike (Double Metaphone) score at least SoundFloor, so a
+//     mishearing is never worse than a close misspelling
+//   - Aliases are scored like names; the recipient keeps its best score
+//   - Recent payments add a little, at most FrequencyBoost, which is less than
+//     Margin: frequency orders close candidates but never settles a tie alone
+//   - "John at Access" narrows by bank: the part after "at" is compared with the
+//     bank name, and recipients at other banks are marked down
+//   - CheckAccountName scores only how well the spoken words are covered: bank
+//     account names put the surname first and carry middle names nobody says
+package resolve
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"paystack.mpc.proxy/internal/phonetic"
+	"paystack.mpc.proxy/internal/search"
+)
+
+// Statuses
+const (
+	// StatusResolved means one recipient is confidently the one meant
+	StatusResolved = "resolved"
+	// StatusAmbiguous means several recipients are about equally likely
+	StatusAmbiguous = "ambiguous"
+	// StatusUncertain means one recipient is the best match but not by much
+	StatusUncertain = "uncertain"
+	// StatusNotFound means no recipient is close enough
+	StatusNotFound = "not_found"
+)
+
+// Scoring weights
+const (
+	// SoundFloor is the least a pair of words that sound alike scores
+	SoundFloor = 0.5
+	// FrequencyBoost is the most recent payments add to a score
+	FrequencyBoost = 0.05
+	// weakMatch is the least a word scores to count as matched at all
+	weakMatch = 0.6
+	// otherBankPenalty marks down recipients at a bank other than the one named
+	otherBankPenalty = 0.3
+	// prefixScore is what a spoken word scores when it starts a name word, as
+	// "Ade" for "Adebayo"
+	prefixScore = 0.9
+)
+
+// honorifics are dropped from spoken names
+var honorifics = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "miss": true, "dr": true, "chief": true, "madam": true, "sir": true,
+}
+
+// Account name verdicts
+const (
+	// NameVerified means the spoken name is the account holder's
+	NameVerified = "verified"
+	// NameUncertain means the names only partly match, e.g. a misheard surname
+	NameUncertain = "uncertain"
+	// NameMismatch means the account is someone else's, or the name given was a nickname
+	NameMismatch = "mismatch"
+)
+
+// NameCheck is how a spoken name compares with a bank account's name
+type NameCheck struct {
+	Status string
+	// Confidence is from 0 to 1
+	Confidence float64
+}
+
+// Candidate is a recipient that might be the one meant
+type Candidate struct {
+	RecipientCode string
+	Name          string
+	BankName      string
+	AccountNumber string
+	Aliases       []string
+	// RecentPayments is how many times the recipient was paid recently
+	RecentPayments int
+}
+
+// Match is a candidate with how likely it is to be the one meant
+type Match struct {
+	Candidate
+	// Confidence is from 0 to 1
+	Confidence float64
+	// MatchedOn is the name or alias that matched best
+	MatchedOn string
+}
+
+// Result is the outcome of resolving a name
+type Result struct {
+	Status string
+	// Matches are the candidates at or above MinConfidence, best first
+	Matches []Match
+	// Question asks the user to confirm or choose, unless resolved or not found
+	Question string
+}
+
+// Config tunes resolution
+type Config struct {
+	// MinConfidence is the least a candidate needs to be returned at all
+	MinConfidence float64
+	// Confident is the least the best candidate needs to be resolved
+	Confident float64
+	// Margin is how far ahead of the next the best candidate must be to be resolved
+	Margin float64
+	// MaxMatches caps the matches returned
+	MaxMatches int
+}
+
+// DefaultConfig is used for anything left zero
+var DefaultConfig = Config{
+	MinConfidence: 0.6,
+	Confident:     0.8,
+	Margin:        0.1,
+	MaxMatches:    5,
+}
+
+// withDefaults fills zero fields from DefaultConfig
+func (c Config) withDefaults() Config {
+	if c.MinConfidence <= 0 {
+		c.MinConfidence = DefaultConfig.MinConfidence
+	}
+	if c.Confident <= 0 {
+		c.Confident = DefaultConfig.Confident
+	}
+	if c.Margin <= 0 {
+		c.Margin = DefaultConfig.Margin
+	}
+	if c.MaxMatches <= 0 {
+		c.MaxMatches = DefaultConfig.MaxMatches
+	}
+	return c
+}
+
+// Resolve ranks candidates against a spoken name
+func Resolve(spoken string, candidates []Candidate, cfg Config) Result {
+	cfg = cfg.withDefaults()
+
+	spokenName, spokenBank := splitBank(spoken)
+	query := nameWords(spokenName)
+	if len(query) == 0 {
+		return Result{Status: StatusNotFound}
+	}
+
+	var matches []Match
+	for _, c := range candidates {
+		m := Match{Candidate: c}
+		for _, variant := range append([]string{c.Name}, c.Aliases...) {
+			if score := NameScore(query, words(variant)); score > m.Confidence {
+				m.Confidence = score
+				m.MatchedOn = variant
+			}
+		}
+		if spokenBank != "" && NameScore(words(spokenBank), words(c.BankName)) < SoundFloor {
+			m.Confidence -= otherBankPenalty
+		}
+		if m.Confidence < cfg.MinConfidence {
+			continue
+		}
+		m.Confidence = math.Min(1, m.Confidence+frequencyBoost(c.RecentPayments))
+		matches = append(matches, m)
+	}
+	if len(matches) == 0 {
+		return Result{Status: StatusNotFound}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Confidence != matches[j].Confidence {
+			return matches[i].Confidence > matches[j].Confidence
+		}
+		return matches[i].RecentPayments > matches[j].RecentPayments
+	})
+	if len(matches) > cfg.MaxMatches {
+		matches = matches[:cfg.MaxMatches]
+	}
+
+	// Candidates this close to the best are as good as it
+	tied := 1
+	for tied < len(matches) && matches[0].Confidence-matches[tied].Confidence < cfg.Margin {
+		tied++
+	}
+
+	result := Result{Matches: matches}
+	switch {
+	case tied > 1:
+		result.Status = StatusAmbiguous
+		result.Question = question(matches[:tied])
+	case matches[0].Confidence >= cfg.Confident:
+		result.Status = StatusResolved
+	default:
+		result.Status = StatusUncertain
+		result.Question = question(matches[:1])
+	}
+	return result
+}
+
+// CheckAccountName compares a spoken name with the account name a bank returned,
+// e.g. "Ada Obi" with "OBI ADAEZE CHIOMA". Confident or better is verified, and
+// MinConfidence or better is uncertain.
+func CheckAccountName(spoken, accountName string, cfg Config) NameCheck {
+	cfg = cfg.withDefaults()
+	said, name := nameWords(spoken), words(accountName)
+	if len(said) == 0 || len(name) == 0 {
+		return NameCheck{Status: NameMismatch}
+	}
+
+	score := meanBest(said, name)
+	if len(said) > 1 {
+		score = math.Max(score, meanBest([]string{strings.Join(said, "")}, name))
+	}
+
+	check := NameCheck{Status: NameMismatch, Confidence: score}
+	switch {
+	case score >= cfg.Confident:
+		check.Status = NameVerified
+	case score >= cfg.MinConfidence:
+		check.Status = NameUncertain
+	}
+	return check
+}
+
+// NameScore is how well the spoken words match a name's words, from 0 to 1
+func NameScore(spoken, name []string) float64 {
+	if len(spoken) == 0 || len(name) == 0 {
+		return 0
+	}
+	covered := meanBest(spoken, name)
+	said := meanBest(name, spoken)
+	score := 0.8*covered + 0.2*said
+
+	// Transcripts split and join words freely: "john doe" may arrive as "johndoe"
+	if len(spoken) > 1 || len(name) > 1 {
+		score = math.Max(score, WordScore(strings.Join(spoken, ""), strings.Join(name, "")))
+	}
+	return score
+}
+
+// meanBest averages, for each word of from, its best score against the words of
+// to. A best below weakMatch counts as no match at all, so unrelated words that
+// happen to share letters don't tip the ranking.
+func meanBest(from, to []string) float64 {
+	var total float64
+	for _, f := range from {
+		var best float64
+		for _, t := range to {
+			best = math.Max(best, WordScore(f, t))
+		}
+		if best >= weakMatch {
+			total += best
+		}
+	}
+	return total / float64(len(from))
+}
+
+// WordScore is how likely a spoken word is to be a name word, from 0 to 1
+func WordScore(spoken, name string) float64 {
+	if spoken == name {
+		return 1
+	}
+	s, n := []rune(spoken), []rune(name)
+	if len(s) >= 3 && len(s) < len(n) && strings.HasPrefix(name, spoken) {
+		return prefixScore
+	}
+
+	spelling := 1 - float64(search.Distance(spoken, name))/float64(max(len(s), len(n)))
+	if soundAlike(spoken, name) {
+		// Sounding alike counts for more the closer the spelling is
+		return math.Max(spelling, SoundFloor+(1-SoundFloor)*spelling)
+	}
+	return math.Max(0, spelling)
+}
+
+// soundAlike reports whether any Double Metaphone code of a matches one of b
+func soundAlike(a, b string) bool {
+	ap, aa := phonetic.DoubleMetaphone(a)
+	bp, ba := phonetic.DoubleMetaphone(b)
+	for _, x := range []string{ap, aa} {
+		for _, y := range []string{bp, ba} {
+			if x != "" && x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// frequencyBoost grows with recent payments, reaching FrequencyBoost at about ten
+func frequencyBoost(payments int) float64 {
+	if payments <= 0 {
+		return 0
+	}
+	return math.Min(FrequencyBoost, FrequencyBoost*math.Log2(1+float64(payments))/math.Log2(11))
+}
+
+// splitBank separates "John Doe at Access Bank" into the name and the bank
+func splitBank(spoken string) (name, bank string) {
+	lower := strings.ToLower(spoken)
+	if i := strings.LastIndex(lower, " at "); i > 0 {
+		return spoken[:i], spoken[i+len(" at "):]
+	}
+	return spoken, ""
+}
+
+// nameWords splits a spoken name into words, dropping filler words and honorifics
+func nameWords(spoken string) []string {
+	var out []string
+	for _, w := range search.Terms(spoken) {
+		if !honorifics[w] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// words splits a stored name into lowercase words
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// question asks which of matches was meant: "Did you mean John Doe at GTBank or
+// John Doh at Access Bank?"
+func question(matches []Match) string {
+	labels := make([]string, len(matches))
+	count := map[string]int{}
+	for i, m := range matches {
+		labels[i] = m.Name
+		if m.BankName != "" {
+			labels[i] += " at " + m.BankName
+		}
+		count[labels[i]]++
+	}
+	// The same name at the same bank can only be told apart by account
+	for i, m := range matches {
+		if count[labels[i]] > 1 && len(m.AccountNumber) >= 4 {
+			labels[i] += fmt.Sprintf(" (account ending %s)", m.AccountNumber[len(m.AccountNumber)-4:])
+		}
+	}
+
+	switch len(labels) {
+	case 1:
+		return "Did you mean " + labels[0] + "?"
+	default:
+		return "Did you mean " + strings.Join(labels[:len(labels)-1], ", ") + " or " + labels[len(labels)-1] + "?"
+	}
+}
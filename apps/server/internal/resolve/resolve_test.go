@@ -0,0 +1,134 @@
+package resolve
+
+import "testing"
+
+var recipients = []Candidate{
+	{RecipientCode: "RCP_doe", Name: "John Doe", BankName: "GTBank", AccountNumber: "0123456789"},
+	{RecipientCode: "RCP_doh", Name: "John Doh", BankName: "Access Bank", AccountNumber: "9876543210"},
+	{RecipientCode: "RCP_okafor", Name: "Catherine Okafor", BankName: "Zenith Bank"},
+	{RecipientCode: "RCP_adebayo", Name: "Adebayo Ogunlesi", BankName: "First Bank", Aliases: []string{"landlord"}},
+}
+
+func TestResolveAsksBetweenSimilarNames(t *testing.T) {
+	result := Resolve("pay John Doe", recipients, Config{})
+	if result.Status != StatusAmbiguous {
+		t.Fatalf("Expected ambiguous, got %s", result.Status)
+	}
+	if result.Matches[0].RecipientCode != "RCP_doe" {
+		t.Errorf("Expected the exact name first, got %s", result.Matches[0].RecipientCode)
+	}
+	want := "Did you mean John Doe at GTBank or John Doh at Access Bank?"
+	if result.Question != want {
+		t.Errorf("Question = %q, want %q", result.Question, want)
+	}
+}
+
+func TestResolveMisheardName(t *testing.T) {
+	result := Resolve("Kathryn Okafo", recipients, Config{})
+	if result.Status != StatusResolved || result.Matches[0].RecipientCode != "RCP_okafor" {
+		t.Fatalf("Expected Catherine Okafor to be resolved, got %s %+v", result.Status, result.Matches)
+	}
+
+	// A first name alone is a guess worth confirming
+	result = Resolve("Kathryn", recipients, Config{})
+	if result.Status != StatusUncertain {
+		t.Fatalf("Expected uncertain, got %s", result.Status)
+	}
+	if result.Question != "Did you mean Catherine Okafor at Zenith Bank?" {
+		t.Errorf("Unexpected question %q", result.Question)
+	}
+}
+
+func TestResolveAlias(t *testing.T) {
+	result := Resolve("send to my landlrd", recipients, Config{})
+	if result.Status != StatusResolved || result.Matches[0].RecipientCode != "RCP_adebayo" {
+		t.Fatalf("Expected the landlord alias to resolve, got %s %+v", result.Status, result.Matches)
+	}
+	if result.Matches[0].MatchedOn != "landlord" {
+		t.Errorf("Expected the match to be on the alias, got %q", result.Matches[0].MatchedOn)
+	}
+}
+
+func TestResolveNarrowsByBank(t *testing.T) {
+	result := Resolve("John at Access", recipients, Config{})
+	if result.Status != StatusResolved || result.Matches[0].RecipientCode != "RCP_doh" {
+		t.Fatalf("Expected John Doh at Access Bank, got %s %+v", result.Status, result.Matches)
+	}
+}
+
+func TestResolveFrequencyOrdersButDoesNotSettleTies(t *testing.T) {
+	candidates := []Candidate{
+		{RecipientCode: "RCP_doe", Name: "John Doe", BankName: "GTBank"},
+		{RecipientCode: "RCP_smith", Name: "John Smith", BankName: "UBA", RecentPayments: 20},
+	}
+	result := Resolve("John", candidates, Config{})
+	if result.Matches[0].RecipientCode != "RCP_smith" {
+		t.Errorf("Expected the often-paid recipient first, got %s", result.Matches[0].RecipientCode)
+	}
+	if result.Status != StatusAmbiguous {
+		t.Errorf("Expected frequency alone not to resolve a tie, got %s", result.Status)
+	}
+}
+
+func TestResolveNotFound(t *testing.T) {
+	if result := Resolve("Chukwuemeka", recipients, Config{}); result.Status != StatusNotFound || len(result.Matches) != 0 {
+		t.Errorf("Expected nothing to match, got %s %+v", result.Status, result.Matches)
+	}
+	if result := Resolve("pay", nil, Config{}); result.Status != StatusNotFound {
+		t.Errorf("Expected not found with no candidates, got %s", result.Status)
+	}
+}
+
+func TestQuestionTellsApartSameNameAndBank(t *testing.T) {
+	matches := []Match{
+		{Candidate: Candidate{Name: "Ada Obi", BankName: "GTBank", AccountNumber: "0000001111"}},
+		{Candidate: Candidate{Name: "Ada Obi", BankName: "GTBank", AccountNumber: "0000002222"}},
+		{Candidate: Candidate{Name: "Ada Obi"}},
+	}
+	want := "Did you mean Ada Obi at GTBank (account ending 1111), Ada Obi at GTBank (account ending 2222) or Ada Obi?"
+	if got := question(matches); got != want {
+		t.Errorf("question = %q, want %q", got, want)
+	}
+}
+
+func TestWordScore(t *testing.T) {
+	tests := []struct {
+		spoken, name string
+		min, max     float64
+	}{
+		{"john", "john", 1, 1},
+		{"jon", "john", 0.85, 0.9},
+		{"ade", "adebayo", prefixScore, prefixScore},
+		// Sound alike though spelled apart
+		{"kathryn", "catherine", 0.7, 0.8},
+		{"bello", "okafor", 0, 0.3},
+	}
+	for _, tt := range tests {
+		if got := WordScore(tt.spoken, tt.name); got < tt.min || got > tt.max {
+			t.Errorf("WordScore(%q, %q) = %.3f, want between %.2f and %.2f", tt.spoken, tt.name, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestCheckAccountName(t *testing.T) {
+	tests := []struct {
+		spoken, accountName, want string
+	}{
+		// Surname first, middle name unsaid, first name shortened
+		{"Ada Obi", "OBI ADAEZE CHIOMA", NameVerified},
+		{"Mr John Doe", "DOE JOHN", NameVerified},
+		{"Catherine Okafo", "OKAFOR CATHERINE", NameVerified},
+		// A misheard name alone, or a name partly right, is worth confirming
+		{"Kathryn", "OKAFOR CATHERINE", NameUncertain},
+		{"Ada Chioma Eze", "OBI ADAEZE CHIOMA", NameUncertain},
+		// The right first name with the wrong surname is someone else
+		{"John Bello", "DOE JOHN", NameMismatch},
+		{"my landlord", "OGUNLESI ADEBAYO", NameMismatch},
+		{"Tunde", "", NameMismatch},
+	}
+	for _, tt := range tests {
+		if got := CheckAccountName(tt.spoken, tt.accountName, Config{}); got.Status != tt.want {
+			t.Errorf("CheckAccountName(%q, %q) = %s (%.2f), want %s", tt.spoken, tt.accountName, got.Status, got.Confidence, tt.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package schedule works out when recurring payments fall due.
+//
+// A Rule is either a cron expression ("0 9 1 * *": 09:00 on the 1st) or an
+// interval counted from a start time ("every 2 weeks"). Next gives the first
+// run after a moment, so a caller only ever stores the next run and asks again
+// once it has happened.
+//
+// DESIGN DECISIONS:
+//   - Times are evaluated in the Start's location; the server keeps them in UTC
+//   - Intervals count from Start rather than from the previous run, so a late
+//     or retried run never shifts the ones after it
+//   - Monthly and yearly intervals keep Start's day, falling back to the last
+//     day of shorter months (the 31st runs on 30 April, then 31 May)
+//   - Cron has the usual five fields; when both day of month and day of week are
+//     restricted, a day matching either runs, as in Vixie cron
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Interval units
+const (
+	Day   = "day"
+	Week  = "week"
+	Month = "month"
+	Year  = "year"
+)
+
+// ErrInvalidRule is returned when a rule has no valid recurrence
+var ErrInvalidRule = errors.New("invalid schedule")
+
+// Rule says when a schedule runs: on a cron expression, or every Interval Units from Start
+type Rule struct {
+	Cron     string
+	Interval int
+	Unit     string
+	// Start is the first moment the schedule may run; for intervals it is the first run
+	Start time.Time
+	// End, when set, is the last moment the schedule may run
+	End time.Time
+}
+
+// Validate checks that the rule describes exactly one recurrence
+func (r Rule) Validate() error {
+	if r.Start.IsZero() {
+		return fmt.Errorf("%w: a start time is required", ErrInvalidRule)
+	}
+	if !r.End.IsZero() && r.End.Before(r.Start) {
+		return fmt.Errorf("%w: end is before start", ErrInvalidRule)
+	}
+	if r.Cron != "" {
+		if r.Interval != 0 || r.Unit != "" {
+			return fmt.Errorf("%w: use either cron or an interval, not both", ErrInvalidRule)
+		}
+		_, err := ParseCron(r.Cron)
+		return err
+	}
+	if r.Interval <= 0 {
+		return fmt.Errorf("%w: interval must be at least 1", ErrInvalidRule)
+	}
+	switch r.Unit {
+	case Day, Week, Month, Year:
+		return nil
+	}
+	return fmt.Errorf("%w: unit must be one of: day, week, month, year", ErrInvalidRule)
+}
+
+// Next returns the first run strictly after after. ok is false once the rule has
+// ended or is invalid.
+func (r Rule) Next(after time.Time) (next time.Time, ok bool) {
+	if r.Validate() != nil {
+		return time.Time{}, false
+	}
+	if r.Cron != "" {
+		cron, _ := ParseCron(r.Cron)
+		from := after
+		if earliest := r.Start.Add(-time.Nanosecond); from.Before(earliest) {
+			from = earliest
+		}
+		next, ok = cron.Next(from.In(r.Start.Location()))
+	} else {
+		next, ok = r.nextInterval(after), true
+	}
+	if !ok || (!r.End.IsZero() && next.After(r.End)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// Upcoming returns up to n runs after after
+func (r Rule) Upcoming(after time.Time, n int) []time.Time {
+	runs := []time.Time{}
+	for len(runs) < n {
+		next, ok := r.Next(after)
+		if !ok {
+			break
+		}
+		runs = append(runs, next)
+		after = next
+	}
+	return runs
+}
+
+// Describe says how often the rule runs, e.g. "every month" or "every 2 weeks"
+func (r Rule) Describe() string {
+	if r.Cron != "" {
+		return "on cron " + r.Cron
+	}
+	if r.Interval == 1 {
+		return "every " + r.Unit
+	}
+	return fmt.Sprintf("every %d %ss", r.Interval, r.Unit)
+}
+
+// occurrence returns the nth run (n = 0 is Start) of an interval rule
+func (r Rule) occurrence(n int) time.Time {
+	switch r.Unit {
+	case Day:
+		return r.Start.AddDate(0, 0, n*r.Interval)
+	case Week:
+		return r.Start.AddDate(0, 0, 7*n*r.Interval)
+	case Year:
+		return addMonths(r.Start, 12*n*r.Interval)
+	}
+	return addMonths(r.Start, n*r.Interval)
+}
+
+func (r Rule) nextInterval(after time.Time) time.Time {
+	if after.Before(r.Start) {
+		return r.Start
+	}
+
+	// Estimate how many runs have passed, then step to the first one after
+	var n int
+	switch r.Unit {
+	case Day, Week:
+		days := 1
+		if r.Unit == Week {
+			days = 7
+		}
+		n = int(after.Sub(r.Start).Hours()/24) / (days * r.Interval)
+	default:
+		months := r.Interval
+		if r.Unit == Year {
+			months *= 12
+		}
+		elapsed := (after.Year()-r.Start.Year())*12 + int(after.Month()) - int(r.Start.Month())
+		n = elapsed / months
+	}
+	if n > 0 {
+		n--
+	}
+	for !r.occurrence(n).After(after) {
+		n++
+	}
+	return r.occurrence(n)
+}
+
+// addMonths moves t by months, keeping its day where the month has it and the
+// month's last day where it doesn't
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	day := t.Day()
+	if last := daysIn(first.Year(), first.Month()); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
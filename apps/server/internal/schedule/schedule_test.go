@@ -0,0 +1,115 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestMonthlyIntervalKeepsDayOfMonth(t *testing.T) {
+	rule := Rule{Interval: 1, Unit: Month, Start: at("2026-01-31 09:00")}
+	got := rule.Upcoming(at("2026-01-01 00:00"), 4)
+	want := []string{"2026-01-31 09:00", "2026-02-28 09:00", "2026-03-31 09:00", "2026-04-30 09:00"}
+	for i, w := range want {
+		if i >= len(got) || !got[i].Equal(at(w)) {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+
+	// Counting from the start means a late call lands on the right run
+	if next, _ := rule.Next(at("2026-07-15 12:00")); !next.Equal(at("2026-07-31 09:00")) {
+		t.Errorf("Expected 31 July, got %v", next)
+	}
+}
+
+func TestIntervalUnits(t *testing.T) {
+	start := at("2026-01-05 08:00")
+	tests := []struct {
+		interval int
+		unit     string
+		after    string
+		want     string
+	}{
+		{1, Day, "2026-01-05 08:00", "2026-01-06 08:00"},
+		{3, Day, "2026-01-06 00:00", "2026-01-08 08:00"},
+		{2, Week, "2026-01-05 09:00", "2026-01-19 08:00"},
+		{1, Year, "2026-03-01 00:00", "2027-01-05 08:00"},
+		{1, Week, "2025-12-01 00:00", "2026-01-05 08:00"},
+	}
+	for _, tt := range tests {
+		rule := Rule{Interval: tt.interval, Unit: tt.unit, Start: start}
+		if next, ok := rule.Next(at(tt.after)); !ok || !next.Equal(at(tt.want)) {
+			t.Errorf("every %d %s after %s: expected %s, got %v", tt.interval, tt.unit, tt.after, tt.want, next)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	tests := []struct {
+		expr  string
+		after string
+		want  string
+	}{
+		{"0 9 1 * *", "2026-01-01 09:00", "2026-02-01 09:00"},
+		{"0 9 1 * *", "2026-01-01 08:59", "2026-01-01 09:00"},
+		{"30 8 * * 1-5", "2026-01-02 09:00", "2026-01-05 08:30"}, // Friday → Monday
+		{"*/15 * * * *", "2026-01-01 10:07", "2026-01-01 10:15"},
+		{"0 0 29 2 *", "2026-01-01 00:00", "2028-02-29 00:00"},
+		{"@monthly", "2026-03-15 00:00", "2026-04-01 00:00"},
+		{"0 12 13 * 5", "2026-01-01 00:00", "2026-01-02 12:00"}, // the 13th or any Friday
+		{"0 0 * * 7", "2026-01-01 00:00", "2026-01-04 00:00"},   // 7 is Sunday
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		if next, ok := cron.Next(at(tt.after)); !ok || !next.Equal(at(tt.want)) {
+			t.Errorf("%s after %s: expected %s, got %v", tt.expr, tt.after, tt.want, next)
+		}
+	}
+
+	if _, ok := (&Cron{}).Next(at("2026-01-01 00:00")); ok {
+		t.Error("Expected a cron that never matches to give up")
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	start := at("2026-01-01 00:00")
+	rules := []Rule{
+		{Cron: "0 9 1 *", Start: start},
+		{Cron: "61 * * * *", Start: start},
+		{Cron: "0 9 1 * *", Interval: 1, Unit: Month, Start: start},
+		{Interval: 1, Unit: "fortnight", Start: start},
+		{Interval: 0, Unit: Day, Start: start},
+		{Interval: 1, Unit: Day},
+		{Interval: 1, Unit: Day, Start: start, End: start.Add(-time.Hour)},
+	}
+	for _, rule := range rules {
+		if err := rule.Validate(); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Expected %+v to be invalid, got %v", rule, err)
+		}
+	}
+}
+
+func TestRuleBounds(t *testing.T) {
+	rule := Rule{Cron: "0 9 * * *", Start: at("2026-01-10 12:00"), End: at("2026-01-12 09:00")}
+	runs := rule.Upcoming(at("2026-01-01 00:00"), 10)
+	if len(runs) != 2 || !runs[0].Equal(at("2026-01-11 09:00")) || !runs[1].Equal(at("2026-01-12 09:00")) {
+		t.Errorf("Expected runs on the 11th and 12th only, got %v", runs)
+	}
+	if rule.Describe() != "on cron 0 9 * * *" {
+		t.Errorf("Unexpected description %q", rule.Describe())
+	}
+	if d := (Rule{Interval: 2, Unit: Week}).Describe(); d != "every 2 weeks" {
+		t.Errorf("Unexpected description %q", d)
+	}
+}
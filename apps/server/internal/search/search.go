@@ -0,0 +1,214 @@
+// Package search turns a free-text query, often a voice transcript, into SQLite
+// FTS5 match expressions that tolerate prefixes and small typos.
+//
+// A query is tried in tiers, each looser than the last:
+//   - exact: every term matches, as a word or the start of one
+//   - fuzzy: every term matches, or a word in the index within a few edits of it
+//   - partial: any term (or its corrections) matches
+//
+// Callers run the tiers in order and keep the first results they see for each
+// record, so "pay jonh doe" lists John Doe under fuzzy rather than not at all.
+//
+// DESIGN DECISIONS:
+//   - Words that carry the command rather than the subject ("pay", "send", "to")
+//     are dropped, unless that leaves nothing to search for
+//   - Corrections come from the index's own vocabulary, so they can only ever
+//     point at something that exists
+//   - Edits allowed grow with the word: none up to 3 letters, 1 up to 6, 2 beyond.
+//     Short words have too many neighbours for a correction to mean anything
+//   - A swap of two neighbouring letters ("jonh") counts as one edit, since it is
+//     the most common slip when typing and in transcripts
+//   - Single characters are matched whole rather than as prefixes, which would
+//     match nearly everything
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Tier names
+const (
+	TierExact   = "exact"
+	TierFuzzy   = "fuzzy"
+	TierPartial = "partial"
+)
+
+// maxCorrections caps the index words a single term can be corrected to
+const maxCorrections = 5
+
+// fillerWords are dropped from queries; they say what to do, not what to find
+var fillerWords = map[string]bool{
+	"a": true, "an": true, "the": true, "to": true, "for": true, "of": true,
+	"my": true, "me": true, "please": true,
+	"pay": true, "send": true, "find": true, "search": true, "show": true, "look": true, "up": true,
+}
+
+// Tier is one pass over the index
+type Tier struct {
+	Name string
+	// Expression is the FTS5 MATCH expression for the pass
+	Expression string
+}
+
+// Terms splits a query into lowercase words, dropping filler words and repeats
+func Terms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var terms, fillers []string
+	seen := map[string]bool{}
+	for _, w := range words {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		if fillerWords[w] {
+			fillers = append(fillers, w)
+			continue
+		}
+		terms = append(terms, w)
+	}
+	if len(terms) == 0 {
+		return fillers
+	}
+	return terms
+}
+
+// MaxEdits is how many edits a correction of term may be
+func MaxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Distance is the number of insertions, deletions, substitutions and swaps of
+// neighbouring letters needed to turn a into b
+func Distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	// Three rows are enough: swaps look back two
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	cur := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		cur[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(t)]
+}
+
+// Corrections returns the vocabulary words close enough to term to be what was
+// meant, closest first
+func Corrections(term string, vocabulary []string) []string {
+	limit := MaxEdits(term)
+	if limit == 0 {
+		return nil
+	}
+
+	type candidate struct {
+		word     string
+		distance int
+	}
+	var candidates []candidate
+	seen := map[string]bool{}
+	for _, word := range vocabulary {
+		if word == term || seen[word] {
+			continue
+		}
+		seen[word] = true
+		if d := Distance(term, word); d <= limit {
+			candidates = append(candidates, candidate{word, d})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].word < candidates[j].word
+	})
+
+	var words []string
+	for i, c := range candidates {
+		if i == maxCorrections {
+			break
+		}
+		words = append(words, c.word)
+	}
+	return words
+}
+
+// Plan builds the tiers for terms, given the corrections found for each.
+// Tiers that would match exactly what an earlier one did are left out.
+func Plan(terms []string, corrections map[string][]string) []Tier {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	exact := make([]string, len(terms))
+	fuzzy := make([]string, len(terms))
+	for i, term := range terms {
+		exact[i] = phrase(term)
+		alternatives := []string{exact[i]}
+		for _, c := range corrections[term] {
+			alternatives = append(alternatives, quote(c))
+		}
+		fuzzy[i] = group(alternatives, " OR ")
+	}
+
+	var tiers []Tier
+	add := func(name, expression string) {
+		for _, t := range tiers {
+			if t.Expression == expression {
+				return
+			}
+		}
+		tiers = append(tiers, Tier{Name: name, Expression: expression})
+	}
+	add(TierExact, strings.Join(exact, " AND "))
+	add(TierFuzzy, strings.Join(fuzzy, " AND "))
+	if len(terms) > 1 {
+		add(TierPartial, strings.Join(fuzzy, " OR "))
+	}
+	return tiers
+}
+
+// phrase matches term as a word, or as the start of one when it is long enough
+func phrase(term string) string {
+	if len([]rune(term)) < 2 {
+		return quote(term)
+	}
+	return quote(term) + "*"
+}
+
+// quote makes term an FTS5 string, so nothing in it is read as syntax
+func quote(term string) string {
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+}
+
+// group joins alternatives, bracketing them when there is more than one
+func group(alternatives []string, op string) string {
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+	return "(" + strings.Join(alternatives, op) + ")"
+}
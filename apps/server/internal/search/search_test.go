@@ -0,0 +1,86 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"Pay Jonh Doe", []string{"jonh", "doe"}},
+		{"send ₦5,000 to Ada-Obi, please", []string{"5", "000", "ada", "obi"}},
+		{"fuel fuel FUEL", []string{"fuel"}},
+		// Nothing but filler is still searched for
+		{"pay me", []string{"pay", "me"}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		if got := Terms(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"john", "john", 0},
+		{"jonh", "john", 1},
+		{"jon", "john", 1},
+		{"jhon", "john", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCorrections(t *testing.T) {
+	vocabulary := []string{"john", "joan", "johnson", "doe", "jonah", "jonh"}
+	if got := Corrections("jonh", vocabulary); !reflect.DeepEqual(got, []string{"john", "jonah"}) {
+		t.Errorf("Corrections(jonh) = %q", got)
+	}
+	if got := Corrections("doe", vocabulary); got != nil {
+		t.Errorf("Expected short words not to be corrected, got %q", got)
+	}
+	if got := Corrections("jonhson", vocabulary); !reflect.DeepEqual(got, []string{"johnson"}) {
+		t.Errorf("Corrections(jonhson) = %q", got)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tiers := Plan([]string{"jonh", "d"}, map[string][]string{"jonh": {"john", "joan"}})
+	want := []Tier{
+		{TierExact, `"jonh"* AND "d"`},
+		{TierFuzzy, `("jonh"* OR "john" OR "joan") AND "d"`},
+		{TierPartial, `("jonh"* OR "john" OR "joan") OR "d"`},
+	}
+	if !reflect.DeepEqual(tiers, want) {
+		t.Errorf("Plan = %+v", tiers)
+	}
+}
+
+func TestPlanSkipsRepeatedTiers(t *testing.T) {
+	tiers := Plan([]string{"fuel"}, nil)
+	if len(tiers) != 1 || tiers[0].Name != TierExact {
+		t.Errorf("Expected a single exact tier, got %+v", tiers)
+	}
+	if Plan(nil, nil) != nil {
+		t.Error("Expected no tiers without terms")
+	}
+}
+
+func TestPlanQuotesSyntax(t *testing.T) {
+	tiers := Plan([]string{`a"b`}, nil)
+	if tiers[0].Expression != `"a""b"*` {
+		t.Errorf("Expected the quote to be escaped, got %s", tiers[0].Expression)
+	}
+}
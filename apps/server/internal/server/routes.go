@@ -0,0 +1,255 @@
+package server
+
+import (
+	"net/http"
+
+	"paystack.mpc.proxy/internal/cards"
+	"paystack.mpc.proxy/internal/handlers"
+	"paystack.mpc.proxy/internal/paystack"
+	"paystack.mpc.proxy/internal/tools"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewAPIRouter builds the /api/v1 route table. It carries no middleware so it
+// can be mounted by the HTTP server or dispatched to in-process (e.g. by the
+// MCP server).
+func NewAPIRouter(client *paystack.Client) http.Handler {
+	// Initialize handlers
+	coreHandler := handlers.NewCoreHandler(client)
+	customerHandler := handlers.NewCustomerHandler(client)
+	transactionHandler := handlers.NewTransactionHandler(client)
+	transferHandler := handlers.NewTransferHandler(client)
+	planHandler := handlers.NewPlanHandler(client)
+	subscriptionHandler := handlers.NewSubscriptionHandler(client)
+	bankHandler := handlers.NewBankHandler(client)
+	subAccountHandler := handlers.NewSubAccountHandler(client)
+	invoiceHandler := handlers.NewInvoiceHandler(client)
+	verdictHandler := handlers.NewVerdictHandler()
+	recipientHandler := handlers.NewRecipientHandler(client)
+	recipientGroupHandler := handlers.NewRecipientGroupHandler()
+	expenseHandler := handlers.NewExpenseHandler()
+	budgetHandler := handlers.NewBudgetHandler()
+	goalHandler := handlers.NewGoalHandler()
+	serviceProviderHandler := handlers.NewServiceProviderHandler()
+	limitHandler := handlers.NewLimitHandler()
+	analyticsHandler := handlers.NewAnalyticsHandler()
+	snapshotHandler := handlers.NewSnapshotHandler(client)
+	forecastHandler := handlers.NewForecastHandler(client)
+	reportHandler := handlers.NewReportHandler()
+	anomalyHandler := handlers.NewAnomalyHandler()
+	searchHandler := handlers.NewSearchHandler()
+	cardHandler := handlers.NewCardHandler(cards.NewSimulatedIssuer())
+	reconciliationHandler := handlers.NewReconciliationHandler(client)
+	ledgerHandler := handlers.NewLedgerHandler()
+	fxHandler := handlers.NewFXHandler()
+	categoryHandler := handlers.NewCategoryHandler()
+	importHandler := handlers.NewImportHandler()
+	exportHandler := handlers.NewExportHandler()
+	scheduleHandler := handlers.NewScheduleHandler(client)
+
+	r := chi.NewRouter()
+
+	// Core routes
+	r.Post("/balance", coreHandler.CheckBalance)
+
+	// Customer routes
+	r.Post("/customers/create", customerHandler.Create)
+	r.Post("/customers/list", customerHandler.List)
+
+	// Transaction routes
+	r.Post("/transactions/initialize", transactionHandler.Initialize)
+	r.Post("/transactions/verify", transactionHandler.Verify)
+	r.Post("/transactions/list", transactionHandler.List)
+	r.Get("/transactions/local", transactionHandler.ListLocal)
+	r.Get("/transactions/search", transactionHandler.Search)
+	r.Post("/transactions/sync", transactionHandler.Sync)
+	r.Get("/transactions/sync", transactionHandler.SyncStatus)
+
+	// Transfer routes
+	r.Post("/transfers/recipient/create", transferHandler.CreateRecipient)
+	r.Post("/transfers/initiate", transferHandler.Initiate)
+	r.Post("/transfers/bulk", transferHandler.Bulk)
+
+	// Scheduled transfer routes (one-off transfers sent at a future time)
+	r.Post("/transfers/schedule", transferHandler.Schedule)
+	r.Get("/transfers/scheduled", transferHandler.ListScheduled)
+	r.Post("/transfers/scheduled/run", transferHandler.RunScheduled)
+	r.Get("/transfers/scheduled/{id}", transferHandler.GetScheduled)
+	r.Post("/transfers/scheduled/{id}/cancel", transferHandler.CancelScheduled)
+	r.Post("/transfers/scheduled/{id}/reschedule", transferHandler.RescheduleScheduled)
+
+	// Plan routes
+	r.Post("/plans/list", planHandler.List)
+
+	// Subscription routes
+	r.Post("/subscriptions/list", subscriptionHandler.List)
+
+	// Bank routes
+	r.Post("/banks/list", bankHandler.List)
+	r.Post("/banks/resolve", bankHandler.ResolveAccount)
+
+	// SubAccount routes
+	r.Post("/subaccounts/list", subAccountHandler.List)
+
+	// Invoice routes
+	r.Post("/invoices/create", invoiceHandler.Create)
+	r.Post("/invoices/list", invoiceHandler.List)
+	r.Post("/invoices/get/{id_or_code}", invoiceHandler.Get)
+	r.Post("/invoices/verify/{code}", invoiceHandler.Verify)
+
+	// Verdict routes (credit check / affordability)
+	r.Post("/verdict/check", verdictHandler.CheckAffordability)
+	r.Get("/verdict/profile", verdictHandler.GetFinancialProfile)
+	r.Get("/verdict/profiles", verdictHandler.ListProfiles)
+
+	// Recipient routes (transfer recipients)
+	r.Post("/recipients/create", recipientHandler.Create)
+	r.Get("/recipients/list", recipientHandler.List)
+	r.Get("/recipients/get", recipientHandler.Get)
+	r.Get("/recipients/search", recipientHandler.Search)
+	r.Get("/recipients/resolve", recipientHandler.Resolve)
+	r.Post("/recipients/sync", recipientHandler.Sync)
+	r.Get("/recipients/sync", recipientHandler.SyncStatus)
+	r.Get("/recipients/aliases", recipientHandler.ListAliases)
+	r.Post("/recipients/aliases", recipientHandler.AddAlias)
+	r.Delete("/recipients/aliases/{id}", recipientHandler.DeleteAlias)
+
+	// Recipient group routes (named sets of recipients paid together)
+	r.Post("/recipients/groups", recipientGroupHandler.Create)
+	r.Get("/recipients/groups", recipientGroupHandler.List)
+	r.Get("/recipients/groups/{id}", recipientGroupHandler.Get)
+	r.Put("/recipients/groups/{id}", recipientGroupHandler.Update)
+	r.Delete("/recipients/groups/{id}", recipientGroupHandler.Delete)
+	r.Post("/recipients/groups/{id}/members", recipientGroupHandler.SetMember)
+	r.Delete("/recipients/groups/{id}/members/{recipient_code}", recipientGroupHandler.RemoveMember)
+
+	// Recipient lifecycle routes (registered after the fixed paths above)
+	r.Put("/recipients/{recipient_code}", recipientHandler.Update)
+	r.Delete("/recipients/{recipient_code}", recipientHandler.Delete)
+
+	// Expense routes
+	r.Post("/expenses/create", expenseHandler.Create)
+	r.Post("/expenses/list", expenseHandler.List)
+	r.Get("/expenses/get/{id}", expenseHandler.Get)
+	r.Put("/expenses/update/{id}", expenseHandler.Update)
+
+	// Category routes (canonical category tree and categorization rules)
+	r.Get("/categories", categoryHandler.List)
+	r.Post("/categories", categoryHandler.Create)
+	r.Get("/categories/rules", categoryHandler.ListRules)
+	r.Post("/categories/rules", categoryHandler.CreateRule)
+	r.Put("/categories/rules/{id}", categoryHandler.UpdateRule)
+	r.Delete("/categories/rules/{id}", categoryHandler.DeleteRule)
+	r.Post("/categories/suggest", categoryHandler.Suggest)
+	r.Post("/categories/recategorize", categoryHandler.Recategorize)
+
+	// Import routes (bank statements imported as paid expenses)
+	r.Post("/imports/statements", importHandler.Import)
+	r.Get("/imports/statements", importHandler.List)
+	r.Get("/imports/statements/{id}", importHandler.Get)
+	r.Get("/imports/profiles", importHandler.ListProfiles)
+	r.Post("/imports/profiles", importHandler.SaveProfile)
+
+	// Export routes (CSV, XLSX, OFX and QIF downloads; filters match the list routes)
+	r.Get("/exports/expenses", exportHandler.Expenses)
+	r.Post("/exports/expenses", exportHandler.Expenses)
+	r.Get("/exports/budgets", exportHandler.Budgets)
+	r.Post("/exports/budgets", exportHandler.Budgets)
+	r.Get("/exports/invoices", exportHandler.Invoices)
+	r.Post("/exports/invoices", exportHandler.Invoices)
+
+	// Schedule routes (recurring payments)
+	r.Post("/schedules/create", scheduleHandler.Create)
+	r.Get("/schedules/list", scheduleHandler.List)
+	r.Post("/schedules/run", scheduleHandler.RunDue)
+	r.Get("/schedules/{id}", scheduleHandler.Get)
+	r.Get("/schedules/{id}/runs", scheduleHandler.Runs)
+	r.Post("/schedules/{id}/pause", scheduleHandler.Pause)
+	r.Post("/schedules/{id}/resume", scheduleHandler.Resume)
+	r.Post("/schedules/{id}/skip", scheduleHandler.Skip)
+	r.Post("/schedules/{id}/cancel", scheduleHandler.Cancel)
+
+	// Budget routes
+	r.Post("/budgets/create", budgetHandler.Create)
+	r.Post("/budgets/list", budgetHandler.List)
+	r.Get("/budgets/{id}", budgetHandler.Get)
+	r.Put("/budgets/{id}", budgetHandler.Update)
+	r.Get("/budgets/{id}/check/{amount}", budgetHandler.CheckLimit)
+	r.Get("/budgets/active", budgetHandler.GetActiveBudgets)
+
+	// Goal routes
+	r.Post("/goals/create", goalHandler.Create)
+	r.Post("/goals/list", goalHandler.List)
+	r.Get("/goals/{id}", goalHandler.Get)
+	r.Put("/goals/{id}", goalHandler.Update)
+	r.Delete("/goals/{id}", goalHandler.Delete)
+
+	// Transfer limit routes
+	r.Get("/limits/account", limitHandler.GetAccount)
+	r.Post("/limits/account", limitHandler.SetAccount)
+	r.Get("/limits/beneficiaries", limitHandler.ListBeneficiaries)
+	r.Post("/limits/beneficiaries", limitHandler.SetBeneficiary)
+	r.Delete("/limits/beneficiaries/{recipient_code}/{period}", limitHandler.DeleteBeneficiary)
+	r.Post("/limits/check", limitHandler.Check)
+
+	// Analytics routes
+	r.Post("/analytics/aggregate", analyticsHandler.Aggregate)
+
+	// Search route (expenses, recipients, invoices and service providers ranked together)
+	r.Get("/search", searchHandler.Search)
+
+	// Snapshot route (balances, budgets, goals, pending expenses, invoices, activity)
+	r.Get("/snapshot", snapshotHandler.Get)
+
+	// Forecast route (projected daily balance for the next N days)
+	r.Get("/forecast", forecastHandler.Get)
+
+	// Report routes (monthly statements stored as snapshots)
+	r.Post("/reports/generate", reportHandler.Generate)
+	r.Get("/reports/list", reportHandler.List)
+	r.Get("/reports/{id}", reportHandler.Get)
+
+	// Anomaly routes (payments flagged as likely duplicates or unusual, for review)
+	r.Get("/anomalies/list", anomalyHandler.List)
+	r.Post("/anomalies/{id}/review", anomalyHandler.Review)
+
+	// Virtual card routes
+	r.Post("/cards/create", cardHandler.Create)
+	r.Get("/cards/list", cardHandler.List)
+	r.Get("/cards/{id}", cardHandler.Get)
+	r.Put("/cards/{id}/controls", cardHandler.UpdateControls)
+	r.Post("/cards/{id}/freeze", cardHandler.Freeze)
+	r.Post("/cards/{id}/unfreeze", cardHandler.Unfreeze)
+	r.Post("/cards/{id}/authorize", cardHandler.Authorize)
+	r.Get("/cards/{id}/transactions", cardHandler.Transactions)
+
+	// Reconciliation routes (local records vs Paystack)
+	r.Post("/reconciliation/run", reconciliationHandler.Run)
+	r.Get("/reconciliation/runs", reconciliationHandler.ListRuns)
+	r.Get("/reconciliation/runs/{id}", reconciliationHandler.GetRun)
+	r.Post("/reconciliation/items/{id}/fix", reconciliationHandler.FixItem)
+	r.Post("/reconciliation/items/{id}/resolve", reconciliationHandler.ResolveItem)
+
+	// Ledger routes (double-entry journal behind budgets and goals)
+	r.Get("/ledger/accounts", ledgerHandler.Accounts)
+	r.Get("/ledger/entries", ledgerHandler.Entries)
+	r.Get("/ledger/integrity", ledgerHandler.Integrity)
+	r.Post("/ledger/backfill", ledgerHandler.Backfill)
+
+	// Exchange rate routes (dated rates for converting into a budget's currency)
+	r.Get("/fx/rates", fxHandler.ListRates)
+	r.Post("/fx/rates", fxHandler.SetRates)
+	r.Post("/fx/rates/import", fxHandler.ImportRates)
+	r.Get("/fx/convert", fxHandler.Convert)
+
+	// Service Provider routes
+	r.Get("/service_providers", serviceProviderHandler.List)
+
+	// Tool routes (voice agent); tools dispatch back into this router
+	toolHandler := handlers.NewToolHandler(tools.NewDefaultRegistry(r))
+	r.Post("/tools/execute", toolHandler.Execute)
+	r.Get("/tools/catalog", toolHandler.Catalog)
+
+	return r
+}
@@ -25,6 +25,35 @@ func New(cfg *config.Config) *Server {
 	// Create Paystack client
 	client := paystack.NewClient(cfg.PaystackSecretKey)
 
+	// Post journal entries for records created before the ledger existed
+	if _, err := handlers.BackfillLedger(); err != nil {
+		log.Printf("Warning: ledger backfill failed: %v", err)
+	}
+
+	// Load exchange rates shipped alongside the deployment
+	if cfg.FXRatesFile != "" {
+		if count, err := handlers.ImportFXRatesFile(cfg.FXRatesFile); err != nil {
+			log.Printf("Warning: failed to import exchange rates from %s: %v", cfg.FXRatesFile, err)
+		} else {
+			log.Printf("Imported %d exchange rates from %s", count, cfg.FXRatesFile)
+		}
+	}
+
+	// Keep the local transaction ledger in sync with Paystack
+	if cfg.TransactionSyncInterval > 0 {
+		handlers.StartTransactionSync(client, cfg.TransactionSyncInterval)
+	}
+	if cfg.RecipientSyncInterval > 0 {
+		handlers.StartRecipientSync(client, cfg.RecipientSyncInterval)
+	}
+	if cfg.ReconciliationInterval > 0 {
+		handlers.StartReconciliation(client, cfg.ReconciliationInterval)
+	}
+	if cfg.ScheduleInterval > 0 {
+		handlers.StartScheduler(client, cfg.ScheduleInterval)
+		handlers.StartTransferQueue(client, cfg.ScheduleInterval)
+	}
+
 	// Create Chi router
 	r := chi.NewRouter()
 
@@ -45,95 +74,8 @@ func New(cfg *config.Config) *Server {
 		MaxAge:           300,
 	}))
 
-	// Initialize handlers
-	coreHandler := handlers.NewCoreHandler(client)
-	customerHandler := handlers.NewCustomerHandler(client)
-	transactionHandler := handlers.NewTransactionHandler(client)
-	transferHandler := handlers.NewTransferHandler(client)
-	planHandler := handlers.NewPlanHandler(client)
-	subscriptionHandler := handlers.NewSubscriptionHandler(client)
-	bankHandler := handlers.NewBankHandler(client)
-	subAccountHandler := handlers.NewSubAccountHandler(client)
-	invoiceHandler := handlers.NewInvoiceHandler(client)
-	verdictHandler := handlers.NewVerdictHandler()
-	recipientHandler := handlers.NewRecipientHandler(client)
-	expenseHandler := handlers.NewExpenseHandler()
-	budgetHandler := handlers.NewBudgetHandler()
-	goalHandler := handlers.NewGoalHandler()
-	serviceProviderHandler := handlers.NewServiceProviderHandler()
-
-	// Routes
-	r.Route("/api/v1", func(r chi.Router) {
-		// Core routes
-		r.Post("/balance", coreHandler.CheckBalance)
-
-		// Customer routes
-		r.Post("/customers/create", customerHandler.Create)
-		r.Post("/customers/list", customerHandler.List)
-
-		// Transaction routes
-		r.Post("/transactions/initialize", transactionHandler.Initialize)
-		r.Post("/transactions/verify", transactionHandler.Verify)
-		r.Post("/transactions/list", transactionHandler.List)
-
-		// Transfer routes
-		r.Post("/transfers/recipient/create", transferHandler.CreateRecipient)
-		r.Post("/transfers/initiate", transferHandler.Initiate)
-
-		// Plan routes
-		r.Post("/plans/list", planHandler.List)
-
-		// Subscription routes
-		r.Post("/subscriptions/list", subscriptionHandler.List)
-
-		// Bank routes
-		r.Post("/banks/list", bankHandler.List)
-		r.Post("/banks/resolve", bankHandler.ResolveAccount)
-
-		// SubAccount routes
-		r.Post("/subaccounts/list", subAccountHandler.List)
-
-		// Invoice routes
-		r.Post("/invoices/create", invoiceHandler.Create)
-		r.Post("/invoices/list", invoiceHandler.List)
-		r.Post("/invoices/get/{id_or_code}", invoiceHandler.Get)
-		r.Post("/invoices/verify/{code}", invoiceHandler.Verify)
-
-		// Verdict routes (credit check / affordability)
-		r.Post("/verdict/check", verdictHandler.CheckAffordability)
-		r.Get("/verdict/profile", verdictHandler.GetFinancialProfile)
-		r.Get("/verdict/profiles", verdictHandler.ListProfiles)
-
-		// Recipient routes (transfer recipients)
-		r.Post("/recipients/create", recipientHandler.Create)
-		r.Get("/recipients/list", recipientHandler.List)
-		r.Get("/recipients/get", recipientHandler.Get)
-		r.Get("/recipients/search", recipientHandler.Search)
-
-		// Expense routes
-		r.Post("/expenses/create", expenseHandler.Create)
-		r.Post("/expenses/list", expenseHandler.List)
-		r.Get("/expenses/get/{id}", expenseHandler.Get)
-		r.Put("/expenses/update/{id}", expenseHandler.Update)
-
-		// Budget routes
-		r.Post("/budgets/create", budgetHandler.Create)
-		r.Post("/budgets/list", budgetHandler.List)
-		r.Get("/budgets/{id}", budgetHandler.Get)
-		r.Put("/budgets/{id}", budgetHandler.Update)
-		r.Get("/budgets/{id}/check/{amount}", budgetHandler.CheckLimit)
-		r.Get("/budgets/active", budgetHandler.GetActiveBudgets)
-
-		// Goal routes
-		r.Post("/goals/create", goalHandler.Create)
-		r.Post("/goals/list", goalHandler.List)
-		r.Get("/goals/{id}", goalHandler.Get)
-		r.Put("/goals/{id}", goalHandler.Update)
-		r.Delete("/goals/{id}", goalHandler.Delete)
-
-		// Service Provider routes
-		r.Get("/service_providers", serviceProviderHandler.List)
-	})
+	// API routes
+	r.Mount("/api/v1", NewAPIRouter(client))
 
 	// Health check endpoint
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
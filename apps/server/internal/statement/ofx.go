@@ -0,0 +1,103 @@
+package statement
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+var (
+	ofxTransaction = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	ofxCurrency    = regexp.MustCompile(`(?i)<CURDEF>\s*([A-Za-z]{3})`)
+)
+
+// ofxField reads one field from a transaction. OFX 1.x is SGML and leaves
+// fields unclosed, so a value runs to the next tag or line end.
+func ofxField(block, name string) string {
+	re := regexp.MustCompile(`(?i)<` + name + `>([^<\r\n]*)`)
+	if m := re.FindStringSubmatch(block); m != nil {
+		return strings.TrimSpace(html.UnescapeString(m[1]))
+	}
+	return ""
+}
+
+// ParseOFX reads the bank transactions from an OFX (1.x SGML or 2.x XML) file.
+// Each transaction takes the CURDEF of the statement it is in, falling back to currency.
+func ParseOFX(data []byte, currency money.Currency) ([]Line, error) {
+	text := string(data)
+	currency = currency.OrDefault()
+
+	// A file may hold one statement per currency; remember where each one starts
+	type currencyAt struct {
+		offset   int
+		currency money.Currency
+	}
+	var currencies []currencyAt
+	for _, m := range ofxCurrency.FindAllStringSubmatchIndex(text, -1) {
+		parsed, err := money.ParseCurrency(text[m[2]:m[3]])
+		if err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, currencyAt{offset: m[0], currency: parsed})
+	}
+
+	blocks := ofxTransaction.FindAllStringSubmatchIndex(text, -1)
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no transactions found in OFX file")
+	}
+
+	lines := make([]Line, 0, len(blocks))
+	for i, m := range blocks {
+		block := text[m[2]:m[3]]
+		lineCurrency := currency
+		for _, c := range currencies {
+			if c.offset < m[0] {
+				lineCurrency = c.currency
+			}
+		}
+		date, err := parseOFXDate(ofxField(block, "DTPOSTED"))
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		amount, negative, ok, err := ParseAmount(ofxField(block, "TRNAMT"), lineCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		if !ok {
+			continue
+		}
+
+		name, memo := ofxField(block, "NAME"), ofxField(block, "MEMO")
+		description := memo
+		if description == "" {
+			description = name
+		}
+		lines = append(lines, Line{
+			Row:          i + 1,
+			Date:         date,
+			Amount:       amount,
+			Currency:     lineCurrency,
+			Debit:        negative,
+			Description:  description,
+			Reference:    ofxField(block, "FITID"),
+			Counterparty: name,
+		})
+	}
+	return lines, nil
+}
+
+// parseOFXDate reads YYYYMMDD with an optional time and timezone, keeping the day
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED %q", value)
+	}
+	t, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED %q", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,248 @@
+package statement
+
+import (
+	"fmt"
+	"strings"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+// headerSearchRows is how far down a CSV the header row is looked for
+const headerSearchRows = 30
+
+// Profile maps a bank's CSV columns onto statement lines. Each field lists the
+// header names the column may have; names are matched ignoring case and spacing.
+// Amounts come either from one signed Amount column or from separate Debit and
+// Credit columns; when a CSV has both, Debit and Credit win.
+type Profile struct {
+	Name         string   `json:"name"`
+	Bank         string   `json:"bank,omitempty"`
+	Date         []string `json:"date"`
+	Description  []string `json:"description"`
+	Reference    []string `json:"reference,omitempty"`
+	Counterparty []string `json:"counterparty,omitempty"`
+	Amount       []string `json:"amount,omitempty"`
+	Debit        []string `json:"debit,omitempty"`
+	Credit       []string `json:"credit,omitempty"`
+	Currency     []string `json:"currency,omitempty"`
+	DateFormats  []string `json:"date_formats,omitempty"`
+	// DebitsPositive means a single Amount column shows money out as positive
+	DebitsPositive bool   `json:"debits_positive,omitempty"`
+	Delimiter      string `json:"delimiter,omitempty"`
+	BuiltIn        bool   `json:"built_in,omitempty"`
+}
+
+// Validate checks the profile names the columns it needs
+func (p Profile) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(p.Date) == 0 {
+		return fmt.Errorf("date column is required")
+	}
+	if len(p.Description) == 0 {
+		return fmt.Errorf("description column is required")
+	}
+	if len(p.Amount) == 0 && len(p.Debit) == 0 {
+		return fmt.Errorf("an amount or debit column is required")
+	}
+	if len([]rune(p.Delimiter)) > 1 {
+		return fmt.Errorf("delimiter must be a single character")
+	}
+	return nil
+}
+
+// Profiles are the built-in profiles. Bank profiles come first so detection
+// prefers them over the generic one.
+var Profiles = []Profile{
+	{
+		Name: "gtbank", Bank: "Guaranty Trust Bank",
+		Date: []string{"Trans. Date", "Trans Date"}, Description: []string{"Remarks", "Narration"},
+		Reference: []string{"Reference"}, Debit: []string{"Debits"}, Credit: []string{"Credits"},
+		DateFormats: []string{"02-Jan-2006", "02/01/2006", "2006-01-02"},
+	},
+	{
+		Name: "access", Bank: "Access Bank",
+		Date: []string{"Posted Date"}, Description: []string{"Description", "Narration"},
+		Reference: []string{"Reference"}, Debit: []string{"Debit"}, Credit: []string{"Credit"},
+		DateFormats: []string{"02-Jan-06", "02-Jan-2006", "02/01/2006"},
+	},
+	{
+		Name: "zenith", Bank: "Zenith Bank",
+		Date: []string{"Date Posted"}, Description: []string{"Description"},
+		Reference: []string{"Reference"}, Debit: []string{"Debit"}, Credit: []string{"Credit"},
+		DateFormats: []string{"02/01/2006", "02/01/2006 15:04:05", "02-Jan-2006"},
+	},
+	{
+		Name: "firstbank", Bank: "First Bank of Nigeria",
+		Date: []string{"TransDate", "Trans Date"}, Description: []string{"Narration", "Details"},
+		Reference: []string{"Reference", "Ref"}, Debit: []string{"Debit"}, Credit: []string{"Credit"},
+		DateFormats: []string{"02-Jan-2006", "02/01/2006"},
+	},
+	{
+		Name: "uba", Bank: "United Bank for Africa",
+		Date: []string{"Tran Date"}, Description: []string{"Narration"},
+		Reference: []string{"Chq. No", "Cheque No"}, Debit: []string{"Debit"}, Credit: []string{"Credit"},
+		DateFormats: []string{"02-Jan-2006", "02/01/2006"},
+	},
+	{
+		Name: "kuda", Bank: "Kuda",
+		Date: []string{"Date/Time"}, Description: []string{"Description"},
+		Counterparty: []string{"To / From"}, Debit: []string{"Money Out"}, Credit: []string{"Money In"},
+		DateFormats: []string{"02/01/06 15:04:05", "02/01/2006 15:04:05", "02/01/2006"},
+	},
+	{
+		Name:         "generic",
+		Date:         []string{"Date", "Transaction Date", "Trans Date", "Posted Date", "Value Date"},
+		Description:  []string{"Description", "Narration", "Details", "Remarks", "Memo"},
+		Reference:    []string{"Reference", "Ref", "Transaction Reference"},
+		Counterparty: []string{"Payee", "Beneficiary", "Counterparty", "To / From"},
+		Amount:       []string{"Amount"},
+		Debit:        []string{"Debit", "Debits", "Withdrawal", "Withdrawals", "Money Out"},
+		Credit:       []string{"Credit", "Credits", "Deposit", "Lodgement", "Money In"},
+		Currency:     []string{"Currency"},
+	},
+}
+
+func init() {
+	for i := range Profiles {
+		Profiles[i].BuiltIn = true
+	}
+}
+
+// FindProfile returns the profile with the given name
+func FindProfile(profiles []Profile, name string) (*Profile, bool) {
+	for i := range profiles {
+		if strings.EqualFold(profiles[i].Name, strings.TrimSpace(name)) {
+			p := profiles[i]
+			return &p, true
+		}
+	}
+	return nil, false
+}
+
+// detect returns the first profile whose header row appears in records
+func detect(records []record, profiles []Profile) (*Profile, error) {
+	for i := range profiles {
+		if _, _, ok := profiles[i].header(records); ok {
+			p := profiles[i]
+			return &p, nil
+		}
+	}
+	return nil, ErrNoProfile
+}
+
+// columns are the indexes of a profile's fields in one header row, -1 when absent
+type columns struct {
+	date, description, reference, counterparty, amount, debit, credit, currency int
+}
+
+// header finds the header row and the profile's columns in it
+func (p Profile) header(records []record) (int, columns, bool) {
+	for row := 0; row < len(records) && row < headerSearchRows; row++ {
+		index := map[string]int{}
+		for i, cell := range records[row].fields {
+			key := normalizeHeader(cell)
+			if _, dup := index[key]; !dup && key != "" {
+				index[key] = i
+			}
+		}
+		find := func(names []string) int {
+			for _, name := range names {
+				if i, ok := index[normalizeHeader(name)]; ok {
+					return i
+				}
+			}
+			return -1
+		}
+		cols := columns{
+			date:         find(p.Date),
+			description:  find(p.Description),
+			reference:    find(p.Reference),
+			counterparty: find(p.Counterparty),
+			amount:       find(p.Amount),
+			debit:        find(p.Debit),
+			credit:       find(p.Credit),
+			currency:     find(p.Currency),
+		}
+		if cols.date >= 0 && cols.description >= 0 && (cols.debit >= 0 || cols.amount >= 0) {
+			return row, cols, true
+		}
+	}
+	return 0, columns{}, false
+}
+
+func normalizeHeader(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.TrimSpace(name))), " ")
+}
+
+// parse turns the rows below the header into lines
+func (p Profile) parse(records []record, currency money.Currency) ([]Line, error) {
+	start, cols, ok := p.header(records)
+	if !ok {
+		return nil, fmt.Errorf("%w: profile %q", ErrNoProfile, p.Name)
+	}
+	currency = currency.OrDefault()
+
+	cell := func(rec record, i int) string {
+		if i < 0 || i >= len(rec.fields) {
+			return ""
+		}
+		return strings.TrimSpace(rec.fields[i])
+	}
+
+	var lines []Line
+	for r := start + 1; r < len(records); r++ {
+		rec := records[r]
+		date, err := ParseDate(cell(rec, cols.date), p.DateFormats)
+		if err != nil {
+			continue // balance, total and footer rows
+		}
+
+		lineCurrency := currency
+		if code := cell(rec, cols.currency); code != "" {
+			if lineCurrency, err = money.ParseCurrency(code); err != nil {
+				return nil, fmt.Errorf("row %d: %w", rec.line, err)
+			}
+		}
+
+		line := Line{
+			Row:          rec.line,
+			Date:         date,
+			Currency:     lineCurrency,
+			Description:  cell(rec, cols.description),
+			Reference:    cell(rec, cols.reference),
+			Counterparty: cell(rec, cols.counterparty),
+		}
+
+		if cols.debit >= 0 {
+			debit, _, hasDebit, err := ParseAmount(cell(rec, cols.debit), lineCurrency)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", rec.line, err)
+			}
+			credit, _, hasCredit, err := ParseAmount(cell(rec, cols.credit), lineCurrency)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", rec.line, err)
+			}
+			switch {
+			case hasDebit:
+				line.Amount, line.Debit = debit, true
+			case hasCredit:
+				line.Amount = credit
+			default:
+				continue
+			}
+		} else {
+			amount, negative, has, err := ParseAmount(cell(rec, cols.amount), lineCurrency)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", rec.line, err)
+			}
+			if !has {
+				continue
+			}
+			line.Amount, line.Debit = amount, negative != p.DebitsPositive
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
@@ -0,0 +1,241 @@
+// Package statement reads bank statement exports into transaction lines.
+//
+// CSV exports are read through a Profile that names the columns a bank uses
+// for the date, description, reference and amounts. Nigerian banks put a few
+// lines of account details above the table, so the header row is found by
+// looking for the profile's columns rather than assumed to be first. OFX files
+// need no profile.
+//
+// DESIGN DECISIONS:
+//   - Amounts are whole minor units and always positive; Debit says which way money moved
+//   - Dates are day-first (02/01/2026 is 2 January), as Nigerian banks print them
+//   - Rows without a usable date and amount (opening balances, totals) are skipped, not errors
+//   - Every line gets a stable key from its own contents, so importing the same
+//     statement twice produces the same keys and duplicates are easy to spot
+package statement
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+// Formats
+const (
+	FormatCSV = "csv"
+	FormatOFX = "ofx"
+)
+
+// ErrNoProfile is returned when no profile matches a CSV's columns
+var ErrNoProfile = errors.New("no import profile matches the statement's columns")
+
+// Line is one transaction from a statement
+type Line struct {
+	Row          int            `json:"row"`
+	Date         time.Time      `json:"date"`
+	Amount       money.Amount   `json:"amount"`
+	Currency     money.Currency `json:"currency,omitempty"`
+	Debit        bool           `json:"debit"`
+	Description  string         `json:"description"`
+	Reference    string         `json:"reference,omitempty"`
+	Counterparty string         `json:"counterparty,omitempty"`
+}
+
+// Money returns the line's amount in its currency
+func (l Line) Money() money.Money {
+	return money.New(int64(l.Amount), l.Currency)
+}
+
+// Keys returns a stable key for each line. Identical lines (two ₦500 coffees on
+// the same day) are told apart by the order they appear in.
+func Keys(lines []Line) []string {
+	seen := map[string]int{}
+	keys := make([]string, len(lines))
+	for i, l := range lines {
+		base := strings.Join([]string{
+			l.Date.Format("2006-01-02"),
+			strconv.FormatInt(int64(l.Amount), 10),
+			string(l.Currency),
+			strconv.FormatBool(l.Debit),
+			strings.ToLower(l.Reference),
+			strings.ToLower(l.Description),
+		}, "|")
+		n := seen[base]
+		seen[base]++
+		sum := sha1.Sum([]byte(base + "|" + strconv.Itoa(n)))
+		keys[i] = "STMT_" + strings.ToUpper(hex.EncodeToString(sum[:6]))
+	}
+	return keys
+}
+
+// DetectFormat guesses the format from the file's contents
+func DetectFormat(data []byte) string {
+	head := bytes.ToUpper(data[:min(len(data), 1024)])
+	if bytes.Contains(head, []byte("OFXHEADER")) || bytes.Contains(head, []byte("<OFX>")) {
+		return FormatOFX
+	}
+	return FormatCSV
+}
+
+// Parse reads a statement. For CSV a nil profile means detect one from profiles.
+// It returns the profile used (nil for OFX).
+func Parse(data []byte, format string, profile *Profile, profiles []Profile, currency money.Currency) ([]Line, *Profile, error) {
+	if format == "" {
+		format = DetectFormat(data)
+	}
+	switch format {
+	case FormatOFX:
+		lines, err := ParseOFX(data, currency)
+		return lines, nil, err
+	case FormatCSV:
+		records, err := readCSV(data, profile)
+		if err != nil {
+			return nil, nil, err
+		}
+		if profile == nil {
+			if profile, err = detect(records, profiles); err != nil {
+				return nil, nil, err
+			}
+		}
+		lines, err := profile.parse(records, currency)
+		return lines, profile, err
+	}
+	return nil, nil, fmt.Errorf("format must be csv or ofx, got %q", format)
+}
+
+// readCSV reads every record, sniffing the delimiter when the profile doesn't set one
+func readCSV(data []byte, profile *Profile) ([]record, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	delimiter := ','
+	if profile != nil && profile.Delimiter != "" {
+		delimiter = []rune(profile.Delimiter)[0]
+	} else {
+		delimiter = sniffDelimiter(data)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	var records []record
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record{line: line, fields: fields})
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("statement is empty")
+	}
+	return records, nil
+}
+
+// record is one CSV row and the file line it starts on
+type record struct {
+	line   int
+	fields []string
+}
+
+// sniffDelimiter picks whichever of , ; or tab appears most in the first lines
+func sniffDelimiter(data []byte) rune {
+	sample := data[:min(len(data), 4096)]
+	best, bestCount := ',', 0
+	for _, d := range []rune{',', ';', '\t'} {
+		if n := bytes.Count(sample, []byte(string(d))); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+// ParseAmount reads a printed amount such as "₦1,234.50", "(500.00)", "-20",
+// "NGN 1,000" or "1,500.00 DR" into minor units. negative is true for a minus
+// sign, brackets or a DR suffix. An empty cell returns ok false.
+func ParseAmount(text string, currency money.Currency) (amount money.Amount, negative bool, ok bool, err error) {
+	s := strings.ToUpper(strings.TrimSpace(text))
+	if s == "" || s == "-" {
+		return 0, false, false, nil
+	}
+	if strings.HasSuffix(s, "DR") {
+		negative, s = true, strings.TrimSpace(strings.TrimSuffix(s, "DR"))
+	} else if strings.HasSuffix(s, "CR") {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "CR"))
+	}
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative, s = true, strings.Trim(s, "()")
+	}
+	if strings.HasPrefix(s, "-") {
+		negative, s = true, strings.TrimSpace(s[1:])
+	}
+	for _, c := range money.Currencies() {
+		s = strings.TrimPrefix(s, string(c))
+		s = strings.TrimPrefix(s, strings.ToUpper(c.Symbol()))
+	}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "N") // "N1,500.00" is common for naira
+	if strings.HasPrefix(s, "-") {
+		negative, s = true, strings.TrimSpace(s[1:])
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+	s = strings.NewReplacer(",", "", " ", "", " ", "").Replace(s)
+
+	major, perr := strconv.ParseFloat(s, 64)
+	if perr != nil {
+		return 0, false, false, fmt.Errorf("invalid amount %q", text)
+	}
+	if major < 0 {
+		negative, major = true, -major
+	}
+	return money.FromMajor(major, currency).Amount, negative, major != 0, nil
+}
+
+// DefaultDateFormats are tried when a profile doesn't list its own
+var DefaultDateFormats = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"2/1/2006",
+	"02-01-2006",
+	"02-Jan-2006",
+	"2-Jan-2006",
+	"02-Jan-06",
+	"02 Jan 2006",
+	"2 Jan 2006",
+	"Jan 02, 2006",
+	"02/01/06",
+	"2006-01-02 15:04:05",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/01/06 15:04:05",
+	"02-Jan-2006 15:04:05",
+	"02 Jan 2006 15:04",
+	time.RFC3339,
+}
+
+// ParseDate reads a date with the first format that fits
+func ParseDate(text string, formats []string) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if len(formats) == 0 {
+		formats = DefaultDateFormats
+	}
+	for _, layout := range formats {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", text)
+}
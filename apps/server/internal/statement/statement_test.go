@@ -0,0 +1,152 @@
+package statement
+
+import (
+	"testing"
+	"time"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		text     string
+		want     money.Amount
+		negative bool
+		ok       bool
+	}{
+		{"₦1,234.50", 123450, false, true},
+		{"(500.00)", 50000, true, true},
+		{"-20", 2000, true, true},
+		{"-₦20.10", 2010, true, true},
+		{"NGN 1,000", 100000, false, true},
+		{"N1,500.00", 150000, false, true},
+		{"1,500.00 DR", 150000, true, true},
+		{"250.00CR", 25000, false, true},
+		{"", 0, false, false},
+		{"0.00", 0, false, false},
+	}
+	for _, tt := range tests {
+		got, negative, ok, err := ParseAmount(tt.text, money.NGN)
+		if err != nil || got != tt.want || negative != tt.negative || ok != tt.ok {
+			t.Errorf("ParseAmount(%q) = %d, %v, %v, %v", tt.text, got, negative, ok, err)
+		}
+	}
+	if _, _, _, err := ParseAmount("abc", money.NGN); err == nil {
+		t.Error("Expected an error for a non-numeric amount")
+	}
+}
+
+func TestDetectsBankProfileBelowPreamble(t *testing.T) {
+	csv := "Account Name,ADA OBI\n" +
+		"Account Number,0123456789\n" +
+		"\n" +
+		"Trans. Date,Value Date,Reference,Debits,Credits,Balance,Remarks\n" +
+		"05-Jan-2026,05-Jan-2026,FT001,\"5,000.00\",,\"95,000.00\",TRF TO CHIDI EZE\n" +
+		"06-JAN-2026,06-Jan-2026,FT002,,\"20,000.00\",\"115,000.00\",SALARY\n" +
+		"Total,,,\"5,000.00\",\"20,000.00\",,\n"
+
+	lines, profile, err := Parse([]byte(csv), "", nil, Profiles, money.NGN)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if profile.Name != "gtbank" {
+		t.Errorf("Expected gtbank, got %s", profile.Name)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	want := Line{Row: 5, Date: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), Amount: 500000, Currency: money.NGN, Debit: true, Description: "TRF TO CHIDI EZE", Reference: "FT001"}
+	if lines[0] != want {
+		t.Errorf("Unexpected line %+v", lines[0])
+	}
+	if lines[1].Debit || lines[1].Amount != 2000000 {
+		t.Errorf("Expected a ₦20,000 credit, got %+v", lines[1])
+	}
+}
+
+func TestGenericSignedAmountAndSemicolons(t *testing.T) {
+	csv := "date;description;amount;currency\n" +
+		"2026-02-01;Netflix;-4400.00;NGN\n" +
+		"02/02/2026;Refund;1200;USD\n"
+
+	lines, profile, err := Parse([]byte(csv), FormatCSV, nil, Profiles, money.NGN)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if profile.Name != "generic" || len(lines) != 2 {
+		t.Fatalf("Expected 2 lines from generic, got %d from %s", len(lines), profile.Name)
+	}
+	if !lines[0].Debit || lines[0].Amount != 440000 {
+		t.Errorf("Expected a ₦4,400 debit, got %+v", lines[0])
+	}
+	if lines[1].Debit || lines[1].Currency != money.USD || !lines[1].Date.Equal(time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected second line %+v", lines[1])
+	}
+
+	if _, _, err := Parse([]byte("foo,bar\n1,2\n"), FormatCSV, nil, Profiles, money.NGN); err == nil {
+		t.Error("Expected no profile to match unknown columns")
+	}
+}
+
+func TestParseOFX(t *testing.T) {
+	ofx := `OFXHEADER:100
+DATA:OFXSGML
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>NGN
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260310120000[+1:WAT]
+<TRNAMT>-15000.00
+<FITID>TX100
+<NAME>IKEDC
+<MEMO>Prepaid meter token
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260311
+<TRNAMT>50000
+<FITID>TX101
+<NAME>Client
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+
+	if DetectFormat([]byte(ofx)) != FormatOFX {
+		t.Fatal("Expected OFX to be detected")
+	}
+	lines, _, err := Parse([]byte(ofx), "", nil, nil, money.USD)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	want := Line{Row: 1, Date: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), Amount: 1500000, Currency: money.NGN, Debit: true, Description: "Prepaid meter token", Reference: "TX100", Counterparty: "IKEDC"}
+	if lines[0] != want {
+		t.Errorf("Unexpected line %+v", lines[0])
+	}
+	if lines[1].Debit || lines[1].Description != "Client" {
+		t.Errorf("Unexpected second line %+v", lines[1])
+	}
+}
+
+func TestKeysAreStableAndDistinguishRepeats(t *testing.T) {
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	coffee := Line{Date: day, Amount: 50000, Currency: money.NGN, Debit: true, Description: "Coffee"}
+	lines := []Line{coffee, coffee, {Date: day, Amount: 50000, Currency: money.NGN, Debit: true, Description: "Tea"}}
+
+	keys := Keys(lines)
+	if keys[0] == keys[1] || keys[0] == keys[2] {
+		t.Errorf("Expected distinct keys, got %v", keys)
+	}
+	again := Keys(lines)
+	for i := range keys {
+		if keys[i] != again[i] {
+			t.Errorf("Expected key %d to be stable", i)
+		}
+	}
+}
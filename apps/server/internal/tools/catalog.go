@@ -0,0 +1,1148 @@
+package tools
+
+import "net/http"
+
+// Default returns the tools backed by the existing handlers.
+// Argument names match the handlers' request fields; amounts are in kobo.
+func Default() []Tool {
+	return []Tool{
+		// Core
+		{
+			Name:        "get_balance",
+			Description: "Check the current account balance from Paystack. Returns available balance and currency information.",
+			InputSchema: object(props{}),
+			Method:      http.MethodPost,
+			Path:        "/balance",
+			Summarize:   summarizeBalance,
+		},
+
+		// Customers
+		{
+			Name:        "create_customer",
+			Description: "Create a Paystack customer that can be invoiced or charged.",
+			InputSchema: object(props{
+				"email":      str("Customer email address"),
+				"first_name": str("Customer first name"),
+				"last_name":  str("Customer last name"),
+				"phone":      str("Customer phone number"),
+			}, "email"),
+			Method: http.MethodPost,
+			Path:   "/customers/create",
+		},
+		{
+			Name:        "list_customers",
+			Description: "List Paystack customers.",
+			InputSchema: object(props{
+				"count":  integer("Number of customers to return (0 for all)"),
+				"offset": integer("Pagination offset"),
+			}),
+			Method: http.MethodPost,
+			Path:   "/customers/list",
+		},
+
+		// Transactions
+		{
+			Name:        "initialize_transaction",
+			Description: "Initialize a payment transaction and get a checkout URL for the customer.",
+			InputSchema: object(props{
+				"email":        str("Customer email address"),
+				"amount":       integer("Amount in minor units (kobo for NGN)"),
+				"reference":    str("Unique transaction reference (optional)"),
+				"callback_url": str("URL to redirect to after payment (optional)"),
+				"currency":     currency("Currency code (default NGN)"),
+			}, "email", "amount"),
+			Method: http.MethodPost,
+			Path:   "/transactions/initialize",
+		},
+		{
+			Name:        "verify_transaction",
+			Description: "Verify the status of a payment transaction by reference.",
+			InputSchema: object(props{
+				"reference": str("Transaction reference"),
+			}, "reference"),
+			Method: http.MethodPost,
+			Path:   "/transactions/verify",
+		},
+		{
+			Name:        "list_transactions",
+			Description: "List payment transactions from Paystack.",
+			InputSchema: object(props{
+				"count":  integer("Number of transactions to return (0 for all)"),
+				"offset": integer("Pagination offset"),
+			}),
+			Method: http.MethodPost,
+			Path:   "/transactions/list",
+		},
+		{
+			Name:        "search_transactions",
+			Description: "Search incoming payment transactions in the local ledger (synced from Paystack) without calling Paystack.",
+			InputSchema: object(props{
+				"q":          str("Text to match against reference, customer email or customer code"),
+				"status":     enum("Transaction status", "success", "failed", "abandoned", "pending", "reversed"),
+				"channel":    str("Payment channel (e.g., 'card', 'bank', 'bank_transfer', 'ussd')"),
+				"customer":   str("Customer email or customer code"),
+				"min_amount": integer("Minimum amount in kobo"),
+				"max_amount": integer("Maximum amount in kobo"),
+				"from":       str("Start date (YYYY-MM-DD or ISO datetime)"),
+				"to":         str("End date, inclusive (YYYY-MM-DD or ISO datetime)"),
+				"limit":      integer("Maximum transactions to return (default 50)"),
+			}),
+			Method:    http.MethodGet,
+			Path:      "/transactions/local",
+			Summarize: summarizeLocalTransactions,
+		},
+		{
+			Name:        "sync_transactions",
+			Description: "Pull new transactions from Paystack into the local ledger now.",
+			InputSchema: object(props{
+				"full": boolean("Re-sync the whole history instead of only recent transactions"),
+			}),
+			Method: http.MethodPost,
+			Path:   "/transactions/sync",
+		},
+
+		// Transfers
+		{
+			Name:        "send_money",
+			Description: "Initiate a money transfer to a recipient. Always call search_recipients first to resolve the recipient code. The first call returns a preview and confirmation_token; read the preview to the user and call again with only the confirmation_token once they agree.",
+			InputSchema: object(props{
+				"recipient": str("Paystack recipient code (e.g., 'RCP_abc123')"),
+				"amount":    integer("Amount to send in minor units (multiply NGN by 100)"),
+				"currency":  currency("Currency code (default NGN)"),
+				"reason":    str("Transfer reason/narration"),
+				"source":    enum("Transfer source", "balance"),
+
+				"confirmation_token": str("Token from the preview response; executes the previewed transfer"),
+			}, "recipient", "amount", "source"),
+			Method:    http.MethodPost,
+			Path:      "/transfers/initiate",
+			Summarize: summarizeTransfer,
+		},
+		{
+			Name:        "pay_group",
+			Description: "Pay several recipients at once: every member of a group at their usual amount, and/or a list of recipients named by code, name or alias. Names that don't match one recipient are refused with a question to ask. The first call returns a preview of every payment and a confirmation_token; call again with only the confirmation_token once the user agrees.",
+			InputSchema: object(props{
+				"group":  str("Group name or ID; every member is paid"),
+				"amount": integer("Pay each group member this instead of their usual amount, in minor units"),
+				"transfers": array("Payments to individual recipients", object(props{
+					"recipient": str("Recipient code, name or alias"),
+					"amount":    integer("Amount in minor units"),
+					"currency":  currency("Currency code (default NGN)"),
+					"reason":    str("Narration for this payment"),
+				}, "recipient", "amount")),
+				"reason": str("Narration for payments without their own"),
+				"source": enum("Transfer source", "balance"),
+
+				"confirmation_token": str("Token from the preview response; sends the previewed payments"),
+			}),
+			Method:    http.MethodPost,
+			Path:      "/transfers/bulk",
+			Summarize: summarizeBulkTransfer,
+		},
+		{
+			Name:        "schedule_transfer",
+			Description: "Schedule a one-off transfer for a future date or time, e.g. 'send 20k to Tunde on Friday' (work out the date yourself). Balance and limits are checked again when it is sent. The first call returns a preview and confirmation_token; call again with only the confirmation_token once the user agrees.",
+			InputSchema: object(props{
+				"recipient":  str("Paystack recipient code from search_recipients"),
+				"amount":     integer("Amount to send in minor units (multiply NGN by 100)"),
+				"currency":   currency("Currency code (default NGN)"),
+				"reason":     str("Transfer reason/narration"),
+				"execute_at": str("When to send: YYYY-MM-DD (start of that day, UTC) or an ISO datetime"),
+
+				"confirmation_token": str("Token from the preview response; schedules the previewed transfer"),
+			}, "recipient", "amount", "execute_at"),
+			Method:    http.MethodPost,
+			Path:      "/transfers/schedule",
+			Summarize: summarizeScheduledTransfer,
+		},
+		{
+			Name:        "list_scheduled_transfers",
+			Description: "List transfers scheduled for later, soonest first.",
+			InputSchema: object(props{
+				"status": enum("Filter by status", "scheduled", "sending", "sent", "failed", "needs_review", "cancelled"),
+			}),
+			Method:    http.MethodGet,
+			Path:      "/transfers/scheduled",
+			Summarize: summarizeScheduledTransferList,
+		},
+		{
+			Name:        "cancel_scheduled_transfer",
+			Description: "Cancel a scheduled transfer that hasn't been sent yet.",
+			InputSchema: object(props{
+				"id": integer("Scheduled transfer ID"),
+			}, "id"),
+			Method:    http.MethodPost,
+			Path:      "/transfers/scheduled/{id}/cancel",
+			Summarize: summarizeScheduledTransfer,
+		},
+		{
+			Name:        "reschedule_transfer",
+			Description: "Move a scheduled, failed or needs_review transfer to a new date or time. A needs_review transfer is checked on Paystack before anything is sent.",
+			InputSchema: object(props{
+				"id":         integer("Scheduled transfer ID"),
+				"execute_at": str("New send time: YYYY-MM-DD or an ISO datetime"),
+			}, "id", "execute_at"),
+			Method:    http.MethodPost,
+			Path:      "/transfers/scheduled/{id}/reschedule",
+			Summarize: summarizeScheduledTransfer,
+		},
+
+		// Plans, subscriptions and subaccounts
+		{
+			Name:        "list_plans",
+			Description: "List subscription plans.",
+			InputSchema: object(props{
+				"count":  integer("Number of plans to return (0 for all)"),
+				"offset": integer("Pagination offset"),
+			}),
+			Method: http.MethodPost,
+			Path:   "/plans/list",
+		},
+		{
+			Name:        "list_subscriptions",
+			Description: "List customer subscriptions.",
+			InputSchema: object(props{
+				"count":  integer("Number of subscriptions to return (0 for all)"),
+				"offset": integer("Pagination offset"),
+			}),
+			Method: http.MethodPost,
+			Path:   "/subscriptions/list",
+		},
+		{
+			Name:        "list_subaccounts",
+			Description: "List settlement subaccounts.",
+			InputSchema: object(props{
+				"count":  integer("Number of subaccounts to return (0 for all)"),
+				"offset": integer("Pagination offset"),
+			}),
+			Method: http.MethodPost,
+			Path:   "/subaccounts/list",
+		},
+
+		// Banks
+		{
+			Name:        "list_banks",
+			Description: "Get the list of Nigerian banks with their bank codes. Use this when creating recipients or resolving accounts.",
+			InputSchema: object(props{}),
+			Method:      http.MethodPost,
+			Path:        "/banks/list",
+		},
+		{
+			Name:        "resolve_bank_account",
+			Description: "Verify a bank account number and get the account holder's name. create_recipient does this itself; use it to read the name back to the user first.",
+			InputSchema: object(props{
+				"account_number": str("10-digit bank account number"),
+				"bank_code":      str("Bank code (get from list_banks)"),
+			}, "account_number", "bank_code"),
+			Method:    http.MethodPost,
+			Path:      "/banks/resolve",
+			Summarize: summarizeResolvedAccount,
+		},
+
+		// Invoices
+		{
+			Name:        "create_payment_request",
+			Description: "Create an invoice/payment request for a customer. The first call returns a preview and confirmation_token; call again with only the confirmation_token once the user agrees.",
+			InputSchema: object(props{
+				"customer":          str("Customer email or customer code"),
+				"amount":            integer("Invoice amount in minor units (kobo for NGN)"),
+				"description":       str("Invoice description"),
+				"due_date":          str("Due date in YYYY-MM-DD format (optional)"),
+				"currency":          currency("Currency code (default NGN)"),
+				"send_notification": boolean("Email the invoice to the customer"),
+				"line_items": array("Invoice line items", object(props{
+					"name":     str("Line item name"),
+					"amount":   integer("Unit amount in kobo"),
+					"quantity": integer("Quantity"),
+				}, "name", "amount")),
+
+				"confirmation_token": str("Token from the preview response; sends the previewed payment request"),
+			}, "customer", "amount"),
+			Method:    http.MethodPost,
+			Path:      "/invoices/create",
+			Summarize: summarizePaymentRequest,
+		},
+		{
+			Name:        "list_invoices",
+			Description: "List cached invoices for a customer with optional status and date filters.",
+			InputSchema: object(props{
+				"customer_id": str("Customer email or customer code"),
+				"status":      str("Invoice status filter"),
+				"currency":    currency("Filter by currency"),
+				"from":        str("Start date (YYYY-MM-DD)"),
+				"to":          str("End date (YYYY-MM-DD)"),
+				"count":       integer("Number of invoices to return"),
+				"offset":      integer("Pagination offset"),
+			}, "customer_id"),
+			Method: http.MethodPost,
+			Path:   "/invoices/list",
+		},
+		{
+			Name:        "get_invoice",
+			Description: "Fetch a payment request from Paystack by ID or request code.",
+			InputSchema: object(props{
+				"id_or_code": str("Payment request ID or code (e.g., 'PRQ_abc123')"),
+			}, "id_or_code"),
+			Method: http.MethodPost,
+			Path:   "/invoices/get/{id_or_code}",
+		},
+		{
+			Name:        "verify_invoice",
+			Description: "Verify a payment request and refresh its cached status.",
+			InputSchema: object(props{
+				"code": str("Payment request code (e.g., 'PRQ_abc123')"),
+			}, "code"),
+			Method: http.MethodPost,
+			Path:   "/invoices/verify/{code}",
+		},
+
+		// Verdict
+		{
+			Name:        "check_affordability",
+			Description: "Check if a customer can afford a specific amount based on their credit profile. Returns verdict, risk level, and maximum affordable amount.",
+			InputSchema: object(props{
+				"email":  str("Customer email address"),
+				"amount": integer("Amount to check in minor units of the profile's currency (kobo for NGN)"),
+			}, "email", "amount"),
+			Method:    http.MethodPost,
+			Path:      "/verdict/check",
+			Summarize: summarizeReason,
+		},
+		{
+			Name:        "get_financial_profile",
+			Description: "Get the full credit profile for a customer.",
+			InputSchema: object(props{
+				"email": str("Customer email address"),
+			}, "email"),
+			Method: http.MethodGet,
+			Path:   "/verdict/profile",
+		},
+		{
+			Name:        "list_credit_profiles",
+			Description: "List all credit profiles.",
+			InputSchema: object(props{}),
+			Method:      http.MethodGet,
+			Path:        "/verdict/profiles",
+		},
+
+		// Recipients
+		{
+			Name:        "create_recipient",
+			Description: "Add a new transfer recipient. The server resolves the account and refuses it if the bank's account name doesn't match the name given; tell the user the bank's name and, only if they confirm it's the right account, call again with accept_account_name.",
+			InputSchema: object(props{
+				"type":                enum("Recipient type", "nuban"),
+				"name":                str("Name the user calls the recipient, as spoken"),
+				"account_number":      str("10-digit bank account number"),
+				"bank_code":           str("Bank code (get from list_banks)"),
+				"currency":            str("Currency code (default NGN)"),
+				"description":         str("Optional notes about this recipient"),
+				"aliases":             array("Other names the user calls this recipient, e.g. 'landlord'", str("Alias")),
+				"accept_account_name": boolean("Save even though the bank's account name doesn't match, after the user confirms"),
+			}, "type", "name", "account_number", "bank_code"),
+			Method: http.MethodPost,
+			Path:   "/recipients/create",
+		},
+		{
+			Name:        "list_recipients",
+			Description: "List all cached transfer recipients.",
+			InputSchema: object(props{
+				"include_deleted": boolean("Also list deleted recipients"),
+			}),
+			Method:    http.MethodGet,
+			Path:      "/recipients/list",
+			Summarize: summarizeRecipientList,
+		},
+		{
+			Name:        "get_recipient_details",
+			Description: "Get full details of a recipient by recipient code.",
+			InputSchema: object(props{
+				"recipient_code": str("Paystack recipient code (e.g., 'RCP_abc123')"),
+			}, "recipient_code"),
+			Method:    http.MethodGet,
+			Path:      "/recipients/get",
+			Summarize: summarizeRecipient,
+		},
+		{
+			Name:        "update_recipient",
+			Description: "Rename a recipient or change its notes. A new name is saved in Paystack too.",
+			InputSchema: object(props{
+				"recipient_code": str("Recipient code from resolve_recipient"),
+				"name":           str("New name"),
+				"description":    str("New notes about this recipient"),
+				"email":          str("Email address to save in Paystack"),
+			}, "recipient_code"),
+			Method:    http.MethodPut,
+			Path:      "/recipients/{recipient_code}",
+			Summarize: summarizeRecipient,
+		},
+		{
+			Name:        "delete_recipient",
+			Description: "Delete a recipient here and in Paystack. Past payments keep their record, but the recipient can no longer be paid; their aliases and group memberships are removed. Confirm with the user first.",
+			InputSchema: object(props{
+				"recipient_code": str("Recipient code from resolve_recipient"),
+			}, "recipient_code"),
+			Method: http.MethodDelete,
+			Path:   "/recipients/{recipient_code}",
+		},
+		{
+			Name:        "sync_recipients",
+			Description: "Import recipients created elsewhere in Paystack and pick up renames and deletions made there. Also runs automatically every hour.",
+			InputSchema: object(props{}),
+			Method:      http.MethodPost,
+			Path:        "/recipients/sync",
+			Summarize:   summarizeRecipientSync,
+		},
+		{
+			Name:        "search_recipients",
+			Description: "Search for transfer recipients by name, account number, or bank. Use this before transfers and expenses to resolve the recipient code.",
+			InputSchema: object(props{
+				"q":      str("Search query - recipient name, account number, or bank name"),
+				"limit":  integer("Maximum results (default 10)"),
+				"offset": integer("Pagination offset (default 0)"),
+			}, "q"),
+			Method:    http.MethodGet,
+			Path:      "/recipients/search",
+			Summarize: summarizeRecipientSearch,
+		},
+		{
+			Name:        "resolve_recipient",
+			Description: "Work out which recipient a spoken name means, allowing for misheard names, nicknames and aliases. Use this when the user names who to pay. If the status is ambiguous or uncertain, ask the returned question before paying anyone; 'John at Access' narrows by bank.",
+			InputSchema: object(props{
+				"name": str("The recipient's name or alias as the user said it"),
+			}, "name"),
+			Method:    http.MethodGet,
+			Path:      "/recipients/resolve",
+			Summarize: summarizeRecipientResolution,
+		},
+		{
+			Name:        "list_recipient_aliases",
+			Description: "List the other names recipients answer to, optionally for one recipient.",
+			InputSchema: object(props{
+				"recipient_code": str("Only this recipient's aliases (optional)"),
+			}),
+			Method:    http.MethodGet,
+			Path:      "/recipients/aliases",
+			Summarize: summarizeAliasList,
+		},
+		{
+			Name:        "add_recipient_alias",
+			Description: "Give a recipient another name, e.g. 'landlord' or 'my tailor', so the user can pay them by it. An alias belongs to one recipient and can't be a group's name.",
+			InputSchema: object(props{
+				"recipient_code": str("Recipient code from resolve_recipient"),
+				"alias":          str("The other name"),
+			}, "recipient_code", "alias"),
+			Method: http.MethodPost,
+			Path:   "/recipients/aliases",
+		},
+		{
+			Name:        "remove_recipient_alias",
+			Description: "Remove a recipient alias by ID (from list_recipient_aliases).",
+			InputSchema: object(props{
+				"id": integer("Alias ID"),
+			}, "id"),
+			Method: http.MethodDelete,
+			Path:   "/recipients/aliases/{id}",
+		},
+
+		// Recipient groups
+		{
+			Name:        "create_recipient_group",
+			Description: "Create a named group of recipients, e.g. 'cleaning team', to pay together with pay_group. Members can be named as the user said them; each is paid their own amount or the group's default.",
+			InputSchema: object(props{
+				"name":           str("Group name"),
+				"description":    str("Optional notes about the group"),
+				"currency":       currency("Currency the group is paid in (default NGN)"),
+				"default_amount": integer("Amount each member is paid unless they have their own, in minor units"),
+				"members": array("Group members", object(props{
+					"recipient": str("Recipient code, name or alias"),
+					"amount":    integer("This member's usual amount in minor units (optional)"),
+				}, "recipient")),
+			}, "name"),
+			Method:    http.MethodPost,
+			Path:      "/recipients/groups",
+			Summarize: summarizeRecipientGroup,
+		},
+		{
+			Name:        "list_recipient_groups",
+			Description: "List recipient groups with their members.",
+			InputSchema: object(props{}),
+			Method:      http.MethodGet,
+			Path:        "/recipients/groups",
+			Summarize:   summarizeRecipientGroupList,
+		},
+		{
+			Name:        "get_recipient_group",
+			Description: "Get a recipient group and its members by ID.",
+			InputSchema: object(props{
+				"id": integer("Group ID"),
+			}, "id"),
+			Method:    http.MethodGet,
+			Path:      "/recipients/groups/{id}",
+			Summarize: summarizeRecipientGroup,
+		},
+		{
+			Name:        "update_recipient_group",
+			Description: "Rename a group or change its currency or default amount. Use set_group_member and remove_group_member to change who is in it; a default_amount of 0 removes the default.",
+			InputSchema: object(props{
+				"id":             integer("Group ID"),
+				"name":           str("New name"),
+				"description":    str("New description"),
+				"currency":       currency("New currency"),
+				"default_amount": integer("New default amount per member in minor units"),
+			}, "id"),
+			Method:    http.MethodPut,
+			Path:      "/recipients/groups/{id}",
+			Summarize: summarizeRecipientGroup,
+		},
+		{
+			Name:        "delete_recipient_group",
+			Description: "Delete a recipient group. The recipients themselves are kept.",
+			InputSchema: object(props{
+				"id": integer("Group ID"),
+			}, "id"),
+			Method: http.MethodDelete,
+			Path:   "/recipients/groups/{id}",
+		},
+		{
+			Name:        "set_group_member",
+			Description: "Add a recipient to a group, or change the amount they are usually paid in it.",
+			InputSchema: object(props{
+				"id":        integer("Group ID"),
+				"recipient": str("Recipient code, name or alias"),
+				"amount":    integer("This member's usual amount in minor units; leave out to use the group's default"),
+			}, "id", "recipient"),
+			Method:    http.MethodPost,
+			Path:      "/recipients/groups/{id}/members",
+			Summarize: summarizeRecipientGroup,
+		},
+		{
+			Name:        "remove_group_member",
+			Description: "Take a recipient out of a group.",
+			InputSchema: object(props{
+				"id":             integer("Group ID"),
+				"recipient_code": str("Recipient code of the member"),
+			}, "id", "recipient_code"),
+			Method:    http.MethodDelete,
+			Path:      "/recipients/groups/{id}/members/{recipient_code}",
+			Summarize: summarizeRecipientGroup,
+		},
+
+		// Expenses
+		{
+			Name:        "record_expense",
+			Description: "Record an expense. Validates against budget limits and updates spending totals. The first call returns a preview and confirmation_token; call again with only the confirmation_token once the user agrees.",
+			InputSchema: object(props{
+				"recipient_code":  str("Recipient code from search_recipients"),
+				"recipient":       str("Recipient name or alias as the user said it, instead of recipient_code"),
+				"amount":          integer("Expense amount in minor units (kobo for NGN)"),
+				"currency":        currency("Currency code; must match the budget's (default NGN)"),
+				"category":        str("Expense category from list_categories (e.g., 'utilities', 'groceries'); leave out to categorize automatically"),
+				"narration":       str("Description of the expense"),
+				"reference":       str("Unique expense reference (optional)"),
+				"notes":           str("Additional notes"),
+				"budget_limit_id": integer("Budget ID to track against (optional)"),
+				"goal_id":         integer("Goal ID this expense achieves (optional)"),
+
+				"confirmation_token": str("Token from the preview response; records the previewed expense"),
+			}, "amount", "narration"),
+			Method:    http.MethodPost,
+			Path:      "/expenses/create",
+			Summarize: summarizeExpense,
+		},
+		{
+			Name:        "list_expenses",
+			Description: "List expenses with optional recipient, category, status and date filters.",
+			InputSchema: object(props{
+				"recipient_code": str("Filter by recipient code"),
+				"category":       str("Filter by category (a parent category includes its children)"),
+				"status":         str("Filter by status"),
+				"currency":       currency("Filter by currency"),
+				"from":           str("Start date (YYYY-MM-DD)"),
+				"to":             str("End date (YYYY-MM-DD)"),
+				"count":          integer("Number of expenses to return"),
+				"offset":         integer("Pagination offset"),
+			}),
+			Method:    http.MethodPost,
+			Path:      "/expenses/list",
+			Summarize: summarizeExpenseList,
+		},
+		{
+			Name:        "get_expense",
+			Description: "Get an expense by ID.",
+			InputSchema: object(props{
+				"id": integer("Expense ID"),
+			}, "id"),
+			Method: http.MethodGet,
+			Path:   "/expenses/get/{id}",
+		},
+		{
+			Name:        "update_expense",
+			Description: "Update an expense's category, narration, status, payment date or notes.",
+			InputSchema: object(props{
+				"id":           integer("Expense ID"),
+				"category":     str("New category"),
+				"narration":    str("New narration"),
+				"status":       str("New status"),
+				"payment_date": str("Payment date (RFC 3339)"),
+				"notes":        str("New notes"),
+			}, "id"),
+			Method: http.MethodPut,
+			Path:   "/expenses/update/{id}",
+		},
+
+		// Categories
+		{
+			Name:        "list_categories",
+			Description: "List the canonical expense categories as a tree (e.g. transport → ride_hailing).",
+			InputSchema: object(props{}),
+			Method:      http.MethodGet,
+			Path:        "/categories",
+			Summarize:   summarizeCategories,
+		},
+		{
+			Name:        "create_category_rule",
+			Description: "Add a rule that categorizes matching expenses automatically, e.g. narration contains 'uber' → transport.",
+			InputSchema: object(props{
+				"field":    enum("Expense field to match", "recipient_code", "recipient_name", "narration"),
+				"operator": enum("How to match", "equals", "contains", "prefix", "regex"),
+				"value":    str("Text to match (case-insensitive)"),
+				"category": str("Category to assign, from list_categories"),
+				"priority": integer("Higher priority rules are tried first (default 0)"),
+				"name":     str("Short name for the rule"),
+			}, "field", "operator", "value", "category"),
+			Method:    http.MethodPost,
+			Path:      "/categories/rules",
+			Summarize: summarizeCategoryRule,
+		},
+		{
+			Name:        "recategorize_expenses",
+			Description: "Apply the current category rules and recipient history to past expenses that have no category. Categories the user chose are never changed.",
+			InputSchema: object(props{
+				"from":              str("Start date (YYYY-MM-DD)"),
+				"to":                str("End date, inclusive (YYYY-MM-DD)"),
+				"include_automatic": boolean("Also revisit expenses categorized automatically before"),
+				"dry_run":           boolean("Only report what would change"),
+			}),
+			Method:    http.MethodPost,
+			Path:      "/categories/recategorize",
+			Summarize: summarizeRecategorize,
+		},
+
+		// Budgets
+		{
+			Name:        "create_budget",
+			Description: "Create a budget limit to track spending. Alerts when the threshold is reached.",
+			InputSchema: object(props{
+				"name":            str("Budget name (e.g., 'Monthly Utilities')"),
+				"amount":          integer("Budget limit in minor units (kobo for NGN)"),
+				"currency":        currency("Budget currency (default NGN); only payments in this currency count against it"),
+				"limit_type":      enum("Budget period type", "monthly", "quarterly", "yearly", "emergency_fund", "default"),
+				"period_start":    str("Start date (YYYY-MM-DD)"),
+				"period_end":      str("End date (YYYY-MM-DD)"),
+				"alert_threshold": integer("Alert when spending reaches this percentage (default 80)"),
+				"notes":           str("Additional notes"),
+			}, "name", "amount", "limit_type", "period_start", "period_end"),
+			Method:    http.MethodPost,
+			Path:      "/budgets/create",
+			Summarize: summarizeBudget,
+		},
+		{
+			Name:        "list_budgets",
+			Description: "List budget limits with optional type, status and active filters.",
+			InputSchema: object(props{
+				"limit_type": str("Filter by limit type"),
+				"status":     str("Filter by status"),
+				"active":     boolean("Only budgets whose period covers today"),
+				"count":      integer("Number of budgets to return"),
+				"offset":     integer("Pagination offset"),
+			}),
+			Method: http.MethodPost,
+			Path:   "/budgets/list",
+		},
+		{
+			Name:        "get_budget_summary",
+			Description: "Get active budget limits and spending summary. Shows how much has been spent and how much remains.",
+			InputSchema: object(props{}),
+			Method:      http.MethodGet,
+			Path:        "/budgets/active",
+			Summarize:   summarizeActiveBudgets,
+		},
+		{
+			Name:        "get_budget",
+			Description: "Get a budget limit by ID.",
+			InputSchema: object(props{
+				"id": integer("Budget ID"),
+			}, "id"),
+			Method:    http.MethodGet,
+			Path:      "/budgets/{id}",
+			Summarize: summarizeBudget,
+		},
+		{
+			Name:        "update_budget",
+			Description: "Update a budget limit's name, amount, alert threshold, status or notes.",
+			InputSchema: object(props{
+				"id":              integer("Budget ID"),
+				"name":            str("New name"),
+				"amount":          integer("New limit in kobo"),
+				"alert_threshold": integer("New alert threshold percentage"),
+				"status":          str("New status"),
+				"notes":           str("New notes"),
+			}, "id"),
+			Method:    http.MethodPut,
+			Path:      "/budgets/{id}",
+			Summarize: summarizeBudget,
+		},
+		{
+			Name:        "check_budget_limit",
+			Description: "Check whether a budget can afford an amount without recording anything.",
+			InputSchema: object(props{
+				"id":       integer("Budget ID"),
+				"amount":   integer("Amount in minor units (kobo for NGN)"),
+				"currency": currency("Currency of the amount (defaults to the budget's; others are converted)"),
+				"date":     str("Date whose exchange rate to convert at (YYYY-MM-DD, default today)"),
+			}, "id", "amount"),
+			Method:    http.MethodGet,
+			Path:      "/budgets/{id}/check/{amount}",
+			Summarize: summarizeReason,
+		},
+
+		// Goals
+		{
+			Name:        "create_goal",
+			Description: "Create a financial goal. Can be linked to a budget for affordability checks.",
+			InputSchema: object(props{
+				"title":           str("Goal title (e.g., 'Buy a camera')"),
+				"description":     str("Detailed description"),
+				"goal_type":       enum("Type of goal", "recurring_expense", "investment", "purchase", "emergency"),
+				"target_amount":   integer("Target amount in kobo"),
+				"budget_limit_id": integer("Budget to link for affordability tracking (optional)"),
+				"frequency":       enum("How often to work toward this goal", "once", "daily", "weekly", "monthly", "quarterly", "yearly"),
+				"start_date":      str("Start date (RFC 3339)"),
+				"end_date":        str("Target completion date (RFC 3339)"),
+				"category":        str("Goal category"),
+				"priority":        enum("Priority level", "low", "medium", "high"),
+				"notes":           str("Additional notes"),
+			}, "title", "goal_type", "target_amount", "frequency", "start_date"),
+			Method:    http.MethodPost,
+			Path:      "/goals/create",
+			Summarize: summarizeGoal,
+		},
+		{
+			Name:        "list_goals",
+			Description: "List goals with optional status, budget, type, category and priority filters.",
+			InputSchema: object(props{
+				"status":          str("Filter by status"),
+				"budget_limit_id": integer("Filter by budget ID"),
+				"goal_type":       str("Filter by goal type"),
+				"category":        str("Filter by category"),
+				"priority":        str("Filter by priority"),
+				"active":          boolean("Only pending goals within their date range"),
+				"limit":           integer("Number of goals to return (default 50)"),
+				"offset":          integer("Pagination offset"),
+			}),
+			Method:    http.MethodPost,
+			Path:      "/goals/list",
+			Summarize: summarizeGoalList,
+		},
+		{
+			Name:        "get_goal",
+			Description: "Get a goal by ID.",
+			InputSchema: object(props{
+				"id": integer("Goal ID"),
+			}, "id"),
+			Method:    http.MethodGet,
+			Path:      "/goals/{id}",
+			Summarize: summarizeGoal,
+		},
+		{
+			Name:        "update_goal",
+			Description: "Update a pending goal.",
+			InputSchema: object(props{
+				"id":              integer("Goal ID"),
+				"title":           str("New title"),
+				"description":     str("New description"),
+				"target_amount":   integer("New target amount in kobo"),
+				"budget_limit_id": integer("New budget ID"),
+				"end_date":        str("New end date (RFC 3339)"),
+				"status":          enum("New status", "pending", "achieved", "cancelled", "failed"),
+				"category":        str("New category"),
+				"priority":        enum("New priority", "low", "medium", "high"),
+				"notes":           str("New notes"),
+			}, "id"),
+			Method:    http.MethodPut,
+			Path:      "/goals/{id}",
+			Summarize: summarizeGoal,
+		},
+		{
+			Name:        "delete_goal",
+			Description: "Delete a goal that has no linked expenses.",
+			InputSchema: object(props{
+				"id": integer("Goal ID"),
+			}, "id"),
+			Method: http.MethodDelete,
+			Path:   "/goals/{id}",
+		},
+
+		// Transfer limits
+		{
+			Name:        "set_account_limits",
+			Description: "Set account-wide transfer caps. Every transfer and expense payment is checked against them over rolling windows. Omitted limits are unchanged; 0 removes a limit.",
+			InputSchema: object(props{
+				"per_transaction_limit":  integer("Maximum single payment in kobo"),
+				"daily_transfer_limit":   integer("Maximum sent in any 24 hours, in kobo"),
+				"weekly_transfer_limit":  integer("Maximum sent in any 7 days, in kobo"),
+				"monthly_transfer_limit": integer("Maximum sent in any 30 days, in kobo"),
+				"balance_limit":          integer("Wallet balance alert threshold in kobo (informational)"),
+				"currency":               currency("Limit currency"),
+			}),
+			Method:    http.MethodPost,
+			Path:      "/limits/account",
+			Summarize: summarizeAccountLimits,
+		},
+		{
+			Name:        "get_account_limits",
+			Description: "Get account-wide transfer caps and how much has been sent in each rolling window.",
+			InputSchema: object(props{}),
+			Method:      http.MethodGet,
+			Path:        "/limits/account",
+			Summarize:   summarizeAccountLimits,
+		},
+		{
+			Name:        "set_beneficiary_transfer_limit",
+			Description: "Cap how much can be sent to one recipient over a rolling period, with an alert threshold.",
+			InputSchema: object(props{
+				"beneficiary_id":    str("Recipient code (preferred) or recipient name"),
+				"period":            enum("Rolling period", "daily", "weekly", "monthly"),
+				"amount_limit":      integer("Maximum amount in kobo for the period"),
+				"currency":          currency("Limit currency"),
+				"alerts_at_percent": integer("Alert when this percentage of the limit is reached (default 80)"),
+			}, "beneficiary_id", "period", "amount_limit"),
+			Method:    http.MethodPost,
+			Path:      "/limits/beneficiaries",
+			Summarize: summarizeBeneficiaryLimit,
+		},
+		{
+			Name:        "list_beneficiary_limits",
+			Description: "List per-recipient transfer caps with current usage.",
+			InputSchema: object(props{
+				"recipient_code": str("Filter by recipient code"),
+			}),
+			Method: http.MethodGet,
+			Path:   "/limits/beneficiaries",
+		},
+		{
+			Name:        "remove_beneficiary_transfer_limit",
+			Description: "Remove a per-recipient transfer cap for a period.",
+			InputSchema: object(props{
+				"recipient_code": str("Recipient code"),
+				"period":         enum("Rolling period", "daily", "weekly", "monthly"),
+			}, "recipient_code", "period"),
+			Method: http.MethodDelete,
+			Path:   "/limits/beneficiaries/{recipient_code}/{period}",
+		},
+		{
+			Name:        "check_transfer_limits",
+			Description: "Check whether a payment to a recipient would pass all transfer limits, without sending it.",
+			InputSchema: object(props{
+				"recipient_code": str("Recipient code"),
+				"amount":         integer("Amount in minor units (kobo for NGN)"),
+				"currency":       currency("Currency code (default NGN)"),
+			}, "recipient_code", "amount"),
+			Method:    http.MethodPost,
+			Path:      "/limits/check",
+			Summarize: summarizeReason,
+		},
+
+		// Analytics
+		{
+			Name:        "aggregate_transactions",
+			Description: "Compute sums, counts and averages of transactions, expenses, transfers or invoices over a date range, grouped by time, category, recipient or status, with change versus the previous period.",
+			InputSchema: object(props{
+				"metric":     enum("Headline metric (picks the data source)", "total_transfers_value", "total_transfers_count", "total_transactions_value", "total_transactions_count", "total_invoices_value", "total_invoices_count", "top_categories"),
+				"source":     enum("Data source when no metric is given", "transactions", "expenses", "transfers", "invoices"),
+				"group_by":   enum("How to group results", "day", "week", "month", "category", "recipient", "status"),
+				"from":       str("Start date (YYYY-MM-DD or ISO datetime)"),
+				"to":         str("End date, inclusive (YYYY-MM-DD or ISO datetime)"),
+				"currency":   currency("Currency"),
+				"convert_to": currency("Convert every currency into this one at each row's date instead of filtering by currency"),
+				"limit":      integer("Maximum number of groups for category/recipient/status grouping"),
+				"filters": object(props{
+					"beneficiary_id": str("Recipient code or name"),
+					"category":       str("Category"),
+					"status":         str("Status"),
+				}),
+			}, "from", "to"),
+			Method:    http.MethodPost,
+			Path:      "/analytics/aggregate",
+			Summarize: summarizeAggregate,
+		},
+
+		{
+			Name:        "account_snapshot",
+			Description: "Get the whole financial picture in one call: balances, active budgets, open goals, pending expenses, outstanding invoices and money in/out for a window. Sections that fail are flagged individually.",
+			InputSchema: object(props{
+				"window":         enum("Activity window", "this_week", "this_month", "last_month", "this_year"),
+				"scope":          enum("Limit activity to one beneficiary", "all", "beneficiary"),
+				"beneficiary_id": str("Recipient code or name when scope is beneficiary"),
+			}),
+			Method:    http.MethodGet,
+			Path:      "/snapshot",
+			Summarize: summarizeSnapshot,
+		},
+		{
+			Name:        "forecast_cashflow",
+			Description: "Project the balance for each of the next N days from today's balance, unpaid invoices (on their due dates), scheduled transfers, recurring payments, pending expenses and unspent budgets. Flags the first day the balance goes negative or below the floor.",
+			InputSchema: object(props{
+				"days":  integer("Days to project (default 30, max 365)"),
+				"floor": integer("Flag days below this balance, in minor units (default from server config)"),
+			}),
+			Method:    http.MethodGet,
+			Path:      "/forecast",
+			Summarize: summarizeForecast,
+		},
+		{
+			Name:        "monthly_statement",
+			Description: "Generate a monthly statement: spending by category and recipient, budget vs actual, goals achieved or missed, invoices issued and paid, and changes on the month before. Each statement is stored as a snapshot that can be viewed as HTML or PDF.",
+			InputSchema: object(props{
+				"period":   str("Month as YYYY-MM (default: last month)"),
+				"currency": currency("Statement currency (default NGN); other currencies are converted"),
+			}),
+			Method:    http.MethodPost,
+			Path:      "/reports/generate",
+			Summarize: summarizeReport,
+		},
+
+		// Anomalies
+		{
+			Name:        "list_flagged_payments",
+			Description: "List expenses and transfers flagged as likely duplicates, far larger than usual for the recipient or category, or large first payments to a recipient. Newest first.",
+			InputSchema: object(props{
+				"status": enum("Filter by review status", "open", "reviewed", "dismissed"),
+				"kind":   enum("Filter by kind of warning", "duplicate", "recipient_outlier", "category_outlier", "first_large_payment"),
+			}),
+			Method:    http.MethodGet,
+			Path:      "/anomalies/list",
+			Summarize: summarizeAnomalyList,
+		},
+		{
+			Name:        "review_flagged_payment",
+			Description: "Mark a flagged payment as reviewed or dismissed, with an optional note.",
+			InputSchema: object(props{
+				"id":     integer("Flagged payment (anomaly event) ID"),
+				"status": enum("Review outcome (default reviewed)", "reviewed", "dismissed"),
+				"note":   str("What was found or done"),
+			}, "id"),
+			Method: http.MethodPost,
+			Path:   "/anomalies/{id}/review",
+		},
+
+		// Reconciliation
+		{
+			Name:        "run_reconciliation",
+			Description: "Compare local transfers, expenses, invoices, transactions and recipients with Paystack and report missing records, mismatched amounts and mismatched statuses.",
+			InputSchema: object(props{
+				"sources":  array("Sources to check (default: all)", enum("Source", "transfers", "expenses", "invoices", "transactions", "recipients")),
+				"from":     str("Start date (YYYY-MM-DD, default 30 days ago)"),
+				"to":       str("End date, inclusive (YYYY-MM-DD, default today)"),
+				"auto_fix": boolean("Apply safe fixes such as caching missing records and adopting final Paystack statuses"),
+			}),
+			Method:    http.MethodPost,
+			Path:      "/reconciliation/run",
+			Summarize: summarizeReconciliation,
+		},
+
+		// Ledger
+		{
+			Name:        "check_ledger_integrity",
+			Description: "Check that the double-entry ledger balances and agrees with budgets, goals, expenses, transfers and invoices.",
+			InputSchema: object(props{}),
+			Method:      http.MethodGet,
+			Path:        "/ledger/integrity",
+			Summarize:   summarizeLedgerIntegrity,
+		},
+		{
+			Name:        "list_ledger_entries",
+			Description: "List journal entries with their debit and credit lines, optionally for one account (e.g. expense:budget:3, asset:goal:2) or source (expense, transfer, invoice).",
+			InputSchema: object(props{
+				"account":     str("Account code"),
+				"source_type": enum("Source of the entries", "expense", "transfer", "invoice"),
+				"source_id":   integer("ID of the source record"),
+				"limit":       integer("Maximum number of entries (default 50)"),
+			}),
+			Method: http.MethodGet,
+			Path:   "/ledger/entries",
+		},
+
+		// Exchange rates
+		{
+			Name:        "set_exchange_rate",
+			Description: "Record an exchange rate, used to convert foreign-currency spending into a budget's currency from its date onwards.",
+			InputSchema: object(props{
+				"base":   currency("Currency being priced (e.g. USD)"),
+				"quote":  currency("Currency the price is in (e.g. NGN)"),
+				"rate":   number("Price of one unit of base in quote (e.g. 1550 for 1 USD = 1550 NGN)"),
+				"date":   str("Date the rate applies from (YYYY-MM-DD, default today)"),
+				"source": str("Where the rate came from (default manual)"),
+			}, "base", "quote", "rate"),
+			Method:    http.MethodPost,
+			Path:      "/fx/rates",
+			Summarize: summarizeExchangeRates,
+		},
+		{
+			Name:        "convert_currency",
+			Description: "Convert an amount between currencies at the stored exchange rate in effect on a date.",
+			InputSchema: object(props{
+				"amount": integer("Amount in minor units of the from currency"),
+				"from":   currency("Currency of the amount"),
+				"to":     currency("Currency to convert into"),
+				"date":   str("Date whose rate to use (YYYY-MM-DD, default today)"),
+			}, "amount", "from", "to"),
+			Method:    http.MethodGet,
+			Path:      "/fx/convert",
+			Summarize: summarizeConversion,
+		},
+
+		// Virtual cards
+		{
+			Name:        "create_virtual_card",
+			Description: "Create a virtual debit card that spends from a budget, with optional spend limit, merchant category rules and expiry.",
+			InputSchema: object(props{
+				"label":                 str("Card label/name for identification"),
+				"currency":              enum("Card currency (only NGN is currently supported)", "NGN", "USD", "EUR", "GBP"),
+				"budget_limit_id":       integer("Budget the card spends from (defaults to this month's default budget)"),
+				"spend_limit":           integer("Maximum spend in kobo for the spend period"),
+				"spend_period":          enum("Period the spend limit applies to", "per_transaction", "daily", "monthly", "lifetime"),
+				"per_transaction_limit": integer("Maximum single payment in kobo"),
+				"allowed_categories":    array("Only allow these merchant categories", str("Merchant category")),
+				"blocked_categories":    array("Block these merchant categories", str("Merchant category")),
+				"expires_at":            str("Card expiration date (YYYY-MM-DD or ISO datetime, default one year)"),
+			}, "label", "currency"),
+			Method:    http.MethodPost,
+			Path:      "/cards/create",
+			Summarize: summarizeCard,
+		},
+		{
+			Name:        "list_virtual_cards",
+			Description: "List virtual cards with their controls and status.",
+			InputSchema: object(props{
+				"status": enum("Filter by status", "active", "frozen", "terminated"),
+			}),
+			Method: http.MethodGet,
+			Path:   "/cards/list",
+		},
+		{
+			Name:        "freeze_virtual_card",
+			Description: "Freeze a virtual card so all payments are declined.",
+			InputSchema: object(props{
+				"id": integer("Card ID"),
+			}, "id"),
+			Method:    http.MethodPost,
+			Path:      "/cards/{id}/freeze",
+			Summarize: summarizeCard,
+		},
+		{
+			Name:        "unfreeze_virtual_card",
+			Description: "Unfreeze a frozen virtual card.",
+			InputSchema: object(props{
+				"id": integer("Card ID"),
+			}, "id"),
+			Method:    http.MethodPost,
+			Path:      "/cards/{id}/unfreeze",
+			Summarize: summarizeCard,
+		},
+
+		// Scheduled payments
+		{
+			Name:        "create_schedule",
+			Description: "Schedule a recurring payment to a recipient, on an interval (every + unit) or a cron expression. Each run checks the budget and records an expense; with auto_transfer it also sends the transfer. The first call returns a preview and confirmation_token; call again with only the confirmation_token once the user agrees.",
+			InputSchema: object(props{
+				"recipient_code":  str("Recipient code from search_recipients"),
+				"amount":          integer("Amount per run in minor units (kobo for NGN)"),
+				"currency":        currency("Currency code; must match the budget's (default NGN)"),
+				"narration":       str("Description of the payment, e.g. 'Office rent'"),
+				"name":            str("Schedule name (defaults to the narration)"),
+				"category":        str("Expense category; leave out to categorize automatically"),
+				"budget_limit_id": integer("Budget ID to track against (optional)"),
+				"every":           integer("Run every this many units, e.g. 2 with unit 'week'"),
+				"unit":            enum("Interval unit", "day", "week", "month", "year"),
+				"cron":            str("Cron expression instead of every/unit, e.g. '0 9 1 * *' for 09:00 on the 1st"),
+				"start_at":        str("First run (YYYY-MM-DD or ISO datetime, default now)"),
+				"end_at":          str("Last day it may run (YYYY-MM-DD, optional)"),
+				"auto_transfer":   boolean("Also send the Paystack transfer on each run"),
+				"max_retries":     integer("Times to retry a failed run (default 3)"),
+
+				"confirmation_token": str("Token from the preview response; creates the previewed schedule"),
+			}, "recipient_code", "amount", "narration"),
+			Method:    http.MethodPost,
+			Path:      "/schedules/create",
+			Summarize: summarizeSchedule,
+		},
+		{
+			Name:        "list_schedules",
+			Description: "List recurring payment schedules with their next runs.",
+			InputSchema: object(props{
+				"status": enum("Filter by status", "active", "paused", "completed", "cancelled"),
+			}),
+			Method:    http.MethodGet,
+			Path:      "/schedules/list",
+			Summarize: summarizeScheduleList,
+		},
+		{
+			Name:        "pause_schedule",
+			Description: "Pause a recurring payment until it is resumed.",
+			InputSchema: object(props{
+				"id": integer("Schedule ID"),
+			}, "id"),
+			Method:    http.MethodPost,
+			Path:      "/schedules/{id}/pause",
+			Summarize: summarizeSchedule,
+		},
+		{
+			Name:        "resume_schedule",
+			Description: "Resume a paused recurring payment from its next run; runs missed while paused aren't paid.",
+			InputSchema: object(props{
+				"id": integer("Schedule ID"),
+			}, "id"),
+			Method:    http.MethodPost,
+			Path:      "/schedules/{id}/resume",
+			Summarize: summarizeSchedule,
+		},
+		{
+			Name:        "skip_schedule",
+			Description: "Skip the next run of a recurring payment.",
+			InputSchema: object(props{
+				"id": integer("Schedule ID"),
+			}, "id"),
+			Method:    http.MethodPost,
+			Path:      "/schedules/{id}/skip",
+			Summarize: summarizeSchedule,
+		},
+
+		// Search
+		{
+			Name:        "search_records",
+			Description: "Search expenses, recipients, invoices and service providers at once, best match first. Tolerates partial words and small typos, so a transcript like 'pay jonh doe' still finds John Doe. Each result says whether it matched exactly or through a correction.",
+			InputSchema: object(props{
+				"q":     str("What to look for - a name, narration, category, code or account number"),
+				"types": str("Comma-separated types to include: expense, recipient, invoice, provider (default all)"),
+				"limit": integer("Maximum results (default 20)"),
+			}, "q"),
+			Method:    http.MethodGet,
+			Path:      "/search",
+			Summarize: summarizeSearch,
+		},
+
+		// Service providers
+		{
+			Name:        "search_service_providers",
+			Description: "Search for service providers by name, service type, or category. Returns providers with pricing and match scores.",
+			InputSchema: object(props{
+				"search":   str("Search query - provider name, service name, or description"),
+				"category": enum("Filter by category", "technology", "beauty", "pets"),
+			}),
+			Method:    http.MethodGet,
+			Path:      "/service_providers",
+			Summarize: summarizeProviders,
+		},
+	}
+}
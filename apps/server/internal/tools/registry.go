@@ -0,0 +1,274 @@
+// Package tools exposes the server's handler capabilities as named tools with
+// JSON Schema inputs, so assistants (MCP clients, the voice agent) can drive
+// the API without knowing its routes.
+//
+// KEY WORKFLOW:
+// Lookup Tool → Split Arguments into Path/Query/Body → Dispatch to API Router
+// In-Process → Decode Standard Response Envelope → Return Result
+//
+// DESIGN DECISIONS:
+// - Tools dispatch through the existing API router so business rules live in one place
+// - Path parameters are declared with chi's {name} syntax and filled from arguments
+// - GET and DELETE tools receive remaining arguments as query parameters, others as a JSON body
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Tool describes a single capability exposed to assistants
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+	Method      string                 `json:"-"`
+	Path        string                 `json:"-"`
+
+	// Summarize renders a successful result's data as a short spoken sentence.
+	// Tools without one fall back to the API message.
+	Summarize func(data json.RawMessage) string `json:"-"`
+}
+
+// Result is the normalized outcome of a tool call
+type Result struct {
+	StatusCode int             `json:"status_code"`
+	Status     bool            `json:"status"`
+	Message    string          `json:"message"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Summary    string          `json:"spoken_summary,omitempty"`
+	// Warnings flag a payment that went through but looks like a duplicate or is unusually large
+	Warnings json.RawMessage `json:"warnings,omitempty"`
+}
+
+// ArgumentError reports tool arguments that fail schema validation
+type ArgumentError struct {
+	Message string
+}
+
+func (e *ArgumentError) Error() string {
+	return e.Message
+}
+
+// Registry holds the available tools and the API handler they dispatch to
+type Registry struct {
+	handler http.Handler
+	tools   []Tool
+	index   map[string]int
+}
+
+// NewRegistry creates a registry that dispatches tool calls to handler.
+// The handler is expected to serve the /api/v1 route table.
+func NewRegistry(handler http.Handler) *Registry {
+	return &Registry{
+		handler: handler,
+		index:   map[string]int{},
+	}
+}
+
+// NewDefaultRegistry creates a registry pre-loaded with the Default catalog
+func NewDefaultRegistry(handler http.Handler) *Registry {
+	r := NewRegistry(handler)
+	for _, tool := range Default() {
+		r.Register(tool)
+	}
+	return r
+}
+
+// Register adds a tool, replacing any existing tool with the same name
+func (r *Registry) Register(tool Tool) {
+	if i, ok := r.index[tool.Name]; ok {
+		r.tools[i] = tool
+		return
+	}
+	r.index[tool.Name] = len(r.tools)
+	r.tools = append(r.tools, tool)
+}
+
+// Tools returns all registered tools in registration order
+func (r *Registry) Tools() []Tool {
+	out := make([]Tool, len(r.tools))
+	copy(out, r.tools)
+	return out
+}
+
+// Lookup returns the tool registered under name
+func (r *Registry) Lookup(name string) (Tool, bool) {
+	i, ok := r.index[name]
+	if !ok {
+		return Tool{}, false
+	}
+	return r.tools[i], true
+}
+
+// Call executes the named tool with the given arguments
+func (r *Registry) Call(ctx context.Context, name string, args map[string]interface{}) (*Result, error) {
+	tool, ok := r.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+
+	if err := validateArgs(tool, args); err != nil {
+		return nil, err
+	}
+
+	req, err := buildRequest(ctx, tool, args)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := newRecorder()
+	r.handler.ServeHTTP(rec, req)
+
+	result := &Result{StatusCode: rec.status}
+	if err := json.Unmarshal(rec.body.Bytes(), result); err != nil {
+		return nil, fmt.Errorf("tool %s returned a non-JSON response: %w", name, err)
+	}
+	result.StatusCode = rec.status
+	result.Summary = summarize(tool, result)
+
+	return result, nil
+}
+
+// validateArgs checks that every required argument is present and that
+// enum-restricted arguments hold an allowed value
+func validateArgs(tool Tool, args map[string]interface{}) error {
+	// A confirmation token stands in for the arguments of the previewed call
+	_, confirming := args["confirmation_token"]
+
+	if required, ok := tool.InputSchema["required"].([]string); ok && !confirming {
+		for _, key := range required {
+			if v, ok := args[key]; !ok || v == nil || v == "" {
+				return &ArgumentError{Message: fmt.Sprintf("%s is required", key)}
+			}
+		}
+	}
+
+	properties, _ := tool.InputSchema["properties"].(map[string]interface{})
+	for key, value := range args {
+		property, ok := properties[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		allowed, ok := property["enum"].([]string)
+		if !ok {
+			continue
+		}
+		str, _ := value.(string)
+		valid := false
+		for _, a := range allowed {
+			if str == a {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return &ArgumentError{Message: fmt.Sprintf("%s must be one of: %s", key, strings.Join(allowed, ", "))}
+		}
+	}
+
+	return nil
+}
+
+// buildRequest turns tool arguments into an HTTP request against the API router
+func buildRequest(ctx context.Context, tool Tool, args map[string]interface{}) (*http.Request, error) {
+	remaining := map[string]interface{}{}
+	for k, v := range args {
+		remaining[k] = v
+	}
+
+	// Fill {param} placeholders from arguments
+	path := tool.Path
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(path[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("tool %s has a malformed path: %s", tool.Name, tool.Path)
+		}
+		param := path[start+1 : start+end]
+		value, ok := remaining[param]
+		if !ok || value == nil {
+			return nil, &ArgumentError{Message: fmt.Sprintf("%s is required", param)}
+		}
+		delete(remaining, param)
+		path = path[:start] + url.PathEscape(formatValue(value)) + path[start+end+1:]
+	}
+
+	var body *bytes.Reader
+	switch tool.Method {
+	case http.MethodGet, http.MethodDelete:
+		if len(remaining) > 0 {
+			query := url.Values{}
+			keys := make([]string, 0, len(remaining))
+			for k := range remaining {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				query.Set(k, formatValue(remaining[k]))
+			}
+			path += "?" + query.Encode()
+		}
+		body = bytes.NewReader(nil)
+	default:
+		payload, err := json.Marshal(remaining)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode arguments: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	// Drop any chi routing state inherited from an outer request (e.g. the
+	// tools/execute endpoint), otherwise the router would reuse its route path
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, nil)
+
+	req, err := http.NewRequestWithContext(ctx, tool.Method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// formatValue renders an argument for use in a path or query string.
+// JSON numbers decode as float64, so whole numbers are printed without a fraction.
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		if val == float64(int64(val)) {
+			return fmt.Sprintf("%d", int64(val))
+		}
+		return fmt.Sprintf("%g", val)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// recorder is a minimal in-memory http.ResponseWriter
+type recorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: http.Header{}, status: http.StatusOK}
+}
+
+func (rec *recorder) Header() http.Header         { return rec.header }
+func (rec *recorder) Write(b []byte) (int, error) { return rec.body.Write(b) }
+func (rec *recorder) WriteHeader(statusCode int)  { rec.status = statusCode }
@@ -0,0 +1,353 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRegistryCallDispatchesPathQueryAndBody(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/budgets/{id}/check/{amount}", func(w http.ResponseWriter, req *http.Request) {
+		respondWithEcho(w, map[string]interface{}{
+			"id":     chi.URLParam(req, "id"),
+			"amount": chi.URLParam(req, "amount"),
+		})
+	})
+	r.Get("/recipients/search", func(w http.ResponseWriter, req *http.Request) {
+		respondWithEcho(w, map[string]interface{}{"q": req.URL.Query().Get("q")})
+	})
+	r.Post("/expenses/create", func(w http.ResponseWriter, req *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(req.Body).Decode(&body)
+		respondWithEcho(w, body)
+	})
+
+	registry := NewRegistry(r)
+	registry.Register(Tool{Name: "check", Method: http.MethodGet, Path: "/budgets/{id}/check/{amount}"})
+	registry.Register(Tool{Name: "search", Method: http.MethodGet, Path: "/recipients/search"})
+	registry.Register(Tool{Name: "expense", Method: http.MethodPost, Path: "/expenses/create"})
+
+	tests := []struct {
+		tool string
+		args map[string]interface{}
+		want map[string]interface{}
+	}{
+		{"check", map[string]interface{}{"id": float64(3), "amount": float64(50000)}, map[string]interface{}{"id": "3", "amount": "50000"}},
+		{"search", map[string]interface{}{"q": "john doe"}, map[string]interface{}{"q": "john doe"}},
+		{"expense", map[string]interface{}{"amount": float64(100), "narration": "lunch"}, map[string]interface{}{"amount": float64(100), "narration": "lunch"}},
+	}
+
+	for _, tt := range tests {
+		result, err := registry.Call(context.Background(), tt.tool, tt.args)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.tool, err)
+		}
+		if !result.Status || result.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected success, got %+v", tt.tool, result)
+		}
+
+		var got map[string]interface{}
+		if err := json.Unmarshal(result.Data, &got); err != nil {
+			t.Fatalf("%s: failed to decode data: %v", tt.tool, err)
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("%s: expected %s=%v, got %v", tt.tool, k, v, got[k])
+			}
+		}
+	}
+}
+
+func TestRegistryCallErrors(t *testing.T) {
+	registry := NewRegistry(chi.NewRouter())
+	registry.Register(Tool{Name: "get_goal", Method: http.MethodGet, Path: "/goals/{id}"})
+
+	if _, err := registry.Call(context.Background(), "missing", nil); err == nil {
+		t.Error("expected error for unknown tool")
+	}
+	if _, err := registry.Call(context.Background(), "get_goal", map[string]interface{}{}); err == nil {
+		t.Error("expected error for missing path parameter")
+	}
+}
+
+func TestDefaultCatalogHasUniqueNamesAndObjectSchemas(t *testing.T) {
+	seen := map[string]bool{}
+	for _, tool := range Default() {
+		if seen[tool.Name] {
+			t.Errorf("duplicate tool name: %s", tool.Name)
+		}
+		seen[tool.Name] = true
+
+		if tool.InputSchema["type"] != "object" {
+			t.Errorf("%s: input schema must be an object", tool.Name)
+		}
+		if tool.Method == "" || tool.Path == "" {
+			t.Errorf("%s: method and path are required", tool.Name)
+		}
+	}
+}
+
+func respondWithEcho(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  true,
+		"message": "Success",
+		"data":    data,
+	})
+}
+
+func TestRegistryCallValidatesArguments(t *testing.T) {
+	registry := NewRegistry(chi.NewRouter())
+	registry.Register(Tool{
+		Name: "create_budget",
+		InputSchema: object(props{
+			"name":       str("Budget name"),
+			"limit_type": enum("Budget period type", "monthly", "yearly"),
+		}, "name"),
+		Method: http.MethodPost,
+		Path:   "/budgets/create",
+	})
+
+	cases := []map[string]interface{}{
+		{},
+		{"name": ""},
+		{"name": "Rent", "limit_type": "weekly"},
+	}
+	for _, args := range cases {
+		_, err := registry.Call(context.Background(), "create_budget", args)
+		if _, ok := err.(*ArgumentError); !ok {
+			t.Errorf("args %v: expected ArgumentError, got %v", args, err)
+		}
+	}
+}
+
+func TestSpokenSummaries(t *testing.T) {
+	tests := []struct {
+		name   string
+		tool   Tool
+		result Result
+		want   string
+	}{
+		{
+			name:   "failure uses error",
+			tool:   Tool{Summarize: summarizeBalance},
+			result: Result{Status: false, Error: "budget not found: 3"},
+			want:   "That didn't go through: budget not found: 3.",
+		},
+		{
+			name:   "balance",
+			tool:   Tool{Summarize: summarizeBalance},
+			result: Result{Status: true, Data: json.RawMessage(`{"balance":123456789,"currency":"NGN"}`)},
+			want:   "Your available balance is ₦1,234,567.89.",
+		},
+		{
+			name:   "confirmation preview",
+			tool:   Tool{Summarize: summarizeTransfer},
+			result: Result{Status: true, Data: json.RawMessage(`{"confirmation_required":true,"action":"transfer","amount":500000,"recipient_name":"Ada Obi","budget_impact":{"can_afford":true,"remaining":2000000}}`)},
+			want:   "This will send ₦5,000 to Ada Obi. You'll have ₦15,000 left in that budget. Should I go ahead?",
+		},
+		{
+			name:   "preview in another currency",
+			tool:   Tool{Summarize: summarizeTransfer},
+			result: Result{Status: true, Data: json.RawMessage(`{"confirmation_required":true,"action":"invoice","amount":125050,"currency":"GHS","recipient_name":"Kofi Mensah"}`)},
+			want:   "This will request GH₵1,250.50 from Kofi Mensah. Should I go ahead?",
+		},
+		{
+			name:   "preview charged to a budget in another currency",
+			tool:   Tool{Summarize: summarizeExpense},
+			result: Result{Status: true, Data: json.RawMessage(`{"confirmation_required":true,"action":"expense","amount":1500,"currency":"USD","recipient_name":"Ada Obi","budget_impact":{"can_afford":true,"remaining":5000000,"requested_amount":2325000,"currency":"NGN"}}`)},
+			want:   "This will pay $15 to Ada Obi. You'll have ₦26,750 left in that budget. Should I go ahead?",
+		},
+		{
+			name:   "preview with an anomaly warning",
+			tool:   Tool{Summarize: summarizeTransfer},
+			result: Result{Status: true, Data: json.RawMessage(`{"confirmation_required":true,"action":"transfer","amount":500000,"recipient_name":"Ada Obi","warnings":[{"kind":"duplicate","message":"You paid Ada Obi ₦5,000 for the same thing 2 minutes ago. This may be a duplicate."}]}`)},
+			want:   "This will send ₦5,000 to Ada Obi. You paid Ada Obi ₦5,000 for the same thing 2 minutes ago. This may be a duplicate. Should I go ahead?",
+		},
+		{
+			name: "payment made with warnings",
+			tool: Tool{Summarize: summarizeTransfer},
+			result: Result{Status: true, Data: json.RawMessage(`{"amount":500000,"currency":"NGN","status":"pending"}`),
+				Warnings: json.RawMessage(`[{"kind":"first_large_payment","message":"This is your first payment to Ada Obi, and ₦5,000 is a large amount to start with."}]`)},
+			want: "Your transfer of ₦5,000 is pending. This is your first payment to Ada Obi, and ₦5,000 is a large amount to start with.",
+		},
+		{
+			name:   "flagged payments",
+			tool:   Tool{Summarize: summarizeAnomalyList},
+			result: Result{Status: true, Data: json.RawMessage(`[{"id":3,"status":"open","message":"₦90,000 is far more than you usually spend on fuel (typically ₦20,000)."},{"id":2,"status":"reviewed","message":"old"},{"id":1,"status":"open","message":"older"}]`)},
+			want:   "2 flagged payments waiting for review. The latest: ₦90,000 is far more than you usually spend on fuel (typically ₦20,000).",
+		},
+		{
+			name:   "search corrected a typo",
+			tool:   Tool{Summarize: summarizeSearch},
+			result: Result{Status: true, Data: json.RawMessage(`{"query":"pay jonh doe","results":[{"type":"recipient","id":"RCP_1","title":"John Doe","match":"fuzzy"},{"type":"expense","id":"4","title":"John Doe","amount":500000,"currency":"NGN","match":"fuzzy"}]}`)},
+			want:   "I found 2 results. The best match is the recipient John Doe, though it isn't an exact match.",
+		},
+		{
+			name:   "search with an amount",
+			tool:   Tool{Summarize: summarizeSearch},
+			result: Result{Status: true, Data: json.RawMessage(`{"query":"acme","results":[{"type":"invoice","id":"PRQ_1","title":"Acme Ltd","amount":120000,"currency":"NGN","match":"exact"}]}`)},
+			want:   "I found 1 result. The best match is the invoice Acme Ltd for ₦1,200.",
+		},
+		{
+			name:   "recipient resolved",
+			tool:   Tool{Summarize: summarizeRecipientResolution},
+			result: Result{Status: true, Data: json.RawMessage(`{"query":"landlord","status":"resolved","recipient":{"name":"Adebayo Ogunlesi","bank_name":"First Bank"},"confidence":1}`)},
+			want:   "That's Adebayo Ogunlesi at First Bank.",
+		},
+		{
+			name:   "recipient ambiguous",
+			tool:   Tool{Summarize: summarizeRecipientResolution},
+			result: Result{Status: true, Data: json.RawMessage(`{"query":"john doe","status":"ambiguous","question":"Did you mean John Doe at GTBank or John Doh at Access Bank?"}`)},
+			want:   "Did you mean John Doe at GTBank or John Doh at Access Bank?",
+		},
+		{
+			name:   "recipient sync",
+			tool:   Tool{Summarize: summarizeRecipientSync},
+			result: Result{Status: true, Data: json.RawMessage(`{"fetched":12,"imported":2,"refreshed":1,"deactivated":0,"duplicates":1,"changes":[]}`)},
+			want:   "Recipients synced with Paystack: 2 new, 1 updated, 1 duplicate.",
+		},
+		{
+			name:   "recipient group",
+			tool:   Tool{Summarize: summarizeRecipientGroup},
+			result: Result{Status: true, Data: json.RawMessage(`{"id":1,"name":"cleaning team","currency":"NGN","default_amount":1500000,"members":[{"recipient_code":"RCP_a","recipient_name":"Ada Obi"},{"recipient_code":"RCP_b","recipient_name":"Bola Ade","amount":2000000}]}`)},
+			want:   "The cleaning team group has 2 members, paid ₦15,000 each unless they have their own amount.",
+		},
+		{
+			name:   "bulk transfer sent",
+			tool:   Tool{Summarize: summarizeBulkTransfer},
+			result: Result{Status: true, Data: json.RawMessage(`{"group":"cleaning team","total":{"amount":3500000,"currency":"NGN"},"sent":2,"failed":0,"results":[]}`)},
+			want:   "Sent 2 payments totalling ₦35,000.",
+		},
+		{
+			name:   "bulk transfer partly failed",
+			tool:   Tool{Summarize: summarizeBulkTransfer},
+			result: Result{Status: true, Data: json.RawMessage(`{"total":{"amount":3500000,"currency":"NGN"},"sent":1,"failed":1,"results":[{"recipient_name":"Ada Obi","status":"sent"},{"recipient_name":"Bola Ade","status":"failed","error":"limit reached"}]}`)},
+			want:   "Sent 1 of 2 payments. The payment to Bola Ade failed.",
+		},
+		{
+			name:   "bulk transfer mostly failed",
+			tool:   Tool{Summarize: summarizeBulkTransfer},
+			result: Result{Status: true, Data: json.RawMessage(`{"total":{"amount":5000000,"currency":"NGN"},"sent":1,"failed":2,"results":[{"recipient_name":"Ada Obi","status":"sent"},{"recipient_name":"Bola Ade","status":"failed","error":"limit reached"},{"recipient_name":"Chidi Eze","status":"failed","error":"limit reached"}]}`)},
+			want:   "Sent 1 of 3 payments. The payments to Bola Ade, Chidi Eze failed.",
+		},
+		{
+			name:   "bulk transfer unconfirmed",
+			tool:   Tool{Summarize: summarizeBulkTransfer},
+			result: Result{Status: true, Data: json.RawMessage(`{"total":{"amount":5000000,"currency":"NGN"},"sent":1,"failed":1,"needs_review":1,"results":[{"recipient_name":"Ada Obi","status":"sent"},{"recipient_name":"Bola Ade","status":"failed","error":"limit reached"},{"recipient_name":"Chidi Eze","status":"needs_review","error":"transfer failed"}]}`)},
+			want:   "Sent 1 of 3 payments. The payment to Bola Ade failed. Paystack didn't confirm the payment to Chidi Eze, so check it before paying again.",
+		},
+		{
+			name:   "category rule",
+			tool:   Tool{Summarize: summarizeCategoryRule},
+			result: Result{Status: true, Data: json.RawMessage(`{"id":1,"field":"narration","operator":"contains","value":"uber","category":"ride_hailing","priority":0,"enabled":true}`)},
+			want:   "From now on, expenses whose narration contains \"uber\" go under ride_hailing.",
+		},
+		{
+			name:   "recategorize dry run",
+			tool:   Tool{Summarize: summarizeRecategorize},
+			result: Result{Status: true, Data: json.RawMessage(`{"examined":12,"changed":5,"dry_run":true}`)},
+			want:   "I would re-categorize 5 of 12 expenses.",
+		},
+		{
+			name:   "currency conversion",
+			tool:   Tool{Summarize: summarizeConversion},
+			result: Result{Status: true, Data: json.RawMessage(`{"original":{"amount":1500,"currency":"USD"},"converted":{"amount":2325383,"currency":"NGN"},"rate":1550.255,"rate_date":"2026-01-01"}`)},
+			want:   "$15 is ₦23,253.83, at the rate from 2026-01-01.",
+		},
+		{
+			name:   "exchange rate saved",
+			tool:   Tool{Summarize: summarizeExchangeRates},
+			result: Result{Status: true, Data: json.RawMessage(`{"saved":1,"rates":[{"base":"USD","quote":"NGN","rate":1550.5,"date":"2026-01-01"}]}`)},
+			want:   "Saved: 1 USD is 1550.5 NGN from 2026-01-01.",
+		},
+		{
+			name:   "totals kept per currency",
+			tool:   Tool{Summarize: summarizeLocalTransactions},
+			result: Result{Status: true, Data: json.RawMessage(`{"total":3,"totals":{"USD":2000,"NGN":500000}}`)},
+			want:   "I found 3 transactions totalling ₦5,000 and $20.",
+		},
+		{
+			name:   "ledger integrity issues",
+			tool:   Tool{Summarize: summarizeLedgerIntegrity},
+			result: Result{Status: true, Data: json.RawMessage(`{"balanced":false,"entries":4,"issues":[{"check":"budget_spent","reference":"budget 2","detail":"spent_amount 500 but ledger shows 0"}]}`)},
+			want:   "I found 1 problem in the ledger. The first is budget 2: spent_amount 500 but ledger shows 0.",
+		},
+		{
+			name:   "scheduled transfer preview",
+			tool:   Tool{Summarize: summarizeScheduledTransfer},
+			result: Result{Status: true, Data: json.RawMessage(`{"confirmation_required":true,"action":"scheduled_transfer","amount":2000000,"currency":"NGN","recipient_name":"Tunde Bakare","schedule":"on 23 October 2026"}`)},
+			want:   "This will send ₦20,000 to Tunde Bakare on 23 October 2026. Should I go ahead?",
+		},
+		{
+			name:   "failed scheduled transfer",
+			tool:   Tool{Summarize: summarizeScheduledTransfer},
+			result: Result{Status: true, Data: json.RawMessage(`{"id":4,"amount":2000000,"currency":"NGN","recipient_name":"Tunde Bakare","status":"failed","error":"insufficient balance: ₦12,000 available"}`)},
+			want:   "₦20,000 to Tunde Bakare failed: insufficient balance: ₦12,000 available.",
+		},
+		{
+			name:   "scheduled transfer needing review",
+			tool:   Tool{Summarize: summarizeScheduledTransfer},
+			result: Result{Status: true, Data: json.RawMessage(`{"id":4,"amount":2000000,"currency":"NGN","recipient_name":"Tunde Bakare","status":"needs_review","error":"transfer failed: timeout (transfer may have been sent)"}`)},
+			want:   "₦20,000 to Tunde Bakare may have gone through. Reschedule it to check with Paystack before anything is sent again.",
+		},
+		{
+			name:   "scheduled transfer list",
+			tool:   Tool{Summarize: summarizeScheduledTransferList},
+			result: Result{Status: true, Data: json.RawMessage(`[{"amount":500000,"recipient_name":"Ada Obi","status":"sent","execute_at":"2026-10-01T00:00:00Z"},{"amount":2000000,"currency":"NGN","recipient_name":"Tunde Bakare","status":"scheduled","execute_at":"2026-10-23T00:00:00Z"}]`)},
+			want:   "You have 1 scheduled transfer waiting. The next is ₦20,000 to Tunde Bakare on 23 October 2026.",
+		},
+		{
+			name:   "forecast going negative",
+			tool:   Tool{Summarize: summarizeForecast},
+			result: Result{Status: true, Data: json.RawMessage(`{"currency":"NGN","closing":19000000,"floor":5000000,"lowest":-1000000,"lowest_date":"2026-11-04T00:00:00Z","days":[{},{},{},{},{}],"alerts":[{"date":"2026-11-03T00:00:00Z","kind":"below_floor"},{"date":"2026-11-04T00:00:00Z","kind":"negative"}]}`)},
+			want:   "In 5 days your balance should be ₦190,000. It goes negative on 4 November 2026, reaching a low of -₦10,000 on 4 November 2026.",
+		},
+		{
+			name:   "forecast without breaches",
+			tool:   Tool{Summarize: summarizeForecast},
+			result: Result{Status: true, Data: json.RawMessage(`{"currency":"NGN","closing":500000,"floor":0,"lowest":400000,"days":[{}],"alerts":[]}`)},
+			want:   "In 1 day your balance should be ₦5,000. It stays above ₦0 throughout.",
+		},
+		{
+			name:   "monthly statement",
+			tool:   Tool{Summarize: summarizeReport},
+			result: Result{Status: true, Data: json.RawMessage(`{"title":"October 2026","currency":"NGN","spending":{"sum":29000000,"count":4},"income":{"sum":38000000},"categories":[{"name":"rent","totals":{"sum":20000000}}],"budgets":[{"over":true},{"over":false}],"goals":{"achieved":[{"id":7}],"failed":[]},"changes":[{"metric":"spending","delta":{"sum_percent":346.15}}]}`)},
+			want:   "In October 2026 you spent ₦290,000 across 4 expenses, up 346% on the month before, and received ₦380,000. The biggest category was rent at ₦200,000. 1 budget went over. You achieved 1 goal.",
+		},
+		{
+			name:   "fallback",
+			tool:   Tool{},
+			result: Result{Status: true, Message: "Success"},
+			want:   "Done.",
+		},
+		{
+			name:   "schedule preview",
+			tool:   Tool{Summarize: summarizeSchedule},
+			result: Result{Status: true, Data: json.RawMessage(`{"confirmation_required":true,"action":"schedule","amount":25000000,"recipient_name":"Landlord","schedule":"every month from 2026-11-01","budget_impact":{"can_afford":true,"remaining":100000000}}`)},
+			want:   "This will pay ₦250,000 to Landlord every month from 2026-11-01. You'll have ₦750,000 left in that budget. Should I go ahead?",
+		},
+		{
+			name:   "paused schedule",
+			tool:   Tool{Summarize: summarizeSchedule},
+			result: Result{Status: true, Data: json.RawMessage(`{"id":3,"amount":500000,"currency":"NGN","recipient_name":"Ada Obi","frequency":"every 2 weeks","status":"paused","next_run_at":"2026-11-06T09:00:00Z"}`)},
+			want:   "Paying ₦5,000 to Ada Obi every 2 weeks is paused. The next run is on 6 November 2026.",
+		},
+		{
+			name:   "schedule list",
+			tool:   Tool{Summarize: summarizeScheduleList},
+			result: Result{Status: true, Data: json.RawMessage(`[{"amount":100000,"recipient_name":"Gym","status":"paused","next_run_at":"2026-10-20T00:00:00Z"},{"amount":25000000,"currency":"NGN","recipient_name":"Landlord","status":"active","next_run_at":"2026-11-01T09:00:00Z"}]`)},
+			want:   "You have 2 scheduled payments. Next is ₦250,000 to Landlord on 1 November 2026.",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := summarize(tt.tool, &tt.result); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
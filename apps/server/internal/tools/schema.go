@@ -0,0 +1,60 @@
+package tools
+
+import "paystack.mpc.proxy/internal/money"
+
+// Schema helpers keep the catalog readable. They produce plain JSON Schema
+// maps that serialize directly into MCP and OpenAI tool definitions.
+
+// object builds an object schema with the given properties and required keys
+func object(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// str builds a string property
+func str(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+// enum builds a string property restricted to values
+func enum(description string, values ...string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description, "enum": values}
+}
+
+// integer builds an integer property
+func integer(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "integer", "description": description}
+}
+
+// currency builds a string property restricted to the supported currency codes
+func currency(description string) map[string]interface{} {
+	codes := []string{}
+	for _, c := range money.Currencies() {
+		codes = append(codes, string(c))
+	}
+	return enum(description, codes...)
+}
+
+// number builds a number property
+func number(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "number", "description": description}
+}
+
+// boolean builds a boolean property
+func boolean(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "boolean", "description": description}
+}
+
+// array builds an array property of items
+func array(description string, items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "description": description, "items": items}
+}
+
+// props is shorthand for a property map
+type props = map[string]interface{}
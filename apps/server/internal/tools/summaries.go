@@ -0,0 +1,953 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"paystack.mpc.proxy/internal/money"
+)
+
+// summarize produces the spoken_summary for a tool result
+func summarize(tool Tool, result *Result) string {
+	if !result.Status {
+		reason := result.Error
+		if reason == "" {
+			reason = result.Message
+		}
+		return fmt.Sprintf("That didn't go through: %s.", strings.TrimSuffix(reason, "."))
+	}
+
+	if preview := decodeMap(result.Data); preview != nil && preview["confirmation_required"] == true {
+		return summarizeConfirmation(preview)
+	}
+
+	return withWarnings(summarizeSuccess(tool, result), result.Warnings)
+}
+
+// summarizeSuccess speaks a successful result
+func summarizeSuccess(tool Tool, result *Result) string {
+	if tool.Summarize != nil {
+		if summary := tool.Summarize(result.Data); summary != "" {
+			return summary
+		}
+	}
+
+	if result.Message == "" || result.Message == "Success" {
+		return "Done."
+	}
+	return result.Message
+}
+
+// withWarnings adds the messages of any anomaly warnings after a summary
+func withWarnings(summary string, data json.RawMessage) string {
+	for _, w := range decodeList(data) {
+		if message := text(w, "message"); message != "" {
+			summary += " " + message
+		}
+	}
+	return summary
+}
+
+// summarizeConfirmation reads back a preview so the user can approve it
+func summarizeConfirmation(preview map[string]interface{}) string {
+	var b strings.Builder
+	currency := text(preview, "currency")
+	recipient := text(preview, "recipient_name")
+	if schedule := text(preview, "schedule"); schedule != "" {
+		recipient += " " + schedule
+	}
+	fmt.Fprintf(&b, "This will %s %s %s %s.", previewVerb(text(preview, "action")), spoken(amount(preview, "amount"), currency), previewPreposition(text(preview, "action")), recipient)
+	if remaining, ok := field(preview, "budget_impact.remaining").(float64); ok {
+		// The budget may be kept in another currency, so use its converted amount
+		budgetCurrency, charged := currency, amount(preview, "amount")
+		if c := text(preview, "budget_impact.currency"); c != "" {
+			budgetCurrency = c
+		}
+		if requested, ok := field(preview, "budget_impact.requested_amount").(float64); ok {
+			charged = requested
+		}
+		if canAfford, _ := field(preview, "budget_impact.can_afford").(bool); canAfford {
+			fmt.Fprintf(&b, " You'll have %s left in that budget.", spoken(remaining-charged, budgetCurrency))
+		} else {
+			fmt.Fprintf(&b, " That's more than the %s left in your budget.", spoken(remaining, budgetCurrency))
+		}
+	}
+	if warnings, ok := preview["warnings"].([]interface{}); ok {
+		for _, item := range warnings {
+			if w, ok := item.(map[string]interface{}); ok && text(w, "message") != "" {
+				b.WriteString(" " + text(w, "message"))
+			}
+		}
+	}
+	b.WriteString(" Should I go ahead?")
+	return b.String()
+}
+
+func previewVerb(action string) string {
+	switch action {
+	case "invoice":
+		return "request"
+	case "expense", "schedule":
+		return "pay"
+	default:
+		return "send"
+	}
+}
+
+func previewPreposition(action string) string {
+	if action == "invoice" {
+		return "from"
+	}
+	return "to"
+}
+
+// decodeMap decodes result data as a JSON object, returning nil on mismatch
+func decodeMap(data json.RawMessage) map[string]interface{} {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// decodeList decodes result data as a JSON array of objects, returning nil on mismatch
+func decodeList(data json.RawMessage) []map[string]interface{} {
+	var l []map[string]interface{}
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil
+	}
+	return l
+}
+
+// field reads a nested value from a decoded object by dotted path
+func field(m map[string]interface{}, path string) interface{} {
+	var cur interface{} = m
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = obj[key]
+	}
+	return cur
+}
+
+// text reads a string field, returning "" when absent
+func text(m map[string]interface{}, path string) string {
+	s, _ := field(m, path).(string)
+	return s
+}
+
+// amount reads a numeric field, returning 0 when absent
+func amount(m map[string]interface{}, path string) float64 {
+	f, _ := field(m, path).(float64)
+	return f
+}
+
+// spoken formats minor units of a currency for speech, e.g. 150000 NGN → "₦1,500".
+// An empty currency is naira.
+func spoken(minor float64, currency string) string {
+	return money.New(int64(minor), money.Currency(strings.ToUpper(currency))).Format()
+}
+
+// spokenTotals formats per-currency "totals", e.g. "₦5,000 and $20",
+// found at path, or at the top level when path is empty
+func spokenTotals(m map[string]interface{}, path string) string {
+	if path != "" {
+		path += "."
+	}
+	totals, _ := field(m, path+"totals").(map[string]interface{})
+	if len(totals) == 0 {
+		return spoken(amount(m, path+"total_amount"), text(m, path+"currency"))
+	}
+	currencies := make([]string, 0, len(totals))
+	for currency := range totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	parts := make([]string, len(currencies))
+	for i, currency := range currencies {
+		minor, _ := totals[currency].(float64)
+		parts[i] = spoken(minor, currency)
+	}
+	return strings.Join(parts, " and ")
+}
+
+// plural returns "1 recipient" / "3 recipients"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func summarizeBalance(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("Your available balance is %s.", spoken(amount(m, "balance"), text(m, "currency")))
+}
+
+func summarizeRecipientSearch(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	results, _ := m["results"].([]interface{})
+	if len(results) == 0 {
+		return fmt.Sprintf("I couldn't find any recipients matching '%s'.", text(m, "query"))
+	}
+	top, _ := results[0].(map[string]interface{})
+	summary := fmt.Sprintf("I found %s. The best match is %s", plural(len(results), "recipient"), text(top, "name"))
+	if bank := text(top, "bank_name"); bank != "" {
+		summary += " at " + bank
+	}
+	return summary + "."
+}
+
+func summarizeRecipientResolution(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	switch text(m, "status") {
+	case "resolved":
+		r, _ := m["recipient"].(map[string]interface{})
+		summary := "That's " + text(r, "name")
+		if bank := text(r, "bank_name"); bank != "" {
+			summary += " at " + bank
+		}
+		return summary + "."
+	case "not_found":
+		return fmt.Sprintf("I couldn't find a recipient called '%s'.", text(m, "query"))
+	}
+	return text(m, "question")
+}
+
+func summarizeAliasList(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	aliases, _ := m["aliases"].([]interface{})
+	if len(aliases) == 0 {
+		return "There are no recipient aliases."
+	}
+	names := make([]string, 0, len(aliases))
+	for _, item := range aliases {
+		a, _ := item.(map[string]interface{})
+		names = append(names, fmt.Sprintf("'%s' for %s", text(a, "alias"), text(a, "recipient_name")))
+	}
+	count := "1 alias"
+	if len(aliases) != 1 {
+		count = fmt.Sprintf("%d aliases", len(aliases))
+	}
+	return fmt.Sprintf("Recipients have %s: %s.", count, strings.Join(names, ", "))
+}
+
+func summarizeRecipientGroup(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	members, _ := m["members"].([]interface{})
+	summary := fmt.Sprintf("The %s group has %s", text(m, "name"), plural(len(members), "member"))
+	if def := amount(m, "default_amount"); def > 0 {
+		summary += fmt.Sprintf(", paid %s each unless they have their own amount", spoken(def, text(m, "currency")))
+	}
+	return summary + "."
+}
+
+func summarizeRecipientGroupList(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	groups, _ := m["groups"].([]interface{})
+	if len(groups) == 0 {
+		return "You have no recipient groups."
+	}
+	names := make([]string, 0, len(groups))
+	for _, item := range groups {
+		g, _ := item.(map[string]interface{})
+		names = append(names, text(g, "name"))
+	}
+	return fmt.Sprintf("You have %s: %s.", plural(len(groups), "group"), strings.Join(names, ", "))
+}
+
+func summarizeSearch(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	results, _ := m["results"].([]interface{})
+	if len(results) == 0 {
+		return fmt.Sprintf("I couldn't find anything matching '%s'.", text(m, "query"))
+	}
+	top, _ := results[0].(map[string]interface{})
+	summary := fmt.Sprintf("I found %s. The best match is the %s %s", plural(len(results), "result"), text(top, "type"), text(top, "title"))
+	if _, ok := top["amount"].(float64); ok {
+		summary += " for " + spoken(amount(top, "amount"), text(top, "currency"))
+	}
+	if text(top, "match") != "exact" {
+		summary += ", though it isn't an exact match"
+	}
+	return summary + "."
+}
+
+func summarizeRecipient(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	summary := fmt.Sprintf("%s, account %s", text(m, "name"), text(m, "account_number"))
+	if bank := text(m, "bank_name"); bank != "" {
+		summary += " at " + bank
+	}
+	return summary + "."
+}
+
+func summarizeRecipientList(data json.RawMessage) string {
+	l := decodeList(data)
+	if l == nil {
+		return ""
+	}
+	return fmt.Sprintf("You have %s saved.", plural(len(l), "recipient"))
+}
+
+func summarizeRecipientSync(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	var parts []string
+	for _, c := range []struct{ field, label string }{
+		{"imported", "new"},
+		{"refreshed", "updated"},
+		{"deactivated", "deleted in Paystack"},
+		{"duplicates", "duplicate"},
+	} {
+		if n := int(amount(m, c.field)); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, c.label))
+		}
+	}
+	if len(parts) == 0 {
+		return "Your recipients are already up to date with Paystack."
+	}
+	return fmt.Sprintf("Recipients synced with Paystack: %s.", strings.Join(parts, ", "))
+}
+
+func summarizeResolvedAccount(data json.RawMessage) string {
+	m := decodeMap(data)
+	if name := text(m, "account_name"); name != "" {
+		return fmt.Sprintf("That account belongs to %s.", name)
+	}
+	return ""
+}
+
+func summarizeTransfer(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	status := text(m, "status")
+	if status == "" {
+		status = "submitted"
+	}
+	return fmt.Sprintf("Your transfer of %s is %s.", spoken(amount(m, "amount"), text(m, "currency")), status)
+}
+
+func summarizeBulkTransfer(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	sent, failed, review := int(amount(m, "sent")), int(amount(m, "failed")), int(amount(m, "needs_review"))
+	total := spoken(amount(m, "total.amount"), text(m, "total.currency"))
+	if failed == 0 && review == 0 {
+		return fmt.Sprintf("Sent %s totalling %s.", plural(sent, "payment"), total)
+	}
+	var failedNames, reviewNames []string
+	results, _ := m["results"].([]interface{})
+	for _, item := range results {
+		r, _ := item.(map[string]interface{})
+		switch text(r, "status") {
+		case "failed":
+			failedNames = append(failedNames, text(r, "recipient_name"))
+		case "needs_review":
+			reviewNames = append(reviewNames, text(r, "recipient_name"))
+		}
+	}
+	summary := fmt.Sprintf("Sent %d of %s.", sent, plural(sent+failed+review, "payment"))
+	if failed > 0 {
+		failures := " The payments to %s failed."
+		if failed == 1 {
+			failures = " The payment to %s failed."
+		}
+		summary += fmt.Sprintf(failures, strings.Join(failedNames, ", "))
+	}
+	if review > 0 {
+		unconfirmed := " Paystack didn't confirm the payments to %s, so check them before paying again."
+		if review == 1 {
+			unconfirmed = " Paystack didn't confirm the payment to %s, so check it before paying again."
+		}
+		summary += fmt.Sprintf(unconfirmed, strings.Join(reviewNames, ", "))
+	}
+	return summary
+}
+
+func summarizeExpense(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	summary := fmt.Sprintf("Recorded a %s expense to %s.", spoken(amount(m, "expense.amount"), text(m, "expense.currency")), text(m, "expense.recipient_name"))
+	if field(m, "budget_info.remaining") != nil {
+		summary += fmt.Sprintf(" You have %s left in that budget.", spoken(amount(m, "budget_info.remaining"), text(m, "expense.currency")))
+	}
+	return summary
+}
+
+func summarizeExpenseList(data json.RawMessage) string {
+	l := decodeList(data)
+	if l == nil {
+		return ""
+	}
+	// Totals are kept per currency so naira and dollars are never added together
+	var currencies []string
+	totals := map[string]float64{}
+	for _, e := range l {
+		currency := strings.ToUpper(text(e, "currency"))
+		if _, seen := totals[currency]; !seen {
+			currencies = append(currencies, currency)
+		}
+		totals[currency] += amount(e, "amount")
+	}
+	parts := make([]string, len(currencies))
+	for i, currency := range currencies {
+		parts[i] = spoken(totals[currency], currency)
+	}
+	if len(parts) == 0 {
+		parts = []string{spoken(0, "")}
+	}
+	return fmt.Sprintf("You have %s totalling %s.", plural(len(l), "expense"), strings.Join(parts, " and "))
+}
+
+func summarizeLocalTransactions(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+
+	total := int(amount(m, "total"))
+	if total == 0 {
+		return "I couldn't find any matching transactions."
+	}
+	return fmt.Sprintf("I found %s totalling %s.", plural(total, "transaction"), spokenTotals(m, ""))
+}
+
+func summarizeActiveBudgets(data json.RawMessage) string {
+	l := decodeList(data)
+	if l == nil {
+		return ""
+	}
+	if len(l) == 0 {
+		return "You have no active budgets."
+	}
+	var limit, remaining float64
+	for _, b := range l {
+		limit += amount(b, "amount")
+		remaining += amount(b, "remaining")
+	}
+	return fmt.Sprintf("You have %s with %s remaining of %s.", plural(len(l), "active budget"), spoken(remaining, ""), spoken(limit, ""))
+}
+
+func summarizeBudget(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s has %s remaining of %s.", text(m, "name"), spoken(amount(m, "remaining"), text(m, "currency")), spoken(amount(m, "amount"), text(m, "currency")))
+}
+
+func summarizeReason(data json.RawMessage) string {
+	m := decodeMap(data)
+	if reason := text(m, "reason"); reason != "" {
+		return reason + "."
+	}
+	return ""
+}
+
+func summarizeAccountLimits(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	limits := m
+	if nested, ok := m["limits"].(map[string]interface{}); ok {
+		limits = nested
+	}
+
+	var parts []string
+	for _, l := range []struct{ key, label string }{
+		{"per_transaction_limit", "per transaction"},
+		{"daily_transfer_limit", "a day"},
+		{"weekly_transfer_limit", "a week"},
+		{"monthly_transfer_limit", "a month"},
+	} {
+		if v := amount(limits, l.key); v > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", spoken(v, text(limits, "currency")), l.label))
+		}
+	}
+	if len(parts) == 0 {
+		return "There are no transfer limits on your account."
+	}
+
+	summary := "Your transfer limits are " + strings.Join(parts, ", ") + "."
+	if _, ok := m["usage"]; ok {
+		summary += fmt.Sprintf(" You've sent %s in the last 24 hours.", spoken(amount(m, "usage.daily"), text(limits, "currency")))
+	}
+	return summary
+}
+
+func summarizeBeneficiaryLimit(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	name := text(m, "recipient_name")
+	if name == "" {
+		name = text(m, "recipient_code")
+	}
+	return fmt.Sprintf("Transfers to %s are now capped at %s %s. %s is left for this period.",
+		name, spoken(amount(m, "amount_limit"), text(m, "currency")), text(m, "period"), spoken(amount(m, "remaining"), text(m, "currency")))
+}
+
+func summarizeAggregate(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	count := int(amount(m, "totals.count"))
+	switch metric := text(m, "metric"); {
+	case strings.HasSuffix(metric, "_count"):
+		fmt.Fprintf(&b, "%s in that period", plural(count, strings.TrimSuffix(text(m, "source"), "s")))
+	default:
+		fmt.Fprintf(&b, "%s across %s", spoken(amount(m, "totals.sum"), text(m, "currency")), plural(count, strings.TrimSuffix(text(m, "source"), "s")))
+	}
+
+	if pct, ok := field(m, "delta.sum_percent").(float64); ok {
+		direction := "up"
+		if pct < 0 {
+			direction, pct = "down", -pct
+		}
+		fmt.Fprintf(&b, ", %s %.0f%% on the previous period", direction, pct)
+	}
+	b.WriteString(".")
+
+	if groups, ok := m["groups"].([]interface{}); ok && len(groups) > 0 && text(m, "group_by") == "category" {
+		if top, ok := groups[0].(map[string]interface{}); ok {
+			fmt.Fprintf(&b, " The biggest category is %s at %s.", text(top, "key"), spoken(amount(top, "sum"), text(m, "currency")))
+		}
+	}
+	if skipped := int(amount(m, "conversion.skipped_rows")); skipped > 0 {
+		fmt.Fprintf(&b, " I left out %s with no exchange rate.", plural(skipped, strings.TrimSuffix(text(m, "source"), "s")))
+	}
+	return b.String()
+}
+
+func summarizeCategories(data json.RawMessage) string {
+	m := decodeMap(data)
+	roots, _ := m["categories"].([]interface{})
+	if len(roots) == 0 {
+		return "There are no categories yet."
+	}
+	names := make([]string, 0, len(roots))
+	for _, root := range roots {
+		if node, ok := root.(map[string]interface{}); ok {
+			names = append(names, text(node, "name"))
+		}
+	}
+	return fmt.Sprintf("The top-level categories are %s.", strings.Join(names, ", "))
+}
+
+func summarizeCategoryRule(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("From now on, expenses whose %s %s %q go under %s.", strings.ReplaceAll(text(m, "field"), "_", " "), ruleVerb(text(m, "operator")), text(m, "value"), text(m, "category"))
+}
+
+func ruleVerb(operator string) string {
+	switch operator {
+	case "equals":
+		return "is"
+	case "prefix":
+		return "starts with"
+	case "regex":
+		return "matches"
+	default:
+		return "contains"
+	}
+}
+
+func summarizeRecategorize(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	changed, examined := int(amount(m, "changed")), int(amount(m, "examined"))
+	if dryRun, _ := m["dry_run"].(bool); dryRun {
+		return fmt.Sprintf("I would re-categorize %d of %s.", changed, plural(examined, "expense"))
+	}
+	return fmt.Sprintf("I re-categorized %d of %s.", changed, plural(examined, "expense"))
+}
+
+func summarizeExchangeRates(data json.RawMessage) string {
+	m := decodeMap(data)
+	rates, _ := m["rates"].([]interface{})
+	if len(rates) != 1 {
+		return fmt.Sprintf("I saved %s.", plural(int(amount(m, "saved")), "exchange rate"))
+	}
+	rate, _ := rates[0].(map[string]interface{})
+	return fmt.Sprintf("Saved: 1 %s is %s %s from %s.", text(rate, "base"), strconv.FormatFloat(amount(rate, "rate"), 'f', -1, 64), text(rate, "quote"), text(rate, "date"))
+}
+
+func summarizeConversion(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s is %s, at the rate from %s.", spoken(amount(m, "original.amount"), text(m, "original.currency")), spoken(amount(m, "converted.amount"), text(m, "converted.currency")), text(m, "rate_date"))
+}
+
+func summarizeSnapshot(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+
+	var parts []string
+	if balances, ok := field(m, "balances.data").([]interface{}); ok && len(balances) > 0 {
+		if b, ok := balances[0].(map[string]interface{}); ok {
+			parts = append(parts, fmt.Sprintf("Your balance is %s.", spoken(amount(b, "balance"), text(b, "currency"))))
+		}
+	} else if field(m, "balances.ok") == false {
+		parts = append(parts, "I couldn't reach Paystack for your balance.")
+	}
+
+	window := strings.ReplaceAll(text(m, "window"), "_", " ")
+	if _, ok := field(m, "activity.data").(map[string]interface{}); ok {
+		parts = append(parts, fmt.Sprintf("You've spent %s and received %s %s.",
+			spoken(amount(m, "activity.data.spent.sum")+amount(m, "activity.data.sent.sum"), ""),
+			spoken(amount(m, "activity.data.received.sum"), ""), window))
+	}
+
+	if n := int(amount(m, "pending_expenses.data.count")); n > 0 {
+		parts = append(parts, fmt.Sprintf("%s pending totalling %s.", plural(n, "expense"), spokenTotals(m, "pending_expenses.data")))
+	}
+	if n := int(amount(m, "outstanding_invoices.data.count")); n > 0 {
+		parts = append(parts, fmt.Sprintf("%s outstanding worth %s.", plural(n, "invoice"), spokenTotals(m, "outstanding_invoices.data")))
+	}
+	if goals, ok := field(m, "goals.data").([]interface{}); ok && len(goals) > 0 {
+		parts = append(parts, fmt.Sprintf("%s open.", plural(len(goals), "goal")))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func summarizeForecast(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	currency := text(m, "currency")
+	days, _ := m["days"].([]interface{})
+	summary := fmt.Sprintf("In %s your balance should be %s.", plural(len(days), "day"), spoken(amount(m, "closing"), currency))
+
+	// Going negative matters more than dipping below the floor, so speak it first
+	alerts := map[string]map[string]interface{}{}
+	list, _ := m["alerts"].([]interface{})
+	for _, item := range list {
+		if a, ok := item.(map[string]interface{}); ok && alerts[text(a, "kind")] == nil {
+			alerts[text(a, "kind")] = a
+		}
+	}
+	if a, ok := alerts["negative"]; ok {
+		return summary + fmt.Sprintf(" It goes negative on %s, reaching a low of %s on %s.", scheduleDate(text(a, "date")),
+			spoken(amount(m, "lowest"), currency), scheduleDate(text(m, "lowest_date")))
+	}
+	if a, ok := alerts["below_floor"]; ok {
+		return summary + fmt.Sprintf(" It drops below %s on %s.", spoken(amount(m, "floor"), currency), scheduleDate(text(a, "date")))
+	}
+	return summary + fmt.Sprintf(" It stays above %s throughout.", spoken(amount(m, "floor"), currency))
+}
+
+func summarizeReport(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	currency := text(m, "currency")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "In %s you spent %s across %s", text(m, "title"), spoken(amount(m, "spending.sum"), currency), plural(int(amount(m, "spending.count")), "expense"))
+	if changes, ok := m["changes"].([]interface{}); ok && len(changes) > 0 {
+		if spending, ok := changes[0].(map[string]interface{}); ok {
+			if pct, ok := field(spending, "delta.sum_percent").(float64); ok {
+				direction := "up"
+				if pct < 0 {
+					direction, pct = "down", -pct
+				}
+				fmt.Fprintf(&b, ", %s %.0f%% on the month before", direction, pct)
+			}
+		}
+	}
+	fmt.Fprintf(&b, ", and received %s.", spoken(amount(m, "income.sum"), currency))
+
+	if categories, ok := m["categories"].([]interface{}); ok && len(categories) > 0 {
+		if top, ok := categories[0].(map[string]interface{}); ok && amount(top, "totals.sum") > 0 {
+			fmt.Fprintf(&b, " The biggest category was %s at %s.", text(top, "name"), spoken(amount(top, "totals.sum"), currency))
+		}
+	}
+	over := 0
+	budgets, _ := m["budgets"].([]interface{})
+	for _, item := range budgets {
+		if budget, ok := item.(map[string]interface{}); ok && budget["over"] == true {
+			over++
+		}
+	}
+	if over > 0 {
+		fmt.Fprintf(&b, " %s went over.", plural(over, "budget"))
+	}
+	if achieved, ok := field(m, "goals.achieved").([]interface{}); ok && len(achieved) > 0 {
+		fmt.Fprintf(&b, " You achieved %s.", plural(len(achieved), "goal"))
+	}
+	return b.String()
+}
+
+func summarizeReconciliation(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+
+	checked := int(amount(m, "checked"))
+	found := int(amount(m, "discrepancies"))
+	if found == 0 {
+		return fmt.Sprintf("I checked %s and everything matches Paystack.", plural(checked, "record"))
+	}
+
+	counts := map[string]int{}
+	if summary, ok := m["summary"].(map[string]interface{}); ok {
+		for _, types := range summary {
+			if t, ok := types.(map[string]interface{}); ok {
+				for name, n := range t {
+					f, _ := n.(float64)
+					counts[name] += int(f)
+				}
+			}
+		}
+	}
+
+	var parts []string
+	for _, name := range []string{"missing_local", "missing_remote", "amount_mismatch", "status_mismatch", "currency_mismatch"} {
+		if counts[name] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[name], strings.ReplaceAll(name, "_", " ")))
+		}
+	}
+
+	noun := "discrepancies"
+	if found == 1 {
+		noun = "discrepancy"
+	}
+	summary := fmt.Sprintf("I checked %s and found %d %s", plural(checked, "record"), found, noun)
+	if len(parts) > 0 {
+		summary += ": " + strings.Join(parts, ", ")
+	}
+	summary += "."
+	if fixed := int(amount(m, "fixed")); fixed > 0 {
+		summary += fmt.Sprintf(" I fixed %d automatically.", fixed)
+	}
+	return summary
+}
+
+func summarizeLedgerIntegrity(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+
+	entries := int(amount(m, "entries"))
+	issues, _ := m["issues"].([]interface{})
+	if len(issues) == 0 {
+		noun := "entries"
+		if entries == 1 {
+			noun = "entry"
+		}
+		return fmt.Sprintf("The books balance: %d %s, %s in debits and credits, and every budget and goal matches.", entries, noun, spoken(amount(m, "total_debits"), ""))
+	}
+
+	first, _ := issues[0].(map[string]interface{})
+	summary := fmt.Sprintf("I found %s in the ledger.", plural(len(issues), "problem"))
+	if first != nil {
+		summary += fmt.Sprintf(" The first is %s: %s.", text(first, "reference"), text(first, "detail"))
+	}
+	return summary
+}
+
+func summarizeCard(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+
+	summary := fmt.Sprintf("Your %s card ending %s is %s.", text(m, "label"), text(m, "last4"), text(m, "status"))
+	if limit := amount(m, "controls.spend_limit"); limit > 0 {
+		period := strings.ReplaceAll(text(m, "controls.spend_period"), "_", " ")
+		summary += fmt.Sprintf(" It can spend up to %s %s.", spoken(limit, text(m, "currency")), period)
+	}
+	return summary
+}
+
+func summarizeSchedule(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	summary := fmt.Sprintf("Paying %s to %s %s is %s.", spoken(amount(m, "amount"), text(m, "currency")), text(m, "recipient_name"), text(m, "frequency"), text(m, "status"))
+	if next := scheduleDate(text(m, "next_run_at")); next != "" && (text(m, "status") == "active" || text(m, "status") == "paused") {
+		summary += fmt.Sprintf(" The next run is on %s.", next)
+	}
+	return summary
+}
+
+func summarizeScheduleList(data json.RawMessage) string {
+	l := decodeList(data)
+	if l == nil {
+		return ""
+	}
+	if len(l) == 0 {
+		return "You have no scheduled payments."
+	}
+	summary := fmt.Sprintf("You have %s.", plural(len(l), "scheduled payment"))
+	// The list is ordered by next run, so the first active one is the soonest
+	for _, s := range l {
+		if next := scheduleDate(text(s, "next_run_at")); next != "" && text(s, "status") == "active" {
+			summary += fmt.Sprintf(" Next is %s to %s on %s.", spoken(amount(s, "amount"), text(s, "currency")), text(s, "recipient_name"), next)
+			break
+		}
+	}
+	return summary
+}
+
+func summarizeScheduledTransfer(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	transfer := fmt.Sprintf("%s to %s", spoken(amount(m, "amount"), text(m, "currency")), text(m, "recipient_name"))
+	switch status := text(m, "status"); status {
+	case "scheduled":
+		return fmt.Sprintf("%s is scheduled for %s.", transfer, scheduleDate(text(m, "execute_at")))
+	case "failed":
+		return fmt.Sprintf("%s failed: %s.", transfer, strings.TrimSuffix(text(m, "error"), "."))
+	case "needs_review":
+		return fmt.Sprintf("%s may have gone through. Reschedule it to check with Paystack before anything is sent again.", transfer)
+	default:
+		return fmt.Sprintf("%s is %s.", transfer, status)
+	}
+}
+
+func summarizeScheduledTransferList(data json.RawMessage) string {
+	l := decodeList(data)
+	if l == nil {
+		return ""
+	}
+	var waiting []map[string]interface{}
+	for _, t := range l {
+		if text(t, "status") == "scheduled" {
+			waiting = append(waiting, t)
+		}
+	}
+	if len(waiting) == 0 {
+		return "You have no transfers waiting to be sent."
+	}
+	// The list is ordered by send time, so the first is the soonest
+	next := waiting[0]
+	return fmt.Sprintf("You have %s waiting. The next is %s to %s on %s.", plural(len(waiting), "scheduled transfer"),
+		spoken(amount(next, "amount"), text(next, "currency")), text(next, "recipient_name"), scheduleDate(text(next, "execute_at")))
+}
+
+// scheduleDate speaks an RFC3339 time as a date, e.g. "1 November 2026"
+func scheduleDate(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return ""
+	}
+	return t.Format("2 January 2006")
+}
+
+func summarizeGoal(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("Goal '%s' targets %s and is %s.", text(m, "title"), spoken(amount(m, "target_amount"), ""), text(m, "status"))
+}
+
+func summarizeGoalList(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("You have %s.", plural(int(amount(m, "total_count")), "goal"))
+}
+
+func summarizePaymentRequest(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("Created a payment request for %s.", spoken(amount(m, "amount"), text(m, "currency")))
+}
+
+func summarizeProviders(data json.RawMessage) string {
+	m := decodeMap(data)
+	if m == nil {
+		return ""
+	}
+	providers, _ := m["providers"].([]interface{})
+	if len(providers) == 0 {
+		return "I couldn't find any matching service providers."
+	}
+	top, _ := providers[0].(map[string]interface{})
+	return fmt.Sprintf("I found %s. The top result is %s.", plural(int(amount(m, "total_count")), "provider"), text(top, "name"))
+}
+
+func summarizeAnomalyList(data json.RawMessage) string {
+	l := decodeList(data)
+	if l == nil {
+		return ""
+	}
+	var open []map[string]interface{}
+	for _, e := range l {
+		if text(e, "status") == "open" {
+			open = append(open, e)
+		}
+	}
+	if len(open) == 0 {
+		return "There are no flagged payments waiting for review."
+	}
+	// The list is newest first
+	latest := open[0]
+	return fmt.Sprintf("%s waiting for review. The latest: %s", plural(len(open), "flagged payment"), text(latest, "message"))
+}
@@ -22,7 +22,7 @@ fi
 
 # Build the server
 echo -e "${YELLOW}Building server...${NC}"
-go build -o bin/paystack-server cmd/server/main.go
+go build -tags sqlite_fts5 -o bin/paystack-server cmd/server/main.go
 echo -e "${GREEN}✓ Server built${NC}\n"
 
 # Start the server in the background with environment variable
@@ -58,7 +58,9 @@ func TestRecipientWorkflow(t *testing.T) {
 			"account_number": "0123456789",
 			"bank_code":      "058",
 			"currency":       "NGN",
-			"narration":    "Test recipient for expenses",
+			"narration":      "Test recipient for expenses",
+			// Test accounts aren't held in the names used here
+			"accept_account_name": true,
 		}
 
 		resp := makeRequest(t, "POST", "/recipients/create", reqBody)
@@ -181,10 +183,11 @@ func TestExpenseWorkflow(t *testing.T) {
 
 	t.Run("Step1_CreateRecipient", func(t *testing.T) {
 		reqBody := map[string]interface{}{
-			"type":           "nuban",
-			"name":           "Expense Test Vendor",
-			"account_number": "9876543210",
-			"bank_code":      "058",
+			"type":                "nuban",
+			"name":                "Expense Test Vendor",
+			"account_number":      "9876543210",
+			"bank_code":           "058",
+			"accept_account_name": true,
 		}
 
 		resp := makeRequest(t, "POST", "/recipients/create", reqBody)
@@ -217,11 +220,11 @@ func TestExpenseWorkflow(t *testing.T) {
 			"amount":         5000000, // ₦50,000
 			"currency":       "NGN",
 			"category":       "software",
-			"narration":    "Monthly SaaS subscription",
+			"narration":      "Monthly SaaS subscription",
 			"notes":          "Payment for January 2024",
 		}
 
-		resp := makeRequest(t, "POST", "/expenses/create", reqBody)
+		resp := makeConfirmedRequest(t, "POST", "/expenses/create", reqBody)
 
 		if !resp.Status {
 			t.Fatalf("Expected status true, got false. Error: %s", resp.Error)
@@ -374,7 +377,7 @@ func TestExpenseValidation(t *testing.T) {
 
 	t.Run("MissingRecipientCode", func(t *testing.T) {
 		reqBody := map[string]interface{}{
-			"amount":      1000000,
+			"amount":    1000000,
 			"narration": "Test expense",
 		}
 
@@ -391,7 +394,7 @@ func TestExpenseValidation(t *testing.T) {
 		reqBody := map[string]interface{}{
 			"recipient_code": "RCP_test",
 			"amount":         0,
-			"narration":    "Test expense",
+			"narration":      "Test expense",
 		}
 
 		resp := makeRequest(t, "POST", "/expenses/create", reqBody)
@@ -422,7 +425,7 @@ func TestExpenseValidation(t *testing.T) {
 		reqBody := map[string]interface{}{
 			"recipient_code": "RCP_nonexistent",
 			"amount":         1000000,
-			"narration":    "Test expense",
+			"narration":      "Test expense",
 		}
 
 		resp := makeRequest(t, "POST", "/expenses/create", reqBody)
@@ -449,10 +452,11 @@ func TestExpenseFilters(t *testing.T) {
 	t.Run("Setup", func(t *testing.T) {
 		// Create recipient
 		recipientResp := makeRequest(t, "POST", "/recipients/create", map[string]interface{}{
-			"type":           "nuban",
-			"name":           "Filter Test Vendor",
-			"account_number": "1122334455",
-			"bank_code":      "058",
+			"type":                "nuban",
+			"name":                "Filter Test Vendor",
+			"account_number":      "1122334455",
+			"bank_code":           "058",
+			"accept_account_name": true,
 		})
 
 		if !recipientResp.Status {
@@ -466,11 +470,11 @@ func TestExpenseFilters(t *testing.T) {
 		// Create multiple expenses with different categories
 		categories := []string{"software", "hardware", "consulting"}
 		for _, category := range categories {
-			expenseResp := makeRequest(t, "POST", "/expenses/create", map[string]interface{}{
+			expenseResp := makeConfirmedRequest(t, "POST", "/expenses/create", map[string]interface{}{
 				"recipient_code": recipientCode,
 				"amount":         1000000 + (len(category) * 100000),
 				"category":       category,
-				"narration":    fmt.Sprintf("Test %s expense", category),
+				"narration":      fmt.Sprintf("Test %s expense", category),
 			})
 
 			if !expenseResp.Status {
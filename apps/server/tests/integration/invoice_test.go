@@ -88,6 +88,26 @@ func makeRequest(t *testing.T, method, endpoint string, body interface{}) *Respo
 	return &response
 }
 
+// makeConfirmedRequest previews a money-moving request and then confirms it with the issued token
+func makeConfirmedRequest(t *testing.T, method, endpoint string, body interface{}) *Response {
+	preview := makeRequest(t, method, endpoint, body)
+	if !preview.Status {
+		return preview
+	}
+
+	var data struct {
+		ConfirmationRequired bool   `json:"confirmation_required"`
+		ConfirmationToken    string `json:"confirmation_token"`
+	}
+	if err := json.Unmarshal(preview.Data, &data); err != nil || !data.ConfirmationRequired {
+		t.Fatalf("Expected a confirmation preview from %s, got: %s", endpoint, string(preview.Data))
+	}
+
+	return makeRequest(t, method, endpoint, map[string]interface{}{
+		"confirmation_token": data.ConfirmationToken,
+	})
+}
+
 // TestInvoiceIntegrationFlow tests the complete invoice workflow
 func TestInvoiceIntegrationFlow(t *testing.T) {
 	// Skip if PAYSTACK_SECRET_KEY is not set
@@ -150,7 +170,7 @@ func TestInvoiceIntegrationFlow(t *testing.T) {
 			"due_date":    "2025-12-31",
 		}
 
-		resp := makeRequest(t, "POST", "/invoices/create", reqBody)
+		resp := makeConfirmedRequest(t, "POST", "/invoices/create", reqBody)
 
 		if !resp.Status {
 			t.Fatalf("Expected status true, got false. Error: %s", resp.Error)
@@ -121,7 +121,7 @@ func TestServiceProviderExpenseWithBudget(t *testing.T) {
 			"notes":          "Service provider payment for beauty service",
 		}
 
-		resp := makeRequest(t, "POST", "/expenses/create", reqBody)
+		resp := makeConfirmedRequest(t, "POST", "/expenses/create", reqBody)
 
 		if !resp.Status {
 			t.Fatalf("Expected status true, got false. Error: %s", resp.Error)
@@ -292,7 +292,7 @@ func TestServiceProviderMultipleExpenses(t *testing.T) {
 				"narration":      exp.service,
 			}
 
-			resp := makeRequest(t, "POST", "/expenses/create", reqBody)
+			resp := makeConfirmedRequest(t, "POST", "/expenses/create", reqBody)
 
 			if !resp.Status {
 				t.Fatalf("Failed to create expense %d: %s", i+1, resp.Error)